@@ -4,6 +4,7 @@ package megaport
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/fatih/color"
@@ -75,6 +76,7 @@ func InitializeCommon() {
 	rootCmd.PersistentFlags().String("template", "", "Go template string for --output go-template (not supported in browser version)")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colorful output")
 	rootCmd.PersistentFlags().StringVar(&utils.Env, "env", "", "Environment to use (prod, dev, or staging)")
+	rootCmd.PersistentFlags().StringVar(&utils.ManagedAccount, "managed-account", "", "Act on behalf of a managed account (company UID or name)")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress informational output, only show errors and data")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show additional debug information")
 	rootCmd.PersistentFlags().Duration("timeout", 0, "Timeout for the operation (e.g., 30s, 2m, 5m); must be positive. Omit to use each command's built-in default (see the command's own help)")
@@ -103,25 +105,20 @@ func InitializeCommon() {
 			verbosity = "verbose"
 		}
 		format := strings.ToLower(outputFormat)
-		validFmt := false
-		for _, vf := range utils.ValidFormatsWASM {
-			if format == vf {
-				validFmt = true
-				break
-			}
-		}
-		if !validFmt {
+		validFormats := utils.CommandFormats(cmd, utils.ValidFormatsWASM)
+		if !slices.Contains(validFormats, format) {
 			// Type the error as a usage CLIError so ExecuteWithArgs emits the JSON
 			// envelope under --output json, matching the native root and the shared
 			// conditional-requirement validators.
 			return exitcodes.NewUsageError(fmt.Errorf("invalid output format: %s. Must be one of: %s",
-				outputFormat, strings.Join(utils.ValidFormatsWASM, ", ")))
+				outputFormat, strings.Join(validFormats, ", ")))
 		}
 		cfg := output.GetOutputConfig()
 		cfg.NoHeader = noHeader
 		cfg.NoPager = noPager // no-op in WASM pager; keeps flag wiring symmetric with native
 		cfg.Verbosity = verbosity
 		cfg.Format = format
+		cfg.NoColor = noColor
 		output.ApplyOutputConfig(cfg)
 		if utils.MaxRetries < 0 {
 			return exitcodes.NewUsageError(fmt.Errorf("--max-retries must be >= 0, got %d", utils.MaxRetries))
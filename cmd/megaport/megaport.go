@@ -3,10 +3,12 @@
 package megaport
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/megaport/megaport-cli/internal/base/exitcodes"
@@ -24,24 +26,22 @@ func init() {
 
 	// Apply non-WASM specific initialization
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		defaultWarnings := applyDefaultSettings(cmd)
+		defaultWarnings, err := applyDefaultSettings(cmd)
+		if err != nil {
+			return utils.FinishPreRunError(cmd, args, err)
+		}
 
 		// Auto-disable color when stdout is not a TTY (piped output)
 		if !cmd.Flags().Changed("no-color") && !output.IsTerminal() {
 			noColor = true
 			_ = cmd.Flags().Set("no-color", "true")
+			config.SetSettingOrigin("no-color", config.OriginNotTerminal)
 		}
 		format := strings.ToLower(outputFormat)
-		validFmt := false
-		for _, vf := range utils.ValidFormats {
-			if format == vf {
-				validFmt = true
-				break
-			}
-		}
-		if !validFmt {
+		validFormats := utils.CommandFormats(cmd, utils.ValidFormats)
+		if !slices.Contains(validFormats, format) {
 			return utils.FinishPreRunError(cmd, args, exitcodes.NewUsageError(fmt.Errorf("invalid output format: %s. Must be one of: %s",
-				outputFormat, strings.Join(utils.ValidFormats, ", "))))
+				outputFormat, strings.Join(validFormats, ", "))))
 		}
 		output.SetOutputFormat(format)
 
@@ -56,6 +56,7 @@ func init() {
 		cfg.NoPager = noPager
 		cfg.Verbosity = verbosity
 		cfg.Format = format // normalized to lower-case above
+		cfg.NoColor = noColor
 		output.ApplyOutputConfig(cfg)
 
 		// Emit config-default warnings now that output format and verbosity are
@@ -168,14 +169,74 @@ func init() {
 	moduleRegistry.RegisterAll(rootCmd)
 }
 
-// applyDefaultSettings reads saved defaults from config and applies them to
-// cmd's flags. It returns a list of warning messages to emit later (after the
-// caller has configured output format and verbosity) so that warnings are
-// routed and suppressed correctly under --output json / --quiet.
-func applyDefaultSettings(cmd *cobra.Command) []string {
-	manager, err := config.NewConfigManager()
-	if err != nil {
-		return []string{fmt.Sprintf("Could not load saved default settings: %v", err)}
+// applyDefaultSettings reads the project config (.megaport.yaml) found from
+// the working directory and the saved defaults from config, and applies them
+// to cmd's flags: flags set on the command line win, then the project config,
+// then the saved defaults. It records where each setting came from for
+// config view --show-origin. It returns a list of warning messages to emit
+// later (after the caller has configured output format and verbosity) so that
+// warnings are routed and suppressed correctly under --output json / --quiet.
+// An unreadable or invalid project config is an error rather than a warning,
+// since it may select the profile or environment the command runs against.
+// For the same reason a project config's base_url is refused unless the
+// project-base-url default is set, and a warning names the project config
+// whenever it changes the profile, environment or API URL.
+func applyDefaultSettings(cmd *cobra.Command) ([]string, error) {
+	config.ResetSettingOrigins()
+	for _, flag := range []string{"profile", "env", "base-url", "output", "no-color", "quiet", "verbose", "no-pager"} {
+		if cmd.Flags().Changed(flag) {
+			config.SetSettingOrigin(flag, config.OriginFlag)
+		}
+	}
+
+	// The project config is applied before the saved defaults, so a flag it
+	// sets counts as changed and is not overwritten by a default.
+	var project *config.ProjectConfig
+	if wd, err := os.Getwd(); err == nil {
+		project, err = config.FindProjectConfig(wd)
+		if err != nil {
+			return nil, exitcodes.NewUsageError(err)
+		}
+	}
+	manager, managerErr := config.NewConfigManager()
+
+	var warnings []string
+	config.SetActiveProject(project)
+	var settings utils.ProjectSettings
+	if project != nil {
+		if project.BaseURL != "" && !cmd.Flags().Changed("base-url") && !projectBaseURLAllowed(manager, managerErr) {
+			return nil, exitcodes.NewUsageError(fmt.Errorf("project config %s sets base_url, which is only used once allowed with 'megaport-cli config set-default project-base-url true'", project.Path))
+		}
+		var overrides []string
+		applyProject := func(flag, value string) {
+			if value == "" || cmd.Flags().Changed(flag) {
+				return
+			}
+			// Setting a string flag cannot fail.
+			_ = cmd.Flags().Set(flag, value)
+			config.SetSettingOrigin(flag, config.OriginProject)
+			if flag != "output" {
+				overrides = append(overrides, fmt.Sprintf("%s %s", flag, value))
+			}
+		}
+		applyProject("profile", project.Profile)
+		applyProject("env", project.Env)
+		applyProject("base-url", project.BaseURL)
+		applyProject("output", project.Output)
+		if len(overrides) > 0 {
+			warnings = append(warnings, fmt.Sprintf("Using %s from project config %s", strings.Join(overrides, ", "), project.Path))
+		}
+		settings = utils.ProjectSettings{ResourceTags: project.ResourceTags, Variables: project.Variables}
+	}
+	// Commands read the project's resource tags and variables from their context.
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd.SetContext(utils.WithProjectSettings(ctx, settings))
+
+	if managerErr != nil {
+		return append(warnings, fmt.Sprintf("Could not load saved default settings: %v", managerErr)), nil
 	}
 
 	var failed []string
@@ -198,6 +259,7 @@ func applyDefaultSettings(cmd *cobra.Command) []string {
 			failed = append(failed, flag)
 			return
 		}
+		config.SetSettingOrigin(flag, config.OriginDefault)
 		if target != nil {
 			*target = boolVal
 		}
@@ -217,7 +279,9 @@ func applyDefaultSettings(cmd *cobra.Command) []string {
 		}
 		if setErr := cmd.Flags().Set(flag, strVal); setErr != nil {
 			failed = append(failed, flag)
+			return
 		}
+		config.SetSettingOrigin(flag, config.OriginDefault)
 	}
 
 	// Capture which flags the user set on the CLI before applying defaults,
@@ -233,7 +297,6 @@ func applyDefaultSettings(cmd *cobra.Command) []string {
 	applyBool("verbose", &verbose)
 	applyBool("no-pager", &noPager)
 
-	var warnings []string
 	if len(failed) > 0 {
 		warnings = append(warnings, fmt.Sprintf("Could not apply saved defaults for: %s", strings.Join(failed, ", ")))
 	}
@@ -260,7 +323,20 @@ func applyDefaultSettings(cmd *cobra.Command) []string {
 		warnings = append(warnings, fmt.Sprintf("Saved defaults set both --quiet and --verbose; dropping --%s", dropped))
 	}
 
-	return warnings
+	return warnings, nil
+}
+
+// projectBaseURLAllowed reports whether the user has opted in to a project
+// config setting the API URL, through the project-base-url saved default.
+// Without it a .megaport.yaml in a cloned repository could send the user's
+// credentials to a server of its choosing.
+func projectBaseURLAllowed(manager *config.ConfigManager, err error) bool {
+	if err != nil {
+		return false
+	}
+	val, exists := manager.GetDefault("project-base-url")
+	allowed, ok := val.(bool)
+	return exists && ok && allowed
 }
 
 // ExecuteWithArgs adds all child commands to the root command and executes with given args.
@@ -9,7 +9,11 @@ import (
 	"github.com/megaport/megaport-cli/internal/commands/billing_market"
 	"github.com/megaport/megaport-cli/internal/commands/completion"
 	"github.com/megaport/megaport-cli/internal/commands/config"
+	"github.com/megaport/megaport-cli/internal/commands/destroy"
+	"github.com/megaport/megaport-cli/internal/commands/drift"
+	"github.com/megaport/megaport-cli/internal/commands/exporter"
 	"github.com/megaport/megaport-cli/internal/commands/generate_docs"
+	import_config "github.com/megaport/megaport-cli/internal/commands/import_config"
 	"github.com/megaport/megaport-cli/internal/commands/ix"
 	"github.com/megaport/megaport-cli/internal/commands/locations"
 	"github.com/megaport/megaport-cli/internal/commands/managed_account"
@@ -17,6 +21,7 @@ import (
 	"github.com/megaport/megaport-cli/internal/commands/mve"
 	nat_gateway "github.com/megaport/megaport-cli/internal/commands/nat_gateway"
 	"github.com/megaport/megaport-cli/internal/commands/partners"
+	"github.com/megaport/megaport-cli/internal/commands/plan"
 	"github.com/megaport/megaport-cli/internal/commands/ports"
 	"github.com/megaport/megaport-cli/internal/commands/product"
 	"github.com/megaport/megaport-cli/internal/commands/servicekeys"
@@ -74,6 +79,11 @@ func registerModules() {
 	moduleRegistry.Register(status.NewModule())
 	moduleRegistry.Register(topology.NewModule())
 	moduleRegistry.Register(apply.NewModule())
+	moduleRegistry.Register(plan.NewModule())
+	moduleRegistry.Register(destroy.NewModule())
+	moduleRegistry.Register(import_config.NewModule())
+	moduleRegistry.Register(drift.NewModule())
+	moduleRegistry.Register(exporter.NewModule())
 }
 
 // InitializeCommon performs initialization steps common to all platforms
@@ -91,6 +101,7 @@ func InitializeCommon() {
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colorful output")
 	rootCmd.PersistentFlags().StringVar(&utils.Env, "env", "", "Environment to use (prod, dev, or staging)")
 	rootCmd.PersistentFlags().StringVar(&utils.ProfileOverride, "profile", "", "Use a specific config profile for this command")
+	rootCmd.PersistentFlags().StringVar(&utils.ManagedAccount, "managed-account", "", "Act on behalf of a managed account (company UID or name); 'none' overrides a profile default")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress informational output, only show errors and data")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show additional debug information")
 	rootCmd.PersistentFlags().Duration("timeout", 0, "Timeout for the operation (e.g., 30s, 2m, 5m); must be positive. Omit to use each command's built-in default (see the command's own help)")
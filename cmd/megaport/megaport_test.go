@@ -3,6 +3,7 @@
 package megaport
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -12,6 +13,7 @@ import (
 	"github.com/megaport/megaport-cli/internal/base/exitcodes"
 	"github.com/megaport/megaport-cli/internal/base/output"
 	"github.com/megaport/megaport-cli/internal/commands/config"
+	"github.com/megaport/megaport-cli/internal/utils"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -169,7 +171,8 @@ func TestApplyDefaultSettings_WarnsOnConfigLoadFailure(t *testing.T) {
 		t.Fatal("expected NewConfigManager to fail when config dir cannot be created")
 	}
 
-	warnings := applyDefaultSettings(rootCmd)
+	warnings, err := applyDefaultSettings(rootCmd)
+	require.NoError(t, err)
 	require.Len(t, warnings, 1)
 	assert.Contains(t, warnings[0], "Could not load saved default settings")
 }
@@ -196,7 +199,8 @@ func TestApplyDefaultSettings_ResolvesQuietVerboseConflict(t *testing.T) {
 		_ = rootCmd.PersistentFlags().Set("verbose", "false")
 	}()
 
-	warnings := applyDefaultSettings(rootCmd)
+	warnings, err := applyDefaultSettings(rootCmd)
+	require.NoError(t, err)
 
 	assert.True(t, verbose, "verbose should remain set (safer default — unexpected output surfaces problems)")
 	assert.False(t, quiet, "quiet should be dropped to resolve conflict")
@@ -233,7 +237,8 @@ func TestApplyDefaultSettings_CLIVerboseOverridesConfigQuiet(t *testing.T) {
 	require.NoError(t, rootCmd.PersistentFlags().Set("verbose", "true"))
 	verbose = true
 
-	_ = applyDefaultSettings(rootCmd)
+	_, err = applyDefaultSettings(rootCmd)
+	require.NoError(t, err)
 
 	assert.True(t, verbose, "CLI-set --verbose should win over config quiet")
 	assert.False(t, quiet, "config-sourced quiet should be dropped when CLI set verbose")
@@ -351,3 +356,95 @@ func TestExitCodeFromError_CobraArgValidators(t *testing.T) {
 		})
 	}
 }
+
+// TestApplyDefaultSettings_ProjectConfig verifies that a .megaport.yaml found
+// above the working directory sits between saved defaults and CLI flags: it
+// overrides a saved default, a flag overrides it, and its default resource
+// tags and apply variables are passed to the command in its context.
+func TestApplyDefaultSettings_ProjectConfig(t *testing.T) {
+	t.Setenv("MEGAPORT_CONFIG_DIR", t.TempDir())
+	mgr, err := config.NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, mgr.SetDefault("output", "csv"))
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, config.ProjectConfigFile), []byte(
+		"profile: network-prod\nenv: staging\noutput: json\nresource_tags:\n  team: network\nvariables:\n  region: syd\n"), 0600))
+	nested := filepath.Join(root, "infra")
+	require.NoError(t, os.Mkdir(nested, 0755))
+	t.Chdir(nested)
+
+	defer func() {
+		output.ResetState()
+		for flag, value := range map[string]string{"output": "table", "profile": "", "env": ""} {
+			f := rootCmd.PersistentFlags().Lookup(flag)
+			_ = f.Value.Set(value)
+			f.Changed = false
+		}
+		config.SetActiveProject(nil)
+		config.ResetSettingOrigins()
+		rootCmd.SetContext(context.Background())
+	}()
+
+	require.NoError(t, rootCmd.PersistentFlags().Set("env", "development"))
+
+	warnings, err := applyDefaultSettings(rootCmd)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Using profile network-prod from project config " + filepath.Join(root, config.ProjectConfigFile)}, warnings,
+		"only the settings the project config applied are named")
+
+	assert.Equal(t, "json", outputFormat, "the project config overrides the saved default")
+	assert.Equal(t, "network-prod", utils.ProfileOverride)
+	assert.Equal(t, "development", utils.Env, "a CLI flag overrides the project config")
+	assert.Equal(t, utils.ProjectSettings{
+		ResourceTags: map[string]string{"team": "network"},
+		Variables:    map[string]interface{}{"region": "syd"},
+	}, utils.ProjectSettingsFromCmd(rootCmd))
+}
+
+// TestApplyDefaultSettings_ProjectBaseURL verifies that a project config's
+// base_url is refused until the user opts in with the project-base-url default.
+func TestApplyDefaultSettings_ProjectBaseURL(t *testing.T) {
+	t.Setenv("MEGAPORT_CONFIG_DIR", t.TempDir())
+	root := t.TempDir()
+	project := filepath.Join(root, config.ProjectConfigFile)
+	require.NoError(t, os.WriteFile(project, []byte("base_url: http://localhost:8080\n"), 0600))
+	t.Chdir(root)
+	defer func() {
+		f := rootCmd.PersistentFlags().Lookup("base-url")
+		_ = f.Value.Set("")
+		f.Changed = false
+		config.SetActiveProject(nil)
+		config.ResetSettingOrigins()
+	}()
+
+	_, err := applyDefaultSettings(rootCmd)
+	require.Error(t, err)
+	assert.Equal(t, exitcodes.Usage, exitCodeFromError(err))
+	assert.Contains(t, err.Error(), "config set-default project-base-url true")
+
+	mgr, err := config.NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, mgr.SetDefault("project-base-url", true))
+
+	warnings, err := applyDefaultSettings(rootCmd)
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080", rootCmd.PersistentFlags().Lookup("base-url").Value.String())
+	assert.Contains(t, warnings, "Using base-url http://localhost:8080 from project config "+project)
+}
+
+// TestApplyDefaultSettings_InvalidProjectConfig verifies that a project config
+// that cannot be parsed stops the command with a usage error rather than being
+// skipped, since it may select the account the command runs against.
+func TestApplyDefaultSettings_InvalidProjectConfig(t *testing.T) {
+	t.Setenv("MEGAPORT_CONFIG_DIR", t.TempDir())
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, config.ProjectConfigFile), []byte("profle: prod\n"), 0600))
+	t.Chdir(root)
+	defer config.SetActiveProject(nil)
+
+	_, err := applyDefaultSettings(rootCmd)
+	require.Error(t, err)
+	assert.Equal(t, exitcodes.Usage, exitCodeFromError(err))
+	assert.Contains(t, err.Error(), "field profle not found")
+}
@@ -27,6 +27,7 @@ import (
 // - completion: Shell completion is not applicable in browser environment
 // - generate-docs: Documentation generation is a development-time tool, not needed in WASM
 // - version: Version information is not applicable in browser WASM environment
+// - exporter: Serving metrics requires a network listener, which browsers cannot provide
 func registerModules() {
 	// Register only WASM-compatible modules
 	moduleRegistry.Register(ports.NewModule())
@@ -61,6 +61,16 @@ func (b *CommandBuilder) WithOutputFormatRunFunc(f func(*cobra.Command, []string
 	return b
 }
 
+// WithExtraOutputFormats lets --output take formats besides the common ones,
+// such as diagram formats; the command's run function renders them.
+func (b *CommandBuilder) WithExtraOutputFormats(formats ...string) *CommandBuilder {
+	if b.cmd.Annotations == nil {
+		b.cmd.Annotations = map[string]string{}
+	}
+	b.cmd.Annotations[utils.ExtraFormatsAnnotation] = strings.Join(formats, ",")
+	return b
+}
+
 // WithColorAwareRunFunc wraps the run function with color awareness
 func (b *CommandBuilder) WithColorAwareRunFunc(f func(*cobra.Command, []string, bool) error) *CommandBuilder {
 	b.cmd.RunE = utils.WrapColorAwareRunE(f)
@@ -8,6 +8,8 @@ const (
 	API            = 4
 	Cancelled      = 5
 	SessionExpired = 6
+	DriftDetected  = 7
+	PolicyViolated = 8
 )
 
 // CLIError wraps an error with a specific exit code.
@@ -29,6 +31,14 @@ func NewCancelledError(err error) *CLIError { return &CLIError{Code: Cancelled,
 // a WASM host's injected token is rejected by the API and re-injection is needed.
 func NewSessionExpiredError(err error) *CLIError { return &CLIError{Code: SessionExpired, Err: err} }
 
+// NewDriftError wraps err with the DriftDetected exit code, used when live
+// resources no longer match their apply config.
+func NewDriftError(err error) *CLIError { return &CLIError{Code: DriftDetected, Err: err} }
+
+// NewPolicyError wraps err with the PolicyViolated exit code, used when a
+// config breaks the rules of a policy file.
+func NewPolicyError(err error) *CLIError { return &CLIError{Code: PolicyViolated, Err: err} }
+
 // TypeName returns the string error type name for a given exit code.
 // Used when emitting structured JSON error output (--output json).
 func TypeName(code int) string {
@@ -43,6 +53,10 @@ func TypeName(code int) string {
 		return "cancelled"
 	case SessionExpired:
 		return "session_expired_error"
+	case DriftDetected:
+		return "drift_detected"
+	case PolicyViolated:
+		return "policy_violation"
 	default:
 		return "general_error"
 	}
@@ -55,6 +55,8 @@ func TestTypeName(t *testing.T) {
 		{API, "api_error"},
 		{Cancelled, "cancelled"},
 		{SessionExpired, "session_expired_error"},
+		{DriftDetected, "drift_detected"},
+		{PolicyViolated, "policy_violation"},
 		{99, "general_error"}, // unknown code → default
 	}
 	for _, tt := range tests {
@@ -73,6 +75,8 @@ func TestConstructors(t *testing.T) {
 		{"NewAPIError", NewAPIError(errors.New("500")), API},
 		{"NewCancelledError", NewCancelledError(errors.New("cancelled by user")), Cancelled},
 		{"NewSessionExpiredError", NewSessionExpiredError(errors.New("token rejected")), SessionExpired},
+		{"NewDriftError", NewDriftError(errors.New("drift detected")), DriftDetected},
+		{"NewPolicyError", NewPolicyError(errors.New("policy violated")), PolicyViolated},
 		{"New with General", New(General, errors.New("unknown")), General},
 	}
 
@@ -4,17 +4,23 @@ import "fmt"
 
 // FieldChange represents a single field that changed between two versions of a resource.
 type FieldChange struct {
-	Label    string
-	OldValue string
-	NewValue string
+	Label    string `json:"field"`
+	OldValue string `json:"old"`
+	NewValue string `json:"new"`
 }
 
 // DisplayChanges prints a formatted list of field changes for a resource update.
 // Only changes where OldValue != NewValue are displayed. If no changes are found,
 // a "No changes detected" message is printed.
 func DisplayChanges(changes []FieldChange, noColor bool) {
+	DisplayChangesWithHeading("Changes applied:", changes, noColor)
+}
+
+// DisplayChangesWithHeading is DisplayChanges with a caller-supplied heading,
+// for change lists that have not been applied yet (e.g. a plan).
+func DisplayChangesWithHeading(heading string, changes []FieldChange, noColor bool) {
 	fmt.Println()
-	PrintInfo("Changes applied:", noColor)
+	PrintInfo("%s", noColor, heading)
 
 	found := false
 	for _, c := range changes {
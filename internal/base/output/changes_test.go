@@ -41,6 +41,16 @@ func TestDisplayChanges(t *testing.T) {
 	})
 }
 
+func TestDisplayChangesWithHeading(t *testing.T) {
+	out := captureOutput(func() {
+		DisplayChangesWithHeading("Planned changes:", []FieldChange{
+			{Label: "rate_limit", OldValue: "100", NewValue: "200"},
+		}, true)
+	})
+	assert.Contains(t, out, "rate_limit:")
+	assert.Contains(t, out, "200")
+}
+
 func TestFormatBool(t *testing.T) {
 	assert.Equal(t, "Yes", FormatBool(true))
 	assert.Equal(t, "No", FormatBool(false))
@@ -26,6 +26,7 @@ type OutputConfig struct {
 	NoPager   bool
 	Format    string // "table"|"json"|"csv"|"xml"|"go-template"
 	Verbosity string // "normal"|"quiet"|"verbose"
+	NoColor   bool   // --no-color, for messages printed outside a command action
 }
 
 // defaultOutputConfig returns the baseline configuration used at startup and by ResetState.
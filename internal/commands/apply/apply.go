@@ -8,20 +8,70 @@ import (
 // AddCommandsTo builds the apply command and adds it to the root command.
 func AddCommandsTo(rootCmd *cobra.Command) {
 	cmd := cmdbuilder.NewCommand("apply", "Provision multiple resources from a config file").
-		WithLongDesc("Provision multiple Megaport resources (ports, MCRs, MVEs, VXCs) from a declarative YAML or JSON config file.\n\nResources are provisioned sequentially in dependency order: ports and MCRs first, then MVEs, then VXCs. VXC endpoints can reference previously provisioned resources using {{.type.name}} template syntax.\n\nThe --timeout flag bounds each resource's provisioning wait individually, not the whole run, so a large multi-resource apply can take longer in total than a single --timeout. A resource that is not ready within the timeout fails the apply (triggering rollback when --rollback-on-failure is set).").
+		WithLongDesc("Provision multiple Megaport resources (ports and LAG ports, MCRs, MVEs, NAT gateways, IXs, service keys and VXCs) from a declarative YAML or JSON config file.\n\nResources can reference each other using {{.type.name}} template syntax and are provisioned in dependency order, up to --parallelism at a time. Each provisioned resource is recorded in a state file next to the config, so re-running apply updates the resources it created in place instead of ordering them again; use plan to preview the changes.\n\nThe --timeout flag bounds each resource's provisioning wait individually, not the whole run, so a large multi-resource apply can take longer in total than a single --timeout. A resource that is not ready within the timeout fails the apply (triggering rollback when --rollback-on-failure is set).").
 		WithOutputFormatRunFunc(ApplyConfig).
 		WithFlagP("file", "f", "", "Path to config file (YAML or JSON)").
 		WithRequiredFlag("file", "Path to config file (YAML or JSON)").
 		WithBoolFlag("dry-run", false, "Validate all orders without provisioning").
 		WithBoolFlagP("yes", "y", false, "Skip confirmation prompt").
 		WithBoolFlag("rollback-on-failure", false, "Delete any resources created during this run if provisioning fails").
+		WithFlag("state", "", "Path to the apply state file (default: the config file path with a .state.json extension)").
+		WithBoolFlag("allow-replace", false, "Replace existing resources whose changed fields cannot be updated in place").
+		WithIntFlag("parallelism", 1, "Maximum number of resources to provision at the same time").
+		WithFlag("resume", "", "ID of a failed run to continue, waiting for its orders instead of placing them again").
+		WithStringArrayFlag("var", "Set a config variable as name=value (repeatable; overrides --var-file)").
+		WithStringArrayFlag("var-file", "Path to a YAML or JSON file of config variable values (repeatable)").
+		WithFlag("outputs-file", "", "Path to write the run's results and resolved outputs to, as JSON").
+		WithFlag("policy", "", "Path to a policy file whose rules the config must follow").
 		WithExample(`megaport-cli apply -f infrastructure.yaml`).
 		WithExample(`megaport-cli apply -f infrastructure.yaml --dry-run`).
 		WithExample(`megaport-cli apply -f infrastructure.yaml --yes`).
 		WithExample(`megaport-cli apply -f infrastructure.yaml --rollback-on-failure`).
 		WithExample(`megaport-cli apply -f infrastructure.json --output json`).
+		WithExample(`megaport-cli apply -f infrastructure.yaml --state ci/infrastructure.state.json`).
+		WithExample(`megaport-cli apply -f infrastructure.yaml --allow-replace`).
+		WithExample(`megaport-cli apply -f infrastructure.yaml --parallelism 8`).
+		WithExample(`megaport-cli apply -f infrastructure.yaml --resume 20260101T093000Z-K7RM2Q`).
+		WithExample(`megaport-cli apply -f region.yaml --var-file syd.yaml --var port_speed=10000 --state syd.state.json`).
+		WithExample(`megaport-cli apply -f infrastructure.yaml --yes --outputs-file outputs.json`).
+		WithExample(`megaport-cli apply -f infrastructure.yaml --policy policy.yaml`).
+		WithImportantNote("Changed fields that cannot be updated in place, such as a port's location_id or speed, require replacing the resource, which apply refuses unless --allow-replace is set").
+		WithImportantNote("--rollback-on-failure deletes resources ordered during the run; it does not revert in-place updates").
+		WithImportantNote("A config applied with different variables (e.g. once per region) needs its own --state for each set of values, or the runs will update each other's resources").
+		WithImportantNote("Keep the state file with the config (e.g. commit it or store it as a CI artifact); without it, apply cannot tell which resources it already created").
+		WithImportantNote("Config includes, variables, outputs and policy files are described in the apply guide (internal/commands/apply/apply.md)").
 		WithRootCmd(rootCmd).
 		Build()
 
+	schemaCmd := cmdbuilder.NewCommand("schema", "Print the JSON Schema of apply config files").
+		WithLongDesc("Print the JSON Schema (draft 2020-12) of the YAML and JSON config files that apply, plan, drift and destroy read, for editors and other tools to validate configs as they are written.\n\nThe schema describes a config file as written: its include list and variables block, and every resource field with its type. Fields the API limits, such as term, port and MCR speeds, MVE vendors and product sizes, and partner connect types, list the values the CLI accepts. Any value other than a plain string may instead be a single {{.var.name}} or ${env:NAME} reference.").
+		WithColorAwareRunFunc(SchemaConfig).
+		WithFlagP("file", "f", "", "Path to write the schema to (default: stdout)").
+		WithExample(`megaport-cli apply schema > apply.schema.json`).
+		WithExample(`megaport-cli apply schema -f apply.schema.json`).
+		WithImportantNote("The schema is generated from this version of the CLI; regenerate it after upgrading").
+		WithRootCmd(rootCmd).
+		Build()
+
+	lintCmd := cmdbuilder.NewCommand("lint", "Check a config file for mistakes without logging in").
+		WithLongDesc("Check a YAML or JSON config file for the mistakes apply would otherwise only report when it runs, without logging in or contacting the API, so editors, pre-commit hooks and CI can catch them early.\n\nThe config is checked against the schema (see apply schema), with each problem reported at its path in the config, e.g. ports[0].term, and then given the client-side checks apply makes before ordering. Lint exits with code 2 when it finds a problem.").
+		WithOutputFormatRunFunc(LintConfig).
+		WithFlagP("file", "f", "", "Path to config file (YAML or JSON)").
+		WithRequiredFlag("file", "Path to config file (YAML or JSON)").
+		WithStringArrayFlag("var", "Set a config variable as name=value (repeatable; overrides --var-file)").
+		WithStringArrayFlag("var-file", "Path to a YAML or JSON file of config variable values (repeatable)").
+		WithFlag("policy", "", "Path to a policy file whose rules the config must follow (see apply)").
+		WithExample(`megaport-cli apply lint -f infrastructure.yaml`).
+		WithExample(`megaport-cli apply lint -f region.yaml --var-file syd.yaml`).
+		WithExample(`megaport-cli apply lint -f infrastructure.yaml --output json`).
+		WithExample(`megaport-cli apply lint -f infrastructure.yaml --policy policy.yaml`).
+		WithImportantNote("The client-side checks cover each order's validation rules, {{.type.name}} references to undeclared entries, reference cycles, outputs that reference undeclared entries or unknown attributes, and entries of the same type with the same name").
+		WithImportantNote("With --policy, breaking a rule of the policy file (see apply) is reported as a problem too").
+		WithImportantNote("Lint cannot catch what only the API checks, such as a location that does not offer a speed; use apply --dry-run for those").
+		WithImportantNote("A variable declared with no value must be set with --var or --var-file, as for apply").
+		WithRootCmd(rootCmd).
+		Build()
+
+	cmd.AddCommand(schemaCmd, lintCmd)
 	rootCmd.AddCommand(cmd)
 }
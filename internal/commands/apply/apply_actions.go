@@ -1,56 +1,42 @@
 package apply
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
-	"os"
-	"path/filepath"
-	"regexp"
+	"maps"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/megaport/megaport-cli/internal/base/exitcodes"
 	"github.com/megaport/megaport-cli/internal/base/output"
 	"github.com/megaport/megaport-cli/internal/commands/config"
 	"github.com/megaport/megaport-cli/internal/commands/mve"
+	"github.com/megaport/megaport-cli/internal/infra"
 	"github.com/megaport/megaport-cli/internal/utils"
 	"github.com/megaport/megaport-cli/internal/validation"
 	megaport "github.com/megaport/megaportgo"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
-const (
-	defaultWaitTime = 10 * time.Minute
-	statusError     = "error"
-
-	// maxConfigFileSize caps how much of a config file is read into memory. Real
-	// infra configs are kilobytes; this guards against pointing --file at a huge
-	// or pathological file (e.g. /dev/zero) and exhausting memory.
-	maxConfigFileSize = 10 * 1024 * 1024
-)
-
-// templateRe matches {{.type.name}} references in config values.
-var templateRe = regexp.MustCompile(`\{\{\.(\w+)\.([^}]+)\}\}`)
-
 // deleteCLICommand maps resource type to the CLI subcommand used to delete it.
 var deleteCLICommand = map[string]string{
-	"Port": "ports",
-	"MCR":  "mcr",
-	"MVE":  "mve",
-	"VXC":  "vxc",
+	"Port":        "ports",
+	"MCR":         "mcr",
+	"MVE":         "mve",
+	"NAT Gateway": "nat-gateway",
+	"IX":          "ix",
+	"VXC":         "vxc",
 }
 
-// createdResource records a resource that was successfully provisioned during an apply run.
-type createdResource struct {
-	resType string // "Port", "MCR", "MVE", or "VXC"
-	name    string
-	uid     string
+// removeCommand returns the CLI command that removes a resource by hand.
+// Service keys cannot be deleted, only deactivated.
+func removeCommand(resType, uid string) string {
+	if resType == "Service Key" {
+		return "megaport-cli servicekeys update " + uid + " --active=false"
+	}
+	return fmt.Sprintf("megaport-cli %s delete %s", deleteCLICommand[resType], uid)
 }
 
 // ApplyConfig is the entry point for `megaport-cli apply`.
@@ -62,21 +48,60 @@ func ApplyConfig(cmd *cobra.Command, _ []string, noColor bool, outputFormat stri
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	yes, _ := cmd.Flags().GetBool("yes")
 	rollback, _ := cmd.Flags().GetBool("rollback-on-failure")
+	allowReplace, _ := cmd.Flags().GetBool("allow-replace")
+	statePath, _ := cmd.Flags().GetString("state")
+	parallelism, _ := cmd.Flags().GetInt("parallelism")
+	resume, _ := cmd.Flags().GetString("resume")
+	outputsPath, _ := cmd.Flags().GetString("outputs-file")
+	policyPath, _ := cmd.Flags().GetString("policy")
 
 	if filePath == "" {
 		output.PrintError("--file is required", noColor)
 		return exitcodes.NewUsageError(fmt.Errorf("--file is required"))
 	}
+	if parallelism < 1 {
+		output.PrintError("--parallelism must be at least 1", noColor)
+		return exitcodes.NewUsageError(fmt.Errorf("--parallelism must be at least 1"))
+	}
+	if statePath == "" {
+		statePath = infra.DefaultStatePath(filePath)
+	}
+	journal := newRunJournal(statePath, filePath, noColor)
+	if resume != "" {
+		var err error
+		journal, err = openRunJournal(statePath, filePath, resume, noColor)
+		if err != nil {
+			output.PrintError("Cannot resume run: %v", noColor, err)
+			return exitcodes.NewUsageError(err)
+		}
+	}
 
-	cfg, err := parseConfigFile(filePath)
+	vars, err := infra.ConfigVars(cmd)
+	if err != nil {
+		output.PrintError("Invalid variables: %v", noColor, err)
+		return exitcodes.NewUsageError(err)
+	}
+	project := utils.ProjectSettingsFromCmd(cmd)
+	cfg, err := infra.ParseConfigFile(filePath, vars, project)
 	if err != nil {
 		output.PrintError("Failed to parse config file: %v", noColor, err)
 		return err
 	}
+	nodes, err := buildGraph(cfg)
+	if err == nil {
+		err = checkOutputs(cfg)
+	}
+	if err != nil {
+		output.PrintError("Invalid config file: %v", noColor, err)
+		return exitcodes.NewUsageError(err)
+	}
+	if err := infra.EnforcePolicy(policyPath, cfg, outputFormat, noColor); err != nil {
+		return err
+	}
 
 	// provisionTimeout is the per-resource provisioning budget; rollbackTimeout
 	// reuses it for a fresh rollback context.
-	provisionTimeout := utils.TimeoutFromCmd(cmd, defaultWaitTime)
+	provisionTimeout := utils.TimeoutFromCmd(cmd, infra.DefaultWaitTime)
 	rollbackTimeout := provisionTimeout
 
 	// No run-wide deadline: each resource's provisioning wait applies
@@ -96,391 +121,757 @@ func ApplyConfig(cmd *cobra.Command, _ []string, noColor bool, outputFormat stri
 	spinner.Stop()
 
 	if dryRun {
-		return validateAll(ctx, client, cfg, noColor, outputFormat)
+		return validateAll(ctx, client, cfg, project.ResourceTags, noColor, outputFormat)
 	}
 
-	total := len(cfg.Ports) + len(cfg.MCRs) + len(cfg.MVEs) + len(cfg.VXCs)
+	total := len(cfg.Ports) + len(cfg.MCRs) + len(cfg.MVEs) + len(cfg.NATGateways) + len(cfg.IXs) + len(cfg.ServiceKeys) + len(cfg.VXCs)
 	if total == 0 {
 		output.PrintInfo("Config file contains no resources to provision.", noColor)
 		return nil
 	}
 
-	if !yes {
+	st, err := infra.OpenStateFile(statePath)
+	if err != nil {
+		output.PrintError("Failed to load apply state: %v", noColor, err)
+		return err
+	}
+	if runs := unfinishedRuns(statePath); resume == "" && len(runs) > 0 {
+		output.PrintWarning("Earlier run(s) did not finish: %s. Re-run with --resume <run-id> to continue one instead of starting a new run.", noColor, strings.Join(runs, ", "))
+	}
+	// existing holds config entries whose state UID still refers to an active
+	// resource; those are skipped rather than ordered again.
+	existing, err := findExistingResources(ctx, client, cfg, st, noColor)
+	if err != nil {
+		output.PrintError("Failed to check existing resources: %v", noColor, err)
+		return err
+	}
+	pending := diffExisting(cfg, existing)
+	if replacements := pending.replacements(); len(replacements) > 0 && !allowReplace {
+		output.PrintError("The following changes cannot be made in place and require replacing the resource:", noColor)
+		for _, r := range replacements {
+			output.PrintError("  %s", noColor, r)
+		}
+		output.PrintError("Revert these fields in the config, or re-run with --allow-replace to order replacements and delete the old resources.", noColor)
+		return exitcodes.NewUsageError(fmt.Errorf("%d resource(s) require replacement; re-run with --allow-replace to replace them", len(replacements)))
+	}
+	skipped := pending.count(infra.PlanNoOp)
+	if skipped == total {
+		output.PrintInfo("All %d resource(s) in the config already exist and are up to date; nothing to provision.", noColor, total)
+	}
+
+	if !yes && skipped < total {
 		output.PrintInfo("Resources to provision:", noColor)
 		output.PrintInfo("  Ports: %d, MCRs: %d, MVEs: %d, VXCs: %d", noColor,
-			len(cfg.Ports), len(cfg.MCRs), len(cfg.MVEs), len(cfg.VXCs))
+			len(cfg.Ports)-len(existing["port"]), len(cfg.MCRs)-len(existing["mcr"]),
+			len(cfg.MVEs)-len(existing["mve"]), len(cfg.VXCs)-len(existing["vxc"]))
+		if len(cfg.NATGateways)+len(cfg.IXs)+len(cfg.ServiceKeys) > 0 {
+			output.PrintInfo("  NAT gateways: %d, IXs: %d, service keys: %d", noColor,
+				len(cfg.NATGateways)-len(existing["nat_gateway"]), len(cfg.IXs)-len(existing["ix"]),
+				len(cfg.ServiceKeys)-len(existing["service_key"]))
+		}
+		if n := pending.count(infra.PlanUpdate); n > 0 {
+			output.PrintInfo("  To update in place: %d", noColor, n)
+		}
+		if n := pending.count(infra.PlanReplace); n > 0 {
+			output.PrintInfo("  To replace (new order, old resource deleted afterwards): %d", noColor, n)
+		}
+		if skipped > 0 {
+			output.PrintInfo("  Already provisioned (skipped): %d", noColor, skipped)
+		}
 		if !utils.ConfirmPrompt("Proceed with provisioning?", noColor) {
 			return exitcodes.New(exitcodes.Cancelled, fmt.Errorf("cancelled by user"))
 		}
 	}
 
-	// uids["port"]["Sydney-Primary"] = "provisioned-uid"
-	uids := map[string]map[string]string{
-		"port": {},
-		"mcr":  {},
-		"mve":  {},
-		"vxc":  {},
+	run := &applyRun{
+		client:           client,
+		st:               st,
+		existing:         existing,
+		provisionTimeout: provisionTimeout,
+		noColor:          noColor,
+		defaultTags:      project.ResourceTags,
+		progress:         &progress{parallel: parallelism > 1, noColor: noColor, total: total},
+		journal:          journal,
+		uids:             infra.NewTypeMap[string](),
+		// A resumed run finishes what the orders of the run it resumes left
+		// for the end of the run.
+		created: journal.carried(st),
+	}
+	results, runErr := runGraph(nodes, parallelism, func(n graphNode) (infra.Result, error) {
+		return run.apply(ctx, cfg, n)
+	})
+	if runErr != nil {
+		err := handleFailure(client, st, run.created, results, outputFormat, noColor, rollback, rollbackTimeout, runErr)
+		resumable := !rollback && journal.fail(runErr)
+		if outputsPath != "" {
+			artifact := newApplyOutputs(filePath, results, runErr)
+			if resumable {
+				artifact.RunID = journal.id()
+			}
+			if werr := infra.WriteJSONFile(outputsPath, artifact, "outputs file"); werr != nil {
+				output.PrintWarning("Failed to write outputs file: %v", noColor, werr)
+			}
+		}
+		if !resumable {
+			journal.remove()
+			return err
+		}
+		if outputFormat == "json" {
+			return fmt.Errorf("%w; to continue this run, re-run apply with --resume %s", err, journal.id())
+		}
+		output.PrintInfo("To continue this run once the cause is fixed, re-run apply with --resume %s: orders already placed are waited for rather than placed again.", noColor, journal.id())
+		return err
 	}
-	var results []ApplyResult
-	var created []createdResource // tracks successfully provisioned resources for orphan reporting
 
-	// 1. Ports
-	for _, p := range cfg.Ports {
-		req := &megaport.BuyPortRequest{
-			Name:                  p.Name,
-			LocationId:            p.LocationID,
-			PortSpeed:             p.Speed,
-			Term:                  p.Term,
-			MarketPlaceVisibility: p.MarketplaceVisibility,
-			DiversityZone:         p.DiversityZone,
-			CostCentre:            p.CostCentre,
-			ResourceTags:          p.ResourceTags,
-			WaitForProvision:      false,
-		}
-		if err := validation.ValidatePortRequest(req); err != nil {
-			results = append(results, ApplyResult{Type: "Port", Name: p.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("validation failed for port %q: %w", p.Name, err))
-		}
-		validateSpinner := output.PrintResourceValidating("Port", noColor)
-		if err := client.PortService.ValidatePortOrder(ctx, req); err != nil {
-			validateSpinner.Stop()
-			results = append(results, ApplyResult{Type: "Port", Name: p.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("server-side validation failed for port %q: %w", p.Name, err))
+	lockCreated(client, run.created, noColor, rollbackTimeout)
+	deleteReplaced(client, st, noColor, rollbackTimeout)
+	journal.remove()
+
+	outputs, outputsErr := run.resolveOutputs(ctx, cfg)
+	if outputsPath != "" {
+		artifact := newApplyOutputs(filePath, results, outputsErr)
+		artifact.Outputs = outputs
+		if err := infra.WriteJSONFile(outputsPath, artifact, "outputs file"); err != nil {
+			output.PrintError("Failed to write outputs file: %v", noColor, err)
+			return err
 		}
-		validateSpinner.Stop()
+	}
+	if err := output.PrintOutput(results, outputFormat, noColor); err != nil {
+		return err
+	}
+	if outputsErr != nil {
+		output.PrintError("Failed to resolve outputs: %v", noColor, outputsErr)
+		return outputsErr
+	}
+	printOutputs(outputs, outputFormat, noColor)
+	return nil
+}
 
-		createSpinner := output.PrintResourceCreating("Port", p.Name, noColor)
-		var resp *megaport.BuyPortResponse
-		err = utils.WithOrderOnceRetry(ctx, func(ctx context.Context) error {
-			var e error
-			resp, e = client.PortService.BuyPort(ctx, req)
-			return e
-		})
-		if err != nil {
-			createSpinner.Stop()
-			err = utils.WrapAPIError(err, "Port", p.Name)
-			results = append(results, ApplyResult{Type: "Port", Name: p.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision port %q: %w", p.Name, err))
-		}
-		if resp == nil {
-			createSpinner.Stop()
-			err := fmt.Errorf("empty response from API")
-			results = append(results, ApplyResult{Type: "Port", Name: p.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision port %q: %w", p.Name, err))
-		}
-		if len(resp.TechnicalServiceUIDs) == 0 {
-			createSpinner.Stop()
-			err := fmt.Errorf("API returned no UID")
-			results = append(results, ApplyResult{Type: "Port", Name: p.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision port %q: %w", p.Name, err))
-		}
-		uid := resp.TechnicalServiceUIDs[0]
-		uids["port"][p.Name] = uid
-		// Track immediately: the order is placed and billing has started, even
-		// though provisioning has not completed. If the wait below fails, the
-		// resource must still be visible to rollback/orphan reporting.
-		created = append(created, createdResource{resType: "Port", name: p.Name, uid: uid})
-		if err := waitForProvision(ctx, provisionTimeout, "Port", p.Name, uid, func(ctx context.Context) (string, error) {
-			port, e := client.PortService.GetPort(ctx, uid)
-			if e != nil {
-				return "", e
-			}
-			if port == nil {
-				return "", fmt.Errorf("empty response from API")
-			}
-			return port.ProvisioningStatus, nil
-		}); err != nil {
-			createSpinner.Stop()
-			results = append(results, ApplyResult{Type: "Port", Name: p.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision port %q: %w", p.Name, err))
+// applyRun holds what the resources of one apply run share. Resources on
+// independent branches of the dependency graph are applied concurrently, so
+// the UID map and the created list are guarded by mu.
+type applyRun struct {
+	client           *megaport.Client
+	st               *infra.StateFile
+	existing         map[string]map[string]*infra.LiveResource
+	provisionTimeout time.Duration
+	noColor          bool
+	defaultTags      map[string]string // the project config's default resource tags, added to each order
+	progress         *progress
+	journal          *runJournal
+
+	mu      sync.Mutex
+	uids    map[string]map[string]string // uids["port"]["Sydney-Primary"] = "provisioned-uid"
+	created []infra.CreatedResource      // tracks successfully ordered resources for rollback and orphan reporting
+}
+
+// apply provisions or updates the config entry n stands for.
+func (r *applyRun) apply(ctx context.Context, cfg *infra.Config, n graphNode) (infra.Result, error) {
+	var res infra.Result
+	var err error
+	switch {
+	case r.resumable(n):
+		res, err = r.resume(ctx, n)
+	case n.resType == "port":
+		res, err = r.applyPort(ctx, cfg.Ports[n.index])
+	case n.resType == "mcr":
+		res, err = r.applyMCR(ctx, cfg.MCRs[n.index])
+	case n.resType == "mve":
+		res, err = r.applyMVE(ctx, cfg.MVEs[n.index])
+	case n.resType == "nat_gateway":
+		res, err = r.applyNATGateway(ctx, cfg.NATGateways[n.index])
+	case n.resType == "ix":
+		res, err = r.applyIX(ctx, cfg.IXs[n.index])
+	case n.resType == "service_key":
+		res, err = r.applyServiceKey(ctx, cfg.ServiceKeys[n.index])
+	case n.resType == "vxc":
+		res, err = r.applyVXC(ctx, cfg.VXCs[n.index])
+	default:
+		err = fmt.Errorf("unknown resource type %q", n.resType)
+		res = infra.Result{Type: n.resType, Name: n.name, Status: infra.StatusError + ": " + err.Error()}
+	}
+	if err == nil {
+		r.journal.provisioned(n.resType, n.name)
+	}
+	r.progress.finish(res)
+	return res, err
+}
+
+// setUID records the UID a config entry resolved to, for the {{.type.name}}
+// references of the entries that depend on it.
+func (r *applyRun) setUID(resType, name, uid string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.uids[resType][name] = uid
+}
+
+// resolve replaces {{.type.name}} references in s with the UIDs recorded so far.
+func (r *applyRun) resolve(s string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return infra.ResolveTemplates(s, r.uids)
+}
+
+// uidSnapshot returns a copy of the UIDs recorded so far.
+func (r *applyRun) uidSnapshot() map[string]map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	uids := make(map[string]map[string]string, len(r.uids))
+	for t, m := range r.uids {
+		uids[t] = maps.Clone(m)
+	}
+	return uids
+}
+
+// track records an order as soon as it is placed: billing has started even
+// though provisioning has not completed, so if the wait that follows fails,
+// the resource must still be visible to rollback, orphan reporting and the
+// state file.
+func (r *applyRun) track(c infra.CreatedResource) {
+	resType := infra.TemplateType(c.ResType)
+	r.mu.Lock()
+	r.uids[resType][c.Name] = c.UID
+	r.created = append(r.created, c)
+	r.mu.Unlock()
+	if c.Replaces != "" {
+		r.st.Replace(resType, c.Name, c.UID, c.Replaces, r.noColor)
+	} else {
+		r.st.Record(resType, c.Name, c.UID, r.noColor)
+	}
+	r.journal.ordered(c)
+}
+
+// update applies the in-place changes of an existing resource and returns its result.
+func (r *applyRun) update(ctx context.Context, resType, name, uid string, changes []output.FieldChange, update func(ctx context.Context) error) (infra.Result, error) {
+	status, err := applyUpdate(ctx, r.progress, resType, name, uid, changes, update)
+	if err != nil {
+		return failure(resType, name, uid, err, fmt.Errorf("failed to update %s %q: %w", resultNoun(resType), name, err))
+	}
+	return infra.Result{Type: resType, Name: name, UID: uid, Status: status}, nil
+}
+
+// failure returns the result of a resource that failed with err, and runErr,
+// the error the run reports for it.
+func failure(resType, name, uid string, err, runErr error) (infra.Result, error) {
+	return infra.Result{Type: resType, Name: name, UID: uid, Status: infra.StatusError + ": " + err.Error()}, runErr
+}
+
+// resultNoun names a resource type in error messages.
+func resultNoun(resType string) string {
+	switch resType {
+	case "Port":
+		return "port"
+	case "NAT Gateway":
+		return "NAT gateway"
+	case "Service Key":
+		return "service key"
+	default:
+		return resType
+	}
+}
+
+func (r *applyRun) applyPort(ctx context.Context, p infra.PortConfig) (infra.Result, error) {
+	var replaces string
+	if live, ok := r.existing["port"][p.Name]; ok {
+		changes := infra.PortChanges(p, live.Port, live.Tags)
+		if infra.ReplacedFields("port", changes) == nil {
+			r.setUID("port", p.Name, live.UID)
+			return r.update(ctx, "Port", p.Name, live.UID, changes, func(ctx context.Context) error {
+				return updatePort(ctx, r.client, p, live, changes, r.provisionTimeout)
+			})
 		}
-		createSpinner.Stop()
-		results = append(results, ApplyResult{Type: "Port", Name: p.Name, UID: uid, Status: "provisioned"})
-		output.PrintResourceCreated("Port", uid, noColor)
+		replaces = live.UID
+	}
+	req := portRequest(p, r.defaultTags)
+	if err := validatePortRequest(req); err != nil {
+		return failure("Port", p.Name, "", err, fmt.Errorf("validation failed for port %q: %w", p.Name, err))
 	}
+	validateSpinner := r.progress.validating("Port", p.Name)
+	if err := r.client.PortService.ValidatePortOrder(ctx, req); err != nil {
+		validateSpinner.Stop()
+		return failure("Port", p.Name, "", err, fmt.Errorf("server-side validation failed for port %q: %w", p.Name, err))
+	}
+	validateSpinner.Stop()
 
-	// 2. MCRs
-	for _, m := range cfg.MCRs {
-		if err := validation.ValidateIPSecTunnelCount(m.TunnelCount, true); err != nil {
-			results = append(results, ApplyResult{Type: "MCR", Name: m.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("validation failed for MCR %q: %w", m.Name, err))
-		}
-		req := &megaport.BuyMCRRequest{
-			Name:             m.Name,
-			LocationID:       m.LocationID,
-			PortSpeed:        m.Speed,
-			Term:             m.Term,
-			MCRAsn:           m.ASN,
-			DiversityZone:    m.DiversityZone,
-			CostCentre:       m.CostCentre,
-			ResourceTags:     m.ResourceTags,
-			AddOns:           mcrAddOns(m.TunnelCount),
-			WaitForProvision: false,
+	createSpinner := r.progress.creating("Port", p.Name)
+	defer createSpinner.Stop()
+	var resp *megaport.BuyPortResponse
+	err := utils.WithOrderOnceRetry(ctx, func(ctx context.Context) error {
+		var e error
+		resp, e = r.client.PortService.BuyPort(ctx, req)
+		return e
+	})
+	if err != nil {
+		err = utils.WrapAPIError(err, "Port", p.Name)
+		return failure("Port", p.Name, "", err, fmt.Errorf("failed to provision port %q: %w", p.Name, err))
+	}
+	if resp == nil {
+		err := fmt.Errorf("empty response from API")
+		return failure("Port", p.Name, "", err, fmt.Errorf("failed to provision port %q: %w", p.Name, err))
+	}
+	if len(resp.TechnicalServiceUIDs) == 0 {
+		err := fmt.Errorf("API returned no UID")
+		return failure("Port", p.Name, "", err, fmt.Errorf("failed to provision port %q: %w", p.Name, err))
+	}
+	// For a LAG the first UID is the primary port, which stands for the LAG.
+	uid := resp.TechnicalServiceUIDs[0]
+	r.track(infra.CreatedResource{ResType: "Port", Name: p.Name, UID: uid, Replaces: replaces, Lock: p.Locked != nil && *p.Locked})
+	if err := waitForProvision(ctx, r.provisionTimeout, "Port", p.Name, uid, func(ctx context.Context) (string, error) {
+		port, e := r.client.PortService.GetPort(ctx, uid)
+		if e != nil {
+			return "", e
 		}
-		if err := validation.ValidateMCRRequest(req); err != nil {
-			results = append(results, ApplyResult{Type: "MCR", Name: m.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("validation failed for MCR %q: %w", m.Name, err))
+		if port == nil {
+			return "", fmt.Errorf("empty response from API")
 		}
-		validateSpinner := output.PrintResourceValidating("MCR", noColor)
-		if err := client.MCRService.ValidateMCROrder(ctx, req); err != nil {
-			validateSpinner.Stop()
-			results = append(results, ApplyResult{Type: "MCR", Name: m.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("server-side validation failed for MCR %q: %w", m.Name, err))
+		return port.ProvisioningStatus, nil
+	}); err != nil {
+		return failure("Port", p.Name, "", err, fmt.Errorf("failed to provision port %q: %w", p.Name, err))
+	}
+	createSpinner.Stop()
+	r.progress.created("Port", uid)
+	return infra.Result{Type: "Port", Name: p.Name, UID: uid, Status: provisionedStatus(replaces)}, nil
+}
+
+func (r *applyRun) applyMCR(ctx context.Context, m infra.MCRConfig) (infra.Result, error) {
+	var replaces string
+	if live, ok := r.existing["mcr"][m.Name]; ok {
+		changes := infra.MCRChanges(m, live.MCR, live.Tags)
+		if infra.ReplacedFields("mcr", changes) == nil {
+			r.setUID("mcr", m.Name, live.UID)
+			return r.update(ctx, "MCR", m.Name, live.UID, changes, func(ctx context.Context) error {
+				return updateMCR(ctx, r.client, m, live, changes, r.provisionTimeout)
+			})
 		}
+		replaces = live.UID
+	}
+	if err := validation.ValidateIPSecTunnelCount(m.TunnelCount, true); err != nil {
+		return failure("MCR", m.Name, "", err, fmt.Errorf("validation failed for MCR %q: %w", m.Name, err))
+	}
+	pflReqs, err := prefixFilterListRequests("", m.PrefixFilterLists)
+	if err != nil {
+		return failure("MCR", m.Name, "", err, fmt.Errorf("validation failed for MCR %q: %w", m.Name, err))
+	}
+	req := mcrRequest(m, r.defaultTags)
+	if err := validation.ValidateMCRRequest(req); err != nil {
+		return failure("MCR", m.Name, "", err, fmt.Errorf("validation failed for MCR %q: %w", m.Name, err))
+	}
+	validateSpinner := r.progress.validating("MCR", m.Name)
+	if err := r.client.MCRService.ValidateMCROrder(ctx, req); err != nil {
 		validateSpinner.Stop()
+		return failure("MCR", m.Name, "", err, fmt.Errorf("server-side validation failed for MCR %q: %w", m.Name, err))
+	}
+	validateSpinner.Stop()
 
-		createSpinner := output.PrintResourceCreating("MCR", m.Name, noColor)
-		var resp *megaport.BuyMCRResponse
-		err = utils.WithOrderOnceRetry(ctx, func(ctx context.Context) error {
-			var e error
-			resp, e = client.MCRService.BuyMCR(ctx, req)
-			return e
-		})
-		if err != nil {
-			createSpinner.Stop()
-			err = utils.WrapAPIError(err, "MCR", m.Name)
-			results = append(results, ApplyResult{Type: "MCR", Name: m.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision MCR %q: %w", m.Name, err))
-		}
-		if resp == nil {
-			createSpinner.Stop()
-			err := fmt.Errorf("empty response from API")
-			results = append(results, ApplyResult{Type: "MCR", Name: m.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision MCR %q: %w", m.Name, err))
+	createSpinner := r.progress.creating("MCR", m.Name)
+	defer createSpinner.Stop()
+	var resp *megaport.BuyMCRResponse
+	err = utils.WithOrderOnceRetry(ctx, func(ctx context.Context) error {
+		var e error
+		resp, e = r.client.MCRService.BuyMCR(ctx, req)
+		return e
+	})
+	if err != nil {
+		err = utils.WrapAPIError(err, "MCR", m.Name)
+		return failure("MCR", m.Name, "", err, fmt.Errorf("failed to provision MCR %q: %w", m.Name, err))
+	}
+	if resp == nil {
+		err := fmt.Errorf("empty response from API")
+		return failure("MCR", m.Name, "", err, fmt.Errorf("failed to provision MCR %q: %w", m.Name, err))
+	}
+	uid := strings.TrimSpace(resp.TechnicalServiceUID)
+	if uid == "" {
+		err := fmt.Errorf("API returned empty UID")
+		return failure("MCR", m.Name, "", err, fmt.Errorf("failed to provision MCR %q: %w", m.Name, err))
+	}
+	r.track(infra.CreatedResource{ResType: "MCR", Name: m.Name, UID: uid, Replaces: replaces, Lock: m.Locked != nil && *m.Locked})
+	if err := waitForProvision(ctx, r.provisionTimeout, "MCR", m.Name, uid, func(ctx context.Context) (string, error) {
+		mcr, e := r.client.MCRService.GetMCR(ctx, uid)
+		if e != nil {
+			return "", e
 		}
-		uid := strings.TrimSpace(resp.TechnicalServiceUID)
-		if uid == "" {
-			createSpinner.Stop()
-			err := fmt.Errorf("API returned empty UID")
-			results = append(results, ApplyResult{Type: "MCR", Name: m.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision MCR %q: %w", m.Name, err))
-		}
-		uids["mcr"][m.Name] = uid
-		created = append(created, createdResource{resType: "MCR", name: m.Name, uid: uid})
-		if err := waitForProvision(ctx, provisionTimeout, "MCR", m.Name, uid, func(ctx context.Context) (string, error) {
-			mcr, e := client.MCRService.GetMCR(ctx, uid)
-			if e != nil {
-				return "", e
-			}
-			if mcr == nil {
-				return "", fmt.Errorf("empty response from API")
-			}
-			return mcr.ProvisioningStatus, nil
-		}); err != nil {
-			createSpinner.Stop()
-			results = append(results, ApplyResult{Type: "MCR", Name: m.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision MCR %q: %w", m.Name, err))
+		if mcr == nil {
+			return "", fmt.Errorf("empty response from API")
 		}
-		createSpinner.Stop()
-		results = append(results, ApplyResult{Type: "MCR", Name: m.Name, UID: uid, Status: "provisioned"})
-		output.PrintResourceCreated("MCR", uid, noColor)
+		return mcr.ProvisioningStatus, nil
+	}); err != nil {
+		return failure("MCR", m.Name, "", err, fmt.Errorf("failed to provision MCR %q: %w", m.Name, err))
+	}
+	for _, req := range pflReqs {
+		req.MCRID = uid
 	}
+	if err := createPrefixFilterLists(ctx, r.client, pflReqs); err != nil {
+		return failure("MCR", m.Name, uid, err, fmt.Errorf("failed to configure MCR %q: %w", m.Name, err))
+	}
+	createSpinner.Stop()
+	r.progress.created("MCR", uid)
+	return infra.Result{Type: "MCR", Name: m.Name, UID: uid, Status: provisionedStatus(replaces)}, nil
+}
 
-	// 3. MVEs
-	for _, mv := range cfg.MVEs {
-		normalizedVC, err := normalizeVendorConfigMap(mv.VendorConfig)
-		if err != nil {
-			results = append(results, ApplyResult{Type: "MVE", Name: mv.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("invalid vendor_config for MVE %q: %w", mv.Name, err))
+func (r *applyRun) applyMVE(ctx context.Context, mv infra.MVEConfig) (infra.Result, error) {
+	var replaces string
+	if live, ok := r.existing["mve"][mv.Name]; ok {
+		changes := infra.MVEChanges(mv, live.MVE, live.Tags)
+		if infra.ReplacedFields("mve", changes) == nil {
+			r.setUID("mve", mv.Name, live.UID)
+			return r.update(ctx, "MVE", mv.Name, live.UID, changes, func(ctx context.Context) error {
+				return updateMVE(ctx, r.client, mv, live, changes, r.provisionTimeout)
+			})
 		}
-		vendorCfg, err := mve.ParseVendorConfig(normalizedVC)
-		if err != nil {
-			results = append(results, ApplyResult{Type: "MVE", Name: mv.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("invalid vendor_config for MVE %q: %w", mv.Name, err))
-		}
-		req := &megaport.BuyMVERequest{
-			Name:             mv.Name,
-			LocationID:       mv.LocationID,
-			Term:             mv.Term,
-			VendorConfig:     vendorCfg,
-			DiversityZone:    mv.DiversityZone,
-			CostCentre:       mv.CostCentre,
-			ResourceTags:     mv.ResourceTags,
-			WaitForProvision: false,
+		replaces = live.UID
+	}
+	req, err := mveRequest(mv, r.defaultTags)
+	if err != nil {
+		return failure("MVE", mv.Name, "", err, fmt.Errorf("invalid vendor_config for MVE %q: %w", mv.Name, err))
+	}
+	if err := validation.ValidateBuyMVERequest(req); err != nil {
+		return failure("MVE", mv.Name, "", err, fmt.Errorf("validation failed for MVE %q: %w", mv.Name, err))
+	}
+	validateSpinner := r.progress.validating("MVE", mv.Name)
+	if err := r.client.MVEService.ValidateMVEOrder(ctx, req); err != nil {
+		validateSpinner.Stop()
+		return failure("MVE", mv.Name, "", err, fmt.Errorf("server-side validation failed for MVE %q: %w", mv.Name, err))
+	}
+	validateSpinner.Stop()
+
+	createSpinner := r.progress.creating("MVE", mv.Name)
+	defer createSpinner.Stop()
+	var resp *megaport.BuyMVEResponse
+	err = utils.WithOrderOnceRetry(ctx, func(ctx context.Context) error {
+		var e error
+		resp, e = r.client.MVEService.BuyMVE(ctx, req)
+		return e
+	})
+	if err != nil {
+		err = utils.WrapAPIError(err, "MVE", mv.Name)
+		return failure("MVE", mv.Name, "", err, fmt.Errorf("failed to provision MVE %q: %w", mv.Name, err))
+	}
+	if resp == nil {
+		err := fmt.Errorf("empty response from API")
+		return failure("MVE", mv.Name, "", err, fmt.Errorf("failed to provision MVE %q: %w", mv.Name, err))
+	}
+	uid := strings.TrimSpace(resp.TechnicalServiceUID)
+	if uid == "" {
+		err := fmt.Errorf("API returned empty UID")
+		return failure("MVE", mv.Name, "", err, fmt.Errorf("failed to provision MVE %q: %w", mv.Name, err))
+	}
+	r.track(infra.CreatedResource{ResType: "MVE", Name: mv.Name, UID: uid, Replaces: replaces, Lock: mv.Locked != nil && *mv.Locked})
+	if err := waitForProvision(ctx, r.provisionTimeout, "MVE", mv.Name, uid, func(ctx context.Context) (string, error) {
+		m, e := r.client.MVEService.GetMVE(ctx, uid)
+		if e != nil {
+			return "", e
 		}
-		if err := validation.ValidateBuyMVERequest(req); err != nil {
-			results = append(results, ApplyResult{Type: "MVE", Name: mv.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("validation failed for MVE %q: %w", mv.Name, err))
+		if m == nil {
+			return "", fmt.Errorf("empty response from API")
 		}
-		validateSpinner := output.PrintResourceValidating("MVE", noColor)
-		if err := client.MVEService.ValidateMVEOrder(ctx, req); err != nil {
-			validateSpinner.Stop()
-			results = append(results, ApplyResult{Type: "MVE", Name: mv.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("server-side validation failed for MVE %q: %w", mv.Name, err))
+		return m.ProvisioningStatus, nil
+	}); err != nil {
+		return failure("MVE", mv.Name, "", err, fmt.Errorf("failed to provision MVE %q: %w", mv.Name, err))
+	}
+	createSpinner.Stop()
+	r.progress.created("MVE", uid)
+	return infra.Result{Type: "MVE", Name: mv.Name, UID: uid, Status: provisionedStatus(replaces)}, nil
+}
+
+// applyNATGateway creates a NAT gateway as a design, then validates and buys it.
+func (r *applyRun) applyNATGateway(ctx context.Context, n infra.NATGatewayConfig) (infra.Result, error) {
+	var replaces string
+	if live, ok := r.existing["nat_gateway"][n.Name]; ok {
+		changes := infra.NATGatewayChanges(n, live.NATGateway, live.Tags)
+		if infra.ReplacedFields("nat_gateway", changes) == nil {
+			r.setUID("nat_gateway", n.Name, live.UID)
+			return r.update(ctx, "NAT Gateway", n.Name, live.UID, changes, func(ctx context.Context) error {
+				return updateNATGateway(ctx, r.client, n, live, changes)
+			})
 		}
-		validateSpinner.Stop()
+		replaces = live.UID
+	}
+	req := natGatewayRequest(n, r.defaultTags)
+	if err := validation.ValidateCreateNATGatewayRequest(req); err != nil {
+		return failure("NAT Gateway", n.Name, "", err, fmt.Errorf("validation failed for NAT gateway %q: %w", n.Name, err))
+	}
 
-		createSpinner := output.PrintResourceCreating("MVE", mv.Name, noColor)
-		var resp *megaport.BuyMVEResponse
-		err = utils.WithOrderOnceRetry(ctx, func(ctx context.Context) error {
+	// A design recorded by an earlier run that stopped before buying it is
+	// brought in line with the config and bought, rather than designed again.
+	var uid string
+	if replaces == "" {
+		uid, _ = r.st.Lookup("nat_gateway", n.Name)
+	}
+	designSpinner := r.progress.creating("NAT Gateway", n.Name)
+	if uid == "" {
+		var gw *megaport.NATGateway
+		err := utils.WithOrderOnceRetry(ctx, func(ctx context.Context) error {
 			var e error
-			resp, e = client.MVEService.BuyMVE(ctx, req)
+			gw, e = r.client.NATGatewayService.CreateNATGateway(ctx, req)
 			return e
 		})
 		if err != nil {
-			createSpinner.Stop()
-			err = utils.WrapAPIError(err, "MVE", mv.Name)
-			results = append(results, ApplyResult{Type: "MVE", Name: mv.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision MVE %q: %w", mv.Name, err))
-		}
-		if resp == nil {
-			createSpinner.Stop()
+			designSpinner.Stop()
+			err = utils.WrapAPIError(err, "NAT Gateway", n.Name)
+			return failure("NAT Gateway", n.Name, "", err, fmt.Errorf("failed to create NAT gateway %q design: %w", n.Name, err))
+		}
+		if gw == nil {
+			designSpinner.Stop()
 			err := fmt.Errorf("empty response from API")
-			results = append(results, ApplyResult{Type: "MVE", Name: mv.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision MVE %q: %w", mv.Name, err))
+			return failure("NAT Gateway", n.Name, "", err, fmt.Errorf("failed to create NAT gateway %q design: %w", n.Name, err))
 		}
-		uid := strings.TrimSpace(resp.TechnicalServiceUID)
+		uid = strings.TrimSpace(gw.ProductUID)
 		if uid == "" {
-			createSpinner.Stop()
+			designSpinner.Stop()
 			err := fmt.Errorf("API returned empty UID")
-			results = append(results, ApplyResult{Type: "MVE", Name: mv.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision MVE %q: %w", mv.Name, err))
-		}
-		uids["mve"][mv.Name] = uid
-		created = append(created, createdResource{resType: "MVE", name: mv.Name, uid: uid})
-		if err := waitForProvision(ctx, provisionTimeout, "MVE", mv.Name, uid, func(ctx context.Context) (string, error) {
-			m, e := client.MVEService.GetMVE(ctx, uid)
-			if e != nil {
-				return "", e
-			}
-			if m == nil {
-				return "", fmt.Errorf("empty response from API")
-			}
-			return m.ProvisioningStatus, nil
-		}); err != nil {
-			createSpinner.Stop()
-			results = append(results, ApplyResult{Type: "MVE", Name: mv.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision MVE %q: %w", mv.Name, err))
+			return failure("NAT Gateway", n.Name, "", err, fmt.Errorf("failed to create NAT gateway %q design: %w", n.Name, err))
 		}
-		createSpinner.Stop()
-		results = append(results, ApplyResult{Type: "MVE", Name: mv.Name, UID: uid, Status: "provisioned"})
-		output.PrintResourceCreated("MVE", uid, noColor)
-	}
-
-	// 4. VXCs — resolve {{.type.name}} templates before provisioning
-	for _, v := range cfg.VXCs {
-		aUID, err := resolveTemplates(v.AEnd.ProductUID, uids)
-		if err != nil {
-			results = append(results, ApplyResult{Type: "VXC", Name: v.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("unresolved template in VXC %q a_end: %w", v.Name, err))
+		// A design does not bill, so it is not rollback's concern, but it is
+		// recorded so a failed run's next attempt buys it. A replacement's
+		// design waits until it is bought: the state still points at the
+		// resource it replaces.
+		if replaces == "" {
+			r.st.Record("nat_gateway", n.Name, uid, r.noColor)
 		}
-		bUID, err := resolveTemplates(v.BEnd.ProductUID, uids)
+	} else {
+		err := utils.WithRetry(ctx, func(ctx context.Context) error {
+			_, e := r.client.NATGatewayService.UpdateNATGateway(ctx, designUpdateRequest(uid, req))
+			return e
+		})
 		if err != nil {
-			results = append(results, ApplyResult{Type: "VXC", Name: v.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("unresolved template in VXC %q b_end: %w", v.Name, err))
-		}
-		req := &megaport.BuyVXCRequest{
-			PortUID:   aUID,
-			VXCName:   v.Name,
-			RateLimit: v.RateLimit,
-			Term:      v.Term,
-			AEndConfiguration: megaport.VXCOrderEndpointConfiguration{
-				ProductUID: aUID,
-				VLAN:       v.AEnd.VLAN,
-			},
-			BEndConfiguration: megaport.VXCOrderEndpointConfiguration{
-				ProductUID: bUID,
-				VLAN:       v.BEnd.VLAN,
-			},
-			CostCentre:       v.CostCentre,
-			ResourceTags:     v.ResourceTags,
-			WaitForProvision: false,
+			designSpinner.Stop()
+			return failure("NAT Gateway", n.Name, uid, err, fmt.Errorf("failed to update NAT gateway %q design: %w", n.Name, err))
 		}
-		if err := validation.ValidateVXCRequest(req); err != nil {
-			results = append(results, ApplyResult{Type: "VXC", Name: v.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("validation failed for VXC %q: %w", v.Name, err))
+	}
+	designSpinner.Stop()
+
+	validateSpinner := r.progress.validating("NAT Gateway", n.Name)
+	if _, err := r.client.NATGatewayService.ValidateNATGatewayOrder(ctx, uid); err != nil {
+		validateSpinner.Stop()
+		return failure("NAT Gateway", n.Name, uid, err, fmt.Errorf("server-side validation failed for NAT gateway %q: %w", n.Name, err))
+	}
+	validateSpinner.Stop()
+
+	buySpinner := r.progress.provisioning("NAT Gateway", n.Name)
+	defer buySpinner.Stop()
+	var bought *megaport.NATGatewayBuyResult
+	err := utils.WithOrderOnceRetry(ctx, func(ctx context.Context) error {
+		var e error
+		bought, e = r.client.NATGatewayService.BuyNATGateway(ctx, uid)
+		return e
+	})
+	if err != nil {
+		err = utils.WrapAPIError(err, "NAT Gateway", n.Name)
+		return failure("NAT Gateway", n.Name, uid, err, fmt.Errorf("failed to provision NAT gateway %q: %w", n.Name, err))
+	}
+	if bought == nil {
+		err := fmt.Errorf("empty response from API")
+		return failure("NAT Gateway", n.Name, uid, err, fmt.Errorf("failed to provision NAT gateway %q: %w", n.Name, err))
+	}
+	r.track(infra.CreatedResource{ResType: "NAT Gateway", Name: n.Name, UID: uid, Replaces: replaces, Lock: n.Locked != nil && *n.Locked})
+	if err := waitForProvision(ctx, r.provisionTimeout, "NAT Gateway", n.Name, uid, func(ctx context.Context) (string, error) {
+		gw, e := r.client.NATGatewayService.GetNATGateway(ctx, uid)
+		if e != nil {
+			return "", e
+		}
+		if gw == nil {
+			return "", fmt.Errorf("empty response from API")
 		}
-		validateSpinner := output.PrintResourceValidating("VXC", noColor)
-		if err := client.VXCService.ValidateVXCOrder(ctx, req); err != nil {
-			validateSpinner.Stop()
-			results = append(results, ApplyResult{Type: "VXC", Name: v.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("server-side validation failed for VXC %q: %w", v.Name, err))
+		return gw.ProvisioningStatus, nil
+	}); err != nil {
+		return failure("NAT Gateway", n.Name, "", err, fmt.Errorf("failed to provision NAT gateway %q: %w", n.Name, err))
+	}
+	buySpinner.Stop()
+	r.progress.created("NAT Gateway", uid)
+	return infra.Result{Type: "NAT Gateway", Name: n.Name, UID: uid, Status: provisionedStatus(replaces)}, nil
+}
+
+// applyIX resolves the IX's {{.port.name}} template before ordering it.
+func (r *applyRun) applyIX(ctx context.Context, x infra.IXConfig) (infra.Result, error) {
+	productUID, err := r.resolve(x.ProductUID)
+	if err != nil {
+		return failure("IX", x.Name, "", err, fmt.Errorf("unresolved template in IX %q product_uid: %w", x.Name, err))
+	}
+	var replaces string
+	if live, ok := r.existing["ix"][x.Name]; ok {
+		changes := infra.IXChanges(x, live.IX)
+		if infra.ReplacedFields("ix", changes) == nil {
+			r.setUID("ix", x.Name, live.UID)
+			return r.update(ctx, "IX", x.Name, live.UID, changes, func(ctx context.Context) error {
+				return updateIX(ctx, r.client, x, live, changes, r.provisionTimeout)
+			})
 		}
+		replaces = live.UID
+	}
+	req := ixRequest(x, productUID)
+	if err := validation.ValidateIXRequest(req); err != nil {
+		return failure("IX", x.Name, "", err, fmt.Errorf("validation failed for IX %q: %w", x.Name, err))
+	}
+	validateSpinner := r.progress.validating("IX", x.Name)
+	if err := r.client.IXService.ValidateIXOrder(ctx, req); err != nil {
 		validateSpinner.Stop()
+		return failure("IX", x.Name, "", err, fmt.Errorf("server-side validation failed for IX %q: %w", x.Name, err))
+	}
+	validateSpinner.Stop()
 
-		createSpinner := output.PrintResourceCreating("VXC", v.Name, noColor)
-		var resp *megaport.BuyVXCResponse
-		err = utils.WithOrderOnceRetry(ctx, func(ctx context.Context) error {
-			var e error
-			resp, e = client.VXCService.BuyVXC(ctx, req)
-			return e
-		})
-		if err != nil {
-			createSpinner.Stop()
-			err = utils.WrapAPIError(err, "VXC", v.Name)
-			results = append(results, ApplyResult{Type: "VXC", Name: v.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision VXC %q: %w", v.Name, err))
-		}
-		if resp == nil {
-			createSpinner.Stop()
-			err := fmt.Errorf("empty response from API")
-			results = append(results, ApplyResult{Type: "VXC", Name: v.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision VXC %q: %w", v.Name, err))
+	createSpinner := r.progress.creating("IX", x.Name)
+	defer createSpinner.Stop()
+	var resp *megaport.BuyIXResponse
+	err = utils.WithOrderOnceRetry(ctx, func(ctx context.Context) error {
+		var e error
+		resp, e = r.client.IXService.BuyIX(ctx, req)
+		return e
+	})
+	if err != nil {
+		err = utils.WrapAPIError(err, "IX", x.Name)
+		return failure("IX", x.Name, "", err, fmt.Errorf("failed to provision IX %q: %w", x.Name, err))
+	}
+	if resp == nil {
+		err := fmt.Errorf("empty response from API")
+		return failure("IX", x.Name, "", err, fmt.Errorf("failed to provision IX %q: %w", x.Name, err))
+	}
+	uid := strings.TrimSpace(resp.TechnicalServiceUID)
+	if uid == "" {
+		err := fmt.Errorf("API returned empty UID")
+		return failure("IX", x.Name, "", err, fmt.Errorf("failed to provision IX %q: %w", x.Name, err))
+	}
+	r.track(infra.CreatedResource{ResType: "IX", Name: x.Name, UID: uid, Replaces: replaces})
+	if err := waitForProvision(ctx, r.provisionTimeout, "IX", x.Name, uid, func(ctx context.Context) (string, error) {
+		ix, e := r.client.IXService.GetIX(ctx, uid)
+		if e != nil {
+			return "", e
 		}
-		uid := strings.TrimSpace(resp.TechnicalServiceUID)
-		if uid == "" {
-			createSpinner.Stop()
-			err := fmt.Errorf("API returned empty UID")
-			results = append(results, ApplyResult{Type: "VXC", Name: v.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision VXC %q: %w", v.Name, err))
-		}
-		uids["vxc"][v.Name] = uid
-		created = append(created, createdResource{resType: "VXC", name: v.Name, uid: uid})
-		if err := waitForProvision(ctx, provisionTimeout, "VXC", v.Name, uid, func(ctx context.Context) (string, error) {
-			vxc, e := client.VXCService.GetVXC(ctx, uid)
-			if e != nil {
-				return "", e
-			}
-			if vxc == nil {
-				return "", fmt.Errorf("empty response from API")
-			}
-			return vxc.ProvisioningStatus, nil
-		}); err != nil {
-			createSpinner.Stop()
-			results = append(results, ApplyResult{Type: "VXC", Name: v.Name, Status: statusError + ": " + err.Error()})
-			return handleFailure(client, created, results, outputFormat, noColor, rollback, rollbackTimeout,
-				fmt.Errorf("failed to provision VXC %q: %w", v.Name, err))
+		if ix == nil {
+			return "", fmt.Errorf("empty response from API")
 		}
-		createSpinner.Stop()
-		results = append(results, ApplyResult{Type: "VXC", Name: v.Name, UID: uid, Status: "provisioned"})
-		output.PrintResourceCreated("VXC", uid, noColor)
+		return ix.ProvisioningStatus, nil
+	}); err != nil {
+		return failure("IX", x.Name, "", err, fmt.Errorf("failed to provision IX %q: %w", x.Name, err))
 	}
+	createSpinner.Stop()
+	r.progress.created("IX", uid)
+	return infra.Result{Type: "IX", Name: x.Name, UID: uid, Status: provisionedStatus(replaces)}, nil
+}
 
-	return output.PrintOutput(results, outputFormat, noColor)
+// applyServiceKey creates a service key. The key is usable as soon as it is
+// created, so there is no provisioning to wait for.
+func (r *applyRun) applyServiceKey(ctx context.Context, k infra.ServiceKeyConfig) (infra.Result, error) {
+	productUID, err := r.resolve(k.ProductUID)
+	if err != nil {
+		return failure("Service Key", k.Name, "", err, fmt.Errorf("unresolved template in service key %q product_uid: %w", k.Name, err))
+	}
+	var replaces string
+	if live, ok := r.existing["service_key"][k.Name]; ok {
+		changes := infra.ServiceKeyChanges(k, live.ServiceKey, r.uidSnapshot())
+		if infra.ReplacedFields("service_key", changes) == nil {
+			r.setUID("service_key", k.Name, live.UID)
+			return r.update(ctx, "Service Key", k.Name, live.UID, changes, func(ctx context.Context) error {
+				return updateServiceKey(ctx, r.client, k, productUID, live)
+			})
+		}
+		replaces = live.UID
+	}
+	req := serviceKeyRequest(k, productUID)
+	if err := validation.ValidateCreateServiceKeyRequest(req); err != nil {
+		return failure("Service Key", k.Name, "", err, fmt.Errorf("validation failed for service key %q: %w", k.Name, err))
+	}
+
+	createSpinner := r.progress.creating("Service Key", k.Name)
+	var resp *megaport.CreateServiceKeyResponse
+	err = utils.WithOrderOnceRetry(ctx, func(ctx context.Context) error {
+		var e error
+		resp, e = r.client.ServiceKeyService.CreateServiceKey(ctx, req)
+		return e
+	})
+	createSpinner.Stop()
+	if err != nil {
+		err = utils.WrapAPIError(err, "Service Key", k.Name)
+		return failure("Service Key", k.Name, "", err, fmt.Errorf("failed to create service key %q: %w", k.Name, err))
+	}
+	if resp == nil {
+		err := fmt.Errorf("empty response from API")
+		return failure("Service Key", k.Name, "", err, fmt.Errorf("failed to create service key %q: %w", k.Name, err))
+	}
+	uid := strings.TrimSpace(resp.ServiceKeyUID)
+	if uid == "" {
+		err := fmt.Errorf("API returned empty key")
+		return failure("Service Key", k.Name, "", err, fmt.Errorf("failed to create service key %q: %w", k.Name, err))
+	}
+	r.track(infra.CreatedResource{ResType: "Service Key", Name: k.Name, UID: uid, Replaces: replaces})
+	r.progress.created("Service Key", uid)
+	return infra.Result{Type: "Service Key", Name: k.Name, UID: uid, Status: provisionedStatus(replaces)}, nil
+}
+
+// applyVXC resolves the VXC's {{.type.name}} templates before provisioning it.
+func (r *applyRun) applyVXC(ctx context.Context, v infra.VXCConfig) (infra.Result, error) {
+	aUID, err := r.resolve(v.AEnd.ProductUID)
+	if err != nil {
+		return failure("VXC", v.Name, "", err, fmt.Errorf("unresolved template in VXC %q a_end: %w", v.Name, err))
+	}
+	bUID, err := r.resolve(v.BEnd.ProductUID)
+	if err != nil {
+		return failure("VXC", v.Name, "", err, fmt.Errorf("unresolved template in VXC %q b_end: %w", v.Name, err))
+	}
+	// VXCs have no fields that need replacement: endpoint changes move the VXC.
+	if live, ok := r.existing["vxc"][v.Name]; ok {
+		r.setUID("vxc", v.Name, live.UID)
+		changes := infra.VXCChanges(v, live.VXC, live.Tags, r.uidSnapshot())
+		return r.update(ctx, "VXC", v.Name, live.UID, changes, func(ctx context.Context) error {
+			return updateVXC(ctx, r.client, v, aUID, bUID, live, changes, r.provisionTimeout)
+		})
+	}
+	req, err := infra.VXCRequest(ctx, r.client, v, aUID, bUID, r.defaultTags)
+	if err != nil {
+		return failure("VXC", v.Name, "", err, fmt.Errorf("invalid VXC %q: %w", v.Name, err))
+	}
+	if err := validation.ValidateVXCRequest(req); err != nil {
+		return failure("VXC", v.Name, "", err, fmt.Errorf("validation failed for VXC %q: %w", v.Name, err))
+	}
+	validateSpinner := r.progress.validating("VXC", v.Name)
+	if err := r.client.VXCService.ValidateVXCOrder(ctx, req); err != nil {
+		validateSpinner.Stop()
+		return failure("VXC", v.Name, "", err, fmt.Errorf("server-side validation failed for VXC %q: %w", v.Name, err))
+	}
+	validateSpinner.Stop()
+
+	createSpinner := r.progress.creating("VXC", v.Name)
+	defer createSpinner.Stop()
+	var resp *megaport.BuyVXCResponse
+	err = utils.WithOrderOnceRetry(ctx, func(ctx context.Context) error {
+		var e error
+		resp, e = r.client.VXCService.BuyVXC(ctx, req)
+		return e
+	})
+	if err != nil {
+		err = utils.WrapAPIError(err, "VXC", v.Name)
+		return failure("VXC", v.Name, "", err, fmt.Errorf("failed to provision VXC %q: %w", v.Name, err))
+	}
+	if resp == nil {
+		err := fmt.Errorf("empty response from API")
+		return failure("VXC", v.Name, "", err, fmt.Errorf("failed to provision VXC %q: %w", v.Name, err))
+	}
+	uid := strings.TrimSpace(resp.TechnicalServiceUID)
+	if uid == "" {
+		err := fmt.Errorf("API returned empty UID")
+		return failure("VXC", v.Name, "", err, fmt.Errorf("failed to provision VXC %q: %w", v.Name, err))
+	}
+	r.track(infra.CreatedResource{ResType: "VXC", Name: v.Name, UID: uid, Lock: v.Locked != nil && *v.Locked})
+	if err := waitForProvision(ctx, r.provisionTimeout, "VXC", v.Name, uid, func(ctx context.Context) (string, error) {
+		vxc, e := r.client.VXCService.GetVXC(ctx, uid)
+		if e != nil {
+			return "", e
+		}
+		if vxc == nil {
+			return "", fmt.Errorf("empty response from API")
+		}
+		return vxc.ProvisioningStatus, nil
+	}); err != nil {
+		return failure("VXC", v.Name, "", err, fmt.Errorf("failed to provision VXC %q: %w", v.Name, err))
+	}
+	createSpinner.Stop()
+	r.progress.created("VXC", uid)
+	return infra.Result{Type: "VXC", Name: v.Name, UID: uid, Status: "provisioned"}, nil
 }
 
 // handleFailure prints results, the failure error, and — when resources were already
 // provisioned — either attempts rollback or prints a prominent billing warning with
 // exact remediation commands.
-func handleFailure(client *megaport.Client, created []createdResource, results []ApplyResult, outputFormat string, noColor bool, rollback bool, rollbackTimeout time.Duration, failErr error) error {
+func handleFailure(client *megaport.Client, st *infra.StateFile, created []infra.CreatedResource, results []infra.Result, outputFormat string, noColor bool, rollback bool, rollbackTimeout time.Duration, failErr error) error {
 	_ = output.PrintOutput(results, outputFormat, noColor)
 
 	jsonMode := outputFormat == "json"
@@ -494,21 +885,21 @@ func handleFailure(client *megaport.Client, created []createdResource, results [
 	}
 
 	if rollback {
-		return doRollback(client, created, jsonMode, noColor, rollbackTimeout, failErr)
+		return doRollback(client, st, created, jsonMode, noColor, rollbackTimeout, failErr)
 	}
 
 	if jsonMode {
 		parts := []string{"resources created and ARE BILLING:"}
 		for _, r := range created {
-			parts = append(parts, fmt.Sprintf("%s %q uid: %s; to remove: megaport-cli %s delete %s", r.resType, r.name, r.uid, deleteCLICommand[r.resType], r.uid))
+			parts = append(parts, fmt.Sprintf("%s %q uid: %s; to remove: %s", r.ResType, r.Name, r.UID, removeCommand(r.ResType, r.UID)))
 		}
 		return fmt.Errorf("%w; %s", failErr, strings.Join(parts, "; "))
 	}
 
 	output.PrintError("The following resources were created and ARE BILLING:", noColor)
 	for _, r := range created {
-		output.PrintError("  %s %q  uid: %s", noColor, r.resType, r.name, r.uid)
-		output.PrintError("  To remove: megaport-cli %s delete %s", noColor, deleteCLICommand[r.resType], r.uid)
+		output.PrintError("  %s %q  uid: %s", noColor, r.ResType, r.Name, r.UID)
+		output.PrintError("  To remove: %s", noColor, removeCommand(r.ResType, r.UID))
 	}
 	return failErr
 }
@@ -519,7 +910,7 @@ func handleFailure(client *megaport.Client, created []createdResource, results [
 // expired — reusing it would make every delete fail with "context deadline
 // exceeded" and orphan the billing resources rollback exists to clean up. The
 // fresh context uses the same timeout the user configured for the run.
-func doRollback(client *megaport.Client, created []createdResource, jsonMode bool, noColor bool, rollbackTimeout time.Duration, failErr error) error {
+func doRollback(client *megaport.Client, st *infra.StateFile, created []infra.CreatedResource, jsonMode bool, noColor bool, rollbackTimeout time.Duration, failErr error) error {
 	ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
 	defer cancel()
 
@@ -529,20 +920,26 @@ func doRollback(client *megaport.Client, created []createdResource, jsonMode boo
 	var rollbackResults []string
 	for _, r := range slices.Backward(created) {
 		err := utils.WithRetry(ctx, func(ctx context.Context) error {
-			return deleteResource(ctx, client, r)
+			return infra.DeleteResource(ctx, client, r)
 		})
 		if err != nil {
 			if jsonMode {
-				rollbackResults = append(rollbackResults, fmt.Sprintf("rollback failed for %s %q (%s): %v; to remove: megaport-cli %s delete %s", r.resType, r.name, r.uid, err, deleteCLICommand[r.resType], r.uid))
+				rollbackResults = append(rollbackResults, fmt.Sprintf("rollback failed for %s %q (%s): %v; to remove: %s", r.ResType, r.Name, r.UID, err, removeCommand(r.ResType, r.UID)))
 			} else {
-				output.PrintError("Rollback failed for %s %q (%s): %v", noColor, r.resType, r.name, r.uid, err)
-				output.PrintError("  To remove manually: megaport-cli %s delete %s", noColor, deleteCLICommand[r.resType], r.uid)
+				output.PrintError("Rollback failed for %s %q (%s): %v", noColor, r.ResType, r.Name, r.UID, err)
+				output.PrintError("  To remove manually: %s", noColor, removeCommand(r.ResType, r.UID))
 			}
 		} else {
+			if r.Replaces != "" {
+				// The replaced resource was never deleted, so it is current again.
+				st.Restore(infra.TemplateType(r.ResType), r.Name, r.Replaces, noColor)
+			} else {
+				st.Forget(infra.TemplateType(r.ResType), r.Name, noColor)
+			}
 			if jsonMode {
-				rollbackResults = append(rollbackResults, fmt.Sprintf("rolled back %s %q (%s)", r.resType, r.name, r.uid))
+				rollbackResults = append(rollbackResults, fmt.Sprintf("rolled back %s %q (%s)", r.ResType, r.Name, r.UID))
 			} else {
-				output.PrintSuccess("Rolled back %s %q (%s)", noColor, r.resType, r.name, r.uid)
+				output.PrintSuccess("Rolled back %s %q (%s)", noColor, r.ResType, r.Name, r.UID)
 			}
 		}
 	}
@@ -562,23 +959,88 @@ func waitForProvision(ctx context.Context, timeout time.Duration, resType, name,
 	return utils.WaitForProvision(ctx, resType, name, uid, getStatus)
 }
 
-// deleteResource deletes a single provisioned resource via the appropriate service client.
-func deleteResource(ctx context.Context, client *megaport.Client, r createdResource) error {
-	switch r.resType {
-	case "Port":
-		_, err := client.PortService.DeletePort(ctx, &megaport.DeletePortRequest{PortID: r.uid, DeleteNow: true})
-		return err
-	case "MCR":
-		_, err := client.MCRService.DeleteMCR(ctx, &megaport.DeleteMCRRequest{MCRID: r.uid, DeleteNow: true})
-		return err
-	case "MVE":
-		_, err := client.MVEService.DeleteMVE(ctx, &megaport.DeleteMVERequest{MVEID: r.uid})
-		return err
-	case "VXC":
-		return client.VXCService.DeleteVXC(ctx, r.uid, &megaport.DeleteVXCRequest{DeleteNow: true})
-	default:
-		return fmt.Errorf("unknown resource type %q", r.resType)
+// findExistingResources looks up every config entry that has a UID in the apply
+// state and returns those that still exist and are active, keyed like the
+// template UID map. Entries whose resource was deleted or decommissioned are
+// dropped from the state so they are provisioned again.
+func findExistingResources(ctx context.Context, client *megaport.Client, cfg *infra.Config, st *infra.StateFile, noColor bool) (map[string]map[string]*infra.LiveResource, error) {
+	existing := infra.NewTypeMap[*infra.LiveResource]()
+	check := func(resType, name string, declaredTags map[string]string) error {
+		uid, ok := st.State.Lookup(resType, name)
+		if !ok {
+			return nil
+		}
+		var live *infra.LiveResource
+		err := utils.WithIdempotentRetry(ctx, func(ctx context.Context) error {
+			var e error
+			live, e = infra.GetResource(ctx, client, resType, uid)
+			return e
+		})
+		if err != nil && !infra.IsNotFound(err) {
+			return fmt.Errorf("looking up %s %q (%s) from apply state: %w", resType, name, uid, err)
+		}
+		if err == nil && live.Status() == megaport.STATUS_DESIGN {
+			// A NAT gateway design from a run that stopped before buying it. It
+			// stays in the state so this run buys it rather than designing another.
+			return nil
+		}
+		if err != nil || !utils.IsActiveStatus(live.Status()) {
+			output.PrintWarning("%s %q (%s) from apply state no longer exists; it will be provisioned again", noColor, resType, name, uid)
+			st.Forget(resType, name, noColor)
+			return nil
+		}
+		live.Tags, err = infra.LiveTags(ctx, declaredTags, uid, infra.TagLister(client, resType))
+		if err != nil {
+			return fmt.Errorf("listing resource tags for %s %q (%s): %w", resType, name, uid, err)
+		}
+		existing[resType][name] = live
+		return nil
 	}
+	for _, p := range cfg.Ports {
+		if err := check("port", p.Name, p.ResourceTags); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range cfg.MCRs {
+		if err := check("mcr", m.Name, m.ResourceTags); err != nil {
+			return nil, err
+		}
+	}
+	for _, mv := range cfg.MVEs {
+		if err := check("mve", mv.Name, mv.ResourceTags); err != nil {
+			return nil, err
+		}
+	}
+	for _, n := range cfg.NATGateways {
+		if err := check("nat_gateway", n.Name, n.ResourceTags); err != nil {
+			return nil, err
+		}
+	}
+	for _, x := range cfg.IXs {
+		if err := check("ix", x.Name, nil); err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range cfg.ServiceKeys {
+		if err := check("service_key", k.Name, nil); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range cfg.VXCs {
+		if err := check("vxc", v.Name, v.ResourceTags); err != nil {
+			return nil, err
+		}
+	}
+	return existing, nil
+}
+
+// validatePortRequest validates a port order, or a LAG order when it sets a
+// LAG count.
+func validatePortRequest(req *megaport.BuyPortRequest) error {
+	if req.LagCount > 0 {
+		return validation.ValidateLAGPortRequest(req)
+	}
+	return validation.ValidatePortRequest(req)
 }
 
 // mcrAddOns builds the MCR add-on list from an apply config. A tunnel count of
@@ -594,24 +1056,189 @@ func mcrAddOns(tunnelCount int) []megaport.MCRAddOn {
 	}}
 }
 
+// portRequest builds the order for a port, or for a LAG when it sets lag_count.
+// Like the other request builders, it adds defaultTags to the entry's resource
+// tags.
+func portRequest(p infra.PortConfig, defaultTags map[string]string) *megaport.BuyPortRequest {
+	return &megaport.BuyPortRequest{
+		Name:                  p.Name,
+		LocationId:            p.LocationID,
+		PortSpeed:             p.Speed,
+		Term:                  p.Term,
+		MarketPlaceVisibility: p.MarketplaceVisibility,
+		DiversityZone:         p.DiversityZone,
+		CostCentre:            p.CostCentre,
+		ResourceTags:          utils.WithDefaultResourceTags(defaultTags, p.ResourceTags),
+		LagCount:              p.LagCount,
+		WaitForProvision:      false,
+	}
+}
+
+// mcrRequest builds the order for an MCR. Its prefix filter lists are created
+// separately, once it is provisioned.
+func mcrRequest(m infra.MCRConfig, defaultTags map[string]string) *megaport.BuyMCRRequest {
+	return &megaport.BuyMCRRequest{
+		Name:             m.Name,
+		LocationID:       m.LocationID,
+		PortSpeed:        m.Speed,
+		Term:             m.Term,
+		MCRAsn:           m.ASN,
+		DiversityZone:    m.DiversityZone,
+		CostCentre:       m.CostCentre,
+		ResourceTags:     utils.WithDefaultResourceTags(defaultTags, m.ResourceTags),
+		AddOns:           mcrAddOns(m.TunnelCount),
+		WaitForProvision: false,
+	}
+}
+
+// mveRequest builds the order for an MVE, parsing its vendor_config as mve buy
+// parses --vendor-config.
+func mveRequest(mv infra.MVEConfig, defaultTags map[string]string) (*megaport.BuyMVERequest, error) {
+	normalizedVC, err := infra.NormalizeVendorConfigMap(mv.VendorConfig)
+	if err != nil {
+		return nil, err
+	}
+	vendorCfg, err := mve.ParseVendorConfig(normalizedVC)
+	if err != nil {
+		return nil, err
+	}
+	return &megaport.BuyMVERequest{
+		Name:             mv.Name,
+		LocationID:       mv.LocationID,
+		Term:             mv.Term,
+		VendorConfig:     vendorCfg,
+		DiversityZone:    mv.DiversityZone,
+		CostCentre:       mv.CostCentre,
+		ResourceTags:     utils.WithDefaultResourceTags(defaultTags, mv.ResourceTags),
+		WaitForProvision: false,
+	}, nil
+}
+
+// natGatewayRequest builds the design request for a NAT gateway.
+func natGatewayRequest(n infra.NATGatewayConfig, defaultTags map[string]string) *megaport.CreateNATGatewayRequest {
+	return &megaport.CreateNATGatewayRequest{
+		ProductName:   n.Name,
+		LocationID:    n.LocationID,
+		Speed:         n.Speed,
+		Term:          n.Term,
+		AutoRenewTerm: n.AutoRenewTerm,
+		Config: megaport.NATGatewayNetworkConfig{
+			ASN:           n.ASN,
+			DiversityZone: n.DiversityZone,
+			SessionCount:  n.SessionCount,
+		},
+		PromoCode:             n.PromoCode,
+		ServiceLevelReference: n.ServiceLevelReference,
+		ResourceTags:          resourceTagList(utils.WithDefaultResourceTags(defaultTags, n.ResourceTags)),
+	}
+}
+
+// designUpdateRequest rewrites an unbought NAT gateway design with req.
+func designUpdateRequest(uid string, req *megaport.CreateNATGatewayRequest) *megaport.UpdateNATGatewayRequest {
+	return &megaport.UpdateNATGatewayRequest{
+		ProductUID:            uid,
+		AutoRenewTerm:         req.AutoRenewTerm,
+		Config:                req.Config,
+		LocationID:            req.LocationID,
+		ProductName:           req.ProductName,
+		PromoCode:             req.PromoCode,
+		ResourceTags:          req.ResourceTags,
+		ServiceLevelReference: req.ServiceLevelReference,
+		Speed:                 req.Speed,
+		Term:                  req.Term,
+	}
+}
+
+// resourceTagList converts tags to the list form the NAT gateway API uses,
+// sorted by key so requests are stable.
+func resourceTagList(tags map[string]string) []megaport.ResourceTag {
+	if len(tags) == 0 {
+		return nil
+	}
+	list := make([]megaport.ResourceTag, 0, len(tags))
+	for _, k := range slices.Sorted(maps.Keys(tags)) {
+		list = append(list, megaport.ResourceTag{Key: k, Value: tags[k]})
+	}
+	return list
+}
+
+// ixRequest builds the order for an IX on the port productUID.
+func ixRequest(x infra.IXConfig, productUID string) *megaport.BuyIXRequest {
+	return &megaport.BuyIXRequest{
+		ProductUID:         productUID,
+		Name:               x.Name,
+		NetworkServiceType: x.NetworkServiceType,
+		ASN:                x.ASN,
+		MACAddress:         x.MACAddress,
+		RateLimit:          x.RateLimit,
+		VLAN:               x.VLAN,
+		Shutdown:           x.Shutdown,
+		PromoCode:          x.PromoCode,
+	}
+}
+
+// serviceKeyRequest builds the create request for a service key on the port
+// productUID.
+func serviceKeyRequest(k infra.ServiceKeyConfig, productUID string) *megaport.CreateServiceKeyRequest {
+	return &megaport.CreateServiceKeyRequest{
+		ProductUID:  productUID,
+		Description: k.Name,
+		MaxSpeed:    k.MaxSpeed,
+		SingleUse:   k.SingleUse,
+		VLAN:        k.VLAN,
+		PreApproved: k.PreApproved,
+		Active:      k.Active,
+	}
+}
+
+// prefixFilterListRequests builds the create requests for an MCR's prefix
+// filter lists and validates them, so a bad list fails before the MCR is ordered.
+func prefixFilterListRequests(mcrUID string, lists []infra.PrefixFilterListConfig) ([]*megaport.CreateMCRPrefixFilterListRequest, error) {
+	reqs := make([]*megaport.CreateMCRPrefixFilterListRequest, 0, len(lists))
+	for _, l := range lists {
+		entries := make([]*megaport.MCRPrefixListEntry, 0, len(l.Entries))
+		for _, e := range l.Entries {
+			entries = append(entries, &megaport.MCRPrefixListEntry{Action: e.Action, Prefix: e.Prefix, Ge: e.Ge, Le: e.Le})
+		}
+		req := &megaport.CreateMCRPrefixFilterListRequest{
+			MCRID: mcrUID,
+			PrefixFilterList: megaport.MCRPrefixFilterList{
+				Description:   l.Description,
+				AddressFamily: l.AddressFamily,
+				Entries:       entries,
+			},
+		}
+		if err := validation.ValidatePrefixFilterListRequest(req); err != nil {
+			return nil, fmt.Errorf("prefix filter list %q: %w", l.Description, err)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// createPrefixFilterLists creates an MCR's prefix filter lists once it is live.
+func createPrefixFilterLists(ctx context.Context, client *megaport.Client, reqs []*megaport.CreateMCRPrefixFilterListRequest) error {
+	for _, req := range reqs {
+		err := utils.WithOrderOnceRetry(ctx, func(ctx context.Context) error {
+			_, e := client.MCRService.CreatePrefixFilterList(ctx, req)
+			return e
+		})
+		if err != nil {
+			return fmt.Errorf("creating prefix filter list %q: %w", req.PrefixFilterList.Description, err)
+		}
+	}
+	return nil
+}
+
 // validateAll runs SDK-level validation for every resource without provisioning.
 // Requests mirror provisioning exactly (minus WaitForProvision/WaitForTime).
-func validateAll(ctx context.Context, client *megaport.Client, cfg *InfraConfig, noColor bool, outputFormat string) error {
-	var results []ApplyResult
+func validateAll(ctx context.Context, client *megaport.Client, cfg *infra.Config, defaultTags map[string]string, noColor bool, outputFormat string) error {
+	var results []infra.Result
 
 	for _, p := range cfg.Ports {
-		req := &megaport.BuyPortRequest{
-			Name:                  p.Name,
-			LocationId:            p.LocationID,
-			PortSpeed:             p.Speed,
-			Term:                  p.Term,
-			MarketPlaceVisibility: p.MarketplaceVisibility,
-			DiversityZone:         p.DiversityZone,
-			CostCentre:            p.CostCentre,
-			ResourceTags:          p.ResourceTags,
-		}
-		if err := validation.ValidatePortRequest(req); err != nil {
-			results = append(results, ApplyResult{Type: "Port", Name: p.Name, Status: "invalid: " + err.Error()})
+		req := portRequest(p, defaultTags)
+		if err := validatePortRequest(req); err != nil {
+			results = append(results, infra.Result{Type: "Port", Name: p.Name, Status: "invalid: " + err.Error()})
 			continue
 		}
 		err := client.PortService.ValidatePortOrder(ctx, req)
@@ -619,27 +1246,21 @@ func validateAll(ctx context.Context, client *megaport.Client, cfg *InfraConfig,
 		if err != nil {
 			status = "invalid: " + err.Error()
 		}
-		results = append(results, ApplyResult{Type: "Port", Name: p.Name, Status: status})
+		results = append(results, infra.Result{Type: "Port", Name: p.Name, Status: status})
 	}
 
 	for _, m := range cfg.MCRs {
 		if err := validation.ValidateIPSecTunnelCount(m.TunnelCount, true); err != nil {
-			results = append(results, ApplyResult{Type: "MCR", Name: m.Name, Status: "invalid: " + err.Error()})
+			results = append(results, infra.Result{Type: "MCR", Name: m.Name, Status: "invalid: " + err.Error()})
 			continue
 		}
-		req := &megaport.BuyMCRRequest{
-			Name:          m.Name,
-			LocationID:    m.LocationID,
-			PortSpeed:     m.Speed,
-			Term:          m.Term,
-			MCRAsn:        m.ASN,
-			DiversityZone: m.DiversityZone,
-			CostCentre:    m.CostCentre,
-			ResourceTags:  m.ResourceTags,
-			AddOns:        mcrAddOns(m.TunnelCount),
+		if _, err := prefixFilterListRequests("", m.PrefixFilterLists); err != nil {
+			results = append(results, infra.Result{Type: "MCR", Name: m.Name, Status: "invalid: " + err.Error()})
+			continue
 		}
+		req := mcrRequest(m, defaultTags)
 		if err := validation.ValidateMCRRequest(req); err != nil {
-			results = append(results, ApplyResult{Type: "MCR", Name: m.Name, Status: "invalid: " + err.Error()})
+			results = append(results, infra.Result{Type: "MCR", Name: m.Name, Status: "invalid: " + err.Error()})
 			continue
 		}
 		err := client.MCRService.ValidateMCROrder(ctx, req)
@@ -647,31 +1268,17 @@ func validateAll(ctx context.Context, client *megaport.Client, cfg *InfraConfig,
 		if err != nil {
 			status = "invalid: " + err.Error()
 		}
-		results = append(results, ApplyResult{Type: "MCR", Name: m.Name, Status: status})
+		results = append(results, infra.Result{Type: "MCR", Name: m.Name, Status: status})
 	}
 
 	for _, mv := range cfg.MVEs {
-		normalizedVC, vcErr := normalizeVendorConfigMap(mv.VendorConfig)
-		if vcErr != nil {
-			results = append(results, ApplyResult{Type: "MVE", Name: mv.Name, Status: "invalid: " + vcErr.Error()})
-			continue
-		}
-		vendorCfg, vcErr := mve.ParseVendorConfig(normalizedVC)
+		req, vcErr := mveRequest(mv, defaultTags)
 		if vcErr != nil {
-			results = append(results, ApplyResult{Type: "MVE", Name: mv.Name, Status: "invalid: " + vcErr.Error()})
+			results = append(results, infra.Result{Type: "MVE", Name: mv.Name, Status: "invalid: " + vcErr.Error()})
 			continue
 		}
-		req := &megaport.BuyMVERequest{
-			Name:          mv.Name,
-			LocationID:    mv.LocationID,
-			Term:          mv.Term,
-			VendorConfig:  vendorCfg,
-			DiversityZone: mv.DiversityZone,
-			CostCentre:    mv.CostCentre,
-			ResourceTags:  mv.ResourceTags,
-		}
 		if err := validation.ValidateBuyMVERequest(req); err != nil {
-			results = append(results, ApplyResult{Type: "MVE", Name: mv.Name, Status: "invalid: " + err.Error()})
+			results = append(results, infra.Result{Type: "MVE", Name: mv.Name, Status: "invalid: " + err.Error()})
 			continue
 		}
 		err := client.MVEService.ValidateMVEOrder(ctx, req)
@@ -679,171 +1286,123 @@ func validateAll(ctx context.Context, client *megaport.Client, cfg *InfraConfig,
 		if err != nil {
 			status = "invalid: " + err.Error()
 		}
-		results = append(results, ApplyResult{Type: "MVE", Name: mv.Name, Status: status})
+		results = append(results, infra.Result{Type: "MVE", Name: mv.Name, Status: status})
 	}
 
-	// Build a dry-run UID map from declared resources so template references
-	// are resolved against known names — typos produce an "invalid" result
-	// rather than silently passing with a generic placeholder.
-	dryRunUIDs := map[string]map[string]string{
-		"port": {},
-		"mcr":  {},
-		"mve":  {},
-		"vxc":  {},
-	}
-	const dryRunPlaceholder = "00000000-0000-0000-0000-000000000000"
-	for _, p := range cfg.Ports {
-		dryRunUIDs["port"][p.Name] = dryRunPlaceholder
+	// Resolve template references against the declared resources, so typos
+	// produce an "invalid" result rather than silently passing with a generic
+	// placeholder.
+	dryRunUIDs := placeholderUIDs(cfg)
+
+	// A NAT gateway order can only be validated server-side once its design
+	// exists, so the dry run stops at the client-side checks.
+	for _, n := range cfg.NATGateways {
+		status := "skipped: requires a design"
+		if err := validation.ValidateCreateNATGatewayRequest(natGatewayRequest(n, defaultTags)); err != nil {
+			status = "invalid: " + err.Error()
+		}
+		results = append(results, infra.Result{Type: "NAT Gateway", Name: n.Name, Status: status})
 	}
-	for _, m := range cfg.MCRs {
-		dryRunUIDs["mcr"][m.Name] = dryRunPlaceholder
+
+	for _, x := range cfg.IXs {
+		productUID, err := infra.ResolveTemplates(x.ProductUID, dryRunUIDs)
+		if err != nil {
+			results = append(results, infra.Result{Type: "IX", Name: x.Name, Status: "invalid: " + err.Error()})
+			continue
+		}
+		req := ixRequest(x, productUID)
+		if err := validation.ValidateIXRequest(req); err != nil {
+			results = append(results, infra.Result{Type: "IX", Name: x.Name, Status: "invalid: " + err.Error()})
+			continue
+		}
+		if productUID == dryRunPlaceholder {
+			results = append(results, infra.Result{Type: "IX", Name: x.Name, Status: "skipped: requires provisioning"})
+			continue
+		}
+		status := "valid"
+		if err := client.IXService.ValidateIXOrder(ctx, req); err != nil {
+			status = "invalid: " + err.Error()
+		}
+		results = append(results, infra.Result{Type: "IX", Name: x.Name, Status: status})
 	}
-	for _, mv := range cfg.MVEs {
-		dryRunUIDs["mve"][mv.Name] = dryRunPlaceholder
+
+	// Service keys have no server-side order validation.
+	for _, k := range cfg.ServiceKeys {
+		productUID, err := infra.ResolveTemplates(k.ProductUID, dryRunUIDs)
+		if err == nil {
+			err = validation.ValidateCreateServiceKeyRequest(serviceKeyRequest(k, productUID))
+		}
+		status := "valid"
+		if err != nil {
+			status = "invalid: " + err.Error()
+		}
+		results = append(results, infra.Result{Type: "Service Key", Name: k.Name, Status: status})
 	}
 
 	for _, v := range cfg.VXCs {
 		// Resolve templates against declared resources; literal UIDs pass through.
-		aUID, aErr := resolveTemplates(v.AEnd.ProductUID, dryRunUIDs)
-		bUID, bErr := resolveTemplates(v.BEnd.ProductUID, dryRunUIDs)
+		aUID, aErr := infra.ResolveTemplates(v.AEnd.ProductUID, dryRunUIDs)
+		bUID, bErr := infra.ResolveTemplates(v.BEnd.ProductUID, dryRunUIDs)
 		if aErr != nil {
-			results = append(results, ApplyResult{Type: "VXC", Name: v.Name, Status: "invalid: " + aErr.Error()})
+			results = append(results, infra.Result{Type: "VXC", Name: v.Name, Status: "invalid: " + aErr.Error()})
 			continue
 		}
 		if bErr != nil {
-			results = append(results, ApplyResult{Type: "VXC", Name: v.Name, Status: "invalid: " + bErr.Error()})
+			results = append(results, infra.Result{Type: "VXC", Name: v.Name, Status: "invalid: " + bErr.Error()})
 			continue
 		}
-		req := &megaport.BuyVXCRequest{
-			PortUID:   aUID,
-			VXCName:   v.Name,
-			RateLimit: v.RateLimit,
-			Term:      v.Term,
-			AEndConfiguration: megaport.VXCOrderEndpointConfiguration{
-				ProductUID: aUID,
-				VLAN:       v.AEnd.VLAN,
-			},
-			BEndConfiguration: megaport.VXCOrderEndpointConfiguration{
-				ProductUID: bUID,
-				VLAN:       v.BEnd.VLAN,
-			},
-			CostCentre:   v.CostCentre,
-			ResourceTags: v.ResourceTags,
+		req, err := infra.VXCRequest(ctx, client, v, aUID, bUID, defaultTags)
+		if err != nil {
+			results = append(results, infra.Result{Type: "VXC", Name: v.Name, Status: "invalid: " + err.Error()})
+			continue
 		}
 		if err := validation.ValidateVXCRequest(req); err != nil {
-			results = append(results, ApplyResult{Type: "VXC", Name: v.Name, Status: "invalid: " + err.Error()})
+			results = append(results, infra.Result{Type: "VXC", Name: v.Name, Status: "invalid: " + err.Error()})
 			continue
 		}
 		// Skip server-side validation when either endpoint came from a template
 		// (placeholder UID) — the real UID only exists after provisioning.
 		if aUID == dryRunPlaceholder || bUID == dryRunPlaceholder {
-			results = append(results, ApplyResult{Type: "VXC", Name: v.Name, Status: "skipped: requires provisioning"})
+			results = append(results, infra.Result{Type: "VXC", Name: v.Name, Status: "skipped: requires provisioning"})
 			continue
 		}
-		err := client.VXCService.ValidateVXCOrder(ctx, req)
+		err = client.VXCService.ValidateVXCOrder(ctx, req)
 		status := "valid"
 		if err != nil {
 			status = "invalid: " + err.Error()
 		}
-		results = append(results, ApplyResult{Type: "VXC", Name: v.Name, Status: status})
+		results = append(results, infra.Result{Type: "VXC", Name: v.Name, Status: status})
 	}
 
 	output.PrintInfo("Dry-run: validation results", noColor)
 	return output.PrintOutput(results, outputFormat, noColor)
 }
 
-// parseConfigFile reads filePath and decodes it into InfraConfig, detecting YAML
-// vs JSON by file extension. Unknown keys are rejected so a mistyped field (e.g.
-// tunnelCount instead of tunnel_count) is a clear error rather than silently dropped.
-func parseConfigFile(filePath string) (*InfraConfig, error) {
-	f, err := os.Open(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("reading config file: %w", err)
-	}
-	defer f.Close()
+// dryRunPlaceholder stands in for the UID of a resource a config declares,
+// which only exists once it is provisioned.
+const dryRunPlaceholder = "00000000-0000-0000-0000-000000000000"
 
-	// Read one byte past the cap so an over-limit file is detected rather than
-	// silently truncated.
-	data, err := io.ReadAll(io.LimitReader(f, maxConfigFileSize+1))
-	if err != nil {
-		return nil, fmt.Errorf("reading config file: %w", err)
-	}
-	if len(data) > maxConfigFileSize {
-		return nil, fmt.Errorf("config file %q exceeds maximum size of %d bytes", filePath, maxConfigFileSize)
-	}
-
-	cfg := &InfraConfig{}
-	ext := strings.ToLower(filepath.Ext(filePath))
-	switch ext {
-	case ".json":
-		dec := json.NewDecoder(bytes.NewReader(data))
-		dec.DisallowUnknownFields()
-		if err := dec.Decode(cfg); err != nil {
-			return nil, fmt.Errorf("parsing JSON config: %w", err)
-		}
-		// Reject trailing data so a duplicated or concatenated body isn't silently ignored.
-		if dec.More() {
-			return nil, fmt.Errorf("parsing JSON config: unexpected trailing data after the config object")
-		}
-	default: // .yaml, .yml, or anything else
-		dec := yaml.NewDecoder(bytes.NewReader(data))
-		dec.KnownFields(true)
-		// An empty document decodes to io.EOF; treat it as an empty config.
-		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
-			return nil, fmt.Errorf("parsing YAML config: %w", err)
-		}
-		// Reject a further document that carries content so a stray one isn't silently
-		// dropped. A trailing `---` or comment decodes to an empty document and is benign.
-		for {
-			var extra interface{}
-			err := dec.Decode(&extra)
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			if err != nil {
-				return nil, fmt.Errorf("parsing YAML config: %w", err)
-			}
-			if extra != nil {
-				return nil, fmt.Errorf("parsing YAML config: unexpected content after the first document")
-			}
-		}
+// placeholderUIDs maps every entry cfg declares that a template can reference
+// to dryRunPlaceholder.
+func placeholderUIDs(cfg *infra.Config) map[string]map[string]string {
+	uids := infra.NewTypeMap[string]()
+	for _, p := range cfg.Ports {
+		uids["port"][p.Name] = dryRunPlaceholder
 	}
-	return cfg, nil
-}
-
-// resolveTemplates replaces {{.type.name}} placeholders using the uids map.
-func resolveTemplates(s string, uids map[string]map[string]string) (string, error) {
-	var resolveErr error
-	result := templateRe.ReplaceAllStringFunc(s, func(match string) string {
-		sub := templateRe.FindStringSubmatch(match)
-		if len(sub) != 3 {
-			return match
-		}
-		resType, resName := sub[1], sub[2]
-		if typeMap, ok := uids[resType]; ok {
-			if uid, ok := typeMap[resName]; ok {
-				return uid
-			}
-		}
-		resolveErr = errors.Join(resolveErr, fmt.Errorf("no UID found for reference %q (type=%q name=%q)", match, resType, resName))
-		return match
-	})
-	return result, resolveErr
-}
-
-// normalizeVendorConfigMap round-trips a vendor config map through JSON so that
-// YAML-decoded integers (int) become float64, matching what ParseVendorConfig expects.
-func normalizeVendorConfigMap(m map[string]interface{}) (map[string]interface{}, error) {
-	if m == nil {
-		return nil, nil
+	for _, m := range cfg.MCRs {
+		uids["mcr"][m.Name] = dryRunPlaceholder
 	}
-	b, err := json.Marshal(m)
-	if err != nil {
-		return nil, fmt.Errorf("normalizing vendor config: %w", err)
+	for _, mv := range cfg.MVEs {
+		uids["mve"][mv.Name] = dryRunPlaceholder
+	}
+	for _, n := range cfg.NATGateways {
+		uids["nat_gateway"][n.Name] = dryRunPlaceholder
+	}
+	for _, x := range cfg.IXs {
+		uids["ix"][x.Name] = dryRunPlaceholder
 	}
-	var out map[string]interface{}
-	if err := json.Unmarshal(b, &out); err != nil {
-		return nil, fmt.Errorf("normalizing vendor config: %w", err)
+	for _, k := range cfg.ServiceKeys {
+		uids["service_key"][k.Name] = dryRunPlaceholder
 	}
-	return out, nil
+	return uids
 }
@@ -0,0 +1,257 @@
+package apply
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/infra"
+)
+
+// graphNode is one config entry in the apply dependency graph.
+type graphNode struct {
+	resType string // template type key, e.g. "port"
+	name    string
+	index   int   // position of the entry in the config's list for resType
+	deps    []int // nodes named by the entry's {{.type.name}} references
+}
+
+// buildGraph turns cfg's entries into a dependency graph, listed in
+// provisioning order: an entry depends on the entries its {{.type.name}}
+// references name. A reference to an entry that is not in the config adds no
+// edge; it fails when the entry is applied, as it always has. A reference
+// cycle is an error, since none of its entries could ever start.
+func buildGraph(cfg *infra.Config) ([]graphNode, error) {
+	var nodes []graphNode
+	var refs [][]string
+	add := func(resType, name string, index int, productUIDs ...string) {
+		nodes = append(nodes, graphNode{resType: resType, name: name, index: index})
+		refs = append(refs, productUIDs)
+	}
+	for i, p := range cfg.Ports {
+		add("port", p.Name, i)
+	}
+	for i, m := range cfg.MCRs {
+		add("mcr", m.Name, i)
+	}
+	for i, mv := range cfg.MVEs {
+		add("mve", mv.Name, i)
+	}
+	for i, n := range cfg.NATGateways {
+		add("nat_gateway", n.Name, i)
+	}
+	for i, x := range cfg.IXs {
+		add("ix", x.Name, i, x.ProductUID)
+	}
+	for i, k := range cfg.ServiceKeys {
+		add("service_key", k.Name, i, k.ProductUID)
+	}
+	for i, v := range cfg.VXCs {
+		add("vxc", v.Name, i, v.AEnd.ProductUID, v.BEnd.ProductUID)
+	}
+
+	byKey := map[string][]int{}
+	for i, n := range nodes {
+		key := n.resType + "." + n.name
+		byKey[key] = append(byKey[key], i)
+	}
+	for i := range nodes {
+		for _, s := range refs[i] {
+			for _, m := range infra.TemplateRe.FindAllStringSubmatch(s, -1) {
+				for _, dep := range byKey[m[1]+"."+m[2]] {
+					if !slices.Contains(nodes[i].deps, dep) {
+						nodes[i].deps = append(nodes[i].deps, dep)
+					}
+				}
+			}
+		}
+	}
+
+	// Kahn's algorithm: whatever cannot be sorted is on, or behind, a cycle.
+	pending, dependents, queue := edges(nodes)
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, d := range dependents[i] {
+			pending[d]--
+			if pending[d] == 0 {
+				queue = append(queue, d)
+			}
+		}
+	}
+	var cyclic []string
+	for i, n := range nodes {
+		if pending[i] > 0 {
+			cyclic = append(cyclic, fmt.Sprintf("%s %q", n.resType, n.name))
+		}
+	}
+	if len(cyclic) > 0 {
+		return nil, fmt.Errorf("config has a reference cycle between %s", strings.Join(cyclic, ", "))
+	}
+	return nodes, nil
+}
+
+// edges returns, per node, the number of dependencies and the nodes that
+// depend on it, along with the nodes that have no dependencies, in order.
+func edges(nodes []graphNode) (pending []int, dependents [][]int, roots []int) {
+	pending = make([]int, len(nodes))
+	dependents = make([][]int, len(nodes))
+	for i, n := range nodes {
+		pending[i] = len(n.deps)
+		for _, dep := range n.deps {
+			dependents[dep] = append(dependents[dep], i)
+		}
+		if pending[i] == 0 {
+			roots = append(roots, i)
+		}
+	}
+	return pending, dependents, roots
+}
+
+// graphResult carries a finished node back to the scheduler.
+type graphResult struct {
+	node   int
+	result infra.Result
+	err    error
+}
+
+// runGraph runs each node once all of its dependencies have succeeded, with at
+// most parallelism nodes in flight. Ready nodes start in graph order, so a
+// parallelism of 1 applies the config in provisioning order. After the first
+// failure no further nodes are started, but nodes already in flight run to
+// completion, so that every order placed is tracked before a rollback. It
+// returns the results of the nodes that ran, in graph order, and every failure
+// joined.
+func runGraph(nodes []graphNode, parallelism int, run func(n graphNode) (infra.Result, error)) ([]infra.Result, error) {
+	pending, dependents, ready := edges(nodes)
+	results := make([]*infra.Result, len(nodes))
+	done := make(chan graphResult)
+	var errs []error
+	running := 0
+	for {
+		for len(errs) == 0 && running < parallelism && len(ready) > 0 {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+			go func() {
+				res, err := run(nodes[i])
+				done <- graphResult{node: i, result: res, err: err}
+			}()
+		}
+		if running == 0 {
+			break
+		}
+		r := <-done
+		running--
+		results[r.node] = &r.result
+		if r.err != nil {
+			errs = append(errs, r.err)
+			continue
+		}
+		for _, d := range dependents[r.node] {
+			pending[d]--
+			if pending[d] == 0 {
+				i, _ := slices.BinarySearch(ready, d)
+				ready = slices.Insert(ready, i, d)
+			}
+		}
+	}
+
+	var out []infra.Result
+	for _, r := range results {
+		if r != nil {
+			out = append(out, *r)
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// spinner is the part of *output.Spinner that progress hands out.
+type spinner interface {
+	Stop()
+}
+
+// noSpinner stands in for a spinner in parallel runs.
+type noSpinner struct{}
+
+func (noSpinner) Stop() {}
+
+// progress reports the phases of each resource. A sequential run shows the
+// usual spinners; a parallel run prints a line per phase instead, since
+// concurrent spinners would overwrite each other, and numbers each finished
+// resource.
+type progress struct {
+	parallel bool
+	noColor  bool
+	total    int
+
+	mu       sync.Mutex
+	finished int
+}
+
+func (p *progress) validating(resType, name string) spinner {
+	if !p.parallel {
+		return output.PrintResourceValidating(resType, p.noColor)
+	}
+	output.PrintInfo("Validating %s %q order...", p.noColor, resType, name)
+	return noSpinner{}
+}
+
+func (p *progress) creating(resType, name string) spinner {
+	if !p.parallel {
+		return output.PrintResourceCreating(resType, name, p.noColor)
+	}
+	output.PrintInfo("Creating %s %q...", p.noColor, resType, name)
+	return noSpinner{}
+}
+
+func (p *progress) provisioning(resType, name string) spinner {
+	if !p.parallel {
+		return output.PrintResourceProvisioning(resType, name, p.noColor)
+	}
+	output.PrintInfo("Provisioning %s %q...", p.noColor, resType, name)
+	return noSpinner{}
+}
+
+func (p *progress) updating(resType, name, uid string) spinner {
+	if !p.parallel {
+		return output.PrintResourceUpdating(resType, uid, p.noColor)
+	}
+	output.PrintInfo("Updating %s %q...", p.noColor, resType, name)
+	return noSpinner{}
+}
+
+// created reports a provisioned resource in a sequential run; a parallel run
+// reports it when it finishes.
+func (p *progress) created(resType, uid string) {
+	if !p.parallel {
+		output.PrintResourceCreated(resType, uid, p.noColor)
+	}
+}
+
+// updated reports an updated resource in a sequential run; a parallel run
+// reports it when it finishes.
+func (p *progress) updated(resType, uid string) {
+	if !p.parallel {
+		output.PrintResourceUpdated(resType, uid, p.noColor)
+	}
+}
+
+// finish reports a resource's result in a parallel run, numbered among the
+// run's resources.
+func (p *progress) finish(r infra.Result) {
+	if !p.parallel {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.finished++
+	if strings.HasPrefix(r.Status, infra.StatusError) {
+		output.PrintError("[%d/%d] %s %q: %s", p.noColor, p.finished, p.total, r.Type, r.Name, r.Status)
+		return
+	}
+	output.PrintSuccess("[%d/%d] %s %q: %s", p.noColor, p.finished, p.total, r.Type, r.Name, r.Status)
+}
@@ -0,0 +1,256 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/commands/config"
+	"github.com/megaport/megaport-cli/internal/infra"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nodeNames lists the nodes at indexes as type.name, for readable assertions.
+func nodeNames(nodes []graphNode, indexes []int) []string {
+	names := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		names = append(names, nodes[i].resType+"."+nodes[i].name)
+	}
+	return names
+}
+
+func TestBuildGraph_Dependencies(t *testing.T) {
+	cfg := &infra.Config{
+		Ports: []infra.PortConfig{{Name: "A"}, {Name: "B"}},
+		MCRs:  []infra.MCRConfig{{Name: "R"}},
+		IXs:   []infra.IXConfig{{Name: "IX", ProductUID: "{{.port.A}}"}},
+		VXCs: []infra.VXCConfig{
+			{Name: "A-to-R", AEnd: infra.VXCEndpointConfig{ProductUID: "{{.port.A}}"}, BEnd: infra.VXCEndpointConfig{ProductUID: "{{.mcr.R}}"}},
+			{Name: "B-to-Partner", AEnd: infra.VXCEndpointConfig{ProductUID: "{{.port.B}}"}, BEnd: infra.VXCEndpointConfig{ProductUID: "partner-port-uid"}},
+			{Name: "Typo", AEnd: infra.VXCEndpointConfig{ProductUID: "{{.port.Missing}}"}},
+		},
+	}
+	nodes, err := buildGraph(cfg)
+	require.NoError(t, err)
+	require.Len(t, nodes, 7)
+
+	assert.Equal(t, []string{"port.A", "port.B", "mcr.R", "ix.IX", "vxc.A-to-R", "vxc.B-to-Partner", "vxc.Typo"},
+		nodeNames(nodes, []int{0, 1, 2, 3, 4, 5, 6}), "nodes are listed in provisioning order")
+	assert.Empty(t, nodes[0].deps)
+	assert.Equal(t, []string{"port.A"}, nodeNames(nodes, nodes[3].deps))
+	assert.Equal(t, []string{"port.A", "mcr.R"}, nodeNames(nodes, nodes[4].deps))
+	assert.Equal(t, []string{"port.B"}, nodeNames(nodes, nodes[5].deps))
+	assert.Empty(t, nodes[6].deps, "a reference to an undeclared entry adds no edge")
+	assert.Equal(t, 1, nodes[5].index)
+}
+
+func TestBuildGraph_Cycle(t *testing.T) {
+	cfg := &infra.Config{
+		IXs:         []infra.IXConfig{{Name: "IX", ProductUID: "{{.service_key.Key}}"}},
+		ServiceKeys: []infra.ServiceKeyConfig{{Name: "Key", ProductUID: "{{.ix.IX}}"}},
+	}
+	_, err := buildGraph(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `reference cycle between ix "IX", service_key "Key"`)
+}
+
+func TestRunGraph_SequentialKeepsProvisioningOrder(t *testing.T) {
+	// The IX is listed before the service key it references, so it waits for it.
+	cfg := &infra.Config{
+		Ports:       []infra.PortConfig{{Name: "A"}},
+		IXs:         []infra.IXConfig{{Name: "IX", ProductUID: "{{.service_key.Key}}"}},
+		ServiceKeys: []infra.ServiceKeyConfig{{Name: "Key", ProductUID: "{{.port.A}}"}},
+		VXCs:        []infra.VXCConfig{{Name: "V", AEnd: infra.VXCEndpointConfig{ProductUID: "{{.port.A}}"}}},
+	}
+	nodes, err := buildGraph(cfg)
+	require.NoError(t, err)
+
+	var order []string
+	results, err := runGraph(nodes, 1, func(n graphNode) (infra.Result, error) {
+		order = append(order, n.resType+"."+n.name)
+		return infra.Result{Name: n.name}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"port.A", "service_key.Key", "ix.IX", "vxc.V"}, order)
+	require.Len(t, results, 4)
+	assert.Equal(t, "IX", results[1].Name, "results are in graph order")
+}
+
+func TestRunGraph_ParallelRespectsDependenciesAndLimit(t *testing.T) {
+	cfg := &infra.Config{}
+	for i := range 6 {
+		cfg.Ports = append(cfg.Ports, infra.PortConfig{Name: fmt.Sprintf("P%d", i)})
+		cfg.VXCs = append(cfg.VXCs, infra.VXCConfig{
+			Name: fmt.Sprintf("V%d", i),
+			AEnd: infra.VXCEndpointConfig{ProductUID: fmt.Sprintf("{{.port.P%d}}", i)},
+			BEnd: infra.VXCEndpointConfig{ProductUID: fmt.Sprintf("{{.port.P%d}}", (i+1)%6)},
+		})
+	}
+	nodes, err := buildGraph(cfg)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	finished := map[int]bool{}
+	var inFlight, maxInFlight atomic.Int32
+	_, err = runGraph(nodes, 3, func(n graphNode) (infra.Result, error) {
+		cur := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			prev := maxInFlight.Load()
+			if cur <= prev || maxInFlight.CompareAndSwap(prev, cur) {
+				break
+			}
+		}
+		mu.Lock()
+		for _, dep := range n.deps {
+			assert.True(t, finished[dep], "%s started before its dependency %s", n.name, nodes[dep].name)
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		finished[nodeIndex(nodes, n)] = true
+		mu.Unlock()
+		return infra.Result{Name: n.name}, nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, finished, 12)
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+	assert.Greater(t, maxInFlight.Load(), int32(1), "independent resources run concurrently")
+}
+
+// nodeIndex returns the position of n in nodes.
+func nodeIndex(nodes []graphNode, n graphNode) int {
+	for i, m := range nodes {
+		if m.resType == n.resType && m.name == n.name {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestRunGraph_FailureStopsNewWorkAndJoinsErrors(t *testing.T) {
+	cfg := &infra.Config{
+		Ports: []infra.PortConfig{{Name: "Bad1"}, {Name: "Bad2"}, {Name: "Good"}},
+		VXCs: []infra.VXCConfig{
+			{Name: "V", AEnd: infra.VXCEndpointConfig{ProductUID: "{{.port.Good}}"}},
+		},
+	}
+	nodes, err := buildGraph(cfg)
+	require.NoError(t, err)
+
+	results, err := runGraph(nodes, 3, func(n graphNode) (infra.Result, error) {
+		if n.name == "Good" {
+			// Finishes after both failures; it must still be waited for.
+			time.Sleep(20 * time.Millisecond)
+			return infra.Result{Name: n.name, Status: "provisioned"}, nil
+		}
+		if n.name == "V" {
+			t.Error("no node may start after a failure")
+		}
+		return infra.Result{Name: n.name, Status: infra.StatusError}, fmt.Errorf("%s failed", n.name)
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "Bad1 failed")
+	assert.ErrorContains(t, err, "Bad2 failed")
+	require.Len(t, results, 3, "every node in flight reports its result")
+	assert.Equal(t, "Good", results[2].Name)
+}
+
+// concurrentPortService makes a MockPortService safe for parallel applies and
+// gives each port its own UID. GetPort fails at once for the port named
+// failName.
+type concurrentPortService struct {
+	*infra.MockPortService
+	mu       sync.Mutex
+	failName string
+}
+
+func (m *concurrentPortService) BuyPort(ctx context.Context, req *megaport.BuyPortRequest) (*megaport.BuyPortResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-uid-" + req.Name}}, nil
+}
+
+func (m *concurrentPortService) GetPort(ctx context.Context, portID string) (*megaport.Port, error) {
+	if portID == "port-uid-"+m.failName {
+		return nil, errors.New("port provisioning failed")
+	}
+	// The other ports finish after the failure, while they are in flight.
+	time.Sleep(20 * time.Millisecond)
+	return &megaport.Port{UID: portID, ProvisioningStatus: megaport.SERVICE_LIVE}, nil
+}
+
+func (m *concurrentPortService) DeletePort(ctx context.Context, req *megaport.DeletePortRequest) (*megaport.DeletePortResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.MockPortService.DeletePort(ctx, req)
+}
+
+func TestApplyConfig_ParallelFailureRollsBackEveryBranch(t *testing.T) {
+	mockPort := &concurrentPortService{MockPortService: &infra.MockPortService{}, failName: "Port-C"}
+	mockVXC := &infra.MockVXCService{}
+	original := config.GetLoginFunc()
+	config.SetLoginFunc(func(ctx context.Context) (*megaport.Client, error) {
+		client := &megaport.Client{}
+		client.PortService = mockPort
+		client.MCRService = &infra.MockMCRService{}
+		client.MVEService = &infra.MockMVEService{}
+		client.VXCService = mockVXC
+		client.NATGatewayService = &infra.MockNATGatewayService{}
+		client.IXService = &infra.MockIXService{}
+		client.ServiceKeyService = &infra.MockServiceKeyService{}
+		return client, nil
+	})
+	defer config.SetLoginFunc(original)
+
+	cfgYAML := `
+ports:
+  - {name: Port-A, location_id: 1, speed: 1000, term: 12}
+  - {name: Port-B, location_id: 1, speed: 1000, term: 12}
+  - {name: Port-C, location_id: 1, speed: 1000, term: 12}
+vxcs:
+  - name: A-to-B
+    rate_limit: 100
+    term: 12
+    a_end: {product_uid: "{{.port.Port-A}}"}
+    b_end: {product_uid: "{{.port.Port-B}}"}
+`
+	f := writeTempFile(t, "infra.yaml", cfgYAML)
+	cmd := applyCmdWithRollback(f)
+	require.NoError(t, cmd.Flags().Set("parallelism", "3"))
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to provision port "Port-C"`)
+	assert.Nil(t, mockVXC.CapturedVXCRequest, "the VXC is not started once a branch has failed")
+	assert.ElementsMatch(t, []string{"port-uid-Port-A", "port-uid-Port-B", "port-uid-Port-C"}, mockPort.DeletePortCalledWith,
+		"ports ordered on every branch are rolled back")
+
+	state, err := infra.LoadState(infra.DefaultStatePath(f))
+	require.NoError(t, err)
+	assert.Empty(t, state.Resources, "rolled-back resources are removed from the state")
+}
+
+func TestApplyConfig_InvalidParallelism(t *testing.T) {
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	f := writeTempFile(t, "infra.yaml", "ports: []\n")
+	cmd := applyCmd(f, false, true)
+	require.NoError(t, cmd.Flags().Set("parallelism", "0"))
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--parallelism must be at least 1")
+}
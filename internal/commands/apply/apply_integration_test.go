@@ -57,6 +57,8 @@ func applyIntegrationCmd(t *testing.T, file string, dryRun, yes, rollback bool)
 	cmd.Flags().Bool("dry-run", false, "")
 	cmd.Flags().BoolP("yes", "y", false, "")
 	cmd.Flags().Bool("rollback-on-failure", false, "")
+	cmd.Flags().Int("parallelism", 1, "")
+	cmd.Flags().String("resume", "", "")
 	require.NoError(t, cmd.Flags().Set("file", file))
 	if dryRun {
 		require.NoError(t, cmd.Flags().Set("dry-run", "true"))
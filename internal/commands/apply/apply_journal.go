@@ -0,0 +1,287 @@
+package apply
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/infra"
+)
+
+// journalVersion is the schema version written to new run journals.
+const journalVersion = 1
+
+// Run journal statuses. A journal is removed once its run succeeds or is
+// rolled back, so only unfinished runs have one.
+const (
+	runRunning = "running" // the run is in progress, or its process was killed
+	runFailed  = "failed"
+)
+
+// Journal resource statuses.
+const (
+	journalOrdered     = "ordered"     // the order was placed; provisioning has not been seen to complete
+	journalProvisioned = "provisioned" // the resource is ready
+)
+
+// RunJournal records what one apply run ordered, so that a run that stops
+// part-way can be resumed with --resume rather than cleaned up by hand.
+type RunJournal struct {
+	Version   int               `json:"version"`
+	RunID     string            `json:"run_id"`
+	Config    string            `json:"config"` // absolute path of the config file
+	StartedAt time.Time         `json:"started_at"`
+	Status    string            `json:"status"`
+	Error     string            `json:"error,omitempty"`
+	Resources []JournalResource `json:"resources"`
+}
+
+// JournalResource is one order placed by a run. Type is the template key, as in
+// the state file. Replaces and Lock are the actions left for the end of the
+// run: deleting the resource this one replaces, and locking it.
+type JournalResource struct {
+	Type      string    `json:"type"`
+	Name      string    `json:"name"`
+	UID       string    `json:"uid"`
+	Status    string    `json:"status"`
+	Replaces  string    `json:"replaces,omitempty"`
+	Lock      bool      `json:"lock,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// journalDir is where the run journals of a state file are kept:
+// infra.state.json → infra.state.runs, alongside the state file.
+func journalDir(statePath string) string {
+	return strings.TrimSuffix(statePath, filepath.Ext(statePath)) + ".runs"
+}
+
+// newRunID returns an ID for a run started now. The random suffix keeps runs
+// started in the same second apart.
+func newRunID() string {
+	return time.Now().UTC().Format("20060102T150405Z") + "-" + rand.Text()[:6]
+}
+
+// runJournal pairs a run's journal with the path it is saved to. Like the state
+// file, every change is written through immediately, so the journal survives
+// the process being killed. Nothing is written until the run places an order.
+// Its methods are safe for the concurrent branches of a parallel apply, and a
+// nil *runJournal records nothing.
+type runJournal struct {
+	mu      sync.Mutex
+	path    string
+	journal *RunJournal
+	noColor bool
+}
+
+// newRunJournal starts the journal of a new run of configPath.
+func newRunJournal(statePath, configPath string, noColor bool) *runJournal {
+	abs, err := filepath.Abs(configPath)
+	if err != nil {
+		abs = configPath
+	}
+	id := newRunID()
+	return &runJournal{
+		path:    filepath.Join(journalDir(statePath), id+".json"),
+		journal: &RunJournal{Version: journalVersion, RunID: id, Config: abs, StartedAt: time.Now().UTC(), Status: runRunning},
+		noColor: noColor,
+	}
+}
+
+// openRunJournal loads the journal of the unfinished run runID of configPath.
+func openRunJournal(statePath, configPath, runID string, noColor bool) (*runJournal, error) {
+	dir := journalDir(statePath)
+	path := filepath.Join(dir, runID+".json")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		runs := unfinishedRuns(statePath)
+		if len(runs) == 0 {
+			return nil, fmt.Errorf("no unfinished run %q in %s; there are no runs to resume", runID, dir)
+		}
+		return nil, fmt.Errorf("no unfinished run %q in %s; runs that can be resumed: %s", runID, dir, strings.Join(runs, ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading run journal: %w", err)
+	}
+	journal := &RunJournal{}
+	if err := json.Unmarshal(data, journal); err != nil {
+		return nil, fmt.Errorf("parsing run journal %q: %w", path, err)
+	}
+	if journal.Version > journalVersion {
+		return nil, fmt.Errorf("run journal %q has version %d; this CLI supports up to version %d", path, journal.Version, journalVersion)
+	}
+	if abs, err := filepath.Abs(configPath); err == nil && journal.Config != abs {
+		return nil, fmt.Errorf("run %q applied %s, not %s", runID, journal.Config, abs)
+	}
+	journal.Status = runRunning
+	journal.Error = ""
+	return &runJournal{path: path, journal: journal, noColor: noColor}, nil
+}
+
+// unfinishedRuns returns the IDs of the runs with a journal next to statePath,
+// oldest first.
+func unfinishedRuns(statePath string) []string {
+	matches, _ := filepath.Glob(filepath.Join(journalDir(statePath), "*.json"))
+	runs := make([]string, 0, len(matches))
+	for _, m := range matches {
+		runs = append(runs, strings.TrimSuffix(filepath.Base(m), ".json"))
+	}
+	slices.Sort(runs)
+	return runs
+}
+
+// id returns the run's ID.
+func (j *runJournal) id() string {
+	return j.journal.RunID
+}
+
+// ordered records an order placed by the run.
+func (j *runJournal) ordered(c infra.CreatedResource) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	resType := infra.TemplateType(c.ResType)
+	entry := JournalResource{Type: resType, Name: c.Name, UID: c.UID, Status: journalOrdered, Replaces: c.Replaces, Lock: c.Lock, UpdatedAt: time.Now().UTC()}
+	i := slices.IndexFunc(j.journal.Resources, func(r JournalResource) bool { return r.Type == resType && r.Name == c.Name })
+	if i < 0 {
+		j.journal.Resources = append(j.journal.Resources, entry)
+	} else {
+		j.journal.Resources[i] = entry
+	}
+	j.save()
+}
+
+// provisioned records that the resource resType/name has finished
+// provisioning. It does nothing for a resource the run did not order.
+func (j *runJournal) provisioned(resType, name string) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for i, r := range j.journal.Resources {
+		if r.Type == resType && r.Name == name && r.Status != journalProvisioned {
+			j.journal.Resources[i].Status = journalProvisioned
+			j.journal.Resources[i].UpdatedAt = time.Now().UTC()
+			j.save()
+			return
+		}
+	}
+}
+
+// lookup returns the journal entry for resType/name.
+func (j *runJournal) lookup(resType, name string) (JournalResource, bool) {
+	if j == nil {
+		return JournalResource{}, false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, r := range j.journal.Resources {
+		if r.Type == resType && r.Name == name {
+			return r, true
+		}
+	}
+	return JournalResource{}, false
+}
+
+// fail marks the run as failed with err. It reports whether the run ordered
+// anything, which is what makes it worth resuming.
+func (j *runJournal) fail(err error) bool {
+	if j == nil {
+		return false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if len(j.journal.Resources) == 0 {
+		return false
+	}
+	j.journal.Status = runFailed
+	j.journal.Error = err.Error()
+	j.save()
+	return true
+}
+
+// remove deletes the journal of a run that has finished, and the journal
+// directory once it is empty.
+func (j *runJournal) remove() {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := os.Remove(j.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		output.PrintWarning("Could not remove run journal %s: %v", j.noColor, j.path, err)
+		return
+	}
+	_ = os.Remove(filepath.Dir(j.path)) // fails while other runs' journals remain
+}
+
+// save writes the journal. Like a state file write, a failure is a warning: it
+// only costs the ability to resume the run.
+func (j *runJournal) save() {
+	err := os.MkdirAll(filepath.Dir(j.path), 0o700)
+	if err == nil {
+		err = infra.WriteJSONFile(j.path, j.journal, "run journal")
+	}
+	if err != nil {
+		output.PrintWarning("Could not save the journal of run %s: %v; it cannot be resumed", j.noColor, j.journal.RunID, err)
+	}
+}
+
+// carried returns the orders of a resumed run whose UID the state file still
+// records, so that the resumed run finishes their replacements and locks, and
+// rolls them back if it fails with --rollback-on-failure.
+func (j *runJournal) carried(st *infra.StateFile) []infra.CreatedResource {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var created []infra.CreatedResource
+	for _, r := range j.journal.Resources {
+		if uid, ok := st.Lookup(r.Type, r.Name); ok && uid == r.UID {
+			created = append(created, infra.CreatedResource{ResType: infra.DisplayType(r.Type), Name: r.Name, UID: r.UID, Replaces: r.Replaces, Lock: r.Lock})
+		}
+	}
+	return created
+}
+
+// resumable reports whether n was ordered by the run being resumed, but not
+// seen to finish provisioning, and the order is still live.
+func (r *applyRun) resumable(n graphNode) bool {
+	e, ok := r.journal.lookup(n.resType, n.name)
+	live := r.existing[n.resType][n.name]
+	return ok && e.Status == journalOrdered && live != nil && live.UID == e.UID
+}
+
+// resume waits for an order placed by the run being resumed to finish
+// provisioning, instead of ordering the resource again.
+func (r *applyRun) resume(ctx context.Context, n graphNode) (infra.Result, error) {
+	e, _ := r.journal.lookup(n.resType, n.name)
+	resType := infra.DisplayType(n.resType)
+	r.setUID(n.resType, n.name, e.UID)
+	spinner := r.progress.provisioning(resType, n.name)
+	defer spinner.Stop()
+	if err := waitForProvision(ctx, r.provisionTimeout, resType, n.name, e.UID, func(ctx context.Context) (string, error) {
+		live, err := infra.GetResource(ctx, r.client, n.resType, e.UID)
+		if err != nil {
+			return "", err
+		}
+		return live.Status(), nil
+	}); err != nil {
+		return failure(resType, n.name, e.UID, err, fmt.Errorf("failed to provision %s %q: %w", resultNoun(resType), n.name, err))
+	}
+	spinner.Stop()
+	r.progress.created(resType, e.UID)
+	return infra.Result{Type: resType, Name: n.name, UID: e.UID, Status: provisionedStatus(e.Replaces)}, nil
+}
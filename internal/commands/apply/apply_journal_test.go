@@ -0,0 +1,154 @@
+package apply
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/infra"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const resumeConfig = `
+ports:
+  - {name: Sydney-Port, location_id: 1, speed: 1000, term: 12, locked: true}
+vxcs:
+  - name: Sydney-VXC
+    rate_limit: 100
+    term: 12
+    a_end: {product_uid: "{{.port.Sydney-Port}}"}
+    b_end: {product_uid: "partner-uid"}
+`
+
+// loadJournals reads the journals of the unfinished runs of statePath.
+func loadJournals(t *testing.T, statePath string) []RunJournal {
+	t.Helper()
+	var journals []RunJournal
+	for _, id := range unfinishedRuns(statePath) {
+		data, err := os.ReadFile(filepath.Join(journalDir(statePath), id+".json"))
+		require.NoError(t, err)
+		var j RunJournal
+		require.NoError(t, json.Unmarshal(data, &j))
+		journals = append(journals, j)
+	}
+	return journals
+}
+
+func TestApplyConfig_FailedRunKeepsJournalAndResumes(t *testing.T) {
+	mockPort := &infra.MockPortService{
+		GetPortErr:    errors.New("provisioning wait failed"),
+		GetPortResult: &megaport.Port{Name: "Sydney-Port", LocationID: 1, PortSpeed: 1000, ContractTermMonths: 12},
+	}
+	mockVXC := &infra.MockVXCService{}
+	mockProduct := &infra.MockProductService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+	defer infra.SetupMockProductService(mockProduct)()
+	f := writeTempFile(t, "infra.yaml", resumeConfig)
+	statePath := infra.DefaultStatePath(f)
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.Error(t, err)
+	runs := unfinishedRuns(statePath)
+	require.Len(t, runs, 1, "a failed run keeps its journal")
+	assert.Contains(t, out, "--resume "+runs[0])
+	assert.Nil(t, mockVXC.CapturedVXCRequest)
+
+	journals := loadJournals(t, statePath)
+	assert.Equal(t, runFailed, journals[0].Status)
+	assert.Contains(t, journals[0].Error, "provisioning wait failed")
+	require.Len(t, journals[0].Resources, 1)
+	entry := journals[0].Resources[0]
+	assert.Equal(t, "port", entry.Type)
+	assert.Equal(t, "port-uid-mock", entry.UID)
+	assert.Equal(t, journalOrdered, entry.Status)
+	assert.True(t, entry.Lock)
+
+	// The port comes up. Resuming waits for it rather than ordering it again,
+	// then provisions the VXC and locks the port.
+	mockPort.GetPortErr = nil
+	mockPort.CapturedPortRequest = nil
+	polls := 0
+	mockPort.GetPortStatusFunc = func() string {
+		polls++
+		if polls == 1 {
+			return "DEPLOYABLE" // still provisioning when the resumed run looks it up
+		}
+		return megaport.SERVICE_LIVE
+	}
+	cmd := applyCmd(f, false, true)
+	require.NoError(t, cmd.Flags().Set("resume", runs[0]))
+	output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Nil(t, mockPort.CapturedPortRequest, "an order placed by the failed run is not placed again")
+	assert.Equal(t, 2, polls, "the in-flight order is polled again")
+	require.NotNil(t, mockVXC.CapturedVXCRequest)
+	assert.Equal(t, "port-uid-mock", mockVXC.CapturedVXCRequest.PortUID)
+	assert.Equal(t, []*megaport.ManageProductLockRequest{{ProductID: "port-uid-mock", ShouldLock: true}}, mockProduct.CapturedLockRequests,
+		"the lock the failed run left for its end is applied")
+	assert.Empty(t, unfinishedRuns(statePath), "the journal is removed once the run succeeds")
+}
+
+func TestApplyConfig_NewRunWarnsAboutUnfinishedRun(t *testing.T) {
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	f := writeTempFile(t, "infra.yaml", "ports:\n  - {name: P, location_id: 1, speed: 1000, term: 12}\n")
+	j := newRunJournal(infra.DefaultStatePath(f), f, true)
+	j.ordered(infra.CreatedResource{ResType: "Port", Name: "Old", UID: "old-uid"})
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, "Earlier run(s) did not finish: "+j.id())
+	assert.Equal(t, []string{j.id()}, unfinishedRuns(infra.DefaultStatePath(f)), "only the new run's journal is removed")
+}
+
+func TestApplyConfig_RollbackRemovesJournal(t *testing.T) {
+	mockPort := &infra.MockPortService{GetPortErr: errors.New("provisioning wait failed")}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	f := writeTempFile(t, "infra.yaml", resumeConfig)
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmdWithRollback(f), nil, true, "table")
+	})
+	require.Error(t, err)
+	assert.Empty(t, unfinishedRuns(infra.DefaultStatePath(f)), "a rolled-back run cannot be resumed")
+}
+
+func TestApplyConfig_ResumeUnknownRun(t *testing.T) {
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	f := writeTempFile(t, "infra.yaml", resumeConfig)
+	j := newRunJournal(infra.DefaultStatePath(f), f, true)
+	j.ordered(infra.CreatedResource{ResType: "Port", Name: "Sydney-Port", UID: "port-uid"})
+
+	cmd := applyCmd(f, false, true)
+	require.NoError(t, cmd.Flags().Set("resume", "20000101T000000Z"))
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no unfinished run "20000101T000000Z"`)
+	assert.Contains(t, err.Error(), "runs that can be resumed: "+j.id())
+}
+
+func TestOpenRunJournal_OtherConfig(t *testing.T) {
+	f := writeTempFile(t, "infra.yaml", resumeConfig)
+	j := newRunJournal(infra.DefaultStatePath(f), f, true)
+	j.ordered(infra.CreatedResource{ResType: "Port", Name: "Sydney-Port", UID: "port-uid"})
+
+	_, err := openRunJournal(infra.DefaultStatePath(f), filepath.Join(t.TempDir(), "other.yaml"), j.id(), true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "applied "+f)
+}
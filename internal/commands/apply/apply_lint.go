@@ -0,0 +1,271 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/commands/vxc"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/megaport/megaport-cli/internal/validation"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// LintProblem is one mistake lint found in a config file. Path locates it in
+// the config after includes are merged, e.g. ports[0].term.
+type LintProblem struct {
+	output.Output `json:"-" header:"-"`
+	Path          string `json:"path"    header:"Path"`
+	Message       string `json:"message" header:"Message"`
+}
+
+// SchemaConfig prints the JSON Schema of apply config files, or writes it to
+// --file.
+func SchemaConfig(cmd *cobra.Command, _ []string, noColor bool) error {
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	filePath, _ := cmd.Flags().GetString("file")
+
+	data, err := json.MarshalIndent(configSchema(), "", "  ")
+	if err != nil {
+		output.PrintError("Failed to encode schema: %v", noColor, err)
+		return err
+	}
+	data = append(data, '\n')
+	if filePath == "" {
+		fmt.Print(string(data))
+		return nil
+	}
+	if err := os.WriteFile(filePath, data, 0600); err != nil {
+		output.PrintError("Failed to write schema: %v", noColor, err)
+		return fmt.Errorf("writing schema: %w", err)
+	}
+	output.PrintSuccess("Wrote the apply config schema to %s", noColor, filePath)
+	return nil
+}
+
+// LintConfig checks a config file against the config schema and the rules
+// apply checks before placing each order, without logging in.
+func LintConfig(cmd *cobra.Command, _ []string, noColor bool, outputFormat string) error {
+	output.SetOutputFormat(outputFormat)
+
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	filePath, _ := cmd.Flags().GetString("file")
+	policyPath, _ := cmd.Flags().GetString("policy")
+	if filePath == "" {
+		output.PrintError("--file is required", noColor)
+		return exitcodes.NewUsageError(fmt.Errorf("--file is required"))
+	}
+
+	vars, err := infra.ConfigVars(cmd)
+	if err != nil {
+		output.PrintError("Invalid variables: %v", noColor, err)
+		return exitcodes.NewUsageError(err)
+	}
+	project := utils.ProjectSettingsFromCmd(cmd)
+	problems, err := lintConfigFile(filePath, vars, project)
+	if err != nil {
+		output.PrintError("Failed to parse config file: %v", noColor, err)
+		return exitcodes.NewUsageError(err)
+	}
+	if policyPath != "" {
+		rules, err := infra.LoadPolicy(policyPath)
+		if err != nil {
+			output.PrintError("Invalid policy file: %v", noColor, err)
+			return exitcodes.NewUsageError(err)
+		}
+		// A config that does not match the schema may not decode; its policy
+		// is checked once the schema problems are fixed.
+		if cfg, err := infra.ParseConfigFile(filePath, vars, project); err == nil {
+			for _, v := range infra.EvaluatePolicy(rules, cfg) {
+				problems = append(problems, LintProblem{Path: v.Path, Message: fmt.Sprintf("violates policy rule %q: %s", v.Rule, v.Message)})
+			}
+		}
+	}
+	if len(problems) == 0 {
+		output.PrintSuccess("%s is valid", noColor, filePath)
+		return nil
+	}
+	if err := output.PrintOutput(problems, outputFormat, noColor); err != nil {
+		return err
+	}
+	return exitcodes.NewUsageError(fmt.Errorf("found %d problem(s) in %s", len(problems), filePath))
+}
+
+// lintConfigFile resolves filePath's includes and variables as apply does, and
+// checks the result against the config schema. A config that matches it is
+// decoded and given the checks of lintConfig. An error means the file could
+// not be read or resolved at all.
+func lintConfigFile(filePath string, vars map[string]*yaml.Node, project utils.ProjectSettings) ([]LintProblem, error) {
+	data, err := infra.ReadConfigData(filePath)
+	if err != nil {
+		return nil, err
+	}
+	doc, _, err := infra.LoadConfigNode(filePath, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := infra.ExpandConfigNode(doc, vars, project.Variables); err != nil {
+		return nil, err
+	}
+	var tree interface{}
+	if err := doc.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	schema := configSchema()
+	if problems := schema.validate(schema, "", tree); len(problems) > 0 {
+		return problems, nil
+	}
+	cfg, err := infra.ParseConfigFile(filePath, vars, project)
+	if err != nil {
+		return nil, err
+	}
+	return lintConfig(cfg, project.ResourceTags), nil
+}
+
+// lintConfig runs the checks apply makes before placing orders that need no
+// API: unique names, references to declared entries without cycles, outputs
+// that name declared entries and their attributes, and the validation
+// package's rules for each order, as a dry run makes them, with defaultTags
+// added to each order's resource tags.
+func lintConfig(cfg *infra.Config, defaultTags map[string]string) []LintProblem {
+	var problems []LintProblem
+	add := func(key string, i int, err error) {
+		if err != nil {
+			problems = append(problems, LintProblem{Path: fmt.Sprintf("%s[%d]", key, i), Message: err.Error()})
+		}
+	}
+
+	names := map[string]map[string]bool{}
+	unique := func(key, resType, name string, i int) {
+		if names[resType] == nil {
+			names[resType] = map[string]bool{}
+		}
+		if names[resType][name] {
+			add(key, i, fmt.Errorf("another %s is named %q; names identify entries in the state file and in references", resultNoun(infra.DisplayType(resType)), name))
+		}
+		names[resType][name] = true
+	}
+	for i, p := range cfg.Ports {
+		unique("ports", "port", p.Name, i)
+	}
+	for i, m := range cfg.MCRs {
+		unique("mcrs", "mcr", m.Name, i)
+	}
+	for i, mv := range cfg.MVEs {
+		unique("mves", "mve", mv.Name, i)
+	}
+	for i, n := range cfg.NATGateways {
+		unique("nat_gateways", "nat_gateway", n.Name, i)
+	}
+	for i, x := range cfg.IXs {
+		unique("ixs", "ix", x.Name, i)
+	}
+	for i, k := range cfg.ServiceKeys {
+		unique("service_keys", "service_key", k.Name, i)
+	}
+	for i, v := range cfg.VXCs {
+		unique("vxcs", "vxc", v.Name, i)
+	}
+	if _, err := buildGraph(cfg); err != nil {
+		problems = append(problems, LintProblem{Message: err.Error()})
+	}
+	declared := infra.DeclaredNames(cfg)
+	for _, key := range infra.SortedKeys(cfg.Outputs) {
+		if err := checkOutput(cfg.Outputs[key], declared); err != nil {
+			problems = append(problems, LintProblem{Path: "outputs." + key, Message: err.Error()})
+		}
+	}
+
+	for i, p := range cfg.Ports {
+		add("ports", i, validatePortRequest(portRequest(p, defaultTags)))
+	}
+	for i, m := range cfg.MCRs {
+		err := validation.ValidateIPSecTunnelCount(m.TunnelCount, true)
+		if err == nil {
+			_, err = prefixFilterListRequests("", m.PrefixFilterLists)
+		}
+		if err == nil {
+			err = validation.ValidateMCRRequest(mcrRequest(m, defaultTags))
+		}
+		add("mcrs", i, err)
+	}
+	for i, mv := range cfg.MVEs {
+		req, err := mveRequest(mv, defaultTags)
+		if err != nil {
+			err = fmt.Errorf("invalid vendor_config: %w", err)
+		} else {
+			err = validation.ValidateBuyMVERequest(req)
+		}
+		add("mves", i, err)
+	}
+	for i, n := range cfg.NATGateways {
+		add("nat_gateways", i, validation.ValidateCreateNATGatewayRequest(natGatewayRequest(n, defaultTags)))
+	}
+
+	uids := placeholderUIDs(cfg)
+	for i, x := range cfg.IXs {
+		productUID, err := infra.ResolveTemplates(x.ProductUID, uids)
+		if err == nil {
+			err = validation.ValidateIXRequest(ixRequest(x, productUID))
+		}
+		add("ixs", i, err)
+	}
+	for i, k := range cfg.ServiceKeys {
+		productUID, err := infra.ResolveTemplates(k.ProductUID, uids)
+		if err == nil {
+			err = validation.ValidateCreateServiceKeyRequest(serviceKeyRequest(k, productUID))
+		}
+		add("service_keys", i, err)
+	}
+	for i, v := range cfg.VXCs {
+		add("vxcs", i, lintVXC(v, uids, defaultTags))
+	}
+	return problems
+}
+
+// lintVXC checks a VXC's order without the API. An end that leaves its partner
+// port to be looked up from its key is given a placeholder UID instead.
+func lintVXC(v infra.VXCConfig, uids map[string]map[string]string, defaultTags map[string]string) error {
+	aUID, err := offlineEndpointUID(v.AEnd, uids)
+	if err != nil {
+		return fmt.Errorf("a_end: %w", err)
+	}
+	bUID, err := offlineEndpointUID(v.BEnd, uids)
+	if err != nil {
+		return fmt.Errorf("b_end: %w", err)
+	}
+	req, err := infra.VXCRequest(context.Background(), nil, v, aUID, bUID, defaultTags)
+	if err != nil {
+		return err
+	}
+	return validation.ValidateVXCRequest(req)
+}
+
+// offlineEndpointUID resolves the product_uid of a VXC end against uids. An
+// end with no product_uid gets dryRunPlaceholder when its partner port can be
+// looked up from its partner_config, so VXCRequest makes no API call.
+func offlineEndpointUID(e infra.VXCEndpointConfig, uids map[string]map[string]string) (string, error) {
+	if e.ProductUID != "" || e.PartnerConfig == nil {
+		return infra.ResolveTemplates(e.ProductUID, uids)
+	}
+	raw, err := infra.NormalizeVendorConfigMap(e.PartnerConfig)
+	if err != nil {
+		return "", fmt.Errorf("invalid partner_config: %w", err)
+	}
+	pc, err := vxc.ParsePartnerConfig(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid partner_config: %w", err)
+	}
+	switch pc.(type) {
+	case *megaport.VXCPartnerConfigAzure, *megaport.VXCPartnerConfigGoogle, *megaport.VXCPartnerConfigOracle:
+		return dryRunPlaceholder, nil
+	}
+	return "", errors.New("product_uid is required; only Azure, Google and Oracle partner ports can be looked up from their key")
+}
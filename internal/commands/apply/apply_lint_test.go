@@ -0,0 +1,119 @@
+package apply
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintConfigFile_SchemaProblemsAfterVariables(t *testing.T) {
+	f := writeTempFile(t, "region.yaml", `
+variables:
+  term:
+ports:
+  - {name: P, location_id: 1, speed: 1000, term: "{{.var.term}}"}
+`)
+	vars, err := infra.ConfigVars(varsCmd(nil, "term=13"))
+	require.NoError(t, err)
+	problems, err := lintConfigFile(f, vars, utils.ProjectSettings{})
+	require.NoError(t, err)
+	assert.Equal(t, []LintProblem{{Path: "ports[0].term", Message: "13 is not allowed; must be one of: 1, 12, 24, 36, 48, 60"}}, problems)
+}
+
+func TestLintConfigFile_LocalChecks(t *testing.T) {
+	f := writeTempFile(t, "infra.yaml", `
+ports:
+  - {name: P, location_id: 1, speed: 1000, term: 12}
+  - {name: P, location_id: 1, speed: 10000, term: 12, lag_count: 2}
+mcrs:
+  - {name: R, location_id: 1, speed: 1000, term: 12, tunnel_count: 15}
+mves:
+  - {name: M, location_id: 1, term: 12, vendor_config: {vendor: cisco}}
+vxcs:
+  - name: Typo
+    rate_limit: 100
+    term: 12
+    a_end: {product_uid: "{{.port.Q}}"}
+    b_end: {product_uid: "{{.mcr.R}}"}
+  - name: AWS
+    rate_limit: 100
+    term: 12
+    a_end: {product_uid: "{{.port.P}}"}
+    b_end: {partner_config: {connectType: AWS, ownerAccount: "123456789012"}}
+  - name: Azure
+    rate_limit: 100
+    term: 12
+    a_end: {product_uid: "{{.port.P}}"}
+    b_end: {partner_config: {connectType: AZURE, serviceKey: "1b2329a5-56dc-45d0-8a0d-87b706297777"}}
+`)
+	problems, err := lintConfigFile(f, nil, utils.ProjectSettings{})
+	require.NoError(t, err)
+
+	byPath := map[string]string{}
+	for _, p := range problems {
+		byPath[p.Path] = p.Message
+	}
+	assert.Contains(t, byPath["ports[1]"], `another port is named "P"`)
+	assert.Contains(t, byPath["mcrs[0]"], "tunnel")
+	assert.Contains(t, byPath["mves[0]"], "invalid vendor_config")
+	assert.Contains(t, byPath["vxcs[0]"], `no UID found for reference "{{.port.Q}}"`)
+	assert.Contains(t, byPath["vxcs[1]"], "b_end: product_uid is required")
+	assert.NotContains(t, byPath, "vxcs[2]", "an Azure end's port is looked up when applied")
+	assert.Len(t, problems, 5)
+}
+
+func TestLintConfigFile_Cycle(t *testing.T) {
+	f := writeTempFile(t, "infra.yaml", `
+ixs:
+  - {name: IX, product_uid: "{{.service_key.Key}}", network_service_type: Los Angeles IX, asn: 65000, mac_address: "00:11:22:33:44:55", rate_limit: 1000}
+service_keys:
+  - {name: Key, product_uid: "{{.ix.IX}}", max_speed: 1000}
+`)
+	problems, err := lintConfigFile(f, nil, utils.ProjectSettings{})
+	require.NoError(t, err)
+	require.NotEmpty(t, problems)
+	assert.Contains(t, problems[0].Message, "reference cycle")
+}
+
+func TestLintConfig_ExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("ports:\n  - {name: P, location_id: 1, speed: 1000, term: 12}\n"), 0o600))
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("ports:\n  - {name: P, location_id: 1, speed: 1000, term: 7}\n"), 0o600))
+
+	var err error
+	output.CaptureOutput(func() {
+		err = LintConfig(varsCmdWithFile(valid), nil, true, "table")
+	})
+	assert.NoError(t, err)
+
+	out := output.CaptureOutput(func() {
+		err = LintConfig(varsCmdWithFile(invalid), nil, true, "json")
+	})
+	var cliErr *exitcodes.CLIError
+	require.True(t, errors.As(err, &cliErr))
+	assert.Equal(t, exitcodes.Usage, cliErr.Code)
+	var problems []LintProblem
+	require.NoError(t, json.Unmarshal([]byte(out), &problems))
+	assert.Equal(t, []LintProblem{{Path: "ports[0].term", Message: "7 is not allowed; must be one of: 1, 12, 24, 36, 48, 60"}}, problems)
+}
+
+// varsCmdWithFile builds a command with the flags lint reads.
+func varsCmdWithFile(file string) *cobra.Command {
+	cmd := varsCmd(nil)
+	cmd.Flags().String("file", "", "")
+	cmd.Flags().String("policy", "", "")
+	_ = cmd.Flags().Set("file", file)
+	return cmd
+}
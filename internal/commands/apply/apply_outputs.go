@@ -0,0 +1,281 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/utils"
+	megaport "github.com/megaport/megaportgo"
+)
+
+// outputsVersion is the schema version written to new outputs files.
+const outputsVersion = 1
+
+// Outputs file statuses.
+const (
+	outputsSucceeded = "succeeded"
+	outputsFailed    = "failed"
+)
+
+// ApplyOutputs is the artifact --outputs-file writes after a run, for the tools
+// that consume what apply provisioned. Outputs holds the config's outputs block
+// resolved against the provisioned resources; it is only set when the run
+// succeeded. RunID is set when a failed run can be continued with --resume.
+type ApplyOutputs struct {
+	Version     int                    `json:"version"`
+	Config      string                 `json:"config"` // absolute path of the config file
+	Status      string                 `json:"status"`
+	Error       string                 `json:"error,omitempty"`
+	RunID       string                 `json:"run_id,omitempty"`
+	CompletedAt time.Time              `json:"completed_at"`
+	Outputs     map[string]interface{} `json:"outputs,omitempty"`
+	Results     []infra.Result         `json:"results"`
+}
+
+// newApplyOutputs returns the artifact of a run of configPath that produced
+// results and ended with runErr.
+func newApplyOutputs(configPath string, results []infra.Result, runErr error) *ApplyOutputs {
+	abs, err := filepath.Abs(configPath)
+	if err != nil {
+		abs = configPath
+	}
+	out := &ApplyOutputs{Version: outputsVersion, Config: abs, Status: outputsSucceeded, CompletedAt: time.Now().UTC(), Results: results}
+	if out.Results == nil {
+		out.Results = []infra.Result{}
+	}
+	if runErr != nil {
+		out.Status = outputsFailed
+		out.Error = runErr.Error()
+	}
+	return out
+}
+
+// outputRef is a {{.type.name}} or {{.type.name.attribute}} reference in an
+// output. A reference without an attribute stands for the UID.
+type outputRef struct {
+	resType string
+	name    string
+	attr    string
+}
+
+// parseOutputRef parses the submatches of a TemplateRe match in an output.
+// Names may contain dots, so the whole of sub[2] is tried as a name before its
+// last dot is taken to start an attribute.
+func parseOutputRef(sub []string, names map[string]map[string]bool) (outputRef, error) {
+	resType, rest := sub[1], sub[2]
+	declared, ok := names[resType]
+	if !ok {
+		return outputRef{}, fmt.Errorf("%s: unknown resource type %q; use one of: %s", sub[0], resType, strings.Join(infra.ResourceTypes, ", "))
+	}
+	if declared[rest] {
+		return outputRef{resType: resType, name: rest, attr: "uid"}, nil
+	}
+	i := strings.LastIndex(rest, ".")
+	if i < 0 || !declared[rest[:i]] {
+		return outputRef{}, fmt.Errorf("%s: the config declares no %s named %q", sub[0], resultNoun(infra.DisplayType(resType)), rest)
+	}
+	ref := outputRef{resType: resType, name: rest[:i], attr: rest[i+1:]}
+	attrs := attributeNames(resType)
+	if !slices.Contains(attrs, ref.attr) {
+		return outputRef{}, fmt.Errorf("%s: %s has no attribute %q; use one of: %s", sub[0], resultNoun(infra.DisplayType(resType)), ref.attr, strings.Join(attrs, ", "))
+	}
+	return ref, nil
+}
+
+// checkOutput reports every reference in an output value that does not name a
+// declared entry and one of its attributes.
+func checkOutput(value string, names map[string]map[string]bool) error {
+	var errs error
+	for _, sub := range infra.TemplateRe.FindAllStringSubmatch(value, -1) {
+		if _, err := parseOutputRef(sub, names); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+	return errs
+}
+
+// checkOutputs checks the references of cfg's outputs block before anything is
+// ordered, so a mistyped output does not surface only after the run.
+func checkOutputs(cfg *infra.Config) error {
+	names := infra.DeclaredNames(cfg)
+	var errs error
+	for _, key := range infra.SortedKeys(cfg.Outputs) {
+		if err := checkOutput(cfg.Outputs[key], names); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("outputs.%s: %w", key, err))
+		}
+	}
+	return errs
+}
+
+// resolveOutputs resolves cfg's outputs block once the run has succeeded. UIDs
+// come from the run; other attributes are read from the API, once for each
+// resource referenced. A value that is a single reference keeps its
+// attribute's type, so a VLAN is written as a number; any other value is a
+// string with each reference substituted.
+func (r *applyRun) resolveOutputs(ctx context.Context, cfg *infra.Config) (map[string]interface{}, error) {
+	names := infra.DeclaredNames(cfg)
+	uids := r.uidSnapshot()
+	fetched := map[outputRef]map[string]interface{}{}
+
+	lookup := func(sub []string) (interface{}, error) {
+		ref, err := parseOutputRef(sub, names)
+		if err != nil {
+			return nil, err
+		}
+		uid, ok := uids[ref.resType][ref.name]
+		if !ok {
+			return nil, fmt.Errorf("%s: no UID was recorded for %s %q", sub[0], resultNoun(infra.DisplayType(ref.resType)), ref.name)
+		}
+		if ref.attr == "uid" {
+			return uid, nil
+		}
+		key := outputRef{resType: ref.resType, name: ref.name}
+		attrs, ok := fetched[key]
+		if !ok {
+			var live *infra.LiveResource
+			err := utils.WithIdempotentRetry(ctx, func(ctx context.Context) error {
+				var e error
+				live, e = infra.GetResource(ctx, r.client, ref.resType, uid)
+				return e
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%s: reading %s %q: %w", sub[0], resultNoun(infra.DisplayType(ref.resType)), ref.name, err)
+			}
+			attrs = resourceAttributes(live)
+			fetched[key] = attrs
+		}
+		return attrs[ref.attr], nil
+	}
+
+	outputs := make(map[string]interface{}, len(cfg.Outputs))
+	var errs error
+	for _, key := range infra.SortedKeys(cfg.Outputs) {
+		value := cfg.Outputs[key]
+		var v interface{}
+		var err error
+		if sub := infra.TemplateRe.FindStringSubmatch(value); sub != nil && sub[0] == value {
+			v, err = lookup(sub)
+		} else {
+			v, err = infra.ExpandTemplates(value, infra.TemplateRe, func(sub []string) (string, error) {
+				v, err := lookup(sub)
+				return fmt.Sprint(v), err
+			})
+		}
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("outputs.%s: %w", key, err))
+			continue
+		}
+		outputs[key] = v
+	}
+	return outputs, errs
+}
+
+// printOutputs lists resolved outputs after the results table. JSON output
+// keeps stdout to the results; outputs reach pipelines through --outputs-file.
+func printOutputs(outputs map[string]interface{}, outputFormat string, noColor bool) {
+	if len(outputs) == 0 || outputFormat == "json" {
+		return
+	}
+	output.PrintInfo("Outputs:", noColor)
+	for _, key := range infra.SortedKeys(outputs) {
+		output.PrintInfo("  %s = %v", noColor, key, outputs[key])
+	}
+}
+
+// attributeNames lists the attributes outputs can reference on resType.
+func attributeNames(resType string) []string {
+	live := &infra.LiveResource{}
+	switch resType {
+	case "port":
+		live.Port = &megaport.Port{}
+	case "mcr":
+		live.MCR = &megaport.MCR{}
+	case "mve":
+		live.MVE = &megaport.MVE{}
+	case "nat_gateway":
+		live.NATGateway = &megaport.NATGateway{}
+	case "ix":
+		live.IX = &megaport.IX{}
+	case "service_key":
+		live.ServiceKey = &megaport.ServiceKey{}
+	case "vxc":
+		live.VXC = &megaport.VXC{}
+	}
+	return infra.SortedKeys(resourceAttributes(live))
+}
+
+// resourceAttributes returns the attributes of a live resource that outputs
+// can reference. Keys follow the config file's field names.
+func resourceAttributes(r *infra.LiveResource) map[string]interface{} {
+	attrs := map[string]interface{}{"uid": r.UID, "status": r.Status()}
+	switch {
+	case r.Port != nil:
+		attrs["name"] = r.Port.Name
+		attrs["location_id"] = r.Port.LocationID
+		attrs["location"] = locationName(r.Port.LocationDetails)
+		attrs["speed"] = r.Port.PortSpeed
+		attrs["term"] = r.Port.ContractTermMonths
+		attrs["diversity_zone"] = r.Port.DiversityZone
+	case r.MCR != nil:
+		attrs["name"] = r.MCR.Name
+		attrs["location_id"] = r.MCR.LocationID
+		attrs["location"] = locationName(r.MCR.LocationDetails)
+		attrs["speed"] = r.MCR.PortSpeed
+		attrs["term"] = r.MCR.ContractTermMonths
+		attrs["asn"] = r.MCR.Resources.VirtualRouter.ASN
+	case r.MVE != nil:
+		attrs["name"] = r.MVE.Name
+		attrs["location_id"] = r.MVE.LocationID
+		attrs["location"] = locationName(r.MVE.LocationDetails)
+		attrs["term"] = r.MVE.ContractTermMonths
+		attrs["vendor"] = r.MVE.Vendor
+		attrs["size"] = r.MVE.Size
+	case r.NATGateway != nil:
+		attrs["name"] = r.NATGateway.ProductName
+		attrs["location_id"] = r.NATGateway.LocationID
+		attrs["speed"] = r.NATGateway.Speed
+		attrs["term"] = r.NATGateway.Term
+		attrs["asn"] = r.NATGateway.Config.ASN
+	case r.IX != nil:
+		attrs["name"] = r.IX.ProductName
+		attrs["location_id"] = r.IX.LocationID
+		attrs["location"] = r.IX.LocationDetail.Name
+		attrs["vlan"] = r.IX.VLAN
+		attrs["asn"] = r.IX.ASN
+		attrs["mac_address"] = r.IX.MACAddress
+		attrs["rate_limit"] = r.IX.RateLimit
+	case r.ServiceKey != nil:
+		attrs["key"] = r.ServiceKey.Key
+		attrs["product_uid"] = r.ServiceKey.ProductUID
+		attrs["vlan"] = r.ServiceKey.VLAN
+		attrs["max_speed"] = r.ServiceKey.MaxSpeed
+		attrs["active"] = r.ServiceKey.Active
+	case r.VXC != nil:
+		attrs["name"] = r.VXC.Name
+		attrs["rate_limit"] = r.VXC.RateLimit
+		attrs["term"] = r.VXC.ContractTermMonths
+		for prefix, end := range map[string]megaport.VXCEndConfiguration{"a_end_": r.VXC.AEndConfiguration, "b_end_": r.VXC.BEndConfiguration} {
+			attrs[prefix+"uid"] = end.UID
+			attrs[prefix+"vlan"] = end.VLAN
+			attrs[prefix+"inner_vlan"] = end.InnerVLAN
+			attrs[prefix+"location_id"] = end.LocationID
+			attrs[prefix+"location"] = end.Location
+		}
+	}
+	return attrs
+}
+
+// locationName returns the name of a product's location, if the API gave one.
+func locationName(d *megaport.ProductLocationDetails) string {
+	if d == nil {
+		return ""
+	}
+	return d.Name
+}
@@ -0,0 +1,145 @@
+package apply
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/utils"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const outputsConfig = `
+ports:
+  - {name: Sydney.Port, location_id: 1, speed: 1000, term: 12}
+vxcs:
+  - name: AWS
+    rate_limit: 100
+    term: 12
+    a_end: {product_uid: "{{.port.Sydney.Port}}", vlan: 100}
+    b_end: {product_uid: some-uid}
+outputs:
+  port_uid: "{{.port.Sydney.Port}}"
+  port_location: "{{.port.Sydney.Port.location}}"
+  vlan: "{{.vxc.AWS.a_end_vlan}}"
+  summary: "VLAN {{.vxc.AWS.a_end_vlan}} on {{.port.Sydney.Port}}"
+`
+
+// readOutputsFile decodes an outputs file.
+func readOutputsFile(t *testing.T, path string) ApplyOutputs {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var out ApplyOutputs
+	require.NoError(t, json.Unmarshal(data, &out))
+	return out
+}
+
+func TestApplyConfig_OutputsFile(t *testing.T) {
+	mockPort := &infra.MockPortService{
+		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-uid-abc"}},
+		GetPortResult: &megaport.Port{LocationDetails: &megaport.ProductLocationDetails{Name: "Equinix SY1"}},
+	}
+	mockVXC := &infra.MockVXCService{
+		GetVXCResult: &megaport.VXC{AEndConfiguration: megaport.VXCEndConfiguration{VLAN: 100}},
+	}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "config.yaml", outputsConfig)
+	outputsPath := filepath.Join(t.TempDir(), "outputs.json")
+	cmd := applyCmd(f, false, true)
+	require.NoError(t, cmd.Flags().Set("outputs-file", outputsPath))
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, "vlan = 100")
+
+	artifact := readOutputsFile(t, outputsPath)
+	assert.Equal(t, outputsSucceeded, artifact.Status)
+	assert.Equal(t, map[string]interface{}{
+		"port_uid":      "port-uid-abc",
+		"port_location": "Equinix SY1",
+		"vlan":          float64(100),
+		"summary":       "VLAN 100 on port-uid-abc",
+	}, artifact.Outputs)
+	require.Len(t, artifact.Results, 2)
+	assert.Equal(t, "port-uid-abc", artifact.Results[0].UID)
+	assert.Equal(t, "vxc-uid-mock-1", artifact.Results[1].UID)
+}
+
+func TestApplyConfig_OutputsFileOnFailure(t *testing.T) {
+	mockPort := &infra.MockPortService{
+		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-uid-abc"}},
+	}
+	mockVXC := &infra.MockVXCService{BuyVXCErr: errors.New("no capacity")}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "config.yaml", outputsConfig)
+	outputsPath := filepath.Join(t.TempDir(), "outputs.json")
+	cmd := applyCmd(f, false, true)
+	require.NoError(t, cmd.Flags().Set("outputs-file", outputsPath))
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.Error(t, err)
+
+	artifact := readOutputsFile(t, outputsPath)
+	assert.Equal(t, outputsFailed, artifact.Status)
+	assert.Contains(t, artifact.Error, "no capacity")
+	assert.NotEmpty(t, artifact.RunID, "the port was ordered, so the run can be resumed")
+	assert.Nil(t, artifact.Outputs)
+	require.NotEmpty(t, artifact.Results)
+	assert.Equal(t, "port-uid-abc", artifact.Results[0].UID)
+}
+
+func TestApplyConfig_InvalidOutputReference(t *testing.T) {
+	mockPort := &infra.MockPortService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	f := writeTempFile(t, "config.yaml", `
+ports:
+  - {name: P, location_id: 1, speed: 1000, term: 12}
+outputs:
+  vlan: "{{.port.P.vlan}}"
+`)
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	var cliErr *exitcodes.CLIError
+	require.True(t, errors.As(err, &cliErr))
+	assert.Equal(t, exitcodes.Usage, cliErr.Code)
+	assert.Contains(t, err.Error(), `outputs.vlan: {{.port.P.vlan}}: port has no attribute "vlan"`)
+	assert.Nil(t, mockPort.CapturedPortRequest, "nothing is ordered")
+}
+
+func TestLintConfigFile_Outputs(t *testing.T) {
+	f := writeTempFile(t, "infra.yaml", `
+ports:
+  - {name: P, location_id: 1, speed: 1000, term: 12}
+outputs:
+  uid: "{{.port.P}}"
+  location: "{{.port.P.location}}"
+  typo: "{{.port.Q.location}}"
+  type: "{{.ports.P}}"
+`)
+	problems, err := lintConfigFile(f, nil, utils.ProjectSettings{})
+	require.NoError(t, err)
+	require.Len(t, problems, 2)
+	assert.Equal(t, "outputs.type", problems[0].Path)
+	assert.Contains(t, problems[0].Message, `unknown resource type "ports"`)
+	assert.Equal(t, "outputs.typo", problems[1].Path)
+	assert.Contains(t, problems[1].Message, `the config declares no port named "Q.location"`)
+}
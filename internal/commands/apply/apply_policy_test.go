@@ -0,0 +1,101 @@
+package apply
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+rules:
+  - name: term-approval
+    type: max_term
+    max: 12
+    approval_tag: term-approved-by
+  - type: require_cost_centre
+    resources: [port, vxc]
+  - type: required_tags
+    keys: [owner, env]
+  - type: allowed_locations
+    location_ids: [1, 2]
+  - type: max_rate_limit
+    max: 1000
+`
+
+const policyConfig = `
+ports:
+  - name: Compliant
+    location_id: 1
+    speed: 1000
+    term: 12
+    cost_centre: NET-1
+    resource_tags: {owner: netops, env: prod}
+  - name: Approved
+    location_id: 2
+    speed: 1000
+    term: 36
+    cost_centre: NET-1
+    resource_tags: {owner: netops, env: prod, term-approved-by: cfo}
+  - name: Breaks
+    location_id: 9
+    speed: 1000
+    term: 24
+    resource_tags: {owner: netops}
+ixs:
+  - {name: IX, product_uid: "{{.port.Compliant}}", network_service_type: Los Angeles IX, asn: 65000, mac_address: "00:11:22:33:44:55", rate_limit: 10000}
+vxcs:
+  - name: V
+    rate_limit: 5000
+    term: 12
+    cost_centre: NET-1
+    resource_tags: {owner: netops, env: prod}
+    a_end: {product_uid: "{{.port.Compliant}}"}
+    b_end: {product_uid: "{{.port.Approved}}"}
+`
+
+func TestApplyConfig_PolicyViolation(t *testing.T) {
+	mockPort := &infra.MockPortService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	cmd := applyCmd(writeTempFile(t, "infra.yaml", policyConfig), false, true)
+	require.NoError(t, cmd.Flags().Set("policy", writeTempFile(t, "policy.yaml", testPolicy)))
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "json")
+	})
+	var cliErr *exitcodes.CLIError
+	require.True(t, errors.As(err, &cliErr))
+	assert.Equal(t, exitcodes.PolicyViolated, cliErr.Code)
+	assert.Contains(t, err.Error(), "6 policy violation(s)")
+	assert.Nil(t, mockPort.CapturedPortRequest, "nothing is ordered")
+
+	var violations []infra.PolicyViolation
+	require.NoError(t, json.Unmarshal([]byte(out), &violations))
+	assert.Len(t, violations, 6)
+}
+
+func TestLintConfig_Policy(t *testing.T) {
+	cmd := varsCmdWithFile(writeTempFile(t, "infra.yaml", policyConfig))
+	require.NoError(t, cmd.Flags().Set("policy", writeTempFile(t, "policy.yaml", "rules:\n  - {type: max_term, max: 12}\n")))
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = LintConfig(cmd, nil, true, "json")
+	})
+	var cliErr *exitcodes.CLIError
+	require.True(t, errors.As(err, &cliErr))
+	assert.Equal(t, exitcodes.Usage, cliErr.Code)
+	var problems []LintProblem
+	require.NoError(t, json.Unmarshal([]byte(out), &problems))
+	assert.Equal(t, []LintProblem{
+		{Path: "ports[1]", Message: `violates policy rule "max_term": term of 36 months exceeds the maximum of 12`},
+		{Path: "ports[2]", Message: `violates policy rule "max_term": term of 24 months exceeds the maximum of 12`},
+	}, problems)
+}
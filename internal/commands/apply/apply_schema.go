@@ -0,0 +1,348 @@
+package apply
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/validation"
+)
+
+// schemaDialect is the JSON Schema draft the config schema is written in.
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// referencePattern matches a value that is a single {{.var.name}} or
+// ${env:NAME} reference. Such a value takes on the type of the value it refers
+// to, so it can stand in for a number, a list or a mapping.
+const referencePattern = `^(\{\{\.var\.[^}]+\}\}|\$\{env:[^}]+\})$`
+
+// jsonSchema is the subset of JSON Schema that describes an apply config.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"` // false, or the schema of the other properties
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AnyOf                []*jsonSchema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*jsonSchema `json:"$defs,omitempty"`
+
+	allowed string // the values Pattern accepts, for problem messages
+}
+
+// configSchema returns the JSON Schema of an apply config file as it is
+// written, before its includes are merged and its variables substituted.
+// Fields whose values the API limits carry the values the validation package
+// accepts, so editors can complete them and flag mistakes as they are typed.
+func configSchema() *jsonSchema {
+	g := &schemaGenerator{
+		defs: map[string]*jsonSchema{
+			"reference": {
+				Description: "A {{.var.name}} or ${env:NAME} reference, which takes on the type of the value it refers to",
+				Type:        "string",
+				Pattern:     referencePattern,
+			},
+		},
+		fields: fieldSchemas(),
+	}
+	root := g.object(reflect.TypeOf(infra.Config{}))
+	root.Schema = schemaDialect
+	root.Title = "megaport-cli apply config"
+	root.Properties[infra.IncludeKey] = &jsonSchema{
+		Description: "Config files to merge into this one, relative to it",
+		AnyOf:       []*jsonSchema{{Type: "string"}, {Type: "array", Items: &jsonSchema{Type: "string"}}},
+	}
+	root.Properties[infra.VariablesKey] = &jsonSchema{
+		Description: "Variables referenced as {{.var.name}}; a variable with no value must be set with --var or --var-file",
+		Type:        "object",
+	}
+	root.Defs = g.defs
+	return root
+}
+
+// fieldSchemas returns the schemas of the fields whose values are limited
+// beyond their Go type, keyed by the config type's name and the field's key.
+func fieldSchemas() map[string]*jsonSchema {
+	term := &jsonSchema{Description: "Contract term in months", Type: "integer", Enum: enumOf(validation.ValidContractTerms)}
+	fields := map[string]*jsonSchema{
+		"PortConfig.speed":                      {Description: "Port speed in Mbps", Type: "integer", Enum: enumOf(validation.ValidPortSpeeds)},
+		"MCRConfig.speed":                       {Description: "MCR speed in Mbps", Type: "integer", Enum: enumOf(validation.ValidMCRPortSpeeds)},
+		"PrefixFilterListConfig.address_family": {Type: "string", Enum: enumOf([]string{"IPv4", "IPv6"})},
+		"PrefixFilterEntryConfig.action":        {Type: "string", Enum: enumOf([]string{"permit", "deny"})},
+		"MVEConfig.vendor_config": {
+			Description: "Vendor-specific MVE configuration, as for mve buy --vendor-config",
+			Type:        "object",
+			Properties: map[string]*jsonSchema{
+				"vendor":      orReference(&jsonSchema{Type: "string", Enum: enumOf(validation.ValidMVEVendors)}),
+				"productSize": orReference(anyCase(append(slices.Clone(validation.ValidMVEProductSizes), infra.SortedKeys(validation.MVELabelToProductSize)...))),
+			},
+			Required: []string{"vendor"},
+		},
+		"VXCEndpointConfig.partner_config": {
+			Description: "Partner configuration, as for the partnerConfig field of vxc buy --json",
+			Type:        "object",
+			Properties: map[string]*jsonSchema{
+				"connectType": orReference(anyCase(validation.ValidPartnerConnectTypes)),
+			},
+			Required: []string{"connectType"},
+		},
+	}
+	for _, t := range []string{"PortConfig", "MCRConfig", "MVEConfig", "NATGatewayConfig", "VXCConfig"} {
+		fields[t+".term"] = term
+	}
+	return fields
+}
+
+// schemaGenerator builds the schema of a config type by reflection, adding a
+// definition for each struct type it meets.
+type schemaGenerator struct {
+	defs   map[string]*jsonSchema
+	fields map[string]*jsonSchema
+}
+
+// object describes struct type t, with a property for each field. A field
+// without omitempty is required. Any value other than a plain string may also
+// be written as a single variable or environment reference.
+func (g *schemaGenerator) object(t reflect.Type) *jsonSchema {
+	s := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}, AdditionalProperties: false}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !f.IsExported() {
+			continue
+		}
+		prop, ok := g.fields[t.Name()+"."+name]
+		if !ok {
+			prop = g.schema(f.Type)
+		}
+		if prop.Type != "string" || prop.Enum != nil || prop.Pattern != "" {
+			prop = orReference(prop)
+		}
+		s.Properties[name] = prop
+		if !slices.Contains(strings.Split(opts, ","), "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// schema describes a field of type t. Config structs are described once, as
+// definitions.
+func (g *schemaGenerator) schema(t reflect.Type) *jsonSchema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Slice:
+		return &jsonSchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.Interface {
+			return &jsonSchema{Type: "object"}
+		}
+		return &jsonSchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		name := strings.TrimSuffix(t.Name(), "Config")
+		if _, ok := g.defs[name]; !ok {
+			g.defs[name] = &jsonSchema{} // reserved while its fields are described
+			g.defs[name] = g.object(t)
+		}
+		return &jsonSchema{Ref: "#/$defs/" + name}
+	}
+	return &jsonSchema{}
+}
+
+// orReference allows s's value to be written as a single reference instead.
+func orReference(s *jsonSchema) *jsonSchema {
+	value := *s
+	value.Description = ""
+	return &jsonSchema{Description: s.Description, AnyOf: []*jsonSchema{&value, {Ref: "#/$defs/reference"}}}
+}
+
+// anyCase describes a string that is one of values in any letter case.
+func anyCase(values []string) *jsonSchema {
+	alts := make([]string, len(values))
+	for i, v := range values {
+		var b strings.Builder
+		for _, r := range v {
+			upper, lower := strings.ToUpper(string(r)), strings.ToLower(string(r))
+			if upper == lower {
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			} else {
+				b.WriteString("[" + upper + lower + "]")
+			}
+		}
+		alts[i] = b.String()
+	}
+	return &jsonSchema{
+		Description: "One of " + strings.Join(values, ", ") + ", in any letter case",
+		Type:        "string",
+		Pattern:     "^(" + strings.Join(alts, "|") + ")$",
+		allowed:     strings.Join(values, ", "),
+	}
+}
+
+// enumOf converts allowed values to a schema enum.
+func enumOf[T any](values []T) []interface{} {
+	enum := make([]interface{}, len(values))
+	for i, v := range values {
+		enum[i] = v
+	}
+	return enum
+}
+
+// validate checks v, a config value decoded from YAML or JSON, against s and
+// returns a problem for each mismatch, located by its path in the config. root
+// holds the definitions references point to. A null value counts as absent,
+// as it does when a config is decoded for apply.
+func (s *jsonSchema) validate(root *jsonSchema, path string, v interface{}) []LintProblem {
+	if s.Ref != "" {
+		return root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")].validate(root, path, v)
+	}
+	if len(s.AnyOf) > 0 {
+		// Report against the first alternative: the others are references,
+		// or the shorthand forms of the same value.
+		var first []LintProblem
+		for i, alt := range s.AnyOf {
+			problems := alt.validate(root, path, v)
+			if len(problems) == 0 {
+				return nil
+			}
+			if i == 0 {
+				first = problems
+			}
+		}
+		return first
+	}
+	if v == nil {
+		return nil
+	}
+	if s.Type != "" && !hasSchemaType(v, s.Type) {
+		return []LintProblem{{Path: path, Message: fmt.Sprintf("expected %s, got %s", articled(s.Type), articled(schemaTypeOf(v)))}}
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e interface{}) bool { return sameValue(e, v) }) {
+		return []LintProblem{{Path: path, Message: fmt.Sprintf("%v is not allowed; must be one of: %s", v, joinValues(s.Enum))}}
+	}
+	if str, ok := v.(string); ok && s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(str) {
+		return []LintProblem{{Path: path, Message: fmt.Sprintf("%q is not allowed; must be one of: %s (in any letter case)", str, s.allowed)}}
+	}
+
+	var problems []LintProblem
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if val[name] == nil {
+				problems = append(problems, LintProblem{Path: path, Message: fmt.Sprintf("missing required field %q", name)})
+			}
+		}
+		for _, key := range infra.SortedKeys(val) {
+			if prop, ok := s.Properties[key]; ok {
+				problems = append(problems, prop.validate(root, childPath(path, key), val[key])...)
+			} else if extra, ok := s.AdditionalProperties.(*jsonSchema); ok {
+				problems = append(problems, extra.validate(root, childPath(path, key), val[key])...)
+			} else if s.AdditionalProperties == false {
+				problems = append(problems, LintProblem{Path: childPath(path, key), Message: "unknown field"})
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range val {
+				problems = append(problems, s.Items.validate(root, fmt.Sprintf("%s[%d]", path, i), item)...)
+			}
+		}
+	}
+	return problems
+}
+
+// childPath returns the path of key within the value at path.
+func childPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// hasSchemaType reports whether v is a value of the JSON Schema type t.
+func hasSchemaType(v interface{}, t string) bool {
+	actual := schemaTypeOf(v)
+	return actual == t || (t == "number" && actual == "integer")
+}
+
+// schemaTypeOf returns the JSON Schema type of a decoded value.
+func schemaTypeOf(v interface{}) string {
+	switch n := v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		if n == math.Trunc(n) && !math.IsInf(n, 0) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// articled prefixes a type name with its indefinite article.
+func articled(t string) string {
+	if strings.ContainsRune("aeiou", rune(t[0])) {
+		return "an " + t
+	}
+	return "a " + t
+}
+
+// sameValue reports whether a decoded value equals an enum value, comparing
+// numbers by value.
+func sameValue(e, v interface{}) bool {
+	ef, eNum := toFloat(e)
+	vf, vNum := toFloat(v)
+	if eNum || vNum {
+		return eNum && vNum && ef == vf
+	}
+	return e == v
+}
+
+// toFloat converts a numeric value to float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// joinValues formats enum values as a list.
+func joinValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
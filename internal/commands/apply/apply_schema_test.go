@@ -0,0 +1,92 @@
+package apply
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// validateYAML checks a YAML config against the config schema.
+func validateYAML(t *testing.T, config string) []LintProblem {
+	t.Helper()
+	var tree interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(config), &tree))
+	schema := configSchema()
+	return schema.validate(schema, "", tree)
+}
+
+func TestConfigSchema_Enums(t *testing.T) {
+	data, err := json.Marshal(configSchema())
+	require.NoError(t, err)
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	defs := schema["$defs"].(map[string]interface{})
+	port := defs["Port"].(map[string]interface{})
+	assert.ElementsMatch(t, []interface{}{"name", "location_id", "speed", "term"}, port["required"])
+	assert.Equal(t, false, port["additionalProperties"])
+	term := port["properties"].(map[string]interface{})["term"].(map[string]interface{})
+	terms := term["anyOf"].([]interface{})[0].(map[string]interface{})["enum"].([]interface{})
+	require.Len(t, terms, len(validation.ValidContractTerms))
+	for i, v := range validation.ValidContractTerms {
+		assert.EqualValues(t, v, terms[i])
+	}
+	assert.Contains(t, defs, "VXCEndpoint")
+	assert.Contains(t, defs, "PrefixFilterEntry")
+}
+
+func TestConfigSchema_ValidConfig(t *testing.T) {
+	problems := validateYAML(t, `
+include: shared.yaml
+variables:
+  speed: 1000
+ports:
+  - {name: P, location_id: 1, speed: "{{.var.speed}}", term: 12, locked: true}
+mves:
+  - name: M
+    location_id: 1
+    term: "${env:TERM_MONTHS}"
+    vendor_config: {vendor: cisco, productSize: "mve 4/16", imageId: 42}
+mcrs:
+  - name: R
+    location_id: 1
+    speed: 2500
+    term: 1
+    prefix_filter_lists:
+      - description: in
+        address_family: IPv4
+        entries: [{action: permit, prefix: 10.0.0.0/8, le: 24}]
+vxcs:
+  - name: V
+    rate_limit: 100
+    term: 12
+    a_end: {product_uid: "{{.port.P}}", vlan: 100}
+    b_end: {partner_config: {connectType: Azure, serviceKey: abc}}
+`)
+	assert.Empty(t, problems)
+}
+
+func TestConfigSchema_Problems(t *testing.T) {
+	problems := validateYAML(t, `
+ports:
+  - {name: P, location_id: one, speed: 1000, term: 13, locationId: 2}
+  - {location_id: 1, speed: 1000, term: 12}
+vxcs:
+  - name: V
+    rate_limit: 100
+    term: 12
+    a_end: {product_uid: x}
+    b_end: {partner_config: {connectType: azur}}
+`)
+	assert.Equal(t, []LintProblem{
+		{Path: "ports[0].locationId", Message: "unknown field"},
+		{Path: "ports[0].location_id", Message: "expected an integer, got a string"},
+		{Path: "ports[0].term", Message: "13 is not allowed; must be one of: 1, 12, 24, 36, 48, 60"},
+		{Path: "ports[1]", Message: `missing required field "name"`},
+		{Path: "vxcs[0].b_end.partner_config.connectType", Message: `"azur" is not allowed; must be one of: AWS, AWSHC, AZURE, GOOGLE, ORACLE, IBM, TRANSIT, VROUTER (in any letter case)`},
+	}, problems)
+}
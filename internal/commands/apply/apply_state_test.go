@@ -0,0 +1,348 @@
+package apply
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/infra"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeStateFile writes a state file recording the given entries next to configPath.
+func writeStateFile(t *testing.T, configPath string, entries ...infra.StateResource) string {
+	t.Helper()
+	path := infra.DefaultStatePath(configPath)
+	require.NoError(t, infra.SaveState(path, &infra.State{Version: infra.StateVersion, Resources: entries}))
+	return path
+}
+
+func TestApplyConfig_WritesState(t *testing.T) {
+	mockPort := &infra.MockPortService{
+		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-uid-abc"}},
+	}
+	mockVXC := &infra.MockVXCService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+
+	cfg := `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+vxcs:
+  - name: Port-to-Port
+    rate_limit: 100
+    term: 12
+    a_end:
+      product_uid: "{{.port.Sydney-Port}}"
+    b_end:
+      product_uid: "b-end-uid"
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	cmd := applyCmd(f, false, true)
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+
+	state, err := infra.LoadState(infra.DefaultStatePath(f))
+	require.NoError(t, err)
+	uid, ok := state.Lookup("port", "Sydney-Port")
+	assert.True(t, ok)
+	assert.Equal(t, "port-uid-abc", uid)
+	uid, ok = state.Lookup("vxc", "Port-to-Port")
+	assert.True(t, ok)
+	assert.Equal(t, "vxc-uid-mock-1", uid)
+}
+
+func TestApplyConfig_StateFlagOverridesPath(t *testing.T) {
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	cfg := `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	statePath := filepath.Join(t.TempDir(), "custom.json")
+	cmd := applyCmd(f, false, true)
+	require.NoError(t, cmd.Flags().Set("state", statePath))
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+
+	state, err := infra.LoadState(statePath)
+	require.NoError(t, err)
+	_, ok := state.Lookup("port", "Sydney-Port")
+	assert.True(t, ok)
+	_, err = os.Stat(infra.DefaultStatePath(f))
+	assert.True(t, os.IsNotExist(err), "default state path must not be written when --state is set")
+}
+
+// TestApplyConfig_SkipsResourcesInState verifies a re-run does not order a
+// resource recorded in the state file, and that templates referencing it
+// resolve to the recorded UID.
+func TestApplyConfig_SkipsResourcesInState(t *testing.T) {
+	mockPort := &infra.MockPortService{GetPortResult: &megaport.Port{
+		Name: "Sydney-Port", LocationID: 1, PortSpeed: 1000, ContractTermMonths: 12,
+	}}
+	mockVXC := &infra.MockVXCService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+
+	cfg := `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+vxcs:
+  - name: Port-to-Port
+    rate_limit: 100
+    term: 12
+    a_end:
+      product_uid: "{{.port.Sydney-Port}}"
+    b_end:
+      product_uid: "b-end-uid"
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	writeStateFile(t, f, infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "existing-port-uid"})
+	cmd := applyCmd(f, false, true)
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+
+	assert.Nil(t, mockPort.CapturedPortRequest, "port in state must not be ordered again")
+	require.NotNil(t, mockVXC.CapturedVXCRequest)
+	assert.Equal(t, "existing-port-uid", mockVXC.CapturedVXCRequest.AEndConfiguration.ProductUID)
+	assert.Contains(t, out, infra.StatusUnchanged)
+}
+
+func TestApplyConfig_AllResourcesInStateOrdersNothing(t *testing.T) {
+	mockPort := &infra.MockPortService{GetPortResult: &megaport.Port{
+		Name: "Sydney-Port", LocationID: 1, PortSpeed: 1000, ContractTermMonths: 12,
+	}}
+	mockMCR := &infra.MockMCRService{GetMCRResult: &megaport.MCR{
+		Name: "Sydney-MCR", LocationID: 1, PortSpeed: 1000, ContractTermMonths: 12,
+	}}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	cfg := `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+mcrs:
+  - name: Sydney-MCR
+    location_id: 1
+    speed: 1000
+    term: 12
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	writeStateFile(t, f,
+		infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "existing-port-uid"},
+		infra.StateResource{Type: "mcr", Name: "Sydney-MCR", UID: "existing-mcr-uid"},
+	)
+	// Without --yes: nothing to provision, so no confirmation prompt is shown.
+	cmd := applyCmd(f, false, false)
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Nil(t, mockPort.CapturedPortRequest)
+	assert.Nil(t, mockMCR.CapturedMCRRequest)
+	assert.Contains(t, out, "nothing to provision")
+}
+
+func TestApplyConfig_ReprovisionsInactiveStateEntry(t *testing.T) {
+	calls := 0
+	mockPort := &infra.MockPortService{
+		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"new-port-uid"}},
+		// The state lookup sees the old port as decommissioned; the new one is live.
+		GetPortStatusFunc: func() string {
+			calls++
+			if calls == 1 {
+				return megaport.STATUS_DECOMMISSIONED
+			}
+			return megaport.SERVICE_LIVE
+		},
+	}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	cfg := `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	statePath := writeStateFile(t, f, infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "old-port-uid"})
+	cmd := applyCmd(f, false, true)
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+	require.NotNil(t, mockPort.CapturedPortRequest)
+
+	state, err := infra.LoadState(statePath)
+	require.NoError(t, err)
+	uid, _ := state.Lookup("port", "Sydney-Port")
+	assert.Equal(t, "new-port-uid", uid)
+}
+
+func TestApplyConfig_ReprovisionsDeletedStateEntry(t *testing.T) {
+	notFound := &megaport.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}, Request: &http.Request{}},
+		Message:  "not found",
+	}
+	calls := 0
+	mockMCR := &infra.MockMCRService{
+		// The state lookup gets a 404 for the old MCR; the new one is found.
+		GetMCRErrFunc: func() error {
+			calls++
+			if calls == 1 {
+				return notFound
+			}
+			return nil
+		},
+	}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	cfg := `
+mcrs:
+  - name: Sydney-MCR
+    location_id: 1
+    speed: 1000
+    term: 12
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	statePath := writeStateFile(t, f, infra.StateResource{Type: "mcr", Name: "Sydney-MCR", UID: "gone-mcr-uid"})
+	cmd := applyCmd(f, false, true)
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+	require.NotNil(t, mockMCR.CapturedMCRRequest)
+
+	state, err := infra.LoadState(statePath)
+	require.NoError(t, err)
+	uid, ok := state.Lookup("mcr", "Sydney-MCR")
+	assert.True(t, ok)
+	assert.Equal(t, "mcr-uid-mock", uid)
+}
+
+func TestApplyConfig_StateLookupErrorAborts(t *testing.T) {
+	mockPort := &infra.MockPortService{GetPortErr: fmt.Errorf("API unavailable")}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	cfg := `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	writeStateFile(t, f, infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "existing-port-uid"})
+	cmd := applyCmd(f, false, true)
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "API unavailable")
+	assert.Nil(t, mockPort.CapturedPortRequest, "an unknown state must never fall back to ordering")
+}
+
+func TestApplyConfig_RollbackRemovesFromState(t *testing.T) {
+	mockPort := &infra.MockPortService{
+		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-rollback-uid"}},
+	}
+	mockMCR := &infra.MockMCRService{BuyMCRErr: fmt.Errorf("MCR API down")}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	cfg := `
+ports:
+  - name: Rollback-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+mcrs:
+  - name: Failing-MCR
+    location_id: 1
+    speed: 1000
+    term: 12
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	cmd := applyCmdWithRollback(f)
+
+	output.CaptureOutput(func() {
+		_ = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.Equal(t, []string{"port-rollback-uid"}, mockPort.DeletePortCalledWith)
+
+	state, err := infra.LoadState(infra.DefaultStatePath(f))
+	require.NoError(t, err)
+	_, ok := state.Lookup("port", "Rollback-Port")
+	assert.False(t, ok, "rolled-back resources must be removed from state")
+}
+
+func TestApplyConfig_FailureWithoutRollbackKeepsState(t *testing.T) {
+	mockPort := &infra.MockPortService{
+		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-orphan-uid"}},
+	}
+	mockMCR := &infra.MockMCRService{BuyMCRErr: fmt.Errorf("MCR API down")}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	cfg := `
+ports:
+  - name: Orphan-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+mcrs:
+  - name: Failing-MCR
+    location_id: 1
+    speed: 1000
+    term: 12
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	cmd := applyCmd(f, false, true)
+
+	_ = captureStderr(t, func() {
+		output.CaptureOutput(func() {
+			_ = ApplyConfig(cmd, nil, true, "table")
+		})
+	})
+
+	// The port is still billing, so a re-run must pick it up rather than re-order.
+	state, err := infra.LoadState(infra.DefaultStatePath(f))
+	require.NoError(t, err)
+	uid, ok := state.Lookup("port", "Orphan-Port")
+	assert.True(t, ok)
+	assert.Equal(t, "port-orphan-uid", uid)
+}
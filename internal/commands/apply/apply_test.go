@@ -13,7 +13,9 @@ import (
 
 	"github.com/megaport/megaport-cli/internal/base/output"
 	"github.com/megaport/megaport-cli/internal/commands/config"
+	"github.com/megaport/megaport-cli/internal/infra"
 	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/megaport/megaport-cli/internal/validation"
 	megaport "github.com/megaport/megaportgo"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -45,6 +47,12 @@ func applyCmd(file string, dryRun, yes bool) *cobra.Command {
 	cmd.Flags().Bool("dry-run", false, "")
 	cmd.Flags().BoolP("yes", "y", false, "")
 	cmd.Flags().Bool("rollback-on-failure", false, "")
+	cmd.Flags().String("state", "", "")
+	cmd.Flags().Bool("allow-replace", false, "")
+	cmd.Flags().Int("parallelism", 1, "")
+	cmd.Flags().String("resume", "", "")
+	cmd.Flags().String("outputs-file", "", "")
+	cmd.Flags().String("policy", "", "")
 	_ = cmd.Flags().Set("file", file)
 	if dryRun {
 		_ = cmd.Flags().Set("dry-run", "true")
@@ -62,22 +70,8 @@ func applyCmdWithRollback(file string) *cobra.Command {
 	return cmd
 }
 
-// setupMockClient overrides the login function with mock services and returns cleanup.
-func setupMockClient(port *MockPortService, mcr *MockMCRService, mve *MockMVEService, vxc *MockVXCService) func() {
-	original := config.GetLoginFunc()
-	config.SetLoginFunc(func(ctx context.Context) (*megaport.Client, error) {
-		client := &megaport.Client{}
-		client.PortService = port
-		client.MCRService = mcr
-		client.MVEService = mve
-		client.VXCService = vxc
-		return client, nil
-	})
-	return func() { config.SetLoginFunc(original) }
-}
-
 func TestApplyConfig_EmptyConfig(t *testing.T) {
-	defer setupMockClient(&MockPortService{}, &MockMCRService{}, &MockMVEService{}, &MockVXCService{})()
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	f := writeTempFile(t, "empty.yaml", "")
 	cmd := applyCmd(f, false, true)
@@ -89,8 +83,8 @@ func TestApplyConfig_EmptyConfig(t *testing.T) {
 }
 
 func TestApplyConfig_ProvisionPort(t *testing.T) {
-	mockPort := &MockPortService{}
-	defer setupMockClient(mockPort, &MockMCRService{}, &MockMVEService{}, &MockVXCService{})()
+	mockPort := &infra.MockPortService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 ports:
@@ -119,8 +113,8 @@ ports:
 }
 
 func TestApplyConfig_ProvisionMCR(t *testing.T) {
-	mockMCR := &MockMCRService{}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 mcrs:
@@ -145,14 +139,14 @@ mcrs:
 }
 
 func TestApplyConfig_ProvisionVXCWithTemplateRef(t *testing.T) {
-	mockPort := &MockPortService{
+	mockPort := &infra.MockPortService{
 		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-uid-abc"}},
 	}
-	mockMCR := &MockMCRService{
+	mockMCR := &infra.MockMCRService{
 		BuyMCRResult: &megaport.BuyMCRResponse{TechnicalServiceUID: "mcr-uid-def"},
 	}
-	mockVXC := &MockVXCService{}
-	defer setupMockClient(mockPort, mockMCR, &MockMVEService{}, mockVXC)()
+	mockVXC := &infra.MockVXCService{}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, mockVXC)()
 
 	yaml := `
 ports:
@@ -202,8 +196,8 @@ func TestApplyConfig_PortAPIError(t *testing.T) {
 		},
 		Message: "forbidden",
 	}
-	mockPort := &MockPortService{BuyPortErr: apiErr}
-	defer setupMockClient(mockPort, &MockMCRService{}, &MockMVEService{}, &MockVXCService{})()
+	mockPort := &infra.MockPortService{BuyPortErr: apiErr}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 ports:
@@ -226,7 +220,7 @@ ports:
 }
 
 func TestApplyConfig_UnresolvedTemplate(t *testing.T) {
-	defer setupMockClient(&MockPortService{}, &MockMCRService{}, &MockMVEService{}, &MockVXCService{})()
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 vxcs:
@@ -251,8 +245,8 @@ vxcs:
 }
 
 func TestApplyConfig_DryRunPorts(t *testing.T) {
-	mockPort := &MockPortService{}
-	defer setupMockClient(mockPort, &MockMCRService{}, &MockMVEService{}, &MockVXCService{})()
+	mockPort := &infra.MockPortService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 ports:
@@ -279,8 +273,8 @@ ports:
 func TestApplyConfig_DryRunValidationError(t *testing.T) {
 	output.SetTerminalWidthForTesting(200)
 	defer output.SetTerminalWidthForTesting(0)
-	mockPort := &MockPortService{ValidatePortOrderErr: fmt.Errorf("invalid location")}
-	defer setupMockClient(mockPort, &MockMCRService{}, &MockMVEService{}, &MockVXCService{})()
+	mockPort := &infra.MockPortService{ValidatePortOrderErr: fmt.Errorf("invalid location")}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 ports:
@@ -304,7 +298,7 @@ ports:
 }
 
 func TestApplyConfig_MissingFile(t *testing.T) {
-	defer setupMockClient(&MockPortService{}, &MockMCRService{}, &MockMVEService{}, &MockVXCService{})()
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	cmd := applyCmd("/nonexistent/path.yaml", false, true)
 	var err error
@@ -333,8 +327,8 @@ func TestApplyConfig_LoginError(t *testing.T) {
 }
 
 func TestApplyConfig_JSONFormat(t *testing.T) {
-	mockPort := &MockPortService{}
-	defer setupMockClient(mockPort, &MockMCRService{}, &MockMVEService{}, &MockVXCService{})()
+	mockPort := &infra.MockPortService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	content := `{"ports":[{"name":"JSON-Port","location_id":1,"speed":1000,"term":12}]}`
 	f := writeTempFile(t, "config.json", content)
@@ -352,9 +346,9 @@ func TestApplyConfig_JSONFormat(t *testing.T) {
 
 func TestApplyConfig_MVEWithYAMLIntegerVendorConfig(t *testing.T) {
 	// YAML decodes integer scalars as int (not float64 like JSON).
-	// normalizeVendorConfigMap must convert them so ParseVendorConfig works.
-	mockMVE := &MockMVEService{}
-	defer setupMockClient(&MockPortService{}, &MockMCRService{}, mockMVE, &MockVXCService{})()
+	// NormalizeVendorConfigMap must convert them so ParseVendorConfig works.
+	mockMVE := &infra.MockMVEService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, mockMVE, &infra.MockVXCService{})()
 
 	yaml := `
 mves:
@@ -379,7 +373,7 @@ mves:
 }
 
 func TestApplyConfig_DryRunUnknownTemplateRef(t *testing.T) {
-	defer setupMockClient(&MockPortService{}, &MockMCRService{}, &MockMVEService{}, &MockVXCService{})()
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	// VXC references a port that is not declared in the config — dry-run should catch it.
 	yaml := `
@@ -414,8 +408,8 @@ func TestApplyConfig_MVEAPIError(t *testing.T) {
 		},
 		Message: "unauthorized",
 	}
-	mockMVE := &MockMVEService{BuyMVEErr: apiErr}
-	defer setupMockClient(&MockPortService{}, &MockMCRService{}, mockMVE, &MockVXCService{})()
+	mockMVE := &infra.MockMVEService{BuyMVEErr: apiErr}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, mockMVE, &infra.MockVXCService{})()
 
 	yaml := `
 mves:
@@ -448,8 +442,8 @@ func TestApplyConfig_MCRAPIError(t *testing.T) {
 		},
 		Message: "rate limited",
 	}
-	mockMCR := &MockMCRService{BuyMCRErr: apiErr}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{BuyMCRErr: apiErr}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 mcrs:
@@ -470,7 +464,7 @@ mcrs:
 }
 
 func TestApplyConfig_VXCAPIError(t *testing.T) {
-	mockPort := &MockPortService{
+	mockPort := &infra.MockPortService{
 		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-uid-abc"}},
 	}
 	vxcAPIErr := &megaport.ErrorResponse{
@@ -481,8 +475,8 @@ func TestApplyConfig_VXCAPIError(t *testing.T) {
 		},
 		Message: "unauthorized",
 	}
-	mockVXC := &MockVXCService{BuyVXCErr: vxcAPIErr}
-	defer setupMockClient(mockPort, &MockMCRService{}, &MockMVEService{}, mockVXC)()
+	mockVXC := &infra.MockVXCService{BuyVXCErr: vxcAPIErr}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
 
 	yaml := `
 ports:
@@ -513,7 +507,7 @@ vxcs:
 }
 
 func TestApplyConfig_NoFileFlag(t *testing.T) {
-	defer setupMockClient(&MockPortService{}, &MockMCRService{}, &MockMVEService{}, &MockVXCService{})()
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	cmd := &cobra.Command{Use: "apply"}
 	cmd.Flags().StringP("file", "f", "", "")
@@ -531,9 +525,9 @@ func TestApplyConfig_NoFileFlag(t *testing.T) {
 }
 
 func TestApplyConfig_DryRunMCRAndVXC(t *testing.T) {
-	mockMCR := &MockMCRService{}
-	mockVXC := &MockVXCService{}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, mockVXC)()
+	mockMCR := &infra.MockMCRService{}
+	mockVXC := &infra.MockVXCService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, mockVXC)()
 
 	yaml := `
 mcrs:
@@ -568,7 +562,7 @@ vxcs:
 }
 
 func TestApplyConfig_DryRunMVEInvalidVendorConfig(t *testing.T) {
-	defer setupMockClient(&MockPortService{}, &MockMCRService{}, &MockMVEService{}, &MockVXCService{})()
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 mves:
@@ -598,11 +592,11 @@ func TestApplyConfig_OrphanReporting(t *testing.T) {
 	output.SetTerminalWidthForTesting(200)
 	defer output.SetTerminalWidthForTesting(0)
 
-	mockPort := &MockPortService{
+	mockPort := &infra.MockPortService{
 		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-orphan-uid"}},
 	}
-	mockMCR := &MockMCRService{BuyMCRErr: fmt.Errorf("MCR API down")}
-	defer setupMockClient(mockPort, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{BuyMCRErr: fmt.Errorf("MCR API down")}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	cfg := `
 ports:
@@ -637,11 +631,11 @@ mcrs:
 // TestApplyConfig_RollbackOnFailure verifies that with --rollback-on-failure, the
 // port created before the MCR failure is deleted via DeletePort.
 func TestApplyConfig_RollbackOnFailure(t *testing.T) {
-	mockPort := &MockPortService{
+	mockPort := &infra.MockPortService{
 		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-rollback-uid"}},
 	}
-	mockMCR := &MockMCRService{BuyMCRErr: fmt.Errorf("MCR API down")}
-	defer setupMockClient(mockPort, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{BuyMCRErr: fmt.Errorf("MCR API down")}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	cfg := `
 ports:
@@ -678,11 +672,11 @@ mcrs:
 // would see ctx.Err() and the port would leak; rollback must start a fresh context
 // (with the same configured timeout) so the delete still fires.
 func TestApplyConfig_RollbackSurvivesProvisionTimeout(t *testing.T) {
-	mockPort := &MockPortService{
+	mockPort := &infra.MockPortService{
 		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-rollback-uid"}},
 		GetPortStatus: "CONFIGURING", // never reaches CONFIGURED/LIVE, so the wait times out
 	}
-	defer setupMockClient(mockPort, &MockMCRService{}, &MockMVEService{}, &MockVXCService{})()
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	cfg := `
 ports:
@@ -732,15 +726,15 @@ func TestApplyConfig_PerResourceTimeout(t *testing.T) {
 	}
 
 	const perResourceConsume = 600 * time.Millisecond
-	mockPort := &MockPortService{
+	mockPort := &infra.MockPortService{
 		BuyPortResult:     &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"slow-port-uid"}},
 		GetPortStatusFunc: readyAfter(perResourceConsume),
 	}
-	mockMCR := &MockMCRService{
+	mockMCR := &infra.MockMCRService{
 		BuyMCRResult:     &megaport.BuyMCRResponse{TechnicalServiceUID: "slow-mcr-uid"},
 		GetMCRStatusFunc: readyAfter(perResourceConsume),
 	}
-	defer setupMockClient(mockPort, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	cfg := `
 ports:
@@ -778,12 +772,12 @@ func TestApplyConfig_RollbackOnFailure_DeleteError(t *testing.T) {
 	output.SetTerminalWidthForTesting(200)
 	defer output.SetTerminalWidthForTesting(0)
 
-	mockPort := &MockPortService{
+	mockPort := &infra.MockPortService{
 		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-stuck-uid"}},
 		DeletePortErr: fmt.Errorf("delete also failed"),
 	}
-	mockMCR := &MockMCRService{BuyMCRErr: fmt.Errorf("MCR API down")}
-	defer setupMockClient(mockPort, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{BuyMCRErr: fmt.Errorf("MCR API down")}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	cfg := `
 ports:
@@ -820,14 +814,14 @@ func TestApplyConfig_VXCFailOrphanReporting(t *testing.T) {
 	output.SetTerminalWidthForTesting(200)
 	defer output.SetTerminalWidthForTesting(0)
 
-	mockPort := &MockPortService{
+	mockPort := &infra.MockPortService{
 		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-vxcfail-uid"}},
 	}
-	mockMCR := &MockMCRService{
+	mockMCR := &infra.MockMCRService{
 		BuyMCRResult: &megaport.BuyMCRResponse{TechnicalServiceUID: "mcr-vxcfail-uid"},
 	}
-	mockVXC := &MockVXCService{BuyVXCErr: fmt.Errorf("VXC quota exceeded")}
-	defer setupMockClient(mockPort, mockMCR, &MockMVEService{}, mockVXC)()
+	mockVXC := &infra.MockVXCService{BuyVXCErr: fmt.Errorf("VXC quota exceeded")}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, mockVXC)()
 
 	cfg := `
 ports:
@@ -872,11 +866,11 @@ vxcs:
 // TestApplyConfig_RollbackOnFailure_MCR verifies that with --rollback-on-failure,
 // an MCR created before a VXC failure is deleted via DeleteMCR.
 func TestApplyConfig_RollbackOnFailure_MCR(t *testing.T) {
-	mockMCR := &MockMCRService{
+	mockMCR := &infra.MockMCRService{
 		BuyMCRResult: &megaport.BuyMCRResponse{TechnicalServiceUID: "mcr-rollback-uid"},
 	}
-	mockVXC := &MockVXCService{BuyVXCErr: fmt.Errorf("VXC quota exceeded")}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, mockVXC)()
+	mockVXC := &infra.MockVXCService{BuyVXCErr: fmt.Errorf("VXC quota exceeded")}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, mockVXC)()
 
 	cfg := `
 mcrs:
@@ -910,11 +904,11 @@ vxcs:
 // TestApplyConfig_RollbackOnFailure_MVE verifies that with --rollback-on-failure,
 // an MVE created before a VXC failure is deleted via DeleteMVE.
 func TestApplyConfig_RollbackOnFailure_MVE(t *testing.T) {
-	mockMVE := &MockMVEService{
+	mockMVE := &infra.MockMVEService{
 		BuyMVEResult: &megaport.BuyMVEResponse{TechnicalServiceUID: "mve-rollback-uid"},
 	}
-	mockVXC := &MockVXCService{BuyVXCErr: fmt.Errorf("VXC quota exceeded")}
-	defer setupMockClient(&MockPortService{}, &MockMCRService{}, mockMVE, mockVXC)()
+	mockVXC := &infra.MockVXCService{BuyVXCErr: fmt.Errorf("VXC quota exceeded")}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, mockMVE, mockVXC)()
 
 	cfg := `
 mves:
@@ -952,15 +946,15 @@ vxcs:
 // TestApplyConfig_RollbackOnFailure_VXC verifies that with --rollback-on-failure,
 // a successfully created VXC is deleted when a subsequent VXC fails.
 func TestApplyConfig_RollbackOnFailure_VXC(t *testing.T) {
-	mockMCR := &MockMCRService{
+	mockMCR := &infra.MockMCRService{
 		BuyMCRResult: &megaport.BuyMCRResponse{TechnicalServiceUID: "mcr-vxcroll-uid"},
 	}
 	// Second BuyVXC call fails; first succeeds with default UID "vxc-uid-mock-1".
-	mockVXC := &MockVXCService{
+	mockVXC := &infra.MockVXCService{
 		BuyVXCErr:       fmt.Errorf("second VXC failed"),
 		BuyVXCErrOnCall: 2,
 	}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, mockVXC)()
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, mockVXC)()
 
 	cfg := `
 mcrs:
@@ -1005,11 +999,11 @@ vxcs:
 // orphan window the no-wait-then-poll restructure closes: the order has placed billing
 // before provisioning completes, so the UID must already be recorded when the wait fails.
 func TestApplyConfig_RollbackOnFailure_ProvisionTimeout(t *testing.T) {
-	mockMCR := &MockMCRService{
+	mockMCR := &infra.MockMCRService{
 		BuyMCRResult: &megaport.BuyMCRResponse{TechnicalServiceUID: "mcr-provision-fail-uid"},
 		GetMCRErr:    fmt.Errorf("provisioning status check failed"),
 	}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	cfg := `
 mcrs:
@@ -1037,11 +1031,11 @@ mcrs:
 // --rollback-on-failure, both successful and failed rollbacks appear in the returned
 // error so JSON consumers get a complete picture of what was cleaned up.
 func TestApplyConfig_RollbackOnFailure_JSONMode(t *testing.T) {
-	mockPort := &MockPortService{
+	mockPort := &infra.MockPortService{
 		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-json-roll-uid"}},
 	}
-	mockMCR := &MockMCRService{BuyMCRErr: fmt.Errorf("MCR API down")}
-	defer setupMockClient(mockPort, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{BuyMCRErr: fmt.Errorf("MCR API down")}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	cfg := `
 ports:
@@ -1077,11 +1071,11 @@ mcrs:
 // orphan details are embedded in the returned error (not emitted as plain-text lines)
 // so the JSON error envelope the wrapper prints is the only structured output on stderr.
 func TestApplyConfig_OrphanReporting_JSONMode(t *testing.T) {
-	mockPort := &MockPortService{
+	mockPort := &infra.MockPortService{
 		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-json-uid"}},
 	}
-	mockMCR := &MockMCRService{BuyMCRErr: fmt.Errorf("MCR API down")}
-	defer setupMockClient(mockPort, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{BuyMCRErr: fmt.Errorf("MCR API down")}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	cfg := `
 ports:
@@ -1128,8 +1122,8 @@ func TestApplyModule_RegistersRollbackFlag(t *testing.T) {
 // --- nil API response tests ---
 
 func TestApplyConfig_PortNilResponse(t *testing.T) {
-	mockPort := &MockPortService{BuyPortNilResp: true}
-	defer setupMockClient(mockPort, &MockMCRService{}, &MockMVEService{}, &MockVXCService{})()
+	mockPort := &infra.MockPortService{BuyPortNilResp: true}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 ports:
@@ -1153,8 +1147,8 @@ ports:
 }
 
 func TestApplyConfig_MCRNilResponse(t *testing.T) {
-	mockMCR := &MockMCRService{BuyMCRNilResp: true}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{BuyMCRNilResp: true}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 mcrs:
@@ -1177,8 +1171,8 @@ mcrs:
 }
 
 func TestApplyConfig_MVENilResponse(t *testing.T) {
-	mockMVE := &MockMVEService{BuyMVENilResp: true}
-	defer setupMockClient(&MockPortService{}, &MockMCRService{}, mockMVE, &MockVXCService{})()
+	mockMVE := &infra.MockMVEService{BuyMVENilResp: true}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, mockMVE, &infra.MockVXCService{})()
 
 	yaml := `
 mves:
@@ -1205,11 +1199,11 @@ mves:
 }
 
 func TestApplyConfig_VXCNilResponse(t *testing.T) {
-	mockPort := &MockPortService{
+	mockPort := &infra.MockPortService{
 		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-uid-abc"}},
 	}
-	mockVXC := &MockVXCService{BuyVXCNilResp: true}
-	defer setupMockClient(mockPort, &MockMCRService{}, &MockMVEService{}, mockVXC)()
+	mockVXC := &infra.MockVXCService{BuyVXCNilResp: true}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
 
 	yaml := `
 ports:
@@ -1246,8 +1240,8 @@ vxcs:
 // where the SDK can return (nil, nil) and the status was read off the nil pointer.
 
 func TestApplyConfig_PortProvisionNilResponse(t *testing.T) {
-	mockPort := &MockPortService{GetPortReturnNil: true}
-	defer setupMockClient(mockPort, &MockMCRService{}, &MockMVEService{}, &MockVXCService{})()
+	mockPort := &infra.MockPortService{GetPortReturnNil: true}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 ports:
@@ -1271,8 +1265,8 @@ ports:
 }
 
 func TestApplyConfig_MCRProvisionNilResponse(t *testing.T) {
-	mockMCR := &MockMCRService{GetMCRReturnNil: true}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{GetMCRReturnNil: true}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 mcrs:
@@ -1295,8 +1289,8 @@ mcrs:
 }
 
 func TestApplyConfig_MVEProvisionNilResponse(t *testing.T) {
-	mockMVE := &MockMVEService{GetMVEReturnNil: true}
-	defer setupMockClient(&MockPortService{}, &MockMCRService{}, mockMVE, &MockVXCService{})()
+	mockMVE := &infra.MockMVEService{GetMVEReturnNil: true}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, mockMVE, &infra.MockVXCService{})()
 
 	yaml := `
 mves:
@@ -1323,8 +1317,8 @@ mves:
 }
 
 func TestApplyConfig_VXCProvisionNilResponse(t *testing.T) {
-	mockVXC := &MockVXCService{GetVXCReturnNil: true}
-	defer setupMockClient(&MockPortService{}, &MockMCRService{}, &MockMVEService{}, mockVXC)()
+	mockVXC := &infra.MockVXCService{GetVXCReturnNil: true}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
 
 	yaml := `
 ports:
@@ -1356,144 +1350,6 @@ vxcs:
 	assert.Contains(t, err.Error(), "empty response")
 }
 
-// --- helpers (also used by other test functions) ---
-
-func TestResolveTemplates_NoTemplate(t *testing.T) {
-	uids := map[string]map[string]string{"port": {"Sydney": "uid-abc"}}
-	result, err := resolveTemplates("some-fixed-uid", uids)
-	require.NoError(t, err)
-	assert.Equal(t, "some-fixed-uid", result)
-}
-
-func TestResolveTemplates_ResolvesReference(t *testing.T) {
-	uids := map[string]map[string]string{
-		"port": {"Sydney-Primary": "port-uid-123"},
-		"mcr":  {"Sydney-MCR": "mcr-uid-456"},
-	}
-
-	result, err := resolveTemplates("{{.port.Sydney-Primary}}", uids)
-	require.NoError(t, err)
-	assert.Equal(t, "port-uid-123", result)
-
-	result, err = resolveTemplates("{{.mcr.Sydney-MCR}}", uids)
-	require.NoError(t, err)
-	assert.Equal(t, "mcr-uid-456", result)
-}
-
-func TestResolveTemplates_UnknownReference(t *testing.T) {
-	uids := map[string]map[string]string{"port": {}}
-	_, err := resolveTemplates("{{.port.Missing}}", uids)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "Missing")
-}
-
-func TestResolveTemplates_UnknownType(t *testing.T) {
-	uids := map[string]map[string]string{}
-	_, err := resolveTemplates("{{.vxc.SomeVXC}}", uids)
-	assert.Error(t, err)
-}
-
-func TestNormalizeVendorConfigMap_IntToFloat64(t *testing.T) {
-	// YAML decodes integers as int; normalizeVendorConfigMap converts them to float64
-	// so that ParseVendorConfig (which uses float64 type assertions) works correctly.
-	input := map[string]interface{}{
-		"vendor":      "cisco",
-		"imageId":     42, // int from YAML
-		"productSize": "SMALL",
-	}
-	out, err := normalizeVendorConfigMap(input)
-	require.NoError(t, err)
-	// After round-trip through JSON, integers become float64
-	assert.IsType(t, float64(0), out["imageId"])
-	assert.Equal(t, float64(42), out["imageId"])
-	assert.Equal(t, "cisco", out["vendor"])
-}
-
-func TestNormalizeVendorConfigMap_Nil(t *testing.T) {
-	out, err := normalizeVendorConfigMap(nil)
-	require.NoError(t, err)
-	assert.Nil(t, out)
-}
-
-func TestParseConfigFile_YAML(t *testing.T) {
-	content := `
-ports:
-  - name: Test-Port
-    location_id: 1
-    speed: 1000
-    term: 12
-    marketplace_visibility: true
-
-mcrs:
-  - name: Test-MCR
-    location_id: 2
-    speed: 1000
-    term: 12
-    asn: 65000
-
-vxcs:
-  - name: Test-VXC
-    rate_limit: 100
-    term: 12
-    a_end:
-      product_uid: "{{.port.Test-Port}}"
-      vlan: 100
-    b_end:
-      product_uid: "{{.mcr.Test-MCR}}"
-`
-	f := writeTempFile(t, "config.yaml", content)
-	cfg, err := parseConfigFile(f)
-	require.NoError(t, err)
-
-	require.Len(t, cfg.Ports, 1)
-	assert.Equal(t, "Test-Port", cfg.Ports[0].Name)
-	assert.Equal(t, 1000, cfg.Ports[0].Speed)
-	assert.True(t, cfg.Ports[0].MarketplaceVisibility)
-
-	require.Len(t, cfg.MCRs, 1)
-	assert.Equal(t, "Test-MCR", cfg.MCRs[0].Name)
-	assert.Equal(t, 65000, cfg.MCRs[0].ASN)
-
-	require.Len(t, cfg.VXCs, 1)
-	assert.Equal(t, "{{.port.Test-Port}}", cfg.VXCs[0].AEnd.ProductUID)
-	assert.Equal(t, 100, cfg.VXCs[0].AEnd.VLAN)
-}
-
-func TestParseConfigFile_JSON(t *testing.T) {
-	content := `{
-  "ports": [
-    {"name": "JSON-Port", "location_id": 5, "speed": 10000, "term": 24}
-  ]
-}`
-	f := writeTempFile(t, "config.json", content)
-	cfg, err := parseConfigFile(f)
-	require.NoError(t, err)
-
-	require.Len(t, cfg.Ports, 1)
-	assert.Equal(t, "JSON-Port", cfg.Ports[0].Name)
-	assert.Equal(t, 10000, cfg.Ports[0].Speed)
-}
-
-func TestParseConfigFile_NotFound(t *testing.T) {
-	_, err := parseConfigFile("/nonexistent/path/config.yaml")
-	assert.Error(t, err)
-}
-
-func TestParseConfigFile_InvalidYAML(t *testing.T) {
-	f := writeTempFile(t, "bad.yaml", "ports: [invalid yaml }")
-	_, err := parseConfigFile(f)
-	assert.Error(t, err)
-}
-
-func TestParseConfigFile_EmptyFile(t *testing.T) {
-	f := writeTempFile(t, "empty.yaml", "")
-	cfg, err := parseConfigFile(f)
-	require.NoError(t, err)
-	assert.Empty(t, cfg.Ports)
-	assert.Empty(t, cfg.MCRs)
-	assert.Empty(t, cfg.VXCs)
-}
-
 // writeTempFile creates a temp file with the given name suffix and content,
 // returning its path.
 func writeTempFile(t *testing.T, name, content string) string {
@@ -1505,8 +1361,8 @@ func writeTempFile(t *testing.T, name, content string) string {
 }
 
 func TestApplyConfig_ProvisionMCRWithIPsecTunnelCount(t *testing.T) {
-	mockMCR := &MockMCRService{}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 mcrs:
@@ -1535,8 +1391,8 @@ mcrs:
 }
 
 func TestApplyConfig_DryRunMCRWithIPsecTunnelCount(t *testing.T) {
-	mockMCR := &MockMCRService{}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 mcrs:
@@ -1594,69 +1450,9 @@ func TestMCRAddOns(t *testing.T) {
 	}
 }
 
-// A mistyped key (camelCase tunnelCount) or a stray key must be a clear error
-// rather than silently dropped, which is the failure mode behind issue #439.
-func TestParseConfigFile_RejectsUnknownField(t *testing.T) {
-	tests := []struct {
-		name    string
-		file    string
-		content string
-	}{
-		{
-			name: "yaml camelCase tunnelCount",
-			file: "config.yaml",
-			content: `
-mcrs:
-  - name: MCR
-    location_id: 2
-    speed: 1000
-    term: 12
-    tunnelCount: 10
-`,
-		},
-		{
-			name:    "json camelCase tunnelCount",
-			file:    "config.json",
-			content: `{"mcrs":[{"name":"MCR","location_id":2,"speed":1000,"term":12,"tunnelCount":10}]}`,
-		},
-		{
-			name: "yaml unknown top-level key",
-			file: "config.yaml",
-			content: `
-version: "1.0"
-mcrs: []
-`,
-		},
-		{
-			name: "yaml unknown port field",
-			file: "config.yaml",
-			content: `
-ports:
-  - name: P
-    location_id: 1
-    speed: 1000
-    term: 12
-    locationId: 1
-`,
-		},
-		{
-			name:    "json unknown vxc field",
-			file:    "config.json",
-			content: `{"vxcs":[{"name":"V","rate_limit":100,"term":12,"rateLimit":100}]}`,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			f := writeTempFile(t, tt.file, tt.content)
-			_, err := parseConfigFile(f)
-			assert.Error(t, err, "unknown key should be a clear error, not silently dropped")
-		})
-	}
-}
-
 func TestApplyConfig_ProvisionMCRNoTunnelCount(t *testing.T) {
-	mockMCR := &MockMCRService{}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 mcrs:
@@ -1680,8 +1476,8 @@ mcrs:
 }
 
 func TestApplyConfig_ProvisionMCRWithIPsecTunnelCountJSON(t *testing.T) {
-	mockMCR := &MockMCRService{}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	jsonCfg := `{"mcrs":[{"name":"IPsec-MCR","location_id":2,"speed":1000,"term":12,"asn":65000,"tunnel_count":30}]}`
 	f := writeTempFile(t, "config.json", jsonCfg)
@@ -1711,8 +1507,8 @@ func TestApplyConfig_MCRInvalidTunnelCount(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockMCR := &MockMCRService{}
-			defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, &MockVXCService{})()
+			mockMCR := &infra.MockMCRService{}
+			defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 			yaml := fmt.Sprintf(`
 mcrs:
@@ -1739,8 +1535,8 @@ mcrs:
 }
 
 func TestApplyConfig_DryRunMCRInvalidTunnelCount(t *testing.T) {
-	mockMCR := &MockMCRService{}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 mcrs:
@@ -1765,120 +1561,10 @@ mcrs:
 	assert.Contains(t, captured, "invalid")
 }
 
-func TestParseConfigFile_RejectsTrailingJSON(t *testing.T) {
-	// json.NewDecoder reads only the first value; trailing data must be rejected so
-	// a duplicated or concatenated config body isn't silently half-applied.
-	jsonCfg := `{"mcrs":[{"name":"M","location_id":2,"speed":1000,"term":12}]}{"mcrs":[]}`
-	f := writeTempFile(t, "config.json", jsonCfg)
-	_, err := parseConfigFile(f)
-	assert.Error(t, err, "trailing JSON data should be rejected, not silently ignored")
-}
-
-func TestParseConfigFile_RejectsMultipleYAMLDocuments(t *testing.T) {
-	// A second ---separated document would otherwise be silently dropped.
-	yaml := `
-mcrs:
-  - name: First
-    location_id: 2
-    speed: 1000
-    term: 12
----
-mcrs:
-  - name: Second
-    location_id: 2
-    speed: 1000
-    term: 12
-`
-	f := writeTempFile(t, "config.yaml", yaml)
-	_, err := parseConfigFile(f)
-	assert.Error(t, err, "multiple YAML documents should be rejected, not silently dropped")
-}
-
-func TestParseConfigFile_AllowsBenignTrailingContent(t *testing.T) {
-	// A trailing document marker or comment carries no data, so it must be accepted.
-	// Only a second document with real content is rejected (see RejectsMultipleYAMLDocuments).
-	tests := []struct {
-		name    string
-		file    string
-		content string
-	}{
-		{
-			name: "yaml trailing bare separator",
-			file: "config.yaml",
-			content: `
-mcrs:
-  - name: Only
-    location_id: 2
-    speed: 1000
-    term: 12
----
-`,
-		},
-		{
-			name: "yaml trailing separator then comment",
-			file: "config.yaml",
-			content: `
-mcrs:
-  - name: Only
-    location_id: 2
-    speed: 1000
-    term: 12
----
-# nothing here
-`,
-		},
-		{
-			name:    "json trailing whitespace",
-			file:    "config.json",
-			content: "{\"mcrs\":[{\"name\":\"Only\",\"location_id\":2,\"speed\":1000,\"term\":12}]}\n   \n",
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			f := writeTempFile(t, tt.file, tt.content)
-			cfg, err := parseConfigFile(f)
-			require.NoError(t, err, "benign trailing content should be accepted")
-			require.Len(t, cfg.MCRs, 1, "the first document's data must survive")
-		})
-	}
-}
-
-func TestParseConfigFile_AllowsFreeformMapKeys(t *testing.T) {
-	// resource_tags and vendor_config are map types, so their inner keys are not
-	// subject to strict field checking — arbitrary (incl. camelCase) keys must survive.
-	yaml := `
-ports:
-  - name: P
-    location_id: 1
-    speed: 1000
-    term: 12
-    resource_tags:
-      ownerTeam: networking
-      costCenter: "1234"
-mves:
-  - name: M
-    location_id: 1
-    term: 12
-    vendor_config:
-      vendor: cisco
-      imageId: 42
-      productSize: SMALL
-`
-	f := writeTempFile(t, "config.yaml", yaml)
-	cfg, err := parseConfigFile(f)
-	require.NoError(t, err)
-	require.Len(t, cfg.Ports, 1)
-	assert.Equal(t, "networking", cfg.Ports[0].ResourceTags["ownerTeam"])
-	assert.Equal(t, "1234", cfg.Ports[0].ResourceTags["costCenter"])
-	require.Len(t, cfg.MVEs, 1)
-	assert.Equal(t, "cisco", cfg.MVEs[0].VendorConfig["vendor"])
-	assert.Contains(t, cfg.MVEs[0].VendorConfig, "imageId")
-}
-
 func TestApplyConfig_ProvisionMCRExplicitZeroTunnelCount(t *testing.T) {
 	// Explicit tunnel_count: 0 means no IPsec add-on, same as omitting it.
-	mockMCR := &MockMCRService{}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 mcrs:
@@ -1905,8 +1591,8 @@ mcrs:
 func TestApplyConfig_DryRunMixedMCRTunnelCounts(t *testing.T) {
 	// An invalid MCR is flagged while a later valid MCR still reaches SDK validation
 	// (dry-run continues rather than aborting on the first bad entry).
-	mockMCR := &MockMCRService{}
-	defer setupMockClient(&MockPortService{}, mockMCR, &MockMVEService{}, &MockVXCService{})()
+	mockMCR := &infra.MockMCRService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
 
 	yaml := `
 mcrs:
@@ -1935,3 +1621,377 @@ mcrs:
 	assert.Contains(t, captured, "invalid", "the invalid MCR should be flagged")
 	require.NotNil(t, mockMCR.CapturedValidateMCRRequest, "the valid MCR should still reach SDK validation")
 }
+
+func TestApplyConfig_ProvisionMCRWithPrefixFilterLists(t *testing.T) {
+	mockMCR := &infra.MockMCRService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	yaml := `
+mcrs:
+  - name: Filtered-MCR
+    location_id: 2
+    speed: 1000
+    term: 12
+    prefix_filter_lists:
+      - description: Customer routes
+        address_family: IPv4
+        entries:
+          - action: permit
+            prefix: 10.0.0.0/8
+            le: 24
+`
+	f := writeTempFile(t, "config.yaml", yaml)
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+
+	require.NoError(t, err)
+	require.Len(t, mockMCR.CapturedPrefixFilterLists, 1)
+	req := mockMCR.CapturedPrefixFilterLists[0]
+	assert.Equal(t, "mcr-uid-mock", req.MCRID)
+	assert.Equal(t, "Customer routes", req.PrefixFilterList.Description)
+	assert.Equal(t, []*megaport.MCRPrefixListEntry{{Action: "permit", Prefix: "10.0.0.0/8", Le: 24}}, req.PrefixFilterList.Entries)
+}
+
+func TestApplyConfig_InvalidPrefixFilterListFailsBeforeOrdering(t *testing.T) {
+	mockMCR := &infra.MockMCRService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	yaml := `
+mcrs:
+  - name: Filtered-MCR
+    location_id: 2
+    speed: 1000
+    term: 12
+    prefix_filter_lists:
+      - description: Customer routes
+        address_family: IPv5
+        entries:
+          - action: permit
+            prefix: 10.0.0.0/8
+`
+	f := writeTempFile(t, "config.yaml", yaml)
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `prefix filter list "Customer routes"`)
+	assert.Nil(t, mockMCR.CapturedMCRRequest)
+}
+
+const networkServicesTestConfig = `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 10000
+    term: 12
+nat_gateways:
+  - name: Edge-NAT
+    location_id: 1
+    speed: 1000
+    term: 12
+    session_count: 1000
+ixs:
+  - name: Sydney-IX
+    product_uid: "{{.port.Sydney-Port}}"
+    network_service_type: Sydney IX
+    asn: 65000
+    mac_address: "00:11:22:33:44:55"
+    rate_limit: 1000
+    vlan: 200
+service_keys:
+  - name: Partner-Key
+    product_uid: "{{.port.Sydney-Port}}"
+    max_speed: 500
+    active: true
+`
+
+func TestApplyConfig_ProvisionNetworkServices(t *testing.T) {
+	mockPort := &infra.MockPortService{}
+	mockNAT, mockIX, mockKeys := &infra.MockNATGatewayService{}, &infra.MockIXService{}, &infra.MockServiceKeyService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	defer infra.SetupMockNetworkServices(mockNAT, mockIX, mockKeys)()
+
+	f := writeTempFile(t, "infra.yaml", networkServicesTestConfig)
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, mockNAT.CapturedCreateNATGateway)
+	assert.Equal(t, "Edge-NAT", mockNAT.CapturedCreateNATGateway.ProductName)
+	assert.Equal(t, 1000, mockNAT.CapturedCreateNATGateway.Config.SessionCount)
+	assert.Equal(t, []string{"nat-uid-mock"}, mockNAT.BoughtNATGateways)
+
+	require.NotNil(t, mockIX.CapturedIXRequest)
+	assert.Equal(t, "port-uid-mock", mockIX.CapturedIXRequest.ProductUID, "the IX port template resolves to the new port")
+
+	require.NotNil(t, mockKeys.CapturedCreateServiceKey)
+	assert.Equal(t, "port-uid-mock", mockKeys.CapturedCreateServiceKey.ProductUID)
+	assert.Equal(t, "Partner-Key", mockKeys.CapturedCreateServiceKey.Description)
+
+	state, err := infra.LoadState(infra.DefaultStatePath(f))
+	require.NoError(t, err)
+	for _, want := range []infra.StateResource{
+		{Type: "nat_gateway", Name: "Edge-NAT", UID: "nat-uid-mock"},
+		{Type: "ix", Name: "Sydney-IX", UID: "ix-uid-mock"},
+		{Type: "service_key", Name: "Partner-Key", UID: "service-key-mock"},
+	} {
+		uid, ok := state.Lookup(want.Type, want.Name)
+		assert.True(t, ok, want.Type)
+		assert.Equal(t, want.UID, uid, want.Type)
+	}
+}
+
+func TestApplyConfig_NATGatewayDesignIsBoughtOnNextRun(t *testing.T) {
+	mockNAT := &infra.MockNATGatewayService{ValidateNATGatewayOrderErr: fmt.Errorf("speed unavailable")}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	defer infra.SetupMockNetworkServices(mockNAT, &infra.MockIXService{}, &infra.MockServiceKeyService{})()
+
+	cfg := `
+nat_gateways:
+  - name: Edge-NAT
+    location_id: 1
+    speed: 1000
+    term: 12
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmdWithRollback(f), nil, true, "table")
+	})
+	require.Error(t, err)
+	assert.Empty(t, mockNAT.BoughtNATGateways)
+	assert.Empty(t, mockNAT.DeleteNATGatewayCalledWith, "an unbought design is not rolled back")
+
+	state, err := infra.LoadState(infra.DefaultStatePath(f))
+	require.NoError(t, err)
+	uid, ok := state.Lookup("nat_gateway", "Edge-NAT")
+	require.True(t, ok, "the design is recorded so the next run can buy it")
+	assert.Equal(t, "nat-uid-mock", uid)
+
+	// The next run finds the design and buys it instead of designing another.
+	mockNAT.ValidateNATGatewayOrderErr = nil
+	mockNAT.CapturedCreateNATGateway = nil
+	mockNAT.Designs = []string{"nat-uid-mock"}
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Nil(t, mockNAT.CapturedCreateNATGateway)
+	require.NotNil(t, mockNAT.CapturedUpdateNATGateway)
+	assert.Equal(t, "nat-uid-mock", mockNAT.CapturedUpdateNATGateway.ProductUID)
+	assert.Equal(t, []string{"nat-uid-mock"}, mockNAT.BoughtNATGateways)
+}
+
+func TestApplyConfig_LAGPort(t *testing.T) {
+	mockPort := &infra.MockPortService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	cfg := `
+ports:
+  - name: Sydney-LAG
+    location_id: 1
+    speed: 10000
+    term: 12
+    lag_count: 2
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+	require.NotNil(t, mockPort.CapturedPortRequest)
+	assert.Equal(t, 2, mockPort.CapturedPortRequest.LagCount)
+}
+
+func TestApplyConfig_RollbackDeactivatesServiceKeys(t *testing.T) {
+	mockKeys := &infra.MockServiceKeyService{}
+	mockVXC := &infra.MockVXCService{BuyVXCErr: fmt.Errorf("VXC quota exceeded")}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+	defer infra.SetupMockNetworkServices(&infra.MockNATGatewayService{}, &infra.MockIXService{}, mockKeys)()
+
+	cfg := `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+service_keys:
+  - name: Partner-Key
+    product_uid: "{{.port.Sydney-Port}}"
+    max_speed: 500
+    active: true
+vxcs:
+  - name: Fail-VXC
+    rate_limit: 100
+    term: 12
+    a_end:
+      product_uid: "{{.port.Sydney-Port}}"
+    b_end:
+      product_uid: "port-partner-uid"
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmdWithRollback(f), nil, true, "table")
+	})
+	require.Error(t, err)
+	require.Len(t, mockKeys.CapturedUpdates, 1, "service keys cannot be deleted, so rollback deactivates them")
+	assert.Equal(t, "service-key-mock", mockKeys.CapturedUpdates[0].Key)
+	assert.False(t, mockKeys.CapturedUpdates[0].Active)
+}
+
+func TestApplyConfig_DryRunNetworkServices(t *testing.T) {
+	mockNAT, mockIX, mockKeys := &infra.MockNATGatewayService{}, &infra.MockIXService{}, &infra.MockServiceKeyService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	defer infra.SetupMockNetworkServices(mockNAT, mockIX, mockKeys)()
+
+	f := writeTempFile(t, "infra.yaml", networkServicesTestConfig)
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, true, false), nil, true, "json")
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, "skipped: requires a design")
+	assert.Contains(t, out, "skipped: requires provisioning")
+	assert.Nil(t, mockNAT.CapturedCreateNATGateway)
+	assert.Nil(t, mockIX.CapturedIXRequest)
+	assert.Nil(t, mockKeys.CapturedCreateServiceKey)
+}
+
+const partnerVXCTestConfig = `
+mcrs:
+  - name: Sydney-MCR
+    location_id: 1
+    speed: 1000
+    term: 12
+vxcs:
+  - name: MCR-to-AWS
+    rate_limit: 500
+    term: 12
+    a_end:
+      product_uid: "{{.mcr.Sydney-MCR}}"
+      partner_config:
+        connectType: VROUTER
+        interfaces:
+          - ipAddresses: ["10.0.0.1/30"]
+            bgpConnections:
+              - peerAsn: 64512
+                localIpAddress: 10.0.0.1
+                peerIpAddress: 10.0.0.2
+                password: bgp-secret
+    b_end:
+      product_uid: aws-port-uid
+      partner_config:
+        connectType: AWS
+        type: private
+        ownerAccount: "123456789012"
+        asn: 65000
+        amazonAsn: 64512
+  - name: MCR-to-Azure
+    rate_limit: 200
+    term: 12
+    a_end:
+      product_uid: "{{.mcr.Sydney-MCR}}"
+    b_end:
+      partner_config:
+        connectType: AZURE
+        serviceKey: azure-service-key
+`
+
+func TestApplyConfig_VXCPartnerConfigs(t *testing.T) {
+	mockVXC := &infra.MockVXCService{PartnerPortUID: "azure-port-uid"}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "infra.yaml", partnerVXCTestConfig)
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+
+	// The last order is the Azure VXC, whose port was looked up from its key.
+	azure := mockVXC.CapturedVXCRequest
+	require.NotNil(t, azure)
+	assert.Equal(t, "azure-port-uid", azure.BEndConfiguration.ProductUID)
+	require.NotNil(t, mockVXC.CapturedLookup)
+	assert.Equal(t, "azure-service-key", mockVXC.CapturedLookup.Key)
+	assert.Equal(t, "AZURE", mockVXC.CapturedLookup.Partner)
+	assert.IsType(t, &megaport.VXCPartnerConfigAzure{}, azure.BEndConfiguration.PartnerConfig)
+}
+
+func TestVXCRequest_PartnerAndVRouterConfig(t *testing.T) {
+	cfg, err := infra.ParseConfigFile(writeTempFile(t, "infra.yaml", partnerVXCTestConfig), nil, utils.ProjectSettings{})
+	require.NoError(t, err)
+
+	req, err := infra.VXCRequest(context.Background(), &megaport.Client{}, cfg.VXCs[0], "mcr-uid-1", "aws-port-uid", nil)
+	require.NoError(t, err)
+	require.NoError(t, validation.ValidateVXCRequest(req))
+
+	aws, ok := req.BEndConfiguration.PartnerConfig.(*megaport.VXCPartnerConfigAWS)
+	require.True(t, ok, "%T", req.BEndConfiguration.PartnerConfig)
+	assert.Equal(t, "123456789012", aws.OwnerAccount)
+	assert.Equal(t, 65000, aws.ASN, "YAML integers are accepted")
+	assert.Equal(t, 64512, aws.AmazonASN)
+
+	vr, ok := req.AEndConfiguration.PartnerConfig.(*megaport.VXCOrderVrouterPartnerConfig)
+	require.True(t, ok, "%T", req.AEndConfiguration.PartnerConfig)
+	require.Len(t, vr.Interfaces, 1)
+	require.Len(t, vr.Interfaces[0].BgpConnections, 1)
+	assert.Equal(t, 64512, vr.Interfaces[0].BgpConnections[0].PeerAsn)
+	assert.Equal(t, "bgp-secret", vr.Interfaces[0].BgpConnections[0].Password)
+}
+
+func TestVXCRequest_MVEEndpoint(t *testing.T) {
+	vnic := 0
+	v := infra.VXCConfig{
+		Name: "MVE-to-Port", RateLimit: 100, Term: 12,
+		AEnd: infra.VXCEndpointConfig{ProductUID: "{{.mve.Edge}}", InnerVLAN: 300, VNICIndex: &vnic},
+		BEnd: infra.VXCEndpointConfig{ProductUID: "port-uid-1", VLAN: 200},
+	}
+	req, err := infra.VXCRequest(context.Background(), &megaport.Client{}, v, "mve-uid-1", "port-uid-1", nil)
+	require.NoError(t, err)
+	require.NotNil(t, req.AEndConfiguration.VXCOrderMVEConfig, "an explicit vNIC index of 0 is sent")
+	assert.Equal(t, 300, req.AEndConfiguration.InnerVLAN)
+	assert.Equal(t, 0, req.AEndConfiguration.NetworkInterfaceIndex)
+	assert.Nil(t, req.BEndConfiguration.VXCOrderMVEConfig)
+
+	vnic = -1
+	_, err = infra.VXCRequest(context.Background(), &megaport.Client{}, v, "mve-uid-1", "port-uid-1", nil)
+	assert.ErrorContains(t, err, "vNIC index")
+}
+
+func TestApplyConfig_InvalidPartnerConfigFailsBeforeOrdering(t *testing.T) {
+	mockVXC := &infra.MockVXCService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+
+	cfg := `
+vxcs:
+  - name: Port-to-AWS
+    rate_limit: 500
+    term: 12
+    a_end:
+      product_uid: port-uid-1
+    b_end:
+      product_uid: aws-port-uid
+      partner_config:
+        connectType: AWS
+        asn: 65000
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ownerAccount is required")
+	assert.Nil(t, mockVXC.CapturedVXCRequest)
+}
@@ -0,0 +1,505 @@
+package apply
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/megaport/megaport-cli/internal/validation"
+	megaport "github.com/megaport/megaportgo"
+)
+
+// pendingChanges holds a plan entry (update, replace or no-op) for every
+// existing resource in the config.
+type pendingChanges []infra.PlanEntry
+
+// diffExisting compares each existing resource with its config entry, in
+// provisioning order so VXC endpoints can resolve against the resources before
+// them. A resource that will be replaced gets a new UID, so VXCs attached to it
+// see an endpoint change.
+func diffExisting(cfg *infra.Config, existing map[string]map[string]*infra.LiveResource) pendingChanges {
+	var pending pendingChanges
+	uids := infra.NewTypeMap[string]()
+	add := func(resType, name string, changes []output.FieldChange) {
+		live := existing[resType][name]
+		entry := infra.MatchedEntry(infra.PlanEntry{Type: infra.DisplayType(resType), Name: name}, resType, live.UID, changes)
+		if entry.Action != infra.PlanReplace {
+			uids[resType][name] = live.UID
+		}
+		pending = append(pending, entry)
+	}
+	for _, p := range cfg.Ports {
+		if live, ok := existing["port"][p.Name]; ok {
+			add("port", p.Name, infra.PortChanges(p, live.Port, live.Tags))
+		}
+	}
+	for _, m := range cfg.MCRs {
+		if live, ok := existing["mcr"][m.Name]; ok {
+			add("mcr", m.Name, infra.MCRChanges(m, live.MCR, live.Tags))
+		}
+	}
+	for _, mv := range cfg.MVEs {
+		if live, ok := existing["mve"][mv.Name]; ok {
+			add("mve", mv.Name, infra.MVEChanges(mv, live.MVE, live.Tags))
+		}
+	}
+	for _, n := range cfg.NATGateways {
+		if live, ok := existing["nat_gateway"][n.Name]; ok {
+			add("nat_gateway", n.Name, infra.NATGatewayChanges(n, live.NATGateway, live.Tags))
+		}
+	}
+	for _, x := range cfg.IXs {
+		if live, ok := existing["ix"][x.Name]; ok {
+			add("ix", x.Name, infra.IXChanges(x, live.IX))
+		}
+	}
+	for _, k := range cfg.ServiceKeys {
+		if live, ok := existing["service_key"][k.Name]; ok {
+			add("service_key", k.Name, infra.ServiceKeyChanges(k, live.ServiceKey, uids))
+		}
+	}
+	for _, v := range cfg.VXCs {
+		if live, ok := existing["vxc"][v.Name]; ok {
+			add("vxc", v.Name, infra.VXCChanges(v, live.VXC, live.Tags, uids))
+		}
+	}
+	return pending
+}
+
+// count returns the number of entries with action.
+func (p pendingChanges) count(action string) int {
+	n := 0
+	for _, e := range p {
+		if e.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// replacements describes each entry that requires replacement.
+func (p pendingChanges) replacements() []string {
+	var out []string
+	for _, e := range p {
+		if e.Action == infra.PlanReplace {
+			out = append(out, fmt.Sprintf("%s %q (%s): %s", e.Type, e.Name, e.UID, e.Detail))
+		}
+	}
+	return out
+}
+
+// provisionedStatus is the result status for a newly ordered resource.
+func provisionedStatus(replaces string) string {
+	if replaces == "" {
+		return "provisioned"
+	}
+	return "provisioned (replaces " + replaces + ")"
+}
+
+// changedFields indexes changes by label.
+func changedFields(changes []output.FieldChange) map[string]bool {
+	changed := make(map[string]bool, len(changes))
+	for _, c := range changes {
+		changed[c.Label] = true
+	}
+	return changed
+}
+
+// applyUpdate runs update for an existing resource with pending in-place
+// changes and returns the result status. A resource without changes is left
+// alone.
+func applyUpdate(ctx context.Context, p *progress, resType, name, uid string, changes []output.FieldChange, update func(ctx context.Context) error) (string, error) {
+	if len(changes) == 0 {
+		return infra.StatusUnchanged, nil
+	}
+	spinner := p.updating(resType, name, uid)
+	err := update(ctx)
+	spinner.Stop()
+	if err != nil {
+		return "", err
+	}
+	p.updated(resType, uid)
+	labels := make([]string, 0, len(changes))
+	for _, c := range changes {
+		labels = append(labels, c.Label)
+	}
+	return fmt.Sprintf("%s (%s)", infra.StatusUpdated, strings.Join(labels, ", ")), nil
+}
+
+// updateTags replaces a resource's tags with declared.
+func updateTags(ctx context.Context, uid string, declared map[string]string, update func(context.Context, string, map[string]string) error) error {
+	if err := utils.WithRetry(ctx, func(ctx context.Context) error {
+		return update(ctx, uid, declared)
+	}); err != nil {
+		return fmt.Errorf("updating resource tags: %w", err)
+	}
+	return nil
+}
+
+// updatePort applies in-place changes to an existing port.
+func updatePort(ctx context.Context, client *megaport.Client, p infra.PortConfig, live *infra.LiveResource, changes []output.FieldChange, timeout time.Duration) error {
+	changed := changedFields(changes)
+	if err := changeLock(ctx, client, live.UID, p.Locked, changed, false); err != nil {
+		return err
+	}
+	if changed["name"] || changed["term"] || changed["marketplace_visibility"] || changed["cost_centre"] {
+		req := &megaport.ModifyPortRequest{
+			PortID: live.UID,
+			Name:   p.Name,
+			// The SDK always sends the cost centre, so keep the live value
+			// unless the config manages it.
+			CostCentre:    cmp.Or(p.CostCentre, live.Port.CostCentre),
+			WaitForUpdate: true,
+			WaitForTime:   timeout,
+		}
+		if changed["term"] {
+			req.ContractTermMonths = &p.Term
+		}
+		if changed["marketplace_visibility"] {
+			req.MarketplaceVisibility = &p.MarketplaceVisibility
+		}
+		var resp *megaport.ModifyPortResponse
+		err := utils.WithRetry(ctx, func(ctx context.Context) error {
+			var e error
+			resp, e = client.PortService.ModifyPort(ctx, req)
+			return e
+		})
+		if err != nil {
+			return err
+		}
+		if resp == nil {
+			return fmt.Errorf("empty response from API")
+		}
+		if !resp.IsUpdated {
+			return fmt.Errorf("port update request was not successful")
+		}
+	}
+	if changed["resource_tags"] {
+		if err := updateTags(ctx, live.UID, p.ResourceTags, client.PortService.UpdatePortResourceTags); err != nil {
+			return err
+		}
+	}
+	return changeLock(ctx, client, live.UID, p.Locked, changed, true)
+}
+
+// updateMCR applies in-place changes to an existing MCR.
+func updateMCR(ctx context.Context, client *megaport.Client, m infra.MCRConfig, live *infra.LiveResource, changes []output.FieldChange, timeout time.Duration) error {
+	changed := changedFields(changes)
+	if err := changeLock(ctx, client, live.UID, m.Locked, changed, false); err != nil {
+		return err
+	}
+	if changed["name"] || changed["term"] || changed["asn"] || changed["cost_centre"] {
+		req := &megaport.ModifyMCRRequest{
+			MCRID:         live.UID,
+			Name:          m.Name,
+			CostCentre:    cmp.Or(m.CostCentre, live.MCR.CostCentre),
+			WaitForUpdate: true,
+			WaitForTime:   timeout,
+		}
+		if changed["term"] {
+			req.ContractTermMonths = &m.Term
+		}
+		if changed["asn"] {
+			req.MCRAsn = &m.ASN
+		}
+		var resp *megaport.ModifyMCRResponse
+		err := utils.WithRetry(ctx, func(ctx context.Context) error {
+			var e error
+			resp, e = client.MCRService.ModifyMCR(ctx, req)
+			return e
+		})
+		if err != nil {
+			return err
+		}
+		if resp == nil {
+			return fmt.Errorf("empty response from API")
+		}
+		if !resp.IsUpdated {
+			return fmt.Errorf("MCR update request was not successful")
+		}
+	}
+	if changed["resource_tags"] {
+		if err := updateTags(ctx, live.UID, m.ResourceTags, client.MCRService.UpdateMCRResourceTags); err != nil {
+			return err
+		}
+	}
+	return changeLock(ctx, client, live.UID, m.Locked, changed, true)
+}
+
+// updateMVE applies in-place changes to an existing MVE.
+func updateMVE(ctx context.Context, client *megaport.Client, mv infra.MVEConfig, live *infra.LiveResource, changes []output.FieldChange, timeout time.Duration) error {
+	changed := changedFields(changes)
+	if err := changeLock(ctx, client, live.UID, mv.Locked, changed, false); err != nil {
+		return err
+	}
+	if changed["name"] || changed["term"] || changed["cost_centre"] {
+		req := &megaport.ModifyMVERequest{
+			MVEID:         live.UID,
+			Name:          mv.Name,
+			CostCentre:    cmp.Or(mv.CostCentre, live.MVE.CostCentre),
+			WaitForUpdate: true,
+			WaitForTime:   timeout,
+		}
+		if changed["term"] {
+			req.ContractTermMonths = &mv.Term
+		}
+		var resp *megaport.ModifyMVEResponse
+		err := utils.WithRetry(ctx, func(ctx context.Context) error {
+			var e error
+			resp, e = client.MVEService.ModifyMVE(ctx, req)
+			return e
+		})
+		if err != nil {
+			return err
+		}
+		if resp == nil {
+			return fmt.Errorf("empty response from API")
+		}
+		if !resp.MVEUpdated {
+			return fmt.Errorf("MVE update request was not successful")
+		}
+	}
+	if changed["resource_tags"] {
+		if err := updateTags(ctx, live.UID, mv.ResourceTags, client.MVEService.UpdateMVEResourceTags); err != nil {
+			return err
+		}
+	}
+	return changeLock(ctx, client, live.UID, mv.Locked, changed, true)
+}
+
+// updateVXC applies in-place changes to an existing VXC. aUID and bUID are the
+// resolved endpoint UIDs; a changed endpoint moves the VXC.
+func updateVXC(ctx context.Context, client *megaport.Client, v infra.VXCConfig, aUID, bUID string, live *infra.LiveResource, changes []output.FieldChange, timeout time.Duration) error {
+	changed := changedFields(changes)
+	if err := changeLock(ctx, client, live.UID, v.Locked, changed, false); err != nil {
+		return err
+	}
+	req := &megaport.UpdateVXCRequest{
+		WaitForUpdate: true,
+		WaitForTime:   timeout,
+	}
+	needsUpdate := false
+	if changed["name"] {
+		req.Name, needsUpdate = &v.Name, true
+	}
+	if changed["rate_limit"] {
+		req.RateLimit, needsUpdate = &v.RateLimit, true
+	}
+	if changed["term"] {
+		req.Term, needsUpdate = &v.Term, true
+	}
+	if changed["cost_centre"] {
+		req.CostCentre, needsUpdate = &v.CostCentre, true
+	}
+	if changed["a_end.product_uid"] {
+		req.AEndProductUID, needsUpdate = &aUID, true
+	}
+	if changed["b_end.product_uid"] {
+		req.BEndProductUID, needsUpdate = &bUID, true
+	}
+	if changed["a_end.vlan"] {
+		req.AEndVLAN, needsUpdate = &v.AEnd.VLAN, true
+	}
+	if changed["b_end.vlan"] {
+		req.BEndVLAN, needsUpdate = &v.BEnd.VLAN, true
+	}
+	if changed["a_end.inner_vlan"] {
+		req.AEndInnerVLAN, needsUpdate = &v.AEnd.InnerVLAN, true
+	}
+	if changed["b_end.inner_vlan"] {
+		req.BEndInnerVLAN, needsUpdate = &v.BEnd.InnerVLAN, true
+	}
+	if changed["a_end.vnic_index"] {
+		req.AVnicIndex, needsUpdate = v.AEnd.VNICIndex, true
+	}
+	if changed["b_end.vnic_index"] {
+		req.BVnicIndex, needsUpdate = v.BEnd.VNICIndex, true
+	}
+	if needsUpdate {
+		err := utils.WithRetry(ctx, func(ctx context.Context) error {
+			_, e := client.VXCService.UpdateVXC(ctx, live.UID, req)
+			return e
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if changed["resource_tags"] {
+		if err := updateTags(ctx, live.UID, v.ResourceTags, client.VXCService.UpdateVXCResourceTags); err != nil {
+			return err
+		}
+	}
+	return changeLock(ctx, client, live.UID, v.Locked, changed, true)
+}
+
+// updateNATGateway applies in-place changes to an existing NAT gateway. The
+// update API replaces the whole gateway, so fields the config leaves unset
+// keep their live values.
+func updateNATGateway(ctx context.Context, client *megaport.Client, n infra.NATGatewayConfig, live *infra.LiveResource, changes []output.FieldChange) error {
+	changed := changedFields(changes)
+	if err := changeLock(ctx, client, live.UID, n.Locked, changed, false); err != nil {
+		return err
+	}
+	gw := live.NATGateway
+	req := &megaport.UpdateNATGatewayRequest{
+		ProductUID:            live.UID,
+		ProductName:           n.Name,
+		LocationID:            gw.LocationID,
+		Speed:                 n.Speed,
+		Term:                  n.Term,
+		AutoRenewTerm:         n.AutoRenewTerm,
+		Config:                gw.Config,
+		PromoCode:             gw.PromoCode,
+		ResourceTags:          gw.ResourceTags,
+		ServiceLevelReference: cmp.Or(n.ServiceLevelReference, gw.ServiceLevelReference),
+	}
+	if n.SessionCount != 0 {
+		req.Config.SessionCount = n.SessionCount
+	}
+	if n.ASN != 0 {
+		req.Config.ASN = n.ASN
+	}
+	if n.ResourceTags != nil {
+		req.ResourceTags = resourceTagList(n.ResourceTags)
+	}
+	// A lock change alone needs no update call.
+	if slices.ContainsFunc(changes, func(c output.FieldChange) bool { return c.Label != "locked" }) {
+		if err := validation.ValidateUpdateNATGatewayRequest(req); err != nil {
+			return err
+		}
+		err := utils.WithRetry(ctx, func(ctx context.Context) error {
+			_, e := client.NATGatewayService.UpdateNATGateway(ctx, req)
+			return e
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return changeLock(ctx, client, live.UID, n.Locked, changed, true)
+}
+
+// updateIX applies in-place changes to an existing IX.
+func updateIX(ctx context.Context, client *megaport.Client, x infra.IXConfig, live *infra.LiveResource, changes []output.FieldChange, timeout time.Duration) error {
+	changed := changedFields(changes)
+	req := &megaport.UpdateIXRequest{
+		WaitForUpdate: true,
+		WaitForTime:   timeout,
+	}
+	if changed["name"] {
+		req.Name = &x.Name
+	}
+	if changed["rate_limit"] {
+		req.RateLimit = &x.RateLimit
+	}
+	if changed["vlan"] {
+		req.VLAN = &x.VLAN
+	}
+	if changed["mac_address"] {
+		req.MACAddress = &x.MACAddress
+	}
+	if changed["asn"] {
+		req.ASN = &x.ASN
+	}
+	return utils.WithRetry(ctx, func(ctx context.Context) error {
+		_, e := client.IXService.UpdateIX(ctx, live.UID, req)
+		return e
+	})
+}
+
+// updateServiceKey applies in-place changes to an existing service key.
+// productUID is the resolved port; the update API sets every field it takes,
+// so the request carries the declared values whether or not they changed.
+func updateServiceKey(ctx context.Context, client *megaport.Client, k infra.ServiceKeyConfig, productUID string, live *infra.LiveResource) error {
+	var resp *megaport.UpdateServiceKeyResponse
+	err := utils.WithRetry(ctx, func(ctx context.Context) error {
+		var e error
+		resp, e = client.ServiceKeyService.UpdateServiceKey(ctx, &megaport.UpdateServiceKeyRequest{
+			Key:        live.UID,
+			ProductUID: productUID,
+			SingleUse:  k.SingleUse,
+			Active:     k.Active,
+		})
+		return e
+	})
+	if err != nil {
+		return err
+	}
+	if resp == nil {
+		return fmt.Errorf("empty response from API")
+	}
+	if !resp.IsUpdated {
+		return fmt.Errorf("service key update request was not successful")
+	}
+	return nil
+}
+
+// changeLock applies a changed locked field when its new value is lock. Updates
+// call it with lock=false before their other changes and lock=true after them,
+// so a resource is unlocked before it is modified and locked once it has been.
+func changeLock(ctx context.Context, client *megaport.Client, uid string, locked *bool, changed map[string]bool, lock bool) error {
+	if !changed["locked"] || locked == nil || *locked != lock {
+		return nil
+	}
+	return setLock(ctx, client, uid, lock)
+}
+
+// setLock locks or unlocks a product.
+func setLock(ctx context.Context, client *megaport.Client, uid string, lock bool) error {
+	err := utils.WithRetry(ctx, func(ctx context.Context) error {
+		_, e := client.ProductService.ManageProductLock(ctx, &megaport.ManageProductLockRequest{ProductID: uid, ShouldLock: lock})
+		return e
+	})
+	if err == nil {
+		return nil
+	}
+	if lock {
+		return fmt.Errorf("locking: %w", err)
+	}
+	return fmt.Errorf("unlocking: %w", err)
+}
+
+// lockCreated locks the new resources whose config sets locked: true. It runs
+// once the whole run has succeeded, so a rollback never meets a locked
+// resource. A failed lock only warns: the resource is provisioned and recorded,
+// and the next apply locks it.
+func lockCreated(client *megaport.Client, created []infra.CreatedResource, noColor bool, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, r := range created {
+		if !r.Lock {
+			continue
+		}
+		if err := setLock(ctx, client, r.UID, true); err != nil {
+			output.PrintWarning("Could not lock %s %q (%s): %v; re-run apply to lock it", noColor, r.ResType, r.Name, r.UID, err)
+		}
+	}
+}
+
+// deleteReplaced deletes the resources superseded by replacements once the
+// whole run has succeeded, so dependents have already been moved to the new
+// UIDs. They are kept in the state as pending deletes until their delete
+// succeeds, so one that fails is retried by the next apply or destroy; this
+// also finishes the deletes an earlier run left.
+func deleteReplaced(client *megaport.Client, st *infra.StateFile, noColor bool, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, r := range slices.Backward(st.PendingDeletes()) {
+		old := infra.CreatedResource{ResType: infra.DisplayType(r.Type), Name: r.Name, UID: r.UID}
+		err := utils.WithRetry(ctx, func(ctx context.Context) error {
+			return infra.DeleteResource(ctx, client, old)
+		})
+		if err != nil && !infra.IsNotFound(err) {
+			output.PrintWarning("Could not delete replaced %s %q (%s): %v; it is still billing, and the next apply or destroy will retry", noColor, old.ResType, r.Name, old.UID, err)
+			output.PrintWarning("  To remove manually: %s", noColor, removeCommand(old.ResType, old.UID))
+			continue
+		}
+		st.DeletedReplaced(old.UID, noColor)
+		output.PrintSuccess("Deleted replaced %s %q (%s)", noColor, old.ResType, r.Name, old.UID)
+	}
+}
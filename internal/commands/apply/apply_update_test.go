@@ -0,0 +1,401 @@
+package apply
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/infra"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const updateTestConfig = `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+vxcs:
+  - name: Port-to-Cloud
+    rate_limit: 500
+    term: 12
+    a_end:
+      product_uid: "{{.port.Sydney-Port}}"
+    b_end:
+      product_uid: "b-end-uid"
+`
+
+// livePort matches the port in updateTestConfig.
+func livePort() *megaport.Port {
+	return &megaport.Port{Name: "Sydney-Port", LocationID: 1, PortSpeed: 1000, ContractTermMonths: 12, CostCentre: "NET-01"}
+}
+
+// liveVXC matches the VXC in updateTestConfig, attached to aEnd.
+func liveVXC(aEnd string) *megaport.VXC {
+	return &megaport.VXC{
+		Name: "Port-to-Cloud", RateLimit: 500, ContractTermMonths: 12,
+		AEndConfiguration: megaport.VXCEndConfiguration{UID: aEnd},
+		BEndConfiguration: megaport.VXCEndConfiguration{UID: "b-end-uid"},
+	}
+}
+
+func TestApplyConfig_UpdatesVXCRateLimitInPlace(t *testing.T) {
+	vxc := liveVXC("port-uid-1")
+	vxc.RateLimit = 100
+	mockPort := &infra.MockPortService{GetPortResult: livePort()}
+	mockVXC := &infra.MockVXCService{GetVXCResult: vxc}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "infra.yaml", updateTestConfig)
+	writeStateFile(t, f,
+		infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"},
+		infra.StateResource{Type: "vxc", Name: "Port-to-Cloud", UID: "vxc-uid-1"},
+	)
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+
+	assert.Nil(t, mockVXC.CapturedVXCRequest, "an existing VXC must not be ordered again")
+	assert.Nil(t, mockPort.CapturedModifyPort, "an unchanged port must not be updated")
+	require.NotNil(t, mockVXC.CapturedUpdateVXC)
+	require.NotNil(t, mockVXC.CapturedUpdateVXC.RateLimit)
+	assert.Equal(t, 500, *mockVXC.CapturedUpdateVXC.RateLimit)
+	assert.Nil(t, mockVXC.CapturedUpdateVXC.Name)
+	assert.Nil(t, mockVXC.CapturedUpdateVXC.AEndProductUID)
+	assert.Contains(t, out, "updated (rate_limit)")
+}
+
+func TestApplyConfig_UpdatesPortNameAndTags(t *testing.T) {
+	port := livePort()
+	port.Name = "Renamed-In-Portal"
+	cfg := `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+    resource_tags:
+      env: prod
+`
+	mockPort := &infra.MockPortService{
+		GetPortResult: port,
+		ResourceTags:  map[string]map[string]string{"port-uid-1": {"env": "dev"}},
+	}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	f := writeTempFile(t, "infra.yaml", cfg)
+	writeStateFile(t, f, infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"})
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, mockPort.CapturedModifyPort)
+	assert.Equal(t, "port-uid-1", mockPort.CapturedModifyPort.PortID)
+	assert.Equal(t, "Sydney-Port", mockPort.CapturedModifyPort.Name)
+	assert.Equal(t, "NET-01", mockPort.CapturedModifyPort.CostCentre, "an unmanaged cost centre must be preserved")
+	assert.Nil(t, mockPort.CapturedModifyPort.ContractTermMonths)
+	assert.Equal(t, map[string]string{"env": "prod"}, mockPort.CapturedTagUpdate)
+	assert.Nil(t, mockPort.CapturedPortRequest)
+}
+
+func TestApplyConfig_UpdatesMCRASN(t *testing.T) {
+	mcr := &megaport.MCR{Name: "Sydney-MCR", LocationID: 1, PortSpeed: 1000, ContractTermMonths: 12}
+	mcr.Resources.VirtualRouter.ASN = 133937
+	cfg := `
+mcrs:
+  - name: Sydney-MCR
+    location_id: 1
+    speed: 1000
+    term: 12
+    asn: 64512
+`
+	mockMCR := &infra.MockMCRService{GetMCRResult: mcr}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	f := writeTempFile(t, "infra.yaml", cfg)
+	writeStateFile(t, f, infra.StateResource{Type: "mcr", Name: "Sydney-MCR", UID: "mcr-uid-1"})
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+	require.NotNil(t, mockMCR.CapturedModifyMCR)
+	require.NotNil(t, mockMCR.CapturedModifyMCR.MCRAsn)
+	assert.Equal(t, 64512, *mockMCR.CapturedModifyMCR.MCRAsn)
+	assert.Nil(t, mockMCR.CapturedMCRRequest)
+}
+
+func TestApplyConfig_UpdateErrorFails(t *testing.T) {
+	mcr := &megaport.MCR{Name: "Old-Name", LocationID: 1, PortSpeed: 1000, ContractTermMonths: 12}
+	cfg := `
+mcrs:
+  - name: Sydney-MCR
+    location_id: 1
+    speed: 1000
+    term: 12
+`
+	mockMCR := &infra.MockMCRService{GetMCRResult: mcr, ModifyMCRErr: fmt.Errorf("update rejected")}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	f := writeTempFile(t, "infra.yaml", cfg)
+	writeStateFile(t, f, infra.StateResource{Type: "mcr", Name: "Sydney-MCR", UID: "mcr-uid-1"})
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to update MCR "Sydney-MCR"`)
+	assert.Contains(t, err.Error(), "update rejected")
+}
+
+func TestApplyConfig_RefusesReplacementWithoutFlag(t *testing.T) {
+	port := livePort()
+	port.LocationID = 2
+	mockPort := &infra.MockPortService{GetPortResult: port}
+	mockVXC := &infra.MockVXCService{GetVXCResult: liveVXC("port-uid-1")}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "infra.yaml", updateTestConfig)
+	writeStateFile(t, f,
+		infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"},
+		infra.StateResource{Type: "vxc", Name: "Port-to-Cloud", UID: "vxc-uid-1"},
+	)
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.Error(t, err)
+	var cliErr *exitcodes.CLIError
+	require.True(t, errors.As(err, &cliErr))
+	assert.Equal(t, exitcodes.Usage, cliErr.Code)
+	assert.Contains(t, out, "location_id (requires replacement)")
+	assert.Nil(t, mockPort.CapturedPortRequest, "nothing may be ordered when a replacement is refused")
+	assert.Nil(t, mockPort.CapturedModifyPort)
+	assert.Nil(t, mockVXC.CapturedUpdateVXC)
+	assert.Empty(t, mockPort.DeletePortCalledWith)
+}
+
+func TestApplyConfig_ReplacesWithAllowReplace(t *testing.T) {
+	port := livePort()
+	port.LocationID = 2
+	mockPort := &infra.MockPortService{
+		GetPortResult: port,
+		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-uid-new"}},
+	}
+	mockVXC := &infra.MockVXCService{GetVXCResult: liveVXC("port-uid-1")}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "infra.yaml", updateTestConfig)
+	statePath := writeStateFile(t, f,
+		infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"},
+		infra.StateResource{Type: "vxc", Name: "Port-to-Cloud", UID: "vxc-uid-1"},
+	)
+	cmd := applyCmd(f, false, true)
+	require.NoError(t, cmd.Flags().Set("allow-replace", "true"))
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, mockPort.CapturedPortRequest)
+	assert.Equal(t, 1, mockPort.CapturedPortRequest.LocationId)
+	// The VXC is moved to the replacement before the old port is deleted.
+	require.NotNil(t, mockVXC.CapturedUpdateVXC)
+	require.NotNil(t, mockVXC.CapturedUpdateVXC.AEndProductUID)
+	assert.Equal(t, "port-uid-new", *mockVXC.CapturedUpdateVXC.AEndProductUID)
+	assert.Equal(t, []string{"port-uid-1"}, mockPort.DeletePortCalledWith)
+	assert.Contains(t, out, `Deleted replaced Port "Sydney-Port" (port-uid-1)`)
+
+	state, err := infra.LoadState(statePath)
+	require.NoError(t, err)
+	uid, _ := state.Lookup("port", "Sydney-Port")
+	assert.Equal(t, "port-uid-new", uid)
+}
+
+func TestApplyConfig_FailedReplacedDeleteIsRetried(t *testing.T) {
+	port := livePort()
+	port.LocationID = 2
+	mockPort := &infra.MockPortService{
+		GetPortResult: port,
+		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-uid-new"}},
+		DeletePortErr: errors.New("port has attached services"),
+	}
+	mockVXC := &infra.MockVXCService{GetVXCResult: liveVXC("port-uid-1")}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "infra.yaml", updateTestConfig)
+	statePath := writeStateFile(t, f,
+		infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"},
+		infra.StateResource{Type: "vxc", Name: "Port-to-Cloud", UID: "vxc-uid-1"},
+	)
+	cmd := applyCmd(f, false, true)
+	require.NoError(t, cmd.Flags().Set("allow-replace", "true"))
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, `Could not delete replaced Port "Sydney-Port" (port-uid-1)`)
+
+	state, err := infra.LoadState(statePath)
+	require.NoError(t, err)
+	uid, _ := state.Lookup("port", "Sydney-Port")
+	assert.Equal(t, "port-uid-new", uid)
+	require.Len(t, state.PendingDeletes, 1, "the old port stays in the state until it is deleted")
+	assert.Equal(t, "port-uid-1", state.PendingDeletes[0].UID)
+
+	// The next run finds everything up to date and finishes the delete.
+	mockPort.GetPortResult = livePort()
+	mockPort.DeletePortErr = nil
+	mockPort.DeletePortCalledWith = nil
+	mockVXC.GetVXCResult = liveVXC("port-uid-new")
+	out = output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"port-uid-1"}, mockPort.DeletePortCalledWith)
+	assert.Contains(t, out, `Deleted replaced Port "Sydney-Port" (port-uid-1)`)
+
+	state, err = infra.LoadState(statePath)
+	require.NoError(t, err)
+	assert.Empty(t, state.PendingDeletes)
+	uid, _ = state.Lookup("port", "Sydney-Port")
+	assert.Equal(t, "port-uid-new", uid)
+}
+
+func TestApplyConfig_RollbackRestoresReplacedUID(t *testing.T) {
+	port := livePort()
+	port.PortSpeed = 10000
+	cfg := `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+mcrs:
+  - name: Failing-MCR
+    location_id: 1
+    speed: 1000
+    term: 12
+`
+	mockPort := &infra.MockPortService{
+		GetPortResult: port,
+		BuyPortResult: &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-uid-new"}},
+	}
+	mockMCR := &infra.MockMCRService{BuyMCRErr: fmt.Errorf("MCR API down")}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	f := writeTempFile(t, "infra.yaml", cfg)
+	statePath := writeStateFile(t, f, infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"})
+	cmd := applyCmdWithRollback(f)
+	require.NoError(t, cmd.Flags().Set("allow-replace", "true"))
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.Error(t, err)
+	assert.Equal(t, []string{"port-uid-new"}, mockPort.DeletePortCalledWith, "only the replacement may be rolled back")
+
+	state, err := infra.LoadState(statePath)
+	require.NoError(t, err)
+	uid, _ := state.Lookup("port", "Sydney-Port")
+	assert.Equal(t, "port-uid-1", uid)
+}
+
+func TestApplyConfig_UnlocksBeforeUpdating(t *testing.T) {
+	port := livePort()
+	port.Name = "Renamed-In-Portal"
+	port.Locked = true
+	cfg := `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+    locked: false
+`
+	mockPort := &infra.MockPortService{GetPortResult: port}
+	mockProduct := &infra.MockProductService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	defer infra.SetupMockProductService(mockProduct)()
+
+	f := writeTempFile(t, "infra.yaml", cfg)
+	writeStateFile(t, f, infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"})
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*megaport.ManageProductLockRequest{{ProductID: "port-uid-1", ShouldLock: false}}, mockProduct.CapturedLockRequests)
+	require.NotNil(t, mockPort.CapturedModifyPort)
+	assert.Equal(t, "Sydney-Port", mockPort.CapturedModifyPort.Name)
+	assert.Contains(t, out, "updated (name, locke")
+}
+
+func TestApplyConfig_LockFailureStopsUpdate(t *testing.T) {
+	port := livePort()
+	port.Name = "Renamed-In-Portal"
+	port.Locked = true
+	cfg := `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+    locked: false
+`
+	mockPort := &infra.MockPortService{GetPortResult: port}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	defer infra.SetupMockProductService(&infra.MockProductService{ManageProductLockErr: fmt.Errorf("forbidden")})()
+
+	f := writeTempFile(t, "infra.yaml", cfg)
+	writeStateFile(t, f, infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"})
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.Error(t, err)
+	assert.Nil(t, mockPort.CapturedModifyPort, "a resource that could not be unlocked must not be modified")
+}
+
+func TestApplyConfig_LocksCreatedResourceAfterRun(t *testing.T) {
+	cfg := `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+    locked: true
+`
+	mockProduct := &infra.MockProductService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	defer infra.SetupMockProductService(mockProduct)()
+
+	f := writeTempFile(t, "infra.yaml", cfg)
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(applyCmd(f, false, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*megaport.ManageProductLockRequest{{ProductID: "port-uid-mock", ShouldLock: true}}, mockProduct.CapturedLockRequests)
+}
@@ -0,0 +1,126 @@
+package apply
+
+import (
+	"context"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// varsCmd builds a minimal cobra.Command with the flags ConfigVars reads.
+func varsCmd(varFiles []string, vars ...string) *cobra.Command {
+	cmd := &cobra.Command{Use: "apply"}
+	cmd.Flags().StringArray("var", nil, "")
+	cmd.Flags().StringArray("var-file", nil, "")
+	for _, f := range varFiles {
+		_ = cmd.Flags().Set("var-file", f)
+	}
+	for _, v := range vars {
+		_ = cmd.Flags().Set("var", v)
+	}
+	return cmd
+}
+
+const regionConfig = `
+variables:
+  location_id:
+  speed: 1000
+  region: syd
+  tags:
+    team: networking
+ports:
+  - name: "Port-{{.var.region}}"
+    location_id: "{{.var.location_id}}"
+    speed: "{{.var.speed}}"
+    term: 12
+    cost_centre: "${env:APPLY_TEST_COST_CENTRE}"
+    resource_tags: "{{.var.tags}}"
+vxcs:
+  - name: "VXC-{{.var.region}}"
+    rate_limit: 100
+    term: 12
+    a_end: {product_uid: "{{.port.Port-syd}}"}
+`
+
+func TestApplyConfig_VarFlags(t *testing.T) {
+	mockPort := &infra.MockPortService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	f := writeTempFile(t, "region.yaml", `
+variables:
+  location_id:
+ports:
+  - {name: Port, location_id: "{{.var.location_id}}", speed: 1000, term: 12}
+`)
+	cmd := applyCmd(f, false, true)
+	cmd.Flags().StringArray("var", nil, "")
+	cmd.Flags().StringArray("var-file", nil, "")
+	require.NoError(t, cmd.Flags().Set("var", "location_id=7"))
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+	require.NotNil(t, mockPort.CapturedPortRequest)
+	assert.Equal(t, 7, mockPort.CapturedPortRequest.LocationId)
+}
+
+func TestParseConfigFile_ProjectVariablesAndTags(t *testing.T) {
+	project := utils.ProjectSettings{
+		Variables:    map[string]interface{}{"location_id": 5, "speed": 10000, "undeclared": "ignored"},
+		ResourceTags: map[string]string{"team": "platform", "cost": "42"},
+	}
+
+	t.Setenv("APPLY_TEST_COST_CENTRE", "CC-42")
+	f := writeTempFile(t, "region.yaml", regionConfig)
+
+	vars, err := infra.ConfigVars(varsCmd(nil, "speed=100000"))
+	require.NoError(t, err)
+	cfg, err := infra.ParseConfigFile(f, vars, project)
+	require.NoError(t, err, "project variables the config does not declare are ignored")
+
+	require.Len(t, cfg.Ports, 1)
+	p := cfg.Ports[0]
+	assert.Equal(t, 5, p.LocationID, "a project variable sets a declared variable")
+	assert.Equal(t, 100000, p.Speed, "--var overrides a project variable")
+	assert.Equal(t, map[string]string{"team": "networking", "cost": "42"}, p.ResourceTags, "an entry's own tags win over the project's")
+	require.Len(t, cfg.VXCs, 1)
+	assert.Nil(t, cfg.VXCs[0].ResourceTags, "an entry without resource_tags stays unmanaged")
+	req, err := infra.VXCRequest(context.Background(), nil, cfg.VXCs[0], "port-uid", "mcr-uid", project.ResourceTags)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "platform", "cost": "42"}, req.ResourceTags, "the project's tags are set when it is ordered")
+}
+
+// TestApplyConfig_ProjectSettingsFromContext verifies that apply takes the
+// project config's variables and default tags from the command's context.
+func TestApplyConfig_ProjectSettingsFromContext(t *testing.T) {
+	mockPort := &infra.MockPortService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	f := writeTempFile(t, "infra.yaml", `
+variables:
+  location_id: 1
+ports:
+  - {name: Port, location_id: "{{.var.location_id}}", speed: 1000, term: 12}
+`)
+	cmd := applyCmd(f, false, true)
+	cmd.SetContext(utils.WithProjectSettings(context.Background(), utils.ProjectSettings{
+		Variables:    map[string]interface{}{"location_id": 7},
+		ResourceTags: map[string]string{"team": "platform"},
+	}))
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ApplyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+	require.NotNil(t, mockPort.CapturedPortRequest)
+	assert.Equal(t, 7, mockPort.CapturedPortRequest.LocationId)
+	assert.Equal(t, map[string]string{"team": "platform"}, mockPort.CapturedPortRequest.ResourceTags)
+}
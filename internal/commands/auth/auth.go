@@ -15,6 +15,7 @@ func AddCommandsTo(rootCmd *cobra.Command) {
 			"company, environment, and profile you are currently operating as.").
 		WithExample("megaport-cli auth status").
 		WithExample("megaport-cli auth status --output json").
+		WithExample("megaport-cli auth logout").
 		WithRootCmd(rootCmd).
 		Build()
 
@@ -27,11 +28,26 @@ func AddCommandsTo(rootCmd *cobra.Command) {
 			"Note: the displayed user is inferred from the company user list (preferring the " +
 			"primary admin). For companies with multiple admins, it may not reflect the exact " +
 			"user who owns the API credentials.\n\n" +
+			"Acting As shows the company commands act on behalf of: your own company, or the " +
+			"managed account selected with --managed-account or the profile's default managed account.\n\n" +
 			"Use this to confirm which account and environment you are operating against before " +
 			"making infrastructure changes.").
 		WithExample("megaport-cli auth status").
 		WithExample("megaport-cli auth status --output json").
 		WithExample("megaport-cli auth status --output json --query '[0].email'").
+		WithExample("megaport-cli auth status --managed-account \"Acme Corp\"").
+		WithRootCmd(rootCmd).
+		Build()
+
+	logoutCmd := cmdbuilder.NewCommand("logout", "Clear cached access tokens").
+		WithColorAwareRunFunc(AuthLogout).
+		WithLongDesc("Delete the access tokens cached in the config directory.\n\n" +
+			"Commands cache the access token they are issued and reuse it until shortly " +
+			"before it expires, so a script running many commands only exchanges its " +
+			"credentials once. Logging out removes every cached token; the next command " +
+			"requests a new one. Credential process output cached with " +
+			"--credential-process-cache is removed too. Profiles are not changed.").
+		WithExample("megaport-cli auth logout").
 		WithRootCmd(rootCmd).
 		Build()
 
@@ -46,7 +62,7 @@ func AddCommandsTo(rootCmd *cobra.Command) {
 		WithRootCmd(rootCmd).
 		Build()
 
-	authCmd.AddCommand(statusCmd)
+	authCmd.AddCommand(statusCmd, logoutCmd)
 	rootCmd.AddCommand(authCmd)
 	rootCmd.AddCommand(whoamiCmd)
 }
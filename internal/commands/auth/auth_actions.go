@@ -20,7 +20,9 @@ import (
 func AuthStatus(cmd *cobra.Command, _ []string, noColor bool, outputFormat string) error {
 	output.SetOutputFormat(outputFormat)
 
-	ctx, cancel, client, err := utils.LoginClient(cmd, 90*time.Second, config.Login)
+	// Log in as the user's own company so the identity below is the user's,
+	// even when a managed account is selected; ActingAs reports that account.
+	ctx, cancel, client, err := utils.LoginClient(cmd, 90*time.Second, config.LoginAsOwnCompany)
 	if err != nil {
 		output.PrintError("Authentication failed: %v", noColor, err)
 		return exitcodes.NewAuthError(err)
@@ -60,7 +62,31 @@ func AuthStatus(cmd *cobra.Command, _ []string, noColor bool, outputFormat strin
 		}
 	}
 
-	return printAuthStatus(currentUser, profileName, environment, apiEndpoint, companyName, outputFormat, noColor)
+	return printAuthStatus(currentUser, profileName, environment, apiEndpoint, companyName, config.ActingAs(), outputFormat, noColor)
+}
+
+// AuthLogout deletes every cached access token, and any cached credential
+// process output.
+func AuthLogout(_ *cobra.Command, _ []string, noColor bool) error {
+	n, err := clearCachedTokensFunc()
+	if err != nil {
+		output.PrintError("Failed to clear cached access tokens: %v", noColor, err)
+		return err
+	}
+	credentials, err := clearCachedCredentialsFunc()
+	if err != nil {
+		output.PrintError("Failed to clear cached credentials: %v", noColor, err)
+		return err
+	}
+	if n == 0 && credentials == 0 {
+		output.PrintInfo("No cached access tokens to clear", noColor)
+		return nil
+	}
+	output.PrintSuccess("Cleared %d cached access token(s)", noColor, n)
+	if credentials > 0 {
+		output.PrintSuccess("Cleared %d cached credential process result(s)", noColor, credentials)
+	}
+	return nil
 }
 
 // findCurrentUser attempts to identify the current user from the company user list.
@@ -16,13 +16,17 @@ type authStatusOutput struct {
 	Email         string `json:"email" header:"Email"`
 	Position      string `json:"position" header:"Position"`
 	CompanyName   string `json:"company_name" header:"Company"`
+	ActingAs      string `json:"acting_as" header:"Acting As"`
+	ActingAsUID   string `json:"acting_as_uid" header:"Acting As UID"`
 	Active        bool   `json:"active" header:"Active"`
 	Profile       string `json:"profile" header:"Profile"`
 	Environment   string `json:"environment" header:"Environment"`
 	APIEndpoint   string `json:"api_endpoint" header:"API Endpoint"`
 }
 
-func toAuthStatusOutput(user *megaport.User, profileName, environment, apiEndpoint, companyName string) authStatusOutput {
+// toAuthStatusOutput builds the auth status row. actingAs is the managed
+// account the session acts on behalf of, or nil for the user's own company.
+func toAuthStatusOutput(user *megaport.User, profileName, environment, apiEndpoint, companyName string, actingAs *megaport.ManagedAccount) authStatusOutput {
 	out := authStatusOutput{
 		Profile:     profileName,
 		Environment: capitalizeFirst(environment),
@@ -41,11 +45,17 @@ func toAuthStatusOutput(user *megaport.User, profileName, environment, apiEndpoi
 		}
 	}
 
+	out.ActingAs = out.CompanyName
+	if actingAs != nil {
+		out.ActingAs = actingAs.AccountName + " (managed account)"
+		out.ActingAsUID = actingAs.CompanyUID
+	}
+
 	return out
 }
 
-func printAuthStatus(user *megaport.User, profileName, environment, apiEndpoint, companyName, format string, noColor bool) error {
-	out := toAuthStatusOutput(user, profileName, environment, apiEndpoint, companyName)
+func printAuthStatus(user *megaport.User, profileName, environment, apiEndpoint, companyName string, actingAs *megaport.ManagedAccount, format string, noColor bool) error {
+	out := toAuthStatusOutput(user, profileName, environment, apiEndpoint, companyName, actingAs)
 	return output.PrintOutput([]authStatusOutput{out}, format, noColor)
 }
 
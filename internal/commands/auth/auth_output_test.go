@@ -21,7 +21,7 @@ func TestPrintAuthStatus_XML(t *testing.T) {
 	}
 
 	out := output.CaptureOutput(func() {
-		err := printAuthStatus(user, "profile", "production", "https://api.megaport.com/", "Co", "xml", true)
+		err := printAuthStatus(user, "profile", "production", "https://api.megaport.com/", "Co", nil, "xml", true)
 		assert.NoError(t, err)
 	})
 
@@ -38,7 +38,7 @@ func TestPrintAuthStatus_AllFormatsNoSecretLeak(t *testing.T) {
 	for _, format := range []string{"table", "json", "csv", "xml"} {
 		t.Run(format, func(t *testing.T) {
 			out := output.CaptureOutput(func() {
-				err := printAuthStatus(user, "profile", "production", "https://api.megaport.com/", "Co", format, true)
+				err := printAuthStatus(user, "profile", "production", "https://api.megaport.com/", "Co", nil, format, true)
 				assert.NoError(t, err)
 			})
 
@@ -52,10 +52,27 @@ func TestPrintAuthStatus_AllFormatsNoSecretLeak(t *testing.T) {
 
 func TestPrintAuthStatus_NilUser(t *testing.T) {
 	out := output.CaptureOutput(func() {
-		err := printAuthStatus(nil, "profile", "staging", "https://api-staging.megaport.com/", "Fallback Co", "csv", true)
+		err := printAuthStatus(nil, "profile", "staging", "https://api-staging.megaport.com/", "Fallback Co", nil, "csv", true)
 		assert.NoError(t, err)
 	})
 
 	assert.Contains(t, out, "Fallback Co")
 	assert.Contains(t, out, "Staging")
 }
+
+func TestPrintAuthStatus_ActingAs(t *testing.T) {
+	user := &megaport.User{FirstName: "Test", CompanyName: "Partner Co"}
+
+	out := toAuthStatusOutput(user, "profile", "production", "https://api.megaport.com/", "Partner Co", nil)
+	assert.Equal(t, "Partner Co", out.ActingAs, "without a managed account the session acts as the user's company")
+	assert.Empty(t, out.ActingAsUID)
+
+	account := &megaport.ManagedAccount{AccountName: "Acme Corp", CompanyUID: "acme-uid"}
+	captured := output.CaptureOutput(func() {
+		err := printAuthStatus(user, "profile", "production", "https://api.megaport.com/", "Partner Co", account, "json", true)
+		assert.NoError(t, err)
+	})
+	assert.Contains(t, captured, `"acting_as": "Acme Corp (managed account)"`)
+	assert.Contains(t, captured, `"acting_as_uid": "acme-uid"`)
+	assert.Contains(t, captured, `"company_name": "Partner Co"`)
+}
@@ -243,6 +243,43 @@ func TestAuthStatusCSVOutput(t *testing.T) {
 	assert.Contains(t, capturedOutput, "csv@example.com")
 }
 
+func TestAuthLogout(t *testing.T) {
+	orig, origCredentials := clearCachedTokensFunc, clearCachedCredentialsFunc
+	t.Cleanup(func() { clearCachedTokensFunc, clearCachedCredentialsFunc = orig, origCredentials })
+
+	tests := []struct {
+		name        string
+		cleared     int
+		clearErr    error
+		credentials int
+		wantErr     bool
+		wantOutput  string
+	}{
+		{name: "clears tokens", cleared: 2, wantOutput: "Cleared 2 cached access token(s)"},
+		{name: "nothing cached", wantOutput: "No cached access tokens to clear"},
+		{name: "clears credential process output", credentials: 1, wantOutput: "Cleared 1 cached credential process result(s)"},
+		{name: "clear fails", clearErr: fmt.Errorf("permission denied"), wantErr: true, wantOutput: "Failed to clear cached access tokens: permission denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearCachedTokensFunc = func() (int, error) { return tt.cleared, tt.clearErr }
+			clearCachedCredentialsFunc = func() (int, error) { return tt.credentials, nil }
+
+			var err error
+			captured := output.CaptureOutput(func() {
+				err = AuthLogout(testutil.NewCommand("logout", testutil.NoColorAdapter(AuthLogout)), nil, true)
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Contains(t, captured, tt.wantOutput)
+		})
+	}
+}
+
 func TestFindCurrentUser(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -487,13 +524,17 @@ func TestAddCommandsTo(t *testing.T) {
 		case "auth":
 			authFound = true
 			// Check status subcommand exists under auth
-			statusFound := false
+			statusFound, logoutFound := false, false
 			for _, sub := range cmd.Commands() {
-				if sub.Use == "status" {
+				switch sub.Use {
+				case "status":
 					statusFound = true
+				case "logout":
+					logoutFound = true
 				}
 			}
 			assert.True(t, statusFound, "auth should have a status subcommand")
+			assert.True(t, logoutFound, "auth should have a logout subcommand")
 		case "whoami":
 			whoamiFound = true
 		}
@@ -529,7 +570,7 @@ func TestToAuthStatusOutput(t *testing.T) {
 			Active:      true,
 			CompanyName: "Test Corp",
 		}
-		out := toAuthStatusOutput(user, "default", "production", "https://api.megaport.com/", "Fallback Corp")
+		out := toAuthStatusOutput(user, "default", "production", "https://api.megaport.com/", "Fallback Corp", nil)
 
 		assert.Equal(t, "John", out.FirstName)
 		assert.Equal(t, "Doe", out.LastName)
@@ -543,7 +584,7 @@ func TestToAuthStatusOutput(t *testing.T) {
 	})
 
 	t.Run("without user", func(t *testing.T) {
-		out := toAuthStatusOutput(nil, "my-profile", "staging", "https://api-staging.megaport.com/", "Company Inc")
+		out := toAuthStatusOutput(nil, "my-profile", "staging", "https://api-staging.megaport.com/", "Company Inc", nil)
 
 		assert.Equal(t, "", out.FirstName)
 		assert.Equal(t, "", out.Email)
@@ -558,7 +599,7 @@ func TestToAuthStatusOutput(t *testing.T) {
 			FirstName:   "Jane",
 			CompanyName: "",
 		}
-		out := toAuthStatusOutput(user, "p", "production", "https://api.megaport.com/", "Fallback Corp")
+		out := toAuthStatusOutput(user, "p", "production", "https://api.megaport.com/", "Fallback Corp", nil)
 
 		assert.Equal(t, "Fallback Corp", out.CompanyName)
 	})
@@ -574,7 +615,7 @@ func TestPrintAuthStatus(t *testing.T) {
 	}
 
 	capturedOutput := output.CaptureOutput(func() {
-		err := printAuthStatus(user, "profile", "production", "https://api.megaport.com/", "Co", "table", true)
+		err := printAuthStatus(user, "profile", "production", "https://api.megaport.com/", "Co", nil, "table", true)
 		assert.NoError(t, err)
 	})
 
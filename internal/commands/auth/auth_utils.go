@@ -5,9 +5,14 @@ package auth
 import (
 	"context"
 
+	"github.com/megaport/megaport-cli/internal/commands/config"
 	megaport "github.com/megaport/megaportgo"
 )
 
 var listCompanyUsersFunc = func(ctx context.Context, client *megaport.Client) ([]*megaport.User, error) {
 	return client.UserManagementService.ListCompanyUsers(ctx)
 }
+
+var clearCachedTokensFunc = config.ClearCachedTokens
+
+var clearCachedCredentialsFunc = config.ClearCachedCredentials
@@ -15,11 +15,19 @@ func AddCommandsTo(rootCmd *cobra.Command) {
 			"Profiles store your API credentials and environment settings " +
 			"for streamlined operations across multiple Megaport environments.\n\n" +
 			"Configuration is stored locally in ~/.megaport/config.json and persists across CLI sessions.\n\n" +
+			"Profile credentials can be kept out of config.json in a secret store:\n" +
+			"- keyring: the system keyring (macOS Keychain, or the Secret Service via secret-tool on Linux)\n" +
+			"- vault: an encrypted file, ~/.megaport/secrets.vault, unlocked with a passphrase\n" +
+			"- plaintext: config.json itself\n\n" +
 			"Configuration Precedence:\n" +
 			"1. Command-line flags (highest precedence)\n" +
-			"2. Environment variables (MEGAPORT_ACCESS_KEY, MEGAPORT_SECRET_KEY, etc.)\n" +
-			"3. Active profile in config file\n" +
-			"4. Default settings in config file (lowest precedence)").
+			"2. Project config: the nearest .megaport.yaml in the current directory or a parent\n" +
+			"3. Environment variables (MEGAPORT_ACCESS_KEY, MEGAPORT_SECRET_KEY, etc.)\n" +
+			"4. Active profile in config file\n" +
+			"5. Default settings in config file (lowest precedence)\n\n" +
+			"A project config can set profile, env, base_url and output, default resource_tags for new resources, " +
+			"and variables for apply configs. Use config view --show-origin to see where each setting came from. " +
+			"A project config's base_url is only used after config set-default project-base-url true.").
 		WithExample("megaport-cli config create-profile production --environment production").
 		WithExample("megaport-cli config use-profile production").
 		WithImportantNote("Configuration contains sensitive credentials - ensure ~/.megaport directory has appropriate permissions").
@@ -33,16 +41,33 @@ func AddCommandsTo(rootCmd *cobra.Command) {
 		WithLongDesc("Create a new profile with Megaport API credentials and environment settings.\n\n"+
 			"Profiles store your Megaport API access and secret keys along with environment settings for secure reuse. "+
 			"The profile name is case-sensitive and must be unique.\n\n"+
-			"Credentials are stored in ~/.megaport/config.json with secure file permissions.\n\n"+
+			"Credentials are kept in the secret store chosen with --secret-store. Without it, the secret-store "+
+			"default is used if one is set (see config set-default), otherwise the system keyring when one is available, "+
+			"otherwise config.json with secure file permissions.\n\n"+
+			"The vault is encrypted with AES-256-GCM under a key derived from your passphrase. "+
+			"You are prompted for the passphrase, or it is read from MEGAPORT_VAULT_PASSPHRASE.\n\n"+
 			"If --access-key or --secret-key are not provided, you will be prompted. "+
-			"On an interactive terminal input is masked; on piped/non-TTY stdin it is read without masking.").
+			"On an interactive terminal input is masked; on piped/non-TTY stdin it is read without masking.\n\n"+
+			"To keep keys off disk entirely, pass --credential-process with a command that prints them as JSON, "+
+			"for example {\"accessKey\": \"...\", \"secretKey\": \"...\"}. The command is run through the shell each time "+
+			"the profile logs in, and may also print an RFC 3339 \"expiration\" for the keys. "+
+			"With --credential-process-cache its output is reused for that long, cached in the system keyring, or in the vault when there is none.\n\n"+
+			"Partners can pass --default-managed-account with a managed account's company UID or name to make commands "+
+			"using the profile act on behalf of that account, unless --managed-account says otherwise.").
 		WithFlag("access-key", "", "Megaport API access key (omit to be prompted; masked on TTY only)").
 		WithFlag("secret-key", "", "Megaport API secret key (omit to be prompted; masked on TTY only)").
 		WithFlag("environment", "production", "Target API environment: 'production', 'staging', or 'development'").
 		WithFlag("description", "", "Optional description for this profile").
+		WithFlag("secret-store", "", "Where to keep the credentials: 'keyring', 'vault', or 'plaintext' (default: keyring if available)").
+		WithFlag("credential-process", "", "Command that prints the credentials as JSON, run at login instead of storing keys").
+		WithDurationFlag("credential-process-timeout", 0, "How long the credential process may run (default 30s)").
+		WithDurationFlag("credential-process-cache", 0, "How long to reuse the credential process output (default: not cached)").
+		WithFlag("default-managed-account", "", "Managed account (company UID or name) to act on behalf of by default").
 		WithExample("megaport-cli config create-profile production --environment production").
+		WithExample("megaport-cli config create-profile production --secret-store vault").
+		WithExample("megaport-cli config create-profile production --credential-process \"vault-read megaport/prod\" --credential-process-cache 15m").
 		WithExample("megaport-cli config create-profile staging --environment staging --description \"Staging credentials\"").
-		WithImportantNote("API credentials are stored with 0600 permissions (readable only by the current user)").
+		WithImportantNote("Credentials stored in plaintext or the vault are written with 0600 permissions (readable only by the current user)").
 		WithImportantNote("Passing --access-key or --secret-key on the command line exposes credentials in shell history and process listings. Omit them to be prompted securely, or use env vars MEGAPORT_ACCESS_KEY / MEGAPORT_SECRET_KEY instead. Note: the secure prompt masks input only on an interactive terminal; piped input is read without masking.").
 		WithRootCmd(rootCmd).
 		Build()
@@ -54,12 +79,24 @@ func AddCommandsTo(rootCmd *cobra.Command) {
 			"To avoid recording the secret value in shell history, pass an empty string "+
 			"(e.g. --secret-key \"\") and you will be prompted instead of providing the value on the command line. "+
 			"On an interactive terminal input is masked; on piped/non-TTY stdin it is read without masking. "+
-			"Alternatively, use env vars MEGAPORT_ACCESS_KEY / MEGAPORT_SECRET_KEY which always take precedence over stored profiles.").
+			"Alternatively, use env vars MEGAPORT_ACCESS_KEY / MEGAPORT_SECRET_KEY which always take precedence over stored profiles.\n\n"+
+			"Use --secret-store to move the profile's credentials to another secret store, for example out of config.json and into the vault.\n\n"+
+			"Use --credential-process to read the credentials from a command instead; any stored keys are removed. "+
+			"To go back to stored keys, pass --credential-process \"\" with --access-key and --secret-key.\n\n"+
+			"Use --default-managed-account to change the managed account the profile acts on behalf of by default.").
 		WithFlag("access-key", "", "New Megaport API access key (pass empty string to be prompted; masked on TTY only)").
 		WithFlag("secret-key", "", "New Megaport API secret key (pass empty string to be prompted; masked on TTY only)").
 		WithFlag("environment", "", "Target API environment: 'production', 'staging', or 'development'").
 		WithFlag("description", "", "Profile description (use empty string to clear)").
+		WithFlag("secret-store", "", "Move the credentials to another secret store: 'keyring', 'vault', or 'plaintext'").
+		WithFlag("credential-process", "", "Command that prints the credentials as JSON (empty string to remove)").
+		WithDurationFlag("credential-process-timeout", 0, "How long the credential process may run (0 for the default of 30s)").
+		WithDurationFlag("credential-process-cache", 0, "How long to reuse the credential process output (0 to stop caching)").
+		WithFlag("default-managed-account", "", "Managed account (company UID or name) to act on behalf of by default (empty string to clear)").
 		WithExample("megaport-cli config update-profile myprofile --environment staging").
+		WithExample("megaport-cli config update-profile myprofile --credential-process \"vault-read megaport/prod\"").
+		WithExample("megaport-cli config update-profile myprofile --secret-store keyring").
+		WithExample("megaport-cli config update-profile myprofile --default-managed-account \"Acme Corp\"").
 		WithExample("megaport-cli config update-profile myprofile --secret-key \"\"").
 		WithImportantNote("Keep your Megaport API credentials secure; they provide full account access").
 		WithImportantNote("Passing --access-key or --secret-key on the command line exposes credentials in shell history and process listings. Pass an empty value to be prompted instead (masked on a TTY; read without masking on piped/non-TTY stdin).").
@@ -95,6 +132,8 @@ func AddCommandsTo(rootCmd *cobra.Command) {
 		WithLongDesc("Set a default value in the configuration.").
 		WithExample("megaport-cli config set-default output json").
 		WithExample("megaport-cli config set-default no-color true").
+		WithExample("megaport-cli config set-default secret-store vault").
+		WithExample("megaport-cli config set-default project-base-url true").
 		WithRootCmd(rootCmd).
 		Build()
 
@@ -116,10 +155,14 @@ func AddCommandsTo(rootCmd *cobra.Command) {
 			"- Sharing configuration templates with teammates\n"+
 			"- Transferring settings between environments\n\n"+
 			"To use an exported file on another system, you must manually edit the file "+
-			"to replace [REDACTED] values with actual credentials before importing.").
+			"to replace [REDACTED] values with actual credentials before importing, or export "+
+			"with --include-secrets, which reads the credentials from their secret stores and writes them in plaintext.").
 		WithColorAwareRunFunc(ExportConfig).
 		WithFlag("file", "", "File to export to").
+		WithBoolFlag("include-secrets", false, "Include plaintext credentials instead of [REDACTED]").
 		WithExample("megaport-cli config export --file myconfig.json").
+		WithExample("megaport-cli config export --file backup.json --include-secrets").
+		WithImportantNote("An export made with --include-secrets contains plaintext credentials; store it securely and delete it when done").
 		WithRootCmd(rootCmd).
 		Build()
 
@@ -133,22 +176,31 @@ func AddCommandsTo(rootCmd *cobra.Command) {
 			"- Update existing profiles with the same name\n"+
 			"- Add or update default settings\n"+
 			"- Set the active profile if specified in the import file\n\n"+
+			"Imported credentials are kept in the secret store chosen with --secret-store, which defaults as for create-profile.\n\n"+
 			"Version compatibility: Import supports config file versions up to the current version.").
 		WithColorAwareRunFunc(ImportConfig).
 		WithFlag("file", "", "File to import from").
 		WithRequiredFlag("file", "File to import from").
+		WithFlag("secret-store", "", "Where to keep the imported credentials: 'keyring', 'vault', or 'plaintext' (default: keyring if available)").
 		WithExample("megaport-cli config import --file myconfig.json").
+		WithExample("megaport-cli config import --file myconfig.json --secret-store vault").
 		WithImportantNote("Credentials marked as [REDACTED] in export files must be replaced with actual values before import").
 		WithRootCmd(rootCmd).
 		Build()
 
 	viewCmd := cmdbuilder.NewCommand("view", "Display current configuration").
-		WithLongDesc("Display the current active configuration settings for the Megaport CLI.\n\n" +
-			"This command shows your active profile and default settings. " +
-			"Sensitive information like secret keys is partially masked for security. " +
-			"Use this command to verify your current working configuration before executing commands.").
+		WithLongDesc("Display the current active configuration settings for the Megaport CLI.\n\n"+
+			"This command shows your active profile and default settings. "+
+			"Sensitive information like secret keys is partially masked for security. "+
+			"Use this command to verify your current working configuration before executing commands.\n\n"+
+			"With --show-origin it also lists the effective global settings (profile, env, base-url, output and the "+
+			"output switches) and where each came from: a command-line flag, the project config (.megaport.yaml) "+
+			"found in the current directory or one of its parents, a saved default, the active profile, or the built-in default. "+
+			"The project config's default resource tags and apply variables are listed too.").
 		WithColorAwareRunFunc(ViewConfig).
+		WithBoolFlag("show-origin", false, "Show the effective global settings and where each came from").
 		WithExample("megaport-cli config view").
+		WithExample("megaport-cli config view --show-origin").
 		WithRootCmd(rootCmd).
 		Build()
 
@@ -38,6 +38,54 @@ func validateEnvironment(env string) error {
 	return nil
 }
 
+// resolveSecretStore returns the secret store to keep new credentials in:
+// the --secret-store flag, else the secret-store default, else the system
+// keyring when there is one, else plaintext. implicitPlaintext reports that
+// plaintext was chosen only because no keyring was found.
+func resolveSecretStore(cmd *cobra.Command, manager *ConfigManager) (store string, implicitPlaintext bool, err error) {
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	if store, _ = cmd.Flags().GetString("secret-store"); store != "" {
+		return store, false, validateSecretStore(store)
+	}
+	if value, ok := manager.GetDefault("secret-store"); ok {
+		if s, ok := value.(string); ok && validateSecretStore(s) == nil {
+			return s, false, nil
+		}
+	}
+	if keyringAvailable() {
+		return SecretStoreKeyring, false, nil
+	}
+	return SecretStorePlaintext, true, nil
+}
+
+// secretStoreLabel describes where a profile's credentials are kept.
+func secretStoreLabel(profile *Profile) string {
+	if profile.CredentialProcess != "" {
+		return "credential process"
+	}
+	if isExternalSecretStore(profile.SecretStore) {
+		return profile.SecretStore
+	}
+	return SecretStorePlaintext
+}
+
+// warnPlaintextSecrets warns that credentials are being stored unencrypted
+// because no keyring was found.
+func warnPlaintextSecrets(noColor bool) {
+	output.PrintWarning("No system keyring found; credentials are stored in plaintext in config.json. Use --secret-store vault to encrypt them.", noColor)
+}
+
+// credentialProcessDuration formats a credential process duration flag for
+// the config file; zero means the default and is left empty.
+func credentialProcessDuration(cmd *cobra.Command, flag string) string {
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	d, _ := cmd.Flags().GetDuration(flag)
+	if d <= 0 {
+		return ""
+	}
+	return d.String()
+}
+
 func CreateProfile(cmd *cobra.Command, args []string, noColor bool) error {
 	profileName := args[0]
 	// Flag read errors are intentionally ignored — flags are registered by the command builder.
@@ -45,6 +93,8 @@ func CreateProfile(cmd *cobra.Command, args []string, noColor bool) error {
 	secretKey, _ := cmd.Flags().GetString("secret-key")
 	environment, _ := cmd.Flags().GetString("environment")
 	description, _ := cmd.Flags().GetString("description")
+	managedAccount, _ := cmd.Flags().GetString("default-managed-account")
+	managedAccount = strings.TrimSpace(managedAccount)
 
 	if strings.TrimSpace(profileName) == "" {
 		return fmt.Errorf("profile name cannot be empty or whitespace")
@@ -67,6 +117,31 @@ func CreateProfile(cmd *cobra.Command, args []string, noColor bool) error {
 		return fmt.Errorf("profile '%s' already exists", profileName)
 	}
 
+	if credentialProcess, _ := cmd.Flags().GetString("credential-process"); strings.TrimSpace(credentialProcess) != "" {
+		if accessKey != "" || secretKey != "" || cmd.Flags().Changed("secret-store") {
+			return exitcodes.NewUsageError(fmt.Errorf("--credential-process cannot be combined with --access-key, --secret-key or --secret-store"))
+		}
+		if err := manager.CreateProfileInStore(profileName, "", "", environment, description, SecretStorePlaintext); err != nil {
+			return err
+		}
+		if err := manager.SetCredentialProcess(profileName, strings.TrimSpace(credentialProcess),
+			credentialProcessDuration(cmd, "credential-process-timeout"), credentialProcessDuration(cmd, "credential-process-cache")); err != nil {
+			return err
+		}
+		if managedAccount != "" {
+			if err := manager.SetManagedAccount(profileName, managedAccount); err != nil {
+				return err
+			}
+		}
+		output.PrintSuccess("Profile '%s' created successfully (credentials read from a credential process)", noColor, profileName)
+		return nil
+	}
+
+	secretStore, implicitPlaintext, err := resolveSecretStore(cmd, manager)
+	if err != nil {
+		return err
+	}
+
 	accessKey = strings.TrimSpace(accessKey)
 	if accessKey == "" {
 		accessKey, err = utils.SecretResourcePrompt("config", "Enter Megaport API access key: ", noColor)
@@ -90,11 +165,19 @@ func CreateProfile(cmd *cobra.Command, args []string, noColor bool) error {
 		secretKey = strings.TrimSpace(secretKey)
 	}
 
-	if err := manager.CreateProfile(profileName, accessKey, secretKey, environment, description); err != nil {
+	if err := manager.CreateProfileInStore(profileName, accessKey, secretKey, environment, description, secretStore); err != nil {
 		return err
 	}
+	if managedAccount != "" {
+		if err := manager.SetManagedAccount(profileName, managedAccount); err != nil {
+			return err
+		}
+	}
 
-	output.PrintSuccess("Profile '%s' created successfully", noColor, profileName)
+	if implicitPlaintext {
+		warnPlaintextSecrets(noColor)
+	}
+	output.PrintSuccess("Profile '%s' created successfully (credentials stored in %s)", noColor, profileName, secretStore)
 	return nil
 }
 
@@ -110,7 +193,8 @@ func UpdateProfile(cmd *cobra.Command, args []string, noColor bool) error {
 	if err != nil {
 		return err
 	}
-	if _, exists := profiles[profileName]; !exists {
+	profile, exists := profiles[profileName]
+	if !exists {
 		return fmt.Errorf("profile '%s' not found", profileName)
 	}
 
@@ -118,6 +202,34 @@ func UpdateProfile(cmd *cobra.Command, args []string, noColor bool) error {
 	secretKeyChanged := cmd.Flags().Changed("secret-key")
 	environmentChanged := cmd.Flags().Changed("environment")
 	descriptionChanged := cmd.Flags().Changed("description")
+	secretStoreChanged := cmd.Flags().Changed("secret-store")
+	credentialProcessChanged := cmd.Flags().Changed("credential-process")
+	processSettingsChanged := cmd.Flags().Changed("credential-process-timeout") || cmd.Flags().Changed("credential-process-cache")
+
+	credentialProcess := profile.CredentialProcess
+	removingProcess := false
+	if credentialProcessChanged {
+		credentialProcess, _ = cmd.Flags().GetString("credential-process")
+		credentialProcess = strings.TrimSpace(credentialProcess)
+		if credentialProcess != "" && (accessKeyChanged || secretKeyChanged || secretStoreChanged) {
+			return exitcodes.NewUsageError(fmt.Errorf("--credential-process cannot be combined with --access-key, --secret-key or --secret-store"))
+		}
+		removingProcess = credentialProcess == "" && profile.CredentialProcess != ""
+		if removingProcess && (!accessKeyChanged || !secretKeyChanged) {
+			return exitcodes.NewUsageError(fmt.Errorf("removing the credential process requires new keys; pass --access-key and --secret-key"))
+		}
+	}
+	if processSettingsChanged && credentialProcess == "" {
+		return exitcodes.NewUsageError(fmt.Errorf("profile '%s' has no credential process; set one with --credential-process", profileName))
+	}
+
+	secretStore := ""
+	if secretStoreChanged {
+		secretStore, _ = cmd.Flags().GetString("secret-store")
+		if err := validateSecretStore(secretStore); err != nil {
+			return err
+		}
+	}
 
 	environment := ""
 	if environmentChanged {
@@ -164,9 +276,45 @@ func UpdateProfile(cmd *cobra.Command, args []string, noColor bool) error {
 		description, _ = cmd.Flags().GetString("description")
 	}
 
+	if credentialProcessChanged || processSettingsChanged {
+		timeout, cacheFor := profile.CredentialProcessTimeout, profile.CredentialProcessCache
+		if cmd.Flags().Changed("credential-process-timeout") {
+			timeout = credentialProcessDuration(cmd, "credential-process-timeout")
+		}
+		if cmd.Flags().Changed("credential-process-cache") {
+			cacheFor = credentialProcessDuration(cmd, "credential-process-cache")
+		}
+		if err := manager.SetCredentialProcess(profileName, credentialProcess, timeout, cacheFor); err != nil {
+			return err
+		}
+	}
 	if err := manager.UpdateProfile(profileName, accessKey, secretKey, environment, descriptionChanged, description); err != nil {
 		return err
 	}
+	if cmd.Flags().Changed("default-managed-account") {
+		managedAccount, _ := cmd.Flags().GetString("default-managed-account")
+		if err := manager.SetManagedAccount(profileName, strings.TrimSpace(managedAccount)); err != nil {
+			return err
+		}
+	}
+	if removingProcess && !secretStoreChanged {
+		// Keys replacing a credential process go where new keys would.
+		var implicitPlaintext bool
+		secretStore, implicitPlaintext, err = resolveSecretStore(cmd, manager)
+		if err != nil {
+			return err
+		}
+		secretStoreChanged = secretStore != SecretStorePlaintext
+		if implicitPlaintext {
+			warnPlaintextSecrets(noColor)
+		}
+	}
+	if secretStoreChanged {
+		if err := manager.MoveProfileSecrets(profileName, secretStore); err != nil {
+			return fmt.Errorf("failed to move credentials to %s: %w", secretStore, err)
+		}
+		output.PrintInfo("Credentials for profile '%s' are now stored in %s", noColor, profileName, secretStore)
+	}
 
 	output.PrintSuccess("Profile '%s' updated successfully", noColor, profileName)
 	return nil
@@ -208,6 +356,7 @@ type profileOutput struct {
 	AccessKey     string `json:"access_key" header:"Access Key"`
 	Environment   string `json:"environment" header:"Environment"`
 	Description   string `json:"description" header:"Description"`
+	SecretStore   string `json:"secret_store" header:"Secret Store"`
 	IsActive      bool   `json:"is_active" header:"Active"`
 }
 
@@ -239,6 +388,7 @@ func ListProfiles(cmd *cobra.Command, args []string, noColor bool, outputFormat
 			AccessKey:   maskAccessKey(profile.AccessKey),
 			Environment: profile.Environment,
 			Description: profile.Description,
+			SecretStore: secretStoreLabel(profile),
 			IsActive:    name == activeProfile,
 		})
 	}
@@ -327,6 +477,21 @@ func SetDefault(cmd *cobra.Command, args []string, noColor bool) error {
 			}
 			return nil, fmt.Errorf("no-pager must be true or false")
 		},
+		"project-base-url": func(v string) (interface{}, error) {
+			if strings.ToLower(v) == "true" {
+				return true, nil
+			} else if strings.ToLower(v) == "false" {
+				return false, nil
+			}
+			return nil, fmt.Errorf("project-base-url must be true or false")
+		},
+		"secret-store": func(v string) (interface{}, error) {
+			v = strings.ToLower(v)
+			if err := validateSecretStore(v); err != nil {
+				return nil, err
+			}
+			return v, nil
+		},
 	}
 
 	validator, exists := allowedSettings[key]
@@ -376,10 +541,19 @@ func ExportConfig(cmd *cobra.Command, args []string, noColor bool) error {
 		return err
 	}
 
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	includeSecrets, _ := cmd.Flags().GetBool("include-secrets")
+
 	exportConfig, err := manager.Export()
+	if includeSecrets {
+		exportConfig, err = manager.ExportWithSecrets()
+	}
 	if err != nil {
 		return err
 	}
+	if includeSecrets {
+		output.PrintWarning("The export contains plaintext credentials; store it securely and delete it when done", noColor)
+	}
 
 	data, err := json.MarshalIndent(exportConfig, "", "  ")
 	if err != nil {
@@ -430,12 +604,28 @@ func ImportConfig(cmd *cobra.Command, args []string, noColor bool) error {
 		if err := validateEnvironment(profile.Environment); err != nil {
 			return fmt.Errorf("profile '%s' has an invalid environment: %w", profileName, err)
 		}
+		if profile.CredentialProcess != "" {
+			if _, err := parseCredentialProcessDuration("credential process timeout", profile.CredentialProcessTimeout); err != nil {
+				return fmt.Errorf("profile '%s' has an %w", profileName, err)
+			}
+			if _, err := parseCredentialProcessDuration("credential process cache duration", profile.CredentialProcessCache); err != nil {
+				return fmt.Errorf("profile '%s' has an %w", profileName, err)
+			}
+			continue
+		}
 		if profile.AccessKey == "" || profile.SecretKey == "" ||
 			profile.AccessKey == "[REDACTED]" || profile.SecretKey == "[REDACTED]" {
 			return fmt.Errorf("profile '%s' has missing or redacted credentials - cannot import", profileName)
 		}
 	}
 
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	if store, _ := cmd.Flags().GetString("secret-store"); store != "" {
+		if err := validateSecretStore(store); err != nil {
+			return err
+		}
+	}
+
 	// Ask for confirmation BEFORE making any changes
 	confirmed := utils.ConfirmPrompt("This will overwrite any existing profiles with the same names. Continue? (y/n): ", noColor)
 	if !confirmed {
@@ -448,15 +638,34 @@ func ImportConfig(cmd *cobra.Command, args []string, noColor bool) error {
 		return fmt.Errorf("failed to create config manager: %w", err)
 	}
 
+	secretStore, implicitPlaintext, err := resolveSecretStore(cmd, manager)
+	if err != nil {
+		return err
+	}
+	if implicitPlaintext && len(importConfig.Profiles) > 0 {
+		warnPlaintextSecrets(noColor)
+	}
+
 	// Now actually import the profiles
 	for name, profile := range importConfig.Profiles {
-		err = manager.CreateProfile(
-			name,
-			profile.AccessKey,
-			profile.SecretKey,
-			profile.Environment,
-			profile.Description,
-		)
+		if profile.CredentialProcess != "" {
+			err = manager.CreateProfileInStore(name, "", "", profile.Environment, profile.Description, SecretStorePlaintext)
+			if err == nil {
+				err = manager.SetCredentialProcess(name, profile.CredentialProcess, profile.CredentialProcessTimeout, profile.CredentialProcessCache)
+			}
+		} else {
+			err = manager.CreateProfileInStore(
+				name,
+				profile.AccessKey,
+				profile.SecretKey,
+				profile.Environment,
+				profile.Description,
+				secretStore,
+			)
+		}
+		if err == nil && profile.ManagedAccount != "" {
+			err = manager.SetManagedAccount(name, profile.ManagedAccount)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to import profile '%s': %w", name, err)
 		}
@@ -497,8 +706,19 @@ func ViewConfig(cmd *cobra.Command, args []string, noColor bool) error {
 	} else {
 		fmt.Fprintf(cmd.OutOrStdout(), "Current Configuration:\n\n")
 		fmt.Fprintf(cmd.OutOrStdout(), "  Active Profile: %s\n", profileName)
-		fmt.Fprintf(cmd.OutOrStdout(), "  Access Key: %s\n", maskAccessKey(activeProfile.AccessKey))
+		if activeProfile.CredentialProcess != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "  Access Key: (from credential process)\n")
+			fmt.Fprintf(cmd.OutOrStdout(), "  Credential Process: %s\n", activeProfile.CredentialProcess)
+		} else if isExternalSecretStore(activeProfile.SecretStore) {
+			fmt.Fprintf(cmd.OutOrStdout(), "  Access Key: (stored in %s)\n", activeProfile.SecretStore)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "  Access Key: %s\n", maskAccessKey(activeProfile.AccessKey))
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "  Secret Store: %s\n", secretStoreLabel(activeProfile))
 		fmt.Fprintf(cmd.OutOrStdout(), "  Environment: %s\n", activeProfile.Environment)
+		if activeProfile.ManagedAccount != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "  Managed Account: %s\n", activeProfile.ManagedAccount)
+		}
 
 		if activeProfile.Description != "" {
 			fmt.Fprintf(cmd.OutOrStdout(), "  Description: %s\n", activeProfile.Description)
@@ -515,6 +735,11 @@ func ViewConfig(cmd *cobra.Command, args []string, noColor bool) error {
 		}
 	}
 
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	if showOrigin, _ := cmd.Flags().GetBool("show-origin"); showOrigin {
+		printSettingOrigins(cmd, manager)
+	}
+
 	return nil
 }
 
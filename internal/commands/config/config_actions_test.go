@@ -4,6 +4,7 @@ package config
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"os"
 	"path/filepath"
@@ -1299,3 +1300,164 @@ func TestUpdateProfile_EmptyAccessKeyFlagTriggersPrompt(t *testing.T) {
 	assert.Equal(t, "new-access-from-prompt", profiles["update-test"].AccessKey)
 	assert.Equal(t, "old-secret", profiles["update-test"].SecretKey)
 }
+
+func newCreateProfileCmd(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd, _ := setupTestCmd()
+	cmd.Flags().String("access-key", "", "")
+	cmd.Flags().String("secret-key", "", "")
+	cmd.Flags().String("environment", "production", "")
+	cmd.Flags().String("description", "", "")
+	cmd.Flags().String("secret-store", "", "")
+	require.NoError(t, cmd.ParseFlags(append([]string{"--access-key=my-access", "--secret-key=my-secret"}, args...)))
+	return cmd
+}
+
+func TestCreateProfile_SecretStore(t *testing.T) {
+	t.Run("keyring when available", func(t *testing.T) {
+		setupTestConfigEnv(t)
+		keyring := useMemoryKeyring(t)
+		keyringAvailable = func() bool { return true }
+		t.Cleanup(func() { keyringAvailable = func() bool { return false } })
+
+		outputText, err := captureBothFromAction(t, func() error {
+			return CreateProfile(newCreateProfileCmd(t), []string{"prod"}, true)
+		})
+		require.NoError(t, err)
+		assert.Contains(t, outputText, "Profile 'prod' created successfully (credentials stored in keyring)")
+		assert.Equal(t, [2]string{"my-access", "my-secret"}, keyring.entries["prod"])
+
+		manager, err := NewConfigManager()
+		require.NoError(t, err)
+		profile, err := manager.GetProfile("prod")
+		require.NoError(t, err)
+		assert.Empty(t, profile.SecretKey)
+		assert.Equal(t, SecretStoreKeyring, profile.SecretStore)
+	})
+
+	t.Run("plaintext without a keyring warns", func(t *testing.T) {
+		setupTestConfigEnv(t)
+
+		outputText, err := captureBothFromAction(t, func() error {
+			return CreateProfile(newCreateProfileCmd(t), []string{"prod"}, true)
+		})
+		require.NoError(t, err)
+		assert.Contains(t, outputText, "No system keyring found")
+		assert.Contains(t, outputText, "(credentials stored in plaintext)")
+	})
+
+	t.Run("vault from the secret-store default", func(t *testing.T) {
+		configDir := setupTestConfigEnv(t)
+		t.Setenv(vaultPassphraseEnv, "correct horse")
+		_, err := captureOutputFromAction(func() error {
+			return SetDefault(newCreateProfileCmd(t), []string{"secret-store", "vault"}, true)
+		})
+		require.NoError(t, err)
+
+		outputText, err := captureBothFromAction(t, func() error {
+			return CreateProfile(newCreateProfileCmd(t), []string{"prod"}, true)
+		})
+		require.NoError(t, err)
+		assert.Contains(t, outputText, "(credentials stored in vault)")
+		assert.NotContains(t, outputText, "No system keyring found")
+		assert.FileExists(t, filepath.Join(configDir, vaultFileName))
+	})
+
+	t.Run("invalid secret store", func(t *testing.T) {
+		setupTestConfigEnv(t)
+
+		_, err := captureBothFromAction(t, func() error {
+			return CreateProfile(newCreateProfileCmd(t, "--secret-store=file"), []string{"prod"}, true)
+		})
+		assert.EqualError(t, err, `secret store must be one of: keyring, vault, plaintext (got "file")`)
+	})
+}
+
+func TestUpdateProfile_MoveSecretStore(t *testing.T) {
+	setupTestConfigEnv(t)
+	keyring := useMemoryKeyring(t)
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfile("prod", "old-access", "old-secret", "production", ""))
+
+	cmd, _ := setupTestCmd()
+	cmd.Flags().String("access-key", "", "")
+	cmd.Flags().String("secret-key", "", "")
+	cmd.Flags().String("environment", "", "")
+	cmd.Flags().String("description", "", "")
+	cmd.Flags().String("secret-store", "", "")
+	require.NoError(t, cmd.ParseFlags([]string{"--secret-key=new-secret", "--secret-store=keyring"}))
+
+	outputText, err := captureBothFromAction(t, func() error {
+		return UpdateProfile(cmd, []string{"prod"}, true)
+	})
+	require.NoError(t, err)
+	assert.Contains(t, outputText, "Credentials for profile 'prod' are now stored in keyring")
+	assert.Equal(t, [2]string{"old-access", "new-secret"}, keyring.entries["prod"])
+
+	manager, err = NewConfigManager()
+	require.NoError(t, err)
+	profile, err := manager.GetProfile("prod")
+	require.NoError(t, err)
+	assert.Empty(t, profile.AccessKey)
+	assert.Empty(t, profile.SecretKey)
+}
+
+func TestExportConfig_IncludeSecrets(t *testing.T) {
+	setupTestConfigEnv(t)
+	useMemoryKeyring(t)
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfileInStore("prod", "my-access", "my-secret", "production", "", SecretStoreKeyring))
+
+	cmd, _ := setupTestCmd()
+	cmd.Flags().String("file", "", "")
+	cmd.Flags().Bool("include-secrets", false, "")
+
+	stdout, err := captureOutputFromAction(func() error {
+		return ExportConfig(cmd, nil, true)
+	})
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "[REDACTED]", "secrets are redacted unless asked for")
+	assert.NotContains(t, stdout, "my-secret")
+
+	require.NoError(t, cmd.ParseFlags([]string{"--include-secrets"}))
+	var stderr string
+	stderr = captureStderr(t, func() {
+		stdout, err = captureOutputFromAction(func() error {
+			return ExportConfig(cmd, nil, true)
+		})
+	})
+	require.NoError(t, err)
+	assert.Contains(t, stdout+stderr, "contains plaintext credentials")
+	var exported ConfigFile
+	require.NoError(t, json.Unmarshal([]byte(stdout[strings.Index(stdout, "{"):]), &exported))
+	assert.Equal(t, "my-secret", exported.Profiles["prod"].SecretKey)
+	assert.Empty(t, exported.Profiles["prod"].SecretStore)
+}
+
+func TestListProfiles_ShowsSecretStore(t *testing.T) {
+	setupTestConfigEnv(t)
+	useMemoryKeyring(t)
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfileInStore("prod", "my-access-key", "my-secret", "production", "", SecretStoreKeyring))
+	require.NoError(t, manager.CreateProfile("dev", "dev-access-key", "dev-secret", "development", ""))
+
+	cmd, _ := setupTestCmd()
+	stdout, err := captureOutputFromAction(func() error {
+		return ListProfiles(cmd, nil, true, "json")
+	})
+	require.NoError(t, err)
+
+	var profiles []profileOutput
+	require.NoError(t, json.Unmarshal([]byte(stdout), &profiles))
+	require.Len(t, profiles, 2)
+	assert.Equal(t, SecretStorePlaintext, profiles[0].SecretStore)
+	assert.Equal(t, "dev-...-key", profiles[0].AccessKey)
+	assert.Equal(t, SecretStoreKeyring, profiles[1].SecretStore)
+	assert.Empty(t, profiles[1].AccessKey)
+}
@@ -20,6 +20,22 @@ type Profile struct {
 	SecretKey   string `json:"secretKey"`
 	Environment string `json:"environment"`
 	Description string `json:"description,omitempty"`
+	// SecretStore names where the keys are kept when they are not in the
+	// config file itself; empty or "plaintext" means AccessKey and SecretKey.
+	SecretStore string `json:"secretStore,omitempty"`
+	// CredentialProcess is a command that prints the profile's keys as JSON.
+	// When it is set no keys are stored; the command is run at login instead.
+	CredentialProcess string `json:"credentialProcess,omitempty"`
+	// CredentialProcessTimeout bounds how long CredentialProcess may run, as
+	// a Go duration. Empty means the default of 30 seconds.
+	CredentialProcessTimeout string `json:"credentialProcessTimeout,omitempty"`
+	// CredentialProcessCache is how long the keys CredentialProcess prints
+	// are reused before it is run again, as a Go duration. Empty means they
+	// are never cached.
+	CredentialProcessCache string `json:"credentialProcessCache,omitempty"`
+	// ManagedAccount is the managed account (company UID or name) the
+	// profile acts on behalf of unless --managed-account says otherwise.
+	ManagedAccount string `json:"managedAccount,omitempty"`
 }
 
 // ConfigVersion is the current version of the config file format
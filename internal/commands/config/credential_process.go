@@ -0,0 +1,337 @@
+//go:build !js && !wasm
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+)
+
+const (
+	// defaultCredentialProcessTimeout bounds a credential process whose
+	// profile sets no timeout.
+	defaultCredentialProcessTimeout = 30 * time.Second
+	// credentialCacheFileName is the credential process cache's file name in
+	// the config directory.
+	credentialCacheFileName = "credential-process-cache.json"
+	// credentialCacheVersion is the current version of the cache format.
+	// Version 1 held the keys themselves and is discarded.
+	credentialCacheVersion = 2
+	// credentialCacheEntryPrefix starts the secret store entry names cached
+	// keys are kept under. Profile names may not start with it.
+	credentialCacheEntryPrefix = "credential-process-cache/"
+)
+
+// credentialProcessOutput is what a credential process prints on stdout.
+// Expiration, if set, is when the keys stop being valid.
+type credentialProcessOutput struct {
+	AccessKey  string     `json:"accessKey"`
+	SecretKey  string     `json:"secretKey"`
+	Expiration *time.Time `json:"expiration,omitempty"`
+}
+
+// credentialCacheFile is the on-disk credential process cache, keyed by
+// profile name. It holds no keys: those are kept in a secret store.
+type credentialCacheFile struct {
+	Version int                         `json:"version"`
+	Entries map[string]cachedCredential `json:"entries"`
+}
+
+// cachedCredential describes the cached output of a credential process.
+// Store names the secret store its keys are kept in. CommandHash ties it to
+// the command that printed it, so editing the command is never answered from
+// the cache.
+type cachedCredential struct {
+	Store       string    `json:"store"`
+	Expiry      time.Time `json:"expiry"`
+	CommandHash string    `json:"commandHash"`
+}
+
+// runCredentialProcessCommand runs command through the platform shell and
+// returns its stdout. The command's stderr is written to stderr. It is a
+// variable so tests can fake credential processes.
+var runCredentialProcessCommand = func(ctx context.Context, command string, stderr io.Writer) ([]byte, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	}
+	var stdout bytes.Buffer
+	// The process shares the terminal's stdin and stderr so it can prompt,
+	// for example for a password or an MFA code.
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+	// Stop waiting for output shortly after the process is killed, even if
+	// something it started still holds stdout open.
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+	return stdout.Bytes(), err
+}
+
+// parseCredentialProcessDuration parses one of a profile's credential
+// process durations. An empty value is zero.
+func parseCredentialProcessDuration(field, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", field, value, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid %s %q: must not be negative", field, value)
+	}
+	return d, nil
+}
+
+// credentialProcessCredentials returns the keys the named profile's
+// credential process prints, from the cache when the profile allows it.
+func (m *ConfigManager) credentialProcessCredentials(name string, profile *Profile) (string, string, error) {
+	timeout, err := parseCredentialProcessDuration("credential process timeout", profile.CredentialProcessTimeout)
+	if err != nil {
+		return "", "", fmt.Errorf("profile %q has an %w", name, err)
+	}
+	if timeout == 0 {
+		timeout = defaultCredentialProcessTimeout
+	}
+	cacheFor, err := parseCredentialProcessDuration("credential process cache duration", profile.CredentialProcessCache)
+	if err != nil {
+		return "", "", fmt.Errorf("profile %q has an %w", name, err)
+	}
+
+	now := time.Now()
+	if cacheFor > 0 {
+		if accessKey, secretKey, ok := m.cachedCredentials(name, profile.CredentialProcess, now); ok {
+			return accessKey, secretKey, nil
+		}
+	}
+
+	result, err := runCredentialProcess(name, profile.CredentialProcess, timeout)
+	if err != nil {
+		return "", "", err
+	}
+	if result.Expiration != nil && !now.Before(*result.Expiration) {
+		return "", "", fmt.Errorf("credential process for profile %q returned credentials that expired at %s", name, result.Expiration.Format(time.RFC3339))
+	}
+
+	if cacheFor > 0 {
+		expiry := now.Add(cacheFor)
+		if result.Expiration != nil && result.Expiration.Before(expiry) {
+			expiry = *result.Expiration
+		}
+		if err := m.cacheCredentials(name, profile.CredentialProcess, result.AccessKey, result.SecretKey, expiry, now); err != nil {
+			output.PrintWarning("Could not cache credentials for profile %q: %v", output.GetOutputConfig().NoColor, name, err)
+		}
+	}
+	return result.AccessKey, result.SecretKey, nil
+}
+
+// runCredentialProcess runs a credential process and parses what it prints.
+// Errors carry the process's stderr, so its own explanation of a failure
+// reaches the user.
+func runCredentialProcess(name, command string, timeout time.Duration) (*credentialProcessOutput, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	stdout, err := runCredentialProcessCommand(ctx, command, io.MultiWriter(os.Stderr, &stderr))
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("credential process for profile %q timed out after %s", name, timeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("credential process for profile %q failed: %w: %s", name, err, msg)
+		}
+		return nil, fmt.Errorf("credential process for profile %q failed: %w", name, err)
+	}
+
+	var result credentialProcessOutput
+	if err := json.Unmarshal(bytes.TrimSpace(stdout), &result); err != nil {
+		return nil, fmt.Errorf("credential process for profile %q printed invalid JSON: %w", name, err)
+	}
+	result.AccessKey = strings.TrimSpace(result.AccessKey)
+	result.SecretKey = strings.TrimSpace(result.SecretKey)
+	if result.AccessKey == "" || result.SecretKey == "" {
+		return nil, fmt.Errorf("credential process for profile %q did not print both accessKey and secretKey", name)
+	}
+	return &result, nil
+}
+
+func commandHash(command string) string {
+	sum := sha256.Sum256([]byte(command))
+	return hex.EncodeToString(sum[:])
+}
+
+func credentialCachePath() (string, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, credentialCacheFileName), nil
+}
+
+// credentialCacheEntry is the secret store entry the keys cached for profile
+// are kept under.
+func credentialCacheEntry(profile string) string {
+	return credentialCacheEntryPrefix + profile
+}
+
+// readCredentialCache reads the credential process cache. A missing or
+// unreadable cache is empty.
+func readCredentialCache(path string) credentialCacheFile {
+	cache := credentialCacheFile{Version: credentialCacheVersion, Entries: map[string]cachedCredential{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	var file credentialCacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != credentialCacheVersion || file.Entries == nil {
+		return cache
+	}
+	return file
+}
+
+// writeCredentialCache saves the credential process cache, replacing a
+// cache of an earlier version along with any keys it held.
+func writeCredentialCache(path string, cache credentialCacheFile) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credential cache: %w", err)
+	}
+	if err := writePrivateFile(path, data); err != nil {
+		return fmt.Errorf("failed to write credential cache: %w", err)
+	}
+	return nil
+}
+
+// credentialCacheStore returns the secret store new cache entries are kept
+// in: the system keyring, or the vault where there is none.
+func (m *ConfigManager) credentialCacheStore() (string, SecretStore, error) {
+	if store, err := m.secretStore(SecretStoreKeyring); err == nil {
+		return SecretStoreKeyring, store, nil
+	}
+	store, err := m.secretStore(SecretStoreVault)
+	if err != nil {
+		return "", nil, err
+	}
+	return SecretStoreVault, store, nil
+}
+
+// cachedCredentials returns the keys cached for profile if they were printed
+// by command and have not expired.
+func (m *ConfigManager) cachedCredentials(profile, command string, now time.Time) (string, string, bool) {
+	path, err := credentialCachePath()
+	if err != nil {
+		return "", "", false
+	}
+	entry, ok := readCredentialCache(path).Entries[profile]
+	if !ok || entry.CommandHash != commandHash(command) || !now.Before(entry.Expiry) {
+		return "", "", false
+	}
+	store, err := m.secretStore(entry.Store)
+	if err != nil {
+		return "", "", false
+	}
+	accessKey, secretKey, err := store.Get(credentialCacheEntry(profile))
+	if err != nil {
+		return "", "", false
+	}
+	return accessKey, secretKey, true
+}
+
+// cacheCredentials saves the keys command printed for profile in a secret
+// store, dropping any cached keys that have expired.
+func (m *ConfigManager) cacheCredentials(profile, command, accessKey, secretKey string, expiry, now time.Time) error {
+	path, err := credentialCachePath()
+	if err != nil {
+		return err
+	}
+	storeName, store, err := m.credentialCacheStore()
+	if err != nil {
+		return err
+	}
+	cache := readCredentialCache(path)
+	for k, e := range cache.Entries {
+		if k != profile && !now.Before(e.Expiry) && m.deleteCachedKeys(k, e) == nil {
+			delete(cache.Entries, k)
+		}
+	}
+	if old, ok := cache.Entries[profile]; ok && old.Store != storeName {
+		if err := m.deleteCachedKeys(profile, old); err != nil {
+			return err
+		}
+		delete(cache.Entries, profile)
+	}
+	if err := store.Set(credentialCacheEntry(profile), accessKey, secretKey); err != nil {
+		return err
+	}
+	cache.Entries[profile] = cachedCredential{
+		Store:       storeName,
+		Expiry:      expiry,
+		CommandHash: commandHash(command),
+	}
+	return writeCredentialCache(path, cache)
+}
+
+// deleteCachedKeys removes the keys of a cache entry from its secret store.
+func (m *ConfigManager) deleteCachedKeys(profile string, entry cachedCredential) error {
+	store, err := m.secretStore(entry.Store)
+	if err != nil {
+		return err
+	}
+	return store.Delete(credentialCacheEntry(profile))
+}
+
+// forgetCachedCredentials drops any keys cached for profile.
+func (m *ConfigManager) forgetCachedCredentials(profile string) error {
+	path, err := credentialCachePath()
+	if err != nil {
+		return err
+	}
+	cache := readCredentialCache(path)
+	entry, ok := cache.Entries[profile]
+	if !ok {
+		return nil
+	}
+	if err := m.deleteCachedKeys(profile, entry); err != nil {
+		return err
+	}
+	delete(cache.Entries, profile)
+	return writeCredentialCache(path, cache)
+}
+
+// ClearCachedCredentials deletes every cached credential process result and
+// returns how many there were.
+func ClearCachedCredentials() (int, error) {
+	path, err := credentialCachePath()
+	if err != nil {
+		return 0, err
+	}
+	cache := readCredentialCache(path)
+	m := &ConfigManager{}
+	for profile, entry := range cache.Entries {
+		if err := m.deleteCachedKeys(profile, entry); err != nil {
+			return 0, fmt.Errorf("failed to clear cached credentials for profile %q: %w", profile, err)
+		}
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("failed to clear credential cache: %w", err)
+	}
+	return len(cache.Entries), nil
+}
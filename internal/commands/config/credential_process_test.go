@@ -0,0 +1,402 @@
+//go:build !js && !wasm
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCredentialProcess replaces the credential process runner for the test
+// and returns a pointer to the number of times it ran.
+func fakeCredentialProcess(t *testing.T, run func(ctx context.Context, command string, stderr io.Writer) ([]byte, error)) *int {
+	t.Helper()
+	calls := 0
+	orig := runCredentialProcessCommand
+	runCredentialProcessCommand = func(ctx context.Context, command string, stderr io.Writer) ([]byte, error) {
+		calls++
+		return run(ctx, command, stderr)
+	}
+	t.Cleanup(func() { runCredentialProcessCommand = orig })
+	return &calls
+}
+
+func printCredentials(accessKey, secretKey string) func(context.Context, string, io.Writer) ([]byte, error) {
+	return func(context.Context, string, io.Writer) ([]byte, error) {
+		return []byte(fmt.Sprintf(`{"accessKey": %q, "secretKey": %q}`, accessKey, secretKey)), nil
+	}
+}
+
+func TestCredentialProcess_Credentials(t *testing.T) {
+	setupTestConfigEnv(t)
+	var gotCommand string
+	fakeCredentialProcess(t, func(_ context.Context, command string, _ io.Writer) ([]byte, error) {
+		gotCommand = command
+		return []byte("{\"accessKey\": \"access\", \"secretKey\": \"secret\"}\n"), nil
+	})
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfile("prod", "old-access", "old-secret", "production", ""))
+	require.NoError(t, manager.SetCredentialProcess("prod", "vault-read megaport/prod", "", ""))
+
+	profile, err := manager.GetProfile("prod")
+	require.NoError(t, err)
+	assert.Empty(t, profile.AccessKey, "stored keys are removed")
+	assert.Empty(t, profile.SecretKey)
+
+	accessKey, secretKey, err := manager.Credentials("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "access", accessKey)
+	assert.Equal(t, "secret", secretKey)
+	assert.Equal(t, "vault-read megaport/prod", gotCommand)
+
+	err = manager.UpdateProfile("prod", "new-access", "", "", false, "")
+	assert.ErrorContains(t, err, "reads its credentials from a credential process")
+	err = manager.MoveProfileSecrets("prod", SecretStoreVault)
+	assert.ErrorContains(t, err, "reads its credentials from a credential process")
+}
+
+func TestCredentialProcess_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     func(ctx context.Context, command string, stderr io.Writer) ([]byte, error)
+		timeout string
+		wantErr string
+	}{
+		{
+			name: "exit status with stderr",
+			run: func(_ context.Context, _ string, stderr io.Writer) ([]byte, error) {
+				_, _ = io.WriteString(stderr, "permission denied: megaport/prod\n")
+				return nil, errors.New("exit status 2")
+			},
+			wantErr: `credential process for profile "prod" failed: exit status 2: permission denied: megaport/prod`,
+		},
+		{
+			name: "invalid JSON",
+			run: func(context.Context, string, io.Writer) ([]byte, error) {
+				return []byte("access secret"), nil
+			},
+			wantErr: `credential process for profile "prod" printed invalid JSON`,
+		},
+		{
+			name:    "missing secret key",
+			run:     printCredentials("access", ""),
+			wantErr: `credential process for profile "prod" did not print both accessKey and secretKey`,
+		},
+		{
+			name: "expired credentials",
+			run: func(context.Context, string, io.Writer) ([]byte, error) {
+				return []byte(`{"accessKey": "a", "secretKey": "s", "expiration": "2020-01-01T00:00:00Z"}`), nil
+			},
+			wantErr: `credential process for profile "prod" returned credentials that expired at 2020-01-01T00:00:00Z`,
+		},
+		{
+			name: "timeout",
+			run: func(ctx context.Context, _ string, _ io.Writer) ([]byte, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			timeout: "10ms",
+			wantErr: `credential process for profile "prod" timed out after 10ms`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestConfigEnv(t)
+			fakeCredentialProcess(t, tt.run)
+
+			manager, err := NewConfigManager()
+			require.NoError(t, err)
+			require.NoError(t, manager.CreateProfile("prod", "", "", "production", ""))
+			require.NoError(t, manager.SetCredentialProcess("prod", "vault-read megaport/prod", tt.timeout, ""))
+
+			captureStderr(t, func() {
+				_, _, err = manager.Credentials("prod")
+			})
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCredentialProcess_Cache(t *testing.T) {
+	configDir := setupTestConfigEnv(t)
+	keyring := useMemoryKeyring(t)
+	calls := fakeCredentialProcess(t, printCredentials("access", "secret"))
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfile("prod", "", "", "production", ""))
+	require.NoError(t, manager.SetCredentialProcess("prod", "vault-read megaport/prod", "", "15m"))
+
+	for i := 0; i < 2; i++ {
+		accessKey, _, err := manager.Credentials("prod")
+		require.NoError(t, err)
+		assert.Equal(t, "access", accessKey)
+	}
+	assert.Equal(t, 1, *calls, "the second login is answered from the cache")
+
+	assert.Equal(t, [2]string{"access", "secret"}, keyring.entries[credentialCacheEntry("prod")])
+	data, err := os.ReadFile(filepath.Join(configDir, credentialCacheFileName))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret", "the keys are kept in the secret store, not the cache file")
+
+	require.NoError(t, manager.SetCredentialProcess("prod", "vault-read megaport/other", "", "15m"))
+	_, _, err = manager.Credentials("prod")
+	require.NoError(t, err)
+	assert.Equal(t, 2, *calls, "changing the command skips the cache")
+
+	n, err := ClearCachedCredentials()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Empty(t, keyring.entries)
+	assert.ErrorContains(t, manager.CreateProfile(credentialCacheEntry("prod"), "a", "s", "production", ""), "profile name cannot start with")
+	_, _, err = manager.Credentials("prod")
+	require.NoError(t, err)
+	assert.Equal(t, 3, *calls)
+}
+
+func TestCredentialProcess_CacheHonoursExpiration(t *testing.T) {
+	setupTestConfigEnv(t)
+	useMemoryKeyring(t)
+	now := time.Now()
+	expiration := now.Add(time.Minute).UTC().Format(time.RFC3339)
+	fakeCredentialProcess(t, func(context.Context, string, io.Writer) ([]byte, error) {
+		return []byte(`{"accessKey": "a", "secretKey": "s", "expiration": "` + expiration + `"}`), nil
+	})
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfile("prod", "", "", "production", ""))
+	require.NoError(t, manager.SetCredentialProcess("prod", "vault-read", "", "1h"))
+	_, _, err = manager.Credentials("prod")
+	require.NoError(t, err)
+
+	_, _, ok := manager.cachedCredentials("prod", "vault-read", now.Add(30*time.Second))
+	assert.True(t, ok)
+	_, _, ok = manager.cachedCredentials("prod", "vault-read", now.Add(2*time.Minute))
+	assert.False(t, ok, "cached keys expire with the credentials, not the cache duration")
+}
+
+func TestCredentialProcess_CacheFallsBackToVault(t *testing.T) {
+	configDir := setupTestConfigEnv(t)
+	t.Setenv(vaultPassphraseEnv, "correct horse battery staple")
+	calls := fakeCredentialProcess(t, printCredentials("access", "secret"))
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfile("prod", "", "", "production", ""))
+	require.NoError(t, manager.SetCredentialProcess("prod", "vault-read", "", "15m"))
+	_, _, err = manager.Credentials("prod")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(configDir, vaultFileName))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+
+	manager, err = NewConfigManager()
+	require.NoError(t, err)
+	accessKey, _, err := manager.Credentials("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "access", accessKey)
+	assert.Equal(t, 1, *calls, "the keys are read back from the vault")
+
+	require.NoError(t, manager.DeleteProfile("prod"))
+	store := newVaultStore(filepath.Join(configDir, vaultFileName))
+	_, _, err = store.Get(credentialCacheEntry("prod"))
+	assert.ErrorIs(t, err, errSecretNotFound, "deleting the profile removes its cached keys")
+}
+
+func TestCredentialProcess_DiscardsPlaintextCache(t *testing.T) {
+	configDir := setupTestConfigEnv(t)
+	useMemoryKeyring(t)
+	calls := fakeCredentialProcess(t, printCredentials("access", "secret"))
+	path := filepath.Join(configDir, credentialCacheFileName)
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 1, "entries": {"prod": {"accessKey": "old-access", "secretKey": "old-secret", "expiry": "2999-01-01T00:00:00Z", "commandHash": "`+commandHash("vault-read")+`"}}}`), 0600))
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfile("prod", "", "", "production", ""))
+	require.NoError(t, manager.SetCredentialProcess("prod", "vault-read", "", "15m"))
+	accessKey, _, err := manager.Credentials("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "access", accessKey)
+	assert.Equal(t, 1, *calls, "keys cached in plaintext are not used")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "old-secret")
+}
+
+func TestCredentialProcess_Shell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exercises /bin/sh")
+	}
+	setupTestConfigEnv(t)
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfile("prod", "", "", "production", ""))
+	require.NoError(t, manager.SetCredentialProcess("prod", `echo '{"accessKey": "access", "secretKey": "secret"}'`, "", ""))
+	accessKey, secretKey, err := manager.Credentials("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "access", accessKey)
+	assert.Equal(t, "secret", secretKey)
+
+	require.NoError(t, manager.SetCredentialProcess("prod", "echo 'vault is sealed' >&2; exit 3", "", ""))
+	captureStderr(t, func() {
+		_, _, err = manager.Credentials("prod")
+	})
+	assert.EqualError(t, err, `credential process for profile "prod" failed: exit status 3: vault is sealed`)
+}
+
+func TestSetCredentialProcess_InvalidDuration(t *testing.T) {
+	setupTestConfigEnv(t)
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfile("prod", "access", "secret", "production", ""))
+
+	err = manager.SetCredentialProcess("prod", "vault-read", "soon", "")
+	assert.ErrorContains(t, err, `invalid credential process timeout "soon"`)
+}
+
+func TestExport_CredentialProcess(t *testing.T) {
+	setupTestConfigEnv(t)
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfile("prod", "", "", "production", ""))
+	require.NoError(t, manager.SetCredentialProcess("prod", "vault-read", "10s", "5m"))
+
+	exported, err := manager.ExportWithSecrets()
+	require.NoError(t, err)
+	profile := exported.Profiles["prod"]
+	assert.Empty(t, profile.AccessKey, "the credential process is not run on export")
+	assert.Equal(t, "vault-read", profile.CredentialProcess)
+	assert.Equal(t, "10s", profile.CredentialProcessTimeout)
+	assert.Equal(t, "5m", profile.CredentialProcessCache)
+}
+
+func newCredentialProcessCmd(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd, _ := setupTestCmd()
+	cmd.Flags().String("access-key", "", "")
+	cmd.Flags().String("secret-key", "", "")
+	cmd.Flags().String("environment", "production", "")
+	cmd.Flags().String("description", "", "")
+	cmd.Flags().String("secret-store", "", "")
+	cmd.Flags().String("credential-process", "", "")
+	cmd.Flags().Duration("credential-process-timeout", 0, "")
+	cmd.Flags().Duration("credential-process-cache", 0, "")
+	require.NoError(t, cmd.ParseFlags(args))
+	return cmd
+}
+
+func TestCreateProfile_CredentialProcess(t *testing.T) {
+	setupTestConfigEnv(t)
+
+	outputText, err := captureBothFromAction(t, func() error {
+		return CreateProfile(newCredentialProcessCmd(t, "--credential-process=vault-read megaport/prod", "--credential-process-cache=15m"), []string{"prod"}, true)
+	})
+	require.NoError(t, err)
+	assert.Contains(t, outputText, "Profile 'prod' created successfully (credentials read from a credential process)")
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	profile, err := manager.GetProfile("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "vault-read megaport/prod", profile.CredentialProcess)
+	assert.Equal(t, "15m0s", profile.CredentialProcessCache)
+	assert.Empty(t, profile.CredentialProcessTimeout)
+
+	_, err = captureBothFromAction(t, func() error {
+		return CreateProfile(newCredentialProcessCmd(t, "--credential-process=vault-read", "--access-key=a"), []string{"other"}, true)
+	})
+	assert.EqualError(t, err, "--credential-process cannot be combined with --access-key, --secret-key or --secret-store")
+}
+
+func TestUpdateProfile_CredentialProcess(t *testing.T) {
+	setupTestConfigEnv(t)
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfile("prod", "access", "secret", "production", ""))
+
+	_, err = captureBothFromAction(t, func() error {
+		return UpdateProfile(newCredentialProcessCmd(t, "--credential-process-timeout=1m"), []string{"prod"}, true)
+	})
+	assert.EqualError(t, err, "profile 'prod' has no credential process; set one with --credential-process")
+
+	_, err = captureBothFromAction(t, func() error {
+		return UpdateProfile(newCredentialProcessCmd(t, "--credential-process=vault-read"), []string{"prod"}, true)
+	})
+	require.NoError(t, err)
+	manager, err = NewConfigManager()
+	require.NoError(t, err)
+	profile, err := manager.GetProfile("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "vault-read", profile.CredentialProcess)
+	assert.Empty(t, profile.SecretKey)
+
+	_, err = captureBothFromAction(t, func() error {
+		return UpdateProfile(newCredentialProcessCmd(t, "--credential-process="), []string{"prod"}, true)
+	})
+	assert.EqualError(t, err, "removing the credential process requires new keys; pass --access-key and --secret-key")
+
+	_, err = captureBothFromAction(t, func() error {
+		return UpdateProfile(newCredentialProcessCmd(t, "--credential-process=", "--access-key=new-access", "--secret-key=new-secret"), []string{"prod"}, true)
+	})
+	require.NoError(t, err)
+	manager, err = NewConfigManager()
+	require.NoError(t, err)
+	profile, err = manager.GetProfile("prod")
+	require.NoError(t, err)
+	assert.Empty(t, profile.CredentialProcess)
+	assert.Equal(t, "new-secret", profile.SecretKey)
+}
+
+func TestImportConfig_CredentialProcess(t *testing.T) {
+	configDir := setupTestConfigEnv(t)
+	data, err := json.Marshal(ConfigFile{
+		Version: ConfigVersion,
+		Profiles: map[string]*Profile{
+			"prod": {Environment: "production", CredentialProcess: "vault-read", CredentialProcessCache: "5m"},
+		},
+	})
+	require.NoError(t, err)
+	importPath := filepath.Join(configDir, "import.json")
+	require.NoError(t, os.WriteFile(importPath, data, 0600))
+
+	cmd, _ := setupTestCmd()
+	cmd.Flags().String("file", "", "")
+	cmd.Flags().String("secret-store", "", "")
+	require.NoError(t, cmd.ParseFlags([]string{"--file=" + importPath}))
+
+	oldConfirmPrompt := utils.GetConfirmPrompt()
+	utils.SetConfirmPrompt(func(_ string, _ bool) bool { return true })
+	defer func() { utils.SetConfirmPrompt(oldConfirmPrompt) }()
+
+	_, err = captureBothFromAction(t, func() error {
+		return ImportConfig(cmd, nil, true)
+	})
+	require.NoError(t, err)
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	profile, err := manager.GetProfile("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "vault-read", profile.CredentialProcess)
+	assert.Equal(t, "5m", profile.CredentialProcessCache)
+}
@@ -130,9 +130,33 @@ var loginFunc = func(ctx context.Context) (*megaport.Client, error) {
 	return GetLoginFuncWithOutput()(ctx, "")
 }
 
+// managedAccountSetting returns the managed account to act on behalf of:
+// the --managed-account flag, else the default of the profile the
+// credentials came from.
+func managedAccountSetting(profileName string) string {
+	if utils.ManagedAccount != "" {
+		return managedAccountFlag()
+	}
+	if profileName == "" {
+		return ""
+	}
+	manager, err := NewConfigManager()
+	if err != nil {
+		return ""
+	}
+	profile, err := manager.GetProfile(profileName)
+	if err != nil {
+		return ""
+	}
+	return profile.ManagedAccount
+}
+
 // loginFuncWithOutput logs into the Megaport API using the current profile or environment variables.
 var loginFuncWithOutput = func(ctx context.Context, outputFormat string) (*megaport.Client, error) {
 	var accessKey, secretKey string
+	// profileName is the profile the credentials came from, if any; it keys
+	// the token cache.
+	var profileName string
 
 	env, err := resolveEnvironment(false)
 	if err != nil {
@@ -145,12 +169,14 @@ var loginFuncWithOutput = func(ctx context.Context, outputFormat string) (*megap
 		if err != nil {
 			return nil, fmt.Errorf("failed to load config: %w", err)
 		}
-		profile, err := manager.GetProfile(utils.ProfileOverride)
-		if err != nil {
+		if _, err := manager.GetProfile(utils.ProfileOverride); err != nil {
 			return nil, fmt.Errorf("profile %q not found. Use 'megaport config list-profiles' to see available profiles", utils.ProfileOverride)
 		}
-		accessKey = profile.AccessKey
-		secretKey = profile.SecretKey
+		accessKey, secretKey, err = manager.Credentials(utils.ProfileOverride)
+		if err != nil {
+			return nil, err
+		}
+		profileName = utils.ProfileOverride
 	} else {
 		// Credential selection: if --env flag is used, prefer env vars over profile
 		if utils.Env != "" {
@@ -162,13 +188,20 @@ var loginFuncWithOutput = func(ctx context.Context, outputFormat string) (*megap
 			if accessKey == "" || secretKey == "" {
 				manager, err := NewConfigManager()
 				if err == nil {
-					profile, _, err := manager.GetCurrentProfile()
+					_, name, err := manager.GetCurrentProfile()
 					if err == nil {
+						profileAccessKey, profileSecretKey, err := manager.Credentials(name)
+						if err != nil {
+							return nil, err
+						}
+						if accessKey == "" || secretKey == "" {
+							profileName = name
+						}
 						if accessKey == "" {
-							accessKey = profile.AccessKey
+							accessKey = profileAccessKey
 						}
 						if secretKey == "" {
-							secretKey = profile.SecretKey
+							secretKey = profileSecretKey
 						}
 					}
 				}
@@ -177,10 +210,13 @@ var loginFuncWithOutput = func(ctx context.Context, outputFormat string) (*megap
 			// No --env flag, use original priority: profile > env vars
 			manager, err := NewConfigManager()
 			if err == nil {
-				profile, _, err := manager.GetCurrentProfile()
+				_, name, err := manager.GetCurrentProfile()
 				if err == nil {
-					accessKey = profile.AccessKey
-					secretKey = profile.SecretKey
+					accessKey, secretKey, err = manager.Credentials(name)
+					if err != nil {
+						return nil, err
+					}
+					profileName = name
 				}
 			}
 
@@ -222,8 +258,16 @@ var loginFuncWithOutput = func(ctx context.Context, outputFormat string) (*megap
 		return nil, err
 	}
 
+	// Reuse a cached token when there is one, so scripts running many
+	// commands do not exchange credentials for a new token every time.
+	cacheKey := tokenCacheKey(profileName, env, utils.BaseURL, utils.TokenURL)
+	reusedToken, reusedExpiry, reused := cachedAccessToken(cacheKey, accessKey, secretKey, time.Now())
+	if reused {
+		megaportClient.SetAccessToken(reusedToken, reusedExpiry)
+	}
+
 	spinner := output.PrintLoggingInWithOutput(false, outputFormat)
-	_, err = megaportClient.Authorize(ctx)
+	authInfo, err := megaportClient.Authorize(ctx)
 
 	if err != nil {
 		spinner.Stop()
@@ -238,6 +282,16 @@ var loginFuncWithOutput = func(ctx context.Context, outputFormat string) (*megap
 		spinner.StopWithSuccess(fmt.Sprintf("Successfully logged in to Megaport %s", target))
 	}
 
+	if authInfo != nil && authInfo.AccessToken != "" && authInfo.AccessToken != reusedToken {
+		if err := cacheAccessToken(cacheKey, accessKey, secretKey, authInfo.AccessToken, authInfo.Expiration, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not cache access token: %v\n", err)
+		}
+	}
+
+	if err := actAsManagedAccount(ctx, megaportClient, managedAccountSetting(profileName)); err != nil {
+		return nil, err
+	}
+
 	return megaportClient, nil
 }
 
@@ -156,6 +156,9 @@ var loginFunc = func(ctx context.Context) (*megaport.Client, error) {
 
 			js.Global().Get("console").Call("log", "✅ Client created with external token - no OAuth needed!")
 			js.Global().Get("console").Call("groupEnd")
+			if err := actAsManagedAccount(ctx, megaportClient, managedAccountFlag()); err != nil {
+				return nil, err
+			}
 			return megaportClient, nil
 		}
 	}
@@ -290,6 +293,9 @@ var loginFunc = func(ctx context.Context) (*megaport.Client, error) {
 		spinner.StopWithSuccess("Successfully logged in to Megaport")
 	}
 
+	if err := actAsManagedAccount(ctx, megaportClient, managedAccountFlag()); err != nil {
+		return nil, err
+	}
 	return megaportClient, nil
 }
 
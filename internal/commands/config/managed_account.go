@@ -0,0 +1,105 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/megaport/megaport-cli/internal/utils"
+	megaport "github.com/megaport/megaportgo"
+)
+
+// NoManagedAccount is the --managed-account value that acts as the
+// credentials' own company, overriding a profile's default managed account.
+const NoManagedAccount = "none"
+
+// listManagedAccountsFunc lists the partner's managed accounts. It is a
+// variable so tests can fake the API.
+var listManagedAccountsFunc = func(ctx context.Context, client *megaport.Client) ([]*megaport.ManagedAccount, error) {
+	return client.ManagedAccountService.ListManagedAccounts(ctx)
+}
+
+// actingAs is the managed account the last login acted on behalf of, or nil.
+var actingAs *megaport.ManagedAccount
+
+// ActingAs returns the managed account selected for the current command, or
+// nil when it acts as the credentials' own company. It is set by login.
+func ActingAs() *megaport.ManagedAccount {
+	return actingAs
+}
+
+type ownCompanyContextKey struct{}
+
+// LoginAsOwnCompany logs in like Login, but the client acts as the
+// credentials' own company even when a managed account is selected. Partner
+// commands such as managed-account use it; ActingAs still reports the
+// selected account.
+func LoginAsOwnCompany(ctx context.Context) (*megaport.Client, error) {
+	return Login(context.WithValue(ctx, ownCompanyContextKey{}, true))
+}
+
+func actsAsOwnCompany(ctx context.Context) bool {
+	own, _ := ctx.Value(ownCompanyContextKey{}).(bool)
+	return own
+}
+
+// managedAccountFlag returns the --managed-account value, with
+// NoManagedAccount as empty.
+func managedAccountFlag() string {
+	if strings.EqualFold(utils.ManagedAccount, NoManagedAccount) {
+		return ""
+	}
+	return strings.TrimSpace(utils.ManagedAccount)
+}
+
+// actAsManagedAccount makes client act on behalf of the managed account
+// named by account, a company UID or account name. An empty account acts as
+// the credentials' own company.
+func actAsManagedAccount(ctx context.Context, client *megaport.Client, account string) error {
+	actingAs = nil
+	if account == "" {
+		return nil
+	}
+	match, err := findManagedAccount(ctx, client, account)
+	if err != nil {
+		return err
+	}
+	actingAs = match
+	if actsAsOwnCompany(ctx) {
+		return nil
+	}
+	return megaport.WithCallContext(match.CompanyUID)(client)
+}
+
+// findManagedAccount finds the managed account whose company UID, or else
+// whose account name (ignoring case), is account.
+func findManagedAccount(ctx context.Context, client *megaport.Client, account string) (*megaport.ManagedAccount, error) {
+	accounts, err := listManagedAccountsFunc(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up managed account %q: %w", account, err)
+	}
+	var byName []*megaport.ManagedAccount
+	for _, a := range accounts {
+		if a == nil {
+			continue
+		}
+		if a.CompanyUID == account {
+			return a, nil
+		}
+		if strings.EqualFold(a.AccountName, account) {
+			byName = append(byName, a)
+		}
+	}
+	switch len(byName) {
+	case 0:
+		return nil, fmt.Errorf("managed account %q not found. Use 'megaport-cli managed-account list' to see your managed accounts", account)
+	case 1:
+		return byName[0], nil
+	default:
+		uids := make([]string, len(byName))
+		for i, a := range byName {
+			uids[i] = a.CompanyUID
+		}
+		return nil, fmt.Errorf("managed account name %q is ambiguous; use one of these company UIDs: %s", account, strings.Join(uids, ", "))
+	}
+}
@@ -0,0 +1,225 @@
+//go:build !js && !wasm
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/utils"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeManagedAccounts(t *testing.T, accounts []*megaport.ManagedAccount, err error) {
+	t.Helper()
+	orig := listManagedAccountsFunc
+	listManagedAccountsFunc = func(context.Context, *megaport.Client) ([]*megaport.ManagedAccount, error) {
+		return accounts, err
+	}
+	t.Cleanup(func() {
+		listManagedAccountsFunc = orig
+		actingAs = nil
+	})
+}
+
+func TestFindManagedAccount(t *testing.T) {
+	fakeManagedAccounts(t, []*megaport.ManagedAccount{
+		nil,
+		{AccountName: "Acme Corp", CompanyUID: "acme-uid"},
+		{AccountName: "Globex", CompanyUID: "globex-1"},
+		{AccountName: "globex", CompanyUID: "globex-2"},
+	}, nil)
+
+	tests := []struct {
+		name    string
+		account string
+		wantUID string
+		wantErr string
+	}{
+		{name: "company UID", account: "acme-uid", wantUID: "acme-uid"},
+		{name: "name ignoring case", account: "ACME CORP", wantUID: "acme-uid"},
+		{name: "UID wins over ambiguous name", account: "globex-2", wantUID: "globex-2"},
+		{name: "ambiguous name", account: "Globex", wantErr: `managed account name "Globex" is ambiguous; use one of these company UIDs: globex-1, globex-2`},
+		{name: "not found", account: "Initech", wantErr: `managed account "Initech" not found. Use 'megaport-cli managed-account list' to see your managed accounts`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account, err := findManagedAccount(context.Background(), nil, tt.account)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUID, account.CompanyUID)
+		})
+	}
+}
+
+func TestFindManagedAccount_ListError(t *testing.T) {
+	fakeManagedAccounts(t, nil, errors.New("forbidden"))
+
+	_, err := findManagedAccount(context.Background(), nil, "acme-uid")
+	assert.EqualError(t, err, `failed to look up managed account "acme-uid": forbidden`)
+}
+
+func TestActAsManagedAccount_SendsCallContext(t *testing.T) {
+	fakeManagedAccounts(t, []*megaport.ManagedAccount{{AccountName: "Acme Corp", CompanyUID: "acme-uid"}}, nil)
+
+	var mu sync.Mutex
+	var callContexts []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		callContexts = append(callContexts, r.Header.Get("X-Call-Context"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"data":[]}`)
+	}))
+	defer ts.Close()
+
+	newClient := func() *megaport.Client {
+		client, err := megaport.New(nil, megaport.WithBaseURL(ts.URL))
+		require.NoError(t, err)
+		return client
+	}
+
+	client := newClient()
+	require.NoError(t, actAsManagedAccount(context.Background(), client, "Acme Corp"))
+	require.NotNil(t, ActingAs())
+	assert.Equal(t, "acme-uid", ActingAs().CompanyUID)
+	_, err := client.PortService.ListPorts(context.Background())
+	require.NoError(t, err)
+
+	ownCtx := context.WithValue(context.Background(), ownCompanyContextKey{}, true)
+	client = newClient()
+	require.NoError(t, actAsManagedAccount(ownCtx, client, "acme-uid"))
+	assert.Equal(t, "acme-uid", ActingAs().CompanyUID, "ActingAs still reports the selected account")
+	_, err = client.PortService.ListPorts(context.Background())
+	require.NoError(t, err)
+
+	client = newClient()
+	require.NoError(t, actAsManagedAccount(context.Background(), client, ""))
+	assert.Nil(t, ActingAs())
+
+	assert.Equal(t, []string{"acme-uid", ""}, callContexts)
+}
+
+func TestManagedAccountSetting(t *testing.T) {
+	setupTestConfigEnv(t)
+	orig := utils.ManagedAccount
+	t.Cleanup(func() { utils.ManagedAccount = orig })
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfile("partner", "key", "secret", "production", ""))
+	require.NoError(t, manager.SetManagedAccount("partner", "Acme Corp"))
+
+	utils.ManagedAccount = ""
+	assert.Equal(t, "Acme Corp", managedAccountSetting("partner"), "the profile default applies without the flag")
+	assert.Equal(t, "", managedAccountSetting(""), "credentials from env vars have no default")
+
+	utils.ManagedAccount = "globex-uid"
+	assert.Equal(t, "globex-uid", managedAccountSetting("partner"), "the flag overrides the profile default")
+
+	utils.ManagedAccount = "None"
+	assert.Equal(t, "", managedAccountSetting("partner"), "'none' overrides the profile default")
+}
+
+func newManagedAccountProfileCmd(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd, _ := setupTestCmd()
+	cmd.Flags().String("access-key", "", "")
+	cmd.Flags().String("secret-key", "", "")
+	cmd.Flags().String("environment", "production", "")
+	cmd.Flags().String("description", "", "")
+	cmd.Flags().String("secret-store", "", "")
+	cmd.Flags().String("default-managed-account", "", "")
+	require.NoError(t, cmd.ParseFlags(args))
+	return cmd
+}
+
+func TestProfileDefaultManagedAccount(t *testing.T) {
+	setupTestConfigEnv(t)
+
+	_, err := captureBothFromAction(t, func() error {
+		return CreateProfile(newManagedAccountProfileCmd(t,
+			"--access-key=key", "--secret-key=secret", "--secret-store=plaintext", "--default-managed-account= Acme Corp "), []string{"partner"}, true)
+	})
+	require.NoError(t, err)
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	profile, err := manager.GetProfile("partner")
+	require.NoError(t, err)
+	assert.Equal(t, "Acme Corp", profile.ManagedAccount)
+	require.NoError(t, manager.UseProfile("partner"))
+
+	cmd, out := setupTestCmd()
+	require.NoError(t, ViewConfig(cmd, nil, true))
+	assert.Contains(t, out.String(), "Managed Account: Acme Corp")
+
+	exported, err := manager.Export()
+	require.NoError(t, err)
+	assert.Equal(t, "Acme Corp", exported.Profiles["partner"].ManagedAccount)
+
+	useMemoryKeyring(t)
+	require.NoError(t, manager.MoveProfileSecrets("partner", SecretStoreKeyring))
+	profile, err = manager.GetProfile("partner")
+	require.NoError(t, err)
+	assert.Equal(t, "Acme Corp", profile.ManagedAccount, "moving the keys keeps the default")
+
+	_, err = captureBothFromAction(t, func() error {
+		return UpdateProfile(newManagedAccountProfileCmd(t, "--environment=staging"), []string{"partner"}, true)
+	})
+	require.NoError(t, err)
+	manager, err = NewConfigManager()
+	require.NoError(t, err)
+	profile, err = manager.GetProfile("partner")
+	require.NoError(t, err)
+	assert.Equal(t, "Acme Corp", profile.ManagedAccount, "other updates leave the default alone")
+
+	_, err = captureBothFromAction(t, func() error {
+		return UpdateProfile(newManagedAccountProfileCmd(t, "--default-managed-account="), []string{"partner"}, true)
+	})
+	require.NoError(t, err)
+	manager, err = NewConfigManager()
+	require.NoError(t, err)
+	profile, err = manager.GetProfile("partner")
+	require.NoError(t, err)
+	assert.Empty(t, profile.ManagedAccount)
+}
+
+func TestImportConfig_ManagedAccount(t *testing.T) {
+	setupTestConfigEnv(t)
+	origConfirm := utils.GetConfirmPrompt()
+	utils.SetConfirmPrompt(func(string, bool) bool { return true })
+	t.Cleanup(func() { utils.SetConfirmPrompt(origConfirm) })
+
+	importFile := t.TempDir() + "/import.json"
+	require.NoError(t, os.WriteFile(importFile, []byte(`{
+		"version": 1,
+		"profiles": {
+			"partner": {"accessKey": "key", "secretKey": "secret", "environment": "production", "managedAccount": "acme-uid"}
+		}
+	}`), 0600))
+
+	cmd, _ := setupTestCmd()
+	cmd.Flags().String("file", importFile, "")
+	cmd.Flags().String("secret-store", SecretStorePlaintext, "")
+	_, err := captureBothFromAction(t, func() error { return ImportConfig(cmd, nil, true) })
+	require.NoError(t, err)
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	profile, err := manager.GetProfile("partner")
+	require.NoError(t, err)
+	assert.Equal(t, "acme-uid", profile.ManagedAccount)
+}
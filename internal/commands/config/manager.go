@@ -9,6 +9,8 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
 )
 
 var (
@@ -23,6 +25,10 @@ var (
 type ConfigManager struct {
 	config     *ConfigFile
 	configPath string
+
+	// secretStores caches the secret stores opened by this manager, so a
+	// vault is unlocked at most once per process.
+	secretStores map[string]SecretStore
 }
 
 func NewConfigManager() (*ConfigManager, error) {
@@ -144,24 +150,49 @@ func (m *ConfigManager) GetProfile(name string) (*Profile, error) {
 }
 
 func (m *ConfigManager) CreateProfile(name, accessKey, secretKey, environment, description string) error {
+	return m.CreateProfileInStore(name, accessKey, secretKey, environment, description, SecretStorePlaintext)
+}
+
+// CreateProfileInStore creates or replaces a profile whose credentials are
+// kept in the named secret store. Credentials a replaced profile kept in a
+// different store are removed from it.
+func (m *ConfigManager) CreateProfileInStore(name, accessKey, secretKey, environment, description, secretStore string) error {
 	if name == "" {
 		return fmt.Errorf("profile name cannot be empty")
 	}
 	if len(strings.TrimSpace(name)) == 0 {
 		return fmt.Errorf("profile name cannot be just whitespace")
 	}
+	if strings.HasPrefix(name, credentialCacheEntryPrefix) {
+		return fmt.Errorf("profile name cannot start with %q", credentialCacheEntryPrefix)
+	}
 	if m.config == nil {
 		m.config = NewConfigFile()
 	}
 	if m.config.Profiles == nil {
 		m.config.Profiles = make(map[string]*Profile)
 	}
-	m.config.Profiles[name] = &Profile{
+	profile := &Profile{
 		AccessKey:   accessKey,
 		SecretKey:   secretKey,
 		Environment: environment,
 		Description: description,
 	}
+	if isExternalSecretStore(secretStore) {
+		store, err := m.secretStore(secretStore)
+		if err != nil {
+			return err
+		}
+		if err := store.Set(name, accessKey, secretKey); err != nil {
+			return err
+		}
+		profile.AccessKey, profile.SecretKey = "", ""
+		profile.SecretStore = secretStore
+	}
+	if existing, ok := m.config.Profiles[name]; ok && existing != nil && existing.SecretStore != profile.SecretStore {
+		m.deleteSecrets(name, existing)
+	}
+	m.config.Profiles[name] = profile
 	return m.Save()
 }
 
@@ -170,11 +201,34 @@ func (m *ConfigManager) UpdateProfile(name, accessKey, secretKey, environment st
 	if !exists {
 		return ErrProfileNotFound
 	}
-	if accessKey != "" {
-		profile.AccessKey = accessKey
+	if profile.CredentialProcess != "" && (accessKey != "" || secretKey != "") {
+		return fmt.Errorf("profile %q reads its credentials from a credential process; remove the credential process to store keys", name)
 	}
-	if secretKey != "" {
-		profile.SecretKey = secretKey
+	if isExternalSecretStore(profile.SecretStore) && (accessKey != "" || secretKey != "") {
+		currentAccessKey, currentSecretKey, err := m.Credentials(name)
+		if err != nil && !errors.Is(err, errSecretNotFound) {
+			return err
+		}
+		if accessKey == "" {
+			accessKey = currentAccessKey
+		}
+		if secretKey == "" {
+			secretKey = currentSecretKey
+		}
+		store, err := m.secretStore(profile.SecretStore)
+		if err != nil {
+			return err
+		}
+		if err := store.Set(name, accessKey, secretKey); err != nil {
+			return err
+		}
+	} else {
+		if accessKey != "" {
+			profile.AccessKey = accessKey
+		}
+		if secretKey != "" {
+			profile.SecretKey = secretKey
+		}
 	}
 	if environment != "" {
 		profile.Environment = environment
@@ -192,10 +246,147 @@ func (m *ConfigManager) DeleteProfile(name string) error {
 	if m.config.ActiveProfile == name {
 		return fmt.Errorf("cannot delete active profile; use 'config use-profile' to switch profiles first")
 	}
+	m.deleteSecrets(name, m.config.Profiles[name])
+	if err := m.forgetCachedCredentials(name); err != nil {
+		output.PrintWarning("Could not remove cached credentials for profile %q: %v", output.GetOutputConfig().NoColor, name, err)
+	}
 	delete(m.config.Profiles, name)
 	return m.Save()
 }
 
+// Credentials returns the access and secret keys of the named profile,
+// running its credential process or reading its secret store if it has one.
+func (m *ConfigManager) Credentials(name string) (accessKey, secretKey string, err error) {
+	profile, err := m.GetProfile(name)
+	if err != nil {
+		return "", "", err
+	}
+	if profile.CredentialProcess != "" {
+		return m.credentialProcessCredentials(name, profile)
+	}
+	if !isExternalSecretStore(profile.SecretStore) {
+		return profile.AccessKey, profile.SecretKey, nil
+	}
+	store, err := m.secretStore(profile.SecretStore)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read credentials for profile %q: %w", name, err)
+	}
+	accessKey, secretKey, err = store.Get(name)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read credentials for profile %q from %s: %w", name, profile.SecretStore, err)
+	}
+	return accessKey, secretKey, nil
+}
+
+// MoveProfileSecrets moves the named profile's credentials to another
+// secret store, removing them from the one they were in.
+func (m *ConfigManager) MoveProfileSecrets(name, secretStore string) error {
+	profile, err := m.GetProfile(name)
+	if err != nil {
+		return ErrProfileNotFound
+	}
+	if profile.CredentialProcess != "" {
+		return fmt.Errorf("profile %q reads its credentials from a credential process and stores none", name)
+	}
+	current := profile.SecretStore
+	if current == "" {
+		current = SecretStorePlaintext
+	}
+	if current == secretStore {
+		return nil
+	}
+	accessKey, secretKey, err := m.Credentials(name)
+	if err != nil {
+		return err
+	}
+	if err := m.CreateProfileInStore(name, accessKey, secretKey, profile.Environment, profile.Description, secretStore); err != nil {
+		return err
+	}
+	if profile.ManagedAccount == "" {
+		return nil
+	}
+	return m.SetManagedAccount(name, profile.ManagedAccount)
+}
+
+// SetManagedAccount sets the managed account, a company UID or account name,
+// the named profile acts on behalf of by default. An empty account makes it
+// act as the credentials' own company.
+func (m *ConfigManager) SetManagedAccount(name, account string) error {
+	profile, err := m.GetProfile(name)
+	if err != nil {
+		return ErrProfileNotFound
+	}
+	if strings.EqualFold(account, NoManagedAccount) {
+		account = ""
+	}
+	profile.ManagedAccount = account
+	return m.Save()
+}
+
+// SetCredentialProcess makes the named profile read its credentials from
+// command, which is given timeout to run and whose output is cached for
+// cacheFor; both are Go durations and may be empty. Any stored keys are
+// removed. An empty command removes the credential process, leaving the
+// profile with no credentials until new keys are stored.
+func (m *ConfigManager) SetCredentialProcess(name, command, timeout, cacheFor string) error {
+	profile, err := m.GetProfile(name)
+	if err != nil {
+		return ErrProfileNotFound
+	}
+	if _, err := parseCredentialProcessDuration("credential process timeout", timeout); err != nil {
+		return err
+	}
+	if _, err := parseCredentialProcessDuration("credential process cache duration", cacheFor); err != nil {
+		return err
+	}
+	if command == "" {
+		timeout, cacheFor = "", ""
+	} else if profile.CredentialProcess == "" {
+		m.deleteSecrets(name, profile)
+		profile.AccessKey, profile.SecretKey, profile.SecretStore = "", "", ""
+	}
+	if command != profile.CredentialProcess {
+		if err := m.forgetCachedCredentials(name); err != nil {
+			output.PrintWarning("Could not remove cached credentials for profile %q: %v", output.GetOutputConfig().NoColor, name, err)
+		}
+	}
+	profile.CredentialProcess = command
+	profile.CredentialProcessTimeout = timeout
+	profile.CredentialProcessCache = cacheFor
+	return m.Save()
+}
+
+// secretStore returns the named secret store, opening it on first use.
+func (m *ConfigManager) secretStore(name string) (SecretStore, error) {
+	if store, ok := m.secretStores[name]; ok {
+		return store, nil
+	}
+	store, err := newSecretStore(name)
+	if err != nil {
+		return nil, err
+	}
+	if m.secretStores == nil {
+		m.secretStores = make(map[string]SecretStore)
+	}
+	m.secretStores[name] = store
+	return store, nil
+}
+
+// deleteSecrets removes profile's credentials from its secret store, if it
+// has one. Failures are warnings: the profile is going away regardless.
+func (m *ConfigManager) deleteSecrets(name string, profile *Profile) {
+	if profile == nil || !isExternalSecretStore(profile.SecretStore) {
+		return
+	}
+	store, err := m.secretStore(profile.SecretStore)
+	if err == nil {
+		err = store.Delete(name)
+	}
+	if err != nil {
+		output.PrintWarning("Could not remove credentials for profile %q from %s: %v", output.GetOutputConfig().NoColor, name, profile.SecretStore, err)
+	}
+}
+
 func (m *ConfigManager) ListProfiles() (map[string]*Profile, error) {
 	if m == nil || m.config == nil || m.config.Profiles == nil {
 		return make(map[string]*Profile), nil
@@ -233,6 +424,7 @@ func (m *ConfigManager) Save() error {
 	return nil
 }
 
+// Export returns the configuration with every profile's credentials redacted.
 func (m *ConfigManager) Export() (*ConfigFile, error) {
 	export := &ConfigFile{
 		Version:       m.config.Version,
@@ -241,12 +433,41 @@ func (m *ConfigManager) Export() (*ConfigFile, error) {
 		Defaults:      m.config.Defaults,
 	}
 	for name, profile := range m.config.Profiles {
-		export.Profiles[name] = &Profile{
-			AccessKey:   "[REDACTED]",
-			SecretKey:   "[REDACTED]",
-			Environment: profile.Environment,
-			Description: profile.Description,
+		exported := &Profile{
+			AccessKey:      "[REDACTED]",
+			SecretKey:      "[REDACTED]",
+			Environment:    profile.Environment,
+			Description:    profile.Description,
+			ManagedAccount: profile.ManagedAccount,
+		}
+		if profile.CredentialProcess != "" {
+			exported.AccessKey, exported.SecretKey = "", ""
+			exported.CredentialProcess = profile.CredentialProcess
+			exported.CredentialProcessTimeout = profile.CredentialProcessTimeout
+			exported.CredentialProcessCache = profile.CredentialProcessCache
+		}
+		export.Profiles[name] = exported
+	}
+	return export, nil
+}
+
+// ExportWithSecrets returns the configuration with every profile's
+// credentials in plaintext, read from their secret stores. Profiles with a
+// credential process keep it in place of keys.
+func (m *ConfigManager) ExportWithSecrets() (*ConfigFile, error) {
+	export, err := m.Export()
+	if err != nil {
+		return nil, err
+	}
+	for name, profile := range export.Profiles {
+		if profile.CredentialProcess != "" {
+			continue
+		}
+		accessKey, secretKey, err := m.Credentials(name)
+		if err != nil {
+			return nil, err
 		}
+		profile.AccessKey, profile.SecretKey = accessKey, secretKey
 	}
 	return export, nil
 }
@@ -0,0 +1,208 @@
+//go:build !js && !wasm
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigFile is the name of the per-directory project config, found by
+// searching upward from the working directory.
+const ProjectConfigFile = ".megaport.yaml"
+
+// maxProjectConfigSize bounds the project config read, as for other input files.
+const maxProjectConfigSize = 1 << 20 // 1 MiB
+
+// ProjectConfig is a project config file. Its settings apply to every command
+// run in its directory or below, above the saved defaults and below flags.
+type ProjectConfig struct {
+	// Path is the file the config was read from.
+	Path string `yaml:"-"`
+
+	Profile      string                 `yaml:"profile"`
+	Env          string                 `yaml:"env"`
+	BaseURL      string                 `yaml:"base_url"`
+	Output       string                 `yaml:"output"`
+	ResourceTags map[string]string      `yaml:"resource_tags"`
+	Variables    map[string]interface{} `yaml:"variables"`
+}
+
+// FindProjectConfig reads the nearest project config in dir or one of its
+// parents. It returns nil when there is none.
+func FindProjectConfig(dir string) (*ProjectConfig, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		path := filepath.Join(dir, ProjectConfigFile)
+		info, err := os.Stat(path)
+		if err == nil && !info.IsDir() {
+			return LoadProjectConfig(path)
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read project config %s: %w", path, err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// LoadProjectConfig reads the project config at path. Unknown keys are
+// rejected so a misspelled setting is not silently ignored.
+func LoadProjectConfig(path string) (*ProjectConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config %s: %w", path, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxProjectConfigSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config %s: %w", path, err)
+	}
+	if len(data) > maxProjectConfigSize {
+		return nil, fmt.Errorf("project config %s exceeds maximum size of %d bytes", path, maxProjectConfigSize)
+	}
+
+	project := &ProjectConfig{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(project); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse project config %s: %w", path, err)
+	}
+	switch strings.ToLower(strings.TrimSpace(project.Env)) {
+	case "", "production", "prod", "staging", "development", "dev":
+	default:
+		return nil, fmt.Errorf("project config %s: env must be 'production', 'staging', or 'development' (got %q)", path, project.Env)
+	}
+	if err := utils.RejectEmptyTagKeys(project.ResourceTags); err != nil {
+		return nil, fmt.Errorf("project config %s: resource_tags: %w", path, err)
+	}
+	project.Path = path
+	return project, nil
+}
+
+// Setting origins, as reported by config view --show-origin.
+const (
+	OriginFlag        = "flag"
+	OriginDefault     = "default"
+	OriginProject     = "project"
+	OriginNotTerminal = "not-terminal"
+)
+
+// settingOrigins records where each setting applied at startup came from,
+// keyed by flag name.
+var settingOrigins = map[string]string{}
+
+// ResetSettingOrigins forgets the recorded setting origins.
+func ResetSettingOrigins() {
+	settingOrigins = map[string]string{}
+}
+
+// SetSettingOrigin records where the setting of flag came from.
+func SetSettingOrigin(flag, origin string) {
+	settingOrigins[flag] = origin
+}
+
+// activeProject is the project config applied at startup, or nil.
+var activeProject *ProjectConfig
+
+// SetActiveProject records the project config applied at startup.
+func SetActiveProject(project *ProjectConfig) {
+	activeProject = project
+}
+
+// printSettingOrigins prints each effective global setting and where it came
+// from, for config view --show-origin.
+func printSettingOrigins(cmd *cobra.Command, manager *ConfigManager) {
+	w := cmd.OutOrStdout()
+	configPath, err := GetConfigFilePath()
+	if err != nil {
+		configPath = "config.json"
+	}
+	label := func(flag, origin string) string {
+		switch origin {
+		case OriginFlag:
+			return "--" + flag + " flag"
+		case OriginProject:
+			return "project config " + activeProject.Path
+		case OriginDefault:
+			return "default setting in " + configPath
+		case OriginNotTerminal:
+			return "stdout is not a terminal"
+		}
+		return origin
+	}
+
+	fmt.Fprintf(w, "\n  Effective Settings:\n")
+	for _, flag := range []string{"profile", "env", "base-url", "output", "no-color", "quiet", "verbose", "no-pager"} {
+		f := cmd.Flag(flag)
+		if f == nil {
+			continue
+		}
+		value, origin := f.Value.String(), settingOrigins[flag]
+		if origin == "" {
+			value, origin = implicitSetting(flag, value, manager, configPath)
+		}
+		if value == "" {
+			fmt.Fprintf(w, "    %s: (not set)\n", flag)
+			continue
+		}
+		fmt.Fprintf(w, "    %s: %s (%s)\n", flag, value, label(flag, origin))
+	}
+	if activeProject != nil {
+		if len(activeProject.ResourceTags) > 0 {
+			tags := make([]string, 0, len(activeProject.ResourceTags))
+			for _, k := range slices.Sorted(maps.Keys(activeProject.ResourceTags)) {
+				tags = append(tags, k+"="+activeProject.ResourceTags[k])
+			}
+			fmt.Fprintf(w, "    resource_tags: %s (%s)\n", strings.Join(tags, ", "), label("", OriginProject))
+		}
+		if len(activeProject.Variables) > 0 {
+			names := slices.Sorted(maps.Keys(activeProject.Variables))
+			fmt.Fprintf(w, "    variables: %s (%s)\n", strings.Join(names, ", "), label("", OriginProject))
+		}
+	}
+}
+
+// implicitSetting returns the value and origin of a setting that no flag,
+// project config or default set: the active profile and the environment are
+// resolved as login resolves them, and anything else is the flag's built-in
+// default.
+func implicitSetting(flag, value string, manager *ConfigManager, configPath string) (string, string) {
+	switch flag {
+	case "profile":
+		if _, name, err := manager.GetCurrentProfile(); err == nil {
+			return name, "active profile in " + configPath
+		}
+		return "", ""
+	case "env":
+		name := utils.ProfileOverride
+		if name == "" {
+			_, name, _ = manager.GetCurrentProfile()
+		}
+		if profile, err := manager.GetProfile(name); err == nil && profile.Environment != "" {
+			return profile.Environment, fmt.Sprintf("profile '%s'", name)
+		}
+		if env := os.Getenv("MEGAPORT_ENVIRONMENT"); env != "" {
+			return env, "MEGAPORT_ENVIRONMENT environment variable"
+		}
+		return "production", "built-in default"
+	}
+	return value, "built-in default"
+}
@@ -0,0 +1,137 @@
+//go:build !js && !wasm
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "infra", "syd")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+
+	project, err := FindProjectConfig(nested)
+	require.NoError(t, err)
+	assert.Nil(t, project, "no project config is not an error")
+
+	path := filepath.Join(root, ProjectConfigFile)
+	require.NoError(t, os.WriteFile(path, []byte(`
+profile: network-prod
+env: staging
+base_url: http://localhost:8080
+output: json
+resource_tags:
+  team: network
+variables:
+  region: syd
+  port_speed: 10000
+`), 0600))
+
+	project, err = FindProjectConfig(nested)
+	require.NoError(t, err)
+	require.NotNil(t, project)
+	assert.Equal(t, path, project.Path, "the search goes up from the directory")
+	assert.Equal(t, "network-prod", project.Profile)
+	assert.Equal(t, "staging", project.Env)
+	assert.Equal(t, "http://localhost:8080", project.BaseURL)
+	assert.Equal(t, "json", project.Output)
+	assert.Equal(t, map[string]string{"team": "network"}, project.ResourceTags)
+	assert.Equal(t, map[string]interface{}{"region": "syd", "port_speed": 10000}, project.Variables)
+
+	closer := filepath.Join(root, "infra", ProjectConfigFile)
+	require.NoError(t, os.WriteFile(closer, []byte("profile: network-dev\n"), 0600))
+	project, err = FindProjectConfig(nested)
+	require.NoError(t, err)
+	assert.Equal(t, closer, project.Path, "the nearest project config wins")
+	assert.Equal(t, "network-dev", project.Profile)
+}
+
+func TestLoadProjectConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "unknown key", content: "profiles: prod\n", wantErr: "field profiles not found"},
+		{name: "invalid env", content: "env: qa\n", wantErr: `env must be 'production', 'staging', or 'development' (got "qa")`},
+		{name: "empty tag key", content: "resource_tags:\n  \"\": x\n", wantErr: "resource_tags: tag key must not be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ProjectConfigFile)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+			_, err := LoadProjectConfig(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), ProjectConfigFile)
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+	project, err := LoadProjectConfig(path)
+	require.NoError(t, err, "an empty project config sets nothing")
+	assert.Equal(t, &ProjectConfig{Path: path}, project)
+}
+
+func TestViewConfig_ShowOrigin(t *testing.T) {
+	configDir := setupTestConfigEnv(t)
+	origProfile := utils.ProfileOverride
+	t.Cleanup(func() {
+		utils.ProfileOverride = origProfile
+		ResetSettingOrigins()
+		SetActiveProject(nil)
+	})
+	utils.ProfileOverride = ""
+	t.Setenv("MEGAPORT_ENVIRONMENT", "")
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfile("dev", "key", "secret", "development", ""))
+	require.NoError(t, manager.UseProfile("dev"))
+
+	projectPath := filepath.Join(t.TempDir(), ProjectConfigFile)
+	SetActiveProject(&ProjectConfig{
+		Path:         projectPath,
+		Output:       "json",
+		ResourceTags: map[string]string{"team": "network", "cost": "42"},
+		Variables:    map[string]interface{}{"region": "syd"},
+	})
+	ResetSettingOrigins()
+	SetSettingOrigin("output", OriginProject)
+	SetSettingOrigin("no-pager", OriginDefault)
+	SetSettingOrigin("verbose", OriginFlag)
+
+	cmd, out := setupTestCmd()
+	cmd.Flags().Bool("show-origin", false, "")
+	cmd.Flags().String("profile", "", "")
+	cmd.Flags().String("env", "", "")
+	cmd.Flags().String("base-url", "", "")
+	cmd.Flags().String("output", "json", "")
+	cmd.Flags().Bool("no-pager", true, "")
+	cmd.Flags().Bool("verbose", true, "")
+	require.NoError(t, cmd.Flags().Set("show-origin", "true"))
+
+	require.NoError(t, ViewConfig(cmd, nil, true))
+	text := out.String()
+	configPath := filepath.Join(configDir, "config.json")
+	assert.Contains(t, text, "Effective Settings:")
+	assert.Contains(t, text, "profile: dev (active profile in "+configPath+")")
+	assert.Contains(t, text, "env: development (profile 'dev')")
+	assert.Contains(t, text, "base-url: (not set)")
+	assert.Contains(t, text, "output: json (project config "+projectPath+")")
+	assert.Contains(t, text, "no-pager: true (default setting in "+configPath+")")
+	assert.Contains(t, text, "verbose: true (--verbose flag)")
+	assert.Contains(t, text, "resource_tags: cost=42, team=network (project config "+projectPath+")")
+	assert.Contains(t, text, "variables: region (project config "+projectPath+")")
+
+	cmd, out = setupTestCmd()
+	require.NoError(t, ViewConfig(cmd, nil, true))
+	assert.NotContains(t, out.String(), "Effective Settings:", "origins are shown only with --show-origin")
+}
@@ -0,0 +1,73 @@
+//go:build !js && !wasm
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Secret stores a profile's credentials can be kept in. Plaintext profiles
+// keep their keys in config.json; the others keep them in the named backend
+// and leave the keys in config.json empty.
+const (
+	SecretStorePlaintext = "plaintext"
+	SecretStoreKeyring   = "keyring"
+	SecretStoreVault     = "vault"
+)
+
+// SecretStores lists the accepted --secret-store values.
+var SecretStores = []string{SecretStoreKeyring, SecretStoreVault, SecretStorePlaintext}
+
+// errSecretNotFound is returned by a SecretStore that has nothing stored for
+// a profile.
+var errSecretNotFound = errors.New("no credentials stored for profile")
+
+// SecretStore keeps profile credentials outside config.json.
+type SecretStore interface {
+	// Get returns the credentials stored for profile, or errSecretNotFound.
+	Get(profile string) (accessKey, secretKey string, err error)
+	// Set stores credentials for profile, replacing any already stored.
+	Set(profile, accessKey, secretKey string) error
+	// Delete removes profile's credentials. Deleting credentials that are
+	// not stored is not an error.
+	Delete(profile string) error
+}
+
+// newSecretStore opens the named secret store. It is a variable so tests can
+// substitute in-memory stores.
+var newSecretStore = func(name string) (SecretStore, error) {
+	switch name {
+	case SecretStoreKeyring:
+		if !keyringAvailable() {
+			return nil, fmt.Errorf("no system keyring is available on this system; use --secret-store vault instead")
+		}
+		return newKeyringStore(), nil
+	case SecretStoreVault:
+		dir, err := GetConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		return newVaultStore(filepath.Join(dir, vaultFileName)), nil
+	default:
+		return nil, fmt.Errorf("unknown secret store %q", name)
+	}
+}
+
+// validateSecretStore rejects any --secret-store value outside SecretStores.
+func validateSecretStore(name string) error {
+	for _, s := range SecretStores {
+		if name == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("secret store must be one of: %s (got %q)", strings.Join(SecretStores, ", "), name)
+}
+
+// isExternalSecretStore reports whether a profile with the given SecretStore
+// keeps its credentials outside config.json.
+func isExternalSecretStore(name string) bool {
+	return name != "" && name != SecretStorePlaintext
+}
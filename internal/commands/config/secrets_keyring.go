@@ -0,0 +1,142 @@
+//go:build !js && !wasm
+
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// keyringService is the service name credentials are stored under in the
+// system keyring; each profile is stored as its own account.
+const keyringService = "megaport-cli"
+
+// keyringTool returns the command-line tool used to reach the system
+// keyring on this platform: the macOS security tool, or secret-tool for the
+// Secret Service (GNOME Keyring, KWallet) elsewhere.
+func keyringTool() string {
+	if runtime.GOOS == "darwin" {
+		return "security"
+	}
+	return "secret-tool"
+}
+
+// keyringAvailable reports whether the system keyring can be reached. It is a
+// variable so tests never touch the real keyring.
+var keyringAvailable = func() bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	_, err := exec.LookPath(keyringTool())
+	return err == nil
+}
+
+// runKeyringTool runs the keyring tool with args, writing stdin to it, and
+// returns its stdout. It is a variable so tests can fake the keyring.
+var runKeyringTool = func(stdin string, args ...string) (string, error) {
+	cmd := exec.Command(keyringTool(), args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && isKeyringNotFound(exitErr.ExitCode(), stderr.String()) {
+			return "", errSecretNotFound
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", keyringTool(), msg)
+		}
+		return "", fmt.Errorf("%s: %w", keyringTool(), err)
+	}
+	return stdout.String(), nil
+}
+
+// isKeyringNotFound reports whether the keyring tool exited because the
+// item it was asked for does not exist.
+func isKeyringNotFound(code int, stderr string) bool {
+	if runtime.GOOS == "darwin" {
+		return code == 44
+	}
+	return code == 1 && strings.TrimSpace(stderr) == ""
+}
+
+// keyringStore keeps credentials in the system keyring. Both keys are stored
+// together as one base64-encoded JSON secret, so they never appear on a
+// command line.
+type keyringStore struct{}
+
+func newKeyringStore() *keyringStore {
+	return &keyringStore{}
+}
+
+type keyringSecret struct {
+	AccessKey string `json:"accessKey"`
+	SecretKey string `json:"secretKey"`
+}
+
+func (k *keyringStore) Get(profile string) (string, string, error) {
+	var out string
+	var err error
+	if runtime.GOOS == "darwin" {
+		out, err = runKeyringTool("", "find-generic-password", "-s", keyringService, "-a", profile, "-w")
+	} else {
+		out, err = runKeyringTool("", "lookup", "service", keyringService, "profile", profile)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(out))
+	if err != nil {
+		return "", "", fmt.Errorf("keyring entry for profile %q is not valid: %w", profile, err)
+	}
+	var secret keyringSecret
+	if err := json.Unmarshal(data, &secret); err != nil {
+		return "", "", fmt.Errorf("keyring entry for profile %q is not valid: %w", profile, err)
+	}
+	return secret.AccessKey, secret.SecretKey, nil
+}
+
+func (k *keyringStore) Set(profile, accessKey, secretKey string) error {
+	data, err := json.Marshal(keyringSecret{AccessKey: accessKey, SecretKey: secretKey})
+	if err != nil {
+		return err
+	}
+	secret := base64.StdEncoding.EncodeToString(data)
+	if runtime.GOOS == "darwin" {
+		// security reads commands from stdin with -i, which keeps the secret
+		// out of the process list. Profile names are quoted; base64 needs no quoting.
+		command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", keyringService, quoteKeyringArg(profile), secret)
+		_, err = runKeyringTool(command, "-i")
+	} else {
+		_, err = runKeyringTool(secret, "store", "--label", "Megaport CLI profile "+profile, "service", keyringService, "profile", profile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store credentials in keyring: %w", err)
+	}
+	return nil
+}
+
+func (k *keyringStore) Delete(profile string) error {
+	var err error
+	if runtime.GOOS == "darwin" {
+		_, err = runKeyringTool("", "delete-generic-password", "-s", keyringService, "-a", profile)
+	} else {
+		_, err = runKeyringTool("", "clear", "service", keyringService, "profile", profile)
+	}
+	if err != nil && !errors.Is(err, errSecretNotFound) {
+		return fmt.Errorf("failed to delete credentials from keyring: %w", err)
+	}
+	return nil
+}
+
+// quoteKeyringArg double-quotes s for the security tool's interactive mode.
+func quoteKeyringArg(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,307 @@
+//go:build !js && !wasm
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	// Keep tests away from the real system keyring, and key derivation fast.
+	keyringAvailable = func() bool { return false }
+	vaultIterations = 1000
+	os.Exit(m.Run())
+}
+
+// memorySecretStore is an in-memory SecretStore.
+type memorySecretStore struct {
+	entries map[string][2]string
+}
+
+func (s *memorySecretStore) Get(profile string) (string, string, error) {
+	e, ok := s.entries[profile]
+	if !ok {
+		return "", "", errSecretNotFound
+	}
+	return e[0], e[1], nil
+}
+
+func (s *memorySecretStore) Set(profile, accessKey, secretKey string) error {
+	s.entries[profile] = [2]string{accessKey, secretKey}
+	return nil
+}
+
+func (s *memorySecretStore) Delete(profile string) error {
+	delete(s.entries, profile)
+	return nil
+}
+
+// useMemoryKeyring makes the keyring an in-memory store for the test.
+func useMemoryKeyring(t *testing.T) *memorySecretStore {
+	t.Helper()
+	store := &memorySecretStore{entries: map[string][2]string{}}
+	orig := newSecretStore
+	newSecretStore = func(name string) (SecretStore, error) {
+		if name == SecretStoreKeyring {
+			return store, nil
+		}
+		return orig(name)
+	}
+	t.Cleanup(func() { newSecretStore = orig })
+	return store
+}
+
+// ── vaultStore ────────────────────────────────────────────────────────────────
+
+func TestVaultStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), vaultFileName)
+	t.Setenv(vaultPassphraseEnv, "correct horse")
+
+	v := newVaultStore(path)
+	require.NoError(t, v.Set("prod", "access-1", "secret-1"))
+	require.NoError(t, v.Set("dev", "access-2", "secret-2"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-1", "the vault is encrypted")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	reopened := newVaultStore(path)
+	accessKey, secretKey, err := reopened.Get("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", accessKey)
+	assert.Equal(t, "secret-1", secretKey)
+
+	require.NoError(t, reopened.Delete("prod"))
+	_, _, err = newVaultStore(path).Get("prod")
+	assert.ErrorIs(t, err, errSecretNotFound)
+	_, secretKey, err = newVaultStore(path).Get("dev")
+	require.NoError(t, err)
+	assert.Equal(t, "secret-2", secretKey)
+}
+
+func TestVaultStore_WrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), vaultFileName)
+	t.Setenv(vaultPassphraseEnv, "correct horse")
+	require.NoError(t, newVaultStore(path).Set("prod", "access", "secret"))
+
+	t.Setenv(vaultPassphraseEnv, "battery staple")
+	_, _, err := newVaultStore(path).Get("prod")
+	assert.EqualError(t, err, "failed to unlock vault: incorrect passphrase or corrupted vault")
+}
+
+func TestVaultStore_MissingVaultDoesNotPrompt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), vaultFileName)
+	orig := vaultPassphrasePrompt
+	vaultPassphrasePrompt = func(string) (string, error) {
+		t.Fatal("unexpected passphrase prompt")
+		return "", nil
+	}
+	t.Cleanup(func() { vaultPassphrasePrompt = orig })
+
+	v := newVaultStore(path)
+	_, _, err := v.Get("prod")
+	assert.ErrorIs(t, err, errSecretNotFound)
+	assert.NoError(t, v.Delete("prod"))
+}
+
+func TestVaultStore_NewVaultConfirmsPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), vaultFileName)
+	answers := []string{"first", "second"}
+	var prompts []string
+	orig := vaultPassphrasePrompt
+	vaultPassphrasePrompt = func(msg string) (string, error) {
+		prompts = append(prompts, msg)
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}
+	t.Cleanup(func() { vaultPassphrasePrompt = orig })
+
+	err := newVaultStore(path).Set("prod", "access", "secret")
+	assert.EqualError(t, err, "failed to read vault passphrase: vault passphrases do not match")
+	assert.Equal(t, []string{"Choose a vault passphrase: ", "Confirm vault passphrase: "}, prompts)
+	assert.NoFileExists(t, path)
+}
+
+func TestVaultStore_Corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), vaultFileName)
+	t.Setenv(vaultPassphraseEnv, "correct horse")
+	require.NoError(t, newVaultStore(path).Set("prod", "access", "secret"))
+
+	var file vaultFile
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &file))
+	file.Nonce = file.Nonce[:4]
+	data, err = json.Marshal(file)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	_, _, err = newVaultStore(path).Get("prod")
+	assert.ErrorContains(t, err, "invalid nonce")
+}
+
+// ── keyringStore ──────────────────────────────────────────────────────────────
+
+func TestKeyringStore(t *testing.T) {
+	if keyringTool() != "secret-tool" {
+		t.Skip("exercises the secret-tool commands")
+	}
+	stored := map[string]string{}
+	var calls [][]string
+	orig := runKeyringTool
+	runKeyringTool = func(stdin string, args ...string) (string, error) {
+		calls = append(calls, args)
+		profile := args[len(args)-1]
+		switch args[0] {
+		case "store":
+			stored[profile] = stdin
+		case "lookup":
+			s, ok := stored[profile]
+			if !ok {
+				return "", errSecretNotFound
+			}
+			return s + "\n", nil
+		case "clear":
+			if _, ok := stored[profile]; !ok {
+				return "", errSecretNotFound
+			}
+			delete(stored, profile)
+		}
+		return "", nil
+	}
+	t.Cleanup(func() { runKeyringTool = orig })
+
+	k := newKeyringStore()
+	require.NoError(t, k.Set("prod", "access", "secret"))
+	assert.NotContains(t, strings.Join(calls[0], " "), "secret", "secrets never go on the command line")
+	assert.Equal(t, []string{"store", "--label", "Megaport CLI profile prod", "service", keyringService, "profile", "prod"}, calls[0])
+
+	accessKey, secretKey, err := k.Get("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "access", accessKey)
+	assert.Equal(t, "secret", secretKey)
+
+	require.NoError(t, k.Delete("prod"))
+	require.NoError(t, k.Delete("prod"), "deleting missing credentials is not an error")
+	_, _, err = k.Get("prod")
+	assert.ErrorIs(t, err, errSecretNotFound)
+}
+
+func TestKeyringStore_Unavailable(t *testing.T) {
+	_, err := newSecretStore(SecretStoreKeyring)
+	assert.ErrorContains(t, err, "no system keyring is available")
+}
+
+func TestQuoteKeyringArg(t *testing.T) {
+	assert.Equal(t, `"my \"prod\" \\ profile"`, quoteKeyringArg(`my "prod" \ profile`))
+}
+
+func TestValidateSecretStore(t *testing.T) {
+	for _, s := range SecretStores {
+		assert.NoError(t, validateSecretStore(s))
+	}
+	assert.EqualError(t, validateSecretStore("file"), `secret store must be one of: keyring, vault, plaintext (got "file")`)
+}
+
+// ── ConfigManager ─────────────────────────────────────────────────────────────
+
+func TestConfigManager_SecretStoreProfiles(t *testing.T) {
+	setupTestConfigEnv(t)
+	keyring := useMemoryKeyring(t)
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfileInStore("prod", "access", "secret", "production", "", SecretStoreKeyring))
+
+	data, err := os.ReadFile(manager.configPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret\"", "keyring credentials are not written to config.json")
+	assert.Contains(t, string(data), `"secretStore": "keyring"`)
+
+	accessKey, secretKey, err := manager.Credentials("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "access", accessKey)
+	assert.Equal(t, "secret", secretKey)
+
+	require.NoError(t, manager.UpdateProfile("prod", "", "new-secret", "", false, ""))
+	assert.Equal(t, [2]string{"access", "new-secret"}, keyring.entries["prod"], "updates keep the key that was not changed")
+
+	exported, err := manager.ExportWithSecrets()
+	require.NoError(t, err)
+	assert.Equal(t, "new-secret", exported.Profiles["prod"].SecretKey)
+	assert.Empty(t, exported.Profiles["prod"].SecretStore)
+
+	redacted, err := manager.Export()
+	require.NoError(t, err)
+	assert.Equal(t, "[REDACTED]", redacted.Profiles["prod"].SecretKey)
+
+	require.NoError(t, manager.DeleteProfile("prod"))
+	assert.Empty(t, keyring.entries)
+}
+
+func TestConfigManager_MoveProfileSecrets(t *testing.T) {
+	setupTestConfigEnv(t)
+	keyring := useMemoryKeyring(t)
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfile("prod", "access", "secret", "production", "Production"))
+
+	require.NoError(t, manager.MoveProfileSecrets("prod", SecretStoreKeyring))
+	profile, err := manager.GetProfile("prod")
+	require.NoError(t, err)
+	assert.Empty(t, profile.AccessKey)
+	assert.Empty(t, profile.SecretKey)
+	assert.Equal(t, "Production", profile.Description)
+	assert.Equal(t, [2]string{"access", "secret"}, keyring.entries["prod"])
+
+	require.NoError(t, manager.MoveProfileSecrets("prod", SecretStorePlaintext))
+	profile, err = manager.GetProfile("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", profile.SecretKey)
+	assert.Empty(t, profile.SecretStore)
+	assert.Empty(t, keyring.entries, "moving out of a store removes the credentials from it")
+}
+
+func TestConfigManager_CredentialsStoreError(t *testing.T) {
+	setupTestConfigEnv(t)
+	useMemoryKeyring(t)
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfileInStore("prod", "access", "secret", "production", "", SecretStoreKeyring))
+
+	fresh, err := NewConfigManager()
+	require.NoError(t, err)
+	newSecretStore = func(string) (SecretStore, error) { return nil, errors.New("keyring locked") }
+	_, _, err = fresh.Credentials("prod")
+	assert.EqualError(t, err, `failed to read credentials for profile "prod": keyring locked`)
+}
+
+func TestConfigManager_VaultProfile(t *testing.T) {
+	configDir := setupTestConfigEnv(t)
+	t.Setenv(vaultPassphraseEnv, "correct horse")
+
+	manager, err := NewConfigManager()
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateProfileInStore("prod", "access", "secret", "production", "", SecretStoreVault))
+	assert.FileExists(t, filepath.Join(configDir, vaultFileName))
+
+	fresh, err := NewConfigManager()
+	require.NoError(t, err)
+	_, secretKey, err := fresh.Credentials("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", secretKey)
+}
@@ -0,0 +1,254 @@
+//go:build !js && !wasm
+
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/megaport/megaport-cli/internal/utils"
+)
+
+const (
+	// vaultFileName is the vault's file name in the config directory.
+	vaultFileName = "secrets.vault"
+	// vaultVersion is the current version of the vault file format.
+	vaultVersion = 1
+	// vaultKDF names the key derivation function recorded in the vault.
+	vaultKDF = "pbkdf2-sha256"
+	// vaultPassphraseEnv names the environment variable that supplies the
+	// vault passphrase in place of a prompt.
+	vaultPassphraseEnv = "MEGAPORT_VAULT_PASSPHRASE"
+)
+
+// vaultIterations is the PBKDF2 iteration count for new vaults. It is a
+// variable so tests can keep key derivation fast.
+var vaultIterations = 600000
+
+// vaultAAD binds the ciphertext to the vault format.
+var vaultAAD = []byte("megaport-cli vault v1")
+
+// vaultPassphrasePrompt asks for the vault passphrase. It is a variable so
+// tests can answer it.
+var vaultPassphrasePrompt = func(msg string) (string, error) {
+	return utils.SecretResourcePrompt("config", msg, false)
+}
+
+// vaultFile is the on-disk vault: the credentials of every vault profile,
+// encrypted with AES-256-GCM under a key derived from the passphrase.
+type vaultFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type vaultEntry struct {
+	AccessKey string `json:"accessKey"`
+	SecretKey string `json:"secretKey"`
+}
+
+// vaultStore keeps credentials in an encrypted file. It asks for the
+// passphrase once, the first time it is used, and keeps the vault unlocked
+// for the rest of the process.
+type vaultStore struct {
+	path       string
+	unlocked   bool
+	key        []byte
+	salt       []byte
+	iterations int
+	entries    map[string]vaultEntry
+}
+
+func newVaultStore(path string) *vaultStore {
+	return &vaultStore{path: path}
+}
+
+// passphrase returns the vault passphrase from MEGAPORT_VAULT_PASSPHRASE, or
+// asks for it. A new vault's passphrase is asked for twice.
+func (v *vaultStore) passphrase(isNew bool) (string, error) {
+	if p := os.Getenv(vaultPassphraseEnv); p != "" {
+		return p, nil
+	}
+	if !isNew {
+		return vaultPassphrasePrompt("Enter vault passphrase: ")
+	}
+	p, err := vaultPassphrasePrompt("Choose a vault passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(p) == "" {
+		return "", fmt.Errorf("vault passphrase cannot be empty")
+	}
+	confirm, err := vaultPassphrasePrompt("Confirm vault passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if p != confirm {
+		return "", fmt.Errorf("vault passphrases do not match")
+	}
+	return p, nil
+}
+
+// unlock reads and decrypts the vault, creating an empty one in memory if
+// the file does not exist yet.
+func (v *vaultStore) unlock() error {
+	if v.unlocked {
+		return nil
+	}
+
+	data, err := os.ReadFile(v.path)
+	if errors.Is(err, os.ErrNotExist) {
+		passphrase, err := v.passphrase(true)
+		if err != nil {
+			return fmt.Errorf("failed to read vault passphrase: %w", err)
+		}
+		v.salt = make([]byte, 16)
+		if _, err := rand.Read(v.salt); err != nil {
+			return fmt.Errorf("failed to create vault: %w", err)
+		}
+		v.iterations = vaultIterations
+		if v.key, err = deriveVaultKey(passphrase, v.salt, v.iterations); err != nil {
+			return fmt.Errorf("failed to create vault: %w", err)
+		}
+		v.entries = map[string]vaultEntry{}
+		v.unlocked = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read vault: %w", err)
+	}
+
+	var file vaultFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("vault %s is corrupted: %w", v.path, err)
+	}
+	if file.Version > vaultVersion || file.KDF != vaultKDF {
+		return fmt.Errorf("vault %s was written by a newer version of megaport-cli", v.path)
+	}
+
+	passphrase, err := v.passphrase(false)
+	if err != nil {
+		return fmt.Errorf("failed to read vault passphrase: %w", err)
+	}
+	key, err := deriveVaultKey(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return fmt.Errorf("failed to unlock vault: %w", err)
+	}
+	gcm, err := newVaultCipher(key)
+	if err != nil {
+		return fmt.Errorf("failed to unlock vault: %w", err)
+	}
+	if len(file.Nonce) != gcm.NonceSize() {
+		return fmt.Errorf("vault %s is corrupted: invalid nonce", v.path)
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, vaultAAD)
+	if err != nil {
+		return fmt.Errorf("failed to unlock vault: incorrect passphrase or corrupted vault")
+	}
+	entries := map[string]vaultEntry{}
+	if err := json.Unmarshal(plaintext, &entries); err != nil {
+		return fmt.Errorf("vault %s is corrupted: %w", v.path, err)
+	}
+
+	v.key, v.salt, v.iterations, v.entries = key, file.Salt, file.Iterations, entries
+	v.unlocked = true
+	return nil
+}
+
+// save encrypts the entries under a fresh nonce and replaces the vault, so an
+// interrupted write never leaves a corrupt vault behind.
+func (v *vaultStore) save() error {
+	plaintext, err := json.Marshal(v.entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal vault: %w", err)
+	}
+	gcm, err := newVaultCipher(v.key)
+	if err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	data, err := json.MarshalIndent(vaultFile{
+		Version:    vaultVersion,
+		KDF:        vaultKDF,
+		Iterations: v.iterations,
+		Salt:       v.salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, vaultAAD),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal vault: %w", err)
+	}
+	if err := writePrivateFile(v.path, data); err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	return nil
+}
+
+func (v *vaultStore) Get(profile string) (string, string, error) {
+	if !v.unlocked {
+		if _, err := os.Stat(v.path); errors.Is(err, os.ErrNotExist) {
+			return "", "", errSecretNotFound
+		}
+	}
+	if err := v.unlock(); err != nil {
+		return "", "", err
+	}
+	entry, ok := v.entries[profile]
+	if !ok {
+		return "", "", errSecretNotFound
+	}
+	return entry.AccessKey, entry.SecretKey, nil
+}
+
+func (v *vaultStore) Set(profile, accessKey, secretKey string) error {
+	if err := v.unlock(); err != nil {
+		return err
+	}
+	v.entries[profile] = vaultEntry{AccessKey: accessKey, SecretKey: secretKey}
+	return v.save()
+}
+
+func (v *vaultStore) Delete(profile string) error {
+	if !v.unlocked {
+		if _, err := os.Stat(v.path); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+	}
+	if err := v.unlock(); err != nil {
+		return err
+	}
+	if _, ok := v.entries[profile]; !ok {
+		return nil
+	}
+	delete(v.entries, profile)
+	return v.save()
+}
+
+func deriveVaultKey(passphrase string, salt []byte, iterations int) ([]byte, error) {
+	if iterations <= 0 || len(salt) == 0 {
+		return nil, fmt.Errorf("invalid key derivation parameters")
+	}
+	return pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+}
+
+func newVaultCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
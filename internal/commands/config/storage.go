@@ -36,3 +36,26 @@ func GetConfigFilePath() (string, error) {
 	}
 	return filepath.Join(configDir, "config.json"), nil
 }
+
+// writePrivateFile replaces path with data, readable only by the current
+// user. The file is written in full before it is renamed into place, so
+// concurrent commands never read a partial file.
+func writePrivateFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,141 @@
+//go:build !js && !wasm
+
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// tokenCacheFileName is the token cache's file name in the config directory.
+	tokenCacheFileName = "tokens.json"
+	// tokenCacheVersion is the current version of the token cache format.
+	tokenCacheVersion = 1
+	// tokenRefreshMargin is how long before its expiry a cached token stops
+	// being reused, so a command never starts with a token about to lapse.
+	tokenRefreshMargin = 5 * time.Minute
+)
+
+// tokenCacheFile is the on-disk token cache, keyed by tokenCacheKey.
+type tokenCacheFile struct {
+	Version int                    `json:"version"`
+	Tokens  map[string]cachedToken `json:"tokens"`
+}
+
+// cachedToken is an access token and when it expires. CredentialHash ties it
+// to the credentials it was issued for, so changing a profile's keys never
+// reuses a token issued for the old ones.
+type cachedToken struct {
+	AccessToken    string    `json:"accessToken"`
+	Expiry         time.Time `json:"expiry"`
+	CredentialHash string    `json:"credentialHash"`
+}
+
+// tokenCacheKey identifies the tokens of one profile in one environment. An
+// empty profile means credentials from environment variables. baseURL and
+// tokenURL are the --base-url and --token-url overrides, if any.
+func tokenCacheKey(profile, environment, baseURL, tokenURL string) string {
+	if profile == "" {
+		profile = "(env vars)"
+	}
+	key := profile + "|" + environment
+	if baseURL != "" || tokenURL != "" {
+		key += "|" + baseURL + "|" + tokenURL
+	}
+	return key
+}
+
+func credentialHash(accessKey, secretKey string) string {
+	sum := sha256.Sum256([]byte(accessKey + "\x00" + secretKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func tokenCachePath() (string, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, tokenCacheFileName), nil
+}
+
+// readTokenCache reads the token cache. A missing or unreadable cache is
+// empty: the cache only ever saves a token exchange.
+func readTokenCache(path string) tokenCacheFile {
+	cache := tokenCacheFile{Version: tokenCacheVersion, Tokens: map[string]cachedToken{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	var file tokenCacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != tokenCacheVersion || file.Tokens == nil {
+		return cache
+	}
+	return file
+}
+
+// cachedAccessToken returns the cached token for key if it was issued for
+// these credentials and is not within tokenRefreshMargin of expiring.
+func cachedAccessToken(key, accessKey, secretKey string, now time.Time) (string, time.Time, bool) {
+	path, err := tokenCachePath()
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	token, ok := readTokenCache(path).Tokens[key]
+	if !ok || token.AccessToken == "" || token.CredentialHash != credentialHash(accessKey, secretKey) {
+		return "", time.Time{}, false
+	}
+	if !now.Add(tokenRefreshMargin).Before(token.Expiry) {
+		return "", time.Time{}, false
+	}
+	return token.AccessToken, token.Expiry, true
+}
+
+// cacheAccessToken saves a token for key, dropping any cached tokens that
+// have expired.
+func cacheAccessToken(key, accessKey, secretKey, accessToken string, expiry, now time.Time) error {
+	path, err := tokenCachePath()
+	if err != nil {
+		return err
+	}
+	cache := readTokenCache(path)
+	for k, t := range cache.Tokens {
+		if !now.Before(t.Expiry) {
+			delete(cache.Tokens, k)
+		}
+	}
+	cache.Tokens[key] = cachedToken{
+		AccessToken:    accessToken,
+		Expiry:         expiry,
+		CredentialHash: credentialHash(accessKey, secretKey),
+	}
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal token cache: %w", err)
+	}
+	if err := writePrivateFile(path, data); err != nil {
+		return fmt.Errorf("failed to write token cache: %w", err)
+	}
+	return nil
+}
+
+// ClearCachedTokens deletes every cached access token and returns how many
+// there were.
+func ClearCachedTokens() (int, error) {
+	path, err := tokenCachePath()
+	if err != nil {
+		return 0, err
+	}
+	n := len(readTokenCache(path).Tokens)
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("failed to clear token cache: %w", err)
+	}
+	return n, nil
+}
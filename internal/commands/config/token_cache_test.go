@@ -0,0 +1,138 @@
+//go:build !js && !wasm
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenCacheKey(t *testing.T) {
+	assert.Equal(t, "prod|production", tokenCacheKey("prod", "production", "", ""))
+	assert.Equal(t, "(env vars)|staging", tokenCacheKey("", "staging", "", ""))
+	assert.Equal(t, "prod|production|http://localhost:8080|", tokenCacheKey("prod", "production", "http://localhost:8080", ""))
+}
+
+func TestTokenCache_RoundTrip(t *testing.T) {
+	configDir := setupTestConfigEnv(t)
+	now := time.Now()
+	expiry := now.Add(time.Hour)
+
+	require.NoError(t, cacheAccessToken("prod|production", "access", "secret", "token-1", expiry, now))
+
+	info, err := os.Stat(filepath.Join(configDir, tokenCacheFileName))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	token, gotExpiry, ok := cachedAccessToken("prod|production", "access", "secret", now)
+	require.True(t, ok)
+	assert.Equal(t, "token-1", token)
+	assert.True(t, expiry.Equal(gotExpiry))
+
+	_, _, ok = cachedAccessToken("prod|staging", "access", "secret", now)
+	assert.False(t, ok, "tokens are cached per environment")
+
+	_, _, ok = cachedAccessToken("prod|production", "access", "rotated-secret", now)
+	assert.False(t, ok, "a token is not reused once the credentials change")
+
+	_, _, ok = cachedAccessToken("prod|production", "access", "secret", expiry.Add(-tokenRefreshMargin))
+	assert.False(t, ok, "a token close to expiry is not reused")
+}
+
+func TestTokenCache_PrunesExpiredTokens(t *testing.T) {
+	configDir := setupTestConfigEnv(t)
+	now := time.Now()
+
+	require.NoError(t, cacheAccessToken("old|production", "access", "secret", "token-old", now.Add(time.Minute), now))
+	require.NoError(t, cacheAccessToken("new|production", "access", "secret", "token-new", now.Add(2*time.Hour), now.Add(time.Hour)))
+
+	cache := readTokenCache(filepath.Join(configDir, tokenCacheFileName))
+	assert.NotContains(t, cache.Tokens, "old|production")
+	assert.Contains(t, cache.Tokens, "new|production")
+}
+
+func TestTokenCache_CorruptedCacheIsIgnored(t *testing.T) {
+	configDir := setupTestConfigEnv(t)
+	path := filepath.Join(configDir, tokenCacheFileName)
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0600))
+
+	_, _, ok := cachedAccessToken("prod|production", "access", "secret", time.Now())
+	assert.False(t, ok)
+	require.NoError(t, cacheAccessToken("prod|production", "access", "secret", "token", time.Now().Add(time.Hour), time.Now()))
+	assert.Len(t, readTokenCache(path).Tokens, 1)
+}
+
+func TestClearCachedTokens(t *testing.T) {
+	configDir := setupTestConfigEnv(t)
+
+	n, err := ClearCachedTokens()
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "clearing a missing cache is not an error")
+
+	now := time.Now()
+	require.NoError(t, cacheAccessToken("a|production", "access", "secret", "token-a", now.Add(time.Hour), now))
+	require.NoError(t, cacheAccessToken("b|production", "access", "secret", "token-b", now.Add(time.Hour), now))
+
+	n, err = ClearCachedTokens()
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.NoFileExists(t, filepath.Join(configDir, tokenCacheFileName))
+}
+
+func TestLoginReusesCachedToken(t *testing.T) {
+	setupTestConfigEnv(t)
+	origBaseURL, origTokenURL := utils.BaseURL, utils.TokenURL
+	origEnv, origProfile := utils.Env, utils.ProfileOverride
+	t.Cleanup(func() {
+		utils.BaseURL, utils.TokenURL = origBaseURL, origTokenURL
+		utils.Env, utils.ProfileOverride = origEnv, origProfile
+	})
+
+	var tokenRequests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/token" {
+			n := tokenRequests.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":3600}`, n)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	t.Setenv("MEGAPORT_ACCESS_KEY", "test-key")
+	t.Setenv("MEGAPORT_SECRET_KEY", "test-secret")
+	utils.BaseURL = ts.URL
+	utils.TokenURL = ts.URL + "/oauth2/token"
+	utils.Env = ""
+	utils.ProfileOverride = ""
+
+	origStderr := os.Stderr
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	require.NoError(t, err)
+	os.Stderr = devNull
+	t.Cleanup(func() { os.Stderr = origStderr; _ = devNull.Close() })
+
+	_, err = LoginWithOutput(context.Background(), "")
+	require.NoError(t, err)
+	_, err = LoginWithOutput(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), tokenRequests.Load(), "the second login reuses the cached token")
+
+	_, err = ClearCachedTokens()
+	require.NoError(t, err)
+	_, err = LoginWithOutput(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), tokenRequests.Load(), "logging out forces a new token")
+}
@@ -0,0 +1,35 @@
+package destroy
+
+import (
+	"github.com/megaport/megaport-cli/internal/base/cmdbuilder"
+	"github.com/spf13/cobra"
+)
+
+// AddCommandsTo builds the destroy command and adds it to the root command.
+func AddCommandsTo(rootCmd *cobra.Command) {
+	destroyCmd := cmdbuilder.NewCommand("destroy", "Delete the resources an apply created").
+		WithLongDesc("Delete the resources recorded in an apply state file, in reverse dependency order: VXCs first, then service keys, IXs, NAT gateways, MVEs and MCRs, then ports.\n\nWith --file, only the config file's entries are destroyed, using the UIDs recorded in its state file (by default next to the config file). With only --state, every resource in the state file is destroyed. Destroyed resources are removed from the state file.").
+		WithOutputFormatRunFunc(DestroyConfig).
+		WithFlagP("file", "f", "", "Path to config file (YAML or JSON)").
+		WithFlag("state", "", "Path to the apply state file (default: the config file path with a .state.json extension)").
+		WithBoolFlag("dry-run", false, "List the resources that would be deleted without deleting them").
+		WithBoolFlagP("yes", "y", false, "Skip confirmation prompt").
+		WithBoolFlag("later", false, "Cancel VXCs and IXs at the end of the current billing cycle instead of deleting immediately").
+		WithStringArrayFlag("var", "Set a config variable as name=value (repeatable; overrides --var-file)").
+		WithStringArrayFlag("var-file", "Path to a YAML or JSON file of config variable values (repeatable)").
+		WithExample(`megaport-cli destroy -f infrastructure.yaml`).
+		WithExample(`megaport-cli destroy -f infrastructure.yaml --dry-run`).
+		WithExample(`megaport-cli destroy -f infrastructure.yaml --yes`).
+		WithExample(`megaport-cli destroy --state ci/infrastructure.state.json`).
+		WithExample(`megaport-cli destroy -f infrastructure.yaml --later`).
+		WithImportantNote("Config entries with no UID in the state file are reported and left alone: destroy never looks resources up by name").
+		WithImportantNote("Service keys cannot be deleted, so they are deactivated and removed from the state file").
+		WithImportantNote("With --later, VXCs and IXs are cancelled at the end of their current term; other resources cannot be deleted while VXCs or IXs are attached, so they are kept until destroy is re-run without --later").
+		WithImportantNote("Destroy stops at the first failed deletion; re-running it continues from there").
+		WithImportantNote("Either --file or --state must be provided").
+		WithImportantNote("Deletion is final and cannot be undone").
+		WithRootCmd(rootCmd).
+		Build()
+
+	rootCmd.AddCommand(destroyCmd)
+}
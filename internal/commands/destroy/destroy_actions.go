@@ -0,0 +1,283 @@
+package destroy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/commands/config"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/utils"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/spf13/cobra"
+)
+
+// Destroy result statuses.
+const (
+	statusDeleted         = "deleted"
+	statusScheduled       = "cancellation scheduled at end of term"
+	statusAlreadyDeleted  = "already deleted"
+	statusNotInState      = "not in apply state"
+	statusWouldDelete     = "would delete"
+	statusWouldSchedule   = "would schedule cancellation at end of term"
+	statusKept            = "kept (only immediate deletion is supported)"
+	statusWouldKeep       = "would keep (only immediate deletion is supported)"
+	statusDeactivated     = "deactivated"
+	statusWouldDeactivate = "would deactivate"
+)
+
+// destroyOrder is the reverse of provisioning order: nothing is deleted while
+// a resource that depends on it still exists.
+var destroyOrder = []string{"vxc", "service_key", "ix", "nat_gateway", "mve", "mcr", "port"}
+
+// destroyTarget is a resource destroy resolved to a UID through the apply state.
+// A pending target is one a replacement superseded whose delete has not yet
+// succeeded.
+type destroyTarget struct {
+	resType string // template key, e.g. port or nat_gateway
+	name    string
+	uid     string
+	pending bool
+}
+
+// forget drops t from the apply state once it is gone.
+func (t destroyTarget) forget(st *infra.StateFile, noColor bool) {
+	if t.pending {
+		st.DeletedReplaced(t.uid, noColor)
+		return
+	}
+	st.Forget(t.resType, t.name, noColor)
+}
+
+// DestroyConfig is the entry point for `megaport-cli destroy`.
+func DestroyConfig(cmd *cobra.Command, _ []string, noColor bool, outputFormat string) error {
+	output.SetOutputFormat(outputFormat)
+
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	filePath, _ := cmd.Flags().GetString("file")
+	statePath, _ := cmd.Flags().GetString("state")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	yes, _ := cmd.Flags().GetBool("yes")
+	later, _ := cmd.Flags().GetBool("later")
+
+	if filePath == "" && statePath == "" {
+		output.PrintError("--file or --state is required", noColor)
+		return exitcodes.NewUsageError(fmt.Errorf("--file or --state is required"))
+	}
+	if statePath == "" {
+		statePath = infra.DefaultStatePath(filePath)
+	}
+
+	var cfg *infra.Config
+	if filePath != "" {
+		vars, err := infra.ConfigVars(cmd)
+		if err != nil {
+			output.PrintError("Invalid variables: %v", noColor, err)
+			return exitcodes.NewUsageError(err)
+		}
+		cfg, err = infra.ParseConfigFile(filePath, vars, utils.ProjectSettingsFromCmd(cmd))
+		if err != nil {
+			output.PrintError("Failed to parse config file: %v", noColor, err)
+			return err
+		}
+	}
+	st, err := infra.OpenStateFile(statePath)
+	if err != nil {
+		output.PrintError("Failed to load apply state: %v", noColor, err)
+		return err
+	}
+
+	targets, results := destroyTargets(cfg, st.State)
+	if len(targets) == 0 {
+		output.PrintInfo("No resources in the apply state to destroy.", noColor)
+		return output.PrintOutput(results, outputFormat, noColor)
+	}
+
+	ctx, cancel := utils.ContextFromCmdWithDefault(cmd, utils.DefaultMutationTimeout)
+	defer cancel()
+
+	spinner := output.PrintLoggingInWithOutput(noColor, outputFormat)
+	client, err := config.Login(ctx)
+	if err != nil {
+		spinner.Stop()
+		output.PrintError("Failed to log in: %v", noColor, err)
+		return err
+	}
+	spinner.Stop()
+
+	// Drop resources that are already gone so the summary only lists real deletions.
+	var live []destroyTarget
+	for _, t := range targets {
+		var r *infra.LiveResource
+		err := utils.WithIdempotentRetry(ctx, func(ctx context.Context) error {
+			var e error
+			r, e = infra.GetResource(ctx, client, t.resType, t.uid)
+			return e
+		})
+		if err != nil && !infra.IsNotFound(err) {
+			output.PrintError("Failed to look up %s %q (%s): %v", noColor, t.resType, t.name, t.uid, err)
+			return fmt.Errorf("looking up %s %q (%s) from apply state: %w", t.resType, t.name, t.uid, err)
+		}
+		if err != nil || !utils.IsActiveStatus(r.Status()) {
+			results = append(results, infra.Result{Type: infra.DisplayType(t.resType), Name: t.name, UID: t.uid, Status: statusAlreadyDeleted})
+			if !dryRun {
+				t.forget(st, noColor)
+			}
+			continue
+		}
+		live = append(live, t)
+	}
+
+	if dryRun {
+		for _, t := range live {
+			results = append(results, infra.Result{Type: infra.DisplayType(t.resType), Name: t.name, UID: t.uid, Status: destroyDryRunStatus(t.resType, later)})
+		}
+		return output.PrintOutput(results, outputFormat, noColor)
+	}
+	if len(live) == 0 {
+		output.PrintInfo("All resources in the apply state are already deleted.", noColor)
+		return output.PrintOutput(results, outputFormat, noColor)
+	}
+
+	if !yes {
+		output.PrintInfo("Resources to destroy:", noColor)
+		for _, t := range live {
+			output.PrintInfo("  %s %q (%s): %s", noColor, infra.DisplayType(t.resType), t.name, t.uid, destroyDryRunStatus(t.resType, later))
+		}
+		if !utils.ConfirmPrompt(fmt.Sprintf("Destroy %d resource(s)? This cannot be undone.", len(live)), noColor) {
+			return exitcodes.New(exitcodes.Cancelled, fmt.Errorf("cancelled by user"))
+		}
+	}
+
+	for _, t := range live {
+		resType := infra.DisplayType(t.resType)
+		if later && !cancelsAtTermEnd(t.resType) {
+			results = append(results, infra.Result{Type: resType, Name: t.name, UID: t.uid, Status: statusKept})
+			continue
+		}
+		deleteSpinner := output.PrintResourceDeleting(resType, t.uid, noColor)
+		err := utils.WithRetry(ctx, func(ctx context.Context) error {
+			switch t.resType {
+			case "vxc":
+				return client.VXCService.DeleteVXC(ctx, t.uid, &megaport.DeleteVXCRequest{DeleteNow: !later})
+			case "ix":
+				return client.IXService.DeleteIX(ctx, t.uid, &megaport.DeleteIXRequest{DeleteNow: !later})
+			}
+			return infra.DeleteResource(ctx, client, infra.CreatedResource{ResType: resType, Name: t.name, UID: t.uid})
+		})
+		deleteSpinner.Stop()
+		if err != nil {
+			err = utils.WrapAPIError(err, resType, t.uid)
+			results = append(results, infra.Result{Type: resType, Name: t.name, UID: t.uid, Status: infra.StatusError + ": " + err.Error()})
+			_ = output.PrintOutput(results, outputFormat, noColor)
+			output.PrintError("Destroy stopped: %v", noColor, err)
+			return fmt.Errorf("failed to delete %s %q (%s): %w", resType, t.name, t.uid, err)
+		}
+		if t.resType == "service_key" {
+			output.PrintSuccess("Service key %s deactivated", noColor, t.uid)
+			t.forget(st, noColor)
+			results = append(results, infra.Result{Type: resType, Name: t.name, UID: t.uid, Status: statusDeactivated})
+			continue
+		}
+		output.PrintResourceDeleted(resType, t.uid, !later, noColor)
+		if later {
+			// The resource keeps running until the end of its term, so it stays
+			// in the state; once it is cancelled, the next run drops it.
+			results = append(results, infra.Result{Type: resType, Name: t.name, UID: t.uid, Status: statusScheduled})
+			continue
+		}
+		t.forget(st, noColor)
+		results = append(results, infra.Result{Type: resType, Name: t.name, UID: t.uid, Status: statusDeleted})
+	}
+
+	if later && slices.ContainsFunc(live, func(t destroyTarget) bool { return !cancelsAtTermEnd(t.resType) }) {
+		output.PrintWarning("Only VXCs and IXs can be cancelled at the end of their term, and the rest cannot be deleted while they are attached; re-run destroy without --later once they have been cancelled.", noColor)
+	}
+	return output.PrintOutput(results, outputFormat, noColor)
+}
+
+// destroyTargets resolves the resources to destroy, in destroy order. With a
+// config, only its entries are destroyed, and entries with no UID in the state
+// are reported rather than looked up by name; without one, everything in the
+// State is destroyed. Replaced resources still waiting to be deleted are
+// destroyed along with the entries they were declared as.
+func destroyTargets(cfg *infra.Config, state *infra.State) ([]destroyTarget, []infra.Result) {
+	var targets []destroyTarget
+	results := []infra.Result{}
+	if cfg == nil {
+		for _, resType := range destroyOrder {
+			for _, r := range slices.Backward(state.PendingDeletes) {
+				if r.Type == resType {
+					targets = append(targets, destroyTarget{resType: r.Type, name: r.Name, uid: r.UID, pending: true})
+				}
+			}
+			for _, r := range slices.Backward(state.Resources) {
+				if r.Type == resType {
+					targets = append(targets, destroyTarget{resType: r.Type, name: r.Name, uid: r.UID})
+				}
+			}
+		}
+		return targets, results
+	}
+
+	declared := map[string][]string{}
+	for _, p := range cfg.Ports {
+		declared["port"] = append(declared["port"], p.Name)
+	}
+	for _, m := range cfg.MCRs {
+		declared["mcr"] = append(declared["mcr"], m.Name)
+	}
+	for _, mv := range cfg.MVEs {
+		declared["mve"] = append(declared["mve"], mv.Name)
+	}
+	for _, n := range cfg.NATGateways {
+		declared["nat_gateway"] = append(declared["nat_gateway"], n.Name)
+	}
+	for _, x := range cfg.IXs {
+		declared["ix"] = append(declared["ix"], x.Name)
+	}
+	for _, k := range cfg.ServiceKeys {
+		declared["service_key"] = append(declared["service_key"], k.Name)
+	}
+	for _, v := range cfg.VXCs {
+		declared["vxc"] = append(declared["vxc"], v.Name)
+	}
+	for _, resType := range destroyOrder {
+		for _, r := range slices.Backward(state.PendingDeletes) {
+			if r.Type == resType && slices.Contains(declared[resType], r.Name) {
+				targets = append(targets, destroyTarget{resType: r.Type, name: r.Name, uid: r.UID, pending: true})
+			}
+		}
+		for _, name := range slices.Backward(declared[resType]) {
+			uid, ok := state.Lookup(resType, name)
+			if !ok {
+				results = append(results, infra.Result{Type: infra.DisplayType(resType), Name: name, Status: statusNotInState})
+				continue
+			}
+			targets = append(targets, destroyTarget{resType: resType, name: name, uid: uid})
+		}
+	}
+	return targets, results
+}
+
+// destroyDryRunStatus describes what destroy would do with a resource of resType.
+func destroyDryRunStatus(resType string, later bool) string {
+	switch {
+	case later && cancelsAtTermEnd(resType):
+		return statusWouldSchedule
+	case later:
+		return statusWouldKeep
+	case resType == "service_key":
+		return statusWouldDeactivate
+	default:
+		return statusWouldDelete
+	}
+}
+
+// cancelsAtTermEnd reports whether resType can be cancelled at the end of its
+// term rather than deleted immediately.
+func cancelsAtTermEnd(resType string) bool {
+	return resType == "vxc" || resType == "ix"
+}
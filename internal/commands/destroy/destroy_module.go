@@ -0,0 +1,14 @@
+package destroy
+
+import "github.com/spf13/cobra"
+
+// Module implements registry.Module for the destroy command.
+type Module struct{}
+
+func (m *Module) Name() string { return "destroy" }
+
+func (m *Module) RegisterCommands(rootCmd *cobra.Command) {
+	AddCommandsTo(rootCmd)
+}
+
+func NewModule() *Module { return &Module{} }
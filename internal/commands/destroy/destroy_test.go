@@ -0,0 +1,339 @@
+package destroy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/utils"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// destroyCmd builds a minimal cobra.Command with the flags DestroyConfig reads.
+func destroyCmd(file string, yes bool) *cobra.Command {
+	cmd := &cobra.Command{Use: "destroy"}
+	cmd.Flags().StringP("file", "f", "", "")
+	cmd.Flags().String("state", "", "")
+	cmd.Flags().Bool("dry-run", false, "")
+	cmd.Flags().BoolP("yes", "y", false, "")
+	cmd.Flags().Bool("later", false, "")
+	_ = cmd.Flags().Set("file", file)
+	if yes {
+		_ = cmd.Flags().Set("yes", "true")
+	}
+	return cmd
+}
+
+const destroyTestConfig = `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+mcrs:
+  - name: Sydney-MCR
+    location_id: 1
+    speed: 1000
+    term: 12
+vxcs:
+  - name: Port-to-MCR
+    rate_limit: 500
+    term: 12
+    a_end:
+      product_uid: "{{.port.Sydney-Port}}"
+    b_end:
+      product_uid: "{{.mcr.Sydney-MCR}}"
+`
+
+// destroyTestState records every resource in destroyTestConfig.
+func destroyTestState(t *testing.T, configPath string) string {
+	t.Helper()
+	return writeStateFile(t, configPath,
+		infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"},
+		infra.StateResource{Type: "mcr", Name: "Sydney-MCR", UID: "mcr-uid-1"},
+		infra.StateResource{Type: "vxc", Name: "Port-to-MCR", UID: "vxc-uid-1"},
+	)
+}
+
+func TestDestroyConfig_DeletesAndClearsState(t *testing.T) {
+	mockPort, mockMCR, mockVXC := &infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockVXCService{}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "infra.yaml", destroyTestConfig)
+	statePath := destroyTestState(t, f)
+
+	var err error
+	output.CaptureOutput(func() {
+		err = DestroyConfig(destroyCmd(f, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"vxc-uid-1"}, mockVXC.DeleteVXCCalledWith)
+	require.NotNil(t, mockVXC.CapturedDeleteVXC)
+	assert.True(t, mockVXC.CapturedDeleteVXC.DeleteNow)
+	assert.Equal(t, []string{"mcr-uid-1"}, mockMCR.DeleteMCRCalledWith)
+	assert.Equal(t, []string{"port-uid-1"}, mockPort.DeletePortCalledWith)
+
+	state, err := infra.LoadState(statePath)
+	require.NoError(t, err)
+	assert.Empty(t, state.Resources)
+}
+
+func TestDestroyConfig_StopsAtFirstFailureInReverseOrder(t *testing.T) {
+	mockPort := &infra.MockPortService{}
+	mockMCR := &infra.MockMCRService{DeleteMCRErr: fmt.Errorf("MCR has attached services")}
+	mockVXC := &infra.MockVXCService{}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "infra.yaml", destroyTestConfig)
+	statePath := destroyTestState(t, f)
+
+	var err error
+	output.CaptureOutput(func() {
+		err = DestroyConfig(destroyCmd(f, true), nil, true, "table")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to delete MCR "Sydney-MCR"`)
+
+	// The VXC goes first; the port is not attempted once the MCR fails.
+	assert.Equal(t, []string{"vxc-uid-1"}, mockVXC.DeleteVXCCalledWith)
+	assert.Empty(t, mockPort.DeletePortCalledWith)
+
+	state, err := infra.LoadState(statePath)
+	require.NoError(t, err)
+	_, ok := state.Lookup("vxc", "Port-to-MCR")
+	assert.False(t, ok)
+	_, ok = state.Lookup("mcr", "Sydney-MCR")
+	assert.True(t, ok, "a resource that failed to delete stays in the state")
+}
+
+func TestDestroyConfig_DeletesPendingReplacedResources(t *testing.T) {
+	mockPort, mockMCR, mockVXC := &infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockVXCService{}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "infra.yaml", destroyTestConfig)
+	statePath := destroyTestState(t, f)
+	state, err := infra.LoadState(statePath)
+	require.NoError(t, err)
+	state.AddPendingDelete("port", "Sydney-Port", "port-uid-old")
+	state.AddPendingDelete("mve", "Undeclared-MVE", "mve-uid-old")
+	require.NoError(t, infra.SaveState(statePath, state))
+
+	output.CaptureOutput(func() {
+		err = DestroyConfig(destroyCmd(f, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"port-uid-old", "port-uid-1"}, mockPort.DeletePortCalledWith)
+
+	state, err = infra.LoadState(statePath)
+	require.NoError(t, err)
+	assert.Empty(t, state.Resources)
+	require.Len(t, state.PendingDeletes, 1, "only the config's entries are destroyed")
+	assert.Equal(t, "mve-uid-old", state.PendingDeletes[0].UID)
+}
+
+func TestDestroyConfig_DryRunDeletesNothing(t *testing.T) {
+	mockPort, mockVXC := &infra.MockPortService{}, &infra.MockVXCService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "infra.yaml", destroyTestConfig)
+	statePath := destroyTestState(t, f)
+	cmd := destroyCmd(f, false)
+	require.NoError(t, cmd.Flags().Set("dry-run", "true"))
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = DestroyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, statusWouldDelete)
+	assert.Empty(t, mockVXC.DeleteVXCCalledWith)
+	assert.Empty(t, mockPort.DeletePortCalledWith)
+
+	state, err := infra.LoadState(statePath)
+	require.NoError(t, err)
+	assert.Len(t, state.Resources, 3)
+}
+
+func TestDestroyConfig_StateOnly(t *testing.T) {
+	mockMVE := &infra.MockMVEService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, mockMVE, &infra.MockVXCService{})()
+
+	f := writeTempFile(t, "infra.yaml", destroyTestConfig)
+	statePath := writeStateFile(t, f, infra.StateResource{Type: "mve", Name: "Edge-MVE", UID: "mve-uid-1"})
+	cmd := destroyCmd("", true)
+	require.NoError(t, cmd.Flags().Set("state", statePath))
+
+	var err error
+	output.CaptureOutput(func() {
+		err = DestroyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mve-uid-1"}, mockMVE.DeleteMVECalledWith)
+}
+
+func TestDestroyConfig_RequiresFileOrState(t *testing.T) {
+	var err error
+	output.CaptureOutput(func() {
+		err = DestroyConfig(destroyCmd("", true), nil, true, "table")
+	})
+	var cliErr *exitcodes.CLIError
+	require.True(t, errors.As(err, &cliErr))
+	assert.Equal(t, exitcodes.Usage, cliErr.Code)
+}
+
+func TestDestroyConfig_SkipsUntrackedAndAlreadyDeleted(t *testing.T) {
+	mockPort := &infra.MockPortService{GetPortStatus: megaport.STATUS_DECOMMISSIONED}
+	mockMCR, mockVXC := &infra.MockMCRService{}, &infra.MockVXCService{}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "infra.yaml", destroyTestConfig)
+	statePath := writeStateFile(t, f, infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"})
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = DestroyConfig(destroyCmd(f, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, statusNotInState)
+	assert.Contains(t, out, statusAlreadyDeleted)
+	assert.Empty(t, mockVXC.DeleteVXCCalledWith, "entries without a recorded UID are never looked up by name")
+	assert.Empty(t, mockMCR.DeleteMCRCalledWith)
+	assert.Empty(t, mockPort.DeletePortCalledWith)
+
+	state, err := infra.LoadState(statePath)
+	require.NoError(t, err)
+	assert.Empty(t, state.Resources)
+}
+
+func TestDestroyConfig_LaterCancelsVXCsAtEndOfTerm(t *testing.T) {
+	mockPort, mockMCR, mockVXC := &infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockVXCService{}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "infra.yaml", destroyTestConfig)
+	statePath := destroyTestState(t, f)
+	cmd := destroyCmd(f, true)
+	require.NoError(t, cmd.Flags().Set("later", "true"))
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = DestroyConfig(cmd, nil, true, "table")
+	})
+	require.NoError(t, err)
+	require.NotNil(t, mockVXC.CapturedDeleteVXC)
+	assert.False(t, mockVXC.CapturedDeleteVXC.DeleteNow)
+	assert.Empty(t, mockMCR.DeleteMCRCalledWith)
+	assert.Empty(t, mockPort.DeletePortCalledWith)
+	assert.Contains(t, out, "re-run destroy without --later")
+
+	state, err := infra.LoadState(statePath)
+	require.NoError(t, err)
+	assert.Len(t, state.Resources, 3, "nothing is deleted yet, so the state keeps every entry")
+}
+
+func TestDestroyConfig_CancelledPrompt(t *testing.T) {
+	original := utils.GetConfirmPrompt()
+	defer utils.SetConfirmPrompt(original)
+	utils.SetConfirmPrompt(func(string, bool) bool { return false })
+
+	mockVXC := &infra.MockVXCService{}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "infra.yaml", destroyTestConfig)
+	destroyTestState(t, f)
+
+	var err error
+	output.CaptureOutput(func() {
+		err = DestroyConfig(destroyCmd(f, false), nil, true, "table")
+	})
+	var cliErr *exitcodes.CLIError
+	require.True(t, errors.As(err, &cliErr))
+	assert.Equal(t, exitcodes.Cancelled, cliErr.Code)
+	assert.Empty(t, mockVXC.DeleteVXCCalledWith)
+}
+
+func TestDestroyConfig_NetworkServices(t *testing.T) {
+	mockPort := &infra.MockPortService{}
+	mockNAT, mockIX, mockKeys := &infra.MockNATGatewayService{}, &infra.MockIXService{}, &infra.MockServiceKeyService{}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	defer infra.SetupMockNetworkServices(mockNAT, mockIX, mockKeys)()
+
+	f := writeTempFile(t, "infra.yaml", networkServicesTestConfig)
+	statePath := writeStateFile(t, f,
+		infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"},
+		infra.StateResource{Type: "nat_gateway", Name: "Edge-NAT", UID: "nat-uid-1"},
+		infra.StateResource{Type: "ix", Name: "Sydney-IX", UID: "ix-uid-1"},
+		infra.StateResource{Type: "service_key", Name: "Partner-Key", UID: "key-1"},
+	)
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = DestroyConfig(destroyCmd(f, true), nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, statusDeactivated)
+
+	require.Len(t, mockKeys.CapturedUpdates, 1)
+	assert.Equal(t, "key-1", mockKeys.CapturedUpdates[0].Key)
+	assert.False(t, mockKeys.CapturedUpdates[0].Active)
+	assert.Equal(t, []string{"ix-uid-1"}, mockIX.DeleteIXCalledWith)
+	assert.Equal(t, []string{"nat-uid-1"}, mockNAT.DeleteNATGatewayCalledWith)
+	assert.Equal(t, []string{"port-uid-1"}, mockPort.DeletePortCalledWith)
+
+	state, err := infra.LoadState(statePath)
+	require.NoError(t, err)
+	assert.Empty(t, state.Resources)
+}
+
+// writeStateFile writes a state file recording the given entries next to configPath.
+func writeStateFile(t *testing.T, configPath string, entries ...infra.StateResource) string {
+	t.Helper()
+	path := infra.DefaultStatePath(configPath)
+	require.NoError(t, infra.SaveState(path, &infra.State{Version: infra.StateVersion, Resources: entries}))
+	return path
+}
+
+// writeTempFile creates a temp file with the given name suffix and content,
+// returning its path.
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+const networkServicesTestConfig = `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 10000
+    term: 12
+nat_gateways:
+  - name: Edge-NAT
+    location_id: 1
+    speed: 1000
+    term: 12
+    session_count: 1000
+ixs:
+  - name: Sydney-IX
+    product_uid: "{{.port.Sydney-Port}}"
+    network_service_type: Sydney IX
+    asn: 65000
+    mac_address: "00:11:22:33:44:55"
+    rate_limit: 1000
+    vlan: 200
+service_keys:
+  - name: Partner-Key
+    product_uid: "{{.port.Sydney-Port}}"
+    max_speed: 500
+    active: true
+`
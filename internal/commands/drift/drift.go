@@ -0,0 +1,29 @@
+package drift
+
+import (
+	"github.com/megaport/megaport-cli/internal/base/cmdbuilder"
+	"github.com/spf13/cobra"
+)
+
+// AddCommandsTo builds the drift command and adds it to the root command.
+func AddCommandsTo(rootCmd *cobra.Command) {
+	driftCmd := cmdbuilder.NewCommand("drift", "Report resources that no longer match a config file").
+		WithLongDesc("Compare the resources an apply created against their config file entries and report out-of-band changes, such as a rate limit, VLAN, name, cost centre, resource tag or lock state changed in the portal, or a resource deleted outside apply.\n\nWhen any resource has drifted or been deleted, drift exits with code 7 after printing the report, so scheduled jobs can alert on it. Run apply to bring the resources back in line with the config, or update the config to accept the changes.").
+		WithOutputFormatRunFunc(DriftConfig).
+		WithFlagP("file", "f", "", "Path to config file (YAML or JSON)").
+		WithRequiredFlag("file", "Path to config file (YAML or JSON)").
+		WithFlag("state", "", "Path to the apply state file (default: the config file path with a .state.json extension)").
+		WithStringArrayFlag("var", "Set a config variable as name=value (repeatable; overrides --var-file)").
+		WithStringArrayFlag("var-file", "Path to a YAML or JSON file of config variable values (repeatable)").
+		WithExample(`megaport-cli drift -f infrastructure.yaml`).
+		WithExample(`megaport-cli drift -f infrastructure.yaml --output json`).
+		WithExample(`megaport-cli drift -f infrastructure.yaml --state ci/infrastructure.state.json`).
+		WithExample(`megaport-cli drift -f region.yaml --var-file syd.yaml --state syd.state.json`).
+		WithImportantNote("Entries are matched through the UIDs in the apply state file and compared as plan compares them; fields omitted from the config (cost_centre, diversity_zone, resource_tags, locked) are not checked").
+		WithImportantNote("Entries with no UID in the state file are reported as not applied, which is not drift").
+		WithImportantNote("Exit code 7 means drift was detected; other non-zero codes mean the check itself failed").
+		WithRootCmd(rootCmd).
+		Build()
+
+	rootCmd.AddCommand(driftCmd)
+}
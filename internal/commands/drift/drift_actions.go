@@ -0,0 +1,161 @@
+package drift
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/commands/config"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// Drift statuses.
+const (
+	driftInSync     = "in sync"
+	driftChanged    = "drifted"
+	driftDeleted    = "deleted"
+	driftNotApplied = "not applied"
+)
+
+// DriftEntry reports whether an applied resource still matches its config entry.
+// Each change's old value is the declared one and its new value the live one.
+type DriftEntry struct {
+	output.Output `json:"-" header:"-"`
+	Type          string               `json:"type"              header:"Type"`
+	Name          string               `json:"name"              header:"Name"`
+	Status        string               `json:"status"            header:"Status"`
+	UID           string               `json:"uid,omitempty"     header:"UID"`
+	Detail        string               `json:"detail,omitempty"  header:"Detail"`
+	Changes       []output.FieldChange `json:"changes,omitempty" header:"-"`
+}
+
+// DriftConfig is the entry point for `megaport-cli drift`.
+func DriftConfig(cmd *cobra.Command, _ []string, noColor bool, outputFormat string) error {
+	output.SetOutputFormat(outputFormat)
+
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	filePath, _ := cmd.Flags().GetString("file")
+	statePath, _ := cmd.Flags().GetString("state")
+
+	if filePath == "" {
+		output.PrintError("--file is required", noColor)
+		return exitcodes.NewUsageError(fmt.Errorf("--file is required"))
+	}
+	if statePath == "" {
+		statePath = infra.DefaultStatePath(filePath)
+	}
+
+	vars, err := infra.ConfigVars(cmd)
+	if err != nil {
+		output.PrintError("Invalid variables: %v", noColor, err)
+		return exitcodes.NewUsageError(err)
+	}
+	cfg, err := infra.ParseConfigFile(filePath, vars, utils.ProjectSettingsFromCmd(cmd))
+	if err != nil {
+		output.PrintError("Failed to parse config file: %v", noColor, err)
+		return err
+	}
+	state, err := infra.LoadState(statePath)
+	if err != nil {
+		output.PrintError("Failed to load apply state: %v", noColor, err)
+		return err
+	}
+
+	ctx, cancel := utils.ContextFromCmd(cmd)
+	defer cancel()
+
+	spinner := output.PrintLoggingInWithOutput(noColor, outputFormat)
+	client, err := config.Login(ctx)
+	if err != nil {
+		spinner.Stop()
+		output.PrintError("Failed to log in: %v", noColor, err)
+		return err
+	}
+	spinner.Stop()
+
+	listSpinner := output.PrintResourceListing("resource", noColor)
+	plan, err := infra.BuildPlan(ctx, client, cfg, state)
+	listSpinner.Stop()
+	if err != nil {
+		output.PrintError("Failed to check for drift: %v", noColor, err)
+		return err
+	}
+
+	report := driftReport(plan, state)
+	if err := printDrift(report, outputFormat, noColor); err != nil {
+		return err
+	}
+	drifted := 0
+	for _, e := range report {
+		if e.Status == driftChanged || e.Status == driftDeleted {
+			drifted++
+		}
+	}
+	if drifted > 0 {
+		return exitcodes.NewDriftError(fmt.Errorf("drift detected in %d resource(s)", drifted))
+	}
+	return nil
+}
+
+// driftReport turns a plan into a drift report. Drift only concerns resources
+// apply has created: entries with no UID in the state are reported as not
+// applied, and state entries no longer in the config are left out.
+func driftReport(plan []infra.PlanEntry, state *infra.State) []DriftEntry {
+	report := []DriftEntry{}
+	for _, e := range plan {
+		entry := DriftEntry{Type: e.Type, Name: e.Name, UID: e.UID}
+		switch e.Action {
+		case infra.PlanOrphaned:
+			continue
+		case infra.PlanNoOp:
+			entry.Status = driftInSync
+		case infra.PlanCreate:
+			uid, ok := state.Lookup(infra.TemplateType(e.Type), e.Name)
+			if !ok {
+				entry.Status = driftNotApplied
+				break
+			}
+			if e.UID != "" {
+				// An unbought NAT gateway design: recorded, but never ordered.
+				entry.Status, entry.Detail = driftNotApplied, e.Detail
+				break
+			}
+			entry.Status, entry.UID, entry.Detail = driftDeleted, uid, "no longer exists or is no longer active"
+		default:
+			entry.Status = driftChanged
+			labels := make([]string, 0, len(e.Changes))
+			for _, c := range e.Changes {
+				entry.Changes = append(entry.Changes, output.FieldChange{Label: c.Label, OldValue: c.NewValue, NewValue: c.OldValue})
+				labels = append(labels, c.Label)
+			}
+			entry.Detail = strings.Join(labels, ", ")
+		}
+		report = append(report, entry)
+	}
+	return report
+}
+
+// printDrift renders the report, then for table output each drifted resource's
+// changes and a one-line summary.
+func printDrift(report []DriftEntry, outputFormat string, noColor bool) error {
+	if err := output.PrintOutput(report, outputFormat, noColor); err != nil {
+		return err
+	}
+	if outputFormat != utils.FormatTable {
+		return nil
+	}
+	counts := map[string]int{}
+	for _, e := range report {
+		counts[e.Status]++
+		if len(e.Changes) > 0 {
+			output.DisplayChangesWithHeading(fmt.Sprintf("%s %q has drifted from its config (config → live):", e.Type, e.Name), e.Changes, noColor)
+		}
+	}
+	fmt.Println()
+	output.PrintInfo("Drift: %d drifted, %d deleted, %d in sync, %d not applied.", noColor,
+		counts[driftChanged], counts[driftDeleted], counts[driftInSync], counts[driftNotApplied])
+	return nil
+}
@@ -0,0 +1,14 @@
+package drift
+
+import "github.com/spf13/cobra"
+
+// Module implements registry.Module for the drift command.
+type Module struct{}
+
+func (m *Module) Name() string { return "drift" }
+
+func (m *Module) RegisterCommands(rootCmd *cobra.Command) {
+	AddCommandsTo(rootCmd)
+}
+
+func NewModule() *Module { return &Module{} }
@@ -0,0 +1,261 @@
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/utils"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// driftCmd builds a minimal cobra.Command with the flags DriftConfig reads.
+func driftCmd(file string) *cobra.Command {
+	cmd := &cobra.Command{Use: "drift"}
+	cmd.Flags().StringP("file", "f", "", "")
+	cmd.Flags().String("state", "", "")
+	_ = cmd.Flags().Set("file", file)
+	return cmd
+}
+
+// runDriftJSON runs DriftConfig with JSON output and returns the report and error.
+func runDriftJSON(t *testing.T, file string) ([]DriftEntry, error) {
+	t.Helper()
+	return runDriftCmdJSON(t, driftCmd(file))
+}
+
+// runDriftCmdJSON is runDriftJSON for a command built by the caller.
+func runDriftCmdJSON(t *testing.T, cmd *cobra.Command) ([]DriftEntry, error) {
+	t.Helper()
+	var err error
+	out := output.CaptureOutput(func() {
+		err = DriftConfig(cmd, nil, true, "json")
+	})
+	start := strings.Index(out, "[")
+	require.GreaterOrEqual(t, start, 0, out)
+	var report []DriftEntry
+	require.NoError(t, json.Unmarshal([]byte(out[start:]), &report), out)
+	return report, err
+}
+
+// driftTestAccount returns mocks whose resources match planTestConfig.
+func driftTestAccount() (*infra.MockPortService, *infra.MockMCRService, *infra.MockMVEService, *infra.MockVXCService) {
+	mockPort := &infra.MockPortService{ListPortsResult: []*megaport.Port{{
+		UID: "port-uid-1", Name: "Sydney-Port", LocationID: 1, PortSpeed: 1000,
+		ContractTermMonths: 12, CostCentre: "NET-01", ProvisioningStatus: megaport.SERVICE_LIVE,
+	}}}
+	mockMCR := &infra.MockMCRService{
+		ListMCRsResult: []*megaport.MCR{{
+			UID: "mcr-uid-1", Name: "Sydney-MCR", LocationID: 1, PortSpeed: 1000, ContractTermMonths: 12,
+			ProvisioningStatus: megaport.SERVICE_LIVE,
+		}},
+		ResourceTags: map[string]map[string]string{"mcr-uid-1": {"env": "prod"}},
+	}
+	mockVXC := &infra.MockVXCService{ListVXCsResult: []*megaport.VXC{{
+		UID: "vxc-uid-1", Name: "Port-to-MCR", RateLimit: 500, ContractTermMonths: 12,
+		AEndConfiguration:  megaport.VXCEndConfiguration{UID: "port-uid-1", VLAN: 100},
+		BEndConfiguration:  megaport.VXCEndConfiguration{UID: "mcr-uid-1"},
+		ProvisioningStatus: megaport.SERVICE_LIVE,
+	}}}
+	return mockPort, mockMCR, &infra.MockMVEService{}, mockVXC
+}
+
+// writeDriftTestState records every resource in planTestConfig as applied.
+func writeDriftTestState(t *testing.T, file string) {
+	t.Helper()
+	writeStateFile(t, file,
+		infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"},
+		infra.StateResource{Type: "mcr", Name: "Sydney-MCR", UID: "mcr-uid-1"},
+		infra.StateResource{Type: "vxc", Name: "Port-to-MCR", UID: "vxc-uid-1"},
+	)
+}
+
+func TestDriftConfig_InSync(t *testing.T) {
+	defer infra.SetupMockClient(driftTestAccount())()
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+	writeDriftTestState(t, f)
+
+	report, err := runDriftJSON(t, f)
+	require.NoError(t, err)
+	require.Len(t, report, 3)
+	for _, e := range report {
+		assert.Equal(t, driftInSync, e.Status, e.Name)
+	}
+}
+
+func TestDriftConfig_ProjectTagsLeaveUntaggedEntriesInSync(t *testing.T) {
+	mockPort, mockMCR, mockMVE, mockVXC := driftTestAccount()
+	mockPort.ResourceTags = map[string]map[string]string{"port-uid-1": {"owner": "portal"}}
+	defer infra.SetupMockClient(mockPort, mockMCR, mockMVE, mockVXC)()
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+	writeDriftTestState(t, f)
+
+	cmd := driftCmd(f)
+	cmd.SetContext(utils.WithProjectSettings(context.Background(), utils.ProjectSettings{ResourceTags: map[string]string{"env": "dev"}}))
+	report, err := runDriftCmdJSON(t, cmd)
+	require.NoError(t, err, "an entry's own tags win over the project's, and untagged entries are not compared")
+	require.Len(t, report, 3)
+	for _, e := range report {
+		assert.Equal(t, driftInSync, e.Status, e.Name)
+	}
+}
+
+func TestDriftConfig_ChangedFieldsExitWithDriftCode(t *testing.T) {
+	mockPort, mockMCR, mockMVE, mockVXC := driftTestAccount()
+	mockVXC.ListVXCsResult[0].RateLimit = 1000
+	mockVXC.ListVXCsResult[0].AEndConfiguration.VLAN = 101
+	defer infra.SetupMockClient(mockPort, mockMCR, mockMVE, mockVXC)()
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+	writeDriftTestState(t, f)
+
+	report, err := runDriftJSON(t, f)
+	var cliErr *exitcodes.CLIError
+	require.True(t, errors.As(err, &cliErr), err)
+	assert.Equal(t, exitcodes.DriftDetected, cliErr.Code)
+
+	require.Len(t, report, 3)
+	vxc := report[2]
+	assert.Equal(t, driftChanged, vxc.Status)
+	assert.Equal(t, "vxc-uid-1", vxc.UID)
+	assert.Contains(t, vxc.Changes, output.FieldChange{Label: "rate_limit", OldValue: "500", NewValue: "1000"}, "changes read config → live")
+	assert.Contains(t, vxc.Detail, "rate_limit")
+}
+
+func TestDriftConfig_DeletedResource(t *testing.T) {
+	mockPort, mockMCR, mockMVE, mockVXC := driftTestAccount()
+	mockVXC.ListVXCsResult = nil
+	defer infra.SetupMockClient(mockPort, mockMCR, mockMVE, mockVXC)()
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+	writeDriftTestState(t, f)
+
+	report, err := runDriftJSON(t, f)
+	var cliErr *exitcodes.CLIError
+	require.True(t, errors.As(err, &cliErr), err)
+	assert.Equal(t, exitcodes.DriftDetected, cliErr.Code)
+	assert.Equal(t, driftDeleted, report[2].Status)
+	assert.Equal(t, "vxc-uid-1", report[2].UID)
+}
+
+func TestDriftConfig_NotAppliedIsNotDrift(t *testing.T) {
+	defer infra.SetupMockClient(driftTestAccount())()
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+	writeStateFile(t, f, infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"})
+
+	report, err := runDriftJSON(t, f)
+	require.NoError(t, err)
+	assert.Equal(t, driftInSync, report[0].Status)
+	assert.Equal(t, driftNotApplied, report[1].Status)
+	assert.Equal(t, driftNotApplied, report[2].Status)
+}
+
+func TestDriftConfig_LockState(t *testing.T) {
+	mockPort, mockMCR, mockMVE, mockVXC := driftTestAccount()
+	defer infra.SetupMockClient(mockPort, mockMCR, mockMVE, mockVXC)()
+	cfg := strings.Replace(planTestConfig, "    cost_centre: NET-01\n", "    cost_centre: NET-01\n    locked: true\n", 1)
+	f := writeTempFile(t, "infra.yaml", cfg)
+	writeDriftTestState(t, f)
+
+	report, err := runDriftJSON(t, f)
+	require.Error(t, err)
+	assert.Equal(t, driftChanged, report[0].Status)
+	assert.Equal(t, []output.FieldChange{{Label: "locked", OldValue: "true", NewValue: "false"}}, report[0].Changes)
+
+	mockPort.ListPortsResult[0].Locked = true
+	report, err = runDriftJSON(t, f)
+	require.NoError(t, err)
+	assert.Equal(t, driftInSync, report[0].Status)
+}
+
+func TestDriftConfig_TableSummary(t *testing.T) {
+	mockPort, mockMCR, mockMVE, mockVXC := driftTestAccount()
+	mockPort.ListPortsResult[0].CostCentre = "NET-02"
+	defer infra.SetupMockClient(mockPort, mockMCR, mockMVE, mockVXC)()
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+	writeDriftTestState(t, f)
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = DriftConfig(driftCmd(f), nil, true, "table")
+	})
+	require.Error(t, err)
+	assert.Contains(t, out, `Port "Sydney-Port" has drifted from its config`)
+	assert.Contains(t, out, "Drift: 1 drifted, 0 deleted, 2 in sync, 0 not applied.")
+}
+
+func TestDriftConfig_UnboughtNATGatewayIsNotApplied(t *testing.T) {
+	mockNAT := &infra.MockNATGatewayService{ListNATGatewaysResult: []*megaport.NATGateway{{
+		ProductUID: "nat-uid-1", ProductName: "Edge-NAT", LocationID: 1, Speed: 1000, Term: 12,
+		ProvisioningStatus: megaport.STATUS_DESIGN,
+	}}}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	defer infra.SetupMockNetworkServices(mockNAT, &infra.MockIXService{}, &infra.MockServiceKeyService{})()
+
+	cfg := `
+nat_gateways:
+  - name: Edge-NAT
+    location_id: 1
+    speed: 1000
+    term: 12
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	writeStateFile(t, f, infra.StateResource{Type: "nat_gateway", Name: "Edge-NAT", UID: "nat-uid-1"})
+
+	report, err := runDriftJSON(t, f)
+	require.NoError(t, err)
+	require.Len(t, report, 1)
+	assert.Equal(t, driftNotApplied, report[0].Status)
+	assert.Equal(t, "nat-uid-1", report[0].UID)
+}
+
+const planTestConfig = `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+    cost_centre: NET-01
+mcrs:
+  - name: Sydney-MCR
+    location_id: 1
+    speed: 1000
+    term: 12
+    resource_tags:
+      env: prod
+vxcs:
+  - name: Port-to-MCR
+    rate_limit: 500
+    term: 12
+    a_end:
+      product_uid: "{{.port.Sydney-Port}}"
+      vlan: 100
+    b_end:
+      product_uid: "{{.mcr.Sydney-MCR}}"
+`
+
+// writeStateFile writes a state file recording the given entries next to configPath.
+func writeStateFile(t *testing.T, configPath string, entries ...infra.StateResource) string {
+	t.Helper()
+	path := infra.DefaultStatePath(configPath)
+	require.NoError(t, infra.SaveState(path, &infra.State{Version: infra.StateVersion, Resources: entries}))
+	return path
+}
+
+// writeTempFile creates a temp file with the given name suffix and content,
+// returning its path.
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
@@ -0,0 +1,31 @@
+//go:build !js && !wasm
+
+package exporter
+
+import (
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/base/cmdbuilder"
+	"github.com/spf13/cobra"
+)
+
+// AddCommandsTo builds the exporter command and adds it to the root command.
+func AddCommandsTo(rootCmd *cobra.Command) {
+	exporterCmd := cmdbuilder.NewCommand("exporter", "Serve account resource metrics for Prometheus").
+		WithColorAwareRunFunc(RunExporter).
+		WithFlag("listen", ":9810", "Address to serve metrics on").
+		WithDurationFlag("interval", 60*time.Second, "How often to poll the Megaport API (e.g. 30s, 5m)").
+		WithBoolFlag("include-inactive", false, "Include inactive/decommissioned resources").
+		WithFlag("telemetry-types", "BITS,PACKETS", "Comma-separated NAT Gateway telemetry types to export (e.g. BITS,PACKETS,SPEED); empty to skip telemetry").
+		WithLongDesc("Run a Prometheus exporter for the resources in your account.\n\nThe exporter polls the same APIs as megaport-cli status and megaport-cli nat-gateway telemetry every --interval and serves the results as gauges at /metrics, in the Prometheus text format or, when the scraper asks for it, OpenMetrics. It runs until interrupted.\n\nMetrics exported:\n- megaport_service_provisioning_state: 1 for each service's current provisioning state\n- megaport_service_live: 1 when a service is LIVE, otherwise 0\n- megaport_service_speed_mbps: speeds of ports, MCRs and NAT Gateways\n- megaport_vxc_rate_limit_mbps and megaport_ix_rate_limit_mbps: VXC and IX rate limits\n- megaport_service_contract_end_timestamp_seconds: contract end dates, as Unix times\n- megaport_nat_gateway_telemetry: the latest sample of each NAT Gateway telemetry series\n- megaport_exporter_*: when the exporter last polled, whether it succeeded and how long it took").
+		WithExample("megaport-cli exporter").
+		WithExample("megaport-cli exporter --listen :9810 --interval 5m").
+		WithExample("megaport-cli exporter --listen 127.0.0.1:9810 --telemetry-types BITS,PACKETS,SPEED").
+		WithExample("megaport-cli exporter --telemetry-types \"\"").
+		WithImportantNote("Metrics describe the last successful poll; if a poll fails, megaport_exporter_last_poll_success drops to 0 and the previous values are kept").
+		WithImportantNote("NAT Gateway telemetry is requested only for LIVE NAT Gateways, one request per gateway per poll").
+		WithRootCmd(rootCmd).
+		Build()
+
+	rootCmd.AddCommand(exporterCmd)
+}
@@ -0,0 +1,298 @@
+//go:build !js && !wasm
+
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/commands/config"
+	"github.com/megaport/megaport-cli/internal/utils"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/spf13/cobra"
+)
+
+// shutdownTimeout bounds how long in-flight scrapes get to finish on exit.
+const shutdownTimeout = 5 * time.Second
+
+// authorizeFunc refreshes the client's access token if it has expired. The
+// exporter outlives a token, so it is called before every poll.
+var authorizeFunc = func(ctx context.Context, client *megaport.Client) error {
+	_, err := client.Authorize(ctx)
+	return err
+}
+
+var listPortsFunc = func(ctx context.Context, client *megaport.Client) ([]*megaport.Port, error) {
+	return client.PortService.ListPorts(ctx)
+}
+
+var listMCRsFunc = func(ctx context.Context, client *megaport.Client, includeInactive bool) ([]*megaport.MCR, error) {
+	return client.MCRService.ListMCRs(ctx, &megaport.ListMCRsRequest{IncludeInactive: includeInactive})
+}
+
+var listMVEsFunc = func(ctx context.Context, client *megaport.Client, includeInactive bool) ([]*megaport.MVE, error) {
+	return client.MVEService.ListMVEs(ctx, &megaport.ListMVEsRequest{IncludeInactive: includeInactive})
+}
+
+var listVXCsFunc = func(ctx context.Context, client *megaport.Client, includeInactive bool) ([]*megaport.VXC, error) {
+	return client.VXCService.ListVXCs(ctx, &megaport.ListVXCsRequest{IncludeInactive: includeInactive})
+}
+
+var listIXsFunc = func(ctx context.Context, client *megaport.Client, includeInactive bool) ([]*megaport.IX, error) {
+	return client.IXService.ListIXs(ctx, &megaport.ListIXsRequest{IncludeInactive: includeInactive})
+}
+
+var listNATGatewaysFunc = func(ctx context.Context, client *megaport.Client) ([]*megaport.NATGateway, error) {
+	return client.NATGatewayService.ListNATGateways(ctx)
+}
+
+var getNATGatewayTelemetryFunc = func(ctx context.Context, client *megaport.Client, req *megaport.GetNATGatewayTelemetryRequest) (*megaport.ServiceTelemetryResponse, error) {
+	return client.NATGatewayService.GetNATGatewayTelemetry(ctx, req)
+}
+
+// RunExporter polls the Megaport API every --interval and serves the results
+// as Prometheus metrics on --listen until interrupted.
+func RunExporter(cmd *cobra.Command, args []string, noColor bool) error {
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	listen, _ := cmd.Flags().GetString("listen")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	includeInactive, _ := cmd.Flags().GetBool("include-inactive")
+	telemetryTypes, _ := cmd.Flags().GetString("telemetry-types")
+
+	if strings.TrimSpace(listen) == "" {
+		return exitcodes.NewUsageError(fmt.Errorf("--listen must not be empty"))
+	}
+	if interval <= 0 {
+		return exitcodes.NewUsageError(fmt.Errorf("--interval must be greater than 0"))
+	}
+
+	loginCtx, cancel := utils.ContextFromCmd(cmd)
+	client, err := config.Login(loginCtx)
+	cancel()
+	if err != nil {
+		output.PrintError("Failed to log in: %v", noColor, err)
+		return fmt.Errorf("failed to log in: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Listen before the first poll, so a port already in use fails at once
+	// rather than after a poll that can take until --interval.
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		output.PrintError("Failed to listen on %s: %v", noColor, listen, err)
+		return fmt.Errorf("failed to listen on %s: %w", listen, err)
+	}
+	e := newExporter(client, includeInactive, parseTelemetryTypes(telemetryTypes), noColor)
+	e.poll(ctx, interval)
+
+	server := &http.Server{Handler: e.handler(), ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+	output.PrintSuccess("Serving metrics on http://%s/metrics every %s. Press Ctrl+C to stop.", noColor, listener.Addr(), interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				return fmt.Errorf("failed to stop metrics server: %w", err)
+			}
+			output.PrintInfo("Exporter stopped.", noColor)
+			return nil
+		case err := <-serveErr:
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			output.PrintError("Metrics server failed: %v", noColor, err)
+			return fmt.Errorf("metrics server failed: %w", err)
+		case <-ticker.C:
+			e.poll(ctx, interval)
+		}
+	}
+}
+
+// parseTelemetryTypes splits a comma-separated --telemetry-types value into
+// upper-case types, dropping empty entries.
+func parseTelemetryTypes(s string) []string {
+	var types []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.ToUpper(strings.TrimSpace(t)); t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// exporter polls the Megaport API and serves the last successful poll's
+// metrics.
+type exporter struct {
+	client          *megaport.Client
+	includeInactive bool
+	telemetryTypes  []string
+	noColor         bool
+
+	mu          sync.RWMutex
+	families    []metricFamily
+	success     bool
+	lastPoll    time.Time
+	lastSuccess time.Time
+	duration    time.Duration
+}
+
+func newExporter(client *megaport.Client, includeInactive bool, telemetryTypes []string, noColor bool) *exporter {
+	return &exporter{
+		client:          client,
+		includeInactive: includeInactive,
+		telemetryTypes:  telemetryTypes,
+		noColor:         noColor,
+	}
+}
+
+// poll fetches every resource once, giving up after timeout, and records the
+// result. A failed poll keeps the previous poll's metrics.
+func (e *exporter) poll(ctx context.Context, timeout time.Duration) {
+	start := time.Now()
+	pollCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := e.fetch(pollCtx)
+	if err != nil {
+		output.PrintError("Poll failed: %v", e.noColor, err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastPoll = start
+	e.duration = time.Since(start)
+	e.success = err == nil
+	if err == nil {
+		e.families = resourceMetrics(res)
+		e.lastSuccess = start
+	}
+}
+
+// fetch lists every resource type in parallel, then fetches telemetry for
+// each LIVE NAT Gateway. Telemetry failures are warnings, not poll failures.
+func (e *exporter) fetch(ctx context.Context) (exporterResources, error) {
+	if err := authorizeFunc(ctx, e.client); err != nil {
+		return exporterResources{}, fmt.Errorf("failed to refresh access token: %w", err)
+	}
+
+	var (
+		res  exporterResources
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	run := func(what string, list func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := list(); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, fmt.Errorf("%s: %w", what, err))
+			}
+		}()
+	}
+	run("ports", func() (err error) { res.ports, err = listPortsFunc(ctx, e.client); return })
+	run("MCRs", func() (err error) { res.mcrs, err = listMCRsFunc(ctx, e.client, e.includeInactive); return })
+	run("MVEs", func() (err error) { res.mves, err = listMVEsFunc(ctx, e.client, e.includeInactive); return })
+	run("VXCs", func() (err error) { res.vxcs, err = listVXCsFunc(ctx, e.client, e.includeInactive); return })
+	run("IXs", func() (err error) { res.ixs, err = listIXsFunc(ctx, e.client, e.includeInactive); return })
+	run("NAT Gateways", func() (err error) { res.natGateways, err = listNATGatewaysFunc(ctx, e.client); return })
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return exporterResources{}, errors.Join(errs...)
+	}
+
+	// Filter inactive ports and NAT Gateways client-side (their list calls
+	// have no IncludeInactive param).
+	if !e.includeInactive {
+		var activePorts []*megaport.Port
+		for _, p := range res.ports {
+			if p != nil && utils.IsActiveStatus(p.ProvisioningStatus) {
+				activePorts = append(activePorts, p)
+			}
+		}
+		res.ports = activePorts
+
+		var activeNATGateways []*megaport.NATGateway
+		for _, g := range res.natGateways {
+			if g != nil && utils.IsActiveStatus(g.ProvisioningStatus) {
+				activeNATGateways = append(activeNATGateways, g)
+			}
+		}
+		res.natGateways = activeNATGateways
+	}
+
+	if len(e.telemetryTypes) > 0 {
+		res.telemetry = map[string]*megaport.ServiceTelemetryResponse{}
+		days := int32(1)
+		for _, g := range res.natGateways {
+			if g == nil || g.ProvisioningStatus != megaport.SERVICE_LIVE {
+				continue
+			}
+			resp, err := getNATGatewayTelemetryFunc(ctx, e.client, &megaport.GetNATGatewayTelemetryRequest{
+				ProductUID: g.ProductUID,
+				Types:      e.telemetryTypes,
+				Days:       &days,
+			})
+			if err != nil {
+				output.PrintWarning("Failed to fetch telemetry for NAT Gateway %s: %v", e.noColor, g.ProductUID, err)
+				continue
+			}
+			res.telemetry[g.ProductUID] = resp
+		}
+	}
+
+	return res, nil
+}
+
+// handler serves the metrics at /metrics.
+func (e *exporter) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", e.serveMetrics)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = fmt.Fprintln(w, "Megaport exporter. Metrics are served at /metrics.")
+	})
+	return mux
+}
+
+func (e *exporter) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	families := append(append([]metricFamily{}, e.families...), pollMetrics(e.success, e.lastPoll, e.lastSuccess, e.duration)...)
+	e.mu.RUnlock()
+
+	openMetrics := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+	if openMetrics {
+		w.Header().Set("Content-Type", openMetricsContentType)
+	} else {
+		w.Header().Set("Content-Type", textContentType)
+	}
+	_ = writeMetrics(w, families, openMetrics)
+}
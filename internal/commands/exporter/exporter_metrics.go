@@ -0,0 +1,313 @@
+//go:build !js && !wasm
+
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	megaport "github.com/megaport/megaportgo"
+)
+
+// Content types served at /metrics. OpenMetrics is served only to scrapers
+// that ask for it in their Accept header.
+const (
+	textContentType        = "text/plain; version=0.0.4; charset=utf-8"
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// label is one name="value" pair on a sample.
+type label struct {
+	name  string
+	value string
+}
+
+// sample is one value of a metric family.
+type sample struct {
+	labels []label
+	value  float64
+}
+
+// metricFamily is a gauge and its samples.
+type metricFamily struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+// writeMetrics writes families in the Prometheus text exposition format, or
+// in OpenMetrics when openMetrics is set. Families without samples are skipped.
+func writeMetrics(w io.Writer, families []metricFamily, openMetrics bool) error {
+	var b strings.Builder
+	for _, f := range families {
+		if len(f.samples) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", f.name)
+		for _, s := range f.samples {
+			b.WriteString(f.name)
+			if len(s.labels) > 0 {
+				b.WriteByte('{')
+				for i, l := range s.labels {
+					if i > 0 {
+						b.WriteByte(',')
+					}
+					fmt.Fprintf(&b, "%s=\"%s\"", l.name, escapeLabelValue(l.value))
+				}
+				b.WriteByte('}')
+			}
+			b.WriteByte(' ')
+			b.WriteString(formatValue(s.value))
+			b.WriteByte('\n')
+		}
+	}
+	if openMetrics {
+		b.WriteString("# EOF\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	case v == math.Trunc(v) && math.Abs(v) < 1e15:
+		// Whole numbers such as timestamps and speeds print without an exponent.
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// exporterResources holds the resources fetched by one poll.
+type exporterResources struct {
+	ports       []*megaport.Port
+	mcrs        []*megaport.MCR
+	mves        []*megaport.MVE
+	vxcs        []*megaport.VXC
+	ixs         []*megaport.IX
+	natGateways []*megaport.NATGateway
+
+	// telemetry maps NAT Gateway UIDs to their telemetry.
+	telemetry map[string]*megaport.ServiceTelemetryResponse
+}
+
+// service is what every resource metric needs to know about a resource.
+type service struct {
+	typ    string
+	uid    string
+	name   string
+	status string
+}
+
+func (s service) labels(extra ...label) []label {
+	return append([]label{{"uid", s.uid}, {"name", s.name}, {"type", s.typ}}, extra...)
+}
+
+// resourceMetrics builds the resource metric families from one poll's
+// resources.
+func resourceMetrics(res exporterResources) []metricFamily {
+	state := metricFamily{
+		name: "megaport_service_provisioning_state",
+		help: "Provisioning state of a service; 1 for the state it is in.",
+	}
+	live := metricFamily{
+		name: "megaport_service_live",
+		help: "Whether a service is LIVE (1) or not (0).",
+	}
+	speed := metricFamily{
+		name: "megaport_service_speed_mbps",
+		help: "Speed of a port, MCR or NAT Gateway in Mbps.",
+	}
+	vxcRateLimit := metricFamily{
+		name: "megaport_vxc_rate_limit_mbps",
+		help: "Rate limit of a VXC in Mbps.",
+	}
+	ixRateLimit := metricFamily{
+		name: "megaport_ix_rate_limit_mbps",
+		help: "Rate limit of an IX in Mbps.",
+	}
+	contractEnd := metricFamily{
+		name: "megaport_service_contract_end_timestamp_seconds",
+		help: "Contract end date of a service as a Unix timestamp.",
+	}
+	telemetry := metricFamily{
+		name: "megaport_nat_gateway_telemetry",
+		help: "Latest sample of each NAT Gateway telemetry series.",
+	}
+
+	addService := func(s service, end time.Time) {
+		state.samples = append(state.samples, sample{labels: s.labels(label{"state", s.status}), value: 1})
+		isLive := 0.0
+		if s.status == "LIVE" {
+			isLive = 1
+		}
+		live.samples = append(live.samples, sample{labels: s.labels(), value: isLive})
+		if !end.IsZero() {
+			contractEnd.samples = append(contractEnd.samples, sample{labels: s.labels(), value: float64(end.Unix())})
+		}
+	}
+	addSpeed := func(s service, mbps int) {
+		speed.samples = append(speed.samples, sample{labels: s.labels(), value: float64(mbps)})
+	}
+
+	for _, p := range res.ports {
+		if p == nil {
+			continue
+		}
+		s := service{"Port", p.UID, p.Name, p.ProvisioningStatus}
+		addService(s, timeOf(p.ContractEndDate))
+		addSpeed(s, p.PortSpeed)
+	}
+	for _, m := range res.mcrs {
+		if m == nil {
+			continue
+		}
+		s := service{"MCR", m.UID, m.Name, m.ProvisioningStatus}
+		addService(s, timeOf(m.ContractEndDate))
+		addSpeed(s, m.PortSpeed)
+	}
+	for _, m := range res.mves {
+		if m == nil {
+			continue
+		}
+		addService(service{"MVE", m.UID, m.Name, m.ProvisioningStatus}, timeOf(m.ContractEndDate))
+	}
+	for _, v := range res.vxcs {
+		if v == nil {
+			continue
+		}
+		addService(service{"VXC", v.UID, v.Name, v.ProvisioningStatus}, timeOf(v.ContractEndDate))
+		vxcRateLimit.samples = append(vxcRateLimit.samples, sample{
+			labels: []label{{"uid", v.UID}, {"name", v.Name}, {"a_end_uid", v.AEndConfiguration.UID}, {"b_end_uid", v.BEndConfiguration.UID}},
+			value:  float64(v.RateLimit),
+		})
+	}
+	for _, i := range res.ixs {
+		if i == nil {
+			continue
+		}
+		addService(service{"IX", i.ProductUID, i.ProductName, i.ProvisioningStatus}, time.Time{})
+		ixRateLimit.samples = append(ixRateLimit.samples, sample{
+			labels: []label{{"uid", i.ProductUID}, {"name", i.ProductName}},
+			value:  float64(i.RateLimit),
+		})
+	}
+	for _, g := range res.natGateways {
+		if g == nil {
+			continue
+		}
+		s := service{"NAT Gateway", g.ProductUID, g.ProductName, g.ProvisioningStatus}
+		addService(s, parseContractEnd(g.ContractEndDate))
+		addSpeed(s, g.Speed)
+
+		resp := res.telemetry[g.ProductUID]
+		if resp == nil {
+			continue
+		}
+		for _, series := range resp.Data {
+			if series == nil || len(series.Samples) == 0 {
+				continue
+			}
+			typ := series.Type
+			if typ == "" {
+				typ = resp.Type
+			}
+			telemetry.samples = append(telemetry.samples, sample{
+				labels: []label{
+					{"uid", g.ProductUID}, {"name", g.ProductName},
+					{"type", typ}, {"subtype", series.Subtype}, {"unit", series.Unit.Name},
+				},
+				value: latestSample(series.Samples).Value,
+			})
+		}
+	}
+
+	return []metricFamily{state, live, speed, vxcRateLimit, ixRateLimit, contractEnd, telemetry}
+}
+
+// latestSample returns the sample with the latest timestamp. samples must
+// not be empty.
+func latestSample(samples []megaport.TelemetrySample) megaport.TelemetrySample {
+	latest := samples[0]
+	for _, s := range samples[1:] {
+		if s.Timestamp > latest.Timestamp {
+			latest = s
+		}
+	}
+	return latest
+}
+
+// pollMetrics builds the exporter's own metric families. lastSuccess is zero
+// until a poll has succeeded.
+func pollMetrics(success bool, lastPoll, lastSuccess time.Time, duration time.Duration) []metricFamily {
+	ok := 0.0
+	if success {
+		ok = 1
+	}
+	families := []metricFamily{
+		{
+			name:    "megaport_exporter_last_poll_success",
+			help:    "Whether the last poll of the Megaport API succeeded (1) or failed (0).",
+			samples: []sample{{value: ok}},
+		},
+		{
+			name:    "megaport_exporter_last_poll_timestamp_seconds",
+			help:    "Unix timestamp of the last poll of the Megaport API.",
+			samples: []sample{{value: float64(lastPoll.Unix())}},
+		},
+		{
+			name:    "megaport_exporter_poll_duration_seconds",
+			help:    "How long the last poll of the Megaport API took in seconds.",
+			samples: []sample{{value: duration.Seconds()}},
+		},
+	}
+	if !lastSuccess.IsZero() {
+		families = append(families, metricFamily{
+			name:    "megaport_exporter_last_success_timestamp_seconds",
+			help:    "Unix timestamp of the last successful poll of the Megaport API.",
+			samples: []sample{{value: float64(lastSuccess.Unix())}},
+		})
+	}
+	return families
+}
+
+// parseContractEnd parses a NAT Gateway's contract end date, which the API
+// returns as a string. It returns the zero time when s is empty or unparsable.
+func parseContractEnd(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func timeOf(t *megaport.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time
+}
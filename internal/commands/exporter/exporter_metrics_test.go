@@ -0,0 +1,144 @@
+//go:build !js && !wasm
+
+package exporter
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	megaport "github.com/megaport/megaportgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func render(t *testing.T, families []metricFamily, openMetrics bool) string {
+	t.Helper()
+	var b strings.Builder
+	require.NoError(t, writeMetrics(&b, families, openMetrics))
+	return b.String()
+}
+
+func TestWriteMetrics_Text(t *testing.T) {
+	out := render(t, []metricFamily{
+		{
+			name: "megaport_test",
+			help: "A test gauge.\nSecond line.",
+			samples: []sample{
+				{labels: []label{{"name", `Port "A"`}, {"path", `C:\x`}}, value: 10000},
+				{value: 0.5},
+			},
+		},
+		{name: "megaport_empty", help: "No samples."},
+	}, false)
+
+	assert.Equal(t, `# HELP megaport_test A test gauge.\nSecond line.
+# TYPE megaport_test gauge
+megaport_test{name="Port \"A\"",path="C:\\x"} 10000
+megaport_test 0.5
+`, out, "families without samples are skipped")
+}
+
+func TestWriteMetrics_OpenMetrics(t *testing.T) {
+	out := render(t, []metricFamily{{name: "megaport_test", help: "h", samples: []sample{{value: 1}}}}, true)
+	assert.True(t, strings.HasSuffix(out, "megaport_test 1\n# EOF\n"))
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "+Inf", formatValue(math.Inf(1)))
+	assert.Equal(t, "-Inf", formatValue(math.Inf(-1)))
+	assert.Equal(t, "NaN", formatValue(math.NaN()))
+	assert.Equal(t, "1.7976931348623157e+308", formatValue(math.MaxFloat64))
+	assert.Equal(t, "1798761600", formatValue(1798761600))
+}
+
+// family returns the family named name, failing the test if there is none.
+func family(t *testing.T, families []metricFamily, name string) metricFamily {
+	t.Helper()
+	for _, f := range families {
+		if f.name == name {
+			return f
+		}
+	}
+	t.Fatalf("no %s family", name)
+	return metricFamily{}
+}
+
+func TestResourceMetrics(t *testing.T) {
+	end := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	families := resourceMetrics(exporterResources{
+		ports: []*megaport.Port{
+			{UID: "port-1", Name: "Port One", ProvisioningStatus: "LIVE", PortSpeed: 10000, ContractEndDate: &megaport.Time{Time: end}},
+		},
+		mves: []*megaport.MVE{{UID: "mve-1", Name: "MVE One", ProvisioningStatus: "CONFIGURED"}},
+		vxcs: []*megaport.VXC{{
+			UID: "vxc-1", Name: "VXC One", ProvisioningStatus: "LIVE", RateLimit: 500,
+			AEndConfiguration: megaport.VXCEndConfiguration{UID: "port-1"},
+			BEndConfiguration: megaport.VXCEndConfiguration{UID: "mcr-1"},
+		}},
+		ixs:         []*megaport.IX{{ProductUID: "ix-1", ProductName: "IX One", ProvisioningStatus: "LIVE", RateLimit: 1000}},
+		natGateways: []*megaport.NATGateway{{ProductUID: "nat-1", ProductName: "NAT One", ProvisioningStatus: "LIVE", Speed: 1000, ContractEndDate: "2027-01-01T00:00:00Z"}},
+	})
+
+	state := family(t, families, "megaport_service_provisioning_state")
+	require.Len(t, state.samples, 5)
+	assert.Equal(t, []label{{"uid", "mve-1"}, {"name", "MVE One"}, {"type", "MVE"}, {"state", "CONFIGURED"}}, state.samples[1].labels)
+
+	live := family(t, families, "megaport_service_live")
+	assert.Equal(t, 1.0, live.samples[0].value)
+	assert.Equal(t, 0.0, live.samples[1].value, "CONFIGURED is not live")
+
+	speed := family(t, families, "megaport_service_speed_mbps")
+	require.Len(t, speed.samples, 2, "only ports, MCRs and NAT Gateways have a speed")
+	assert.Equal(t, 10000.0, speed.samples[0].value)
+	assert.Equal(t, "NAT Gateway", speed.samples[1].labels[2].value)
+
+	vxc := family(t, families, "megaport_vxc_rate_limit_mbps")
+	assert.Equal(t, []sample{{
+		labels: []label{{"uid", "vxc-1"}, {"name", "VXC One"}, {"a_end_uid", "port-1"}, {"b_end_uid", "mcr-1"}},
+		value:  500,
+	}}, vxc.samples)
+
+	ix := family(t, families, "megaport_ix_rate_limit_mbps")
+	assert.Equal(t, 1000.0, ix.samples[0].value)
+
+	contract := family(t, families, "megaport_service_contract_end_timestamp_seconds")
+	require.Len(t, contract.samples, 2, "resources without an end date are skipped")
+	assert.Equal(t, float64(end.Unix()), contract.samples[0].value)
+	assert.Equal(t, float64(end.Unix()), contract.samples[1].value)
+}
+
+func TestResourceMetrics_Telemetry(t *testing.T) {
+	families := resourceMetrics(exporterResources{
+		natGateways: []*megaport.NATGateway{{ProductUID: "nat-1", ProductName: "NAT One", ProvisioningStatus: "LIVE"}},
+		telemetry: map[string]*megaport.ServiceTelemetryResponse{
+			"nat-1": {Data: []*megaport.TelemetryMetricData{
+				{
+					Type: "BITS", Subtype: "In", Unit: megaport.TelemetryUnit{Name: "Mbps"},
+					Samples: []megaport.TelemetrySample{{Timestamp: 2000, Value: 20}, {Timestamp: 3000, Value: 30}, {Timestamp: 1000, Value: 10}},
+				},
+				{Type: "PACKETS", Subtype: "Out", Samples: nil},
+			}},
+		},
+	})
+
+	telemetry := family(t, families, "megaport_nat_gateway_telemetry")
+	require.Len(t, telemetry.samples, 1, "series without samples are skipped")
+	assert.Equal(t, sample{
+		labels: []label{{"uid", "nat-1"}, {"name", "NAT One"}, {"type", "BITS"}, {"subtype", "In"}, {"unit", "Mbps"}},
+		value:  30,
+	}, telemetry.samples[0], "the latest sample is exported")
+}
+
+func TestPollMetrics(t *testing.T) {
+	poll := time.Unix(1800000000, 0)
+	families := pollMetrics(false, poll, time.Time{}, 1500*time.Millisecond)
+
+	assert.Len(t, families, 3, "there is no last success until a poll succeeds")
+	assert.Equal(t, 0.0, family(t, families, "megaport_exporter_last_poll_success").samples[0].value)
+	assert.Equal(t, 1.5, family(t, families, "megaport_exporter_poll_duration_seconds").samples[0].value)
+
+	families = pollMetrics(true, poll, poll, time.Second)
+	assert.Equal(t, 1800000000.0, family(t, families, "megaport_exporter_last_success_timestamp_seconds").samples[0].value)
+}
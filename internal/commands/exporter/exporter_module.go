@@ -0,0 +1,23 @@
+//go:build !js && !wasm
+
+package exporter
+
+import "github.com/spf13/cobra"
+
+// Module implements the registry.Module interface for the exporter command.
+type Module struct{}
+
+// Name returns the module name.
+func (m *Module) Name() string {
+	return "exporter"
+}
+
+// RegisterCommands adds the exporter command to the root command.
+func (m *Module) RegisterCommands(rootCmd *cobra.Command) {
+	AddCommandsTo(rootCmd)
+}
+
+// NewModule creates a new exporter module.
+func NewModule() *Module {
+	return &Module{}
+}
@@ -0,0 +1,254 @@
+//go:build !js && !wasm
+
+package exporter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/testutil"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAPI replaces every API call with one returning a LIVE port and NAT
+// Gateway, and restores them when the test ends.
+func stubAPI(t *testing.T) {
+	t.Helper()
+	origAuthorize, origPorts, origMCRs, origMVEs := authorizeFunc, listPortsFunc, listMCRsFunc, listMVEsFunc
+	origVXCs, origIXs, origNATs, origTelemetry := listVXCsFunc, listIXsFunc, listNATGatewaysFunc, getNATGatewayTelemetryFunc
+	t.Cleanup(func() {
+		authorizeFunc, listPortsFunc, listMCRsFunc, listMVEsFunc = origAuthorize, origPorts, origMCRs, origMVEs
+		listVXCsFunc, listIXsFunc, listNATGatewaysFunc, getNATGatewayTelemetryFunc = origVXCs, origIXs, origNATs, origTelemetry
+	})
+
+	authorizeFunc = func(context.Context, *megaport.Client) error { return nil }
+	listPortsFunc = func(context.Context, *megaport.Client) ([]*megaport.Port, error) {
+		return []*megaport.Port{
+			{UID: "port-1", Name: "Port One", ProvisioningStatus: "LIVE", PortSpeed: 10000},
+			{UID: "port-2", Name: "Port Two", ProvisioningStatus: megaport.STATUS_DECOMMISSIONED, PortSpeed: 1000},
+		}, nil
+	}
+	listMCRsFunc = func(context.Context, *megaport.Client, bool) ([]*megaport.MCR, error) { return nil, nil }
+	listMVEsFunc = func(context.Context, *megaport.Client, bool) ([]*megaport.MVE, error) { return nil, nil }
+	listVXCsFunc = func(context.Context, *megaport.Client, bool) ([]*megaport.VXC, error) { return nil, nil }
+	listIXsFunc = func(context.Context, *megaport.Client, bool) ([]*megaport.IX, error) { return nil, nil }
+	listNATGatewaysFunc = func(context.Context, *megaport.Client) ([]*megaport.NATGateway, error) {
+		return []*megaport.NATGateway{
+			{ProductUID: "nat-1", ProductName: "NAT One", ProvisioningStatus: "LIVE", Speed: 1000},
+			{ProductUID: "nat-2", ProductName: "NAT Two", ProvisioningStatus: "DEPLOYABLE", Speed: 1000},
+		}, nil
+	}
+	getNATGatewayTelemetryFunc = func(_ context.Context, _ *megaport.Client, req *megaport.GetNATGatewayTelemetryRequest) (*megaport.ServiceTelemetryResponse, error) {
+		return &megaport.ServiceTelemetryResponse{Data: []*megaport.TelemetryMetricData{
+			{Type: req.Types[0], Subtype: "In", Samples: []megaport.TelemetrySample{{Timestamp: 1, Value: 42}}},
+		}}, nil
+	}
+}
+
+func TestExporterPoll(t *testing.T) {
+	stubAPI(t)
+	var telemetryReqs []*megaport.GetNATGatewayTelemetryRequest
+	getTelemetry := getNATGatewayTelemetryFunc
+	getNATGatewayTelemetryFunc = func(ctx context.Context, c *megaport.Client, req *megaport.GetNATGatewayTelemetryRequest) (*megaport.ServiceTelemetryResponse, error) {
+		telemetryReqs = append(telemetryReqs, req)
+		return getTelemetry(ctx, c, req)
+	}
+
+	e := newExporter(&megaport.Client{}, false, []string{"BITS"}, true)
+	e.poll(context.Background(), time.Minute)
+
+	assert.True(t, e.success)
+	assert.False(t, e.lastSuccess.IsZero())
+	assert.Len(t, family(t, e.families, "megaport_service_live").samples, 3, "decommissioned ports are filtered out")
+	require.Len(t, telemetryReqs, 1, "telemetry is requested only for LIVE NAT Gateways")
+	assert.Equal(t, "nat-1", telemetryReqs[0].ProductUID)
+	assert.Equal(t, int32(1), *telemetryReqs[0].Days)
+	assert.Equal(t, 42.0, family(t, e.families, "megaport_nat_gateway_telemetry").samples[0].value)
+}
+
+func TestExporterPoll_IncludeInactive(t *testing.T) {
+	stubAPI(t)
+
+	e := newExporter(&megaport.Client{}, true, nil, true)
+	e.poll(context.Background(), time.Minute)
+
+	assert.Len(t, family(t, e.families, "megaport_service_live").samples, 4)
+	for _, f := range e.families {
+		if f.name == "megaport_nat_gateway_telemetry" {
+			assert.Empty(t, f.samples, "no telemetry types, no telemetry")
+		}
+	}
+}
+
+func TestExporterPoll_FailureKeepsLastMetrics(t *testing.T) {
+	stubAPI(t)
+	e := newExporter(&megaport.Client{}, false, nil, true)
+	e.poll(context.Background(), time.Minute)
+	lastSuccess := e.lastSuccess
+
+	listVXCsFunc = func(context.Context, *megaport.Client, bool) ([]*megaport.VXC, error) {
+		return nil, errors.New("service unavailable")
+	}
+	out := output.CaptureOutput(func() {
+		e.poll(context.Background(), time.Minute)
+	})
+
+	assert.Contains(t, out, "VXCs: service unavailable")
+	assert.False(t, e.success)
+	assert.Equal(t, lastSuccess, e.lastSuccess)
+	assert.NotEmpty(t, family(t, e.families, "megaport_service_live").samples)
+}
+
+func TestExporterPoll_TelemetryFailureWarns(t *testing.T) {
+	stubAPI(t)
+	getNATGatewayTelemetryFunc = func(context.Context, *megaport.Client, *megaport.GetNATGatewayTelemetryRequest) (*megaport.ServiceTelemetryResponse, error) {
+		return nil, errors.New("not found")
+	}
+
+	e := newExporter(&megaport.Client{}, false, []string{"BITS"}, true)
+	out := output.CaptureOutput(func() {
+		e.poll(context.Background(), time.Minute)
+	})
+
+	assert.Contains(t, out, "Failed to fetch telemetry for NAT Gateway nat-1")
+	assert.True(t, e.success, "telemetry failures do not fail the poll")
+}
+
+func TestExporterPoll_AuthorizeFailure(t *testing.T) {
+	stubAPI(t)
+	authorizeFunc = func(context.Context, *megaport.Client) error { return errors.New("invalid credentials") }
+
+	e := newExporter(&megaport.Client{}, false, nil, true)
+	output.CaptureOutput(func() {
+		e.poll(context.Background(), time.Minute)
+	})
+
+	assert.False(t, e.success)
+	assert.Nil(t, e.families)
+}
+
+func TestServeMetrics(t *testing.T) {
+	stubAPI(t)
+	e := newExporter(&megaport.Client{}, false, nil, true)
+	e.poll(context.Background(), time.Minute)
+	server := httptest.NewServer(e.handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	require.NoError(t, err)
+	body := readBody(t, resp)
+	assert.Equal(t, textContentType, resp.Header.Get("Content-Type"))
+	assert.Contains(t, body, `megaport_service_speed_mbps{uid="port-1",name="Port One",type="Port"} 10000`)
+	assert.Contains(t, body, "megaport_exporter_last_poll_success 1\n")
+	assert.NotContains(t, body, "# EOF")
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/metrics", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	body = readBody(t, resp)
+	assert.Equal(t, openMetricsContentType, resp.Header.Get("Content-Type"))
+	assert.Contains(t, body, "# EOF\n")
+
+	resp, err = http.Get(server.URL + "/other")
+	require.NoError(t, err)
+	readBody(t, resp)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func newExporterCmd() *cobra.Command {
+	cmd := testutil.NewCommand("exporter", testutil.NoColorAdapter(RunExporter))
+	cmd.Flags().String("listen", ":9810", "")
+	cmd.Flags().Duration("interval", time.Minute, "")
+	cmd.Flags().Bool("include-inactive", false, "")
+	cmd.Flags().String("telemetry-types", "BITS,PACKETS", "")
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	return cmd
+}
+
+func TestRunExporter_InvalidFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "empty listen", args: []string{"--listen", " "}, wantErr: "--listen must not be empty"},
+		{name: "zero interval", args: []string{"--interval", "0s"}, wantErr: "--interval must be greater than 0"},
+		{name: "negative interval", args: []string{"--interval", "-5s"}, wantErr: "--interval must be greater than 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newExporterCmd()
+			cmd.SetArgs(tt.args)
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			assert.EqualError(t, err, tt.wantErr)
+			var cliErr *exitcodes.CLIError
+			require.ErrorAs(t, err, &cliErr)
+			assert.Equal(t, exitcodes.Usage, cliErr.Code)
+		})
+	}
+}
+
+func TestRunExporter_LoginError(t *testing.T) {
+	cleanup := testutil.SetupLoginError(errors.New("bad credentials"))
+	defer cleanup()
+
+	cmd := newExporterCmd()
+	cmd.SetArgs(nil)
+	var err error
+	output.CaptureOutput(func() {
+		err = cmd.Execute()
+	})
+	assert.ErrorContains(t, err, "failed to log in: bad credentials")
+}
+
+func TestRunExporter_ListenErrorSkipsPoll(t *testing.T) {
+	stubAPI(t)
+	polled := false
+	authorizeFunc = func(context.Context, *megaport.Client) error {
+		polled = true
+		return nil
+	}
+	cleanup := testutil.SetupLogin(func(*megaport.Client) {})
+	defer cleanup()
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer busy.Close()
+
+	cmd := newExporterCmd()
+	cmd.SetArgs([]string{"--listen", busy.Addr().String()})
+	output.CaptureOutput(func() {
+		err = cmd.Execute()
+	})
+	assert.ErrorContains(t, err, "failed to listen on "+busy.Addr().String())
+	assert.False(t, polled, "nothing is polled when the address is in use")
+}
+
+func TestParseTelemetryTypes(t *testing.T) {
+	assert.Equal(t, []string{"BITS", "PACKETS"}, parseTelemetryTypes(" bits, ,PACKETS "))
+	assert.Nil(t, parseTelemetryTypes(""))
+}
@@ -0,0 +1,29 @@
+package import_config
+
+import (
+	"github.com/megaport/megaport-cli/internal/base/cmdbuilder"
+	"github.com/spf13/cobra"
+)
+
+// AddCommandsTo builds the import command and adds it to the root command.
+func AddCommandsTo(rootCmd *cobra.Command) {
+	importCmd := cmdbuilder.NewCommand("import", "Generate an apply config from existing resources").
+		WithLongDesc("Walk the account and write an apply config file describing its active ports, MCRs, MVEs, NAT gateways, IXs, service keys and VXCs, together with an apply state file that maps each entry to its UID. Applying the generated file therefore updates the existing resources rather than ordering new ones, and plan reports them as no-op until the file or the account changes.").
+		WithColorAwareRunFunc(ImportConfig).
+		WithFlagP("file", "f", "", "Path to write the config file to, as YAML or (for a .json path) JSON (default: stdout)").
+		WithFlag("state", "", "Path to write the apply state file to (default: the config file path with a .state.json extension)").
+		WithBoolFlag("force", false, "Overwrite the config and state files if they exist").
+		WithExample(`megaport-cli import -f infrastructure.yaml`).
+		WithExample(`megaport-cli import -f infrastructure.json`).
+		WithExample(`megaport-cli import > infrastructure.yaml`).
+		WithExample(`megaport-cli import -f infrastructure.yaml --state ci/infrastructure.state.json --force`).
+		WithImportantNote("VXC endpoints and service key ports that are in the file are written as {{.type.name}} references; other endpoints, such as partner ports, keep their UIDs").
+		WithImportantNote("MCRs are imported with their prefix filter lists and IPsec add-on, and a LAG once, as its primary port with lag_count set").
+		WithImportantNote("Service keys are named by their description, or by the key when they have none; resources with the same name are imported as Name-2, Name-3, ..., which apply renames them to unless the names are edited").
+		WithImportantNote("Configuration the API does not return is reported as a warning: MVE vendor_config only holds the vendor, image and size, IX product_uid is left empty, NAT gateway designs that have not been bought are skipped, and VXC partner_config blocks omit BGP passwords, AWS auth keys and Azure shared keys").
+		WithImportantNote("Without --file or --state no state file is written, so applying the output would order every resource again").
+		WithRootCmd(rootCmd).
+		Build()
+
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,621 @@
+package import_config
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/commands/config"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/utils"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// importHeader is written at the top of a generated YAML config.
+const importHeader = "# Generated by megaport-cli import. Review before applying.\n"
+
+// importer accumulates an Config and the matching apply state from the
+// account's resources.
+type importer struct {
+	ctx      context.Context
+	client   *megaport.Client
+	cfg      *infra.Config
+	state    *infra.State
+	names    map[string]map[string]bool // names used so far, per template type
+	refs     map[string]string          // imported UID → {{.type.name}} reference
+	warnings []string
+}
+
+// ImportConfig is the entry point for `megaport-cli import`.
+func ImportConfig(cmd *cobra.Command, _ []string, noColor bool) error {
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	filePath, _ := cmd.Flags().GetString("file")
+	statePath, _ := cmd.Flags().GetString("state")
+	force, _ := cmd.Flags().GetBool("force")
+
+	if statePath == "" && filePath != "" {
+		statePath = infra.DefaultStatePath(filePath)
+	}
+	if !force {
+		for _, path := range []string{filePath, statePath} {
+			if path == "" {
+				continue
+			}
+			if _, err := os.Stat(path); err == nil {
+				output.PrintError("%s already exists; use --force to overwrite it", noColor, path)
+				return exitcodes.NewUsageError(fmt.Errorf("%s already exists; use --force to overwrite it", path))
+			}
+		}
+	}
+
+	ctx, cancel := utils.ContextFromCmd(cmd)
+	defer cancel()
+
+	spinner := output.PrintLoggingIn(noColor)
+	client, err := config.Login(ctx)
+	if err != nil {
+		spinner.Stop()
+		output.PrintError("Failed to log in: %v", noColor, err)
+		return err
+	}
+	spinner.Stop()
+
+	listSpinner := output.PrintResourceListing("resource", noColor)
+	imp := &importer{ctx: ctx, client: client}
+	err = imp.run()
+	listSpinner.Stop()
+	if err != nil {
+		output.PrintError("Failed to import resources: %v", noColor, err)
+		return err
+	}
+	for _, w := range imp.warnings {
+		output.PrintWarning("%s", noColor, w)
+	}
+
+	data, err := marshalConfig(imp.cfg, filepath.Ext(filePath))
+	if err != nil {
+		output.PrintError("Failed to encode config: %v", noColor, err)
+		return err
+	}
+	if filePath == "" {
+		fmt.Print(string(data))
+	} else if err := os.WriteFile(filePath, data, 0600); err != nil {
+		output.PrintError("Failed to write config file: %v", noColor, err)
+		return fmt.Errorf("writing config file: %w", err)
+	}
+
+	if statePath == "" {
+		output.PrintWarning("No state file was written, so apply would order every resource in this config again; re-run with --file or --state to record their UIDs.", noColor)
+	} else if err := infra.SaveState(statePath, imp.state); err != nil {
+		output.PrintError("Failed to write apply state: %v", noColor, err)
+		return err
+	}
+
+	dest := filePath
+	if dest == "" {
+		dest = "stdout"
+	}
+	output.PrintSuccess("Imported %d port(s), %d MCR(s), %d MVE(s), %d NAT gateway(s), %d IX(s), %d service key(s) and %d VXC(s) to %s", noColor,
+		len(imp.cfg.Ports), len(imp.cfg.MCRs), len(imp.cfg.MVEs), len(imp.cfg.NATGateways), len(imp.cfg.IXs), len(imp.cfg.ServiceKeys), len(imp.cfg.VXCs), dest)
+	if statePath != "" {
+		output.PrintInfo("Recorded their UIDs in %s", noColor, statePath)
+	}
+	return nil
+}
+
+// marshalConfig encodes cfg as JSON for a .json path and as YAML otherwise,
+// matching how ParseConfigFile picks a decoder.
+func marshalConfig(cfg *infra.Config, ext string) ([]byte, error) {
+	if strings.EqualFold(ext, ".json") {
+		data, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteString(importHeader)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// run lists the account and converts every supported resource. Resources are
+// sorted by name so repeated imports produce stable files.
+func (imp *importer) run() error {
+	inv, err := infra.FetchInventory(imp.ctx, imp.client)
+	if err != nil {
+		return err
+	}
+	imp.cfg = &infra.Config{}
+	imp.state = &infra.State{Version: infra.StateVersion}
+	imp.names = infra.NewTypeMap[bool]()
+	imp.refs = map[string]string{}
+
+	for _, p := range sortedByName(inv.Ports, func(p *megaport.Port) string { return p.Name }) {
+		// A LAG is imported once, as its primary port with the LAG's size;
+		// the other member ports are covered by that entry.
+		if (p.LAGID != 0 || p.AggregationID != 0) && !p.LAGPrimary {
+			continue
+		}
+		tags, err := imp.tags("port", p.UID)
+		if err != nil {
+			return err
+		}
+		imp.cfg.Ports = append(imp.cfg.Ports, infra.PortConfig{
+			Name:                  imp.record("port", p.Name, p.UID),
+			LocationID:            p.LocationID,
+			Speed:                 p.PortSpeed,
+			Term:                  p.ContractTermMonths,
+			LagCount:              p.LagCount,
+			MarketplaceVisibility: p.MarketplaceVisibility,
+			DiversityZone:         p.DiversityZone,
+			CostCentre:            p.CostCentre,
+			ResourceTags:          tags,
+			Locked:                importLocked(p.Locked),
+		})
+	}
+
+	for _, m := range sortedByName(inv.MCRs, func(m *megaport.MCR) string { return m.Name }) {
+		tags, err := imp.tags("mcr", m.UID)
+		if err != nil {
+			return err
+		}
+		lists, err := imp.prefixFilterLists(m.UID)
+		if err != nil {
+			return err
+		}
+		mc := infra.MCRConfig{
+			Name:              imp.record("mcr", m.Name, m.UID),
+			LocationID:        m.LocationID,
+			Speed:             m.PortSpeed,
+			Term:              m.ContractTermMonths,
+			ASN:               m.Resources.VirtualRouter.ASN,
+			DiversityZone:     m.DiversityZone,
+			CostCentre:        m.CostCentre,
+			ResourceTags:      tags,
+			PrefixFilterLists: lists,
+			Locked:            importLocked(m.Locked),
+		}
+		for _, a := range m.AddOns {
+			if a != nil && a.AddOnType == megaport.AddOnTypeIPsec {
+				mc.TunnelCount = a.TunnelCount
+			}
+		}
+		imp.cfg.MCRs = append(imp.cfg.MCRs, mc)
+	}
+
+	for _, mv := range sortedByName(inv.MVEs, func(mv *megaport.MVE) string { return mv.Name }) {
+		tags, err := imp.tags("mve", mv.UID)
+		if err != nil {
+			return err
+		}
+		name := imp.record("mve", mv.Name, mv.UID)
+		imp.cfg.MVEs = append(imp.cfg.MVEs, infra.MVEConfig{
+			Name:          name,
+			LocationID:    mv.LocationID,
+			Term:          mv.ContractTermMonths,
+			VendorConfig:  mveVendorConfig(mv),
+			DiversityZone: mv.DiversityZone,
+			CostCentre:    mv.CostCentre,
+			ResourceTags:  tags,
+			Locked:        importLocked(mv.Locked),
+		})
+		imp.warn("MVE %q: the API does not return vendor credentials or licensing, so vendor_config only holds vendor, imageId and productSize; complete it before using the file to order a new MVE", name)
+	}
+
+	for _, g := range sortedByName(inv.NATGateways, infra.NATGatewayName) {
+		if g.ProvisioningStatus == megaport.STATUS_DESIGN {
+			imp.warn("Skipped NAT gateway %q (%s): it is a design that has not been bought", g.ProductName, g.ProductUID)
+			continue
+		}
+		var tags map[string]string
+		if len(g.ResourceTags) > 0 {
+			tags = infra.ResourceTagMap(g.ResourceTags)
+		}
+		imp.cfg.NATGateways = append(imp.cfg.NATGateways, infra.NATGatewayConfig{
+			Name:                  imp.record("nat_gateway", g.ProductName, g.ProductUID),
+			LocationID:            g.LocationID,
+			Speed:                 g.Speed,
+			Term:                  g.Term,
+			SessionCount:          g.Config.SessionCount,
+			ASN:                   g.Config.ASN,
+			DiversityZone:         g.Config.DiversityZone,
+			AutoRenewTerm:         g.AutoRenewTerm,
+			ServiceLevelReference: g.ServiceLevelReference,
+			ResourceTags:          tags,
+			Locked:                importLocked(g.Locked),
+		})
+	}
+
+	for _, x := range sortedByName(inv.IXs, infra.IXName) {
+		name := imp.record("ix", x.ProductName, x.ProductUID)
+		imp.cfg.IXs = append(imp.cfg.IXs, infra.IXConfig{
+			Name:               name,
+			NetworkServiceType: x.NetworkServiceType,
+			ASN:                x.ASN,
+			MACAddress:         x.MACAddress,
+			RateLimit:          x.RateLimit,
+			VLAN:               x.VLAN,
+		})
+		imp.warn("IX %q: the API does not report which port an IX is on, so product_uid is empty; set it before using the file to order a new IX", name)
+	}
+
+	// Service keys are named by their description, or by the key itself when
+	// they have none.
+	keyName := func(k *megaport.ServiceKey) string { return cmp.Or(k.Description, k.Key) }
+	for _, k := range sortedByName(inv.ServiceKeys, keyName) {
+		imp.cfg.ServiceKeys = append(imp.cfg.ServiceKeys, infra.ServiceKeyConfig{
+			Name:        imp.record("service_key", keyName(k), k.Key),
+			ProductUID:  imp.ref(k.ProductUID),
+			MaxSpeed:    k.MaxSpeed,
+			SingleUse:   k.SingleUse,
+			VLAN:        k.VLAN,
+			PreApproved: k.PreApproved,
+			Active:      k.Active,
+		})
+	}
+
+	for _, v := range sortedByName(inv.VXCs, func(v *megaport.VXC) string { return v.Name }) {
+		tags, err := imp.tags("vxc", v.UID)
+		if err != nil {
+			return err
+		}
+		name := imp.record("vxc", v.Name, v.UID)
+		vc := infra.VXCConfig{
+			Name:         name,
+			RateLimit:    v.RateLimit,
+			Term:         v.ContractTermMonths,
+			AEnd:         imp.endpoint(v.AEndConfiguration, inv.MVEs),
+			BEnd:         imp.endpoint(v.BEndConfiguration, inv.MVEs),
+			CostCentre:   v.CostCentre,
+			ResourceTags: tags,
+			Locked:       importLocked(v.Locked),
+		}
+		if err := imp.partnerConfigs(&vc, v, inv.MCRs); err != nil {
+			return err
+		}
+		imp.cfg.VXCs = append(imp.cfg.VXCs, vc)
+	}
+	return nil
+}
+
+// endpoint converts one end of a VXC. The vNIC index is only written for MVE
+// ends, since 0 is a real index there and meaningless elsewhere.
+func (imp *importer) endpoint(e megaport.VXCEndConfiguration, mves map[string]*megaport.MVE) infra.VXCEndpointConfig {
+	ep := infra.VXCEndpointConfig{ProductUID: imp.ref(e.UID), VLAN: e.VLAN, InnerVLAN: e.InnerVLAN}
+	if _, ok := mves[e.UID]; ok {
+		vnic := e.NetworkInterfaceIndex
+		ep.VNICIndex = &vnic
+	}
+	return ep
+}
+
+// partnerConfigs rebuilds a VXC's partner configurations as partner_config
+// blocks: the vRouter config on its MCR end and a cloud connection on the
+// other end. Secrets are left out of the file and reported instead.
+func (imp *importer) partnerConfigs(vc *infra.VXCConfig, v *megaport.VXC, mcrs map[string]*megaport.MCR) error {
+	if v.Resources == nil || v.Resources.CSPConnection == nil {
+		return nil
+	}
+	mcrEnd, partnerEnd := &vc.AEnd, &vc.BEnd
+	if _, ok := mcrs[v.BEndConfiguration.UID]; ok {
+		mcrEnd, partnerEnd = &vc.BEnd, &vc.AEnd
+	}
+	secrets := false
+	for _, c := range v.Resources.CSPConnection.CSPConnection {
+		pc, omitted, err := importPartnerConfig(c)
+		if err != nil {
+			return fmt.Errorf("converting partner configuration of VXC %s: %w", v.UID, err)
+		}
+		if pc == nil {
+			imp.warn("VXC %q: its %s partner configuration is not supported in apply config files and is not included", vc.Name, cspConnectType(c))
+			continue
+		}
+		secrets = secrets || omitted
+		if _, ok := c.(megaport.CSPConnectionVirtualRouter); ok {
+			mcrEnd.PartnerConfig = pc
+		} else {
+			partnerEnd.PartnerConfig = pc
+		}
+	}
+	if secrets {
+		imp.warn("VXC %q: BGP passwords, AWS auth keys and Azure shared keys are not written to the file; add them to partner_config before using the file to order a new VXC", vc.Name)
+	}
+	return nil
+}
+
+// importPartnerConfig converts a CSP connection reported by the API into the
+// partner config format vxc buy --json accepts. It returns nil for connection
+// types that format cannot express, and whether a secret was left out.
+func importPartnerConfig(c megaport.CSPConnectionConfig) (map[string]interface{}, bool, error) {
+	pc := map[string]interface{}{}
+	switch c := c.(type) {
+	case megaport.CSPConnectionAWS:
+		pc["connectType"] = c.ConnectType
+		putIfSet(pc, "ownerAccount", cmp.Or(c.OwnerAccount, c.Account))
+		putIfSet(pc, "asn", c.ASN)
+		putIfSet(pc, "amazonAsn", c.AmazonASN)
+		putIfSet(pc, "customerIPAddress", cmp.Or(c.CustomerIPAddress, c.CustomerAddress))
+		putIfSet(pc, "amazonIPAddress", c.AmazonAddress)
+		putIfSet(pc, "connectionName", c.Name)
+		putIfSet(pc, "type", c.Type)
+		return pc, c.AuthKey != "", nil
+	case megaport.CSPConnectionAWSHC:
+		pc["connectType"] = c.ConnectType
+		putIfSet(pc, "ownerAccount", c.OwnerAccount)
+		putIfSet(pc, "connectionName", c.Name)
+		return pc, false, nil
+	case megaport.CSPConnectionAzure:
+		pc["connectType"] = "AZURE"
+		pc["serviceKey"] = c.ServiceKey
+		secrets := false
+		var peers []interface{}
+		for _, p := range c.Peers {
+			peer := map[string]interface{}{}
+			putIfSet(peer, "type", p.Type)
+			if p.PeerASN != 0 {
+				peer["peerASN"] = strconv.Itoa(p.PeerASN)
+			}
+			putIfSet(peer, "primarySubnet", p.PrimarySubnet)
+			putIfSet(peer, "secondarySubnet", p.SecondarySubnet)
+			putIfSet(peer, "prefixes", p.Prefixes)
+			putIfSet(peer, "vlan", p.VLAN)
+			secrets = secrets || p.SharedKey != ""
+			peers = append(peers, peer)
+		}
+		if peers != nil {
+			pc["peers"] = peers
+		}
+		return pc, secrets, nil
+	case megaport.CSPConnectionGoogle:
+		pc["connectType"] = "GOOGLE"
+		pc["pairingKey"] = c.PairingKey
+		return pc, false, nil
+	case megaport.CSPConnectionOracle:
+		pc["connectType"] = "ORACLE"
+		pc["virtualCircuitId"] = c.VirtualCircuitId
+		return pc, false, nil
+	case megaport.CSPConnectionIBM:
+		pc["connectType"] = "IBM"
+		pc["accountID"] = c.AccountID
+		putIfSet(pc, "customerASN", c.CustomerASN)
+		putIfSet(pc, "customerIPAddress", c.CustomerIPAddress)
+		putIfSet(pc, "providerIPAddress", c.ProviderIPAddress)
+		return pc, false, nil
+	case megaport.CSPConnectionTransit:
+		pc["connectType"] = "TRANSIT"
+		return pc, false, nil
+	case megaport.CSPConnectionVirtualRouter:
+		pc["connectType"] = "VROUTER"
+		secrets := false
+		var ifaces []interface{}
+		for _, iface := range c.Interfaces {
+			// The interface and BGP session fields share their JSON names
+			// with the order format, except for the password.
+			m, err := jsonMap(iface)
+			if err != nil {
+				return nil, false, err
+			}
+			conns, _ := m["bgpConnections"].([]interface{})
+			for _, conn := range conns {
+				if conn, ok := conn.(map[string]interface{}); ok {
+					if _, ok := conn["password"]; ok {
+						secrets = true
+						delete(conn, "password")
+					}
+				}
+			}
+			ifaces = append(ifaces, m)
+		}
+		if ifaces != nil {
+			pc["interfaces"] = ifaces
+		}
+		return pc, secrets, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+// cspConnectType names a CSP connection's type for warnings.
+func cspConnectType(c megaport.CSPConnectionConfig) string {
+	if other, ok := c.(megaport.CSPConnectionOther); ok {
+		if t, ok := other.CSPConnection["connectType"].(string); ok && t != "" {
+			return t
+		}
+	}
+	return fmt.Sprintf("%T", c)
+}
+
+// putIfSet adds v to m under key unless it is the zero value.
+func putIfSet[T comparable](m map[string]interface{}, key string, v T) {
+	var zero T
+	if v != zero {
+		m[key] = v
+	}
+}
+
+// jsonMap converts v to a generic map through its JSON encoding. Whole
+// numbers stay integers, so ASNs are not written in float notation, and null
+// fields are dropped, since the order format rejects them.
+func jsonMap(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	return wholeNumbers(m).(map[string]interface{}), nil
+}
+
+// wholeNumbers replaces the json.Numbers in a decoded value with ints, or
+// float64s for numbers with a fraction, and removes null map entries.
+func wholeNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, x := range v {
+			if x == nil {
+				delete(v, k)
+				continue
+			}
+			v[k] = wholeNumbers(x)
+		}
+	case []interface{}:
+		for i, x := range v {
+			v[i] = wholeNumbers(x)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n)
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return v
+}
+
+// record assigns a config name to a resource and records its UID in the state.
+// Names key the state and {{.type.name}} references, so a duplicate is
+// suffixed; apply then renames the resource unless the name is edited.
+func (imp *importer) record(resType, name, uid string) string {
+	unique := name
+	for i := 2; imp.names[resType][unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	if unique != name {
+		imp.warn("%s %q (%s) shares its name with another %s; imported as %q, and apply will rename it to match unless the name is edited", infra.DisplayType(resType), name, uid, resType, unique)
+	}
+	imp.names[resType][unique] = true
+	imp.refs[uid] = fmt.Sprintf("{{.%s.%s}}", resType, unique)
+	imp.state.Set(resType, unique, uid)
+	return unique
+}
+
+// ref returns the template reference for an imported UID, or the UID itself
+// for an endpoint outside the file (e.g. a partner port).
+func (imp *importer) ref(uid string) string {
+	if r, ok := imp.refs[uid]; ok {
+		return r
+	}
+	return uid
+}
+
+func (imp *importer) warn(format string, args ...interface{}) {
+	imp.warnings = append(imp.warnings, fmt.Sprintf(format, args...))
+}
+
+// tags returns a resource's tags, or nil when it has none so the field is omitted.
+func (imp *importer) tags(resType, uid string) (map[string]string, error) {
+	var tags map[string]string
+	err := utils.WithIdempotentRetry(imp.ctx, func(ctx context.Context) error {
+		var e error
+		tags, e = infra.TagLister(imp.client, resType)(ctx, uid)
+		return e
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing resource tags for %s: %w", uid, err)
+	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	return tags, nil
+}
+
+// prefixFilterLists fetches an MCR's prefix filter lists with their entries.
+func (imp *importer) prefixFilterLists(mcrUID string) ([]infra.PrefixFilterListConfig, error) {
+	var summaries []*megaport.PrefixFilterList
+	err := utils.WithIdempotentRetry(imp.ctx, func(ctx context.Context) error {
+		var e error
+		summaries, e = imp.client.MCRService.ListMCRPrefixFilterLists(ctx, mcrUID)
+		return e
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing prefix filter lists for MCR %s: %w", mcrUID, err)
+	}
+	var lists []infra.PrefixFilterListConfig
+	for _, s := range summaries {
+		if s == nil {
+			continue
+		}
+		var pfl *megaport.MCRPrefixFilterList
+		err := utils.WithIdempotentRetry(imp.ctx, func(ctx context.Context) error {
+			var e error
+			pfl, e = imp.client.MCRService.GetMCRPrefixFilterList(ctx, mcrUID, s.Id)
+			return e
+		})
+		if err != nil {
+			return nil, fmt.Errorf("getting prefix filter list %d on MCR %s: %w", s.Id, mcrUID, err)
+		}
+		if pfl == nil {
+			return nil, fmt.Errorf("getting prefix filter list %d on MCR %s: %w", s.Id, mcrUID, errors.New("empty response from API"))
+		}
+		l := infra.PrefixFilterListConfig{Description: pfl.Description, AddressFamily: pfl.AddressFamily}
+		for _, e := range pfl.Entries {
+			if e != nil {
+				l.Entries = append(l.Entries, infra.PrefixFilterEntryConfig{Action: e.Action, Prefix: e.Prefix, Ge: e.Ge, Le: e.Le})
+			}
+		}
+		lists = append(lists, l)
+	}
+	return lists, nil
+}
+
+// importLocked declares locked: true for a locked resource and leaves the lock
+// state unmanaged otherwise, so unlocked resources don't add noise to the file.
+func importLocked(locked bool) *bool {
+	if !locked {
+		return nil
+	}
+	return &locked
+}
+
+// mveVendorConfig rebuilds the parts of an MVE's vendor config the API returns.
+func mveVendorConfig(mv *megaport.MVE) map[string]interface{} {
+	vc := map[string]interface{}{"vendor": infra.MVEVendorKey(mv.Vendor)}
+	if mv.Size != "" {
+		vc["productSize"] = mv.Size
+	}
+	if mv.Resources != nil {
+		for _, vm := range mv.Resources.VirtualMachines {
+			if vm != nil && vm.Image != nil && vm.Image.ID != 0 {
+				vc["imageId"] = vm.Image.ID
+				break
+			}
+		}
+	}
+	return vc
+}
+
+// sortedByName returns the values of m ordered by name, then by UID.
+func sortedByName[T any](m map[string]T, name func(T) string) []T {
+	uids := slices.Sorted(maps.Keys(m))
+	slices.SortStableFunc(uids, func(a, b string) int { return cmp.Compare(name(m[a]), name(m[b])) })
+	out := make([]T, 0, len(uids))
+	for _, uid := range uids {
+		out = append(out, m[uid])
+	}
+	return out
+}
@@ -0,0 +1,14 @@
+package import_config
+
+import "github.com/spf13/cobra"
+
+// Module implements registry.Module for the import command.
+type Module struct{}
+
+func (m *Module) Name() string { return "import" }
+
+func (m *Module) RegisterCommands(rootCmd *cobra.Command) {
+	AddCommandsTo(rootCmd)
+}
+
+func NewModule() *Module { return &Module{} }
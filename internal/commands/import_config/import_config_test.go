@@ -0,0 +1,339 @@
+package import_config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/commands/plan"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/utils"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// importCmd builds a minimal cobra.Command with the flags ImportConfig reads.
+func importCmd(file string) *cobra.Command {
+	cmd := &cobra.Command{Use: "import"}
+	cmd.Flags().StringP("file", "f", "", "")
+	cmd.Flags().String("state", "", "")
+	cmd.Flags().Bool("force", false, "")
+	_ = cmd.Flags().Set("file", file)
+	return cmd
+}
+
+// importTestAccount returns mocks for an account with a port, a two-port LAG,
+// an MCR with a prefix filter list, an MVE, and VXCs to the MCR and a partner
+// port.
+func importTestAccount() (*infra.MockPortService, *infra.MockMCRService, *infra.MockMVEService, *infra.MockVXCService) {
+	mockPort := &infra.MockPortService{
+		ListPortsResult: []*megaport.Port{
+			{UID: "port-uid-1", Name: "Sydney-Port", LocationID: 1, PortSpeed: 10000, ContractTermMonths: 12, CostCentre: "NET-01", ProvisioningStatus: megaport.SERVICE_LIVE},
+			{UID: "lag-uid-1", Name: "Sydney-LAG", LocationID: 1, PortSpeed: 10000, ContractTermMonths: 12, LAGID: 7, AggregationID: 7, LAGPrimary: true, ProvisioningStatus: megaport.SERVICE_LIVE},
+			{UID: "lag-uid-2", Name: "Sydney-LAG", LocationID: 1, PortSpeed: 10000, ContractTermMonths: 12, LAGID: 7, AggregationID: 7, ProvisioningStatus: megaport.SERVICE_LIVE},
+			{UID: "port-uid-old", Name: "Old-Port", ProvisioningStatus: megaport.STATUS_DECOMMISSIONED},
+		},
+		ResourceTags: map[string]map[string]string{"port-uid-1": {"env": "prod"}},
+	}
+	mcr := &megaport.MCR{UID: "mcr-uid-1", Name: "Sydney-MCR", LocationID: 1, PortSpeed: 1000, ContractTermMonths: 12,
+		AddOns: []*megaport.MCRAddOnIPsecConfig{{AddOnType: megaport.AddOnTypeIPsec, TunnelCount: 10}}}
+	mcr.Resources.VirtualRouter.ASN = 64512
+	mockMCR := &infra.MockMCRService{
+		ListMCRsResult: []*megaport.MCR{mcr},
+		PrefixFilterLists: map[string][]*megaport.MCRPrefixFilterList{"mcr-uid-1": {{
+			ID: 3, Description: "Customer routes", AddressFamily: "IPv4",
+			Entries: []*megaport.MCRPrefixListEntry{{Action: "permit", Prefix: "10.0.0.0/8", Le: 24}},
+		}}},
+	}
+	mockMVE := &infra.MockMVEService{ListMVEsResult: []*megaport.MVE{{
+		UID: "mve-uid-1", Name: "Edge-MVE", LocationID: 2, ContractTermMonths: 12, Vendor: "Palo Alto", Size: "SMALL",
+		Resources: &megaport.MVEResources{VirtualMachines: []*megaport.MVEVirtualMachine{{Image: &megaport.MVEVirtualMachineImage{ID: 42}}}},
+	}}}
+	mockVXC := &infra.MockVXCService{ListVXCsResult: []*megaport.VXC{
+		{
+			UID: "vxc-uid-1", Name: "Port-to-MCR", RateLimit: 500, ContractTermMonths: 12,
+			AEndConfiguration: megaport.VXCEndConfiguration{UID: "port-uid-1", VLAN: 100},
+			BEndConfiguration: megaport.VXCEndConfiguration{UID: "mcr-uid-1"},
+		},
+		{
+			UID: "vxc-uid-2", Name: "Port-to-Partner", RateLimit: 100, ContractTermMonths: 1,
+			AEndConfiguration: megaport.VXCEndConfiguration{UID: "port-uid-1", VLAN: 200},
+			BEndConfiguration: megaport.VXCEndConfiguration{UID: "partner-port-uid", VLAN: 300},
+		},
+	}}
+	return mockPort, mockMCR, mockMVE, mockVXC
+}
+
+func TestImportConfig_WritesConfigAndState(t *testing.T) {
+	defer infra.SetupMockClient(importTestAccount())()
+	f := filepath.Join(t.TempDir(), "infra.yaml")
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ImportConfig(importCmd(f), nil, true)
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, `MVE "Edge-MVE": the API does not return vendor credentials`)
+
+	cfg, err := infra.ParseConfigFile(f, nil, utils.ProjectSettings{})
+	require.NoError(t, err, "the generated file must be a valid apply config")
+
+	require.Len(t, cfg.Ports, 2, "a LAG is imported once, as its primary port")
+	assert.Equal(t, infra.PortConfig{Name: "Sydney-LAG", LocationID: 1, Speed: 10000, Term: 12, LagCount: 2}, cfg.Ports[0])
+	assert.Equal(t, infra.PortConfig{
+		Name: "Sydney-Port", LocationID: 1, Speed: 10000, Term: 12, CostCentre: "NET-01",
+		ResourceTags: map[string]string{"env": "prod"},
+	}, cfg.Ports[1])
+
+	require.Len(t, cfg.MCRs, 1)
+	assert.Equal(t, 64512, cfg.MCRs[0].ASN)
+	assert.Equal(t, 10, cfg.MCRs[0].TunnelCount)
+	assert.Equal(t, []infra.PrefixFilterListConfig{{
+		Description: "Customer routes", AddressFamily: "IPv4",
+		Entries: []infra.PrefixFilterEntryConfig{{Action: "permit", Prefix: "10.0.0.0/8", Le: 24}},
+	}}, cfg.MCRs[0].PrefixFilterLists)
+
+	require.Len(t, cfg.MVEs, 1)
+	assert.Equal(t, "palo_alto", cfg.MVEs[0].VendorConfig["vendor"])
+	assert.Equal(t, "SMALL", cfg.MVEs[0].VendorConfig["productSize"])
+	assert.Equal(t, 42, cfg.MVEs[0].VendorConfig["imageId"])
+
+	require.Len(t, cfg.VXCs, 2)
+	assert.Equal(t, "Port-to-MCR", cfg.VXCs[0].Name)
+	assert.Equal(t, infra.VXCEndpointConfig{ProductUID: "{{.port.Sydney-Port}}", VLAN: 100}, cfg.VXCs[0].AEnd)
+	assert.Equal(t, infra.VXCEndpointConfig{ProductUID: "{{.mcr.Sydney-MCR}}"}, cfg.VXCs[0].BEnd)
+	assert.Equal(t, infra.VXCEndpointConfig{ProductUID: "partner-port-uid", VLAN: 300}, cfg.VXCs[1].BEnd, "endpoints outside the file keep their UID")
+
+	state, err := infra.LoadState(infra.DefaultStatePath(f))
+	require.NoError(t, err)
+	assert.Len(t, state.Resources, 6)
+	uid, ok := state.Lookup("mve", "Edge-MVE")
+	assert.True(t, ok)
+	assert.Equal(t, "mve-uid-1", uid)
+	uid, _ = state.Lookup("port", "Sydney-LAG")
+	assert.Equal(t, "lag-uid-1", uid)
+}
+
+func TestImportConfig_PlanOfImportIsNoOp(t *testing.T) {
+	defer infra.SetupMockClient(importTestAccount())()
+	f := filepath.Join(t.TempDir(), "infra.yaml")
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ImportConfig(importCmd(f), nil, true)
+	})
+	require.NoError(t, err)
+
+	for _, e := range runPlanJSON(t, f) {
+		assert.Equal(t, infra.PlanNoOp, e.Action, "%s %s: %v", e.Type, e.Name, e.Changes)
+	}
+}
+
+func TestImportConfig_JSONFile(t *testing.T) {
+	defer infra.SetupMockClient(importTestAccount())()
+	f := filepath.Join(t.TempDir(), "infra.json")
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ImportConfig(importCmd(f), nil, true)
+	})
+	require.NoError(t, err)
+	cfg, err := infra.ParseConfigFile(f, nil, utils.ProjectSettings{})
+	require.NoError(t, err)
+	assert.Len(t, cfg.VXCs, 2)
+}
+
+func TestImportConfig_DuplicateNamesAreSuffixed(t *testing.T) {
+	mockPort := &infra.MockPortService{ListPortsResult: []*megaport.Port{
+		{UID: "port-uid-b", Name: "Sydney-Port", ProvisioningStatus: megaport.SERVICE_LIVE},
+		{UID: "port-uid-a", Name: "Sydney-Port", ProvisioningStatus: megaport.SERVICE_LIVE},
+	}}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	f := filepath.Join(t.TempDir(), "infra.yaml")
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ImportConfig(importCmd(f), nil, true)
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, `imported as "Sydney-Port-2"`)
+
+	state, err := infra.LoadState(infra.DefaultStatePath(f))
+	require.NoError(t, err)
+	uid, _ := state.Lookup("port", "Sydney-Port")
+	assert.Equal(t, "port-uid-a", uid, "ties are broken by UID so the output is stable")
+	uid, _ = state.Lookup("port", "Sydney-Port-2")
+	assert.Equal(t, "port-uid-b", uid)
+}
+
+func TestImportConfig_RefusesToOverwrite(t *testing.T) {
+	f := writeTempFile(t, "infra.yaml", "ports: []\n")
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ImportConfig(importCmd(f), nil, true)
+	})
+	var cliErr *exitcodes.CLIError
+	require.True(t, errors.As(err, &cliErr))
+	assert.Equal(t, exitcodes.Usage, cliErr.Code)
+
+	data, readErr := os.ReadFile(f)
+	require.NoError(t, readErr)
+	assert.Equal(t, "ports: []\n", string(data))
+}
+
+func TestImportConfig_StdoutWithoutState(t *testing.T) {
+	defer infra.SetupMockClient(importTestAccount())()
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ImportConfig(importCmd(""), nil, true)
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(out, importHeader))
+	assert.Contains(t, out, "product_uid: '{{.port.Sydney-Port}}'")
+	assert.Contains(t, out, "No state file was written")
+}
+
+func TestImportConfig_NetworkServices(t *testing.T) {
+	mockNAT := &infra.MockNATGatewayService{ListNATGatewaysResult: []*megaport.NATGateway{
+		{
+			ProductUID: "nat-uid-1", ProductName: "Edge-NAT", LocationID: 1, Speed: 1000, Term: 12,
+			Config: megaport.NATGatewayNetworkConfig{SessionCount: 1000}, ProvisioningStatus: megaport.SERVICE_LIVE,
+		},
+		{ProductUID: "nat-uid-2", ProductName: "Draft-NAT", LocationID: 1, Speed: 1000, Term: 12, ProvisioningStatus: megaport.STATUS_DESIGN},
+	}}
+	mockIX := &infra.MockIXService{ListIXsResult: []*megaport.IX{{
+		ProductUID: "ix-uid-1", ProductName: "Sydney-IX", NetworkServiceType: "Sydney IX", ASN: 65000,
+		MACAddress: "00:11:22:33:44:55", RateLimit: 1000, VLAN: 200, ProvisioningStatus: megaport.SERVICE_LIVE,
+	}}}
+	mockKeys := &infra.MockServiceKeyService{ListServiceKeysResult: []*megaport.ServiceKey{
+		{Key: "key-1", Description: "Partner-Key", ProductUID: "port-uid-1", MaxSpeed: 500, Active: true},
+		{Key: "key-2", ProductUID: "partner-port-uid", MaxSpeed: 100},
+	}}
+	defer infra.SetupMockClient(importTestAccount())()
+	defer infra.SetupMockNetworkServices(mockNAT, mockIX, mockKeys)()
+	f := filepath.Join(t.TempDir(), "infra.yaml")
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ImportConfig(importCmd(f), nil, true)
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, `Skipped NAT gateway "Draft-NAT"`)
+	assert.Contains(t, out, `IX "Sydney-IX": the API does not report which port an IX is on`)
+
+	cfg, err := infra.ParseConfigFile(f, nil, utils.ProjectSettings{})
+	require.NoError(t, err)
+	assert.Equal(t, []infra.NATGatewayConfig{{Name: "Edge-NAT", LocationID: 1, Speed: 1000, Term: 12, SessionCount: 1000}}, cfg.NATGateways)
+	assert.Equal(t, []infra.IXConfig{{
+		Name: "Sydney-IX", NetworkServiceType: "Sydney IX", ASN: 65000, MACAddress: "00:11:22:33:44:55", RateLimit: 1000, VLAN: 200,
+	}}, cfg.IXs)
+	assert.Equal(t, []infra.ServiceKeyConfig{
+		{Name: "Partner-Key", ProductUID: "{{.port.Sydney-Port}}", MaxSpeed: 500, Active: true},
+		{Name: "key-2", ProductUID: "partner-port-uid", MaxSpeed: 100},
+	}, cfg.ServiceKeys, "a key without a description is named by the key itself")
+
+	state, err := infra.LoadState(infra.DefaultStatePath(f))
+	require.NoError(t, err)
+	uid, _ := state.Lookup("nat_gateway", "Edge-NAT")
+	assert.Equal(t, "nat-uid-1", uid)
+	_, ok := state.Lookup("nat_gateway", "Draft-NAT")
+	assert.False(t, ok)
+	uid, _ = state.Lookup("service_key", "key-2")
+	assert.Equal(t, "key-2", uid)
+}
+
+func TestImportConfig_VXCPartnerConfigs(t *testing.T) {
+	mockPort, mockMCR, mockMVE, mockVXC := importTestAccount()
+	mockVXC.ListVXCsResult = []*megaport.VXC{{
+		UID: "vxc-uid-1", Name: "MCR-to-AWS", RateLimit: 500, ContractTermMonths: 12,
+		AEndConfiguration: megaport.VXCEndConfiguration{UID: "aws-port-uid", VLAN: 200},
+		BEndConfiguration: megaport.VXCEndConfiguration{UID: "mcr-uid-1"},
+		Resources: &megaport.VXCResources{CSPConnection: &megaport.CSPConnection{CSPConnection: []megaport.CSPConnectionConfig{
+			megaport.CSPConnectionAWS{ConnectType: "AWS", Type: "private", OwnerAccount: "123456789012", ASN: 65000, AmazonASN: 64512, AuthKey: "aws-secret"},
+			megaport.CSPConnectionVirtualRouter{ConnectType: "VROUTER", Interfaces: []megaport.CSPConnectionVirtualRouterInterface{{
+				IPAddresses:    []string{"10.0.0.1/30"},
+				BGPConnections: []megaport.BgpConnectionConfig{{PeerAsn: 64512, LocalIpAddress: "10.0.0.1", PeerIpAddress: "10.0.0.2", Password: "bgp-secret"}},
+			}}},
+		}}},
+	}}
+	defer infra.SetupMockClient(mockPort, mockMCR, mockMVE, mockVXC)()
+	f := filepath.Join(t.TempDir(), "infra.yaml")
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = ImportConfig(importCmd(f), nil, true)
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, `VXC "MCR-to-AWS": BGP passwords`)
+	data, err := os.ReadFile(f)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret", "secrets are not written to the file")
+
+	cfg, err := infra.ParseConfigFile(f, nil, utils.ProjectSettings{})
+	require.NoError(t, err)
+	require.Len(t, cfg.VXCs, 1)
+	v := cfg.VXCs[0]
+	assert.Equal(t, "AWS", v.AEnd.PartnerConfig["connectType"], "the cloud connection goes on the partner end")
+	assert.Equal(t, "VROUTER", v.BEnd.PartnerConfig["connectType"], "the vRouter config goes on the MCR end")
+
+	// The imported blocks are accepted by apply's parsers.
+	req, err := infra.VXCRequest(context.Background(), &megaport.Client{}, v, "aws-port-uid", "mcr-uid-1", nil)
+	require.NoError(t, err)
+	aws, ok := req.AEndConfiguration.PartnerConfig.(*megaport.VXCPartnerConfigAWS)
+	require.True(t, ok, "%T", req.AEndConfiguration.PartnerConfig)
+	assert.Equal(t, 65000, aws.ASN)
+	vr, ok := req.BEndConfiguration.PartnerConfig.(*megaport.VXCOrderVrouterPartnerConfig)
+	require.True(t, ok, "%T", req.BEndConfiguration.PartnerConfig)
+	require.Len(t, vr.Interfaces, 1)
+	require.Len(t, vr.Interfaces[0].BgpConnections, 1)
+	assert.Equal(t, "10.0.0.2", vr.Interfaces[0].BgpConnections[0].PeerIpAddress)
+}
+
+// runPlanJSON runs plan.PlanConfig with JSON output and decodes the entries.
+func runPlanJSON(t *testing.T, file string) []infra.PlanEntry {
+	t.Helper()
+	cmd := &cobra.Command{Use: "plan"}
+	cmd.Flags().StringP("file", "f", "", "")
+	cmd.Flags().String("state", "", "")
+	cmd.Flags().String("policy", "", "")
+	_ = cmd.Flags().Set("file", file)
+	var err error
+	out := output.CaptureOutput(func() {
+		err = plan.PlanConfig(cmd, nil, true, "json")
+	})
+	require.NoError(t, err)
+	// CaptureOutput also captures the login spinner on stderr; the JSON starts at the array.
+	start := strings.Index(out, "[")
+	require.GreaterOrEqual(t, start, 0, out)
+	var entries []infra.PlanEntry
+	require.NoError(t, json.Unmarshal([]byte(out[start:]), &entries), out)
+	return entries
+}
+
+// writeStateFile writes a state file recording the given entries next to configPath.
+func writeStateFile(t *testing.T, configPath string, entries ...infra.StateResource) string {
+	t.Helper()
+	path := infra.DefaultStatePath(configPath)
+	require.NoError(t, infra.SaveState(path, &infra.State{Version: infra.StateVersion, Resources: entries}))
+	return path
+}
+
+// writeTempFile creates a temp file with the given name suffix and content,
+// returning its path.
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
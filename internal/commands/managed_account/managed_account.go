@@ -16,6 +16,7 @@ func AddCommandsTo(rootCmd *cobra.Command) {
 		WithExample("megaport-cli managed-account update [companyUID]").
 		WithImportantNote("Managed accounts are a partner-only feature").
 		WithImportantNote("Each managed account represents a sub-company under the partner's umbrella").
+		WithImportantNote("To run other commands on behalf of a managed account, pass --managed-account with its company UID or name; managed-account commands always act as the partner").
 		WithRootCmd(rootCmd).
 		Build()
 
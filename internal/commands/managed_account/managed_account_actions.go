@@ -13,7 +13,7 @@ import (
 
 func ListManagedAccounts(cmd *cobra.Command, args []string, noColor bool, outputFormat string) error {
 	output.SetOutputFormat(outputFormat)
-	ctx, cancel, client, err := utils.LoginClient(cmd, 90*time.Second, config.Login)
+	ctx, cancel, client, err := utils.LoginClient(cmd, 90*time.Second, config.LoginAsOwnCompany)
 	if err != nil {
 		output.PrintError("Failed to log in: %v", noColor, err)
 		return err
@@ -44,7 +44,7 @@ func ListManagedAccounts(cmd *cobra.Command, args []string, noColor bool, output
 
 func GetManagedAccount(cmd *cobra.Command, args []string, noColor bool, outputFormat string) error {
 	output.SetOutputFormat(outputFormat)
-	ctx, cancel, client, err := utils.LoginClient(cmd, 90*time.Second, config.Login)
+	ctx, cancel, client, err := utils.LoginClient(cmd, 90*time.Second, config.LoginAsOwnCompany)
 	if err != nil {
 		output.PrintError("Failed to log in: %v", noColor, err)
 		return err
@@ -114,7 +114,7 @@ func CreateManagedAccount(cmd *cobra.Command, args []string, noColor bool) error
 		return fmt.Errorf("no input provided, use --interactive, --json, or flags to specify managed account details")
 	}
 
-	client, err := config.Login(ctx)
+	client, err := config.LoginAsOwnCompany(ctx)
 	if err != nil {
 		output.PrintError("Failed to log in: %v", noColor, err)
 		return err
@@ -179,7 +179,7 @@ func UpdateManagedAccount(cmd *cobra.Command, args []string, noColor bool) error
 		output.PrintInfo("Using flag input", noColor)
 	}
 
-	client, err := config.Login(ctx)
+	client, err := config.LoginAsOwnCompany(ctx)
 	if err != nil {
 		output.PrintError("Failed to log in: %v", noColor, err)
 		return err
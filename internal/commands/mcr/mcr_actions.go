@@ -121,6 +121,8 @@ func BuyMCR(cmd *cobra.Command, args []string, noColor bool) error {
 		}
 	}
 
+	req.ResourceTags = utils.WithDefaultResourceTags(utils.ProjectSettingsFromCmd(cmd).ResourceTags, req.ResourceTags)
+
 	var buySpinner *output.Spinner
 	if noWait {
 		buySpinner = output.PrintResourceCreating("MCR", req.Name, noColor)
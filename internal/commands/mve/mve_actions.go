@@ -186,6 +186,7 @@ func BuyMVE(cmd *cobra.Command, args []string, noColor bool) error {
 	// must not also poll for provisioning: a 429 raised during polling would
 	// otherwise re-submit the order. Provisioning is awaited separately afterwards.
 	req.WaitForProvision = false
+	req.ResourceTags = utils.WithDefaultResourceTags(utils.ProjectSettingsFromCmd(cmd).ResourceTags, req.ResourceTags)
 
 	var spinner *output.Spinner
 	if noWait {
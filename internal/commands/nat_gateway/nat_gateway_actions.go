@@ -82,6 +82,8 @@ func CreateNATGateway(cmd *cobra.Command, args []string, noColor bool) error {
 		}
 	}
 
+	req.ResourceTags = utils.WithDefaultResourceTagList(utils.ProjectSettingsFromCmd(cmd).ResourceTags, req.ResourceTags)
+
 	spinner := output.PrintResourceCreating("NAT Gateway", req.ProductName, noColor)
 
 	var gw *megaport.NATGateway
@@ -0,0 +1,33 @@
+package plan
+
+import (
+	"github.com/megaport/megaport-cli/internal/base/cmdbuilder"
+	"github.com/spf13/cobra"
+)
+
+// AddCommandsTo builds the plan command and adds it to the root command.
+func AddCommandsTo(rootCmd *cobra.Command) {
+	planCmd := cmdbuilder.NewCommand("plan", "Show what apply would change for a config file").
+		WithLongDesc("Compare a declarative YAML or JSON config file against the resources in the account and report what apply would do, without ordering or modifying anything.\n\nEach config entry is matched to its resource through the UID recorded in the apply state file and reported as create, update (with the changed fields), replace (when a changed field cannot be updated in place) or no-op. Entries in the state file that are no longer in the config are reported as orphaned: apply never deletes them.").
+		WithOutputFormatRunFunc(PlanConfig).
+		WithFlagP("file", "f", "", "Path to config file (YAML or JSON)").
+		WithRequiredFlag("file", "Path to config file (YAML or JSON)").
+		WithFlag("state", "", "Path to the apply state file (default: the config file path with a .state.json extension)").
+		WithStringArrayFlag("var", "Set a config variable as name=value (repeatable; overrides --var-file)").
+		WithStringArrayFlag("var-file", "Path to a YAML or JSON file of config variable values (repeatable)").
+		WithFlag("policy", "", "Path to a policy file whose rules the config must follow (see apply)").
+		WithExample(`megaport-cli plan -f infrastructure.yaml`).
+		WithExample(`megaport-cli plan -f infrastructure.yaml --output json`).
+		WithExample(`megaport-cli plan -f infrastructure.yaml --state ci/infrastructure.state.json`).
+		WithExample(`megaport-cli plan -f region.yaml --var-file syd.yaml --state syd.state.json`).
+		WithExample(`megaport-cli plan -f infrastructure.yaml --policy policy.yaml`).
+		WithImportantNote("Fields omitted from the config (cost_centre, diversity_zone, resource_tags, locked) are not compared, and neither is an IX's product_uid, which the API does not report").
+		WithImportantNote("A NAT gateway design that has not been bought yet is reported as create with its UID").
+		WithImportantNote("VXC endpoints that reference a resource the apply would create are shown as \"(known after apply)\"").
+		WithImportantNote("With --policy, a config that breaks a rule of the policy file (see apply) is rejected with exit code 8 before the plan is built").
+		WithImportantNote("A resource with the same name as a config entry but absent from the state file is reported as create: apply does not adopt resources by name").
+		WithRootCmd(rootCmd).
+		Build()
+
+	rootCmd.AddCommand(planCmd)
+}
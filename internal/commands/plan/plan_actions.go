@@ -0,0 +1,96 @@
+package plan
+
+import (
+	"fmt"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/commands/config"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// PlanConfig is the entry point for `megaport-cli plan`.
+func PlanConfig(cmd *cobra.Command, _ []string, noColor bool, outputFormat string) error {
+	output.SetOutputFormat(outputFormat)
+
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	filePath, _ := cmd.Flags().GetString("file")
+	statePath, _ := cmd.Flags().GetString("state")
+	policyPath, _ := cmd.Flags().GetString("policy")
+
+	if filePath == "" {
+		output.PrintError("--file is required", noColor)
+		return exitcodes.NewUsageError(fmt.Errorf("--file is required"))
+	}
+	if statePath == "" {
+		statePath = infra.DefaultStatePath(filePath)
+	}
+
+	vars, err := infra.ConfigVars(cmd)
+	if err != nil {
+		output.PrintError("Invalid variables: %v", noColor, err)
+		return exitcodes.NewUsageError(err)
+	}
+	cfg, err := infra.ParseConfigFile(filePath, vars, utils.ProjectSettingsFromCmd(cmd))
+	if err != nil {
+		output.PrintError("Failed to parse config file: %v", noColor, err)
+		return err
+	}
+	if err := infra.EnforcePolicy(policyPath, cfg, outputFormat, noColor); err != nil {
+		return err
+	}
+	state, err := infra.LoadState(statePath)
+	if err != nil {
+		output.PrintError("Failed to load apply state: %v", noColor, err)
+		return err
+	}
+
+	ctx, cancel := utils.ContextFromCmd(cmd)
+	defer cancel()
+
+	spinner := output.PrintLoggingInWithOutput(noColor, outputFormat)
+	client, err := config.Login(ctx)
+	if err != nil {
+		spinner.Stop()
+		output.PrintError("Failed to log in: %v", noColor, err)
+		return err
+	}
+	spinner.Stop()
+
+	listSpinner := output.PrintResourceListing("resource", noColor)
+	plan, err := infra.BuildPlan(ctx, client, cfg, state)
+	listSpinner.Stop()
+	if err != nil {
+		output.PrintError("Failed to build plan: %v", noColor, err)
+		return err
+	}
+	return printPlan(plan, outputFormat, noColor)
+}
+
+// printPlan renders the plan table, then the field-level changes for every
+// entry that has them and a one-line summary. Machine-readable formats get the
+// entries only; the changes are carried in each entry's changes field.
+func printPlan(plan []infra.PlanEntry, outputFormat string, noColor bool) error {
+	if plan == nil {
+		plan = []infra.PlanEntry{}
+	}
+	if err := output.PrintOutput(plan, outputFormat, noColor); err != nil {
+		return err
+	}
+	if outputFormat != utils.FormatTable {
+		return nil
+	}
+	counts := map[string]int{}
+	for _, e := range plan {
+		counts[e.Action]++
+		if len(e.Changes) > 0 {
+			output.DisplayChangesWithHeading(fmt.Sprintf("%s %q planned changes:", e.Type, e.Name), e.Changes, noColor)
+		}
+	}
+	fmt.Println()
+	output.PrintInfo("Plan: %d to create, %d to update, %d to replace, %d unchanged, %d orphaned.", noColor,
+		counts[infra.PlanCreate], counts[infra.PlanUpdate], counts[infra.PlanReplace], counts[infra.PlanNoOp], counts[infra.PlanOrphaned])
+	return nil
+}
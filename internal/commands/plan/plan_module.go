@@ -0,0 +1,14 @@
+package plan
+
+import "github.com/spf13/cobra"
+
+// Module implements registry.Module for the plan command.
+type Module struct{}
+
+func (m *Module) Name() string { return "plan" }
+
+func (m *Module) RegisterCommands(rootCmd *cobra.Command) {
+	AddCommandsTo(rootCmd)
+}
+
+func NewModule() *Module { return &Module{} }
@@ -0,0 +1,467 @@
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/infra"
+	"github.com/megaport/megaport-cli/internal/utils"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// driftTestAccount returns mocks whose resources match planTestConfig.
+func driftTestAccount() (*infra.MockPortService, *infra.MockMCRService, *infra.MockMVEService, *infra.MockVXCService) {
+	mockPort := &infra.MockPortService{ListPortsResult: []*megaport.Port{{
+		UID: "port-uid-1", Name: "Sydney-Port", LocationID: 1, PortSpeed: 1000,
+		ContractTermMonths: 12, CostCentre: "NET-01", ProvisioningStatus: megaport.SERVICE_LIVE,
+	}}}
+	mockMCR := &infra.MockMCRService{
+		ListMCRsResult: []*megaport.MCR{{
+			UID: "mcr-uid-1", Name: "Sydney-MCR", LocationID: 1, PortSpeed: 1000, ContractTermMonths: 12,
+			ProvisioningStatus: megaport.SERVICE_LIVE,
+		}},
+		ResourceTags: map[string]map[string]string{"mcr-uid-1": {"env": "prod"}},
+	}
+	mockVXC := &infra.MockVXCService{ListVXCsResult: []*megaport.VXC{{
+		UID: "vxc-uid-1", Name: "Port-to-MCR", RateLimit: 500, ContractTermMonths: 12,
+		AEndConfiguration:  megaport.VXCEndConfiguration{UID: "port-uid-1", VLAN: 100},
+		BEndConfiguration:  megaport.VXCEndConfiguration{UID: "mcr-uid-1"},
+		ProvisioningStatus: megaport.SERVICE_LIVE,
+	}}}
+	return mockPort, mockMCR, &infra.MockMVEService{}, mockVXC
+}
+
+// writeDriftTestState records every resource in planTestConfig as applied.
+func writeDriftTestState(t *testing.T, file string) {
+	t.Helper()
+	writeStateFile(t, file,
+		infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"},
+		infra.StateResource{Type: "mcr", Name: "Sydney-MCR", UID: "mcr-uid-1"},
+		infra.StateResource{Type: "vxc", Name: "Port-to-MCR", UID: "vxc-uid-1"},
+	)
+}
+
+// planCmd builds a minimal cobra.Command with the flags PlanConfig reads.
+func planCmd(file string) *cobra.Command {
+	cmd := &cobra.Command{Use: "plan"}
+	cmd.Flags().StringP("file", "f", "", "")
+	cmd.Flags().String("state", "", "")
+	cmd.Flags().String("policy", "", "")
+	_ = cmd.Flags().Set("file", file)
+	return cmd
+}
+
+// runPlanJSON runs PlanConfig with JSON output and decodes the entries.
+func runPlanJSON(t *testing.T, file string) []infra.PlanEntry {
+	t.Helper()
+	return runPlanCmdJSON(t, planCmd(file))
+}
+
+// runPlanCmdJSON is runPlanJSON for a command built by the caller.
+func runPlanCmdJSON(t *testing.T, cmd *cobra.Command) []infra.PlanEntry {
+	t.Helper()
+	var err error
+	out := output.CaptureOutput(func() {
+		err = PlanConfig(cmd, nil, true, "json")
+	})
+	require.NoError(t, err)
+	// CaptureOutput also captures the login spinner on stderr; the JSON starts at the array.
+	start := strings.Index(out, "[")
+	require.GreaterOrEqual(t, start, 0, out)
+	var plan []infra.PlanEntry
+	require.NoError(t, json.Unmarshal([]byte(out[start:]), &plan), out)
+	return plan
+}
+
+const planTestConfig = `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+    cost_centre: NET-01
+mcrs:
+  - name: Sydney-MCR
+    location_id: 1
+    speed: 1000
+    term: 12
+    resource_tags:
+      env: prod
+vxcs:
+  - name: Port-to-MCR
+    rate_limit: 500
+    term: 12
+    a_end:
+      product_uid: "{{.port.Sydney-Port}}"
+      vlan: 100
+    b_end:
+      product_uid: "{{.mcr.Sydney-MCR}}"
+`
+
+func TestPlanConfig_CreatesWhenNothingApplied(t *testing.T) {
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+
+	plan := runPlanJSON(t, f)
+	require.Len(t, plan, 3)
+	for _, e := range plan {
+		assert.Equal(t, infra.PlanCreate, e.Action, e.Name)
+		assert.Empty(t, e.UID)
+	}
+}
+
+func TestPlanConfig_NoOpAndUpdate(t *testing.T) {
+	mockPort := &infra.MockPortService{ListPortsResult: []*megaport.Port{{
+		UID: "port-uid-1", Name: "Sydney-Port", LocationID: 1, PortSpeed: 1000,
+		ContractTermMonths: 12, CostCentre: "NET-01", ProvisioningStatus: megaport.SERVICE_LIVE,
+	}}}
+	mockMCR := &infra.MockMCRService{
+		ListMCRsResult: []*megaport.MCR{{
+			UID: "mcr-uid-1", Name: "Sydney-MCR", LocationID: 1, PortSpeed: 1000, ContractTermMonths: 12,
+		}},
+		ResourceTags: map[string]map[string]string{"mcr-uid-1": {"env": "dev"}},
+	}
+	mockVXC := &infra.MockVXCService{ListVXCsResult: []*megaport.VXC{{
+		UID: "vxc-uid-1", Name: "Port-to-MCR", RateLimit: 100, ContractTermMonths: 12,
+		AEndConfiguration: megaport.VXCEndConfiguration{UID: "port-uid-1", VLAN: 100},
+		BEndConfiguration: megaport.VXCEndConfiguration{UID: "mcr-uid-1"},
+	}}}
+	defer infra.SetupMockClient(mockPort, mockMCR, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+	writeStateFile(t, f,
+		infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"},
+		infra.StateResource{Type: "mcr", Name: "Sydney-MCR", UID: "mcr-uid-1"},
+		infra.StateResource{Type: "vxc", Name: "Port-to-MCR", UID: "vxc-uid-1"},
+	)
+
+	plan := runPlanJSON(t, f)
+	require.Len(t, plan, 3)
+
+	assert.Equal(t, infra.PlanNoOp, plan[0].Action)
+	assert.Equal(t, "port-uid-1", plan[0].UID)
+	assert.Empty(t, plan[0].Changes)
+
+	assert.Equal(t, infra.PlanUpdate, plan[1].Action)
+	assert.Equal(t, []output.FieldChange{{Label: "resource_tags", OldValue: "env=dev", NewValue: "env=prod"}}, plan[1].Changes)
+
+	assert.Equal(t, infra.PlanUpdate, plan[2].Action)
+	assert.Equal(t, []output.FieldChange{{Label: "rate_limit", OldValue: "100", NewValue: "500"}}, plan[2].Changes)
+}
+
+func TestPlanConfig_ProjectTagsLeaveUntaggedEntriesAlone(t *testing.T) {
+	mockPort, mockMCR, mockMVE, mockVXC := driftTestAccount()
+	mockPort.ResourceTags = map[string]map[string]string{"port-uid-1": {"owner": "portal"}}
+	defer infra.SetupMockClient(mockPort, mockMCR, mockMVE, mockVXC)()
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+	writeDriftTestState(t, f)
+
+	cmd := planCmd(f)
+	cmd.SetContext(utils.WithProjectSettings(context.Background(), utils.ProjectSettings{ResourceTags: map[string]string{"team": "network"}}))
+	plan := runPlanCmdJSON(t, cmd)
+	require.Len(t, plan, 3)
+	assert.Equal(t, infra.PlanNoOp, plan[0].Action, "the port's portal tags are not compared")
+	assert.Empty(t, plan[0].Changes)
+	assert.Equal(t, infra.PlanUpdate, plan[1].Action)
+	assert.Equal(t, []output.FieldChange{{Label: "resource_tags", OldValue: "env=prod", NewValue: "env=prod, team=network"}}, plan[1].Changes,
+		"declared tags get the project's tags")
+	assert.Equal(t, infra.PlanNoOp, plan[2].Action)
+}
+
+func TestPlanConfig_InactiveStateEntryIsCreate(t *testing.T) {
+	mockPort := &infra.MockPortService{ListPortsResult: []*megaport.Port{{
+		UID: "port-uid-1", Name: "Sydney-Port", ProvisioningStatus: megaport.STATUS_DECOMMISSIONED,
+	}}}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+	writeStateFile(t, f, infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"})
+
+	plan := runPlanJSON(t, f)
+	assert.Equal(t, infra.PlanCreate, plan[0].Action)
+	assert.Contains(t, plan[0].Detail, "port-uid-1 is no longer active")
+}
+
+func TestPlanConfig_UntrackedSameNameIsFlagged(t *testing.T) {
+	mockPort := &infra.MockPortService{ListPortsResult: []*megaport.Port{{
+		UID: "port-uid-other", Name: "Sydney-Port", ProvisioningStatus: megaport.SERVICE_LIVE,
+	}}}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+
+	plan := runPlanJSON(t, f)
+	assert.Equal(t, infra.PlanCreate, plan[0].Action)
+	assert.Contains(t, plan[0].Detail, "port-uid-other")
+	assert.Contains(t, plan[0].Detail, "not in the apply state")
+}
+
+func TestPlanConfig_VXCEndpointKnownAfterApply(t *testing.T) {
+	mockVXC := &infra.MockVXCService{ListVXCsResult: []*megaport.VXC{{
+		UID: "vxc-uid-1", Name: "Port-to-MCR", RateLimit: 500, ContractTermMonths: 12,
+		AEndConfiguration: megaport.VXCEndConfiguration{UID: "old-port-uid", VLAN: 100},
+		BEndConfiguration: megaport.VXCEndConfiguration{UID: "old-mcr-uid"},
+	}}}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+	writeStateFile(t, f, infra.StateResource{Type: "vxc", Name: "Port-to-MCR", UID: "vxc-uid-1"})
+
+	plan := runPlanJSON(t, f)
+	require.Len(t, plan, 3)
+	vxc := plan[2]
+	assert.Equal(t, infra.PlanUpdate, vxc.Action)
+	require.Len(t, vxc.Changes, 2)
+	assert.Equal(t, "a_end.product_uid", vxc.Changes[0].Label)
+	assert.Equal(t, infra.KnownAfterApply, vxc.Changes[0].NewValue)
+}
+
+func TestPlanConfig_OrphanedStateEntry(t *testing.T) {
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+	writeStateFile(t, f, infra.StateResource{Type: "mve", Name: "Old-MVE", UID: "mve-uid-old"})
+
+	plan := runPlanJSON(t, f)
+	require.Len(t, plan, 4)
+	assert.Equal(t, infra.PlanEntry{
+		Type: "MVE", Name: "Old-MVE", Action: infra.PlanOrphaned, UID: "mve-uid-old",
+		Detail: "in apply state but no longer in config; apply will not delete it",
+	}, plan[3])
+}
+
+func TestPlanConfig_TableOutput(t *testing.T) {
+	mockPort := &infra.MockPortService{ListPortsResult: []*megaport.Port{{
+		UID: "port-uid-1", Name: "Sydney-Port", LocationID: 1, PortSpeed: 10000,
+		ContractTermMonths: 12, CostCentre: "NET-01", ProvisioningStatus: megaport.SERVICE_LIVE,
+	}}}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+	writeStateFile(t, f, infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"})
+
+	var err error
+	out := output.CaptureOutput(func() {
+		err = PlanConfig(planCmd(f), nil, true, "table")
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, "Sydney-Port")
+	assert.Contains(t, out, "speed")
+	assert.Contains(t, out, "10000")
+	assert.Contains(t, out, "│ replace │")
+	assert.Contains(t, out, "Plan: 2 to create, 0 to update, 1 to replace, 0 unchanged, 0 orphaned.")
+}
+
+func TestPlanConfig_ListError(t *testing.T) {
+	mockMCR := &infra.MockMCRService{ListMCRsErr: fmt.Errorf("api unavailable")}
+	defer infra.SetupMockClient(&infra.MockPortService{}, mockMCR, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	f := writeTempFile(t, "infra.yaml", planTestConfig)
+	var err error
+	output.CaptureOutput(func() {
+		err = PlanConfig(planCmd(f), nil, true, "table")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "listing MCRs")
+}
+
+func TestPlanConfig_NetworkServices(t *testing.T) {
+	mockPort := &infra.MockPortService{ListPortsResult: []*megaport.Port{{
+		UID: "port-uid-1", Name: "Sydney-Port", LocationID: 1, PortSpeed: 10000,
+		ContractTermMonths: 12, ProvisioningStatus: megaport.SERVICE_LIVE,
+	}}}
+	mockNAT := &infra.MockNATGatewayService{ListNATGatewaysResult: []*megaport.NATGateway{{
+		ProductUID: "nat-uid-1", ProductName: "Edge-NAT", LocationID: 1, Speed: 1000, Term: 12,
+		ProvisioningStatus: megaport.STATUS_DESIGN,
+	}}}
+	mockIX := &infra.MockIXService{ListIXsResult: []*megaport.IX{{
+		ProductUID: "ix-uid-1", ProductName: "Sydney-IX", NetworkServiceType: "Sydney IX", ASN: 65000,
+		MACAddress: "00:11:22:33:44:55", RateLimit: 500, VLAN: 200, ProvisioningStatus: megaport.SERVICE_LIVE,
+	}}}
+	mockKeys := &infra.MockServiceKeyService{ListServiceKeysResult: []*megaport.ServiceKey{{
+		Key: "key-1", Description: "Partner-Key", ProductUID: "port-uid-1", MaxSpeed: 1000, Active: true,
+	}}}
+	defer infra.SetupMockClient(mockPort, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+	defer infra.SetupMockNetworkServices(mockNAT, mockIX, mockKeys)()
+
+	f := writeTempFile(t, "infra.yaml", networkServicesTestConfig)
+	writeStateFile(t, f,
+		infra.StateResource{Type: "port", Name: "Sydney-Port", UID: "port-uid-1"},
+		infra.StateResource{Type: "nat_gateway", Name: "Edge-NAT", UID: "nat-uid-1"},
+		infra.StateResource{Type: "ix", Name: "Sydney-IX", UID: "ix-uid-1"},
+		infra.StateResource{Type: "service_key", Name: "Partner-Key", UID: "key-1"},
+	)
+
+	plan := runPlanJSON(t, f)
+	require.Len(t, plan, 4)
+	assert.Equal(t, infra.PlanNoOp, plan[0].Action)
+
+	assert.Equal(t, "NAT Gateway", plan[1].Type)
+	assert.Equal(t, infra.PlanCreate, plan[1].Action, "an unbought design is still to be created")
+	assert.Equal(t, "nat-uid-1", plan[1].UID)
+	assert.Contains(t, plan[1].Detail, "apply will buy it")
+
+	assert.Equal(t, infra.PlanUpdate, plan[2].Action)
+	assert.Equal(t, []output.FieldChange{{Label: "rate_limit", OldValue: "500", NewValue: "1000"}}, plan[2].Changes)
+
+	assert.Equal(t, infra.PlanReplace, plan[3].Action, "a service key's speed cannot be changed in place")
+	assert.Contains(t, plan[3].Changes, output.FieldChange{Label: "max_speed", OldValue: "1000", NewValue: "500"})
+}
+
+func TestPlanConfig_VXCInnerVLANAndPartnerEnd(t *testing.T) {
+	mockVXC := &infra.MockVXCService{ListVXCsResult: []*megaport.VXC{{
+		UID: "vxc-uid-1", Name: "MVE-to-Azure", RateLimit: 200, ContractTermMonths: 12,
+		AEndConfiguration: megaport.VXCEndConfiguration{UID: "mve-uid-1", InnerVLAN: 300, NetworkInterfaceIndex: 0},
+		BEndConfiguration: megaport.VXCEndConfiguration{UID: "azure-port-uid"},
+	}}}
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, mockVXC)()
+
+	cfg := `
+vxcs:
+  - name: MVE-to-Azure
+    rate_limit: 200
+    term: 12
+    a_end:
+      product_uid: mve-uid-1
+      inner_vlan: 400
+      vnic_index: 1
+    b_end:
+      partner_config:
+        connectType: AZURE
+        serviceKey: azure-service-key
+`
+	f := writeTempFile(t, "infra.yaml", cfg)
+	writeStateFile(t, f, infra.StateResource{Type: "vxc", Name: "MVE-to-Azure", UID: "vxc-uid-1"})
+
+	plan := runPlanJSON(t, f)
+	require.Len(t, plan, 1)
+	assert.Equal(t, infra.PlanUpdate, plan[0].Action)
+	var labels []string
+	for _, c := range plan[0].Changes {
+		labels = append(labels, c.Label)
+	}
+	assert.Equal(t, []string{"a_end.inner_vlan", "a_end.vnic_index"}, labels, "a b_end looked up from its partner key matches any port")
+}
+
+const testPolicy = `
+rules:
+  - name: term-approval
+    type: max_term
+    max: 12
+    approval_tag: term-approved-by
+  - type: require_cost_centre
+    resources: [port, vxc]
+  - type: required_tags
+    keys: [owner, env]
+  - type: allowed_locations
+    location_ids: [1, 2]
+  - type: max_rate_limit
+    max: 1000
+`
+
+const policyConfig = `
+ports:
+  - name: Compliant
+    location_id: 1
+    speed: 1000
+    term: 12
+    cost_centre: NET-1
+    resource_tags: {owner: netops, env: prod}
+  - name: Approved
+    location_id: 2
+    speed: 1000
+    term: 36
+    cost_centre: NET-1
+    resource_tags: {owner: netops, env: prod, term-approved-by: cfo}
+  - name: Breaks
+    location_id: 9
+    speed: 1000
+    term: 24
+    resource_tags: {owner: netops}
+ixs:
+  - {name: IX, product_uid: "{{.port.Compliant}}", network_service_type: Los Angeles IX, asn: 65000, mac_address: "00:11:22:33:44:55", rate_limit: 10000}
+vxcs:
+  - name: V
+    rate_limit: 5000
+    term: 12
+    cost_centre: NET-1
+    resource_tags: {owner: netops, env: prod}
+    a_end: {product_uid: "{{.port.Compliant}}"}
+    b_end: {product_uid: "{{.port.Approved}}"}
+`
+
+func TestPlanConfig_PolicyViolation(t *testing.T) {
+	defer infra.SetupMockClient(&infra.MockPortService{}, &infra.MockMCRService{}, &infra.MockMVEService{}, &infra.MockVXCService{})()
+
+	cmd := planCmd(writeTempFile(t, "infra.yaml", policyConfig))
+	require.NoError(t, cmd.Flags().Set("policy", writeTempFile(t, "policy.yaml", "rules:\n  - {type: allowed_locations, location_ids: [1, 2, 9]}\n")))
+	var err error
+	output.CaptureOutput(func() {
+		err = PlanConfig(cmd, nil, true, "table")
+	})
+	assert.NoError(t, err)
+
+	require.NoError(t, cmd.Flags().Set("policy", writeTempFile(t, "strict.yaml", "rules:\n  - {type: allowed_locations, location_ids: [1]}\n")))
+	output.CaptureOutput(func() {
+		err = PlanConfig(cmd, nil, true, "table")
+	})
+	var cliErr *exitcodes.CLIError
+	require.True(t, errors.As(err, &cliErr))
+	assert.Equal(t, exitcodes.PolicyViolated, cliErr.Code)
+}
+
+// writeStateFile writes a state file recording the given entries next to configPath.
+func writeStateFile(t *testing.T, configPath string, entries ...infra.StateResource) string {
+	t.Helper()
+	path := infra.DefaultStatePath(configPath)
+	require.NoError(t, infra.SaveState(path, &infra.State{Version: infra.StateVersion, Resources: entries}))
+	return path
+}
+
+// writeTempFile creates a temp file with the given name suffix and content,
+// returning its path.
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+const networkServicesTestConfig = `
+ports:
+  - name: Sydney-Port
+    location_id: 1
+    speed: 10000
+    term: 12
+nat_gateways:
+  - name: Edge-NAT
+    location_id: 1
+    speed: 1000
+    term: 12
+    session_count: 1000
+ixs:
+  - name: Sydney-IX
+    product_uid: "{{.port.Sydney-Port}}"
+    network_service_type: Sydney IX
+    asn: 65000
+    mac_address: "00:11:22:33:44:55"
+    rate_limit: 1000
+    vlan: 200
+service_keys:
+  - name: Partner-Key
+    product_uid: "{{.port.Sydney-Port}}"
+    max_speed: 500
+    active: true
+`
@@ -111,6 +111,8 @@ func BuyPort(cmd *cobra.Command, args []string, noColor bool) error {
 		}
 	}
 
+	req.ResourceTags = utils.WithDefaultResourceTags(utils.ProjectSettingsFromCmd(cmd).ResourceTags, req.ResourceTags)
+
 	var spinner *output.Spinner
 	if noWait {
 		spinner = output.PrintResourceCreating("Port", req.Name, noColor)
@@ -260,6 +262,8 @@ func BuyLAGPort(cmd *cobra.Command, args []string, noColor bool) error {
 		}
 	}
 
+	req.ResourceTags = utils.WithDefaultResourceTags(utils.ProjectSettingsFromCmd(cmd).ResourceTags, req.ResourceTags)
+
 	var spinner *output.Spinner
 	if noWait {
 		spinner = output.PrintResourceCreating("LAG Port", req.Name, noColor)
@@ -8,12 +8,16 @@ import (
 // AddCommandsTo builds the status command and adds it to the root command.
 func AddCommandsTo(rootCmd *cobra.Command) {
 	statusCmd := cmdbuilder.NewCommand("status", "Show a dashboard of all Megaport resources").
-		WithLongDesc("Display a combined status view of all Megaport resources.\n\nFetches ports, MCRs, MVEs, VXCs, and IXs in parallel and displays them in a single dashboard. By default, only active resources are shown.").
+		WithLongDesc("Display a combined status view of all Megaport resources.\n\nFetches ports, MCRs, MVEs, VXCs, IXs, NAT Gateways and service keys in parallel and displays them in a single dashboard. By default, only active resources are shown.\n\nThe dashboard ends with rollups counting resources of each type by location, by market, by provisioning status and by contract expiry window (expired, within 30 days, 31-90 days, 91-180 days, 181-365 days, over a year, or no end date), so you can see what you have in a location and what is renewing soon.\n\nWith --watch, the dashboard is redrawn every --interval until interrupted. Statuses that changed since the last poll are marked with *, and an event log lists each transition with every status the resource has passed through, e.g. DEPLOYABLE → CONFIGURED → LIVE. JSON, XML and CSV output include the changes seen on each poll.").
 		WithOutputFormatRunFunc(StatusDashboard).
 		WithBoolFlag("include-inactive", false, "Include inactive/decommissioned resources").
+		WithWatchFlags().
 		WithExample("megaport-cli status").
 		WithExample("megaport-cli status --output json").
 		WithExample("megaport-cli status --include-inactive").
+		WithExample("megaport-cli status --watch").
+		WithExample("megaport-cli status --watch --interval 30s").
+		WithImportantNote("Watch mode stops after --timeout (30m by default); use --timeout to watch longer, e.g. --timeout 4h").
 		WithRootCmd(rootCmd).
 		WithAliases([]string{"st"}).
 		Build()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/megaport/megaport-cli/internal/base/output"
 	"github.com/megaport/megaport-cli/internal/commands/config"
@@ -32,9 +33,30 @@ var listIXsFunc = func(ctx context.Context, client *megaport.Client, includeInac
 	return client.IXService.ListIXs(ctx, &megaport.ListIXsRequest{IncludeInactive: includeInactive})
 }
 
+var listNATGatewaysFunc = func(ctx context.Context, client *megaport.Client) ([]*megaport.NATGateway, error) {
+	return client.NATGatewayService.ListNATGateways(ctx)
+}
+
+var listServiceKeysFunc = func(ctx context.Context, client *megaport.Client) ([]*megaport.ServiceKey, error) {
+	resp, err := client.ServiceKeyService.ListServiceKeys(ctx, &megaport.ListServiceKeysRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.ServiceKeys, nil
+}
+
 // StatusDashboard fetches all resources in parallel and renders a dashboard view.
+// With --watch it redraws the dashboard every --interval instead.
 func StatusDashboard(cmd *cobra.Command, args []string, noColor bool, outputFormat string) error {
 	output.SetOutputFormat(outputFormat)
+
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	includeInactive, _ := cmd.Flags().GetBool("include-inactive")
+	watch, _ := cmd.Flags().GetBool("watch")
+	if watch {
+		return watchDashboard(cmd, includeInactive, noColor, outputFormat)
+	}
+
 	ctx, cancel := utils.ContextFromCmd(cmd)
 	defer cancel()
 
@@ -44,23 +66,38 @@ func StatusDashboard(cmd *cobra.Command, args []string, noColor bool, outputForm
 		return fmt.Errorf("failed to log in: %w", err)
 	}
 
-	// Flag read errors are intentionally ignored — flags are registered by the command builder.
-	includeInactive, _ := cmd.Flags().GetBool("include-inactive")
+	dashboard, err := fetchDashboard(ctx, client, includeInactive, noColor)
+	if err != nil {
+		return err
+	}
 
+	if err := printDashboard(cmd.OutOrStdout(), dashboard, outputFormat, noColor); err != nil {
+		output.PrintError("Failed to print dashboard: %v", noColor, err)
+		return fmt.Errorf("failed to print dashboard: %w", err)
+	}
+
+	return nil
+}
+
+// fetchDashboard fetches every resource type in parallel and builds the
+// dashboard from them. Each fetch failure is printed and the first returned.
+func fetchDashboard(ctx context.Context, client *megaport.Client, includeInactive, noColor bool) (dashboardOutput, error) {
 	spinner := output.PrintResourceListing("resource", noColor)
 
 	var (
-		mu    sync.Mutex
-		errs  []error
-		ports []*megaport.Port
-		mcrs  []*megaport.MCR
-		mves  []*megaport.MVE
-		vxcs  []*megaport.VXC
-		ixs   []*megaport.IX
-		wg    sync.WaitGroup
+		mu          sync.Mutex
+		errs        []error
+		ports       []*megaport.Port
+		mcrs        []*megaport.MCR
+		mves        []*megaport.MVE
+		vxcs        []*megaport.VXC
+		ixs         []*megaport.IX
+		natGateways []*megaport.NATGateway
+		serviceKeys []*megaport.ServiceKey
+		wg          sync.WaitGroup
 	)
 
-	wg.Add(5)
+	wg.Add(7)
 
 	go func() {
 		defer wg.Done()
@@ -122,6 +159,30 @@ func StatusDashboard(cmd *cobra.Command, args []string, noColor bool, outputForm
 		}
 	}()
 
+	go func() {
+		defer wg.Done()
+		result, fetchErr := listNATGatewaysFunc(ctx, client)
+		mu.Lock()
+		defer mu.Unlock()
+		if fetchErr != nil {
+			errs = append(errs, fmt.Errorf("NAT Gateways: %w", fetchErr))
+		} else {
+			natGateways = result
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		result, fetchErr := listServiceKeysFunc(ctx, client)
+		mu.Lock()
+		defer mu.Unlock()
+		if fetchErr != nil {
+			errs = append(errs, fmt.Errorf("service keys: %w", fetchErr))
+		} else {
+			serviceKeys = result
+		}
+	}()
+
 	wg.Wait()
 	spinner.Stop()
 
@@ -129,33 +190,49 @@ func StatusDashboard(cmd *cobra.Command, args []string, noColor bool, outputForm
 		for _, e := range errs {
 			output.PrintError("Failed to fetch %v", noColor, e)
 		}
-		return errs[0]
+		return dashboardOutput{}, errs[0]
 	}
 
-	// Filter inactive ports client-side (PortService.ListPorts has no IncludeInactive param).
+	// Filter inactive ports, NAT Gateways and service keys client-side (their
+	// list calls have no IncludeInactive param).
 	if !includeInactive {
 		var activePorts []*megaport.Port
 		for _, p := range ports {
-			if p != nil &&
-				p.ProvisioningStatus != megaport.STATUS_DECOMMISSIONED &&
-				p.ProvisioningStatus != megaport.STATUS_CANCELLED &&
-				p.ProvisioningStatus != utils.StatusDecommissioning {
+			if p != nil && utils.IsActiveStatus(p.ProvisioningStatus) {
 				activePorts = append(activePorts, p)
 			}
 		}
 		ports = activePorts
+
+		var activeNATGateways []*megaport.NATGateway
+		for _, g := range natGateways {
+			if g != nil && utils.IsActiveStatus(g.ProvisioningStatus) {
+				activeNATGateways = append(activeNATGateways, g)
+			}
+		}
+		natGateways = activeNATGateways
+
+		var activeServiceKeys []*megaport.ServiceKey
+		for _, k := range serviceKeys {
+			if k != nil && k.Active && !k.Expired {
+				activeServiceKeys = append(activeServiceKeys, k)
+			}
+		}
+		serviceKeys = activeServiceKeys
 	}
 
-	dashboard, err := buildDashboard(ports, mcrs, mves, vxcs, ixs)
+	dashboard, err := buildDashboard(statusResources{
+		ports:       ports,
+		mcrs:        mcrs,
+		mves:        mves,
+		vxcs:        vxcs,
+		ixs:         ixs,
+		natGateways: natGateways,
+		serviceKeys: serviceKeys,
+	}, time.Now())
 	if err != nil {
 		output.PrintError("Failed to build dashboard: %v", noColor, err)
-		return fmt.Errorf("failed to build dashboard: %w", err)
+		return dashboardOutput{}, fmt.Errorf("failed to build dashboard: %w", err)
 	}
-
-	if err := printDashboard(cmd.OutOrStdout(), dashboard, outputFormat, noColor); err != nil {
-		output.PrintError("Failed to print dashboard: %v", noColor, err)
-		return fmt.Errorf("failed to print dashboard: %w", err)
-	}
-
-	return nil
+	return dashboard, nil
 }
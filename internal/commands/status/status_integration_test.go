@@ -41,6 +41,9 @@ func TestIntegration_StatusDashboard(t *testing.T) {
 	assert.Contains(t, dashboard, "mves")
 	assert.Contains(t, dashboard, "vxcs")
 	assert.Contains(t, dashboard, "ixs")
+	assert.Contains(t, dashboard, "nat_gateways")
+	assert.Contains(t, dashboard, "service_keys")
+	assert.Contains(t, dashboard, "rollups")
 	assert.Contains(t, dashboard, "summary")
 
 	summary, ok := dashboard["summary"].(map[string]interface{})
@@ -303,3 +303,144 @@ func (m *MockIXService) DeleteIX(_ context.Context, _ string, _ *megaport.Delete
 func (m *MockIXService) ListIXPs(_ context.Context, _ *megaport.ListIXPsRequest) ([]*megaport.IXP, error) {
 	return nil, fmt.Errorf("mock: ListIXPs not configured")
 }
+
+// MockNATGatewayService is a minimal mock for testing the status dashboard.
+type MockNATGatewayService struct {
+	ListNATGatewaysResult []*megaport.NATGateway
+	ListNATGatewaysErr    error
+}
+
+func (m *MockNATGatewayService) ListNATGateways(ctx context.Context) ([]*megaport.NATGateway, error) {
+	if m.ListNATGatewaysErr != nil {
+		return nil, m.ListNATGatewaysErr
+	}
+	if m.ListNATGatewaysResult != nil {
+		return m.ListNATGatewaysResult, nil
+	}
+	return []*megaport.NATGateway{}, nil
+}
+
+func (m *MockNATGatewayService) CreateNATGateway(_ context.Context, _ *megaport.CreateNATGatewayRequest) (*megaport.NATGateway, error) {
+	return nil, fmt.Errorf("mock: CreateNATGateway not configured")
+}
+
+func (m *MockNATGatewayService) GetNATGateway(_ context.Context, _ string) (*megaport.NATGateway, error) {
+	return nil, fmt.Errorf("mock: GetNATGateway not configured")
+}
+
+func (m *MockNATGatewayService) UpdateNATGateway(_ context.Context, _ *megaport.UpdateNATGatewayRequest) (*megaport.NATGateway, error) {
+	return nil, fmt.Errorf("mock: UpdateNATGateway not configured")
+}
+
+func (m *MockNATGatewayService) DeleteNATGateway(_ context.Context, _ string) error {
+	return fmt.Errorf("mock: DeleteNATGateway not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewaySessions(_ context.Context) ([]*megaport.NATGatewaySession, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewaySessions not configured")
+}
+
+func (m *MockNATGatewayService) GetNATGatewayTelemetry(_ context.Context, _ *megaport.GetNATGatewayTelemetryRequest) (*megaport.ServiceTelemetryResponse, error) {
+	return nil, fmt.Errorf("mock: GetNATGatewayTelemetry not configured")
+}
+
+func (m *MockNATGatewayService) ValidateNATGatewayOrder(_ context.Context, _ string) (*megaport.NATGatewayValidateResult, error) {
+	return nil, fmt.Errorf("mock: ValidateNATGatewayOrder not configured")
+}
+
+func (m *MockNATGatewayService) BuyNATGateway(_ context.Context, _ string) (*megaport.NATGatewayBuyResult, error) {
+	return nil, fmt.Errorf("mock: BuyNATGateway not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayPacketFilters(_ context.Context, _ string) ([]*megaport.NATGatewayPacketFilterSummary, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayPacketFilters not configured")
+}
+
+func (m *MockNATGatewayService) CreateNATGatewayPacketFilter(_ context.Context, _ string, _ *megaport.NATGatewayPacketFilterRequest) (*megaport.NATGatewayPacketFilter, error) {
+	return nil, fmt.Errorf("mock: CreateNATGatewayPacketFilter not configured")
+}
+
+func (m *MockNATGatewayService) GetNATGatewayPacketFilter(_ context.Context, _ string, _ int) (*megaport.NATGatewayPacketFilter, error) {
+	return nil, fmt.Errorf("mock: GetNATGatewayPacketFilter not configured")
+}
+
+func (m *MockNATGatewayService) UpdateNATGatewayPacketFilter(_ context.Context, _ string, _ int, _ *megaport.NATGatewayPacketFilterRequest) (*megaport.NATGatewayPacketFilter, error) {
+	return nil, fmt.Errorf("mock: UpdateNATGatewayPacketFilter not configured")
+}
+
+func (m *MockNATGatewayService) DeleteNATGatewayPacketFilter(_ context.Context, _ string, _ int) error {
+	return fmt.Errorf("mock: DeleteNATGatewayPacketFilter not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayPrefixLists(_ context.Context, _ string) ([]*megaport.NATGatewayPrefixListSummary, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayPrefixLists not configured")
+}
+
+func (m *MockNATGatewayService) CreateNATGatewayPrefixList(_ context.Context, _ string, _ *megaport.NATGatewayPrefixList) (*megaport.NATGatewayPrefixList, error) {
+	return nil, fmt.Errorf("mock: CreateNATGatewayPrefixList not configured")
+}
+
+func (m *MockNATGatewayService) GetNATGatewayPrefixList(_ context.Context, _ string, _ int) (*megaport.NATGatewayPrefixList, error) {
+	return nil, fmt.Errorf("mock: GetNATGatewayPrefixList not configured")
+}
+
+func (m *MockNATGatewayService) UpdateNATGatewayPrefixList(_ context.Context, _ string, _ int, _ *megaport.NATGatewayPrefixList) (*megaport.NATGatewayPrefixList, error) {
+	return nil, fmt.Errorf("mock: UpdateNATGatewayPrefixList not configured")
+}
+
+func (m *MockNATGatewayService) DeleteNATGatewayPrefixList(_ context.Context, _ string, _ int) error {
+	return fmt.Errorf("mock: DeleteNATGatewayPrefixList not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayIPRoutesAsync(_ context.Context, _ string, _ string) (string, error) {
+	return "", fmt.Errorf("mock: ListNATGatewayIPRoutesAsync not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayBGPRoutesAsync(_ context.Context, _ string, _ string) (string, error) {
+	return "", fmt.Errorf("mock: ListNATGatewayBGPRoutesAsync not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayBGPNeighborRoutesAsync(_ context.Context, _ *megaport.NATGatewayBGPNeighborRoutesRequest) (string, error) {
+	return "", fmt.Errorf("mock: ListNATGatewayBGPNeighborRoutesAsync not configured")
+}
+
+func (m *MockNATGatewayService) GetNATGatewayDiagnosticsRoutes(_ context.Context, _ string, _ string) ([]*megaport.NATGatewayRoute, error) {
+	return nil, fmt.Errorf("mock: GetNATGatewayDiagnosticsRoutes not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayIPRoutes(_ context.Context, _ string, _ string) ([]*megaport.NATGatewayIPRoute, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayIPRoutes not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayBGPRoutes(_ context.Context, _ string, _ string) ([]*megaport.NATGatewayBGPRoute, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayBGPRoutes not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayBGPNeighborRoutes(_ context.Context, _ *megaport.NATGatewayBGPNeighborRoutesRequest) ([]*megaport.NATGatewayBGPRoute, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayBGPNeighborRoutes not configured")
+}
+
+// MockServiceKeyService is a minimal mock for testing the status dashboard.
+type MockServiceKeyService struct {
+	ListServiceKeysResult []*megaport.ServiceKey
+	ListServiceKeysErr    error
+}
+
+func (m *MockServiceKeyService) ListServiceKeys(ctx context.Context, req *megaport.ListServiceKeysRequest) (*megaport.ListServiceKeysResponse, error) {
+	if m.ListServiceKeysErr != nil {
+		return nil, m.ListServiceKeysErr
+	}
+	return &megaport.ListServiceKeysResponse{ServiceKeys: m.ListServiceKeysResult}, nil
+}
+
+func (m *MockServiceKeyService) CreateServiceKey(_ context.Context, _ *megaport.CreateServiceKeyRequest) (*megaport.CreateServiceKeyResponse, error) {
+	return nil, fmt.Errorf("mock: CreateServiceKey not configured")
+}
+
+func (m *MockServiceKeyService) UpdateServiceKey(_ context.Context, _ *megaport.UpdateServiceKeyRequest) (*megaport.UpdateServiceKeyResponse, error) {
+	return nil, fmt.Errorf("mock: UpdateServiceKey not configured")
+}
+
+func (m *MockServiceKeyService) GetServiceKey(_ context.Context, _ string) (*megaport.ServiceKey, error) {
+	return nil, fmt.Errorf("mock: GetServiceKey not configured")
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/megaport/megaport-cli/internal/base/output"
 	megaport "github.com/megaport/megaportgo"
@@ -61,23 +62,50 @@ type statusIXOutput struct {
 	RateLimit     int    `json:"rate_limit" header:"Rate Limit" xml:"rate_limit"`
 }
 
+// statusNATGatewayOutput represents a NAT Gateway in the status dashboard.
+type statusNATGatewayOutput struct {
+	output.Output `json:"-" header:"-" xml:"-"`
+	UID           string `json:"uid" header:"UID" xml:"uid"`
+	Name          string `json:"name" header:"Name" xml:"name"`
+	Status        string `json:"status" header:"Status" xml:"status"`
+	Speed         int    `json:"speed" header:"Speed" xml:"speed"`
+	LocationID    int    `json:"location_id" header:"Location ID" xml:"location_id"`
+}
+
+// statusServiceKeyOutput represents a service key in the status dashboard.
+type statusServiceKeyOutput struct {
+	output.Output `json:"-" header:"-" xml:"-"`
+	Key           string `json:"key" header:"Key" xml:"key"`
+	ProductUID    string `json:"product_uid" header:"Product UID" xml:"product_uid"`
+	Description   string `json:"description" header:"Description" xml:"description"`
+	MaxSpeed      int    `json:"max_speed" header:"Max Speed" xml:"max_speed"`
+	SingleUse     bool   `json:"single_use" header:"Single Use" xml:"single_use"`
+	Active        bool   `json:"active" header:"Active" xml:"active"`
+}
+
 // dashboardSummary holds resource counts.
 type dashboardSummary struct {
-	Ports int `json:"ports" xml:"ports"`
-	MCRs  int `json:"mcrs" xml:"mcrs"`
-	MVEs  int `json:"mves" xml:"mves"`
-	VXCs  int `json:"vxcs" xml:"vxcs"`
-	IXs   int `json:"ixs" xml:"ixs"`
+	Ports       int `json:"ports" xml:"ports"`
+	MCRs        int `json:"mcrs" xml:"mcrs"`
+	MVEs        int `json:"mves" xml:"mves"`
+	VXCs        int `json:"vxcs" xml:"vxcs"`
+	IXs         int `json:"ixs" xml:"ixs"`
+	NATGateways int `json:"nat_gateways" xml:"nat_gateways"`
+	ServiceKeys int `json:"service_keys" xml:"service_keys"`
 }
 
 // dashboardOutput is the combined output for non-table formats.
 type dashboardOutput struct {
-	Ports   []statusPortOutput `json:"ports" xml:"ports>port"`
-	MCRs    []statusMCROutput  `json:"mcrs" xml:"mcrs>mcr"`
-	MVEs    []statusMVEOutput  `json:"mves" xml:"mves>mve"`
-	VXCs    []statusVXCOutput  `json:"vxcs" xml:"vxcs>vxc"`
-	IXs     []statusIXOutput   `json:"ixs" xml:"ixs>ix"`
-	Summary dashboardSummary   `json:"summary" xml:"summary"`
+	Ports       []statusPortOutput       `json:"ports" xml:"ports>port"`
+	MCRs        []statusMCROutput        `json:"mcrs" xml:"mcrs>mcr"`
+	MVEs        []statusMVEOutput        `json:"mves" xml:"mves>mve"`
+	VXCs        []statusVXCOutput        `json:"vxcs" xml:"vxcs>vxc"`
+	IXs         []statusIXOutput         `json:"ixs" xml:"ixs>ix"`
+	NATGateways []statusNATGatewayOutput `json:"nat_gateways" xml:"nat_gateways>nat_gateway"`
+	ServiceKeys []statusServiceKeyOutput `json:"service_keys" xml:"service_keys>service_key"`
+	Rollups     dashboardRollups         `json:"rollups" xml:"rollups"`
+	Summary     dashboardSummary         `json:"summary" xml:"summary"`
+	Changes     []statusChange           `json:"changes,omitempty" xml:"changes>change,omitempty"` // set by status --watch
 }
 
 // toStatusPortOutput converts a megaport.Port to statusPortOutput.
@@ -151,64 +179,118 @@ func toStatusIXOutput(i *megaport.IX) (statusIXOutput, error) {
 	}, nil
 }
 
-// buildDashboard converts raw resource slices into a dashboardOutput.
-func buildDashboard(
-	ports []*megaport.Port,
-	mcrs []*megaport.MCR,
-	mves []*megaport.MVE,
-	vxcs []*megaport.VXC,
-	ixs []*megaport.IX,
-) (dashboardOutput, error) {
+// toStatusNATGatewayOutput converts a megaport.NATGateway to statusNATGatewayOutput.
+func toStatusNATGatewayOutput(g *megaport.NATGateway) (statusNATGatewayOutput, error) {
+	if g == nil {
+		return statusNATGatewayOutput{}, fmt.Errorf("invalid NAT Gateway: nil value")
+	}
+	return statusNATGatewayOutput{
+		UID:        g.ProductUID,
+		Name:       g.ProductName,
+		Status:     g.ProvisioningStatus,
+		Speed:      g.Speed,
+		LocationID: g.LocationID,
+	}, nil
+}
+
+// toStatusServiceKeyOutput converts a megaport.ServiceKey to statusServiceKeyOutput.
+func toStatusServiceKeyOutput(k *megaport.ServiceKey) (statusServiceKeyOutput, error) {
+	if k == nil {
+		return statusServiceKeyOutput{}, fmt.Errorf("invalid service key: nil value")
+	}
+	return statusServiceKeyOutput{
+		Key:         k.Key,
+		ProductUID:  k.ProductUID,
+		Description: k.Description,
+		MaxSpeed:    k.MaxSpeed,
+		SingleUse:   k.SingleUse,
+		Active:      k.Active,
+	}, nil
+}
+
+// statusResources holds the resources the dashboard is built from.
+type statusResources struct {
+	ports       []*megaport.Port
+	mcrs        []*megaport.MCR
+	mves        []*megaport.MVE
+	vxcs        []*megaport.VXC
+	ixs         []*megaport.IX
+	natGateways []*megaport.NATGateway
+	serviceKeys []*megaport.ServiceKey
+}
+
+// buildDashboard converts raw resources into a dashboardOutput. Contract
+// expiry windows in the rollups are measured from now.
+func buildDashboard(res statusResources, now time.Time) (dashboardOutput, error) {
 	dashboard := dashboardOutput{
-		Ports: make([]statusPortOutput, 0, len(ports)),
-		MCRs:  make([]statusMCROutput, 0, len(mcrs)),
-		MVEs:  make([]statusMVEOutput, 0, len(mves)),
-		VXCs:  make([]statusVXCOutput, 0, len(vxcs)),
-		IXs:   make([]statusIXOutput, 0, len(ixs)),
+		Ports:       make([]statusPortOutput, 0, len(res.ports)),
+		MCRs:        make([]statusMCROutput, 0, len(res.mcrs)),
+		MVEs:        make([]statusMVEOutput, 0, len(res.mves)),
+		VXCs:        make([]statusVXCOutput, 0, len(res.vxcs)),
+		IXs:         make([]statusIXOutput, 0, len(res.ixs)),
+		NATGateways: make([]statusNATGatewayOutput, 0, len(res.natGateways)),
+		ServiceKeys: make([]statusServiceKeyOutput, 0, len(res.serviceKeys)),
 	}
 
-	for _, p := range ports {
+	for _, p := range res.ports {
 		o, err := toStatusPortOutput(p)
 		if err != nil {
 			return dashboardOutput{}, err
 		}
 		dashboard.Ports = append(dashboard.Ports, o)
 	}
-	for _, m := range mcrs {
+	for _, m := range res.mcrs {
 		o, err := toStatusMCROutput(m)
 		if err != nil {
 			return dashboardOutput{}, err
 		}
 		dashboard.MCRs = append(dashboard.MCRs, o)
 	}
-	for _, m := range mves {
+	for _, m := range res.mves {
 		o, err := toStatusMVEOutput(m)
 		if err != nil {
 			return dashboardOutput{}, err
 		}
 		dashboard.MVEs = append(dashboard.MVEs, o)
 	}
-	for _, v := range vxcs {
+	for _, v := range res.vxcs {
 		o, err := toStatusVXCOutput(v)
 		if err != nil {
 			return dashboardOutput{}, err
 		}
 		dashboard.VXCs = append(dashboard.VXCs, o)
 	}
-	for _, i := range ixs {
+	for _, i := range res.ixs {
 		o, err := toStatusIXOutput(i)
 		if err != nil {
 			return dashboardOutput{}, err
 		}
 		dashboard.IXs = append(dashboard.IXs, o)
 	}
+	for _, g := range res.natGateways {
+		o, err := toStatusNATGatewayOutput(g)
+		if err != nil {
+			return dashboardOutput{}, err
+		}
+		dashboard.NATGateways = append(dashboard.NATGateways, o)
+	}
+	for _, k := range res.serviceKeys {
+		o, err := toStatusServiceKeyOutput(k)
+		if err != nil {
+			return dashboardOutput{}, err
+		}
+		dashboard.ServiceKeys = append(dashboard.ServiceKeys, o)
+	}
 
+	dashboard.Rollups = buildRollups(res, now)
 	dashboard.Summary = dashboardSummary{
-		Ports: len(dashboard.Ports),
-		MCRs:  len(dashboard.MCRs),
-		MVEs:  len(dashboard.MVEs),
-		VXCs:  len(dashboard.VXCs),
-		IXs:   len(dashboard.IXs),
+		Ports:       len(dashboard.Ports),
+		MCRs:        len(dashboard.MCRs),
+		MVEs:        len(dashboard.MVEs),
+		VXCs:        len(dashboard.VXCs),
+		IXs:         len(dashboard.IXs),
+		NATGateways: len(dashboard.NATGateways),
+		ServiceKeys: len(dashboard.ServiceKeys),
 	}
 
 	return dashboard, nil
@@ -297,10 +379,44 @@ func printDashboardTable(w io.Writer, dashboard dashboardOutput, noColor bool) e
 		}
 	}
 
+	// NAT GATEWAYS
+	output.PrintNewline()
+	output.PrintPlain("NAT GATEWAYS (%d)", noColor, len(dashboard.NATGateways))
+	if len(dashboard.NATGateways) == 0 {
+		output.PrintWarning("No NAT Gateways found.", noColor)
+	} else {
+		if err := output.PrintTableToWriter(w, dashboard.NATGateways, noColor); err != nil {
+			return err
+		}
+	}
+
+	// SERVICE KEYS
+	output.PrintNewline()
+	output.PrintPlain("SERVICE KEYS (%d)", noColor, len(dashboard.ServiceKeys))
+	if len(dashboard.ServiceKeys) == 0 {
+		output.PrintWarning("No service keys found.", noColor)
+	} else {
+		if err := output.PrintTableToWriter(w, dashboard.ServiceKeys, noColor); err != nil {
+			return err
+		}
+	}
+
+	// ROLLUPS — omitted when there is nothing to roll up.
+	for _, r := range dashboard.Rollups.sections() {
+		if len(r.rows) == 0 {
+			continue
+		}
+		output.PrintNewline()
+		output.PrintPlain("%s", noColor, r.title)
+		if err := output.PrintTableToWriter(w, r.rows, noColor); err != nil {
+			return err
+		}
+	}
+
 	s := dashboard.Summary
 	output.PrintNewline()
-	output.PrintPlain("Total: %d port(s), %d MCR(s), %d MVE(s), %d VXC(s), %d IX(s)", noColor,
-		s.Ports, s.MCRs, s.MVEs, s.VXCs, s.IXs)
+	output.PrintPlain("Total: %d port(s), %d MCR(s), %d MVE(s), %d VXC(s), %d IX(s), %d NAT Gateway(s), %d service key(s)", noColor,
+		s.Ports, s.MCRs, s.MVEs, s.VXCs, s.IXs, s.NATGateways, s.ServiceKeys)
 
 	return nil
 }
@@ -313,21 +429,29 @@ func printDashboardJSON(w io.Writer, dashboard dashboardOutput) error {
 
 func printDashboardXML(w io.Writer, dashboard dashboardOutput) error {
 	type xmlDashboard struct {
-		XMLName xml.Name           `xml:"dashboard"`
-		Ports   []statusPortOutput `xml:"ports>port"`
-		MCRs    []statusMCROutput  `xml:"mcrs>mcr"`
-		MVEs    []statusMVEOutput  `xml:"mves>mve"`
-		VXCs    []statusVXCOutput  `xml:"vxcs>vxc"`
-		IXs     []statusIXOutput   `xml:"ixs>ix"`
-		Summary dashboardSummary   `xml:"summary"`
+		XMLName     xml.Name                 `xml:"dashboard"`
+		Ports       []statusPortOutput       `xml:"ports>port"`
+		MCRs        []statusMCROutput        `xml:"mcrs>mcr"`
+		MVEs        []statusMVEOutput        `xml:"mves>mve"`
+		VXCs        []statusVXCOutput        `xml:"vxcs>vxc"`
+		IXs         []statusIXOutput         `xml:"ixs>ix"`
+		NATGateways []statusNATGatewayOutput `xml:"nat_gateways>nat_gateway"`
+		ServiceKeys []statusServiceKeyOutput `xml:"service_keys>service_key"`
+		Rollups     dashboardRollups         `xml:"rollups"`
+		Summary     dashboardSummary         `xml:"summary"`
+		Changes     []statusChange           `xml:"changes>change,omitempty"`
 	}
 	out := xmlDashboard{
-		Ports:   dashboard.Ports,
-		MCRs:    dashboard.MCRs,
-		MVEs:    dashboard.MVEs,
-		VXCs:    dashboard.VXCs,
-		IXs:     dashboard.IXs,
-		Summary: dashboard.Summary,
+		Ports:       dashboard.Ports,
+		MCRs:        dashboard.MCRs,
+		MVEs:        dashboard.MVEs,
+		VXCs:        dashboard.VXCs,
+		IXs:         dashboard.IXs,
+		NATGateways: dashboard.NATGateways,
+		ServiceKeys: dashboard.ServiceKeys,
+		Rollups:     dashboard.Rollups,
+		Summary:     dashboard.Summary,
+		Changes:     dashboard.Changes,
 	}
 	encoder := xml.NewEncoder(w)
 	encoder.Indent("", "  ")
@@ -339,15 +463,25 @@ func printDashboardXML(w io.Writer, dashboard dashboardOutput) error {
 }
 
 func printDashboardCSV(dashboard dashboardOutput, noColor bool) error {
-	sections := []struct {
+	type section struct {
 		name string
 		fn   func() error
-	}{
+	}
+	sections := []section{
 		{"PORTS", func() error { return output.PrintOutput(dashboard.Ports, "csv", noColor) }},
 		{"MCRS", func() error { return output.PrintOutput(dashboard.MCRs, "csv", noColor) }},
 		{"MVES", func() error { return output.PrintOutput(dashboard.MVEs, "csv", noColor) }},
 		{"VXCS", func() error { return output.PrintOutput(dashboard.VXCs, "csv", noColor) }},
 		{"IXS", func() error { return output.PrintOutput(dashboard.IXs, "csv", noColor) }},
+		{"NAT GATEWAYS", func() error { return output.PrintOutput(dashboard.NATGateways, "csv", noColor) }},
+		{"SERVICE KEYS", func() error { return output.PrintOutput(dashboard.ServiceKeys, "csv", noColor) }},
+	}
+	for _, r := range dashboard.Rollups.sections() {
+		rows := r.rows
+		sections = append(sections, section{r.title, func() error { return output.PrintOutput(rows, "csv", noColor) }})
+	}
+	if len(dashboard.Changes) > 0 {
+		sections = append(sections, section{"CHANGES", func() error { return output.PrintOutput(dashboard.Changes, "csv", noColor) }})
 	}
 	for _, s := range sections {
 		output.PrintPlain("# %s", noColor, s.name)
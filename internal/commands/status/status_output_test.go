@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 	"testing"
+	"time"
 
 	op "github.com/megaport/megaport-cli/internal/base/output"
 	megaport "github.com/megaport/megaportgo"
@@ -32,13 +33,18 @@ func statusTestDashboard(t *testing.T) dashboardOutput {
 	vxc.BEndConfiguration.UID = "b-end"
 	ix := &megaport.IX{ProductUID: "ix-1", ProductName: "IX One", ProvisioningStatus: "LIVE", ASN: 64512, RateLimit: 100}
 
-	dashboard, err := buildDashboard(
-		[]*megaport.Port{port},
-		[]*megaport.MCR{mcr},
-		[]*megaport.MVE{mve},
-		[]*megaport.VXC{vxc},
-		[]*megaport.IX{ix},
-	)
+	nat := &megaport.NATGateway{ProductUID: "nat-1", ProductName: "NAT One", ProvisioningStatus: "LIVE", Speed: 1000, LocationID: 1}
+	key := &megaport.ServiceKey{Key: "key-1", ProductUID: "port-1", Description: "Key One", MaxSpeed: 500, Active: true}
+
+	dashboard, err := buildDashboard(statusResources{
+		ports:       []*megaport.Port{port},
+		mcrs:        []*megaport.MCR{mcr},
+		mves:        []*megaport.MVE{mve},
+		vxcs:        []*megaport.VXC{vxc},
+		ixs:         []*megaport.IX{ix},
+		natGateways: []*megaport.NATGateway{nat},
+		serviceKeys: []*megaport.ServiceKey{key},
+	}, time.Now())
 	assert.NoError(t, err)
 	return dashboard
 }
@@ -65,7 +71,15 @@ func TestBuildDashboard_FieldMapping(t *testing.T) {
 	assert.Equal(t, "ix-1", dashboard.IXs[0].UID)
 	assert.Equal(t, 64512, dashboard.IXs[0].ASN)
 
-	assert.Equal(t, dashboardSummary{Ports: 1, MCRs: 1, MVEs: 1, VXCs: 1, IXs: 1}, dashboard.Summary)
+	assert.Len(t, dashboard.NATGateways, 1)
+	assert.Equal(t, "nat-1", dashboard.NATGateways[0].UID)
+	assert.Equal(t, 1000, dashboard.NATGateways[0].Speed)
+
+	assert.Len(t, dashboard.ServiceKeys, 1)
+	assert.Equal(t, "key-1", dashboard.ServiceKeys[0].Key)
+	assert.Equal(t, "port-1", dashboard.ServiceKeys[0].ProductUID)
+
+	assert.Equal(t, dashboardSummary{Ports: 1, MCRs: 1, MVEs: 1, VXCs: 1, IXs: 1, NATGateways: 1, ServiceKeys: 1}, dashboard.Summary)
 }
 
 func TestPrintDashboard_Table(t *testing.T) {
@@ -81,7 +95,11 @@ func TestPrintDashboard_Table(t *testing.T) {
 	assert.Contains(t, out, "MCRS (1)")
 	assert.Contains(t, out, "mcr-1")
 	assert.Contains(t, out, "ix-1")
-	assert.Contains(t, out, "Total: 1 port(s), 1 MCR(s), 1 MVE(s), 1 VXC(s), 1 IX(s)")
+	assert.Contains(t, out, "NAT GATEWAYS (1)")
+	assert.Contains(t, out, "SERVICE KEYS (1)")
+	assert.Contains(t, out, "BY LOCATION")
+	assert.Contains(t, out, "BY CONTRACT EXPIRY")
+	assert.Contains(t, out, "Total: 1 port(s), 1 MCR(s), 1 MVE(s), 1 VXC(s), 1 IX(s), 1 NAT Gateway(s), 1 service key(s)")
 }
 
 func TestPrintDashboard_JSON(t *testing.T) {
@@ -139,25 +157,25 @@ func TestPrintDashboardTable_SectionError(t *testing.T) {
 	mve := &megaport.MVE{UID: "mve-1", Name: "MVE One", ProvisioningStatus: "LIVE"}
 	vxc := &megaport.VXC{UID: "vxc-1", Name: "VXC One", ProvisioningStatus: "LIVE"}
 	ix := &megaport.IX{ProductUID: "ix-1", ProductName: "IX One", ProvisioningStatus: "LIVE"}
+	nat := &megaport.NATGateway{ProductUID: "nat-1", ProductName: "NAT One", ProvisioningStatus: "LIVE"}
+	key := &megaport.ServiceKey{Key: "key-1", Active: true}
 
 	cases := []struct {
-		name  string
-		ports []*megaport.Port
-		mcrs  []*megaport.MCR
-		mves  []*megaport.MVE
-		vxcs  []*megaport.VXC
-		ixs   []*megaport.IX
+		name string
+		res  statusResources
 	}{
-		{name: "ports", ports: []*megaport.Port{port}},
-		{name: "mcrs", mcrs: []*megaport.MCR{mcr}},
-		{name: "mves", mves: []*megaport.MVE{mve}},
-		{name: "vxcs", vxcs: []*megaport.VXC{vxc}},
-		{name: "ixs", ixs: []*megaport.IX{ix}},
+		{name: "ports", res: statusResources{ports: []*megaport.Port{port}}},
+		{name: "mcrs", res: statusResources{mcrs: []*megaport.MCR{mcr}}},
+		{name: "mves", res: statusResources{mves: []*megaport.MVE{mve}}},
+		{name: "vxcs", res: statusResources{vxcs: []*megaport.VXC{vxc}}},
+		{name: "ixs", res: statusResources{ixs: []*megaport.IX{ix}}},
+		{name: "nat gateways", res: statusResources{natGateways: []*megaport.NATGateway{nat}}},
+		{name: "service keys", res: statusResources{serviceKeys: []*megaport.ServiceKey{key}}},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			dashboard, err := buildDashboard(tc.ports, tc.mcrs, tc.mves, tc.vxcs, tc.ixs)
+			dashboard, err := buildDashboard(tc.res, time.Now())
 			assert.NoError(t, err)
 
 			op.SetOutputFields([]string{"definitely_not_a_field"})
@@ -170,7 +188,7 @@ func TestPrintDashboardTable_SectionError(t *testing.T) {
 }
 
 func TestPrintDashboard_Empty(t *testing.T) {
-	dashboard, err := buildDashboard(nil, nil, nil, nil, nil)
+	dashboard, err := buildDashboard(statusResources{}, time.Now())
 	assert.NoError(t, err)
 
 	for _, format := range []string{"table", "json", "csv", "xml"} {
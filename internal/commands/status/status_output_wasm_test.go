@@ -33,7 +33,10 @@ func TestPrintDashboard_TableWASMCapture(t *testing.T) {
 		"MVES (1)", "mve-1",
 		"VXCS (1)", "vxc-1",
 		"IXS (1)", "ix-1",
-		"Total: 1 port(s), 1 MCR(s), 1 MVE(s), 1 VXC(s), 1 IX(s)",
+		"NAT GATEWAYS (1)", "nat-1",
+		"SERVICE KEYS (1)", "key-1",
+		"BY LOCATION", "BY MARKET", "BY STATUS", "BY CONTRACT EXPIRY",
+		"Total: 1 port(s), 1 MCR(s), 1 MVE(s), 1 VXC(s), 1 IX(s), 1 NAT Gateway(s), 1 service key(s)",
 	} {
 		assert.Contains(t, out, want, "WASM dashboard capture should include %q", want)
 	}
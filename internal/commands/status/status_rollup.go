@@ -0,0 +1,275 @@
+package status
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+	megaport "github.com/megaport/megaportgo"
+)
+
+// Contract expiry windows, soonest first.
+const (
+	expiryExpired = "Expired"
+	expiry30Days  = "Within 30 days"
+	expiry90Days  = "31-90 days"
+	expiry180Days = "91-180 days"
+	expiry365Days = "181-365 days"
+	expiryLater   = "Over a year"
+	expiryNone    = "No end date"
+)
+
+var expiryWindows = []string{expiryExpired, expiry30Days, expiry90Days, expiry180Days, expiry365Days, expiryLater, expiryNone}
+
+// unknownGroup groups resources whose location, market or status is not known.
+const unknownGroup = "Unknown"
+
+// rollupOutput counts the resources in one group of a rollup.
+type rollupOutput struct {
+	output.Output `json:"-" header:"-" xml:"-"`
+	Group         string `json:"group" header:"Group" xml:"group"`
+	Ports         int    `json:"ports" header:"Ports" xml:"ports"`
+	MCRs          int    `json:"mcrs" header:"MCRs" xml:"mcrs"`
+	MVEs          int    `json:"mves" header:"MVEs" xml:"mves"`
+	VXCs          int    `json:"vxcs" header:"VXCs" xml:"vxcs"`
+	IXs           int    `json:"ixs" header:"IXs" xml:"ixs"`
+	NATGateways   int    `json:"nat_gateways" header:"NAT Gateways" xml:"nat_gateways"`
+	Total         int    `json:"total" header:"Total" xml:"total"`
+}
+
+// dashboardRollups groups the dashboard's resources four ways. Service keys
+// have no location, status or contract, so they are not rolled up.
+type dashboardRollups struct {
+	ByLocation       []rollupOutput `json:"by_location" xml:"by_location>group"`
+	ByMarket         []rollupOutput `json:"by_market" xml:"by_market>group"`
+	ByStatus         []rollupOutput `json:"by_status" xml:"by_status>group"`
+	ByContractExpiry []rollupOutput `json:"by_contract_expiry" xml:"by_contract_expiry>group"`
+}
+
+type rollupSection struct {
+	title string
+	rows  []rollupOutput
+}
+
+// sections returns the rollups in the order the dashboard prints them.
+func (r dashboardRollups) sections() []rollupSection {
+	return []rollupSection{
+		{"BY LOCATION", r.ByLocation},
+		{"BY MARKET", r.ByMarket},
+		{"BY STATUS", r.ByStatus},
+		{"BY CONTRACT EXPIRY", r.ByContractExpiry},
+	}
+}
+
+// rolledUpResource is what the rollups need to know about a resource.
+type rolledUpResource struct {
+	kind     string // "Port", "MCR", "MVE", "VXC", "IX" or "NAT Gateway"
+	location string
+	market   string
+	status   string
+	window   string
+}
+
+// buildRollups rolls up res by location, market, provisioning status and
+// contract expiry window, measured from now.
+//
+// Only ports, MCRs and MVEs report a market, and NAT Gateways only a location
+// ID, so the location names and markets they report are looked up by ID for
+// the IXs, VXC A-Ends and NAT Gateways in the same locations.
+func buildRollups(res statusResources, now time.Time) dashboardRollups {
+	locationNames := map[int]string{}
+	markets := map[int]string{}
+	learn := func(locationID int, details *megaport.ProductLocationDetails, market string) {
+		if details != nil && details.Name != "" {
+			locationNames[locationID] = details.Name
+		}
+		if market != "" {
+			markets[locationID] = market
+		}
+	}
+	for _, p := range res.ports {
+		if p != nil {
+			learn(p.LocationID, p.LocationDetails, p.Market)
+		}
+	}
+	for _, m := range res.mcrs {
+		if m != nil {
+			learn(m.LocationID, m.LocationDetails, m.Market)
+		}
+	}
+	for _, m := range res.mves {
+		if m != nil {
+			learn(m.LocationID, m.LocationDetails, m.Market)
+		}
+	}
+
+	var items []rolledUpResource
+	add := func(kind string, locationID int, locationName, market, status string, contractEnd time.Time) {
+		if locationName == "" {
+			locationName = locationNames[locationID]
+		}
+		if locationName == "" && locationID != 0 {
+			locationName = fmt.Sprintf("Location %d", locationID)
+		}
+		if market == "" {
+			market = markets[locationID]
+		}
+		items = append(items, rolledUpResource{
+			kind:     kind,
+			location: orUnknown(locationName),
+			market:   orUnknown(market),
+			status:   orUnknown(status),
+			window:   expiryWindow(contractEnd, now),
+		})
+	}
+	for _, p := range res.ports {
+		if p != nil {
+			add("Port", p.LocationID, detailsName(p.LocationDetails), p.Market, p.ProvisioningStatus, timeOf(p.ContractEndDate))
+		}
+	}
+	for _, m := range res.mcrs {
+		if m != nil {
+			add("MCR", m.LocationID, detailsName(m.LocationDetails), m.Market, m.ProvisioningStatus, timeOf(m.ContractEndDate))
+		}
+	}
+	for _, m := range res.mves {
+		if m != nil {
+			add("MVE", m.LocationID, detailsName(m.LocationDetails), m.Market, m.ProvisioningStatus, timeOf(m.ContractEndDate))
+		}
+	}
+	for _, v := range res.vxcs {
+		if v != nil {
+			a := v.AEndConfiguration
+			add("VXC", a.LocationID, detailsName(a.LocationDetails), "", v.ProvisioningStatus, timeOf(v.ContractEndDate))
+		}
+	}
+	for _, i := range res.ixs {
+		if i != nil {
+			add("IX", i.LocationID, i.LocationDetail.Name, "", i.ProvisioningStatus, time.Time{})
+		}
+	}
+	for _, g := range res.natGateways {
+		if g != nil {
+			add("NAT Gateway", g.LocationID, "", "", g.ProvisioningStatus, parseContractEnd(g.ContractEndDate))
+		}
+	}
+
+	return dashboardRollups{
+		ByLocation:       rollup(items, func(r rolledUpResource) string { return r.location }, byName),
+		ByMarket:         rollup(items, func(r rolledUpResource) string { return r.market }, byName),
+		ByStatus:         rollup(items, func(r rolledUpResource) string { return r.status }, byName),
+		ByContractExpiry: rollup(items, func(r rolledUpResource) string { return r.window }, byWindow),
+	}
+}
+
+// rollup counts items by the group each belongs to, ordering groups by less.
+func rollup(items []rolledUpResource, group func(rolledUpResource) string, less func(a, b string) bool) []rollupOutput {
+	byGroup := map[string]*rollupOutput{}
+	for _, it := range items {
+		g := group(it)
+		row, ok := byGroup[g]
+		if !ok {
+			row = &rollupOutput{Group: g}
+			byGroup[g] = row
+		}
+		switch it.kind {
+		case "Port":
+			row.Ports++
+		case "MCR":
+			row.MCRs++
+		case "MVE":
+			row.MVEs++
+		case "VXC":
+			row.VXCs++
+		case "IX":
+			row.IXs++
+		case "NAT Gateway":
+			row.NATGateways++
+		}
+		row.Total++
+	}
+	rows := make([]rollupOutput, 0, len(byGroup))
+	for _, row := range byGroup {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return less(rows[i].Group, rows[j].Group) })
+	return rows
+}
+
+// byName orders groups alphabetically, with unknownGroup last.
+func byName(a, b string) bool {
+	if (a == unknownGroup) != (b == unknownGroup) {
+		return b == unknownGroup
+	}
+	return a < b
+}
+
+// byWindow orders contract expiry windows soonest first.
+func byWindow(a, b string) bool {
+	return windowIndex(a) < windowIndex(b)
+}
+
+func windowIndex(w string) int {
+	for i, e := range expiryWindows {
+		if e == w {
+			return i
+		}
+	}
+	return len(expiryWindows)
+}
+
+// expiryWindow returns the window a contract ending at end falls in, seen
+// from now. A zero end is expiryNone.
+func expiryWindow(end, now time.Time) string {
+	if end.IsZero() {
+		return expiryNone
+	}
+	days := end.Sub(now).Hours() / 24
+	switch {
+	case days < 0:
+		return expiryExpired
+	case days <= 30:
+		return expiry30Days
+	case days <= 90:
+		return expiry90Days
+	case days <= 180:
+		return expiry180Days
+	case days <= 365:
+		return expiry365Days
+	default:
+		return expiryLater
+	}
+}
+
+// parseContractEnd parses a NAT Gateway's contract end date, which the API
+// returns as a string. It returns the zero time when s is empty or unparsable.
+func parseContractEnd(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func timeOf(t *megaport.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time
+}
+
+func detailsName(d *megaport.ProductLocationDetails) string {
+	if d == nil {
+		return ""
+	}
+	return d.Name
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return unknownGroup
+	}
+	return s
+}
@@ -0,0 +1,99 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	megaport "github.com/megaport/megaportgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var rollupNow = time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+// endsIn returns a contract end date days after rollupNow.
+func endsIn(days int) *megaport.Time {
+	return &megaport.Time{Time: rollupNow.AddDate(0, 0, days)}
+}
+
+// rollupResources is two Sydney ports and an MCR in Melbourne, a VXC from
+// one port, an IX on the other, and a NAT Gateway in Sydney.
+func rollupResources() statusResources {
+	sydney := &megaport.ProductLocationDetails{Name: "Equinix SY1"}
+	return statusResources{
+		ports: []*megaport.Port{
+			{UID: "port-1", ProvisioningStatus: "LIVE", LocationID: 1, LocationDetails: sydney, Market: "AU", ContractEndDate: endsIn(45)},
+			{UID: "port-2", ProvisioningStatus: "CONFIGURED", LocationID: 1, LocationDetails: sydney, Market: "AU", ContractEndDate: endsIn(400)},
+		},
+		mcrs: []*megaport.MCR{
+			{UID: "mcr-1", ProvisioningStatus: "LIVE", LocationID: 2, LocationDetails: &megaport.ProductLocationDetails{Name: "NextDC M1"}, Market: "AU", ContractEndDate: endsIn(-3)},
+		},
+		vxcs: []*megaport.VXC{
+			{UID: "vxc-1", ProvisioningStatus: "LIVE", ContractEndDate: endsIn(10), AEndConfiguration: megaport.VXCEndConfiguration{UID: "port-1", LocationID: 1}},
+		},
+		ixs: []*megaport.IX{
+			{ProductUID: "ix-1", ProvisioningStatus: "LIVE", LocationID: 1, LocationDetail: megaport.IXLocationDetail{Name: "Equinix SY1"}},
+		},
+		natGateways: []*megaport.NATGateway{
+			{ProductUID: "nat-1", ProvisioningStatus: "DEPLOYABLE", LocationID: 1, ContractEndDate: "2027-01-10T00:00:00Z"},
+		},
+		serviceKeys: []*megaport.ServiceKey{{Key: "key-1", Active: true}},
+	}
+}
+
+func TestBuildRollups_ByLocation(t *testing.T) {
+	r := buildRollups(rollupResources(), rollupNow)
+
+	assert.Equal(t, []rollupOutput{
+		{Group: "Equinix SY1", Ports: 2, VXCs: 1, IXs: 1, NATGateways: 1, Total: 5},
+		{Group: "NextDC M1", MCRs: 1, Total: 1},
+	}, r.ByLocation, "VXCs and NAT Gateways take the name of the location they are in")
+}
+
+func TestBuildRollups_ByMarket(t *testing.T) {
+	res := rollupResources()
+	res.ixs[0].LocationID = 99
+	r := buildRollups(res, rollupNow)
+
+	require.Len(t, r.ByMarket, 2)
+	assert.Equal(t, rollupOutput{Group: "AU", Ports: 2, MCRs: 1, VXCs: 1, NATGateways: 1, Total: 5}, r.ByMarket[0])
+	assert.Equal(t, rollupOutput{Group: unknownGroup, IXs: 1, Total: 1}, r.ByMarket[1], "unknown markets sort last")
+}
+
+func TestBuildRollups_ByStatus(t *testing.T) {
+	r := buildRollups(rollupResources(), rollupNow)
+
+	var groups []string
+	for _, row := range r.ByStatus {
+		groups = append(groups, row.Group)
+	}
+	assert.Equal(t, []string{"CONFIGURED", "DEPLOYABLE", "LIVE"}, groups)
+	assert.Equal(t, 4, r.ByStatus[2].Total)
+}
+
+func TestBuildRollups_ByContractExpiry(t *testing.T) {
+	r := buildRollups(rollupResources(), rollupNow)
+
+	assert.Equal(t, []rollupOutput{
+		{Group: expiryExpired, MCRs: 1, Total: 1},
+		{Group: expiry30Days, VXCs: 1, Total: 1},
+		{Group: expiry90Days, Ports: 1, NATGateways: 1, Total: 2},
+		{Group: expiryLater, Ports: 1, Total: 1},
+		{Group: expiryNone, IXs: 1, Total: 1},
+	}, r.ByContractExpiry)
+}
+
+func TestBuildRollups_Empty(t *testing.T) {
+	r := buildRollups(statusResources{}, rollupNow)
+
+	assert.NotNil(t, r.ByLocation, "empty rollups encode as [] rather than null")
+	assert.Empty(t, r.ByLocation)
+	assert.Empty(t, r.ByContractExpiry)
+}
+
+func TestParseContractEnd(t *testing.T) {
+	assert.Equal(t, time.Date(2027, 1, 10, 0, 0, 0, 0, time.UTC), parseContractEnd("2027-01-10T00:00:00Z"))
+	assert.Equal(t, time.Date(2027, 1, 10, 0, 0, 0, 0, time.UTC), parseContractEnd("2027-01-10"))
+	assert.True(t, parseContractEnd("").IsZero())
+	assert.True(t, parseContractEnd("soon").IsZero())
+}
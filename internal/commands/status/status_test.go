@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"errors"
 	"os"
+	"strconv"
 	"testing"
+	"time"
 
 	op "github.com/megaport/megaport-cli/internal/base/output"
 	"github.com/megaport/megaport-cli/internal/commands/config"
@@ -25,13 +27,20 @@ func newStatusCmd() *cobra.Command {
 	return cmd
 }
 
-func setupMocks(portSvc *MockPortService, mcrSvc *MockMCRService, mveSvc *MockMVEService, vxcSvc *MockVXCService, ixSvc *MockIXService) func() {
+// setupMocks serves the given services, with no NAT Gateways or service keys
+// unless opts sets them.
+func setupMocks(portSvc *MockPortService, mcrSvc *MockMCRService, mveSvc *MockMVEService, vxcSvc *MockVXCService, ixSvc *MockIXService, opts ...func(*megaport.Client)) func() {
 	return testutil.SetupLogin(func(c *megaport.Client) {
 		c.PortService = portSvc
 		c.MCRService = mcrSvc
 		c.MVEService = mveSvc
 		c.VXCService = vxcSvc
 		c.IXService = ixSvc
+		c.NATGatewayService = &MockNATGatewayService{}
+		c.ServiceKeyService = &MockServiceKeyService{}
+		for _, opt := range opts {
+			opt(c)
+		}
 	})
 }
 
@@ -184,6 +193,48 @@ func TestStatusDashboard_InactivePortsFiltered(t *testing.T) {
 	_ = capturedPorts
 }
 
+// TestStatusDashboard_NATGatewaysAndServiceKeys verifies NAT Gateways and
+// service keys are listed, and inactive ones excluded by default.
+func TestStatusDashboard_NATGatewaysAndServiceKeys(t *testing.T) {
+	natSvc := &MockNATGatewayService{ListNATGatewaysResult: []*megaport.NATGateway{
+		{ProductUID: "nat-live", ProductName: "Live NAT", ProvisioningStatus: "LIVE", LocationID: 1},
+		{ProductUID: "nat-decomm", ProductName: "Dead NAT", ProvisioningStatus: "DECOMMISSIONED", LocationID: 1},
+	}}
+	keySvc := &MockServiceKeyService{ListServiceKeysResult: []*megaport.ServiceKey{
+		{Key: "key-active", Active: true},
+		{Key: "key-expired", Active: true, Expired: true},
+		{Key: "key-inactive"},
+	}}
+	cleanup := setupMocks(&MockPortService{}, &MockMCRService{}, &MockMVEService{}, &MockVXCService{}, &MockIXService{},
+		func(c *megaport.Client) {
+			c.NATGatewayService = natSvc
+			c.ServiceKeyService = keySvc
+		})
+	defer cleanup()
+
+	run := func(includeInactive bool) dashboardOutput {
+		cmd := newStatusCmd()
+		require.NoError(t, cmd.Flags().Set("include-inactive", strconv.FormatBool(includeInactive)))
+		out := op.CaptureOutput(func() {
+			assert.NoError(t, StatusDashboard(cmd, nil, true, "json"))
+		})
+		var dashboard dashboardOutput
+		require.NoError(t, json.Unmarshal([]byte(out), &dashboard))
+		return dashboard
+	}
+
+	dashboard := run(false)
+	require.Len(t, dashboard.NATGateways, 1)
+	assert.Equal(t, "nat-live", dashboard.NATGateways[0].UID)
+	require.Len(t, dashboard.ServiceKeys, 1)
+	assert.Equal(t, "key-active", dashboard.ServiceKeys[0].Key)
+	assert.Equal(t, []rollupOutput{{Group: "LIVE", NATGateways: 1, Total: 1}}, dashboard.Rollups.ByStatus)
+
+	dashboard = run(true)
+	assert.Len(t, dashboard.NATGateways, 2)
+	assert.Len(t, dashboard.ServiceKeys, 3)
+}
+
 // TestStatusDashboard_JSONOutput verifies the JSON output contains all 5 keys.
 func TestStatusDashboard_JSONOutput(t *testing.T) {
 	cleanup := setupMocks(
@@ -417,31 +468,43 @@ func TestStatusDashboard_PrintError(t *testing.T) {
 
 // TestBuildDashboard_NilPort verifies buildDashboard returns an error for a nil port.
 func TestBuildDashboard_NilPort(t *testing.T) {
-	_, err := buildDashboard([]*megaport.Port{nil}, nil, nil, nil, nil)
+	_, err := buildDashboard(statusResources{ports: []*megaport.Port{nil}}, time.Now())
 	assert.Error(t, err)
 }
 
 // TestBuildDashboard_NilMCR verifies buildDashboard returns an error for a nil MCR.
 func TestBuildDashboard_NilMCR(t *testing.T) {
-	_, err := buildDashboard(nil, []*megaport.MCR{nil}, nil, nil, nil)
+	_, err := buildDashboard(statusResources{mcrs: []*megaport.MCR{nil}}, time.Now())
 	assert.Error(t, err)
 }
 
 // TestBuildDashboard_NilMVE verifies buildDashboard returns an error for a nil MVE.
 func TestBuildDashboard_NilMVE(t *testing.T) {
-	_, err := buildDashboard(nil, nil, []*megaport.MVE{nil}, nil, nil)
+	_, err := buildDashboard(statusResources{mves: []*megaport.MVE{nil}}, time.Now())
 	assert.Error(t, err)
 }
 
 // TestBuildDashboard_NilVXC verifies buildDashboard returns an error for a nil VXC.
 func TestBuildDashboard_NilVXC(t *testing.T) {
-	_, err := buildDashboard(nil, nil, nil, []*megaport.VXC{nil}, nil)
+	_, err := buildDashboard(statusResources{vxcs: []*megaport.VXC{nil}}, time.Now())
 	assert.Error(t, err)
 }
 
 // TestBuildDashboard_NilIX verifies buildDashboard returns an error for a nil IX.
 func TestBuildDashboard_NilIX(t *testing.T) {
-	_, err := buildDashboard(nil, nil, nil, nil, []*megaport.IX{nil})
+	_, err := buildDashboard(statusResources{ixs: []*megaport.IX{nil}}, time.Now())
+	assert.Error(t, err)
+}
+
+// TestBuildDashboard_NilNATGateway verifies buildDashboard returns an error for a nil NAT Gateway.
+func TestBuildDashboard_NilNATGateway(t *testing.T) {
+	_, err := buildDashboard(statusResources{natGateways: []*megaport.NATGateway{nil}}, time.Now())
+	assert.Error(t, err)
+}
+
+// TestBuildDashboard_NilServiceKey verifies buildDashboard returns an error for a nil service key.
+func TestBuildDashboard_NilServiceKey(t *testing.T) {
+	_, err := buildDashboard(statusResources{serviceKeys: []*megaport.ServiceKey{nil}}, time.Now())
 	assert.Error(t, err)
 }
 
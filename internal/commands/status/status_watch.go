@@ -0,0 +1,235 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/commands/config"
+	"github.com/megaport/megaport-cli/internal/utils"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/spf13/cobra"
+)
+
+// changedMarker is appended to the status of a row whose status changed since
+// the last poll.
+const changedMarker = " *"
+
+// maxStatusChanges bounds the event log so the dashboard stays on one screen.
+const maxStatusChanges = 20
+
+// Stand-ins for the status of a resource before it appeared or after it left
+// the dashboard, used in transition chains.
+const (
+	statusAdded   = "(new)"
+	statusRemoved = "(removed)"
+)
+
+// statusChange is a provisioning status transition seen by status --watch.
+// From is empty for a resource that appeared and To for one that disappeared.
+type statusChange struct {
+	output.Output `json:"-" header:"-" xml:"-"`
+	Time          string `json:"time" header:"-" xml:"time"`
+	Clock         string `json:"-" header:"Time" xml:"-"` // time of day only, to keep the event log narrow
+	Type          string `json:"type" header:"Type" xml:"type"`
+	UID           string `json:"uid" header:"UID" xml:"uid"`
+	Name          string `json:"name" header:"Name" xml:"name"`
+	From          string `json:"from" header:"-" xml:"from"`
+	To            string `json:"to" header:"-" xml:"to"`
+	Transition    string `json:"-" header:"Transition" xml:"-"` // every status seen, e.g. "DEPLOYABLE → CONFIGURED → LIVE"
+}
+
+// trackedResource is a dashboard row as the tracker sees it.
+type trackedResource struct {
+	typ    string
+	uid    string
+	name   string
+	status string
+}
+
+func (r trackedResource) key() string {
+	return r.typ + "/" + r.uid
+}
+
+// statusTracker follows the statuses of dashboard resources across polls.
+type statusTracker struct {
+	polled  bool
+	last    map[string]trackedResource // by key, as of the last poll
+	history map[string][]string        // by key, every status seen, oldest first
+	log     []statusChange             // oldest first, at most maxStatusChanges
+}
+
+func newStatusTracker() *statusTracker {
+	return &statusTracker{
+		last:    map[string]trackedResource{},
+		history: map[string][]string{},
+	}
+}
+
+// observe records the statuses in dashboard, polled at now, and returns what
+// changed since the previous poll. The first poll is the baseline and
+// reports no changes.
+func (t *statusTracker) observe(dashboard dashboardOutput, now time.Time) []statusChange {
+	var changes []statusChange
+	record := func(r trackedResource, from, to, chained string) {
+		t.history[r.key()] = append(t.history[r.key()], chained)
+		changes = append(changes, statusChange{
+			Time:       now.Format(time.RFC3339),
+			Clock:      now.Format("15:04:05"),
+			Type:       r.typ,
+			UID:        r.uid,
+			Name:       r.name,
+			From:       from,
+			To:         to,
+			Transition: strings.Join(t.history[r.key()], " → "),
+		})
+	}
+
+	current := map[string]trackedResource{}
+	for _, r := range dashboardResources(dashboard) {
+		current[r.key()] = r
+		prev, ok := t.last[r.key()]
+		switch {
+		case !t.polled:
+			t.history[r.key()] = []string{r.status}
+		case !ok:
+			if len(t.history[r.key()]) == 0 {
+				t.history[r.key()] = []string{statusAdded}
+			}
+			record(r, "", r.status, r.status)
+		case prev.status != r.status:
+			record(r, prev.status, r.status, r.status)
+		}
+	}
+	if t.polled {
+		var gone []string
+		for key := range t.last {
+			if _, ok := current[key]; !ok {
+				gone = append(gone, key)
+			}
+		}
+		sort.Strings(gone)
+		for _, key := range gone {
+			r := t.last[key]
+			record(r, r.status, "", statusRemoved)
+		}
+	}
+
+	t.polled = true
+	t.last = current
+	t.log = append(t.log, changes...)
+	if len(t.log) > maxStatusChanges {
+		t.log = t.log[len(t.log)-maxStatusChanges:]
+	}
+	return changes
+}
+
+// dashboardResources lists the rows of dashboard, in dashboard order.
+// Rows without a UID cannot be followed between polls and are left out.
+func dashboardResources(dashboard dashboardOutput) []trackedResource {
+	var rs []trackedResource
+	add := func(typ, uid, name, status string) {
+		if uid != "" {
+			rs = append(rs, trackedResource{typ: typ, uid: uid, name: name, status: status})
+		}
+	}
+	for _, p := range dashboard.Ports {
+		add("Port", p.UID, p.Name, p.Status)
+	}
+	for _, m := range dashboard.MCRs {
+		add("MCR", m.UID, m.Name, m.Status)
+	}
+	for _, m := range dashboard.MVEs {
+		add("MVE", m.UID, m.Name, m.Status)
+	}
+	for _, v := range dashboard.VXCs {
+		add("VXC", v.UID, v.Name, v.Status)
+	}
+	for _, i := range dashboard.IXs {
+		add("IX", i.UID, i.Name, i.Status)
+	}
+	for _, g := range dashboard.NATGateways {
+		add("NAT Gateway", g.UID, g.Name, g.Status)
+	}
+	return rs
+}
+
+// markChanged appends changedMarker to the status of every row of dashboard
+// that changes lists.
+func markChanged(dashboard *dashboardOutput, changes []statusChange) {
+	changed := map[string]bool{}
+	for _, c := range changes {
+		changed[c.Type+"/"+c.UID] = true
+	}
+	mark := func(typ, uid string, status *string) {
+		if changed[typ+"/"+uid] {
+			*status += changedMarker
+		}
+	}
+	for i := range dashboard.Ports {
+		mark("Port", dashboard.Ports[i].UID, &dashboard.Ports[i].Status)
+	}
+	for i := range dashboard.MCRs {
+		mark("MCR", dashboard.MCRs[i].UID, &dashboard.MCRs[i].Status)
+	}
+	for i := range dashboard.MVEs {
+		mark("MVE", dashboard.MVEs[i].UID, &dashboard.MVEs[i].Status)
+	}
+	for i := range dashboard.VXCs {
+		mark("VXC", dashboard.VXCs[i].UID, &dashboard.VXCs[i].Status)
+	}
+	for i := range dashboard.IXs {
+		mark("IX", dashboard.IXs[i].UID, &dashboard.IXs[i].Status)
+	}
+	for i := range dashboard.NATGateways {
+		mark("NAT Gateway", dashboard.NATGateways[i].UID, &dashboard.NATGateways[i].Status)
+	}
+}
+
+// watchDashboard redraws the dashboard every --interval until interrupted,
+// tracking each resource's status between polls.
+func watchDashboard(cmd *cobra.Command, includeInactive, noColor bool, outputFormat string) error {
+	tracker := newStatusTracker()
+	return utils.WatchResource(cmd, "status", "dashboard", noColor, outputFormat, config.Login,
+		func(pollCtx context.Context, client *megaport.Client) (string, error) {
+			dashboard, err := fetchDashboard(pollCtx, client, includeInactive, noColor)
+			if err != nil {
+				return "", err
+			}
+			changes := tracker.observe(dashboard, time.Now())
+			// The dashboard has no single status for WatchLoop to report;
+			// changes are reported per resource instead.
+			return "", printWatchedDashboard(cmd.OutOrStdout(), dashboard, changes, tracker.log, outputFormat, noColor)
+		})
+}
+
+// printWatchedDashboard prints one poll of status --watch. Tables mark the
+// rows that changed and end with the event log; other formats carry this
+// poll's changes in the dashboard itself.
+func printWatchedDashboard(w io.Writer, dashboard dashboardOutput, changes, events []statusChange, format string, noColor bool) error {
+	switch format {
+	case "json", "xml", "csv":
+		dashboard.Changes = changes
+		return printDashboard(w, dashboard, format, noColor)
+	}
+
+	markChanged(&dashboard, changes)
+	if err := printDashboardTable(w, dashboard, noColor); err != nil {
+		return err
+	}
+	// The event log goes to w with the rows it explains, so it is redrawn
+	// along with them.
+	if len(changes) > 0 {
+		fmt.Fprintf(w, "Statuses marked%s changed since the last poll.\n", changedMarker)
+	}
+	fmt.Fprintf(w, "\nEVENTS (%d)\n", len(events))
+	if len(events) == 0 {
+		fmt.Fprintln(w, "No status changes yet.")
+		return nil
+	}
+	return output.PrintTableToWriter(w, events, noColor)
+}
@@ -0,0 +1,163 @@
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	op "github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withVXCStatus returns the test dashboard with vxc-1 in status.
+func withVXCStatus(t *testing.T, status string) dashboardOutput {
+	t.Helper()
+	dashboard := statusTestDashboard(t)
+	dashboard.VXCs[0].Status = status
+	return dashboard
+}
+
+var pollTime = time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+
+// ── statusTracker ─────────────────────────────────────────────────────────────
+
+func TestStatusTracker_Transitions(t *testing.T) {
+	tracker := newStatusTracker()
+
+	assert.Empty(t, tracker.observe(withVXCStatus(t, "DEPLOYABLE"), pollTime), "the first poll is the baseline")
+	assert.Empty(t, tracker.observe(withVXCStatus(t, "DEPLOYABLE"), pollTime))
+
+	changes := tracker.observe(withVXCStatus(t, "CONFIGURED"), pollTime)
+	require.Len(t, changes, 1)
+	assert.Equal(t, statusChange{
+		Time: "2026-10-16T09:30:00Z", Clock: "09:30:00", Type: "VXC", UID: "vxc-1", Name: "VXC One",
+		From: "DEPLOYABLE", To: "CONFIGURED", Transition: "DEPLOYABLE → CONFIGURED",
+	}, changes[0])
+
+	changes = tracker.observe(withVXCStatus(t, "LIVE"), pollTime)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "DEPLOYABLE → CONFIGURED → LIVE", changes[0].Transition)
+	assert.Len(t, tracker.log, 2, "the event log keeps every change")
+}
+
+func TestStatusTracker_AddedAndRemoved(t *testing.T) {
+	tracker := newStatusTracker()
+	tracker.observe(statusTestDashboard(t), pollTime)
+
+	dashboard := statusTestDashboard(t)
+	dashboard.Ports = append(dashboard.Ports, statusPortOutput{UID: "port-2", Name: "Port Two", Status: "DEPLOYABLE"})
+	dashboard.IXs = nil
+	changes := tracker.observe(dashboard, pollTime)
+
+	require.Len(t, changes, 2)
+	assert.Equal(t, "port-2", changes[0].UID)
+	assert.Equal(t, "", changes[0].From)
+	assert.Equal(t, "(new) → DEPLOYABLE", changes[0].Transition)
+	assert.Equal(t, "ix-1", changes[1].UID)
+	assert.Equal(t, "", changes[1].To)
+	assert.Equal(t, "LIVE → (removed)", changes[1].Transition)
+
+	changes = tracker.observe(statusTestDashboard(t), pollTime)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "LIVE → (removed) → LIVE", changes[0].Transition, "a resource that returns keeps its history")
+}
+
+func TestStatusTracker_LogIsBounded(t *testing.T) {
+	tracker := newStatusTracker()
+	statuses := []string{"DEPLOYABLE", "CONFIGURED"}
+	for i := 0; i <= maxStatusChanges+5; i++ {
+		tracker.observe(withVXCStatus(t, statuses[i%2]), pollTime)
+	}
+	assert.Len(t, tracker.log, maxStatusChanges)
+}
+
+func TestMarkChanged(t *testing.T) {
+	dashboard := withVXCStatus(t, "LIVE")
+	markChanged(&dashboard, []statusChange{{Type: "VXC", UID: "vxc-1"}, {Type: "Port", UID: "port-9"}})
+
+	assert.Equal(t, "LIVE *", dashboard.VXCs[0].Status)
+	assert.Equal(t, "LIVE", dashboard.Ports[0].Status)
+}
+
+// ── printWatchedDashboard ─────────────────────────────────────────────────────
+
+func TestPrintWatchedDashboard_Table(t *testing.T) {
+	withOutputFormat(t, "table")
+	tracker := newStatusTracker()
+	tracker.observe(withVXCStatus(t, "CONFIGURED"), pollTime)
+	changes := tracker.observe(withVXCStatus(t, "LIVE"), pollTime)
+
+	out := op.CaptureOutput(func() {
+		err := printWatchedDashboard(os.Stdout, withVXCStatus(t, "LIVE"), changes, tracker.log, "table", true)
+		assert.NoError(t, err)
+	})
+
+	assert.Contains(t, out, "LIVE *")
+	assert.Contains(t, out, "EVENTS (1)")
+	assert.Contains(t, out, "CONFIGURED → LIVE")
+}
+
+func TestPrintWatchedDashboard_NoEvents(t *testing.T) {
+	withOutputFormat(t, "table")
+	out := op.CaptureOutput(func() {
+		err := printWatchedDashboard(os.Stdout, statusTestDashboard(t), nil, nil, "table", true)
+		assert.NoError(t, err)
+	})
+
+	assert.NotContains(t, out, " *")
+	assert.Contains(t, out, "EVENTS (0)")
+}
+
+func TestPrintWatchedDashboard_EventLogUsesWriter(t *testing.T) {
+	withOutputFormat(t, "table")
+	tracker := newStatusTracker()
+	tracker.observe(withVXCStatus(t, "CONFIGURED"), pollTime)
+	changes := tracker.observe(withVXCStatus(t, "LIVE"), pollTime)
+
+	var buf bytes.Buffer
+	op.CaptureOutput(func() {
+		err := printWatchedDashboard(&buf, withVXCStatus(t, "LIVE"), changes, tracker.log, "table", true)
+		assert.NoError(t, err)
+	})
+
+	assert.Contains(t, buf.String(), "Statuses marked * changed since the last poll.")
+	assert.Contains(t, buf.String(), "EVENTS (1)")
+	assert.Contains(t, buf.String(), "CONFIGURED → LIVE")
+}
+
+func TestPrintWatchedDashboard_JSON(t *testing.T) {
+	withOutputFormat(t, "json")
+	tracker := newStatusTracker()
+	tracker.observe(withVXCStatus(t, "CONFIGURED"), pollTime)
+	changes := tracker.observe(withVXCStatus(t, "LIVE"), pollTime)
+
+	out := op.CaptureOutput(func() {
+		err := printWatchedDashboard(os.Stdout, withVXCStatus(t, "LIVE"), changes, tracker.log, "json", true)
+		assert.NoError(t, err)
+	})
+
+	var decoded struct {
+		VXCs    []statusVXCOutput `json:"vxcs"`
+		Changes []statusChange    `json:"changes"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+	assert.Equal(t, "LIVE", decoded.VXCs[0].Status, "only tables mark changed rows")
+	require.Len(t, decoded.Changes, 1)
+	assert.Equal(t, "CONFIGURED", decoded.Changes[0].From)
+	assert.Equal(t, "LIVE", decoded.Changes[0].To)
+}
+
+// ── StatusDashboard --watch ───────────────────────────────────────────────────
+
+func TestStatusDashboard_WatchInvalidInterval(t *testing.T) {
+	cmd := newStatusCmd()
+	cmd.Flags().Bool("watch", false, "")
+	cmd.Flags().Duration("interval", 0, "")
+	cmd.SetArgs([]string{"--watch"})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "--interval must be greater than 0")
+}
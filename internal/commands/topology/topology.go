@@ -9,17 +9,48 @@ import (
 func AddCommandsTo(rootCmd *cobra.Command) {
 	topologyCmd := cmdbuilder.NewCommand("topology", "Show resource relationship tree").
 		WithOutputFormatRunFunc(ShowTopology).
+		WithExtraOutputFormats(diagramFormats...).
 		WithBoolFlag("include-inactive", false, "Include deprovisioned resources in the tree").
-		WithFlag("type", "", "Filter by resource type: port, mcr, or mve").
-		WithLongDesc("Show a tree view of Megaport resources and their VXC connections.\n\nThis command fetches all Ports, MCRs, and MVEs and renders each with its associated Virtual Cross Connects (VXCs) as a tree. The B-End destination of each VXC is shown to illustrate connectivity.\n\nDefault output is a human-readable ASCII tree. Use --output json for structured output.").
+		WithFlag("type", "", "Filter by resource type: port, mcr, mve, or nat-gateway").
+		WithLongDesc("Show a tree view of Megaport resources and their VXC connections.\n\nThis command fetches all Ports, MCRs, MVEs, and NAT Gateways and renders each with its associated Virtual Cross Connects (VXCs) and Internet Exchange (IX) connections as a tree. The B-End destination of each VXC is shown to illustrate connectivity; when the B-End is a cloud or partner port, such as an AWS, Azure, or Google Cloud port, it is named with its partner.\n\nDefault output is a human-readable ASCII tree. Use --output json for structured output.\n\nUse --output dot, mermaid or d2 to render the topology as a diagram for Graphviz, Mermaid or D2. Ports, MCRs, MVEs, NAT Gateways and IXs are drawn as nodes grouped by location, and each VXC is an edge labelled with its rate limit and VLANs. B-Ends outside the listed resources, such as partner and cloud ports, are drawn as distinct external nodes.").
 		WithExample("megaport-cli topology").
 		WithExample("megaport-cli topology --output json").
+		WithExample("megaport-cli topology --output dot | dot -Tsvg > topology.svg").
+		WithExample("megaport-cli topology --output mermaid > topology.mmd").
+		WithExample("megaport-cli topology --output d2 | d2 - topology.svg").
 		WithExample("megaport-cli topology --type mcr").
+		WithExample("megaport-cli topology --type nat-gateway").
 		WithExample("megaport-cli topology --include-inactive").
+		WithExample("megaport-cli topology impact port-uid").
+		WithExample("megaport-cli topology path mcr-uid azure-port-uid").
 		WithImportantNote("Each VXC is shown once, under its A-End parent resource").
+		WithImportantNote("Diagram formats (dot, mermaid, d2) write the diagram source to stdout; render it with the matching tool").
 		WithImportantNote("CSV and XML output formats are not supported for hierarchical topology data").
 		WithRootCmd(rootCmd).
 		Build()
 
+	impactCmd := cmdbuilder.NewCommand("impact", "Show what depends on a resource").
+		WithArgs(cobra.ExactArgs(1)).
+		WithOutputFormatRunFunc(ShowImpact).
+		WithLongDesc("Show what depends on a resource: what is affected if it goes down.\n\nThe resource can be a Port, MCR, MVE, NAT Gateway, VXC, IX, or a partner port that VXCs end on, given by UID. The VXCs and IXs attached to it are listed as direct impacts. Traffic is then followed through the VXCs, MCRs, MVEs, and NAT Gateways it passes, and every resource reached, including downstream cloud connections, is listed as a downstream impact. Ports and partner ports are where traffic ends, so the search stops at them.\n\nThe Via column gives the UID of the resource each impact is reached through.").
+		WithExample("megaport-cli topology impact port-uid").
+		WithExample("megaport-cli topology impact mcr-uid --output json").
+		WithImportantNote("Only active resources are considered").
+		WithRootCmd(rootCmd).
+		Build()
+
+	pathCmd := cmdbuilder.NewCommand("path", "Find how two resources are connected").
+		WithArgs(cobra.ExactArgs(2)).
+		WithOutputFormatRunFunc(ShowPath).
+		WithIntFlag("max-paths", 10, "Maximum number of paths to show, shortest first").
+		WithLongDesc("Find the connectivity paths between two resources, given by UID.\n\nA path runs from the first resource to the second through VXCs and the MCRs, MVEs, and NAT Gateways that route traffic between them; it does not pass through other Ports or partner ports. Either resource can be a Port, MCR, MVE, NAT Gateway, VXC, IX, or a partner port such as an AWS, Azure, or Google Cloud port. Partner port UIDs are listed as bEndUid in megaport-cli topology --output json.\n\nPaths are listed shortest first, one row per hop.").
+		WithExample("megaport-cli topology path mcr-uid azure-port-uid").
+		WithExample("megaport-cli topology path port-a-uid port-b-uid --max-paths 3").
+		WithExample("megaport-cli topology path port-uid mcr-uid --output json").
+		WithImportantNote("Only active resources are considered").
+		WithRootCmd(rootCmd).
+		Build()
+
+	topologyCmd.AddCommand(impactCmd, pathCmd)
 	rootCmd.AddCommand(topologyCmd)
 }
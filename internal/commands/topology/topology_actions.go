@@ -1,6 +1,7 @@
 package topology
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
 	"github.com/megaport/megaport-cli/internal/base/output"
 	"github.com/megaport/megaport-cli/internal/commands/config"
 	"github.com/megaport/megaport-cli/internal/utils"
@@ -15,7 +17,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// TopologyNode represents a parent resource (Port, MCR, or MVE) with its VXC connections.
+// TopologyNode represents a parent resource (Port, MCR, MVE, or NAT Gateway)
+// with its VXC connections and IXs.
 type TopologyNode struct {
 	UID         string        `json:"uid"`
 	Name        string        `json:"name"`
@@ -24,6 +27,7 @@ type TopologyNode struct {
 	SpeedMbps   int           `json:"speedMbps"`
 	Location    string        `json:"location"`
 	Connections []TopologyVXC `json:"connections"`
+	IXs         []TopologyIX  `json:"ixs,omitempty"`
 }
 
 // TopologyVXC represents a VXC connection hanging off a parent node.
@@ -32,9 +36,26 @@ type TopologyVXC struct {
 	Name         string `json:"name"`
 	Status       string `json:"status"`
 	RateMbps     int    `json:"rateMbps"`
+	AEndVLAN     int    `json:"aEndVlan"`
+	BEndVLAN     int    `json:"bEndVlan"`
 	BEndUID      string `json:"bEndUid"`
 	BEndName     string `json:"bEndName"`
 	BEndLocation string `json:"bEndLocation"`
+	// BEndPartner and BEndConnectType are set when the B-End is a partner
+	// port, such as an AWS, Azure or Google Cloud port.
+	BEndPartner     string `json:"bEndPartner,omitempty"`
+	BEndConnectType string `json:"bEndConnectType,omitempty"`
+}
+
+// TopologyIX represents an Internet Exchange connection on a parent node.
+type TopologyIX struct {
+	UID                string `json:"uid"`
+	Name               string `json:"name"`
+	Status             string `json:"status"`
+	RateMbps           int    `json:"rateMbps"`
+	VLAN               int    `json:"vlan"`
+	ASN                int    `json:"asn"`
+	NetworkServiceType string `json:"networkServiceType"`
 }
 
 // ShowTopology is the cobra run function for the topology command.
@@ -55,23 +76,126 @@ func ShowTopology(cmd *cobra.Command, args []string, noColor bool, outputFormat
 	typeFilter, _ := cmd.Flags().GetString("type")
 	typeFilter = strings.ToLower(strings.TrimSpace(typeFilter))
 	switch typeFilter {
-	case "", "port", "mcr", "mve":
+	case "", "port", "mcr", "mve", "nat-gateway":
+	default:
+		return fmt.Errorf("invalid value for --type: %q (must be one of: port, mcr, mve, nat-gateway)", typeFilter)
+	}
+
+	nodes, err := fetchTopology(ctx, client, typeFilter, includeInactive, noColor)
+	if err != nil {
+		return err
+	}
+
+	switch outputFormat {
+	case "json":
+		jsonBytes, err := json.MarshalIndent(nodes, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal topology: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(jsonBytes))
+	case formatDOT, formatMermaid, formatD2:
+		fmt.Fprint(cmd.OutOrStdout(), renderDiagram(nodes, outputFormat))
+	case "csv", "xml":
+		return fmt.Errorf("output format %q is not supported for topology — use table (default), json, dot, mermaid or d2", outputFormat)
 	default:
-		return fmt.Errorf("invalid value for --type: %q (must be one of: port, mcr, mve)", typeFilter)
+		fmt.Fprint(cmd.OutOrStdout(), renderTree(nodes, noColor))
+	}
+
+	return nil
+}
+
+// ShowImpact is the cobra run function for the topology impact command.
+func ShowImpact(cmd *cobra.Command, args []string, noColor bool, outputFormat string) error {
+	output.SetOutputFormat(outputFormat)
+
+	graph, err := loadTopologyGraph(cmd, noColor)
+	if err != nil {
+		return err
+	}
+	impacted, err := graph.impact(args[0])
+	if err != nil {
+		output.PrintError("%v", noColor, err)
+		return exitcodes.NewUsageError(err)
+	}
+	if len(impacted) == 0 && outputFormat == "table" {
+		output.PrintInfo("Nothing depends on %s", noColor, args[0])
+		return nil
+	}
+	return output.PrintOutput(impacted, outputFormat, noColor)
+}
+
+// ShowPath is the cobra run function for the topology path command.
+func ShowPath(cmd *cobra.Command, args []string, noColor bool, outputFormat string) error {
+	output.SetOutputFormat(outputFormat)
+
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	maxPaths, _ := cmd.Flags().GetInt("max-paths")
+	if maxPaths < 1 {
+		return exitcodes.NewUsageError(fmt.Errorf("--max-paths must be at least 1"))
+	}
+
+	graph, err := loadTopologyGraph(cmd, noColor)
+	if err != nil {
+		return err
+	}
+	paths, err := graph.paths(args[0], args[1], maxPaths)
+	if err != nil {
+		output.PrintError("%v", noColor, err)
+		return exitcodes.NewUsageError(err)
+	}
+
+	if outputFormat == "json" {
+		jsonBytes, err := json.MarshalIndent(paths, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal paths: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(jsonBytes))
+		return nil
+	}
+	if len(paths) == 0 && outputFormat == "table" {
+		output.PrintInfo("No path connects %s and %s", noColor, args[0], args[1])
+		return nil
+	}
+	return output.PrintOutput(pathRows(paths), outputFormat, noColor)
+}
+
+// loadTopologyGraph logs in and builds the graph of the account's active
+// resources.
+func loadTopologyGraph(cmd *cobra.Command, noColor bool) (*topologyGraph, error) {
+	ctx, cancel := utils.ContextFromCmdWithDefault(cmd, 120*time.Second)
+	defer cancel()
+
+	client, err := config.Login(ctx)
+	if err != nil {
+		output.PrintError("Failed to log in: %v", noColor, err)
+		return nil, fmt.Errorf("failed to log in: %w", err)
+	}
+	nodes, err := fetchTopology(ctx, client, "", false, noColor)
+	if err != nil {
+		return nil, err
 	}
+	return buildTopologyGraph(nodes), nil
+}
 
-	// Fetch ports, MCRs, and MVEs in parallel.
+// fetchTopology lists the account's resources and builds the topology from
+// them. typeFilter limits the parent resources as for the --type flag.
+func fetchTopology(ctx context.Context, client *megaport.Client, typeFilter string, includeInactive, noColor bool) ([]TopologyNode, error) {
+	// Fetch ports, MCRs, MVEs, NAT Gateways, and partner ports in parallel.
 	var (
-		ports    []*megaport.Port
-		mcrs     []*megaport.MCR
-		mves     []*megaport.MVE
-		portsErr error
-		mcrsErr  error
-		mvesErr  error
-		wg       sync.WaitGroup
+		ports       []*megaport.Port
+		mcrs        []*megaport.MCR
+		mves        []*megaport.MVE
+		natGateways []*megaport.NATGateway
+		partners    []*megaport.PartnerMegaport
+		portsErr    error
+		mcrsErr     error
+		mvesErr     error
+		natErr      error
+		partnersErr error
+		wg          sync.WaitGroup
 	)
 
-	wg.Add(3)
+	wg.Add(5)
 	go func() {
 		defer wg.Done()
 		ports, portsErr = client.PortService.ListPorts(ctx)
@@ -84,41 +208,50 @@ func ShowTopology(cmd *cobra.Command, args []string, noColor bool, outputFormat
 		defer wg.Done()
 		mves, mvesErr = client.MVEService.ListMVEs(ctx, &megaport.ListMVEsRequest{IncludeInactive: includeInactive})
 	}()
+	go func() {
+		defer wg.Done()
+		natGateways, natErr = client.NATGatewayService.ListNATGateways(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		partners, partnersErr = client.PartnerService.ListPartnerMegaports(ctx)
+	}()
 	wg.Wait()
 
 	if portsErr != nil {
 		output.PrintError("Failed to list ports: %v", noColor, portsErr)
-		return fmt.Errorf("failed to list ports: %w", portsErr)
+		return nil, fmt.Errorf("failed to list ports: %w", portsErr)
 	}
 	if mcrsErr != nil {
 		output.PrintError("Failed to list MCRs: %v", noColor, mcrsErr)
-		return fmt.Errorf("failed to list MCRs: %w", mcrsErr)
+		return nil, fmt.Errorf("failed to list MCRs: %w", mcrsErr)
 	}
 	if mvesErr != nil {
 		output.PrintError("Failed to list MVEs: %v", noColor, mvesErr)
-		return fmt.Errorf("failed to list MVEs: %w", mvesErr)
+		return nil, fmt.Errorf("failed to list MVEs: %w", mvesErr)
+	}
+	if natErr != nil {
+		output.PrintError("Failed to list NAT Gateways: %v", noColor, natErr)
+		return nil, fmt.Errorf("failed to list NAT Gateways: %w", natErr)
+	}
+	// Partner ports only name the B-Ends of VXCs, so the topology is still
+	// shown without them.
+	if partnersErr != nil {
+		output.PrintWarning("Failed to list partner ports, partner B-Ends will not be named: %v", noColor, partnersErr)
 	}
 
 	nodes := buildTopologyNodes(ports, mcrs, mves, typeFilter, includeInactive)
-
-	switch outputFormat {
-	case "json":
-		jsonBytes, err := json.MarshalIndent(nodes, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal topology: %w", err)
-		}
-		fmt.Fprintln(cmd.OutOrStdout(), string(jsonBytes))
-	case "csv", "xml":
-		return fmt.Errorf("output format %q is not supported for topology — use table (default) or json", outputFormat)
-	default:
-		fmt.Fprint(cmd.OutOrStdout(), renderTree(nodes, noColor))
+	if typeFilter == "" || typeFilter == "nat-gateway" {
+		locations := natGatewayLocations(ctx, client, natGateways, ports, mcrs, mves)
+		nodes = append(nodes, natGatewayNodes(natGateways, locations, includeInactive)...)
 	}
-
-	return nil
+	resolvePartnerBEnds(nodes, partners)
+	return nodes, nil
 }
 
 // buildTopologyNodes constructs the topology from the fetched resources.
 // Only VXCs where AEndConfiguration.UID == node.UID are included (avoids duplicates).
+// IXs are included under the resource they are attached to.
 func buildTopologyNodes(
 	ports []*megaport.Port,
 	mcrs []*megaport.MCR,
@@ -152,6 +285,7 @@ func buildTopologyNodes(
 				}
 				node.Connections = append(node.Connections, vxcToTopology(vxc))
 			}
+			node.IXs = ixsToTopology(p.AssociatedIXs, includeInactive)
 			nodes = append(nodes, node)
 		}
 	}
@@ -178,6 +312,7 @@ func buildTopologyNodes(
 				}
 				node.Connections = append(node.Connections, vxcToTopology(vxc))
 			}
+			node.IXs = ixsToTopology(m.AssociatedIXs, includeInactive)
 			nodes = append(nodes, node)
 		}
 	}
@@ -203,6 +338,7 @@ func buildTopologyNodes(
 				}
 				node.Connections = append(node.Connections, vxcToTopology(vxc))
 			}
+			node.IXs = ixsToTopology(mv.AssociatedIXs, includeInactive)
 			nodes = append(nodes, node)
 		}
 	}
@@ -210,6 +346,129 @@ func buildTopologyNodes(
 	return nodes
 }
 
+// natGatewayNodes converts NAT Gateways into topology nodes. NAT Gateways
+// carry no VXCs of their own; VXCs that end on one are listed under their
+// A-End parent and point to the gateway.
+func natGatewayNodes(gateways []*megaport.NATGateway, locations map[int]string, includeInactive bool) []TopologyNode {
+	var nodes []TopologyNode
+	for _, g := range gateways {
+		if g == nil {
+			continue
+		}
+		if !includeInactive && isInactive(g.ProvisioningStatus) {
+			continue
+		}
+		nodes = append(nodes, TopologyNode{
+			UID:       g.ProductUID,
+			Name:      g.ProductName,
+			Type:      "NAT Gateway",
+			Status:    g.ProvisioningStatus,
+			SpeedMbps: g.Speed,
+			Location:  locations[g.LocationID],
+		})
+	}
+	return nodes
+}
+
+// natGatewayLocations names the locations of NAT Gateways, which the API
+// gives only by ID. Names are taken from the other fetched resources where
+// they share a location, and looked up otherwise. A location that cannot be
+// looked up is shown by its ID.
+func natGatewayLocations(
+	ctx context.Context,
+	client *megaport.Client,
+	gateways []*megaport.NATGateway,
+	ports []*megaport.Port,
+	mcrs []*megaport.MCR,
+	mves []*megaport.MVE,
+) map[int]string {
+	known := map[int]string{}
+	for _, p := range ports {
+		if p != nil && p.LocationDetails != nil {
+			known[p.LocationID] = p.LocationDetails.Name
+		}
+	}
+	for _, m := range mcrs {
+		if m != nil && m.LocationDetails != nil {
+			known[m.LocationID] = m.LocationDetails.Name
+		}
+	}
+	for _, mv := range mves {
+		if mv != nil && mv.LocationDetails != nil {
+			known[mv.LocationID] = mv.LocationDetails.Name
+		}
+	}
+
+	locations := map[int]string{}
+	for _, g := range gateways {
+		if g == nil || g.LocationID == 0 {
+			continue
+		}
+		if _, ok := locations[g.LocationID]; ok {
+			continue
+		}
+		if name, ok := known[g.LocationID]; ok {
+			locations[g.LocationID] = name
+			continue
+		}
+		loc, err := client.LocationService.GetLocationByIDV3(ctx, g.LocationID)
+		if err != nil || loc == nil {
+			locations[g.LocationID] = fmt.Sprintf("Location %d", g.LocationID)
+			continue
+		}
+		locations[g.LocationID] = loc.Name
+	}
+	return locations
+}
+
+// resolvePartnerBEnds names the partner behind each VXC whose B-End is a
+// partner port, filling in the B-End name when the VXC does not carry one.
+func resolvePartnerBEnds(nodes []TopologyNode, partners []*megaport.PartnerMegaport) {
+	byUID := make(map[string]*megaport.PartnerMegaport, len(partners))
+	for _, p := range partners {
+		if p != nil {
+			byUID[p.ProductUID] = p
+		}
+	}
+	for i := range nodes {
+		for j := range nodes[i].Connections {
+			c := &nodes[i].Connections[j]
+			p, ok := byUID[c.BEndUID]
+			if !ok {
+				continue
+			}
+			c.BEndPartner = p.CompanyName
+			c.BEndConnectType = p.ConnectType
+			if c.BEndName == "" {
+				c.BEndName = p.ProductName
+			}
+		}
+	}
+}
+
+// ixsToTopology converts a resource's IXs, skipping inactive ones unless asked.
+func ixsToTopology(ixs []*megaport.IX, includeInactive bool) []TopologyIX {
+	var out []TopologyIX
+	for _, x := range ixs {
+		if x == nil {
+			continue
+		}
+		if !includeInactive && isInactive(x.ProvisioningStatus) {
+			continue
+		}
+		out = append(out, TopologyIX{
+			UID:                x.ProductUID,
+			Name:               x.ProductName,
+			Status:             x.ProvisioningStatus,
+			RateMbps:           x.RateLimit,
+			VLAN:               x.VLAN,
+			ASN:                x.ASN,
+			NetworkServiceType: x.NetworkServiceType,
+		})
+	}
+	return out
+}
+
 // locationName safely dereferences a *ProductLocationDetails Name.
 func locationName(d *megaport.ProductLocationDetails) string {
 	if d == nil {
@@ -224,6 +483,8 @@ func vxcToTopology(vxc *megaport.VXC) TopologyVXC {
 		Name:         vxc.Name,
 		Status:       vxc.ProvisioningStatus,
 		RateMbps:     vxc.RateLimit,
+		AEndVLAN:     vxc.AEndConfiguration.VLAN,
+		BEndVLAN:     vxc.BEndConfiguration.VLAN,
 		BEndUID:      vxc.BEndConfiguration.UID,
 		BEndName:     vxc.BEndConfiguration.Name,
 		BEndLocation: vxc.BEndConfiguration.Location,
@@ -291,27 +552,36 @@ func renderTree(nodes []TopologyNode, noColor bool) string {
 			speed,
 		)
 
-		if len(node.Connections) == 0 {
+		children := len(node.Connections) + len(node.IXs)
+		if children == 0 {
 			fmt.Fprintf(&sb, "  (no connections)\n")
 		} else {
 			for j, conn := range node.Connections {
-				isLast := j == len(node.Connections)-1
-				prefix := "├── "
-				if isLast {
-					prefix = "└── "
-				}
 				bEnd := conn.BEndName
 				if conn.BEndLocation != "" {
 					bEnd = fmt.Sprintf("%s (%s)", conn.BEndName, conn.BEndLocation)
 				}
+				if partner := partnerLabel(conn); partner != "" {
+					bEnd = fmt.Sprintf("%s [%s]", bEnd, partner)
+				}
 				fmt.Fprintf(&sb, "%s%s (%s, %s) → %s\n",
-					prefix,
+					treePrefix(j, children),
 					conn.Name,
 					statusBadge(conn.Status, noColor),
 					formatSpeed(conn.RateMbps),
 					bEnd,
 				)
 			}
+			for k, ix := range node.IXs {
+				fmt.Fprintf(&sb, "%sIX %s (%s, %s, VLAN %d) → %s\n",
+					treePrefix(len(node.Connections)+k, children),
+					ix.Name,
+					statusBadge(ix.Status, noColor),
+					formatSpeed(ix.RateMbps),
+					ix.VLAN,
+					ix.NetworkServiceType,
+				)
+			}
 		}
 
 		if i < len(nodes)-1 {
@@ -321,3 +591,24 @@ func renderTree(nodes []TopologyNode, noColor bool) string {
 
 	return sb.String()
 }
+
+// treePrefix returns the branch drawn before the i-th of n children.
+func treePrefix(i, n int) string {
+	if i == n-1 {
+		return "└── "
+	}
+	return "├── "
+}
+
+// partnerLabel names the partner of a VXC's B-End, e.g.
+// "Amazon Web Services (AWS)", or returns "" when it is not a partner port.
+func partnerLabel(c TopologyVXC) string {
+	switch {
+	case c.BEndPartner != "" && c.BEndConnectType != "":
+		return fmt.Sprintf("%s (%s)", c.BEndPartner, c.BEndConnectType)
+	case c.BEndPartner != "":
+		return c.BEndPartner
+	default:
+		return c.BEndConnectType
+	}
+}
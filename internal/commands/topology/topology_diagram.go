@@ -0,0 +1,344 @@
+package topology
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diagram output formats.
+const (
+	formatDOT     = "dot"
+	formatMermaid = "mermaid"
+	formatD2      = "d2"
+)
+
+// diagramFormats lists the --output formats that render the topology as a diagram.
+var diagramFormats = []string{formatDOT, formatMermaid, formatD2}
+
+// diagram is the topology laid out for the diagram renderers: resources grouped
+// by location, the external B-ends their VXCs reach, and one edge per VXC.
+type diagram struct {
+	groups    []diagramGroup
+	ungrouped []diagramNode // resources with no location
+	external  []diagramNode
+	edges     []diagramEdge
+}
+
+type diagramGroup struct {
+	id    string
+	label string
+	nodes []diagramNode
+}
+
+// Kinds of diagram node, each drawn with its own shape.
+const (
+	nodeResource = ""
+	nodeIX       = "ix"
+	nodeExternal = "external"
+)
+
+type diagramNode struct {
+	id    string
+	kind  string
+	group string // id of the node's location group, or ""
+	lines []string
+}
+
+type diagramEdge struct {
+	from  diagramNode
+	to    diagramNode
+	lines []string
+}
+
+// buildDiagram lays out nodes for a diagram. Each IX is drawn beside its
+// parent. A VXC whose B-End is one of nodes is drawn between the two; any
+// other B-End, such as a partner or cloud port, becomes an external node,
+// shared by every VXC that reaches it.
+func buildDiagram(nodes []TopologyNode) diagram {
+	var d diagram
+	byUID := map[string]diagramNode{}
+	groupIndex := map[string]int{}
+	ixCount := 0
+
+	place := func(dn diagramNode, location string) diagramNode {
+		if location == "" {
+			d.ungrouped = append(d.ungrouped, dn)
+			return dn
+		}
+		gi, ok := groupIndex[location]
+		if !ok {
+			gi = len(d.groups)
+			groupIndex[location] = gi
+			d.groups = append(d.groups, diagramGroup{id: fmt.Sprintf("loc%d", gi+1), label: location})
+		}
+		dn.group = d.groups[gi].id
+		d.groups[gi].nodes = append(d.groups[gi].nodes, dn)
+		return dn
+	}
+
+	for i, n := range nodes {
+		dn := place(diagramNode{id: fmt.Sprintf("n%d", i+1), lines: []string{n.Name, nodeDetail(n)}}, n.Location)
+		if n.UID != "" {
+			byUID[n.UID] = dn
+		}
+		for _, x := range n.IXs {
+			ixCount++
+			ixn := place(diagramNode{id: fmt.Sprintf("ix%d", ixCount), kind: nodeIX, lines: ixLines(x)}, n.Location)
+			d.edges = append(d.edges, diagramEdge{from: dn, to: ixn, lines: []string{rateAndVLAN(x.RateMbps, vlanLabel(x.VLAN, 0))}})
+		}
+	}
+
+	externalByUID := map[string]diagramNode{}
+	for i, n := range nodes {
+		from := byUID[n.UID]
+		if n.UID == "" {
+			from = diagramNode{id: fmt.Sprintf("n%d", i+1)}
+		}
+		for _, c := range n.Connections {
+			to, ok := byUID[c.BEndUID]
+			if !ok {
+				to, ok = externalByUID[c.BEndUID]
+			}
+			if !ok || c.BEndUID == "" {
+				to = diagramNode{id: fmt.Sprintf("ext%d", len(d.external)+1), kind: nodeExternal, lines: externalLines(c)}
+				d.external = append(d.external, to)
+				if c.BEndUID != "" {
+					externalByUID[c.BEndUID] = to
+				}
+			}
+			d.edges = append(d.edges, diagramEdge{from: from, to: to, lines: edgeLines(c)})
+		}
+	}
+	return d
+}
+
+// nodeDetail describes a resource under its name, e.g. "Port, 10 Gbps, LIVE".
+func nodeDetail(n TopologyNode) string {
+	parts := []string{n.Type}
+	if n.SpeedMbps > 0 {
+		parts = append(parts, formatSpeed(n.SpeedMbps))
+	}
+	if n.Status != "" {
+		parts = append(parts, n.Status)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ixLines labels an IX with its name and the exchange it connects to.
+func ixLines(x TopologyIX) []string {
+	detail := "IX"
+	if x.NetworkServiceType != "" {
+		detail += ", " + x.NetworkServiceType
+	}
+	if x.ASN != 0 {
+		detail += fmt.Sprintf(", AS%d", x.ASN)
+	}
+	return []string{x.Name, detail}
+}
+
+// externalLines labels a B-End outside the topology with its name, partner
+// and location.
+func externalLines(c TopologyVXC) []string {
+	name := c.BEndName
+	if name == "" {
+		name = c.BEndUID
+	}
+	if name == "" {
+		name = "(unknown B-End)"
+	}
+	lines := []string{name}
+	if p := partnerLabel(c); p != "" {
+		lines = append(lines, p)
+	}
+	if c.BEndLocation != "" {
+		lines = append(lines, c.BEndLocation)
+	}
+	return lines
+}
+
+// edgeLines labels a VXC with its name, rate limit and VLANs.
+func edgeLines(c TopologyVXC) []string {
+	return []string{c.Name, rateAndVLAN(c.RateMbps, vlanLabel(c.AEndVLAN, c.BEndVLAN))}
+}
+
+// rateAndVLAN joins a rate limit and a VLAN label, which may be empty.
+func rateAndVLAN(rateMbps int, vlan string) string {
+	if vlan == "" {
+		return formatSpeed(rateMbps)
+	}
+	return formatSpeed(rateMbps) + ", " + vlan
+}
+
+// vlanLabel describes the VLANs of a VXC's ends: "VLAN 100" when only the
+// A-End is tagged or both ends share it, "VLAN 100/200" for A-End/B-End.
+func vlanLabel(aEnd, bEnd int) string {
+	switch {
+	case aEnd == 0 && bEnd == 0:
+		return ""
+	case bEnd == 0 || aEnd == bEnd:
+		return fmt.Sprintf("VLAN %d", aEnd)
+	case aEnd == 0:
+		return fmt.Sprintf("B-End VLAN %d", bEnd)
+	default:
+		return fmt.Sprintf("VLAN %d/%d", aEnd, bEnd)
+	}
+}
+
+// renderDiagram renders nodes in one of diagramFormats.
+func renderDiagram(nodes []TopologyNode, format string) string {
+	d := buildDiagram(nodes)
+	switch format {
+	case formatMermaid:
+		return renderMermaid(d)
+	case formatD2:
+		return renderD2(d)
+	default:
+		return renderDOT(d)
+	}
+}
+
+// renderDOT renders a Graphviz DOT digraph. Locations are clusters, IXs are
+// hexagons and external B-Ends are dashed ellipses.
+func renderDOT(d diagram) string {
+	var sb strings.Builder
+	sb.WriteString("digraph topology {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box, style=rounded];\n")
+	for _, g := range d.groups {
+		fmt.Fprintf(&sb, "  subgraph %s {\n", dotQuote("cluster_"+g.id))
+		fmt.Fprintf(&sb, "    label=%s;\n", dotQuote(g.label))
+		for _, n := range g.nodes {
+			sb.WriteString("    " + dotNode(n))
+		}
+		sb.WriteString("  }\n")
+	}
+	for _, n := range d.ungrouped {
+		sb.WriteString("  " + dotNode(n))
+	}
+	for _, n := range d.external {
+		sb.WriteString("  " + dotNode(n))
+	}
+	for _, e := range d.edges {
+		fmt.Fprintf(&sb, "  %s -> %s [label=%s];\n", dotQuote(e.from.id), dotQuote(e.to.id), dotQuote(strings.Join(e.lines, "\n")))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// dotNode returns the DOT statement declaring n.
+func dotNode(n diagramNode) string {
+	attrs := "label=" + dotQuote(strings.Join(n.lines, "\n"))
+	switch n.kind {
+	case nodeIX:
+		attrs += ", shape=hexagon"
+	case nodeExternal:
+		attrs += ", shape=ellipse, style=dashed"
+	}
+	return fmt.Sprintf("%s [%s];\n", dotQuote(n.id), attrs)
+}
+
+// dotQuote returns s as a DOT quoted string.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// renderMermaid renders a Mermaid flowchart. Locations are subgraphs, IXs are
+// stadiums and external B-Ends are hexagons.
+func renderMermaid(d diagram) string {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for _, g := range d.groups {
+		fmt.Fprintf(&sb, "  subgraph %s[%s]\n", g.id, mermaidQuote([]string{g.label}))
+		for _, n := range g.nodes {
+			sb.WriteString("    " + mermaidNode(n))
+		}
+		sb.WriteString("  end\n")
+	}
+	for _, n := range d.ungrouped {
+		sb.WriteString("  " + mermaidNode(n))
+	}
+	for _, n := range d.external {
+		sb.WriteString("  " + mermaidNode(n))
+	}
+	for _, e := range d.edges {
+		fmt.Fprintf(&sb, "  %s -->|%s| %s\n", e.from.id, mermaidQuote(e.lines), e.to.id)
+	}
+	return sb.String()
+}
+
+// mermaidNode returns the Mermaid statement declaring n.
+func mermaidNode(n diagramNode) string {
+	label := mermaidQuote(n.lines)
+	switch n.kind {
+	case nodeIX:
+		return fmt.Sprintf("%s([%s])\n", n.id, label)
+	case nodeExternal:
+		return fmt.Sprintf("%s{{%s}}\n", n.id, label)
+	default:
+		return fmt.Sprintf("%s[%s]\n", n.id, label)
+	}
+}
+
+// mermaidQuote returns lines as a Mermaid quoted label. Characters Mermaid
+// would read as syntax are written as entity codes.
+func mermaidQuote(lines []string) string {
+	r := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+	escaped := make([]string, len(lines))
+	for i, l := range lines {
+		escaped[i] = r.Replace(l)
+	}
+	return `"` + strings.Join(escaped, "<br/>") + `"`
+}
+
+// renderD2 renders a D2 diagram. Locations are containers, IXs are hexagons
+// and external B-Ends are clouds.
+func renderD2(d diagram) string {
+	var sb strings.Builder
+	sb.WriteString("direction: right\n")
+	for _, g := range d.groups {
+		fmt.Fprintf(&sb, "%s: %s {\n", g.id, d2Quote(g.label))
+		for _, n := range g.nodes {
+			sb.WriteString(d2Node(n, "  "))
+		}
+		sb.WriteString("}\n")
+	}
+	for _, n := range d.ungrouped {
+		sb.WriteString(d2Node(n, ""))
+	}
+	for _, n := range d.external {
+		sb.WriteString(d2Node(n, ""))
+	}
+	for _, e := range d.edges {
+		fmt.Fprintf(&sb, "%s -> %s: %s\n", d2Path(e.from), d2Path(e.to), d2Quote(strings.Join(e.lines, "\n")))
+	}
+	return sb.String()
+}
+
+// d2Node returns the D2 declaration of n, indented by indent.
+func d2Node(n diagramNode, indent string) string {
+	decl := fmt.Sprintf("%s%s: %s", indent, n.id, d2Quote(strings.Join(n.lines, "\n")))
+	switch n.kind {
+	case nodeIX:
+		return decl + " {\n" + indent + "  shape: hexagon\n" + indent + "}\n"
+	case nodeExternal:
+		return decl + " {\n" + indent + "  shape: cloud\n" + indent + "  style.stroke-dash: 3\n" + indent + "}\n"
+	default:
+		return decl + "\n"
+	}
+}
+
+// d2Path returns the key of n from the top of the diagram: nodes in a
+// location container are addressed through it.
+func d2Path(n diagramNode) string {
+	if n.group == "" {
+		return n.id
+	}
+	return n.group + "." + n.id
+}
+
+// d2Quote returns s as a D2 double-quoted string.
+func d2Quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,180 @@
+package topology
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// diagramNodes is a port and an MCR in one location, linked by a VXC, with a
+// second VXC from the port to a cloud B-End.
+func diagramNodes() []TopologyNode {
+	return []TopologyNode{
+		{
+			UID: "port-aaa", Name: "Sydney-Primary", Type: "Port", Status: "LIVE", SpeedMbps: 10000, Location: "Equinix SY1",
+			Connections: []TopologyVXC{
+				{UID: "vxc-1", Name: "AWS-SYD", RateMbps: 500, AEndVLAN: 100, BEndVLAN: 200, BEndUID: "cloud-bbb", BEndName: "AWS Gateway", BEndLocation: "ap-southeast-2"},
+				{UID: "vxc-2", Name: "To-MCR", RateMbps: 1000, AEndVLAN: 300, BEndUID: "mcr-ccc", BEndName: "Cloud-Router"},
+			},
+		},
+		{UID: "mcr-ccc", Name: "Cloud-Router", Type: "MCR", Status: "LIVE", SpeedMbps: 5000, Location: "Equinix SY1"},
+		{UID: "mve-ddd", Name: "Edge", Type: "MVE", Status: "LIVE"},
+	}
+}
+
+// ── buildDiagram ──────────────────────────────────────────────────────────────
+
+func TestBuildDiagram_GroupsAndExternalNodes(t *testing.T) {
+	d := buildDiagram(diagramNodes())
+
+	require.Len(t, d.groups, 1)
+	assert.Equal(t, "Equinix SY1", d.groups[0].label)
+	assert.Len(t, d.groups[0].nodes, 2)
+	require.Len(t, d.ungrouped, 1, "a node without a location is not grouped")
+	assert.Equal(t, []string{"Edge", "MVE, LIVE"}, d.ungrouped[0].lines)
+
+	require.Len(t, d.external, 1, "only the cloud B-End is external")
+	assert.Equal(t, []string{"AWS Gateway", "ap-southeast-2"}, d.external[0].lines)
+
+	require.Len(t, d.edges, 2)
+	assert.Equal(t, d.external[0].id, d.edges[0].to.id)
+	assert.Equal(t, []string{"AWS-SYD", "500 Mbps, VLAN 100/200"}, d.edges[0].lines)
+	assert.Equal(t, d.groups[0].nodes[1].id, d.edges[1].to.id, "a VXC between listed resources joins their nodes")
+	assert.Equal(t, []string{"To-MCR", "1 Gbps, VLAN 300"}, d.edges[1].lines)
+}
+
+func TestBuildDiagram_SharedExternalBEnd(t *testing.T) {
+	nodes := []TopologyNode{
+		{UID: "port-1", Name: "A", Type: "Port", Connections: []TopologyVXC{{Name: "v1", BEndUID: "partner-1", BEndName: "Partner"}}},
+		{UID: "port-2", Name: "B", Type: "Port", Connections: []TopologyVXC{{Name: "v2", BEndUID: "partner-1", BEndName: "Partner"}, {Name: "v3"}}},
+	}
+	d := buildDiagram(nodes)
+
+	require.Len(t, d.external, 2, "VXCs to the same B-End share a node; an unknown B-End gets its own")
+	assert.Equal(t, d.edges[0].to.id, d.edges[1].to.id)
+	assert.Equal(t, []string{"(unknown B-End)"}, d.external[1].lines)
+}
+
+func TestBuildDiagram_IXsAndPartners(t *testing.T) {
+	nodes := []TopologyNode{{
+		UID: "port-aaa", Name: "Sydney-Primary", Type: "Port", Location: "Equinix SY1",
+		Connections: []TopologyVXC{{Name: "AWS-SYD", RateMbps: 500, BEndUID: "aws-port", BEndName: "Asia Pacific (Sydney)", BEndPartner: "AWS", BEndConnectType: "AWS"}},
+		IXs:         []TopologyIX{{UID: "ix-1", Name: "Peering", RateMbps: 1000, VLAN: 300, ASN: 65000, NetworkServiceType: "Sydney IX"}},
+	}}
+	d := buildDiagram(nodes)
+
+	require.Len(t, d.groups, 1)
+	require.Len(t, d.groups[0].nodes, 2, "an IX sits in its parent's location")
+	ix := d.groups[0].nodes[1]
+	assert.Equal(t, nodeIX, ix.kind)
+	assert.Equal(t, []string{"Peering", "IX, Sydney IX, AS65000"}, ix.lines)
+
+	require.Len(t, d.edges, 2)
+	assert.Equal(t, ix.id, d.edges[0].to.id)
+	assert.Equal(t, []string{"1 Gbps, VLAN 300"}, d.edges[0].lines)
+
+	require.Len(t, d.external, 1)
+	assert.Equal(t, []string{"Asia Pacific (Sydney)", "AWS (AWS)"}, d.external[0].lines)
+
+	assert.Contains(t, renderDOT(d), `"ix1" [label="Peering\nIX, Sydney IX, AS65000", shape=hexagon];`)
+	assert.Contains(t, renderMermaid(d), `ix1(["Peering<br/>IX, Sydney IX, AS65000"])`)
+	assert.Contains(t, renderD2(d), "  ix1: \"Peering\\nIX, Sydney IX, AS65000\" {\n    shape: hexagon\n  }\n")
+}
+
+func TestVLANLabel(t *testing.T) {
+	assert.Equal(t, "", vlanLabel(0, 0))
+	assert.Equal(t, "VLAN 100", vlanLabel(100, 0))
+	assert.Equal(t, "VLAN 100", vlanLabel(100, 100))
+	assert.Equal(t, "B-End VLAN 200", vlanLabel(0, 200))
+	assert.Equal(t, "VLAN 100/200", vlanLabel(100, 200))
+}
+
+// ── renderers ─────────────────────────────────────────────────────────────────
+
+func TestRenderDOT(t *testing.T) {
+	out := renderDiagram(diagramNodes(), formatDOT)
+
+	assert.True(t, strings.HasPrefix(out, "digraph topology {\n"))
+	assert.Contains(t, out, `subgraph "cluster_loc1" {`)
+	assert.Contains(t, out, `label="Equinix SY1";`)
+	assert.Contains(t, out, `"n1" [label="Sydney-Primary\nPort, 10 Gbps, LIVE"];`)
+	assert.Contains(t, out, `"ext1" [label="AWS Gateway\nap-southeast-2", shape=ellipse, style=dashed];`)
+	assert.Contains(t, out, `"n1" -> "ext1" [label="AWS-SYD\n500 Mbps, VLAN 100/200"];`)
+	assert.Contains(t, out, `"n1" -> "n2" [label="To-MCR\n1 Gbps, VLAN 300"];`)
+	assert.True(t, strings.HasSuffix(out, "}\n"))
+}
+
+func TestRenderMermaid(t *testing.T) {
+	out := renderDiagram(diagramNodes(), formatMermaid)
+
+	assert.True(t, strings.HasPrefix(out, "flowchart LR\n"))
+	assert.Contains(t, out, `subgraph loc1["Equinix SY1"]`)
+	assert.Contains(t, out, `n1["Sydney-Primary<br/>Port, 10 Gbps, LIVE"]`)
+	assert.Contains(t, out, `n3["Edge<br/>MVE, LIVE"]`)
+	assert.Contains(t, out, `ext1{{"AWS Gateway<br/>ap-southeast-2"}}`)
+	assert.Contains(t, out, `n1 -->|"AWS-SYD<br/>500 Mbps, VLAN 100/200"| ext1`)
+}
+
+func TestRenderD2(t *testing.T) {
+	out := renderDiagram(diagramNodes(), formatD2)
+
+	assert.True(t, strings.HasPrefix(out, "direction: right\n"))
+	assert.Contains(t, out, `loc1: "Equinix SY1" {`)
+	assert.Contains(t, out, `n1: "Sydney-Primary\nPort, 10 Gbps, LIVE"`)
+	assert.Contains(t, out, "ext1: \"AWS Gateway\\nap-southeast-2\" {\n  shape: cloud")
+	assert.Contains(t, out, `loc1.n1 -> ext1: "AWS-SYD\n500 Mbps, VLAN 100/200"`)
+	assert.Contains(t, out, `loc1.n1 -> loc1.n2: "To-MCR\n1 Gbps, VLAN 300"`)
+	assert.Contains(t, out, "\nn3: \"Edge\\nMVE, LIVE\"\n", "ungrouped nodes sit at the top level")
+}
+
+func TestRenderDiagram_EscapesLabels(t *testing.T) {
+	nodes := []TopologyNode{{UID: "p", Name: `My "core" <port>`, Type: "Port"}}
+
+	assert.Contains(t, renderDiagram(nodes, formatDOT), `My \"core\" <port>`)
+	assert.Contains(t, renderDiagram(nodes, formatMermaid), `My #quot;core#quot; #lt;port#gt;`)
+	assert.Contains(t, renderDiagram(nodes, formatD2), `My \"core\" <port>`)
+}
+
+func TestShowTopology_DiagramOutput(t *testing.T) {
+	cleanup := setupTopologyMocks(
+		&MockPortService{
+			ListPortsResult: []*megaport.Port{
+				{
+					UID:                "port-aaa",
+					Name:               "Sydney-Primary",
+					ProvisioningStatus: "LIVE",
+					PortSpeed:          10000,
+					LocationDetails:    &megaport.ProductLocationDetails{Name: "Equinix SY1"},
+					AssociatedVXCs: []*megaport.VXC{
+						{
+							UID: "vxc-1", Name: "AWS-SYD",
+							ProvisioningStatus: "LIVE", RateLimit: 500,
+							AEndConfiguration: megaport.VXCEndConfiguration{UID: "port-aaa", VLAN: 100},
+							BEndConfiguration: megaport.VXCEndConfiguration{UID: "cloud-bbb", Name: "AWS Gateway"},
+						},
+					},
+				},
+			},
+		},
+		&MockMCRService{},
+		&MockMVEService{},
+	)
+	defer cleanup()
+
+	cmd := &cobra.Command{Use: "topology"}
+	cmd.Flags().Bool("include-inactive", false, "")
+	cmd.Flags().String("type", "", "")
+
+	captured := output.CaptureOutput(func() {
+		err := ShowTopology(cmd, nil, true, "mermaid")
+		assert.NoError(t, err)
+	})
+
+	assert.Contains(t, captured, `subgraph loc1["Equinix SY1"]`)
+	assert.Contains(t, captured, `n1 -->|"AWS-SYD<br/>500 Mbps, VLAN 100"| ext1`)
+}
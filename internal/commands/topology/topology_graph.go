@@ -0,0 +1,258 @@
+package topology
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+)
+
+// Types of graph vertex besides the parent resources, whose type is their
+// TopologyNode.Type.
+const (
+	vertexVXC      = "VXC"
+	vertexIX       = "IX"
+	vertexPartner  = "Partner Port"
+	vertexExternal = "External"
+)
+
+// Impacts of a resource going down.
+const (
+	impactDirect     = "direct"     // attached to the resource
+	impactDownstream = "downstream" // reached through a direct impact
+)
+
+// maxPathSearch bounds the paths collected before the shortest are chosen, so
+// a densely meshed account cannot stall a path query.
+const maxPathSearch = 1000
+
+// ImpactedResource is a resource affected when another goes down.
+type ImpactedResource struct {
+	output.Output `json:"-" header:"-" xml:"-"`
+	UID           string `json:"uid" header:"UID" xml:"uid"`
+	Name          string `json:"name" header:"Name" xml:"name"`
+	Type          string `json:"type" header:"Type" xml:"type"`
+	Impact        string `json:"impact" header:"Impact" xml:"impact"`
+	Via           string `json:"via" header:"Via" xml:"via"` // UID of the resource it is reached through
+	Location      string `json:"location" header:"Location" xml:"location"`
+}
+
+// TopologyPath is one way two resources are connected, from the first hop to
+// the last.
+type TopologyPath struct {
+	Hops []TopologyPathHop `json:"hops"`
+}
+
+// TopologyPathHop is a resource on a TopologyPath.
+type TopologyPathHop struct {
+	UID      string `json:"uid"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Location string `json:"location"`
+}
+
+// pathHopOutput is a row of the path table: one hop of one path.
+type pathHopOutput struct {
+	output.Output `json:"-" header:"-" xml:"-"`
+	Path          int    `json:"path" header:"Path" xml:"path"`
+	Hop           int    `json:"hop" header:"Hop" xml:"hop"`
+	UID           string `json:"uid" header:"UID" xml:"uid"`
+	Name          string `json:"name" header:"Name" xml:"name"`
+	Type          string `json:"type" header:"Type" xml:"type"`
+	Location      string `json:"location" header:"Location" xml:"location"`
+}
+
+// topologyGraph is the topology as an undirected graph. Parent resources,
+// VXCs, IXs and the B-Ends outside the account are vertices; each VXC is
+// joined to the resources at both of its ends, and each IX to its parent.
+type topologyGraph struct {
+	vertices map[string]*graphVertex
+}
+
+type graphVertex struct {
+	uid       string
+	name      string
+	typ       string
+	location  string
+	neighbors []string // UIDs, in topology order
+}
+
+// buildTopologyGraph builds the graph of nodes.
+func buildTopologyGraph(nodes []TopologyNode) *topologyGraph {
+	g := &topologyGraph{vertices: map[string]*graphVertex{}}
+	for _, n := range nodes {
+		if n.UID != "" {
+			g.add(&graphVertex{uid: n.UID, name: n.Name, typ: n.Type, location: n.Location})
+		}
+	}
+	for _, n := range nodes {
+		if n.UID == "" {
+			continue
+		}
+		for _, c := range n.Connections {
+			if c.UID == "" {
+				continue
+			}
+			g.add(&graphVertex{uid: c.UID, name: c.Name, typ: vertexVXC})
+			g.link(n.UID, c.UID)
+			if c.BEndUID == "" {
+				continue
+			}
+			typ := vertexExternal
+			if c.BEndPartner != "" || c.BEndConnectType != "" {
+				typ = vertexPartner
+			}
+			g.add(&graphVertex{uid: c.BEndUID, name: c.BEndName, typ: typ, location: c.BEndLocation})
+			g.link(c.UID, c.BEndUID)
+		}
+		for _, x := range n.IXs {
+			if x.UID == "" {
+				continue
+			}
+			g.add(&graphVertex{uid: x.UID, name: x.Name, typ: vertexIX, location: n.Location})
+			g.link(n.UID, x.UID)
+		}
+	}
+	return g
+}
+
+// add adds v unless a vertex with its UID exists.
+func (g *topologyGraph) add(v *graphVertex) {
+	if _, ok := g.vertices[v.uid]; !ok {
+		g.vertices[v.uid] = v
+	}
+}
+
+// link joins the vertices a and b.
+func (g *topologyGraph) link(a, b string) {
+	g.vertices[a].neighbors = append(g.vertices[a].neighbors, b)
+	g.vertices[b].neighbors = append(g.vertices[b].neighbors, a)
+}
+
+// vertex returns the vertex with uid, or an error naming it when the
+// topology has none.
+func (g *topologyGraph) vertex(uid string) (*graphVertex, error) {
+	v, ok := g.vertices[uid]
+	if !ok {
+		return nil, fmt.Errorf("no resource with UID %q in the topology", uid)
+	}
+	return v, nil
+}
+
+// transit reports whether traffic passes through v between its neighbors:
+// VXCs carry it, and MCRs, MVEs and NAT Gateways route it. Ports, IXs and
+// B-Ends outside the account are where traffic enters or leaves.
+func transit(v *graphVertex) bool {
+	switch v.typ {
+	case vertexVXC, "MCR", "MVE", "NAT Gateway":
+		return true
+	default:
+		return false
+	}
+}
+
+// impact lists what is affected if the resource with uid goes down, nearest
+// first. The VXCs and IXs attached to it fail with it; beyond them, every
+// resource traffic to or from it passes through is affected too, as far as
+// the ports and external B-Ends where that traffic ends.
+func (g *topologyGraph) impact(uid string) ([]ImpactedResource, error) {
+	start, err := g.vertex(uid)
+	if err != nil {
+		return nil, err
+	}
+	var impacted []ImpactedResource
+	seen := map[string]bool{uid: true}
+	queue := []*graphVertex{start}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur != start && !transit(cur) {
+			continue
+		}
+		for _, n := range cur.neighbors {
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+			v := g.vertices[n]
+			impact := impactDownstream
+			if cur == start {
+				impact = impactDirect
+			}
+			impacted = append(impacted, ImpactedResource{UID: v.uid, Name: v.name, Type: v.typ, Impact: impact, Via: cur.uid, Location: v.location})
+			queue = append(queue, v)
+		}
+	}
+	return impacted, nil
+}
+
+// paths returns up to limit of the shortest paths from one resource to
+// another. Paths do not revisit a resource, and pass only through resources
+// that carry traffic onward: VXCs, MCRs, MVEs and NAT Gateways.
+func (g *topologyGraph) paths(from, to string, limit int) ([]TopologyPath, error) {
+	start, err := g.vertex(from)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := g.vertex(to); err != nil {
+		return nil, err
+	}
+	if from == to {
+		return nil, fmt.Errorf("the two UIDs are the same resource")
+	}
+
+	var found [][]string
+	onPath := map[string]bool{from: true}
+	route := []string{from}
+	var walk func(v *graphVertex)
+	walk = func(v *graphVertex) {
+		for _, n := range v.neighbors {
+			if len(found) >= maxPathSearch {
+				return
+			}
+			if onPath[n] {
+				continue
+			}
+			if n == to {
+				found = append(found, append(append([]string(nil), route...), n))
+				continue
+			}
+			next := g.vertices[n]
+			if !transit(next) {
+				continue
+			}
+			onPath[n] = true
+			route = append(route, n)
+			walk(next)
+			route = route[:len(route)-1]
+			onPath[n] = false
+		}
+	}
+	walk(start)
+
+	sort.SliceStable(found, func(i, j int) bool { return len(found[i]) < len(found[j]) })
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	paths := make([]TopologyPath, 0, len(found))
+	for _, uids := range found {
+		p := TopologyPath{Hops: make([]TopologyPathHop, 0, len(uids))}
+		for _, uid := range uids {
+			v := g.vertices[uid]
+			p.Hops = append(p.Hops, TopologyPathHop{UID: v.uid, Name: v.name, Type: v.typ, Location: v.location})
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+// pathRows flattens paths into table rows, numbering paths and hops from 1.
+func pathRows(paths []TopologyPath) []pathHopOutput {
+	var rows []pathHopOutput
+	for i, p := range paths {
+		for j, h := range p.Hops {
+			rows = append(rows, pathHopOutput{Path: i + 1, Hop: j + 1, UID: h.UID, Name: h.Name, Type: h.Type, Location: h.Location})
+		}
+	}
+	return rows
+}
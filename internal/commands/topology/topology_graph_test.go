@@ -0,0 +1,245 @@
+package topology
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	megaport "github.com/megaport/megaportgo"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// graphNodes is two ports and an MCR. Port A reaches the MCR, port B and an
+// IX; port B also reaches the MCR; the MCR reaches Azure and AWS.
+func graphNodes() []TopologyNode {
+	return []TopologyNode{
+		{
+			UID: "port-a", Name: "Port A", Type: "Port", Location: "Equinix SY1",
+			Connections: []TopologyVXC{
+				{UID: "vxc-1", Name: "A to MCR", BEndUID: "mcr-1", BEndName: "MCR"},
+				{UID: "vxc-5", Name: "A to B", BEndUID: "port-b", BEndName: "Port B"},
+			},
+			IXs: []TopologyIX{{UID: "ix-1", Name: "Peering"}},
+		},
+		{
+			UID: "port-b", Name: "Port B", Type: "Port", Location: "Equinix SY1",
+			Connections: []TopologyVXC{
+				{UID: "vxc-4", Name: "B to MCR", BEndUID: "mcr-1", BEndName: "MCR"},
+			},
+		},
+		{
+			UID: "mcr-1", Name: "MCR", Type: "MCR", Location: "Equinix SY1",
+			Connections: []TopologyVXC{
+				{UID: "vxc-2", Name: "Azure", BEndUID: "azure-1", BEndName: "Azure Sydney", BEndConnectType: "AZURE"},
+				{UID: "vxc-3", Name: "AWS", BEndUID: "aws-1", BEndName: "ap-southeast-2", BEndPartner: "AWS"},
+			},
+		},
+	}
+}
+
+// impactUIDs returns the UIDs of impacted resources by impact.
+func impactUIDs(impacted []ImpactedResource) map[string][]string {
+	byImpact := map[string][]string{}
+	for _, r := range impacted {
+		byImpact[r.Impact] = append(byImpact[r.Impact], r.UID)
+	}
+	return byImpact
+}
+
+// hopUIDs returns the UIDs along each path.
+func hopUIDs(paths []TopologyPath) [][]string {
+	var out [][]string
+	for _, p := range paths {
+		var uids []string
+		for _, h := range p.Hops {
+			uids = append(uids, h.UID)
+		}
+		out = append(out, uids)
+	}
+	return out
+}
+
+// ── buildTopologyGraph ────────────────────────────────────────────────────────
+
+func TestBuildTopologyGraph(t *testing.T) {
+	g := buildTopologyGraph(graphNodes())
+
+	assert.Len(t, g.vertices, 11)
+	assert.Equal(t, vertexPartner, g.vertices["azure-1"].typ)
+	assert.Equal(t, vertexIX, g.vertices["ix-1"].typ)
+	assert.Equal(t, []string{"vxc-1", "vxc-4", "vxc-2", "vxc-3"}, g.vertices["mcr-1"].neighbors)
+}
+
+// ── impact ────────────────────────────────────────────────────────────────────
+
+func TestImpact_Port(t *testing.T) {
+	impacted, err := buildTopologyGraph(graphNodes()).impact("port-a")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{
+		impactDirect:     {"vxc-1", "vxc-5", "ix-1"},
+		impactDownstream: {"mcr-1", "port-b", "vxc-4", "vxc-2", "vxc-3", "azure-1", "aws-1"},
+	}, impactUIDs(impacted))
+	assert.Equal(t, "vxc-1", impacted[3].Via, "the MCR is reached through port A's VXC")
+}
+
+func TestImpact_StopsAtPorts(t *testing.T) {
+	impacted, err := buildTopologyGraph(graphNodes()).impact("vxc-5")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{
+		impactDirect: {"port-a", "port-b"},
+	}, impactUIDs(impacted), "traffic does not pass through a port to its other VXCs")
+}
+
+func TestImpact_UnknownUID(t *testing.T) {
+	_, err := buildTopologyGraph(graphNodes()).impact("nope")
+	assert.EqualError(t, err, `no resource with UID "nope" in the topology`)
+}
+
+// ── paths ─────────────────────────────────────────────────────────────────────
+
+func TestPaths_ThroughMCR(t *testing.T) {
+	paths, err := buildTopologyGraph(graphNodes()).paths("port-b", "azure-1", 10)
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{{"port-b", "vxc-4", "mcr-1", "vxc-2", "azure-1"}}, hopUIDs(paths),
+		"the path through port A is not taken: ports do not carry traffic onward")
+	assert.Equal(t, TopologyPathHop{UID: "azure-1", Name: "Azure Sydney", Type: vertexPartner}, paths[0].Hops[4])
+}
+
+func TestPaths_ShortestFirstAndLimited(t *testing.T) {
+	g := buildTopologyGraph(graphNodes())
+
+	paths, err := g.paths("port-a", "port-b", 10)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"port-a", "vxc-5", "port-b"},
+		{"port-a", "vxc-1", "mcr-1", "vxc-4", "port-b"},
+	}, hopUIDs(paths))
+
+	paths, err = g.paths("port-a", "port-b", 1)
+	require.NoError(t, err)
+	assert.Len(t, paths, 1)
+}
+
+func TestPaths_NoneAndErrors(t *testing.T) {
+	g := buildTopologyGraph(graphNodes())
+
+	paths, err := g.paths("ix-1", "azure-1", 10)
+	require.NoError(t, err)
+	assert.Empty(t, paths, "an IX only reaches its parent port")
+
+	_, err = g.paths("port-a", "nope", 10)
+	assert.EqualError(t, err, `no resource with UID "nope" in the topology`)
+	_, err = g.paths("port-a", "port-a", 10)
+	assert.Error(t, err)
+}
+
+func TestPathRows(t *testing.T) {
+	paths, err := buildTopologyGraph(graphNodes()).paths("port-a", "port-b", 10)
+	require.NoError(t, err)
+
+	rows := pathRows(paths)
+	require.Len(t, rows, 8)
+	assert.Equal(t, 1, rows[0].Path)
+	assert.Equal(t, 3, rows[2].Hop)
+	assert.Equal(t, 2, rows[3].Path)
+	assert.Equal(t, "port-b", rows[7].UID)
+}
+
+// ── ShowImpact and ShowPath ───────────────────────────────────────────────────
+
+// setupGraphMocks serves a port with a VXC to an MCR, which has a VXC to Azure.
+func setupGraphMocks() func() {
+	return setupTopologyMocks(
+		&MockPortService{ListPortsResult: []*megaport.Port{{
+			UID: "port-a", Name: "Port A", ProvisioningStatus: "LIVE",
+			AssociatedVXCs: []*megaport.VXC{{
+				UID: "vxc-1", Name: "A to MCR", ProvisioningStatus: "LIVE",
+				AEndConfiguration: megaport.VXCEndConfiguration{UID: "port-a"},
+				BEndConfiguration: megaport.VXCEndConfiguration{UID: "mcr-1", Name: "MCR"},
+			}},
+		}}},
+		&MockMCRService{ListMCRsResult: []*megaport.MCR{{
+			UID: "mcr-1", Name: "MCR", ProvisioningStatus: "LIVE",
+			AssociatedVXCs: []*megaport.VXC{{
+				UID: "vxc-2", Name: "Azure", ProvisioningStatus: "LIVE",
+				AEndConfiguration: megaport.VXCEndConfiguration{UID: "mcr-1"},
+				BEndConfiguration: megaport.VXCEndConfiguration{UID: "azure-1"},
+			}},
+		}}},
+		&MockMVEService{},
+		func(c *megaport.Client) {
+			c.PartnerService = &MockPartnerService{ListPartnerMegaportsResult: []*megaport.PartnerMegaport{
+				{ProductUID: "azure-1", ProductName: "Azure Sydney", CompanyName: "Microsoft Azure", ConnectType: "AZURE"},
+			}}
+		},
+	)
+}
+
+func TestShowImpact_JSONOutput(t *testing.T) {
+	defer setupGraphMocks()()
+
+	cmd := &cobra.Command{Use: "impact"}
+	captured := output.CaptureOutput(func() {
+		err := ShowImpact(cmd, []string{"port-a"}, true, "json")
+		assert.NoError(t, err)
+	})
+
+	var impacted []ImpactedResource
+	require.NoError(t, json.Unmarshal([]byte(captured), &impacted))
+	assert.Equal(t, map[string][]string{
+		impactDirect:     {"vxc-1"},
+		impactDownstream: {"mcr-1", "vxc-2", "azure-1"},
+	}, impactUIDs(impacted))
+	assert.Equal(t, "Azure Sydney", impacted[3].Name)
+}
+
+func TestShowImpact_UnknownUIDIsUsageError(t *testing.T) {
+	defer setupGraphMocks()()
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ShowImpact(&cobra.Command{Use: "impact"}, []string{"nope"}, true, "table")
+	})
+	var cliErr *exitcodes.CLIError
+	require.True(t, errors.As(err, &cliErr))
+	assert.Equal(t, exitcodes.Usage, cliErr.Code)
+}
+
+func TestShowPath_Output(t *testing.T) {
+	defer setupGraphMocks()()
+
+	cmd := &cobra.Command{Use: "path"}
+	cmd.Flags().Int("max-paths", 10, "")
+
+	captured := output.CaptureOutput(func() {
+		err := ShowPath(cmd, []string{"port-a", "azure-1"}, true, "json")
+		assert.NoError(t, err)
+	})
+	var paths []TopologyPath
+	require.NoError(t, json.Unmarshal([]byte(captured), &paths))
+	assert.Equal(t, [][]string{{"port-a", "vxc-1", "mcr-1", "vxc-2", "azure-1"}}, hopUIDs(paths))
+
+	captured = output.CaptureOutput(func() {
+		err := ShowPath(cmd, []string{"port-a", "azure-1"}, true, "table")
+		assert.NoError(t, err)
+	})
+	assert.Contains(t, captured, "Azure Sydney")
+	assert.Contains(t, captured, "Partner Port")
+}
+
+func TestShowPath_InvalidMaxPaths(t *testing.T) {
+	cmd := &cobra.Command{Use: "path"}
+	cmd.Flags().Int("max-paths", 0, "")
+
+	err := ShowPath(cmd, []string{"a", "b"}, true, "table")
+	var cliErr *exitcodes.CLIError
+	require.True(t, errors.As(err, &cliErr))
+	assert.Equal(t, exitcodes.Usage, cliErr.Code)
+}
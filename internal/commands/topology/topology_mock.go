@@ -153,3 +153,189 @@ func (m *MockMVEService) ListMVEResourceTags(ctx context.Context, mveID string)
 func (m *MockMVEService) UpdateMVEResourceTags(ctx context.Context, mveID string, tags map[string]string) error {
 	return fmt.Errorf("mock: UpdateMVEResourceTags not configured")
 }
+
+// MockPartnerService satisfies megaport.PartnerService for testing.
+type MockPartnerService struct {
+	ListPartnerMegaportsResult []*megaport.PartnerMegaport
+	ListPartnerMegaportsErr    error
+}
+
+func (m *MockPartnerService) ListPartnerMegaports(ctx context.Context) ([]*megaport.PartnerMegaport, error) {
+	return m.ListPartnerMegaportsResult, m.ListPartnerMegaportsErr
+}
+
+func (m *MockPartnerService) FilterPartnerMegaportByProductName(ctx context.Context, partners []*megaport.PartnerMegaport, productName string, exactMatch bool) ([]*megaport.PartnerMegaport, error) {
+	return nil, fmt.Errorf("mock: FilterPartnerMegaportByProductName not configured")
+}
+func (m *MockPartnerService) FilterPartnerMegaportByConnectType(ctx context.Context, partners []*megaport.PartnerMegaport, connectType string, exactMatch bool) ([]*megaport.PartnerMegaport, error) {
+	return nil, fmt.Errorf("mock: FilterPartnerMegaportByConnectType not configured")
+}
+func (m *MockPartnerService) FilterPartnerMegaportByCompanyName(ctx context.Context, partners []*megaport.PartnerMegaport, companyName string, exactMatch bool) ([]*megaport.PartnerMegaport, error) {
+	return nil, fmt.Errorf("mock: FilterPartnerMegaportByCompanyName not configured")
+}
+func (m *MockPartnerService) FilterPartnerMegaportByLocationId(ctx context.Context, partners []*megaport.PartnerMegaport, locationId int) ([]*megaport.PartnerMegaport, error) {
+	return nil, fmt.Errorf("mock: FilterPartnerMegaportByLocationId not configured")
+}
+func (m *MockPartnerService) FilterPartnerMegaportByDiversityZone(ctx context.Context, partners []*megaport.PartnerMegaport, diversityZone string) ([]*megaport.PartnerMegaport, error) {
+	return nil, fmt.Errorf("mock: FilterPartnerMegaportByDiversityZone not configured")
+}
+func (m *MockPartnerService) FilterPartnerMegaportByMetro(ctx context.Context, partners []*megaport.PartnerMegaport, metro string) ([]*megaport.PartnerMegaport, error) {
+	return nil, fmt.Errorf("mock: FilterPartnerMegaportByMetro not configured")
+}
+
+// MockNATGatewayService satisfies megaport.NATGatewayService for testing.
+type MockNATGatewayService struct {
+	ListNATGatewaysResult []*megaport.NATGateway
+	ListNATGatewaysErr    error
+}
+
+func (m *MockNATGatewayService) ListNATGateways(ctx context.Context) ([]*megaport.NATGateway, error) {
+	return m.ListNATGatewaysResult, m.ListNATGatewaysErr
+}
+
+func (m *MockNATGatewayService) CreateNATGateway(ctx context.Context, req *megaport.CreateNATGatewayRequest) (*megaport.NATGateway, error) {
+	return nil, fmt.Errorf("mock: CreateNATGateway not configured")
+}
+func (m *MockNATGatewayService) GetNATGateway(ctx context.Context, productUID string) (*megaport.NATGateway, error) {
+	return nil, fmt.Errorf("mock: GetNATGateway not configured")
+}
+func (m *MockNATGatewayService) UpdateNATGateway(ctx context.Context, req *megaport.UpdateNATGatewayRequest) (*megaport.NATGateway, error) {
+	return nil, fmt.Errorf("mock: UpdateNATGateway not configured")
+}
+func (m *MockNATGatewayService) DeleteNATGateway(ctx context.Context, productUID string) error {
+	return fmt.Errorf("mock: DeleteNATGateway not configured")
+}
+func (m *MockNATGatewayService) ListNATGatewaySessions(ctx context.Context) ([]*megaport.NATGatewaySession, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewaySessions not configured")
+}
+func (m *MockNATGatewayService) GetNATGatewayTelemetry(ctx context.Context, req *megaport.GetNATGatewayTelemetryRequest) (*megaport.ServiceTelemetryResponse, error) {
+	return nil, fmt.Errorf("mock: GetNATGatewayTelemetry not configured")
+}
+func (m *MockNATGatewayService) ValidateNATGatewayOrder(ctx context.Context, productUID string) (*megaport.NATGatewayValidateResult, error) {
+	return nil, fmt.Errorf("mock: ValidateNATGatewayOrder not configured")
+}
+func (m *MockNATGatewayService) BuyNATGateway(ctx context.Context, productUID string) (*megaport.NATGatewayBuyResult, error) {
+	return nil, fmt.Errorf("mock: BuyNATGateway not configured")
+}
+func (m *MockNATGatewayService) ListNATGatewayPacketFilters(ctx context.Context, productUID string) ([]*megaport.NATGatewayPacketFilterSummary, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayPacketFilters not configured")
+}
+func (m *MockNATGatewayService) CreateNATGatewayPacketFilter(ctx context.Context, productUID string, req *megaport.NATGatewayPacketFilterRequest) (*megaport.NATGatewayPacketFilter, error) {
+	return nil, fmt.Errorf("mock: CreateNATGatewayPacketFilter not configured")
+}
+func (m *MockNATGatewayService) GetNATGatewayPacketFilter(ctx context.Context, productUID string, packetFilterID int) (*megaport.NATGatewayPacketFilter, error) {
+	return nil, fmt.Errorf("mock: GetNATGatewayPacketFilter not configured")
+}
+func (m *MockNATGatewayService) UpdateNATGatewayPacketFilter(ctx context.Context, productUID string, packetFilterID int, req *megaport.NATGatewayPacketFilterRequest) (*megaport.NATGatewayPacketFilter, error) {
+	return nil, fmt.Errorf("mock: UpdateNATGatewayPacketFilter not configured")
+}
+func (m *MockNATGatewayService) DeleteNATGatewayPacketFilter(ctx context.Context, productUID string, packetFilterID int) error {
+	return fmt.Errorf("mock: DeleteNATGatewayPacketFilter not configured")
+}
+func (m *MockNATGatewayService) ListNATGatewayPrefixLists(ctx context.Context, productUID string) ([]*megaport.NATGatewayPrefixListSummary, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayPrefixLists not configured")
+}
+func (m *MockNATGatewayService) CreateNATGatewayPrefixList(ctx context.Context, productUID string, req *megaport.NATGatewayPrefixList) (*megaport.NATGatewayPrefixList, error) {
+	return nil, fmt.Errorf("mock: CreateNATGatewayPrefixList not configured")
+}
+func (m *MockNATGatewayService) GetNATGatewayPrefixList(ctx context.Context, productUID string, prefixListID int) (*megaport.NATGatewayPrefixList, error) {
+	return nil, fmt.Errorf("mock: GetNATGatewayPrefixList not configured")
+}
+func (m *MockNATGatewayService) UpdateNATGatewayPrefixList(ctx context.Context, productUID string, prefixListID int, req *megaport.NATGatewayPrefixList) (*megaport.NATGatewayPrefixList, error) {
+	return nil, fmt.Errorf("mock: UpdateNATGatewayPrefixList not configured")
+}
+func (m *MockNATGatewayService) DeleteNATGatewayPrefixList(ctx context.Context, productUID string, prefixListID int) error {
+	return fmt.Errorf("mock: DeleteNATGatewayPrefixList not configured")
+}
+func (m *MockNATGatewayService) ListNATGatewayIPRoutesAsync(ctx context.Context, productUID, ipAddress string) (string, error) {
+	return "", fmt.Errorf("mock: ListNATGatewayIPRoutesAsync not configured")
+}
+func (m *MockNATGatewayService) ListNATGatewayBGPRoutesAsync(ctx context.Context, productUID, ipAddress string) (string, error) {
+	return "", fmt.Errorf("mock: ListNATGatewayBGPRoutesAsync not configured")
+}
+func (m *MockNATGatewayService) ListNATGatewayBGPNeighborRoutesAsync(ctx context.Context, req *megaport.NATGatewayBGPNeighborRoutesRequest) (string, error) {
+	return "", fmt.Errorf("mock: ListNATGatewayBGPNeighborRoutesAsync not configured")
+}
+func (m *MockNATGatewayService) GetNATGatewayDiagnosticsRoutes(ctx context.Context, productUID, operationID string) ([]*megaport.NATGatewayRoute, error) {
+	return nil, fmt.Errorf("mock: GetNATGatewayDiagnosticsRoutes not configured")
+}
+func (m *MockNATGatewayService) ListNATGatewayIPRoutes(ctx context.Context, productUID, ipAddress string) ([]*megaport.NATGatewayIPRoute, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayIPRoutes not configured")
+}
+func (m *MockNATGatewayService) ListNATGatewayBGPRoutes(ctx context.Context, productUID, ipAddress string) ([]*megaport.NATGatewayBGPRoute, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayBGPRoutes not configured")
+}
+func (m *MockNATGatewayService) ListNATGatewayBGPNeighborRoutes(ctx context.Context, req *megaport.NATGatewayBGPNeighborRoutesRequest) ([]*megaport.NATGatewayBGPRoute, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayBGPNeighborRoutes not configured")
+}
+
+// MockLocationService satisfies megaport.LocationService for testing.
+type MockLocationService struct {
+	GetLocationByIDV3Result map[int]*megaport.LocationV3
+	GetLocationByIDV3Err    error
+}
+
+func (m *MockLocationService) GetLocationByIDV3(ctx context.Context, locationID int) (*megaport.LocationV3, error) {
+	if m.GetLocationByIDV3Err != nil {
+		return nil, m.GetLocationByIDV3Err
+	}
+	if loc, ok := m.GetLocationByIDV3Result[locationID]; ok {
+		return loc, nil
+	}
+	return nil, fmt.Errorf("mock: location %d not found", locationID)
+}
+
+func (m *MockLocationService) ListLocationsV3(ctx context.Context) ([]*megaport.LocationV3, error) {
+	return nil, fmt.Errorf("mock: ListLocationsV3 not configured")
+}
+func (m *MockLocationService) ListLocationsV3WithOptions(ctx context.Context, opts *megaport.ListLocationsV3Options) ([]*megaport.LocationV3, error) {
+	return nil, fmt.Errorf("mock: ListLocationsV3WithOptions not configured")
+}
+func (m *MockLocationService) GetLocationByNameV3(ctx context.Context, locationName string) (*megaport.LocationV3, error) {
+	return nil, fmt.Errorf("mock: GetLocationByNameV3 not configured")
+}
+func (m *MockLocationService) GetLocationByNameFuzzyV3(ctx context.Context, search string) ([]*megaport.LocationV3, error) {
+	return nil, fmt.Errorf("mock: GetLocationByNameFuzzyV3 not configured")
+}
+func (m *MockLocationService) FilterLocationsByMarketCodeV3(ctx context.Context, marketCode string, locations []*megaport.LocationV3) ([]*megaport.LocationV3, error) {
+	return nil, fmt.Errorf("mock: FilterLocationsByMarketCodeV3 not configured")
+}
+func (m *MockLocationService) FilterLocationsByMcrAvailabilityV3(ctx context.Context, mcrAvailable bool, locations []*megaport.LocationV3) []*megaport.LocationV3 {
+	return nil
+}
+func (m *MockLocationService) FilterLocationsByMetroV3(ctx context.Context, metro string, locations []*megaport.LocationV3) []*megaport.LocationV3 {
+	return nil
+}
+func (m *MockLocationService) FilterLocationsByNATGatewaySpeedV3(ctx context.Context, speedMbps int, locations []*megaport.LocationV3) []*megaport.LocationV3 {
+	return nil
+}
+func (m *MockLocationService) ListCountries(ctx context.Context) ([]*megaport.Country, error) {
+	return nil, fmt.Errorf("mock: ListCountries not configured")
+}
+func (m *MockLocationService) ListMarketCodes(ctx context.Context) ([]string, error) {
+	return nil, fmt.Errorf("mock: ListMarketCodes not configured")
+}
+func (m *MockLocationService) IsValidMarketCode(ctx context.Context, marketCode string) (bool, error) {
+	return false, fmt.Errorf("mock: IsValidMarketCode not configured")
+}
+func (m *MockLocationService) GetRoundTripTimes(ctx context.Context, srcLocation, year, month int) ([]*megaport.RoundTripTime, error) {
+	return nil, fmt.Errorf("mock: GetRoundTripTimes not configured")
+}
+func (m *MockLocationService) ListLocations(ctx context.Context) ([]*megaport.Location, error) {
+	return nil, fmt.Errorf("mock: ListLocations not configured")
+}
+func (m *MockLocationService) GetLocationByID(ctx context.Context, locationID int) (*megaport.Location, error) {
+	return nil, fmt.Errorf("mock: GetLocationByID not configured")
+}
+func (m *MockLocationService) GetLocationByName(ctx context.Context, locationName string) (*megaport.Location, error) {
+	return nil, fmt.Errorf("mock: GetLocationByName not configured")
+}
+func (m *MockLocationService) GetLocationByNameFuzzy(ctx context.Context, search string) ([]*megaport.Location, error) {
+	return nil, fmt.Errorf("mock: GetLocationByNameFuzzy not configured")
+}
+func (m *MockLocationService) FilterLocationsByMarketCode(ctx context.Context, marketCode string, locations []*megaport.Location) ([]*megaport.Location, error) {
+	return nil, fmt.Errorf("mock: FilterLocationsByMarketCode not configured")
+}
+func (m *MockLocationService) FilterLocationsByMcrAvailability(ctx context.Context, mcrAvailable bool, locations []*megaport.Location) []*megaport.Location {
+	return nil
+}
@@ -105,6 +105,78 @@ func TestBuildTopologyNodes_MVESpeedIsZero(t *testing.T) {
 	assert.Equal(t, 0, nodes[0].SpeedMbps, "MVE has no numeric speed")
 }
 
+func TestBuildTopologyNodes_IXsUnderParentPort(t *testing.T) {
+	port := &megaport.Port{
+		UID: "port-aaa", Name: "Sydney-Primary", ProvisioningStatus: "LIVE",
+		AssociatedIXs: []*megaport.IX{
+			{ProductUID: "ix-1", ProductName: "Sydney IX", ProvisioningStatus: "LIVE", RateLimit: 1000, VLAN: 300, ASN: 65000, NetworkServiceType: "Sydney IX"},
+			{ProductUID: "ix-2", ProductName: "Old IX", ProvisioningStatus: "DECOMMISSIONED"},
+		},
+	}
+
+	nodes := buildTopologyNodes([]*megaport.Port{port}, nil, nil, "", false)
+	assert.Len(t, nodes, 1)
+	assert.Equal(t, []TopologyIX{
+		{UID: "ix-1", Name: "Sydney IX", Status: "LIVE", RateMbps: 1000, VLAN: 300, ASN: 65000, NetworkServiceType: "Sydney IX"},
+	}, nodes[0].IXs)
+
+	nodes = buildTopologyNodes([]*megaport.Port{port}, nil, nil, "", true)
+	assert.Len(t, nodes[0].IXs, 2)
+}
+
+// ── NAT Gateways and partners ────────────────────────────────────────────────
+
+func TestNATGatewayNodes(t *testing.T) {
+	gateways := []*megaport.NATGateway{
+		{ProductUID: "nat-1", ProductName: "Egress", ProvisioningStatus: "LIVE", Speed: 1000, LocationID: 5},
+		{ProductUID: "nat-2", ProductName: "Gone", ProvisioningStatus: "CANCELLED", LocationID: 5},
+		nil,
+	}
+	nodes := natGatewayNodes(gateways, map[int]string{5: "Equinix SY1"}, false)
+	assert.Equal(t, []TopologyNode{
+		{UID: "nat-1", Name: "Egress", Type: "NAT Gateway", Status: "LIVE", SpeedMbps: 1000, Location: "Equinix SY1"},
+	}, nodes)
+}
+
+func TestNATGatewayLocations(t *testing.T) {
+	client := &megaport.Client{}
+	client.LocationService = &MockLocationService{
+		GetLocationByIDV3Result: map[int]*megaport.LocationV3{7: {ID: 7, Name: "Global Switch SY3"}},
+	}
+	ports := []*megaport.Port{{LocationID: 5, LocationDetails: &megaport.ProductLocationDetails{Name: "Equinix SY1"}}}
+	gateways := []*megaport.NATGateway{{LocationID: 5}, {LocationID: 7}, {LocationID: 9}}
+
+	locations := natGatewayLocations(context.Background(), client, gateways, ports, nil, nil)
+	assert.Equal(t, map[int]string{
+		5: "Equinix SY1",       // shared with a port
+		7: "Global Switch SY3", // looked up
+		9: "Location 9",        // lookup failed
+	}, locations)
+}
+
+func TestResolvePartnerBEnds(t *testing.T) {
+	nodes := []TopologyNode{{
+		UID: "port-aaa",
+		Connections: []TopologyVXC{
+			{UID: "vxc-1", BEndUID: "aws-port", BEndName: "AWS Sydney"},
+			{UID: "vxc-2", BEndUID: "gcp-port"},
+			{UID: "vxc-3", BEndUID: "own-port", BEndName: "Mine"},
+		},
+	}}
+	partners := []*megaport.PartnerMegaport{
+		{ProductUID: "aws-port", ProductName: "Asia Pacific (Sydney) (ap-southeast-2)", CompanyName: "AWS", ConnectType: "AWS"},
+		{ProductUID: "gcp-port", ProductName: "Sydney (syd-zone1-1660)", CompanyName: "Google inc..", ConnectType: "GOOGLE"},
+	}
+
+	resolvePartnerBEnds(nodes, partners)
+	conns := nodes[0].Connections
+	assert.Equal(t, "AWS Sydney", conns[0].BEndName, "the VXC's own B-End name is kept")
+	assert.Equal(t, "AWS", conns[0].BEndPartner)
+	assert.Equal(t, "Sydney (syd-zone1-1660)", conns[1].BEndName)
+	assert.Equal(t, "GOOGLE", conns[1].BEndConnectType)
+	assert.Empty(t, conns[2].BEndPartner)
+}
+
 // ── renderTree ────────────────────────────────────────────────────────────────
 
 func TestRenderTree_Empty(t *testing.T) {
@@ -183,6 +255,22 @@ func TestRenderTree_BEndNoLocation(t *testing.T) {
 	assert.NotContains(t, out, "Remote Port ()")
 }
 
+func TestRenderTree_IXsAndPartners(t *testing.T) {
+	nodes := []TopologyNode{
+		{
+			UID: "p-1", Name: "Port", Type: "Port", Status: "LIVE", SpeedMbps: 1000,
+			Connections: []TopologyVXC{
+				{UID: "v-1", Name: "Link", Status: "LIVE", RateMbps: 100, BEndName: "ap-southeast-2", BEndPartner: "Amazon Web Services", BEndConnectType: "AWS"},
+			},
+			IXs: []TopologyIX{{UID: "ix-1", Name: "Peering", Status: "LIVE", RateMbps: 1000, VLAN: 300, NetworkServiceType: "Sydney IX"}},
+		},
+	}
+	out := renderTree(nodes, true)
+	assert.Contains(t, out, "├── Link (LIVE, 100 Mbps) → ap-southeast-2 [Amazon Web Services (AWS)]")
+	assert.Contains(t, out, "└── IX Peering (LIVE, 1 Gbps, VLAN 300) → Sydney IX")
+	assert.NotContains(t, out, "(no connections)")
+}
+
 // ── formatSpeed ──────────────────────────────────────────────────────────────
 
 func TestFormatSpeed(t *testing.T) {
@@ -196,13 +284,21 @@ func TestFormatSpeed(t *testing.T) {
 
 // ── ShowTopology integration ──────────────────────────────────────────────────
 
-func setupTopologyMocks(portSvc *MockPortService, mcrSvc *MockMCRService, mveSvc *MockMVEService) func() {
+// setupTopologyMocks logs in to a client backed by the given services. NAT
+// Gateway, partner and location services are empty unless set by opts.
+func setupTopologyMocks(portSvc *MockPortService, mcrSvc *MockMCRService, mveSvc *MockMVEService, opts ...func(*megaport.Client)) func() {
 	original := config.GetLoginFunc()
 	config.SetLoginFunc(func(ctx context.Context) (*megaport.Client, error) {
 		client := &megaport.Client{}
 		client.PortService = portSvc
 		client.MCRService = mcrSvc
 		client.MVEService = mveSvc
+		client.NATGatewayService = &MockNATGatewayService{}
+		client.PartnerService = &MockPartnerService{}
+		client.LocationService = &MockLocationService{}
+		for _, opt := range opts {
+			opt(client)
+		}
 		return client, nil
 	})
 	return func() { config.SetLoginFunc(original) }
@@ -332,3 +428,96 @@ func TestShowTopology_ListPortsError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "port service unavailable")
 }
+
+func TestShowTopology_NATGatewaysAndPartners(t *testing.T) {
+	cleanup := setupTopologyMocks(
+		&MockPortService{
+			ListPortsResult: []*megaport.Port{
+				{
+					UID: "port-aaa", Name: "Sydney-Primary", ProvisioningStatus: "LIVE", PortSpeed: 10000,
+					LocationID: 5, LocationDetails: &megaport.ProductLocationDetails{Name: "Equinix SY1"},
+					AssociatedVXCs: []*megaport.VXC{
+						{
+							UID: "vxc-1", Name: "AWS-SYD", ProvisioningStatus: "LIVE", RateLimit: 500,
+							AEndConfiguration: megaport.VXCEndConfiguration{UID: "port-aaa"},
+							BEndConfiguration: megaport.VXCEndConfiguration{UID: "aws-port"},
+						},
+					},
+				},
+			},
+		},
+		&MockMCRService{},
+		&MockMVEService{},
+		func(c *megaport.Client) {
+			c.NATGatewayService = &MockNATGatewayService{ListNATGatewaysResult: []*megaport.NATGateway{
+				{ProductUID: "nat-1", ProductName: "Egress", ProvisioningStatus: "LIVE", Speed: 1000, LocationID: 5},
+			}}
+			c.PartnerService = &MockPartnerService{ListPartnerMegaportsResult: []*megaport.PartnerMegaport{
+				{ProductUID: "aws-port", ProductName: "Asia Pacific (Sydney)", CompanyName: "AWS", ConnectType: "AWS"},
+			}}
+		},
+	)
+	defer cleanup()
+
+	cmd := &cobra.Command{Use: "topology"}
+	cmd.Flags().Bool("include-inactive", false, "")
+	cmd.Flags().String("type", "", "")
+
+	captured := output.CaptureOutput(func() {
+		err := ShowTopology(cmd, nil, true, "json")
+		assert.NoError(t, err)
+	})
+
+	var parsed []TopologyNode
+	assert.NoError(t, json.Unmarshal([]byte(captured), &parsed))
+	assert.Len(t, parsed, 2)
+	assert.Equal(t, "Asia Pacific (Sydney)", parsed[0].Connections[0].BEndName)
+	assert.Equal(t, "AWS", parsed[0].Connections[0].BEndPartner)
+	assert.Equal(t, "NAT Gateway", parsed[1].Type)
+	assert.Equal(t, "Equinix SY1", parsed[1].Location)
+}
+
+func TestShowTopology_PartnerListErrorIsNotFatal(t *testing.T) {
+	cleanup := setupTopologyMocks(
+		&MockPortService{ListPortsResult: []*megaport.Port{{UID: "port-aaa", Name: "Sydney-Primary", ProvisioningStatus: "LIVE"}}},
+		&MockMCRService{},
+		&MockMVEService{},
+		func(c *megaport.Client) {
+			c.PartnerService = &MockPartnerService{ListPartnerMegaportsErr: fmt.Errorf("marketplace unavailable")}
+		},
+	)
+	defer cleanup()
+
+	cmd := &cobra.Command{Use: "topology"}
+	cmd.Flags().Bool("include-inactive", false, "")
+	cmd.Flags().String("type", "", "")
+
+	captured := output.CaptureOutput(func() {
+		err := ShowTopology(cmd, nil, true, "table")
+		assert.NoError(t, err)
+	})
+	assert.Contains(t, captured, "Sydney-Primary")
+}
+
+func TestShowTopology_ListNATGatewaysError(t *testing.T) {
+	cleanup := setupTopologyMocks(
+		&MockPortService{},
+		&MockMCRService{},
+		&MockMVEService{},
+		func(c *megaport.Client) {
+			c.NATGatewayService = &MockNATGatewayService{ListNATGatewaysErr: fmt.Errorf("forbidden")}
+		},
+	)
+	defer cleanup()
+
+	cmd := &cobra.Command{Use: "topology"}
+	cmd.Flags().Bool("include-inactive", false, "")
+	cmd.Flags().String("type", "", "")
+
+	var err error
+	output.CaptureOutput(func() {
+		err = ShowTopology(cmd, nil, true, "table")
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list NAT Gateways")
+}
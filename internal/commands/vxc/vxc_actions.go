@@ -308,6 +308,8 @@ func BuyVXC(cmd *cobra.Command, args []string, noColor bool) error {
 		}
 	}
 
+	req.ResourceTags = utils.WithDefaultResourceTags(utils.ProjectSettingsFromCmd(cmd).ResourceTags, req.ResourceTags)
+
 	var spinner *output.Spinner
 	if noWait {
 		spinner = output.PrintResourceCreating("VXC", req.VXCName, noColor)
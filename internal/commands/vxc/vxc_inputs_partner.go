@@ -17,6 +17,13 @@ func parsePartnerConfigFromJSON(jsonStr string) (megaport.VXCPartnerConfiguratio
 	return parsePartnerConfigFromMap(rawConfig)
 }
 
+// ParsePartnerConfig parses a decoded partner configuration object, in the
+// format accepted by the partnerConfig field of vxc buy --json, so other
+// commands can accept the same partner configs as the VXC commands.
+func ParsePartnerConfig(rawConfig map[string]interface{}) (megaport.VXCPartnerConfiguration, error) {
+	return parsePartnerConfigFromMap(rawConfig)
+}
+
 func parsePartnerConfigFromMap(rawConfig map[string]interface{}) (megaport.VXCPartnerConfiguration, error) {
 	connectType, ok := rawConfig["connectType"].(string)
 	if !ok {
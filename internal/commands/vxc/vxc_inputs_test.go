@@ -79,6 +79,15 @@ func TestParsePartnerConfigFromJSON(t *testing.T) {
 	}
 }
 
+func TestParsePartnerConfig_ValidConnectTypesDispatched(t *testing.T) {
+	for _, connectType := range validation.ValidPartnerConnectTypes {
+		_, err := ParsePartnerConfig(map[string]interface{}{"connectType": connectType})
+		if err != nil {
+			assert.NotContains(t, err.Error(), "unsupported connect type", connectType)
+		}
+	}
+}
+
 func TestParseAWSConfig(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -86,6 +86,13 @@ var resolvePartnerPortUID = func(ctx context.Context, svc megaport.VXCService, p
 	}
 }
 
+// ResolvePartnerPortUID looks up the partner port for an Azure, Google or
+// Oracle partner config from its key. It returns ("", nil) for other partner
+// types, whose port UID must be given explicitly.
+func ResolvePartnerPortUID(ctx context.Context, svc megaport.VXCService, partnerConfig megaport.VXCPartnerConfiguration) (string, error) {
+	return resolvePartnerPortUID(ctx, svc, partnerConfig)
+}
+
 var listVXCResourceTagsFunc = func(ctx context.Context, client *megaport.Client, vxcUID string) (map[string]string, error) {
 	return client.VXCService.ListVXCResourceTags(ctx, vxcUID)
 }
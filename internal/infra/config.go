@@ -0,0 +1,307 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/commands/vxc"
+	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/megaport/megaport-cli/internal/validation"
+	megaport "github.com/megaport/megaportgo"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultWaitTime = 10 * time.Minute
+	StatusError     = "error"
+	StatusUnchanged = "unchanged"
+	StatusUpdated   = "updated"
+
+	// maxConfigFileSize caps how much of a config file is read into memory. Real
+	// infra configs are kilobytes; this guards against pointing --file at a huge
+	// or pathological file (e.g. /dev/zero) and exhausting memory.
+	maxConfigFileSize = 10 * 1024 * 1024
+)
+
+// TemplateRe matches {{.type.name}} references in config values.
+var TemplateRe = regexp.MustCompile(`\{\{\.(\w+)\.([^}]+)\}\}`)
+
+// VXCRequest builds the order for a VXC whose endpoint templates resolved to
+// aUID and bUID, adding defaultTags to its resource tags.
+func VXCRequest(ctx context.Context, client *megaport.Client, v VXCConfig, aUID, bUID string, defaultTags map[string]string) (*megaport.BuyVXCRequest, error) {
+	aEnd, err := vxcOrderEndpoint(ctx, client, v.AEnd, aUID)
+	if err != nil {
+		return nil, fmt.Errorf("a_end: %w", err)
+	}
+	bEnd, err := vxcOrderEndpoint(ctx, client, v.BEnd, bUID)
+	if err != nil {
+		return nil, fmt.Errorf("b_end: %w", err)
+	}
+	return &megaport.BuyVXCRequest{
+		PortUID:           aEnd.ProductUID,
+		VXCName:           v.Name,
+		RateLimit:         v.RateLimit,
+		Term:              v.Term,
+		AEndConfiguration: aEnd,
+		BEndConfiguration: bEnd,
+		CostCentre:        v.CostCentre,
+		ResourceTags:      utils.WithDefaultResourceTags(defaultTags, v.ResourceTags),
+		WaitForProvision:  false,
+	}, nil
+}
+
+// vxcOrderEndpoint builds one end of a VXC order. An end with a partner config
+// but no product_uid is ordered onto the partner port its key looks up, as
+// vxc buy does.
+func vxcOrderEndpoint(ctx context.Context, client *megaport.Client, e VXCEndpointConfig, uid string) (megaport.VXCOrderEndpointConfiguration, error) {
+	end := megaport.VXCOrderEndpointConfiguration{ProductUID: uid, VLAN: e.VLAN}
+	if e.InnerVLAN != 0 || e.VNICIndex != nil {
+		end.VXCOrderMVEConfig = &megaport.VXCOrderMVEConfig{InnerVLAN: e.InnerVLAN}
+		if e.VNICIndex != nil {
+			if err := validation.ValidateVNICIndex(*e.VNICIndex); err != nil {
+				return end, err
+			}
+			end.NetworkInterfaceIndex = *e.VNICIndex
+		}
+	}
+	if e.PartnerConfig == nil {
+		return end, nil
+	}
+	raw, err := NormalizeVendorConfigMap(e.PartnerConfig)
+	if err != nil {
+		return end, fmt.Errorf("invalid partner_config: %w", err)
+	}
+	end.PartnerConfig, err = vxc.ParsePartnerConfig(raw)
+	if err != nil {
+		return end, fmt.Errorf("invalid partner_config: %w", err)
+	}
+	if end.ProductUID == "" {
+		err := utils.WithIdempotentRetry(ctx, func(ctx context.Context) error {
+			var e error
+			end.ProductUID, e = vxc.ResolvePartnerPortUID(ctx, client.VXCService, end.PartnerConfig)
+			return e
+		})
+		if err != nil {
+			return end, fmt.Errorf("looking up partner port: %w", err)
+		}
+		if end.ProductUID == "" {
+			return end, fmt.Errorf("product_uid is required; only Azure, Google and Oracle partner ports can be looked up from their key")
+		}
+	}
+	return end, nil
+}
+
+// ResourceTagMap converts a resource tag list back to a map.
+func ResourceTagMap(list []megaport.ResourceTag) map[string]string {
+	tags := make(map[string]string, len(list))
+	for _, t := range list {
+		tags[t.Key] = t.Value
+	}
+	return tags
+}
+
+// ParseConfigFile reads filePath and decodes it into Config, detecting YAML
+// vs JSON by file extension. Unknown keys are rejected so a mistyped field (e.g.
+// tunnelCount instead of tunnel_count) is a clear error rather than silently dropped.
+//
+// Files named by the config's include list are merged into it, and {{.var.name}}
+// and ${env:NAME} references are substituted, with vars overriding the values
+// declared in the variables block. Every reference that cannot be resolved is
+// reported here, before anything is ordered. The project config's variables
+// and default resource tags are applied as described for ExpandConfigNode and
+// addDefaultResourceTags.
+func ParseConfigFile(filePath string, vars map[string]*yaml.Node, project utils.ProjectSettings) (*Config, error) {
+	data, err := ReadConfigData(filePath)
+	if err != nil {
+		return nil, err
+	}
+	doc, composed, err := LoadConfigNode(filePath, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	substituted, err := ExpandConfigNode(doc, vars, project.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if !composed && !substituted {
+		// Decode the file as written, so that errors point at its own lines.
+		if err := decodeConfigData(data, isJSONConfig(filePath), cfg); err != nil {
+			return nil, err
+		}
+		addDefaultResourceTags(cfg, project.ResourceTags)
+		return cfg, nil
+	}
+	expanded, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("encoding expanded config: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(expanded))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing config after includes and variables: %w", err)
+	}
+	addDefaultResourceTags(cfg, project.ResourceTags)
+	return cfg, nil
+}
+
+// addDefaultResourceTags adds the project config's default resource tags to
+// every entry that declares resource tags, except keys the entry sets itself.
+// Entries without resource_tags stay untagged here, so their live tags are
+// neither compared nor replaced; they get the defaults only when ordered.
+func addDefaultResourceTags(cfg *Config, defaults map[string]string) {
+	withDefaults := func(tags map[string]string) map[string]string {
+		if tags == nil {
+			return nil
+		}
+		return utils.WithDefaultResourceTags(defaults, tags)
+	}
+	for i := range cfg.Ports {
+		cfg.Ports[i].ResourceTags = withDefaults(cfg.Ports[i].ResourceTags)
+	}
+	for i := range cfg.MCRs {
+		cfg.MCRs[i].ResourceTags = withDefaults(cfg.MCRs[i].ResourceTags)
+	}
+	for i := range cfg.MVEs {
+		cfg.MVEs[i].ResourceTags = withDefaults(cfg.MVEs[i].ResourceTags)
+	}
+	for i := range cfg.NATGateways {
+		cfg.NATGateways[i].ResourceTags = withDefaults(cfg.NATGateways[i].ResourceTags)
+	}
+	for i := range cfg.VXCs {
+		cfg.VXCs[i].ResourceTags = withDefaults(cfg.VXCs[i].ResourceTags)
+	}
+}
+
+// ReadConfigData reads a config file, refusing one over maxConfigFileSize.
+func ReadConfigData(filePath string) ([]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	defer f.Close()
+
+	// Read one byte past the cap so an over-limit file is detected rather than
+	// silently truncated.
+	data, err := io.ReadAll(io.LimitReader(f, maxConfigFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	if len(data) > maxConfigFileSize {
+		return nil, fmt.Errorf("config file %q exceeds maximum size of %d bytes", filePath, maxConfigFileSize)
+	}
+	return data, nil
+}
+
+// isJSONConfig reports whether filePath is decoded as JSON rather than YAML.
+func isJSONConfig(filePath string) bool {
+	return strings.ToLower(filepath.Ext(filePath)) == ".json"
+}
+
+// decodeConfigData decodes a single JSON or YAML document from data into out.
+// Unknown keys are rejected when out is a struct.
+func decodeConfigData(data []byte, isJSON bool, out interface{}) error {
+	if isJSON {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(out); err != nil {
+			return fmt.Errorf("parsing JSON config: %w", err)
+		}
+		// Reject trailing data so a duplicated or concatenated body isn't silently ignored.
+		if dec.More() {
+			return fmt.Errorf("parsing JSON config: unexpected trailing data after the config object")
+		}
+		return nil
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	// An empty document decodes to io.EOF; treat it as an empty config.
+	if err := dec.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing YAML config: %w", err)
+	}
+	// Reject a further document that carries content so a stray one isn't silently
+	// dropped. A trailing `---` or comment decodes to an empty document and is benign.
+	for {
+		var extra interface{}
+		err := dec.Decode(&extra)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("parsing YAML config: %w", err)
+		}
+		if extra != nil {
+			return fmt.Errorf("parsing YAML config: unexpected content after the first document")
+		}
+	}
+	return nil
+}
+
+// ResolveTemplates replaces {{.type.name}} placeholders using the uids map.
+// {{.var.name}} references have already been substituted by ParseConfigFile.
+func ResolveTemplates(s string, uids map[string]map[string]string) (string, error) {
+	return ExpandTemplates(s, TemplateRe, func(sub []string) (string, error) {
+		resType, resName := sub[1], sub[2]
+		if typeMap, ok := uids[resType]; ok {
+			if uid, ok := typeMap[resName]; ok {
+				return uid, nil
+			}
+		}
+		return "", fmt.Errorf("no UID found for reference %q (type=%q name=%q)", sub[0], resType, resName)
+	})
+}
+
+// ExpandTemplates replaces each match of re in s with the value lookup returns
+// for its submatches. A match lookup fails on is left in place, and every
+// failure is returned joined.
+func ExpandTemplates(s string, re *regexp.Regexp, lookup func(sub []string) (string, error)) (string, error) {
+	var expandErr error
+	result := re.ReplaceAllStringFunc(s, func(match string) string {
+		v, err := lookup(re.FindStringSubmatch(match))
+		if err != nil {
+			expandErr = errors.Join(expandErr, err)
+			return match
+		}
+		return v
+	})
+	return result, expandErr
+}
+
+// NormalizeVendorConfigMap round-trips a vendor or partner config map through
+// JSON so that YAML-decoded integers (int) become float64, matching what
+// ParseVendorConfig and ParsePartnerConfig expect.
+func NormalizeVendorConfigMap(m map[string]interface{}) (map[string]interface{}, error) {
+	if m == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("normalizing config map: %w", err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("normalizing config map: %w", err)
+	}
+	return out, nil
+}
+
+// SortedKeys returns m's keys in order.
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
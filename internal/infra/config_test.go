@@ -0,0 +1,327 @@
+package infra
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveTemplates_NoTemplate(t *testing.T) {
+	uids := map[string]map[string]string{"port": {"Sydney": "uid-abc"}}
+	result, err := ResolveTemplates("some-fixed-uid", uids)
+	require.NoError(t, err)
+	assert.Equal(t, "some-fixed-uid", result)
+}
+
+func TestResolveTemplates_ResolvesReference(t *testing.T) {
+	uids := map[string]map[string]string{
+		"port": {"Sydney-Primary": "port-uid-123"},
+		"mcr":  {"Sydney-MCR": "mcr-uid-456"},
+	}
+
+	result, err := ResolveTemplates("{{.port.Sydney-Primary}}", uids)
+	require.NoError(t, err)
+	assert.Equal(t, "port-uid-123", result)
+
+	result, err = ResolveTemplates("{{.mcr.Sydney-MCR}}", uids)
+	require.NoError(t, err)
+	assert.Equal(t, "mcr-uid-456", result)
+}
+
+func TestResolveTemplates_UnknownReference(t *testing.T) {
+	uids := map[string]map[string]string{"port": {}}
+	_, err := ResolveTemplates("{{.port.Missing}}", uids)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Missing")
+}
+
+func TestResolveTemplates_UnknownType(t *testing.T) {
+	uids := map[string]map[string]string{}
+	_, err := ResolveTemplates("{{.vxc.SomeVXC}}", uids)
+	assert.Error(t, err)
+}
+
+func TestNormalizeVendorConfigMap_IntToFloat64(t *testing.T) {
+	// YAML decodes integers as int; NormalizeVendorConfigMap converts them to float64
+	// so that ParseVendorConfig (which uses float64 type assertions) works correctly.
+	input := map[string]interface{}{
+		"vendor":      "cisco",
+		"imageId":     42, // int from YAML
+		"productSize": "SMALL",
+	}
+	out, err := NormalizeVendorConfigMap(input)
+	require.NoError(t, err)
+	// After round-trip through JSON, integers become float64
+	assert.IsType(t, float64(0), out["imageId"])
+	assert.Equal(t, float64(42), out["imageId"])
+	assert.Equal(t, "cisco", out["vendor"])
+}
+
+func TestNormalizeVendorConfigMap_Nil(t *testing.T) {
+	out, err := NormalizeVendorConfigMap(nil)
+	require.NoError(t, err)
+	assert.Nil(t, out)
+}
+
+func TestParseConfigFile_YAML(t *testing.T) {
+	content := `
+ports:
+  - name: Test-Port
+    location_id: 1
+    speed: 1000
+    term: 12
+    marketplace_visibility: true
+
+mcrs:
+  - name: Test-MCR
+    location_id: 2
+    speed: 1000
+    term: 12
+    asn: 65000
+
+vxcs:
+  - name: Test-VXC
+    rate_limit: 100
+    term: 12
+    a_end:
+      product_uid: "{{.port.Test-Port}}"
+      vlan: 100
+    b_end:
+      product_uid: "{{.mcr.Test-MCR}}"
+`
+	f := writeTempFile(t, "config.yaml", content)
+	cfg, err := ParseConfigFile(f, nil, utils.ProjectSettings{})
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Ports, 1)
+	assert.Equal(t, "Test-Port", cfg.Ports[0].Name)
+	assert.Equal(t, 1000, cfg.Ports[0].Speed)
+	assert.True(t, cfg.Ports[0].MarketplaceVisibility)
+
+	require.Len(t, cfg.MCRs, 1)
+	assert.Equal(t, "Test-MCR", cfg.MCRs[0].Name)
+	assert.Equal(t, 65000, cfg.MCRs[0].ASN)
+
+	require.Len(t, cfg.VXCs, 1)
+	assert.Equal(t, "{{.port.Test-Port}}", cfg.VXCs[0].AEnd.ProductUID)
+	assert.Equal(t, 100, cfg.VXCs[0].AEnd.VLAN)
+}
+
+func TestParseConfigFile_JSON(t *testing.T) {
+	content := `{
+  "ports": [
+    {"name": "JSON-Port", "location_id": 5, "speed": 10000, "term": 24}
+  ]
+}`
+	f := writeTempFile(t, "config.json", content)
+	cfg, err := ParseConfigFile(f, nil, utils.ProjectSettings{})
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Ports, 1)
+	assert.Equal(t, "JSON-Port", cfg.Ports[0].Name)
+	assert.Equal(t, 10000, cfg.Ports[0].Speed)
+}
+
+func TestParseConfigFile_NotFound(t *testing.T) {
+	_, err := ParseConfigFile("/nonexistent/path/config.yaml", nil, utils.ProjectSettings{})
+	assert.Error(t, err)
+}
+
+func TestParseConfigFile_InvalidYAML(t *testing.T) {
+	f := writeTempFile(t, "bad.yaml", "ports: [invalid yaml }")
+	_, err := ParseConfigFile(f, nil, utils.ProjectSettings{})
+	assert.Error(t, err)
+}
+
+func TestParseConfigFile_EmptyFile(t *testing.T) {
+	f := writeTempFile(t, "empty.yaml", "")
+	cfg, err := ParseConfigFile(f, nil, utils.ProjectSettings{})
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Ports)
+	assert.Empty(t, cfg.MCRs)
+	assert.Empty(t, cfg.VXCs)
+}
+
+// writeTempFile creates a temp file with the given name suffix and content,
+// returning its path.
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+// A mistyped key (camelCase tunnelCount) or a stray key must be a clear error
+// rather than silently dropped, which is the failure mode behind issue #439.
+func TestParseConfigFile_RejectsUnknownField(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "yaml camelCase tunnelCount",
+			file: "config.yaml",
+			content: `
+mcrs:
+  - name: MCR
+    location_id: 2
+    speed: 1000
+    term: 12
+    tunnelCount: 10
+`,
+		},
+		{
+			name:    "json camelCase tunnelCount",
+			file:    "config.json",
+			content: `{"mcrs":[{"name":"MCR","location_id":2,"speed":1000,"term":12,"tunnelCount":10}]}`,
+		},
+		{
+			name: "yaml unknown top-level key",
+			file: "config.yaml",
+			content: `
+version: "1.0"
+mcrs: []
+`,
+		},
+		{
+			name: "yaml unknown port field",
+			file: "config.yaml",
+			content: `
+ports:
+  - name: P
+    location_id: 1
+    speed: 1000
+    term: 12
+    locationId: 1
+`,
+		},
+		{
+			name:    "json unknown vxc field",
+			file:    "config.json",
+			content: `{"vxcs":[{"name":"V","rate_limit":100,"term":12,"rateLimit":100}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := writeTempFile(t, tt.file, tt.content)
+			_, err := ParseConfigFile(f, nil, utils.ProjectSettings{})
+			assert.Error(t, err, "unknown key should be a clear error, not silently dropped")
+		})
+	}
+}
+
+func TestParseConfigFile_RejectsTrailingJSON(t *testing.T) {
+	// json.NewDecoder reads only the first value; trailing data must be rejected so
+	// a duplicated or concatenated config body isn't silently half-applied.
+	jsonCfg := `{"mcrs":[{"name":"M","location_id":2,"speed":1000,"term":12}]}{"mcrs":[]}`
+	f := writeTempFile(t, "config.json", jsonCfg)
+	_, err := ParseConfigFile(f, nil, utils.ProjectSettings{})
+	assert.Error(t, err, "trailing JSON data should be rejected, not silently ignored")
+}
+
+func TestParseConfigFile_RejectsMultipleYAMLDocuments(t *testing.T) {
+	// A second ---separated document would otherwise be silently dropped.
+	yaml := `
+mcrs:
+  - name: First
+    location_id: 2
+    speed: 1000
+    term: 12
+---
+mcrs:
+  - name: Second
+    location_id: 2
+    speed: 1000
+    term: 12
+`
+	f := writeTempFile(t, "config.yaml", yaml)
+	_, err := ParseConfigFile(f, nil, utils.ProjectSettings{})
+	assert.Error(t, err, "multiple YAML documents should be rejected, not silently dropped")
+}
+
+func TestParseConfigFile_AllowsBenignTrailingContent(t *testing.T) {
+	// A trailing document marker or comment carries no data, so it must be accepted.
+	// Only a second document with real content is rejected (see RejectsMultipleYAMLDocuments).
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "yaml trailing bare separator",
+			file: "config.yaml",
+			content: `
+mcrs:
+  - name: Only
+    location_id: 2
+    speed: 1000
+    term: 12
+---
+`,
+		},
+		{
+			name: "yaml trailing separator then comment",
+			file: "config.yaml",
+			content: `
+mcrs:
+  - name: Only
+    location_id: 2
+    speed: 1000
+    term: 12
+---
+# nothing here
+`,
+		},
+		{
+			name:    "json trailing whitespace",
+			file:    "config.json",
+			content: "{\"mcrs\":[{\"name\":\"Only\",\"location_id\":2,\"speed\":1000,\"term\":12}]}\n   \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := writeTempFile(t, tt.file, tt.content)
+			cfg, err := ParseConfigFile(f, nil, utils.ProjectSettings{})
+			require.NoError(t, err, "benign trailing content should be accepted")
+			require.Len(t, cfg.MCRs, 1, "the first document's data must survive")
+		})
+	}
+}
+
+func TestParseConfigFile_AllowsFreeformMapKeys(t *testing.T) {
+	// resource_tags and vendor_config are map types, so their inner keys are not
+	// subject to strict field checking — arbitrary (incl. camelCase) keys must survive.
+	yaml := `
+ports:
+  - name: P
+    location_id: 1
+    speed: 1000
+    term: 12
+    resource_tags:
+      ownerTeam: networking
+      costCenter: "1234"
+mves:
+  - name: M
+    location_id: 1
+    term: 12
+    vendor_config:
+      vendor: cisco
+      imageId: 42
+      productSize: SMALL
+`
+	f := writeTempFile(t, "config.yaml", yaml)
+	cfg, err := ParseConfigFile(f, nil, utils.ProjectSettings{})
+	require.NoError(t, err)
+	require.Len(t, cfg.Ports, 1)
+	assert.Equal(t, "networking", cfg.Ports[0].ResourceTags["ownerTeam"])
+	assert.Equal(t, "1234", cfg.Ports[0].ResourceTags["costCenter"])
+	require.Len(t, cfg.MVEs, 1)
+	assert.Equal(t, "cisco", cfg.MVEs[0].VendorConfig["vendor"])
+	assert.Contains(t, cfg.MVEs[0].VendorConfig, "imageId")
+}
@@ -0,0 +1,837 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"github.com/megaport/megaport-cli/internal/utils"
+	megaport "github.com/megaport/megaportgo"
+)
+
+// Plan actions.
+const (
+	PlanCreate   = "create"
+	PlanUpdate   = "update"
+	PlanReplace  = "replace"
+	PlanNoOp     = "no-op"
+	PlanOrphaned = "orphaned"
+)
+
+// replacementFields lists, per template type, the config fields the update APIs
+// cannot change. A change to any of them means ordering a new resource and
+// deleting the old one.
+var replacementFields = map[string][]string{
+	"port":        {"location_id", "speed", "lag_count", "diversity_zone"},
+	"mcr":         {"location_id", "speed", "diversity_zone"},
+	"mve":         {"location_id", "vendor_config.vendor", "diversity_zone"},
+	"nat_gateway": {"location_id", "diversity_zone"},
+	"ix":          {"network_service_type"},
+	"service_key": {"name", "max_speed", "vlan", "pre_approved"},
+}
+
+// ReplacedFields returns the labels in changes that require replacing resType.
+func ReplacedFields(resType string, changes []output.FieldChange) []string {
+	var fields []string
+	for _, c := range changes {
+		if slices.Contains(replacementFields[resType], c.Label) {
+			fields = append(fields, c.Label)
+		}
+	}
+	return fields
+}
+
+// KnownAfterApply stands in for a value that only exists once a dependency has
+// been provisioned (e.g. the UID of a port the same apply will create).
+const KnownAfterApply = "(known after apply)"
+
+// PlanEntry describes what apply would do with a single config entry.
+type PlanEntry struct {
+	output.Output `json:"-" header:"-"`
+	Type          string               `json:"type"              header:"Type"`
+	Name          string               `json:"name"              header:"Name"`
+	Action        string               `json:"action"            header:"Action"`
+	UID           string               `json:"uid,omitempty"     header:"UID"`
+	Detail        string               `json:"detail,omitempty"  header:"Detail"`
+	Changes       []output.FieldChange `json:"changes,omitempty" header:"-"`
+}
+
+// Inventory holds the active resources in the account, keyed by UID.
+type Inventory struct {
+	Ports       map[string]*megaport.Port
+	MCRs        map[string]*megaport.MCR
+	MVEs        map[string]*megaport.MVE
+	NATGateways map[string]*megaport.NATGateway
+	IXs         map[string]*megaport.IX
+	ServiceKeys map[string]*megaport.ServiceKey
+	VXCs        map[string]*megaport.VXC
+}
+
+// FetchInventory lists the account's active resources of every type in parallel.
+func FetchInventory(ctx context.Context, client *megaport.Client) (*Inventory, error) {
+	inv := &Inventory{
+		Ports:       map[string]*megaport.Port{},
+		MCRs:        map[string]*megaport.MCR{},
+		MVEs:        map[string]*megaport.MVE{},
+		NATGateways: map[string]*megaport.NATGateway{},
+		IXs:         map[string]*megaport.IX{},
+		ServiceKeys: map[string]*megaport.ServiceKey{},
+		VXCs:        map[string]*megaport.VXC{},
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	fetch := func(what string, fn func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("listing %s: %w", what, err))
+				mu.Unlock()
+			}
+		}()
+	}
+
+	fetch("ports", func() error {
+		ports, err := client.PortService.ListPorts(ctx)
+		if err != nil {
+			return err
+		}
+		// ListPorts leaves LagCount unset; a LAG's size is the number of
+		// ports sharing its aggregation ID.
+		lagSizes := map[int]int{}
+		for _, p := range ports {
+			if p != nil && p.AggregationID != 0 {
+				lagSizes[p.AggregationID]++
+			}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, p := range ports {
+			// ListPorts has no IncludeInactive parameter; filter client-side.
+			if p != nil && utils.IsActiveStatus(p.ProvisioningStatus) {
+				p.LagCount = lagSizes[p.AggregationID]
+				inv.Ports[p.UID] = p
+			}
+		}
+		return nil
+	})
+	fetch("MCRs", func() error {
+		mcrs, err := client.MCRService.ListMCRs(ctx, &megaport.ListMCRsRequest{})
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, m := range mcrs {
+			if m != nil {
+				inv.MCRs[m.UID] = m
+			}
+		}
+		return nil
+	})
+	fetch("MVEs", func() error {
+		mves, err := client.MVEService.ListMVEs(ctx, &megaport.ListMVEsRequest{})
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, m := range mves {
+			if m != nil {
+				inv.MVEs[m.UID] = m
+			}
+		}
+		return nil
+	})
+	fetch("NAT gateways", func() error {
+		gateways, err := client.NATGatewayService.ListNATGateways(ctx)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, g := range gateways {
+			if g != nil && utils.IsActiveStatus(g.ProvisioningStatus) {
+				inv.NATGateways[g.ProductUID] = g
+			}
+		}
+		return nil
+	})
+	fetch("IXs", func() error {
+		ixs, err := client.IXService.ListIXs(ctx, &megaport.ListIXsRequest{})
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, x := range ixs {
+			if x != nil {
+				inv.IXs[x.ProductUID] = x
+			}
+		}
+		return nil
+	})
+	fetch("service keys", func() error {
+		resp, err := client.ServiceKeyService.ListServiceKeys(ctx, &megaport.ListServiceKeysRequest{})
+		if err != nil {
+			return err
+		}
+		if resp == nil {
+			return nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, k := range resp.ServiceKeys {
+			if k != nil && !k.Expired {
+				inv.ServiceKeys[k.Key] = k
+			}
+		}
+		return nil
+	})
+	fetch("VXCs", func() error {
+		vxcs, err := client.VXCService.ListVXCs(ctx, &megaport.ListVXCsRequest{})
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, v := range vxcs {
+			if v != nil {
+				inv.VXCs[v.UID] = v
+			}
+		}
+		return nil
+	})
+
+	wg.Wait()
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return inv, nil
+}
+
+// BuildPlan compares cfg against the account. Config entries are matched to
+// live resources the same way apply matches them — through the UID recorded in
+// the apply state — so the plan describes what apply would actually do.
+func BuildPlan(ctx context.Context, client *megaport.Client, cfg *Config, state *State) ([]PlanEntry, error) {
+	inv, err := FetchInventory(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// uids holds the UIDs of matched resources so VXC endpoint templates can be
+	// compared against the live endpoints.
+	uids := NewTypeMap[string]()
+	var plan []PlanEntry
+
+	for _, p := range cfg.Ports {
+		entry := PlanEntry{Type: "Port", Name: p.Name}
+		live := matchLive(state, "port", p.Name, inv.Ports)
+		if live == nil {
+			entry.Action, entry.Detail = PlanCreate, untrackedDetail("port", p.Name, state, inv.Ports, portName)
+			plan = append(plan, entry)
+			continue
+		}
+		uids["port"][p.Name] = live.UID
+		tags, err := LiveTags(ctx, p.ResourceTags, live.UID, client.PortService.ListPortResourceTags)
+		if err != nil {
+			return nil, fmt.Errorf("listing resource tags for port %q: %w", p.Name, err)
+		}
+		plan = append(plan, MatchedEntry(entry, "port", live.UID, PortChanges(p, live, tags)))
+	}
+
+	for _, m := range cfg.MCRs {
+		entry := PlanEntry{Type: "MCR", Name: m.Name}
+		live := matchLive(state, "mcr", m.Name, inv.MCRs)
+		if live == nil {
+			entry.Action, entry.Detail = PlanCreate, untrackedDetail("mcr", m.Name, state, inv.MCRs, mcrName)
+			plan = append(plan, entry)
+			continue
+		}
+		uids["mcr"][m.Name] = live.UID
+		tags, err := LiveTags(ctx, m.ResourceTags, live.UID, client.MCRService.ListMCRResourceTags)
+		if err != nil {
+			return nil, fmt.Errorf("listing resource tags for MCR %q: %w", m.Name, err)
+		}
+		plan = append(plan, MatchedEntry(entry, "mcr", live.UID, MCRChanges(m, live, tags)))
+	}
+
+	for _, mv := range cfg.MVEs {
+		entry := PlanEntry{Type: "MVE", Name: mv.Name}
+		live := matchLive(state, "mve", mv.Name, inv.MVEs)
+		if live == nil {
+			entry.Action, entry.Detail = PlanCreate, untrackedDetail("mve", mv.Name, state, inv.MVEs, mveName)
+			plan = append(plan, entry)
+			continue
+		}
+		uids["mve"][mv.Name] = live.UID
+		tags, err := LiveTags(ctx, mv.ResourceTags, live.UID, client.MVEService.ListMVEResourceTags)
+		if err != nil {
+			return nil, fmt.Errorf("listing resource tags for MVE %q: %w", mv.Name, err)
+		}
+		plan = append(plan, MatchedEntry(entry, "mve", live.UID, MVEChanges(mv, live, tags)))
+	}
+
+	for _, n := range cfg.NATGateways {
+		entry := PlanEntry{Type: "NAT Gateway", Name: n.Name}
+		live := matchLive(state, "nat_gateway", n.Name, inv.NATGateways)
+		if live == nil {
+			entry.Action, entry.Detail = PlanCreate, untrackedDetail("nat_gateway", n.Name, state, inv.NATGateways, NATGatewayName)
+			plan = append(plan, entry)
+			continue
+		}
+		if live.ProvisioningStatus == megaport.STATUS_DESIGN {
+			// A design left by a run that stopped before buying it.
+			entry.Action, entry.UID, entry.Detail = PlanCreate, live.ProductUID, "design exists but has not been bought; apply will buy it"
+			plan = append(plan, entry)
+			continue
+		}
+		uids["nat_gateway"][n.Name] = live.ProductUID
+		var tags map[string]string
+		if n.ResourceTags != nil {
+			tags = ResourceTagMap(live.ResourceTags)
+		}
+		plan = append(plan, MatchedEntry(entry, "nat_gateway", live.ProductUID, NATGatewayChanges(n, live, tags)))
+	}
+
+	for _, x := range cfg.IXs {
+		entry := PlanEntry{Type: "IX", Name: x.Name}
+		live := matchLive(state, "ix", x.Name, inv.IXs)
+		if live == nil {
+			entry.Action, entry.Detail = PlanCreate, untrackedDetail("ix", x.Name, state, inv.IXs, IXName)
+			plan = append(plan, entry)
+			continue
+		}
+		uids["ix"][x.Name] = live.ProductUID
+		plan = append(plan, MatchedEntry(entry, "ix", live.ProductUID, IXChanges(x, live)))
+	}
+
+	for _, k := range cfg.ServiceKeys {
+		entry := PlanEntry{Type: "Service Key", Name: k.Name}
+		live := matchLive(state, "service_key", k.Name, inv.ServiceKeys)
+		if live == nil {
+			entry.Action, entry.Detail = PlanCreate, untrackedDetail("service_key", k.Name, state, inv.ServiceKeys, serviceKeyName)
+			plan = append(plan, entry)
+			continue
+		}
+		uids["service_key"][k.Name] = live.Key
+		plan = append(plan, MatchedEntry(entry, "service_key", live.Key, ServiceKeyChanges(k, live, uids)))
+	}
+
+	for _, v := range cfg.VXCs {
+		entry := PlanEntry{Type: "VXC", Name: v.Name}
+		live := matchLive(state, "vxc", v.Name, inv.VXCs)
+		if live == nil {
+			entry.Action, entry.Detail = PlanCreate, untrackedDetail("vxc", v.Name, state, inv.VXCs, vxcName)
+			plan = append(plan, entry)
+			continue
+		}
+		uids["vxc"][v.Name] = live.UID
+		tags, err := LiveTags(ctx, v.ResourceTags, live.UID, client.VXCService.ListVXCResourceTags)
+		if err != nil {
+			return nil, fmt.Errorf("listing resource tags for VXC %q: %w", v.Name, err)
+		}
+		plan = append(plan, MatchedEntry(entry, "vxc", live.UID, VXCChanges(v, live, tags, uids)))
+	}
+
+	// State entries for resources no longer in the config. Apply only acts on
+	// config entries, so these keep running until removed by hand.
+	declared := DeclaredNames(cfg)
+	for _, r := range state.Resources {
+		if declared[r.Type][r.Name] {
+			continue
+		}
+		plan = append(plan, PlanEntry{
+			Type:   DisplayType(r.Type),
+			Name:   r.Name,
+			Action: PlanOrphaned,
+			UID:    r.UID,
+			Detail: "in apply state but no longer in config; apply will not delete it",
+		})
+	}
+	return plan, nil
+}
+
+// matchLive returns the active resource recorded in state for resType/name, or
+// nil (the zero value) when there is none.
+func matchLive[T any](state *State, resType, name string, live map[string]T) T {
+	var zero T
+	uid, ok := state.Lookup(resType, name)
+	if !ok {
+		return zero
+	}
+	r, ok := live[uid]
+	if !ok {
+		return zero
+	}
+	return r
+}
+
+// untrackedDetail explains why an entry will be created. Apply never adopts a
+// resource by name, so a same-named resource that is not in the state is called
+// out: apply would order a second one alongside it.
+func untrackedDetail[T any](resType, name string, state *State, live map[string]T, nameOf func(T) string) string {
+	if uid, ok := state.Lookup(resType, name); ok {
+		return fmt.Sprintf("recorded UID %s is no longer active", uid)
+	}
+	for _, uid := range slices.Sorted(maps.Keys(live)) {
+		if nameOf(live[uid]) == name {
+			return fmt.Sprintf("a %s with this name already exists (%s) but is not in the apply state", DisplayType(resType), uid)
+		}
+	}
+	return ""
+}
+
+func portName(p *megaport.Port) string { return p.Name }
+
+func mcrName(m *megaport.MCR) string { return m.Name }
+
+func mveName(m *megaport.MVE) string { return m.Name }
+
+func vxcName(v *megaport.VXC) string { return v.Name }
+
+func NATGatewayName(g *megaport.NATGateway) string { return g.ProductName }
+
+func IXName(x *megaport.IX) string { return x.ProductName }
+
+func serviceKeyName(k *megaport.ServiceKey) string { return k.Description }
+
+// MatchedEntry fills in the UID, action and change summary for a matched resource.
+func MatchedEntry(entry PlanEntry, resType, uid string, changes []output.FieldChange) PlanEntry {
+	entry.UID = uid
+	entry.Changes = changes
+	if len(changes) == 0 {
+		entry.Action = PlanNoOp
+		return entry
+	}
+	entry.Action = PlanUpdate
+	replaced := ReplacedFields(resType, changes)
+	if len(replaced) > 0 {
+		entry.Action = PlanReplace
+	}
+	labels := make([]string, 0, len(changes))
+	for _, c := range changes {
+		if slices.Contains(replaced, c.Label) {
+			labels = append(labels, c.Label+" (requires replacement)")
+		} else {
+			labels = append(labels, c.Label)
+		}
+	}
+	entry.Detail = strings.Join(labels, ", ")
+	return entry
+}
+
+// LiveTags fetches a matched resource's tags, but only when the config manages
+// them: an omitted resource_tags block leaves the live tags alone.
+func LiveTags(ctx context.Context, declared map[string]string, uid string, list func(context.Context, string) (map[string]string, error)) (map[string]string, error) {
+	if declared == nil {
+		return nil, nil
+	}
+	var tags map[string]string
+	err := utils.WithIdempotentRetry(ctx, func(ctx context.Context) error {
+		var e error
+		tags, e = list(ctx, uid)
+		return e
+	})
+	return tags, err
+}
+
+// changeSet accumulates field differences. Labels are the config file keys so
+// a change maps straight back to the line to edit.
+type changeSet []output.FieldChange
+
+func (c *changeSet) add(label, live, declared string) {
+	if live != declared {
+		*c = append(*c, output.FieldChange{Label: label, OldValue: live, NewValue: declared})
+	}
+}
+
+func (c *changeSet) addInt(label string, live, declared int) {
+	c.add(label, strconv.Itoa(live), strconv.Itoa(declared))
+}
+
+// addOptional compares an optional string; an empty declared value means the
+// config does not manage the field.
+func (c *changeSet) addOptional(label, live, declared string) {
+	if declared != "" {
+		c.add(label, output.FormatOptionalString(live), output.FormatOptionalString(declared))
+	}
+}
+
+// addTags compares resource tags; a nil declared map means the config does not
+// manage them.
+func (c *changeSet) addTags(live, declared map[string]string) {
+	if declared != nil && !maps.Equal(live, declared) {
+		*c = append(*c, output.FieldChange{Label: "resource_tags", OldValue: formatTags(live), NewValue: formatTags(declared)})
+	}
+}
+
+// addLocked compares the lock state when the config declares one.
+func (c *changeSet) addLocked(live bool, declared *bool) {
+	if declared != nil {
+		c.add("locked", strconv.FormatBool(live), strconv.FormatBool(*declared))
+	}
+}
+
+func PortChanges(p PortConfig, live *megaport.Port, tags map[string]string) []output.FieldChange {
+	var c changeSet
+	c.add("name", live.Name, p.Name)
+	c.addInt("location_id", live.LocationID, p.LocationID)
+	c.addInt("speed", live.PortSpeed, p.Speed)
+	c.addInt("lag_count", live.LagCount, p.LagCount)
+	c.addInt("term", live.ContractTermMonths, p.Term)
+	c.add("marketplace_visibility", strconv.FormatBool(live.MarketplaceVisibility), strconv.FormatBool(p.MarketplaceVisibility))
+	c.addOptional("diversity_zone", live.DiversityZone, p.DiversityZone)
+	c.addOptional("cost_centre", live.CostCentre, p.CostCentre)
+	c.addLocked(live.Locked, p.Locked)
+	c.addTags(tags, p.ResourceTags)
+	return c
+}
+
+func MCRChanges(m MCRConfig, live *megaport.MCR, tags map[string]string) []output.FieldChange {
+	var c changeSet
+	c.add("name", live.Name, m.Name)
+	c.addInt("location_id", live.LocationID, m.LocationID)
+	c.addInt("speed", live.PortSpeed, m.Speed)
+	c.addInt("term", live.ContractTermMonths, m.Term)
+	// An ASN of 0 lets the API assign its default, so there is nothing to compare.
+	if m.ASN != 0 {
+		c.addInt("asn", live.Resources.VirtualRouter.ASN, m.ASN)
+	}
+	c.addOptional("diversity_zone", live.DiversityZone, m.DiversityZone)
+	c.addOptional("cost_centre", live.CostCentre, m.CostCentre)
+	c.addLocked(live.Locked, m.Locked)
+	c.addTags(tags, m.ResourceTags)
+	return c
+}
+
+func MVEChanges(mv MVEConfig, live *megaport.MVE, tags map[string]string) []output.FieldChange {
+	var c changeSet
+	c.add("name", live.Name, mv.Name)
+	c.addInt("location_id", live.LocationID, mv.LocationID)
+	c.addInt("term", live.ContractTermMonths, mv.Term)
+	if vendor, ok := mv.VendorConfig["vendor"].(string); ok && MVEVendorKey(vendor) != MVEVendorKey(live.Vendor) {
+		c.add("vendor_config.vendor", live.Vendor, vendor)
+	}
+	c.addOptional("diversity_zone", live.DiversityZone, mv.DiversityZone)
+	c.addOptional("cost_centre", live.CostCentre, mv.CostCentre)
+	c.addLocked(live.Locked, mv.Locked)
+	c.addTags(tags, mv.ResourceTags)
+	return c
+}
+
+// MVEVendorKey normalizes an MVE vendor name to the form vendor_config uses
+// ("Palo Alto" → "palo_alto"), so the API's display name matches its config key.
+func MVEVendorKey(vendor string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(vendor)), " ", "_")
+}
+
+func NATGatewayChanges(n NATGatewayConfig, live *megaport.NATGateway, tags map[string]string) []output.FieldChange {
+	var c changeSet
+	c.add("name", live.ProductName, n.Name)
+	c.addInt("location_id", live.LocationID, n.LocationID)
+	c.addInt("speed", live.Speed, n.Speed)
+	c.addInt("term", live.Term, n.Term)
+	// A session count or ASN of 0 takes the API's default.
+	if n.SessionCount != 0 {
+		c.addInt("session_count", live.Config.SessionCount, n.SessionCount)
+	}
+	if n.ASN != 0 {
+		c.addInt("asn", live.Config.ASN, n.ASN)
+	}
+	c.addOptional("diversity_zone", live.Config.DiversityZone, n.DiversityZone)
+	c.add("auto_renew_term", strconv.FormatBool(live.AutoRenewTerm), strconv.FormatBool(n.AutoRenewTerm))
+	c.addOptional("service_level_reference", live.ServiceLevelReference, n.ServiceLevelReference)
+	c.addLocked(live.Locked, n.Locked)
+	c.addTags(tags, n.ResourceTags)
+	return c
+}
+
+// IXChanges compares an IX with its config entry. product_uid and shutdown
+// are not compared: the API does not report an IX's port or shutdown state.
+func IXChanges(x IXConfig, live *megaport.IX) []output.FieldChange {
+	var c changeSet
+	c.add("name", live.ProductName, x.Name)
+	c.add("network_service_type", live.NetworkServiceType, x.NetworkServiceType)
+	c.addInt("asn", live.ASN, x.ASN)
+	c.add("mac_address", strings.ToLower(live.MACAddress), strings.ToLower(x.MACAddress))
+	c.addInt("rate_limit", live.RateLimit, x.RateLimit)
+	if x.VLAN != 0 {
+		c.addInt("vlan", live.VLAN, x.VLAN)
+	}
+	return c
+}
+
+// ServiceKeyChanges compares a service key with its config entry. The name is
+// the key's description, which cannot be changed once the key exists.
+func ServiceKeyChanges(k ServiceKeyConfig, live *megaport.ServiceKey, uids map[string]map[string]string) []output.FieldChange {
+	var c changeSet
+	// A key without a description is known by the key itself.
+	if live.Description != "" {
+		c.add("name", live.Description, k.Name)
+	}
+	c.add("product_uid", live.ProductUID, planEndpointUID(k.ProductUID, uids))
+	c.addInt("max_speed", live.MaxSpeed, k.MaxSpeed)
+	c.add("single_use", strconv.FormatBool(live.SingleUse), strconv.FormatBool(k.SingleUse))
+	if k.VLAN != 0 {
+		c.addInt("vlan", live.VLAN, k.VLAN)
+	}
+	c.add("pre_approved", strconv.FormatBool(live.PreApproved), strconv.FormatBool(k.PreApproved))
+	c.add("active", strconv.FormatBool(live.Active), strconv.FormatBool(k.Active))
+	return c
+}
+
+func VXCChanges(v VXCConfig, live *megaport.VXC, tags map[string]string, uids map[string]map[string]string) []output.FieldChange {
+	var c changeSet
+	c.add("name", live.Name, v.Name)
+	c.addInt("rate_limit", live.RateLimit, v.RateLimit)
+	c.addInt("term", live.ContractTermMonths, v.Term)
+	c.addEndpoint("a_end", live.AEndConfiguration, v.AEnd, uids)
+	c.addEndpoint("b_end", live.BEndConfiguration, v.BEnd, uids)
+	c.addOptional("cost_centre", live.CostCentre, v.CostCentre)
+	c.addLocked(live.Locked, v.Locked)
+	c.addTags(tags, v.ResourceTags)
+	return c
+}
+
+// addEndpoint compares one end of a VXC. partner_config is not compared: the
+// API reports it in a different shape and without its secrets.
+func (c *changeSet) addEndpoint(end string, live megaport.VXCEndConfiguration, e VXCEndpointConfig, uids map[string]map[string]string) {
+	// An end without product_uid is ordered onto the port its partner key
+	// looks up, so any live port satisfies it.
+	if e.ProductUID != "" {
+		c.add(end+".product_uid", live.UID, planEndpointUID(e.ProductUID, uids))
+	}
+	// A VLAN of 0 asks the API to allocate one, so any live VLAN satisfies it.
+	if e.VLAN != 0 {
+		c.addInt(end+".vlan", live.VLAN, e.VLAN)
+	}
+	if e.InnerVLAN != 0 {
+		c.addInt(end+".inner_vlan", live.InnerVLAN, e.InnerVLAN)
+	}
+	if e.VNICIndex != nil {
+		c.addInt(end+".vnic_index", live.NetworkInterfaceIndex, *e.VNICIndex)
+	}
+}
+
+// planEndpointUID resolves a VXC endpoint against matched resources. A template
+// naming a resource the apply has yet to create has no UID to compare.
+func planEndpointUID(s string, uids map[string]map[string]string) string {
+	uid, err := ResolveTemplates(s, uids)
+	if err != nil {
+		return KnownAfterApply
+	}
+	return uid
+}
+
+// formatTags renders tags as sorted key=value pairs, or "(none)".
+func formatTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return output.FormatOptionalString("")
+	}
+	pairs := make([]string, 0, len(tags))
+	for _, k := range slices.Sorted(maps.Keys(tags)) {
+		pairs = append(pairs, k+"="+tags[k])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// DeclaredNames returns the config entry names per template type.
+func DeclaredNames(cfg *Config) map[string]map[string]bool {
+	declared := NewTypeMap[bool]()
+	for _, p := range cfg.Ports {
+		declared["port"][p.Name] = true
+	}
+	for _, m := range cfg.MCRs {
+		declared["mcr"][m.Name] = true
+	}
+	for _, mv := range cfg.MVEs {
+		declared["mve"][mv.Name] = true
+	}
+	for _, n := range cfg.NATGateways {
+		declared["nat_gateway"][n.Name] = true
+	}
+	for _, x := range cfg.IXs {
+		declared["ix"][x.Name] = true
+	}
+	for _, k := range cfg.ServiceKeys {
+		declared["service_key"][k.Name] = true
+	}
+	for _, v := range cfg.VXCs {
+		declared["vxc"][v.Name] = true
+	}
+	return declared
+}
+
+// ResourceTypes lists the template type keys in provisioning order: IXs and
+// service keys attach to ports, and VXCs can attach to any of the others.
+var ResourceTypes = []string{"port", "mcr", "mve", "nat_gateway", "ix", "service_key", "vxc"}
+
+// NewTypeMap returns a map with an empty entry per template type, the shape of
+// the template UID map.
+func NewTypeMap[T any]() map[string]map[string]T {
+	m := make(map[string]map[string]T, len(ResourceTypes))
+	for _, t := range ResourceTypes {
+		m[t] = map[string]T{}
+	}
+	return m
+}
+
+// DisplayType maps a template type key to the resource type shown in results.
+func DisplayType(resType string) string {
+	switch resType {
+	case "port":
+		return "Port"
+	case "mcr", "mve", "vxc", "ix":
+		return strings.ToUpper(resType)
+	case "nat_gateway":
+		return "NAT Gateway"
+	case "service_key":
+		return "Service Key"
+	default:
+		return resType
+	}
+}
+
+// TemplateType maps a displayed resource type back to its template type key.
+func TemplateType(display string) string {
+	for _, t := range ResourceTypes {
+		if DisplayType(t) == display {
+			return t
+		}
+	}
+	return strings.ToLower(display)
+}
+
+// LiveResource is a resource recorded in the apply state as returned by the
+// API. Exactly one of the resource fields is set. tags is only populated when
+// the config entry declares resource_tags.
+type LiveResource struct {
+	UID        string
+	Port       *megaport.Port
+	MCR        *megaport.MCR
+	MVE        *megaport.MVE
+	NATGateway *megaport.NATGateway
+	IX         *megaport.IX
+	ServiceKey *megaport.ServiceKey
+	VXC        *megaport.VXC
+	Tags       map[string]string
+}
+
+// Status returns the resource's provisioning status.
+func (r *LiveResource) Status() string {
+	switch {
+	case r.Port != nil:
+		return r.Port.ProvisioningStatus
+	case r.MCR != nil:
+		return r.MCR.ProvisioningStatus
+	case r.MVE != nil:
+		return r.MVE.ProvisioningStatus
+	case r.NATGateway != nil:
+		return r.NATGateway.ProvisioningStatus
+	case r.IX != nil:
+		return r.IX.ProvisioningStatus
+	case r.ServiceKey != nil:
+		return serviceKeyStatus(r.ServiceKey)
+	case r.VXC != nil:
+		return r.VXC.ProvisioningStatus
+	default:
+		return ""
+	}
+}
+
+// serviceKeyStatus stands in for a provisioning status, which service keys
+// lack. An expired key can no longer be used, so it counts as decommissioned.
+func serviceKeyStatus(sk *megaport.ServiceKey) string {
+	if sk.Expired {
+		return megaport.STATUS_DECOMMISSIONED
+	}
+	return megaport.SERVICE_LIVE
+}
+
+// GetResource fetches the resource uid, where resType is a template key (port,
+// mcr, nat_gateway, ...).
+func GetResource(ctx context.Context, client *megaport.Client, resType, uid string) (*LiveResource, error) {
+	r := &LiveResource{UID: uid}
+	var err error
+	switch resType {
+	case "port":
+		r.Port, err = client.PortService.GetPort(ctx, uid)
+		if err == nil && r.Port == nil {
+			err = fmt.Errorf("empty response from API")
+		}
+	case "mcr":
+		r.MCR, err = client.MCRService.GetMCR(ctx, uid)
+		if err == nil && r.MCR == nil {
+			err = fmt.Errorf("empty response from API")
+		}
+	case "mve":
+		r.MVE, err = client.MVEService.GetMVE(ctx, uid)
+		if err == nil && r.MVE == nil {
+			err = fmt.Errorf("empty response from API")
+		}
+	case "nat_gateway":
+		r.NATGateway, err = client.NATGatewayService.GetNATGateway(ctx, uid)
+		if err == nil && r.NATGateway == nil {
+			err = fmt.Errorf("empty response from API")
+		}
+	case "ix":
+		r.IX, err = client.IXService.GetIX(ctx, uid)
+		if err == nil && r.IX == nil {
+			err = fmt.Errorf("empty response from API")
+		}
+	case "service_key":
+		r.ServiceKey, err = client.ServiceKeyService.GetServiceKey(ctx, uid)
+		if err == nil && r.ServiceKey == nil {
+			err = fmt.Errorf("empty response from API")
+		}
+	case "vxc":
+		r.VXC, err = client.VXCService.GetVXC(ctx, uid)
+		if err == nil && r.VXC == nil {
+			err = fmt.Errorf("empty response from API")
+		}
+	default:
+		err = fmt.Errorf("unknown resource type %q", resType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TagLister returns the resource tag list call for resType. IXs and service
+// keys have no resource tags.
+func TagLister(client *megaport.Client, resType string) func(context.Context, string) (map[string]string, error) {
+	switch resType {
+	case "port":
+		return client.PortService.ListPortResourceTags
+	case "mcr":
+		return client.MCRService.ListMCRResourceTags
+	case "mve":
+		return client.MVEService.ListMVEResourceTags
+	case "nat_gateway":
+		// NAT gateways carry their tags rather than having a tags endpoint.
+		return func(ctx context.Context, uid string) (map[string]string, error) {
+			gw, err := client.NATGatewayService.GetNATGateway(ctx, uid)
+			if err != nil {
+				return nil, err
+			}
+			if gw == nil {
+				return nil, fmt.Errorf("empty response from API")
+			}
+			return ResourceTagMap(gw.ResourceTags), nil
+		}
+	default:
+		return client.VXCService.ListVXCResourceTags
+	}
+}
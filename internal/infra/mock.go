@@ -0,0 +1,850 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/commands/config"
+	megaport "github.com/megaport/megaportgo"
+)
+
+// MockPortService implements megaport.PortService for testing.
+type MockPortService struct {
+	BuyPortResult        *megaport.BuyPortResponse
+	BuyPortErr           error
+	BuyPortNilResp       bool
+	ValidatePortOrderErr error
+	CapturedPortRequest  *megaport.BuyPortRequest
+	DeletePortErr        error
+	DeletePortCalledWith []string
+	GetPortStatus        string        // provisioning status returned by GetPort (default ready)
+	GetPortStatusFunc    func() string // dynamic status for GetPort; takes precedence over GetPortStatus
+	GetPortErr           error         // error returned by GetPort (simulates a provision-wait failure)
+	GetPortReturnNil     bool          // GetPort returns (nil, nil) (simulates an empty API response)
+	ListPortsResult      []*megaport.Port
+	ListPortsErr         error
+	ResourceTags         map[string]map[string]string // resource tags returned by ListPortResourceTags, keyed by port UID
+	GetPortResult        *megaport.Port               // port returned by GetPort, with UID and status filled in (default a bare port)
+	ModifyPortErr        error
+	CapturedModifyPort   *megaport.ModifyPortRequest
+	CapturedTagUpdate    map[string]string // tags passed to UpdatePortResourceTags
+}
+
+func (m *MockPortService) BuyPort(ctx context.Context, req *megaport.BuyPortRequest) (*megaport.BuyPortResponse, error) {
+	m.CapturedPortRequest = req
+	if m.BuyPortNilResp {
+		return nil, nil
+	}
+	if m.BuyPortErr != nil {
+		return nil, m.BuyPortErr
+	}
+	if m.BuyPortResult != nil {
+		return m.BuyPortResult, nil
+	}
+	return &megaport.BuyPortResponse{TechnicalServiceUIDs: []string{"port-uid-mock"}}, nil
+}
+
+func (m *MockPortService) ValidatePortOrder(ctx context.Context, req *megaport.BuyPortRequest) error {
+	return m.ValidatePortOrderErr
+}
+
+func (m *MockPortService) ListPorts(ctx context.Context) ([]*megaport.Port, error) {
+	return m.ListPortsResult, m.ListPortsErr
+}
+
+func (m *MockPortService) GetPort(ctx context.Context, portId string) (*megaport.Port, error) {
+	if m.GetPortErr != nil {
+		return nil, m.GetPortErr
+	}
+	if m.GetPortReturnNil {
+		return nil, nil
+	}
+	status := m.GetPortStatus
+	if m.GetPortStatusFunc != nil {
+		status = m.GetPortStatusFunc()
+	} else if status == "" {
+		status = megaport.SERVICE_LIVE
+	}
+	port := &megaport.Port{}
+	if m.GetPortResult != nil {
+		cp := *m.GetPortResult
+		port = &cp
+	}
+	port.UID, port.ProvisioningStatus = portId, status
+	return port, nil
+}
+
+func (m *MockPortService) ModifyPort(ctx context.Context, req *megaport.ModifyPortRequest) (*megaport.ModifyPortResponse, error) {
+	m.CapturedModifyPort = req
+	if m.ModifyPortErr != nil {
+		return nil, m.ModifyPortErr
+	}
+	return &megaport.ModifyPortResponse{IsUpdated: true}, nil
+}
+
+func (m *MockPortService) DeletePort(ctx context.Context, req *megaport.DeletePortRequest) (*megaport.DeletePortResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.DeletePortCalledWith = append(m.DeletePortCalledWith, req.PortID)
+	if m.DeletePortErr != nil {
+		return nil, m.DeletePortErr
+	}
+	return &megaport.DeletePortResponse{}, nil
+}
+
+func (m *MockPortService) RestorePort(ctx context.Context, portId string) (*megaport.RestorePortResponse, error) {
+	return nil, fmt.Errorf("mock: RestorePort not configured")
+}
+
+func (m *MockPortService) LockPort(ctx context.Context, portId string) (*megaport.LockPortResponse, error) {
+	return nil, fmt.Errorf("mock: LockPort not configured")
+}
+
+func (m *MockPortService) UnlockPort(ctx context.Context, portId string) (*megaport.UnlockPortResponse, error) {
+	return nil, fmt.Errorf("mock: UnlockPort not configured")
+}
+
+func (m *MockPortService) CheckPortVLANAvailability(ctx context.Context, portId string, vlan int) (bool, error) {
+	return true, nil
+}
+
+func (m *MockPortService) ListPortResourceTags(ctx context.Context, portID string) (map[string]string, error) {
+	return m.ResourceTags[portID], nil
+}
+
+func (m *MockPortService) UpdatePortResourceTags(ctx context.Context, portID string, tags map[string]string) error {
+	m.CapturedTagUpdate = tags
+	return nil
+}
+
+// MockMCRService implements megaport.MCRService for testing.
+type MockMCRService struct {
+	BuyMCRResult               *megaport.BuyMCRResponse
+	BuyMCRErr                  error
+	BuyMCRNilResp              bool
+	ValidateMCROrderErr        error
+	CapturedMCRRequest         *megaport.BuyMCRRequest
+	CapturedValidateMCRRequest *megaport.BuyMCRRequest
+	WaitForMCRReadyDelay       time.Duration
+	WaitForMCRReadyErr         error
+	DeleteMCRErr               error
+	DeleteMCRCalledWith        []string
+	GetMCRStatus               string        // provisioning status returned by GetMCR (default ready)
+	GetMCRStatusFunc           func() string // dynamic status for GetMCR; takes precedence over GetMCRStatus
+	GetMCRErr                  error         // error returned by GetMCR (simulates a provision-wait failure)
+	GetMCRErrFunc              func() error  // dynamic error for GetMCR; takes precedence over GetMCRErr
+	GetMCRReturnNil            bool          // GetMCR returns (nil, nil) (simulates an empty API response)
+	ListMCRsResult             []*megaport.MCR
+	ListMCRsErr                error
+	ResourceTags               map[string]map[string]string // resource tags returned by ListMCRResourceTags, keyed by MCR UID
+	GetMCRResult               *megaport.MCR                // MCR returned by GetMCR, with UID and status filled in (default a bare MCR)
+	ModifyMCRErr               error
+	CapturedModifyMCR          *megaport.ModifyMCRRequest
+	CapturedTagUpdate          map[string]string                          // tags passed to UpdateMCRResourceTags
+	PrefixFilterLists          map[string][]*megaport.MCRPrefixFilterList // prefix filter lists returned by ListMCRPrefixFilterLists and GetMCRPrefixFilterList, keyed by MCR UID
+	CapturedPrefixFilterLists  []*megaport.CreateMCRPrefixFilterListRequest
+	CreatePrefixFilterListErr  error
+}
+
+func (m *MockMCRService) BuyMCR(ctx context.Context, req *megaport.BuyMCRRequest) (*megaport.BuyMCRResponse, error) {
+	m.CapturedMCRRequest = req
+	if m.BuyMCRNilResp {
+		return nil, nil
+	}
+	if m.BuyMCRErr != nil {
+		return nil, m.BuyMCRErr
+	}
+	if m.BuyMCRResult != nil {
+		return m.BuyMCRResult, nil
+	}
+	return &megaport.BuyMCRResponse{TechnicalServiceUID: "mcr-uid-mock"}, nil
+}
+
+func (m *MockMCRService) ValidateMCROrder(ctx context.Context, req *megaport.BuyMCRRequest) error {
+	m.CapturedValidateMCRRequest = req
+	return m.ValidateMCROrderErr
+}
+
+func (m *MockMCRService) ListMCRs(ctx context.Context, req *megaport.ListMCRsRequest) ([]*megaport.MCR, error) {
+	return m.ListMCRsResult, m.ListMCRsErr
+}
+
+func (m *MockMCRService) GetMCR(ctx context.Context, mcrId string) (*megaport.MCR, error) {
+	if m.GetMCRErrFunc != nil {
+		if err := m.GetMCRErrFunc(); err != nil {
+			return nil, err
+		}
+	} else if m.GetMCRErr != nil {
+		return nil, m.GetMCRErr
+	}
+	if m.GetMCRReturnNil {
+		return nil, nil
+	}
+	status := m.GetMCRStatus
+	if m.GetMCRStatusFunc != nil {
+		status = m.GetMCRStatusFunc()
+	} else if status == "" {
+		status = megaport.SERVICE_LIVE
+	}
+	mcr := &megaport.MCR{}
+	if m.GetMCRResult != nil {
+		cp := *m.GetMCRResult
+		mcr = &cp
+	}
+	mcr.UID, mcr.ProvisioningStatus = mcrId, status
+	return mcr, nil
+}
+
+func (m *MockMCRService) CreatePrefixFilterList(ctx context.Context, req *megaport.CreateMCRPrefixFilterListRequest) (*megaport.CreateMCRPrefixFilterListResponse, error) {
+	m.CapturedPrefixFilterLists = append(m.CapturedPrefixFilterLists, req)
+	if m.CreatePrefixFilterListErr != nil {
+		return nil, m.CreatePrefixFilterListErr
+	}
+	return &megaport.CreateMCRPrefixFilterListResponse{IsCreated: true, PrefixFilterListID: len(m.CapturedPrefixFilterLists)}, nil
+}
+
+func (m *MockMCRService) ListMCRPrefixFilterLists(ctx context.Context, mcrId string) ([]*megaport.PrefixFilterList, error) {
+	var lists []*megaport.PrefixFilterList
+	for _, l := range m.PrefixFilterLists[mcrId] {
+		lists = append(lists, &megaport.PrefixFilterList{Id: l.ID, Description: l.Description, AddressFamily: l.AddressFamily})
+	}
+	return lists, nil
+}
+
+func (m *MockMCRService) GetMCRPrefixFilterList(ctx context.Context, mcrID string, id int) (*megaport.MCRPrefixFilterList, error) {
+	for _, l := range m.PrefixFilterLists[mcrID] {
+		if l.ID == id {
+			return l, nil
+		}
+	}
+	return nil, fmt.Errorf("mock: prefix filter list %d not found on %s", id, mcrID)
+}
+
+func (m *MockMCRService) ModifyMCRPrefixFilterList(ctx context.Context, mcrID string, id int, list *megaport.MCRPrefixFilterList) (*megaport.ModifyMCRPrefixFilterListResponse, error) {
+	return nil, fmt.Errorf("mock: ModifyMCRPrefixFilterList not configured")
+}
+
+func (m *MockMCRService) DeleteMCRPrefixFilterList(ctx context.Context, mcrID string, id int) (*megaport.DeleteMCRPrefixFilterListResponse, error) {
+	return nil, fmt.Errorf("mock: DeleteMCRPrefixFilterList not configured")
+}
+
+func (m *MockMCRService) ModifyMCR(ctx context.Context, req *megaport.ModifyMCRRequest) (*megaport.ModifyMCRResponse, error) {
+	m.CapturedModifyMCR = req
+	if m.ModifyMCRErr != nil {
+		return nil, m.ModifyMCRErr
+	}
+	return &megaport.ModifyMCRResponse{IsUpdated: true}, nil
+}
+
+func (m *MockMCRService) DeleteMCR(ctx context.Context, req *megaport.DeleteMCRRequest) (*megaport.DeleteMCRResponse, error) {
+	m.DeleteMCRCalledWith = append(m.DeleteMCRCalledWith, req.MCRID)
+	if m.DeleteMCRErr != nil {
+		return nil, m.DeleteMCRErr
+	}
+	return &megaport.DeleteMCRResponse{}, nil
+}
+
+func (m *MockMCRService) RestoreMCR(ctx context.Context, mcrId string) (*megaport.RestoreMCRResponse, error) {
+	return nil, fmt.Errorf("mock: RestoreMCR not configured")
+}
+
+func (m *MockMCRService) ListMCRResourceTags(ctx context.Context, mcrID string) (map[string]string, error) {
+	return m.ResourceTags[mcrID], nil
+}
+
+func (m *MockMCRService) UpdateMCRResourceTags(ctx context.Context, mcrID string, tags map[string]string) error {
+	m.CapturedTagUpdate = tags
+	return nil
+}
+
+func (m *MockMCRService) GetMCRPrefixFilterLists(ctx context.Context, mcrId string) ([]*megaport.PrefixFilterList, error) {
+	return nil, fmt.Errorf("mock: GetMCRPrefixFilterLists not configured")
+}
+
+func (m *MockMCRService) UpdateMCRWithAddOn(ctx context.Context, mcrID string, req megaport.MCRAddOnRequest) error {
+	return fmt.Errorf("mock: UpdateMCRWithAddOn not configured")
+}
+
+func (m *MockMCRService) UpdateMCRIPsecAddOn(ctx context.Context, mcrID string, addOnUID string, tunnelCount int) error {
+	return fmt.Errorf("mock: UpdateMCRIPsecAddOn not configured")
+}
+
+func (m *MockMCRService) WaitForMCRReady(ctx context.Context, mcrID string, timeout time.Duration) error {
+	if m.WaitForMCRReadyDelay > 0 {
+		t := time.NewTimer(m.WaitForMCRReadyDelay)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	return m.WaitForMCRReadyErr
+}
+
+// MockMVEService implements megaport.MVEService for testing.
+type MockMVEService struct {
+	BuyMVEResult        *megaport.BuyMVEResponse
+	BuyMVEErr           error
+	BuyMVENilResp       bool
+	ValidateMVEOrderErr error
+	DeleteMVEErr        error
+	DeleteMVECalledWith []string
+	GetMVEStatus        string // provisioning status returned by GetMVE (default ready)
+	GetMVEErr           error  // error returned by GetMVE (simulates a provision-wait failure)
+	GetMVEReturnNil     bool   // GetMVE returns (nil, nil) (simulates an empty API response)
+	ListMVEsResult      []*megaport.MVE
+	ListMVEsErr         error
+	ResourceTags        map[string]map[string]string // resource tags returned by ListMVEResourceTags, keyed by MVE UID
+	GetMVEResult        *megaport.MVE                // MVE returned by GetMVE, with UID and status filled in (default a bare MVE)
+	ModifyMVEErr        error
+	CapturedModifyMVE   *megaport.ModifyMVERequest
+	CapturedTagUpdate   map[string]string // tags passed to UpdateMVEResourceTags
+}
+
+func (m *MockMVEService) BuyMVE(ctx context.Context, req *megaport.BuyMVERequest) (*megaport.BuyMVEResponse, error) {
+	if m.BuyMVENilResp {
+		return nil, nil
+	}
+	if m.BuyMVEErr != nil {
+		return nil, m.BuyMVEErr
+	}
+	if m.BuyMVEResult != nil {
+		return m.BuyMVEResult, nil
+	}
+	return &megaport.BuyMVEResponse{TechnicalServiceUID: "mve-uid-mock"}, nil
+}
+
+func (m *MockMVEService) ValidateMVEOrder(ctx context.Context, req *megaport.BuyMVERequest) error {
+	return m.ValidateMVEOrderErr
+}
+
+func (m *MockMVEService) ListMVEs(ctx context.Context, req *megaport.ListMVEsRequest) ([]*megaport.MVE, error) {
+	return m.ListMVEsResult, m.ListMVEsErr
+}
+
+func (m *MockMVEService) GetMVE(ctx context.Context, mveId string) (*megaport.MVE, error) {
+	if m.GetMVEErr != nil {
+		return nil, m.GetMVEErr
+	}
+	if m.GetMVEReturnNil {
+		return nil, nil
+	}
+	status := m.GetMVEStatus
+	if status == "" {
+		status = megaport.SERVICE_LIVE
+	}
+	mve := &megaport.MVE{}
+	if m.GetMVEResult != nil {
+		cp := *m.GetMVEResult
+		mve = &cp
+	}
+	mve.UID, mve.ProvisioningStatus = mveId, status
+	return mve, nil
+}
+
+func (m *MockMVEService) ModifyMVE(ctx context.Context, req *megaport.ModifyMVERequest) (*megaport.ModifyMVEResponse, error) {
+	m.CapturedModifyMVE = req
+	if m.ModifyMVEErr != nil {
+		return nil, m.ModifyMVEErr
+	}
+	return &megaport.ModifyMVEResponse{MVEUpdated: true}, nil
+}
+
+func (m *MockMVEService) DeleteMVE(ctx context.Context, req *megaport.DeleteMVERequest) (*megaport.DeleteMVEResponse, error) {
+	m.DeleteMVECalledWith = append(m.DeleteMVECalledWith, req.MVEID)
+	if m.DeleteMVEErr != nil {
+		return nil, m.DeleteMVEErr
+	}
+	return &megaport.DeleteMVEResponse{}, nil
+}
+
+func (m *MockMVEService) ListMVEImages(ctx context.Context) ([]*megaport.MVEImage, error) {
+	return nil, fmt.Errorf("mock: ListMVEImages not configured")
+}
+
+func (m *MockMVEService) ListAvailableMVESizes(ctx context.Context) ([]*megaport.MVESize, error) {
+	return nil, fmt.Errorf("mock: ListAvailableMVESizes not configured")
+}
+
+func (m *MockMVEService) ListMVEResourceTags(ctx context.Context, mveID string) (map[string]string, error) {
+	return m.ResourceTags[mveID], nil
+}
+
+func (m *MockMVEService) UpdateMVEResourceTags(ctx context.Context, mveID string, tags map[string]string) error {
+	m.CapturedTagUpdate = tags
+	return nil
+}
+
+// MockVXCService implements megaport.VXCService for testing.
+type MockVXCService struct {
+	BuyVXCResult        *megaport.BuyVXCResponse
+	BuyVXCErr           error
+	BuyVXCErrOnCall     int // if > 0, return BuyVXCErr only on this call number (1-based)
+	buyVXCCallCount     int
+	BuyVXCNilResp       bool
+	ValidateVXCOrderErr error
+	CapturedVXCRequest  *megaport.BuyVXCRequest
+	DeleteVXCErr        error
+	DeleteVXCCalledWith []string
+	CapturedDeleteVXC   *megaport.DeleteVXCRequest
+	GetVXCStatus        string // provisioning status returned by GetVXC (default ready)
+	GetVXCErr           error  // error returned by GetVXC (simulates a provision-wait failure)
+	GetVXCReturnNil     bool   // GetVXC returns (nil, nil) (simulates an empty API response)
+	ListVXCsResult      []*megaport.VXC
+	ListVXCsErr         error
+	ResourceTags        map[string]map[string]string // resource tags returned by ListVXCResourceTags, keyed by VXC UID
+	GetVXCResult        *megaport.VXC                // VXC returned by GetVXC, with UID and status filled in (default a bare VXC)
+	UpdateVXCErr        error
+	CapturedUpdateVXC   *megaport.UpdateVXCRequest
+	CapturedTagUpdate   map[string]string // tags passed to UpdateVXCResourceTags
+	PartnerPortUID      string            // UID returned by LookupPartnerPorts (unset: lookups fail)
+	CapturedLookup      *megaport.LookupPartnerPortsRequest
+}
+
+func (m *MockVXCService) BuyVXC(ctx context.Context, req *megaport.BuyVXCRequest) (*megaport.BuyVXCResponse, error) {
+	m.CapturedVXCRequest = req
+	if m.BuyVXCNilResp {
+		return nil, nil
+	}
+	m.buyVXCCallCount++
+	if m.BuyVXCErr != nil && (m.BuyVXCErrOnCall == 0 || m.buyVXCCallCount == m.BuyVXCErrOnCall) {
+		return nil, m.BuyVXCErr
+	}
+	if m.BuyVXCResult != nil {
+		return m.BuyVXCResult, nil
+	}
+	return &megaport.BuyVXCResponse{TechnicalServiceUID: fmt.Sprintf("vxc-uid-mock-%d", m.buyVXCCallCount)}, nil
+}
+
+func (m *MockVXCService) ValidateVXCOrder(ctx context.Context, req *megaport.BuyVXCRequest) error {
+	return m.ValidateVXCOrderErr
+}
+
+func (m *MockVXCService) ListVXCs(ctx context.Context, req *megaport.ListVXCsRequest) ([]*megaport.VXC, error) {
+	return m.ListVXCsResult, m.ListVXCsErr
+}
+
+func (m *MockVXCService) GetVXC(ctx context.Context, id string) (*megaport.VXC, error) {
+	if m.GetVXCErr != nil {
+		return nil, m.GetVXCErr
+	}
+	if m.GetVXCReturnNil {
+		return nil, nil
+	}
+	status := m.GetVXCStatus
+	if status == "" {
+		status = megaport.SERVICE_LIVE
+	}
+	vxc := &megaport.VXC{}
+	if m.GetVXCResult != nil {
+		cp := *m.GetVXCResult
+		vxc = &cp
+	}
+	vxc.UID, vxc.ProvisioningStatus = id, status
+	return vxc, nil
+}
+
+func (m *MockVXCService) DeleteVXC(ctx context.Context, id string, req *megaport.DeleteVXCRequest) error {
+	m.DeleteVXCCalledWith = append(m.DeleteVXCCalledWith, id)
+	m.CapturedDeleteVXC = req
+	return m.DeleteVXCErr
+}
+
+func (m *MockVXCService) UpdateVXC(ctx context.Context, id string, req *megaport.UpdateVXCRequest) (*megaport.VXC, error) {
+	m.CapturedUpdateVXC = req
+	if m.UpdateVXCErr != nil {
+		return nil, m.UpdateVXCErr
+	}
+	return &megaport.VXC{UID: id}, nil
+}
+
+func (m *MockVXCService) LookupPartnerPorts(ctx context.Context, req *megaport.LookupPartnerPortsRequest) (*megaport.LookupPartnerPortsResponse, error) {
+	m.CapturedLookup = req
+	if m.PartnerPortUID == "" {
+		return nil, fmt.Errorf("mock: LookupPartnerPorts not configured")
+	}
+	return &megaport.LookupPartnerPortsResponse{ProductUID: m.PartnerPortUID}, nil
+}
+
+func (m *MockVXCService) ListPartnerPorts(ctx context.Context, req *megaport.ListPartnerPortsRequest) (*megaport.ListPartnerPortsResponse, error) {
+	return nil, fmt.Errorf("mock: ListPartnerPorts not configured")
+}
+
+func (m *MockVXCService) ListVXCResourceTags(ctx context.Context, vxcID string) (map[string]string, error) {
+	return m.ResourceTags[vxcID], nil
+}
+
+func (m *MockVXCService) UpdateVXCResourceTags(ctx context.Context, vxcID string, tags map[string]string) error {
+	m.CapturedTagUpdate = tags
+	return nil
+}
+
+// MockNATGatewayService implements megaport.NATGatewayService for testing.
+type MockNATGatewayService struct {
+	CreateNATGatewayErr        error
+	CapturedCreateNATGateway   *megaport.CreateNATGatewayRequest
+	ValidateNATGatewayOrderErr error
+	BuyNATGatewayErr           error
+	BoughtNATGateways          []string // UIDs passed to BuyNATGateway
+	GetNATGatewayStatus        string   // provisioning status returned by GetNATGateway (default ready)
+	Designs                    []string // UIDs GetNATGateway reports as designs until they are bought
+	GetNATGatewayResult        *megaport.NATGateway
+	ListNATGatewaysResult      []*megaport.NATGateway
+	ListNATGatewaysErr         error
+	UpdateNATGatewayErr        error
+	CapturedUpdateNATGateway   *megaport.UpdateNATGatewayRequest
+	DeleteNATGatewayErr        error
+	DeleteNATGatewayCalledWith []string
+}
+
+func (m *MockNATGatewayService) CreateNATGateway(ctx context.Context, req *megaport.CreateNATGatewayRequest) (*megaport.NATGateway, error) {
+	m.CapturedCreateNATGateway = req
+	if m.CreateNATGatewayErr != nil {
+		return nil, m.CreateNATGatewayErr
+	}
+	return &megaport.NATGateway{ProductUID: "nat-uid-mock", ProductName: req.ProductName, ProvisioningStatus: megaport.STATUS_DESIGN}, nil
+}
+
+func (m *MockNATGatewayService) ValidateNATGatewayOrder(ctx context.Context, productUID string) (*megaport.NATGatewayValidateResult, error) {
+	if m.ValidateNATGatewayOrderErr != nil {
+		return nil, m.ValidateNATGatewayOrderErr
+	}
+	return &megaport.NATGatewayValidateResult{ProductUID: productUID}, nil
+}
+
+func (m *MockNATGatewayService) BuyNATGateway(ctx context.Context, productUID string) (*megaport.NATGatewayBuyResult, error) {
+	m.BoughtNATGateways = append(m.BoughtNATGateways, productUID)
+	if m.BuyNATGatewayErr != nil {
+		return nil, m.BuyNATGatewayErr
+	}
+	return &megaport.NATGatewayBuyResult{ProductUID: productUID}, nil
+}
+
+func (m *MockNATGatewayService) GetNATGateway(ctx context.Context, productUID string) (*megaport.NATGateway, error) {
+	status := m.GetNATGatewayStatus
+	if status == "" {
+		status = megaport.SERVICE_LIVE
+	}
+	if slices.Contains(m.Designs, productUID) && !slices.Contains(m.BoughtNATGateways, productUID) {
+		status = megaport.STATUS_DESIGN
+	}
+	gw := &megaport.NATGateway{}
+	if m.GetNATGatewayResult != nil {
+		cp := *m.GetNATGatewayResult
+		gw = &cp
+	}
+	gw.ProductUID, gw.ProvisioningStatus = productUID, status
+	return gw, nil
+}
+
+func (m *MockNATGatewayService) ListNATGateways(ctx context.Context) ([]*megaport.NATGateway, error) {
+	return m.ListNATGatewaysResult, m.ListNATGatewaysErr
+}
+
+func (m *MockNATGatewayService) UpdateNATGateway(ctx context.Context, req *megaport.UpdateNATGatewayRequest) (*megaport.NATGateway, error) {
+	m.CapturedUpdateNATGateway = req
+	if m.UpdateNATGatewayErr != nil {
+		return nil, m.UpdateNATGatewayErr
+	}
+	return &megaport.NATGateway{ProductUID: req.ProductUID}, nil
+}
+
+func (m *MockNATGatewayService) DeleteNATGateway(ctx context.Context, productUID string) error {
+	m.DeleteNATGatewayCalledWith = append(m.DeleteNATGatewayCalledWith, productUID)
+	return m.DeleteNATGatewayErr
+}
+
+func (m *MockNATGatewayService) ListNATGatewaySessions(ctx context.Context) ([]*megaport.NATGatewaySession, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewaySessions not configured")
+}
+
+func (m *MockNATGatewayService) GetNATGatewayTelemetry(ctx context.Context, req *megaport.GetNATGatewayTelemetryRequest) (*megaport.ServiceTelemetryResponse, error) {
+	return nil, fmt.Errorf("mock: GetNATGatewayTelemetry not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayPacketFilters(ctx context.Context, productUID string) ([]*megaport.NATGatewayPacketFilterSummary, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayPacketFilters not configured")
+}
+
+func (m *MockNATGatewayService) CreateNATGatewayPacketFilter(ctx context.Context, productUID string, req *megaport.NATGatewayPacketFilterRequest) (*megaport.NATGatewayPacketFilter, error) {
+	return nil, fmt.Errorf("mock: CreateNATGatewayPacketFilter not configured")
+}
+
+func (m *MockNATGatewayService) GetNATGatewayPacketFilter(ctx context.Context, productUID string, packetFilterID int) (*megaport.NATGatewayPacketFilter, error) {
+	return nil, fmt.Errorf("mock: GetNATGatewayPacketFilter not configured")
+}
+
+func (m *MockNATGatewayService) UpdateNATGatewayPacketFilter(ctx context.Context, productUID string, packetFilterID int, req *megaport.NATGatewayPacketFilterRequest) (*megaport.NATGatewayPacketFilter, error) {
+	return nil, fmt.Errorf("mock: UpdateNATGatewayPacketFilter not configured")
+}
+
+func (m *MockNATGatewayService) DeleteNATGatewayPacketFilter(ctx context.Context, productUID string, packetFilterID int) error {
+	return fmt.Errorf("mock: DeleteNATGatewayPacketFilter not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayPrefixLists(ctx context.Context, productUID string) ([]*megaport.NATGatewayPrefixListSummary, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayPrefixLists not configured")
+}
+
+func (m *MockNATGatewayService) CreateNATGatewayPrefixList(ctx context.Context, productUID string, req *megaport.NATGatewayPrefixList) (*megaport.NATGatewayPrefixList, error) {
+	return nil, fmt.Errorf("mock: CreateNATGatewayPrefixList not configured")
+}
+
+func (m *MockNATGatewayService) GetNATGatewayPrefixList(ctx context.Context, productUID string, prefixListID int) (*megaport.NATGatewayPrefixList, error) {
+	return nil, fmt.Errorf("mock: GetNATGatewayPrefixList not configured")
+}
+
+func (m *MockNATGatewayService) UpdateNATGatewayPrefixList(ctx context.Context, productUID string, prefixListID int, req *megaport.NATGatewayPrefixList) (*megaport.NATGatewayPrefixList, error) {
+	return nil, fmt.Errorf("mock: UpdateNATGatewayPrefixList not configured")
+}
+
+func (m *MockNATGatewayService) DeleteNATGatewayPrefixList(ctx context.Context, productUID string, prefixListID int) error {
+	return fmt.Errorf("mock: DeleteNATGatewayPrefixList not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayIPRoutesAsync(ctx context.Context, productUID, ipAddress string) (string, error) {
+	return "", fmt.Errorf("mock: ListNATGatewayIPRoutesAsync not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayBGPRoutesAsync(ctx context.Context, productUID, ipAddress string) (string, error) {
+	return "", fmt.Errorf("mock: ListNATGatewayBGPRoutesAsync not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayBGPNeighborRoutesAsync(ctx context.Context, req *megaport.NATGatewayBGPNeighborRoutesRequest) (string, error) {
+	return "", fmt.Errorf("mock: ListNATGatewayBGPNeighborRoutesAsync not configured")
+}
+
+func (m *MockNATGatewayService) GetNATGatewayDiagnosticsRoutes(ctx context.Context, productUID, operationID string) ([]*megaport.NATGatewayRoute, error) {
+	return nil, fmt.Errorf("mock: GetNATGatewayDiagnosticsRoutes not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayIPRoutes(ctx context.Context, productUID, ipAddress string) ([]*megaport.NATGatewayIPRoute, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayIPRoutes not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayBGPRoutes(ctx context.Context, productUID, ipAddress string) ([]*megaport.NATGatewayBGPRoute, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayBGPRoutes not configured")
+}
+
+func (m *MockNATGatewayService) ListNATGatewayBGPNeighborRoutes(ctx context.Context, req *megaport.NATGatewayBGPNeighborRoutesRequest) ([]*megaport.NATGatewayBGPRoute, error) {
+	return nil, fmt.Errorf("mock: ListNATGatewayBGPNeighborRoutes not configured")
+}
+
+// MockIXService implements megaport.IXService for testing.
+type MockIXService struct {
+	BuyIXErr           error
+	ValidateIXOrderErr error
+	CapturedIXRequest  *megaport.BuyIXRequest
+	GetIXStatus        string // provisioning status returned by GetIX (default ready)
+	GetIXResult        *megaport.IX
+	ListIXsResult      []*megaport.IX
+	ListIXsErr         error
+	UpdateIXErr        error
+	CapturedUpdateIX   *megaport.UpdateIXRequest
+	DeleteIXErr        error
+	DeleteIXCalledWith []string
+	CapturedDeleteIX   *megaport.DeleteIXRequest
+}
+
+func (m *MockIXService) BuyIX(ctx context.Context, req *megaport.BuyIXRequest) (*megaport.BuyIXResponse, error) {
+	m.CapturedIXRequest = req
+	if m.BuyIXErr != nil {
+		return nil, m.BuyIXErr
+	}
+	return &megaport.BuyIXResponse{TechnicalServiceUID: "ix-uid-mock"}, nil
+}
+
+func (m *MockIXService) ValidateIXOrder(ctx context.Context, req *megaport.BuyIXRequest) error {
+	return m.ValidateIXOrderErr
+}
+
+func (m *MockIXService) GetIX(ctx context.Context, id string) (*megaport.IX, error) {
+	status := m.GetIXStatus
+	if status == "" {
+		status = megaport.SERVICE_LIVE
+	}
+	ix := &megaport.IX{}
+	if m.GetIXResult != nil {
+		cp := *m.GetIXResult
+		ix = &cp
+	}
+	ix.ProductUID, ix.ProvisioningStatus = id, status
+	return ix, nil
+}
+
+func (m *MockIXService) ListIXs(ctx context.Context, req *megaport.ListIXsRequest) ([]*megaport.IX, error) {
+	return m.ListIXsResult, m.ListIXsErr
+}
+
+func (m *MockIXService) UpdateIX(ctx context.Context, id string, req *megaport.UpdateIXRequest) (*megaport.IX, error) {
+	m.CapturedUpdateIX = req
+	if m.UpdateIXErr != nil {
+		return nil, m.UpdateIXErr
+	}
+	return &megaport.IX{ProductUID: id}, nil
+}
+
+func (m *MockIXService) DeleteIX(ctx context.Context, id string, req *megaport.DeleteIXRequest) error {
+	m.DeleteIXCalledWith = append(m.DeleteIXCalledWith, id)
+	m.CapturedDeleteIX = req
+	return m.DeleteIXErr
+}
+
+func (m *MockIXService) ListIXPs(ctx context.Context, req *megaport.ListIXPsRequest) ([]*megaport.IXP, error) {
+	return nil, fmt.Errorf("mock: ListIXPs not configured")
+}
+
+// MockServiceKeyService implements megaport.ServiceKeyService for testing.
+type MockServiceKeyService struct {
+	CreateServiceKeyErr      error
+	CapturedCreateServiceKey *megaport.CreateServiceKeyRequest
+	ListServiceKeysResult    []*megaport.ServiceKey // also searched by GetServiceKey
+	ListServiceKeysErr       error
+	UpdateServiceKeyErr      error
+	CapturedUpdates          []*megaport.UpdateServiceKeyRequest
+}
+
+func (m *MockServiceKeyService) CreateServiceKey(ctx context.Context, req *megaport.CreateServiceKeyRequest) (*megaport.CreateServiceKeyResponse, error) {
+	m.CapturedCreateServiceKey = req
+	if m.CreateServiceKeyErr != nil {
+		return nil, m.CreateServiceKeyErr
+	}
+	return &megaport.CreateServiceKeyResponse{ServiceKeyUID: "service-key-mock"}, nil
+}
+
+func (m *MockServiceKeyService) ListServiceKeys(ctx context.Context, req *megaport.ListServiceKeysRequest) (*megaport.ListServiceKeysResponse, error) {
+	if m.ListServiceKeysErr != nil {
+		return nil, m.ListServiceKeysErr
+	}
+	return &megaport.ListServiceKeysResponse{ServiceKeys: m.ListServiceKeysResult}, nil
+}
+
+func (m *MockServiceKeyService) GetServiceKey(ctx context.Context, keyId string) (*megaport.ServiceKey, error) {
+	for _, k := range m.ListServiceKeysResult {
+		if k.Key == keyId {
+			cp := *k
+			return &cp, nil
+		}
+	}
+	return &megaport.ServiceKey{Key: keyId, Active: true}, nil
+}
+
+func (m *MockServiceKeyService) UpdateServiceKey(ctx context.Context, req *megaport.UpdateServiceKeyRequest) (*megaport.UpdateServiceKeyResponse, error) {
+	m.CapturedUpdates = append(m.CapturedUpdates, req)
+	if m.UpdateServiceKeyErr != nil {
+		return nil, m.UpdateServiceKeyErr
+	}
+	return &megaport.UpdateServiceKeyResponse{IsUpdated: true}, nil
+}
+
+// MockProductService implements megaport.ProductService for lock changes.
+type MockProductService struct {
+	CapturedLockRequests []*megaport.ManageProductLockRequest
+	ManageProductLockErr error
+}
+
+func (m *MockProductService) ExecuteOrder(ctx context.Context, requestBody interface{}) (*[]byte, error) {
+	return nil, fmt.Errorf("mock: ExecuteOrder not configured")
+}
+
+func (m *MockProductService) ListProducts(ctx context.Context) ([]megaport.Product, error) {
+	return nil, nil
+}
+
+func (m *MockProductService) ModifyProduct(ctx context.Context, req *megaport.ModifyProductRequest) (*megaport.ModifyProductResponse, error) {
+	return nil, fmt.Errorf("mock: ModifyProduct not configured")
+}
+
+func (m *MockProductService) DeleteProduct(ctx context.Context, req *megaport.DeleteProductRequest) (*megaport.DeleteProductResponse, error) {
+	return nil, fmt.Errorf("mock: DeleteProduct not configured")
+}
+
+func (m *MockProductService) RestoreProduct(ctx context.Context, productId string) (*megaport.RestoreProductResponse, error) {
+	return nil, fmt.Errorf("mock: RestoreProduct not configured")
+}
+
+func (m *MockProductService) ManageProductLock(ctx context.Context, req *megaport.ManageProductLockRequest) (*megaport.ManageProductLockResponse, error) {
+	m.CapturedLockRequests = append(m.CapturedLockRequests, req)
+	if m.ManageProductLockErr != nil {
+		return nil, m.ManageProductLockErr
+	}
+	return &megaport.ManageProductLockResponse{}, nil
+}
+
+func (m *MockProductService) ValidateProductOrder(ctx context.Context, requestBody interface{}) error {
+	return nil
+}
+
+func (m *MockProductService) ListProductResourceTags(ctx context.Context, productID string) ([]megaport.ResourceTag, error) {
+	return nil, nil
+}
+
+func (m *MockProductService) UpdateProductResourceTags(ctx context.Context, productUID string, tagsReq *megaport.UpdateProductResourceTagsRequest) error {
+	return nil
+}
+
+func (m *MockProductService) GetProductType(ctx context.Context, productUID string) (string, error) {
+	return "", fmt.Errorf("mock: GetProductType not configured")
+}
+
+func (m *MockProductService) GetProductPricing(ctx context.Context, req megaport.PriceBookRequest) (*megaport.PriceBookDTO, error) {
+	return nil, fmt.Errorf("mock: GetProductPricing not configured")
+}
+
+func (m *MockProductService) GetProductPricingForCompany(ctx context.Context, req *megaport.GetProductPricingRequest) (*megaport.PriceBookDTO, error) {
+	return nil, fmt.Errorf("mock: GetProductPricingForCompany not configured")
+}
+
+// SetupMockClient overrides the login function with mock services and returns cleanup.
+func SetupMockClient(port *MockPortService, mcr *MockMCRService, mve *MockMVEService, vxc *MockVXCService) func() {
+	original := config.GetLoginFunc()
+	config.SetLoginFunc(func(ctx context.Context) (*megaport.Client, error) {
+		client := &megaport.Client{}
+		client.PortService = port
+		client.MCRService = mcr
+		client.MVEService = mve
+		client.VXCService = vxc
+		client.NATGatewayService = &MockNATGatewayService{}
+		client.IXService = &MockIXService{}
+		client.ServiceKeyService = &MockServiceKeyService{}
+		return client, nil
+	})
+	return func() { config.SetLoginFunc(original) }
+}
+
+// SetupMockNetworkServices replaces the NAT gateway, IX and service key mocks
+// of the current login function and returns cleanup. Call it after
+// SetupMockClient.
+func SetupMockNetworkServices(nat *MockNATGatewayService, ix *MockIXService, sk *MockServiceKeyService) func() {
+	original := config.GetLoginFunc()
+	config.SetLoginFunc(func(ctx context.Context) (*megaport.Client, error) {
+		client, err := original(ctx)
+		if err != nil {
+			return nil, err
+		}
+		client.NATGatewayService = nat
+		client.IXService = ix
+		client.ServiceKeyService = sk
+		return client, nil
+	})
+	return func() { config.SetLoginFunc(original) }
+}
+
+// SetupMockProductService adds a mock product service to the current login
+// function and returns cleanup. Call it after SetupMockClient.
+func SetupMockProductService(product *MockProductService) func() {
+	original := config.GetLoginFunc()
+	config.SetLoginFunc(func(ctx context.Context) (*megaport.Client, error) {
+		client, err := original(ctx)
+		if err != nil {
+			return nil, err
+		}
+		client.ProductService = product
+		return client, nil
+	})
+	return func() { config.SetLoginFunc(original) }
+}
@@ -0,0 +1,328 @@
+package infra
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/megaport/megaport-cli/internal/base/exitcodes"
+	"github.com/megaport/megaport-cli/internal/base/output"
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyViolation is one config entry that breaks a rule of a policy file.
+// Path locates the entry in the config, e.g. ports[0].
+type PolicyViolation struct {
+	output.Output `json:"-" header:"-"`
+	Rule          string `json:"rule"    header:"Rule"`
+	Path          string `json:"path"    header:"Path"`
+	Name          string `json:"name"    header:"Name"`
+	Message       string `json:"message" header:"Message"`
+}
+
+// PolicyFile is the layout of a policy file: a list of rules, each with a type
+// from policyRuleTypes and that type's settings.
+type PolicyFile struct {
+	Rules []yaml.Node `yaml:"rules"`
+}
+
+// policyRule is one rule of a policy file.
+type policyRule interface {
+	base() *policyRuleBase
+	// validate checks the rule's own settings.
+	validate() error
+	// check returns why e breaks the rule, or "" when it complies.
+	check(e policyEntry) string
+}
+
+// policyRuleTypes maps the type of each rule a policy file can declare to a
+// constructor of its rule. A new kind of rule only needs an entry here.
+var policyRuleTypes = map[string]func() policyRule{
+	"max_term":            func() policyRule { return &maxTermRule{} },
+	"require_cost_centre": func() policyRule { return &requireCostCentreRule{} },
+	"required_tags":       func() policyRule { return &requiredTagsRule{} },
+	"allowed_locations":   func() policyRule { return &allowedLocationsRule{} },
+	"max_rate_limit":      func() policyRule { return &maxRateLimitRule{} },
+}
+
+// policyRuleBase holds the settings every rule has. A rule applies to the
+// entries of the template types in Resources, or to every entry when it is
+// empty.
+type policyRuleBase struct {
+	Name      string   `yaml:"name,omitempty"`
+	Type      string   `yaml:"type"`
+	Resources []string `yaml:"resources,omitempty"`
+}
+
+func (b *policyRuleBase) base() *policyRuleBase { return b }
+
+// appliesTo reports whether the rule covers entries of resType.
+func (b *policyRuleBase) appliesTo(resType string) bool {
+	return len(b.Resources) == 0 || slices.Contains(b.Resources, resType)
+}
+
+// maxTermRule caps contract terms. An entry whose ApprovalTag resource tag is
+// set has been approved for a longer term.
+type maxTermRule struct {
+	policyRuleBase `yaml:",inline"`
+	Max            int    `yaml:"max"`
+	ApprovalTag    string `yaml:"approval_tag,omitempty"`
+}
+
+func (r *maxTermRule) validate() error {
+	if r.Max < 1 {
+		return errors.New("max must be at least 1")
+	}
+	return nil
+}
+
+func (r *maxTermRule) check(e policyEntry) string {
+	if e.term <= r.Max {
+		return ""
+	}
+	if r.ApprovalTag == "" {
+		return fmt.Sprintf("term of %d months exceeds the maximum of %d", e.term, r.Max)
+	}
+	if e.hasTags && e.tags[r.ApprovalTag] != "" {
+		return ""
+	}
+	return fmt.Sprintf("term of %d months exceeds the maximum of %d without approval; record the approval in the %q resource tag", e.term, r.Max, r.ApprovalTag)
+}
+
+// requireCostCentreRule requires a cost centre on every entry that can have one.
+type requireCostCentreRule struct {
+	policyRuleBase `yaml:",inline"`
+}
+
+func (r *requireCostCentreRule) validate() error { return nil }
+
+func (r *requireCostCentreRule) check(e policyEntry) string {
+	if !e.hasCostCentre || strings.TrimSpace(e.costCentre) != "" {
+		return ""
+	}
+	return "cost_centre is required"
+}
+
+// requiredTagsRule requires resource tag keys, with values, on every entry
+// that can have resource tags.
+type requiredTagsRule struct {
+	policyRuleBase `yaml:",inline"`
+	Keys           []string `yaml:"keys"`
+}
+
+func (r *requiredTagsRule) validate() error {
+	if len(r.Keys) == 0 {
+		return errors.New("keys must list at least one resource tag key")
+	}
+	return nil
+}
+
+func (r *requiredTagsRule) check(e policyEntry) string {
+	if !e.hasTags {
+		return ""
+	}
+	var missing []string
+	for _, k := range r.Keys {
+		if e.tags[k] == "" {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("resource_tags must set %s", strings.Join(missing, ", "))
+}
+
+// allowedLocationsRule limits the locations entries are ordered at.
+type allowedLocationsRule struct {
+	policyRuleBase `yaml:",inline"`
+	LocationIDs    []int `yaml:"location_ids"`
+}
+
+func (r *allowedLocationsRule) validate() error {
+	if len(r.LocationIDs) == 0 {
+		return errors.New("location_ids must list at least one location")
+	}
+	return nil
+}
+
+func (r *allowedLocationsRule) check(e policyEntry) string {
+	if e.locationID == 0 || slices.Contains(r.LocationIDs, e.locationID) {
+		return ""
+	}
+	return fmt.Sprintf("location_id %d is not an allowed location", e.locationID)
+}
+
+// maxRateLimitRule caps the rate limits of VXCs and IXs.
+type maxRateLimitRule struct {
+	policyRuleBase `yaml:",inline"`
+	Max            int `yaml:"max"`
+}
+
+func (r *maxRateLimitRule) validate() error {
+	if r.Max < 1 {
+		return errors.New("max must be at least 1")
+	}
+	return nil
+}
+
+func (r *maxRateLimitRule) check(e policyEntry) string {
+	if e.rateLimit <= r.Max {
+		return ""
+	}
+	return fmt.Sprintf("rate_limit of %d Mbps exceeds the maximum of %d", e.rateLimit, r.Max)
+}
+
+// policyEntry is the view of a config entry that rules check. Fields an
+// entry's type does not have are left zero, and rules skip them.
+type policyEntry struct {
+	resType       string
+	name          string
+	path          string
+	term          int
+	locationID    int
+	rateLimit     int
+	costCentre    string
+	hasCostCentre bool
+	tags          map[string]string
+	hasTags       bool
+}
+
+// policyEntries returns the entries of cfg in config order.
+func policyEntries(cfg *Config) []policyEntry {
+	var entries []policyEntry
+	for i, p := range cfg.Ports {
+		entries = append(entries, policyEntry{resType: "port", name: p.Name, path: fmt.Sprintf("ports[%d]", i), term: p.Term, locationID: p.LocationID,
+			costCentre: p.CostCentre, hasCostCentre: true, tags: p.ResourceTags, hasTags: true})
+	}
+	for i, m := range cfg.MCRs {
+		entries = append(entries, policyEntry{resType: "mcr", name: m.Name, path: fmt.Sprintf("mcrs[%d]", i), term: m.Term, locationID: m.LocationID,
+			costCentre: m.CostCentre, hasCostCentre: true, tags: m.ResourceTags, hasTags: true})
+	}
+	for i, mv := range cfg.MVEs {
+		entries = append(entries, policyEntry{resType: "mve", name: mv.Name, path: fmt.Sprintf("mves[%d]", i), term: mv.Term, locationID: mv.LocationID,
+			costCentre: mv.CostCentre, hasCostCentre: true, tags: mv.ResourceTags, hasTags: true})
+	}
+	for i, n := range cfg.NATGateways {
+		entries = append(entries, policyEntry{resType: "nat_gateway", name: n.Name, path: fmt.Sprintf("nat_gateways[%d]", i), term: n.Term, locationID: n.LocationID,
+			tags: n.ResourceTags, hasTags: true})
+	}
+	for i, x := range cfg.IXs {
+		entries = append(entries, policyEntry{resType: "ix", name: x.Name, path: fmt.Sprintf("ixs[%d]", i), rateLimit: x.RateLimit})
+	}
+	for i, k := range cfg.ServiceKeys {
+		entries = append(entries, policyEntry{resType: "service_key", name: k.Name, path: fmt.Sprintf("service_keys[%d]", i)})
+	}
+	for i, v := range cfg.VXCs {
+		entries = append(entries, policyEntry{resType: "vxc", name: v.Name, path: fmt.Sprintf("vxcs[%d]", i), term: v.Term, rateLimit: v.RateLimit,
+			costCentre: v.CostCentre, hasCostCentre: true, tags: v.ResourceTags, hasTags: true})
+	}
+	return entries
+}
+
+// LoadPolicy reads and checks a policy file. Unknown fields are rejected, in
+// the file and in each rule, so a mistyped setting cannot silently weaken a
+// rule.
+func LoadPolicy(path string) ([]policyRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy file: %w", err)
+	}
+	var file PolicyFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing policy file: %w", err)
+	}
+	if len(file.Rules) == 0 {
+		return nil, fmt.Errorf("policy file %s declares no rules", path)
+	}
+	rules := make([]policyRule, 0, len(file.Rules))
+	for i := range file.Rules {
+		rule, err := decodePolicyRule(&file.Rules[i])
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d]: %w", i, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// decodePolicyRule decodes a rule into the type its type field names.
+func decodePolicyRule(node *yaml.Node) (policyRule, error) {
+	var head policyRuleBase
+	if err := node.Decode(&head); err != nil {
+		return nil, err
+	}
+	newRule, ok := policyRuleTypes[head.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown rule type %q; use one of: %s", head.Type, strings.Join(SortedKeys(policyRuleTypes), ", "))
+	}
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+	rule := newRule()
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(rule); err != nil {
+		return nil, err
+	}
+	b := rule.base()
+	if b.Name == "" {
+		b.Name = b.Type
+	}
+	for _, t := range b.Resources {
+		if !slices.Contains(ResourceTypes, t) {
+			return nil, fmt.Errorf("unknown resource type %q; use one of: %s", t, strings.Join(ResourceTypes, ", "))
+		}
+	}
+	if err := rule.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name, err)
+	}
+	return rule, nil
+}
+
+// EvaluatePolicy checks every entry of cfg against rules.
+func EvaluatePolicy(rules []policyRule, cfg *Config) []PolicyViolation {
+	var violations []PolicyViolation
+	for _, e := range policyEntries(cfg) {
+		for _, rule := range rules {
+			b := rule.base()
+			if !b.appliesTo(e.resType) {
+				continue
+			}
+			if msg := rule.check(e); msg != "" {
+				violations = append(violations, PolicyViolation{Rule: b.Name, Path: e.path, Name: e.name, Message: msg})
+			}
+		}
+	}
+	return violations
+}
+
+// EnforcePolicy checks cfg against the policy file at policyPath, if one is
+// given, and prints any violations. Commands call it before logging in, so a
+// config that breaks the policy is rejected before anything is read or ordered.
+func EnforcePolicy(policyPath string, cfg *Config, outputFormat string, noColor bool) error {
+	if policyPath == "" {
+		return nil
+	}
+	rules, err := LoadPolicy(policyPath)
+	if err != nil {
+		output.PrintError("Invalid policy file: %v", noColor, err)
+		return exitcodes.NewUsageError(err)
+	}
+	violations := EvaluatePolicy(rules, cfg)
+	if len(violations) == 0 {
+		return nil
+	}
+	output.PrintError("The config violates the policy in %s:", noColor, policyPath)
+	if err := output.PrintOutput(violations, outputFormat, noColor); err != nil {
+		return err
+	}
+	return exitcodes.NewPolicyError(fmt.Errorf("%d policy violation(s) in %s", len(violations), policyPath))
+}
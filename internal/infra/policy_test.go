@@ -0,0 +1,93 @@
+package infra
+
+import (
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+rules:
+  - name: term-approval
+    type: max_term
+    max: 12
+    approval_tag: term-approved-by
+  - type: require_cost_centre
+    resources: [port, vxc]
+  - type: required_tags
+    keys: [owner, env]
+  - type: allowed_locations
+    location_ids: [1, 2]
+  - type: max_rate_limit
+    max: 1000
+`
+
+const policyConfig = `
+ports:
+  - name: Compliant
+    location_id: 1
+    speed: 1000
+    term: 12
+    cost_centre: NET-1
+    resource_tags: {owner: netops, env: prod}
+  - name: Approved
+    location_id: 2
+    speed: 1000
+    term: 36
+    cost_centre: NET-1
+    resource_tags: {owner: netops, env: prod, term-approved-by: cfo}
+  - name: Breaks
+    location_id: 9
+    speed: 1000
+    term: 24
+    resource_tags: {owner: netops}
+ixs:
+  - {name: IX, product_uid: "{{.port.Compliant}}", network_service_type: Los Angeles IX, asn: 65000, mac_address: "00:11:22:33:44:55", rate_limit: 10000}
+vxcs:
+  - name: V
+    rate_limit: 5000
+    term: 12
+    cost_centre: NET-1
+    resource_tags: {owner: netops, env: prod}
+    a_end: {product_uid: "{{.port.Compliant}}"}
+    b_end: {product_uid: "{{.port.Approved}}"}
+`
+
+func TestEvaluatePolicy(t *testing.T) {
+	rules, err := LoadPolicy(writeTempFile(t, "policy.yaml", testPolicy))
+	require.NoError(t, err)
+	cfg, err := ParseConfigFile(writeTempFile(t, "infra.yaml", policyConfig), nil, utils.ProjectSettings{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []PolicyViolation{
+		{Rule: "term-approval", Path: "ports[2]", Name: "Breaks", Message: `term of 24 months exceeds the maximum of 12 without approval; record the approval in the "term-approved-by" resource tag`},
+		{Rule: "require_cost_centre", Path: "ports[2]", Name: "Breaks", Message: "cost_centre is required"},
+		{Rule: "required_tags", Path: "ports[2]", Name: "Breaks", Message: "resource_tags must set env"},
+		{Rule: "allowed_locations", Path: "ports[2]", Name: "Breaks", Message: "location_id 9 is not an allowed location"},
+		{Rule: "max_rate_limit", Path: "ixs[0]", Name: "IX", Message: "rate_limit of 10000 Mbps exceeds the maximum of 1000"},
+		{Rule: "max_rate_limit", Path: "vxcs[0]", Name: "V", Message: "rate_limit of 5000 Mbps exceeds the maximum of 1000"},
+	}, EvaluatePolicy(rules, cfg))
+}
+
+func TestLoadPolicy_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   string
+	}{
+		{"no rules", "rules: []\n", "declares no rules"},
+		{"unknown type", "rules:\n  - type: max_speed\n", `rules[0]: unknown rule type "max_speed"; use one of: allowed_locations, max_rate_limit, max_term, require_cost_centre, required_tags`},
+		{"unknown field", "rules:\n  - {type: max_term, max: 12, approvalTag: x}\n", "field approvalTag not found"},
+		{"unknown resource type", "rules:\n  - {type: max_term, max: 12, resources: [ports]}\n", `unknown resource type "ports"`},
+		{"missing setting", "rules:\n  - {name: tags, type: required_tags}\n", "rules[0]: tags: keys must list at least one resource tag key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadPolicy(writeTempFile(t, "policy.yaml", tt.policy))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	megaport "github.com/megaport/megaportgo"
+)
+
+// CreatedResource records a resource that was successfully provisioned during an apply run.
+type CreatedResource struct {
+	ResType  string // displayed type, e.g. "Port" or "NAT Gateway"
+	Name     string
+	UID      string
+	Replaces string // UID of the resource this one replaces, deleted once the run succeeds
+	Lock     bool   // lock once the run succeeds
+}
+
+// DeleteResource deletes a single provisioned resource via the appropriate
+// service client. Service keys cannot be deleted, so they are deactivated.
+func DeleteResource(ctx context.Context, client *megaport.Client, r CreatedResource) error {
+	switch r.ResType {
+	case "Port":
+		_, err := client.PortService.DeletePort(ctx, &megaport.DeletePortRequest{PortID: r.UID, DeleteNow: true})
+		return err
+	case "MCR":
+		_, err := client.MCRService.DeleteMCR(ctx, &megaport.DeleteMCRRequest{MCRID: r.UID, DeleteNow: true})
+		return err
+	case "MVE":
+		_, err := client.MVEService.DeleteMVE(ctx, &megaport.DeleteMVERequest{MVEID: r.UID})
+		return err
+	case "NAT Gateway":
+		return client.NATGatewayService.DeleteNATGateway(ctx, r.UID)
+	case "IX":
+		return client.IXService.DeleteIX(ctx, r.UID, &megaport.DeleteIXRequest{DeleteNow: true})
+	case "Service Key":
+		return deactivateServiceKey(ctx, client, r.UID)
+	case "VXC":
+		return client.VXCService.DeleteVXC(ctx, r.UID, &megaport.DeleteVXCRequest{DeleteNow: true})
+	default:
+		return fmt.Errorf("unknown resource type %q", r.ResType)
+	}
+}
+
+// IsNotFound reports whether err is an API 404 response.
+func IsNotFound(err error) bool {
+	var apiErr *megaport.ErrorResponse
+	return errors.As(err, &apiErr) && apiErr.Response != nil && apiErr.Response.StatusCode == http.StatusNotFound
+}
+
+// deactivateServiceKey switches a service key off, keeping its product and
+// single-use setting, which the update API would otherwise reset.
+func deactivateServiceKey(ctx context.Context, client *megaport.Client, key string) error {
+	sk, err := client.ServiceKeyService.GetServiceKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	if sk == nil {
+		return fmt.Errorf("empty response from API")
+	}
+	_, err = client.ServiceKeyService.UpdateServiceKey(ctx, &megaport.UpdateServiceKeyRequest{
+		Key:        key,
+		ProductUID: sk.ProductUID,
+		SingleUse:  sk.SingleUse,
+		Active:     false,
+	})
+	return err
+}
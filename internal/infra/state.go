@@ -0,0 +1,258 @@
+package infra
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/megaport/megaport-cli/internal/base/output"
+)
+
+// StateVersion is the schema version written to new state files. Files with a
+// newer version are rejected rather than partially understood and overwritten.
+const StateVersion = 1
+
+// State maps config entries (type + name) to the UIDs apply provisioned for
+// them, so a re-run of the same config skips resources that already exist.
+// PendingDeletes holds the resources replacements superseded until their
+// delete succeeds, so a later apply or destroy can finish removing them.
+type State struct {
+	Version        int             `json:"version"`
+	Resources      []StateResource `json:"resources"`
+	PendingDeletes []StateResource `json:"pending_deletes,omitempty"`
+}
+
+// StateResource records the UID provisioned for a single config entry. Type is
+// the template key used in {{.type.name}} references (port, mcr, mve,
+// nat_gateway, ix, service_key, vxc).
+type StateResource struct {
+	Type      string    `json:"type"`
+	Name      string    `json:"name"`
+	UID       string    `json:"uid"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Lookup returns the UID recorded for resType/name.
+func (s *State) Lookup(resType, name string) (string, bool) {
+	for _, r := range s.Resources {
+		if r.Type == resType && r.Name == name {
+			return r.UID, true
+		}
+	}
+	return "", false
+}
+
+// Set records uid for resType/name, replacing any existing entry.
+func (s *State) Set(resType, name, uid string) {
+	now := time.Now().UTC()
+	for i, r := range s.Resources {
+		if r.Type == resType && r.Name == name {
+			s.Resources[i].UID = uid
+			s.Resources[i].UpdatedAt = now
+			return
+		}
+	}
+	s.Resources = append(s.Resources, StateResource{Type: resType, Name: name, UID: uid, UpdatedAt: now})
+}
+
+// Remove drops the entry for resType/name, if any.
+func (s *State) Remove(resType, name string) {
+	s.Resources = slices.DeleteFunc(s.Resources, func(r StateResource) bool {
+		return r.Type == resType && r.Name == name
+	})
+}
+
+// AddPendingDelete records uid, superseded as resType/name, as waiting to be
+// deleted.
+func (s *State) AddPendingDelete(resType, name, uid string) {
+	if slices.ContainsFunc(s.PendingDeletes, func(r StateResource) bool { return r.UID == uid }) {
+		return
+	}
+	s.PendingDeletes = append(s.PendingDeletes, StateResource{Type: resType, Name: name, UID: uid, UpdatedAt: time.Now().UTC()})
+}
+
+// RemovePendingDelete drops uid from the resources waiting to be deleted.
+func (s *State) RemovePendingDelete(uid string) {
+	s.PendingDeletes = slices.DeleteFunc(s.PendingDeletes, func(r StateResource) bool {
+		return r.UID == uid
+	})
+}
+
+// DefaultStatePath derives the state file path from the config file path:
+// infra.yaml → infra.state.json, alongside the config.
+func DefaultStatePath(configPath string) string {
+	return strings.TrimSuffix(configPath, filepath.Ext(configPath)) + ".state.json"
+}
+
+// LoadState reads the state file at path. A missing file is not an error: it
+// means nothing has been applied yet, so an empty state is returned.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &State{Version: StateVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading state file: %w", err)
+	}
+	state := &State{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parsing state file %q: %w", path, err)
+	}
+	if state.Version > StateVersion {
+		return nil, fmt.Errorf("state file %q has version %d; this CLI supports up to version %d", path, state.Version, StateVersion)
+	}
+	state.Version = StateVersion
+	return state, nil
+}
+
+// SaveState writes state to path via a temp file and rename, so an interrupted
+// write never leaves a truncated state file that would make the next run
+// re-order everything. The file holds resource UIDs only, but is kept 0600 to
+// match the CLI's other local files.
+func SaveState(path string, state *State) error {
+	return WriteJSONFile(path, state, "state file")
+}
+
+// WriteJSONFile writes v to path as indented JSON via a temp file and rename,
+// so the file is never left half-written. what names the file in errors.
+func WriteJSONFile(path string, v interface{}, what string) (err error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", what, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", what, err)
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmpName)
+		}
+	}()
+	if _, err = tmp.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing %s: %w", what, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", what, err)
+	}
+	if err = os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("writing %s: %w", what, err)
+	}
+	return nil
+}
+
+// StateFile pairs the loaded state with the path it is saved to. Every change is
+// written through immediately: an order is billing from the moment it is placed,
+// so the UID must reach disk before the next resource is attempted. Its methods
+// are safe for the concurrent branches of a parallel apply.
+type StateFile struct {
+	mu    sync.Mutex
+	path  string
+	State *State
+}
+
+// OpenStateFile loads the state file at path (or an empty state if it does not exist).
+func OpenStateFile(path string) (*StateFile, error) {
+	state, err := LoadState(path)
+	if err != nil {
+		return nil, err
+	}
+	return &StateFile{path: path, State: state}, nil
+}
+
+// Lookup returns the UID recorded for resType/name.
+func (f *StateFile) Lookup(resType, name string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.State.Lookup(resType, name)
+}
+
+// Record stores uid for resType/name and saves the file. A write failure is a
+// warning rather than an error: the resource has already been ordered, and
+// aborting the run would not un-order it.
+func (f *StateFile) Record(resType, name, uid string, noColor bool) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.State.Set(resType, name, uid)
+	if err := SaveState(f.path, f.State); err != nil {
+		output.PrintWarning("Could not save apply state for %s %q (%s): %v; a re-run will not know it exists", noColor, resType, name, uid, err)
+	}
+}
+
+// Replace records uid for resType/name in place of replaced, which is kept as a
+// pending delete until DeletedReplaced, and saves the file.
+func (f *StateFile) Replace(resType, name, uid, replaced string, noColor bool) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.State.AddPendingDelete(resType, name, replaced)
+	f.State.Set(resType, name, uid)
+	if err := SaveState(f.path, f.State); err != nil {
+		output.PrintWarning("Could not save apply state for %s %q (%s): %v; a re-run will not know it exists", noColor, resType, name, uid, err)
+	}
+}
+
+// Restore records replaced for resType/name again after its replacement was
+// rolled back, and saves the file.
+func (f *StateFile) Restore(resType, name, replaced string, noColor bool) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.State.RemovePendingDelete(replaced)
+	f.State.Set(resType, name, replaced)
+	if err := SaveState(f.path, f.State); err != nil {
+		output.PrintWarning("Could not save apply state for %s %q (%s): %v", noColor, resType, name, replaced, err)
+	}
+}
+
+// PendingDeletes returns the resources waiting to be deleted, oldest first.
+func (f *StateFile) PendingDeletes() []StateResource {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.State.PendingDeletes)
+}
+
+// DeletedReplaced drops uid from the pending deletes once it is gone, and
+// saves the file.
+func (f *StateFile) DeletedReplaced(uid string, noColor bool) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.State.RemovePendingDelete(uid)
+	if err := SaveState(f.path, f.State); err != nil {
+		output.PrintWarning("Could not save apply state after deleting %s: %v", noColor, uid, err)
+	}
+}
+
+// Forget removes resType/name and saves the file.
+func (f *StateFile) Forget(resType, name string, noColor bool) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.State.Lookup(resType, name); !ok {
+		return
+	}
+	f.State.Remove(resType, name)
+	if err := SaveState(f.path, f.State); err != nil {
+		output.PrintWarning("Could not save apply state after removing %s %q: %v", noColor, resType, name, err)
+	}
+}
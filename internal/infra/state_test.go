@@ -0,0 +1,73 @@
+package infra
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeStateFile writes a state file recording the given entries next to configPath.
+func writeStateFile(t *testing.T, configPath string, entries ...StateResource) string {
+	t.Helper()
+	path := DefaultStatePath(configPath)
+	require.NoError(t, SaveState(path, &State{Version: StateVersion, Resources: entries}))
+	return path
+}
+
+func TestDefaultStatePath(t *testing.T) {
+	assert.Equal(t, filepath.Join("dir", "infra.state.json"), DefaultStatePath(filepath.Join("dir", "infra.yaml")))
+	assert.Equal(t, "infra.state.json", DefaultStatePath("infra.json"))
+	assert.Equal(t, "infra.state.json", DefaultStatePath("infra"))
+}
+
+func TestLoadState_MissingFileIsEmpty(t *testing.T) {
+	state, err := LoadState(filepath.Join(t.TempDir(), "none.state.json"))
+	require.NoError(t, err)
+	assert.Equal(t, StateVersion, state.Version)
+	assert.Empty(t, state.Resources)
+}
+
+func TestLoadState_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "infra.state.json")
+	state := &State{Version: StateVersion}
+	state.Set("port", "Sydney-Port", "port-uid-1")
+	state.Set("vxc", "Port-to-MCR", "vxc-uid-1")
+	state.Set("port", "Sydney-Port", "port-uid-2") // replaces, does not duplicate
+	require.NoError(t, SaveState(path, state))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := LoadState(path)
+	require.NoError(t, err)
+	require.Len(t, loaded.Resources, 2)
+	uid, ok := loaded.Lookup("port", "Sydney-Port")
+	assert.True(t, ok)
+	assert.Equal(t, "port-uid-2", uid)
+
+	loaded.Remove("port", "Sydney-Port")
+	_, ok = loaded.Lookup("port", "Sydney-Port")
+	assert.False(t, ok)
+	_, ok = loaded.Lookup("vxc", "Port-to-MCR")
+	assert.True(t, ok)
+}
+
+func TestLoadState_RejectsNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "infra.state.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 99, "resources": []}`), 0600))
+	_, err := LoadState(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "version 99")
+}
+
+func TestLoadState_InvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "infra.state.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{not json`), 0600))
+	_, err := LoadState(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parsing state file")
+}
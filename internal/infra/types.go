@@ -0,0 +1,154 @@
+// Package infra holds the config, state, policy and account-inventory helpers
+// shared by the apply, plan, destroy, import and drift commands.
+package infra
+
+import "github.com/megaport/megaport-cli/internal/base/output"
+
+// Config is the top-level structure for a megaport apply config file.
+type Config struct {
+	Ports       []PortConfig       `yaml:"ports,omitempty"        json:"ports,omitempty"`
+	MCRs        []MCRConfig        `yaml:"mcrs,omitempty"         json:"mcrs,omitempty"`
+	MVEs        []MVEConfig        `yaml:"mves,omitempty"         json:"mves,omitempty"`
+	NATGateways []NATGatewayConfig `yaml:"nat_gateways,omitempty" json:"nat_gateways,omitempty"`
+	IXs         []IXConfig         `yaml:"ixs,omitempty"          json:"ixs,omitempty"`
+	ServiceKeys []ServiceKeyConfig `yaml:"service_keys,omitempty" json:"service_keys,omitempty"`
+	VXCs        []VXCConfig        `yaml:"vxcs,omitempty"         json:"vxcs,omitempty"`
+
+	// Outputs names values to resolve once a run succeeds, for --outputs-file:
+	// {{.type.name}} stands for a UID and {{.type.name.attribute}} for another
+	// attribute, e.g. {{.vxc.AWS.a_end_vlan}}.
+	Outputs map[string]string `yaml:"outputs,omitempty" json:"outputs,omitempty"`
+}
+
+// PortConfig describes a port to provision.
+type PortConfig struct {
+	Name                  string            `yaml:"name" json:"name"`
+	LocationID            int               `yaml:"location_id" json:"location_id"`
+	Speed                 int               `yaml:"speed" json:"speed"`
+	Term                  int               `yaml:"term" json:"term"`
+	LagCount              int               `yaml:"lag_count,omitempty" json:"lag_count,omitempty"` // ports in a LAG; 0 orders a single port
+	MarketplaceVisibility bool              `yaml:"marketplace_visibility,omitempty" json:"marketplace_visibility,omitempty"`
+	DiversityZone         string            `yaml:"diversity_zone,omitempty" json:"diversity_zone,omitempty"`
+	CostCentre            string            `yaml:"cost_centre,omitempty" json:"cost_centre,omitempty"`
+	ResourceTags          map[string]string `yaml:"resource_tags,omitempty" json:"resource_tags,omitempty"`
+	Locked                *bool             `yaml:"locked,omitempty" json:"locked,omitempty"` // lock state; omit to leave it unmanaged
+}
+
+// MCRConfig describes an MCR to provision.
+type MCRConfig struct {
+	Name              string                   `yaml:"name" json:"name"`
+	LocationID        int                      `yaml:"location_id" json:"location_id"`
+	Speed             int                      `yaml:"speed" json:"speed"`
+	Term              int                      `yaml:"term" json:"term"`
+	ASN               int                      `yaml:"asn,omitempty" json:"asn,omitempty"`
+	TunnelCount       int                      `yaml:"tunnel_count,omitempty" json:"tunnel_count,omitempty"` // IPsec add-on tunnel count (10, 20, or 30); 0 = no IPsec add-on
+	DiversityZone     string                   `yaml:"diversity_zone,omitempty" json:"diversity_zone,omitempty"`
+	CostCentre        string                   `yaml:"cost_centre,omitempty" json:"cost_centre,omitempty"`
+	ResourceTags      map[string]string        `yaml:"resource_tags,omitempty" json:"resource_tags,omitempty"`
+	PrefixFilterLists []PrefixFilterListConfig `yaml:"prefix_filter_lists,omitempty" json:"prefix_filter_lists,omitempty"` // created once the MCR is provisioned
+	Locked            *bool                    `yaml:"locked,omitempty" json:"locked,omitempty"`
+}
+
+// PrefixFilterListConfig describes a prefix filter list on an MCR.
+type PrefixFilterListConfig struct {
+	Description   string                    `yaml:"description" json:"description"`
+	AddressFamily string                    `yaml:"address_family" json:"address_family"` // IPv4 or IPv6
+	Entries       []PrefixFilterEntryConfig `yaml:"entries" json:"entries"`
+}
+
+// PrefixFilterEntryConfig is one entry of a prefix filter list. Ge and Le are
+// optional prefix-length bounds; 0 leaves them unset.
+type PrefixFilterEntryConfig struct {
+	Action string `yaml:"action" json:"action"` // permit or deny
+	Prefix string `yaml:"prefix" json:"prefix"`
+	Ge     int    `yaml:"ge,omitempty" json:"ge,omitempty"`
+	Le     int    `yaml:"le,omitempty" json:"le,omitempty"`
+}
+
+// MVEConfig describes an MVE to provision.
+type MVEConfig struct {
+	Name          string                 `yaml:"name" json:"name"`
+	LocationID    int                    `yaml:"location_id" json:"location_id"`
+	Term          int                    `yaml:"term" json:"term"`
+	VendorConfig  map[string]interface{} `yaml:"vendor_config" json:"vendor_config"` // vendor-specific fields (e.g. vendor, imageId, productSize)
+	DiversityZone string                 `yaml:"diversity_zone,omitempty" json:"diversity_zone,omitempty"`
+	CostCentre    string                 `yaml:"cost_centre,omitempty" json:"cost_centre,omitempty"`
+	ResourceTags  map[string]string      `yaml:"resource_tags,omitempty" json:"resource_tags,omitempty"`
+	Locked        *bool                  `yaml:"locked,omitempty" json:"locked,omitempty"`
+}
+
+// NATGatewayConfig describes a NAT gateway to provision. Apply creates it as a
+// design, validates the design order and then buys it, like nat-gateway
+// create, validate and buy.
+type NATGatewayConfig struct {
+	Name                  string            `yaml:"name" json:"name"`
+	LocationID            int               `yaml:"location_id" json:"location_id"`
+	Speed                 int               `yaml:"speed" json:"speed"`
+	Term                  int               `yaml:"term" json:"term"`
+	SessionCount          int               `yaml:"session_count,omitempty" json:"session_count,omitempty"`
+	ASN                   int               `yaml:"asn,omitempty" json:"asn,omitempty"`
+	DiversityZone         string            `yaml:"diversity_zone,omitempty" json:"diversity_zone,omitempty"`
+	AutoRenewTerm         bool              `yaml:"auto_renew_term,omitempty" json:"auto_renew_term,omitempty"`
+	ServiceLevelReference string            `yaml:"service_level_reference,omitempty" json:"service_level_reference,omitempty"`
+	PromoCode             string            `yaml:"promo_code,omitempty" json:"promo_code,omitempty"`
+	ResourceTags          map[string]string `yaml:"resource_tags,omitempty" json:"resource_tags,omitempty"`
+	Locked                *bool             `yaml:"locked,omitempty" json:"locked,omitempty"`
+}
+
+// IXConfig describes an Internet Exchange connection on a port.
+type IXConfig struct {
+	Name               string `yaml:"name" json:"name"`
+	ProductUID         string `yaml:"product_uid" json:"product_uid"`                   // port to attach to, e.g. {{.port.name}}
+	NetworkServiceType string `yaml:"network_service_type" json:"network_service_type"` // the exchange, e.g. "Los Angeles IX"
+	ASN                int    `yaml:"asn" json:"asn"`
+	MACAddress         string `yaml:"mac_address" json:"mac_address"`
+	RateLimit          int    `yaml:"rate_limit" json:"rate_limit"`
+	VLAN               int    `yaml:"vlan,omitempty" json:"vlan,omitempty"`
+	Shutdown           bool   `yaml:"shutdown,omitempty" json:"shutdown,omitempty"`
+	PromoCode          string `yaml:"promo_code,omitempty" json:"promo_code,omitempty"`
+}
+
+// ServiceKeyConfig describes a service key for a port. Service keys have no
+// name of their own, so Name is sent as the key's description.
+type ServiceKeyConfig struct {
+	Name        string `yaml:"name" json:"name"`
+	ProductUID  string `yaml:"product_uid" json:"product_uid"` // port the key grants access to, e.g. {{.port.name}}
+	MaxSpeed    int    `yaml:"max_speed" json:"max_speed"`
+	SingleUse   bool   `yaml:"single_use,omitempty" json:"single_use,omitempty"`
+	VLAN        int    `yaml:"vlan,omitempty" json:"vlan,omitempty"` // required for single-use keys
+	PreApproved bool   `yaml:"pre_approved,omitempty" json:"pre_approved,omitempty"`
+	Active      bool   `yaml:"active,omitempty" json:"active,omitempty"`
+}
+
+// VXCEndpointConfig describes one end of a VXC connection. PartnerConfig
+// takes the same object as the partnerConfig field of vxc buy --json, e.g. an
+// AWS, Azure, Google, Oracle, IBM or transit connection, or an MCR's vRouter
+// interfaces and BGP sessions. InnerVLAN and VNICIndex apply to MVE ends.
+type VXCEndpointConfig struct {
+	ProductUID    string                 `yaml:"product_uid,omitempty" json:"product_uid,omitempty"` // may be omitted for Azure, Google and Oracle ends, whose port is looked up from the partner key
+	VLAN          int                    `yaml:"vlan,omitempty" json:"vlan,omitempty"`
+	InnerVLAN     int                    `yaml:"inner_vlan,omitempty" json:"inner_vlan,omitempty"`
+	VNICIndex     *int                   `yaml:"vnic_index,omitempty" json:"vnic_index,omitempty"` // 0 is the first vNIC, so omit it to leave it unset
+	PartnerConfig map[string]interface{} `yaml:"partner_config,omitempty" json:"partner_config,omitempty"`
+}
+
+// VXCConfig describes a VXC to provision.
+type VXCConfig struct {
+	Name         string            `yaml:"name" json:"name"`
+	RateLimit    int               `yaml:"rate_limit" json:"rate_limit"`
+	Term         int               `yaml:"term" json:"term"`
+	AEnd         VXCEndpointConfig `yaml:"a_end" json:"a_end"`
+	BEnd         VXCEndpointConfig `yaml:"b_end" json:"b_end"`
+	CostCentre   string            `yaml:"cost_centre,omitempty" json:"cost_centre,omitempty"`
+	ResourceTags map[string]string `yaml:"resource_tags,omitempty" json:"resource_tags,omitempty"`
+	Locked       *bool             `yaml:"locked,omitempty" json:"locked,omitempty"`
+}
+
+// Result records the outcome of provisioning a single resource.
+type Result struct {
+	output.Output `json:"-" header:"-"`
+	Type          string `json:"type"   header:"Type"`
+	Name          string `json:"name"   header:"Name"`
+	UID           string `json:"uid"    header:"UID"`
+	Status        string `json:"status" header:"Status"`
+}
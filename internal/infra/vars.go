@@ -0,0 +1,392 @@
+package infra
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	IncludeKey   = "include"
+	VariablesKey = "variables"
+	varRefType   = "var" // {{.var.name}} names a variable rather than a resource
+)
+
+// envRe matches ${env:NAME} references in config values.
+var envRe = regexp.MustCompile(`\$\{env:([^}]+)\}`)
+
+// ConfigVars reads the --var-file and --var flags into variable values, in
+// that order of precedence: a --var overrides a --var-file, and a later
+// --var-file overrides an earlier one. A --var value is untyped, like a plain
+// YAML scalar, so --var speed=10000 can set an integer field.
+func ConfigVars(cmd *cobra.Command) (map[string]*yaml.Node, error) {
+	// Flag read errors are intentionally ignored — flags are registered by the command builder.
+	varFiles, _ := cmd.Flags().GetStringArray("var-file")
+	pairs, _ := cmd.Flags().GetStringArray("var")
+
+	vars := map[string]*yaml.Node{}
+	for _, path := range varFiles {
+		data, err := ReadConfigData(path)
+		if err != nil {
+			return nil, fmt.Errorf("--var-file %s: %w", path, err)
+		}
+		doc, err := configNode(path, data)
+		if err != nil {
+			return nil, fmt.Errorf("--var-file %s: %w", path, err)
+		}
+		// Variable files may read the environment, but not other variables.
+		x := newExpander()
+		x.walk(doc, false)
+		if err := x.err(); err != nil {
+			return nil, fmt.Errorf("--var-file %s: %w", path, err)
+		}
+		for i := 0; i+1 < len(doc.Content); i += 2 {
+			vars[doc.Content[i].Value] = doc.Content[i+1]
+		}
+	}
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var %q: expected name=value", pair)
+		}
+		vars[name] = &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	}
+	return vars, nil
+}
+
+// configNode decodes a config file's data into its top-level mapping node. An
+// empty file is an empty mapping.
+func configNode(filePath string, data []byte) (*yaml.Node, error) {
+	doc := &yaml.Node{}
+	if isJSONConfig(filePath) {
+		var v interface{}
+		if err := decodeConfigData(data, true, &v); err != nil {
+			return nil, err
+		}
+		if err := doc.Encode(v); err != nil {
+			return nil, fmt.Errorf("parsing JSON config: %w", err)
+		}
+	} else if err := decodeConfigData(data, false, doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == yaml.DocumentNode {
+		doc = doc.Content[0]
+	}
+	switch doc.Kind {
+	case 0:
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	case yaml.MappingNode:
+		return doc, nil
+	}
+	if doc.ShortTag() == "!!null" {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	return nil, fmt.Errorf("config file %q must contain a mapping of resource lists", filePath)
+}
+
+// LoadConfigNode decodes a config file and merges in the files its include
+// list names, resolved relative to it. Included files come first, in the
+// order listed: their resource lists are prepended to the file's own, and
+// their variables are defaults the file can override. composed reports
+// whether the file has an include list or a variables block. including lists
+// the files that include this one, to detect include cycles.
+func LoadConfigNode(filePath string, data []byte, including []string) (doc *yaml.Node, composed bool, err error) {
+	doc, err = configNode(filePath, data)
+	if err != nil {
+		return nil, false, err
+	}
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, false, fmt.Errorf("resolving config file path: %w", err)
+	}
+	if slices.Contains(including, abs) {
+		return nil, false, fmt.Errorf("include cycle: %s", strings.Join(append(including, abs), " -> "))
+	}
+	including = append(including, abs)
+
+	includes, err := includePaths(takeKey(doc, IncludeKey))
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", filePath, err)
+	}
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, inc := range includes {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(filePath), inc)
+		}
+		incData, err := ReadConfigData(inc)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: include %s: %w", filePath, inc, err)
+		}
+		incDoc, _, err := LoadConfigNode(inc, incData, including)
+		if err != nil {
+			return nil, false, err
+		}
+		mergeConfigNodes(merged, incDoc)
+	}
+	_, hasVariables := lookupKey(doc, VariablesKey)
+	if len(includes) == 0 {
+		return doc, hasVariables, nil
+	}
+	mergeConfigNodes(merged, doc)
+	return merged, true, nil
+}
+
+// includePaths reads an include value: a path or a list of paths.
+func includePaths(n *yaml.Node) ([]string, error) {
+	if n == nil || n.ShortTag() == "!!null" {
+		return nil, nil
+	}
+	if n.Kind == yaml.ScalarNode {
+		return []string{n.Value}, nil
+	}
+	var paths []string
+	if err := n.Decode(&paths); err != nil {
+		return nil, fmt.Errorf("include must be a path or a list of paths")
+	}
+	return paths, nil
+}
+
+// mergeConfigNodes merges the top-level keys of src into dst. Lists are
+// appended to and the entries of mappings (the variables block) are set;
+// any other value replaces dst's.
+func mergeConfigNodes(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		existing, ok := lookupKey(dst, key.Value)
+		switch {
+		case !ok:
+			dst.Content = append(dst.Content, key, value)
+		case existing.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode:
+			existing.Content = append(existing.Content, value.Content...)
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				setKey(existing, value.Content[j], value.Content[j+1])
+			}
+		default:
+			setKey(dst, key, value)
+		}
+	}
+}
+
+// lookupKey returns the value of key in the mapping node m.
+func lookupKey(m *yaml.Node, key string) (*yaml.Node, bool) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1], true
+		}
+	}
+	return nil, false
+}
+
+// setKey sets key to value in the mapping node m.
+func setKey(m, key, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key.Value {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, key, value)
+}
+
+// takeKey removes key from the mapping node m and returns its value.
+func takeKey(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			value := m.Content[i+1]
+			m.Content = slices.Delete(m.Content, i, i+2)
+			return value
+		}
+	}
+	return nil
+}
+
+// ExpandConfigNode removes the variables block from doc and substitutes
+// {{.var.name}} and ${env:NAME} references in its values. The project
+// config's variables (projectVars) override the declared values, and vars
+// overrides both; vars must name declared variables. substituted reports
+// whether doc held a variables block or any reference.
+func ExpandConfigNode(doc *yaml.Node, vars map[string]*yaml.Node, projectVars map[string]interface{}) (substituted bool, err error) {
+	x := newExpander()
+	declared := takeKey(doc, VariablesKey)
+	if declared != nil && declared.Kind != yaml.MappingNode && declared.ShortTag() != "!!null" {
+		return false, fmt.Errorf("variables must be a mapping of names to values")
+	}
+	if declared != nil {
+		for i := 0; i+1 < len(declared.Content); i += 2 {
+			value := declared.Content[i+1]
+			x.walk(value, false)
+			x.vars[declared.Content[i].Value] = value
+		}
+	}
+	// Project variables (.megaport.yaml) set only the variables this config
+	// declares, so one project config can serve every config file under it.
+	for _, name := range slices.Sorted(maps.Keys(projectVars)) {
+		if _, ok := x.vars[name]; !ok {
+			continue
+		}
+		value := &yaml.Node{}
+		if err := value.Encode(projectVars[name]); err != nil {
+			x.fail(fmt.Errorf("project variable %q: %w", name, err))
+			continue
+		}
+		x.walk(value, false)
+		x.vars[name] = value
+	}
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		value := vars[name]
+		if _, ok := x.vars[name]; !ok {
+			x.fail(fmt.Errorf("variable %q is set but not declared in the config's variables block", name))
+			continue
+		}
+		x.vars[name] = value
+	}
+	x.walk(doc, true)
+	if err := x.err(); err != nil {
+		return false, err
+	}
+	return declared != nil || x.substituted, nil
+}
+
+// expander substitutes references in config values, collecting every failure.
+type expander struct {
+	vars        map[string]*yaml.Node
+	substituted bool
+	errs        []error
+	seen        map[string]bool // reported failures, so a repeated reference is reported once
+}
+
+func newExpander() *expander {
+	return &expander{vars: map[string]*yaml.Node{}, seen: map[string]bool{}}
+}
+
+// err returns every failure, joined.
+func (x *expander) err() error {
+	if len(x.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("resolving variables: %w", errors.Join(x.errs...))
+}
+
+func (x *expander) fail(err error) {
+	if !x.seen[err.Error()] {
+		x.seen[err.Error()] = true
+		x.errs = append(x.errs, err)
+	}
+}
+
+// walk substitutes the references in n's scalar values; mapping keys are left
+// as written. withVars reports whether variable references are allowed, which
+// they are not in the values of the variables themselves.
+func (x *expander) walk(n *yaml.Node, withVars bool) {
+	switch n.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			x.walk(c, withVars)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			x.walk(n.Content[i], withVars)
+		}
+	case yaml.ScalarNode:
+		if n.ShortTag() == "!!str" {
+			x.scalar(n, withVars)
+		}
+	}
+}
+
+// scalar substitutes the references in a string value. A value that is a
+// single reference takes on the referenced value, so a variable can hold a
+// number, a list or a mapping; a reference within a longer string must name a
+// scalar.
+func (x *expander) scalar(n *yaml.Node, withVars bool) {
+	line, column := n.Line, n.Column
+	if sub := TemplateRe.FindStringSubmatch(n.Value); sub != nil && sub[0] == n.Value && sub[1] == varRefType {
+		if v, err := x.variable(sub, withVars); err != nil {
+			x.fail(err)
+		} else {
+			*n = *v
+			n.Line, n.Column = line, column
+			x.substituted = true
+		}
+		return
+	}
+	if sub := envRe.FindStringSubmatch(n.Value); sub != nil && sub[0] == n.Value {
+		if v, err := env(sub); err != nil {
+			x.fail(err)
+		} else {
+			// Untyped, like a --var value.
+			*n = yaml.Node{Kind: yaml.ScalarNode, Value: v, Line: line, Column: column}
+			x.substituted = true
+		}
+		return
+	}
+
+	s, varErr := ExpandTemplates(n.Value, TemplateRe, func(sub []string) (string, error) {
+		if sub[1] != varRefType {
+			return sub[0], nil // a resource reference, resolved when applied
+		}
+		v, err := x.variable(sub, withVars)
+		if err != nil {
+			return "", err
+		}
+		if v.Kind != yaml.ScalarNode {
+			return "", fmt.Errorf("variable %q is a list or mapping and can only be used as a whole value", sub[2])
+		}
+		return v.Value, nil
+	})
+	s, envErr := ExpandTemplates(s, envRe, env)
+	for _, err := range []error{varErr, envErr} {
+		if err != nil {
+			for _, e := range unjoin(err) {
+				x.fail(e)
+			}
+		}
+	}
+	if s != n.Value {
+		n.Value = s
+		x.substituted = true
+	}
+}
+
+// variable returns the value of the variable a {{.var.name}} match names.
+func (x *expander) variable(sub []string, withVars bool) (*yaml.Node, error) {
+	name := sub[2]
+	if !withVars {
+		return nil, fmt.Errorf("%s: variables cannot reference other variables", sub[0])
+	}
+	v, ok := x.vars[name]
+	if !ok {
+		return nil, fmt.Errorf("undefined variable %q", name)
+	}
+	if v.Kind == yaml.ScalarNode && v.ShortTag() == "!!null" {
+		return nil, fmt.Errorf("variable %q has no value; set it with --var or --var-file", name)
+	}
+	return v, nil
+}
+
+// env returns the value of the environment variable a ${env:NAME} match names.
+// A variable that is set but empty is allowed.
+func env(sub []string) (string, error) {
+	v, ok := os.LookupEnv(sub[1])
+	if !ok {
+		return "", fmt.Errorf("environment variable %q is not set", sub[1])
+	}
+	return v, nil
+}
+
+// unjoin splits an errors.Join error into the errors it joins.
+func unjoin(err error) []error {
+	if j, ok := err.(interface{ Unwrap() []error }); ok {
+		return j.Unwrap()
+	}
+	return []error{err}
+}
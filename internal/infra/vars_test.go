@@ -0,0 +1,180 @@
+package infra
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/megaport/megaport-cli/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// varsCmd builds a minimal cobra.Command with the flags ConfigVars reads.
+func varsCmd(varFiles []string, vars ...string) *cobra.Command {
+	cmd := &cobra.Command{Use: "apply"}
+	cmd.Flags().StringArray("var", nil, "")
+	cmd.Flags().StringArray("var-file", nil, "")
+	for _, f := range varFiles {
+		_ = cmd.Flags().Set("var-file", f)
+	}
+	for _, v := range vars {
+		_ = cmd.Flags().Set("var", v)
+	}
+	return cmd
+}
+
+const regionConfig = `
+variables:
+  location_id:
+  speed: 1000
+  region: syd
+  tags:
+    team: networking
+ports:
+  - name: "Port-{{.var.region}}"
+    location_id: "{{.var.location_id}}"
+    speed: "{{.var.speed}}"
+    term: 12
+    cost_centre: "${env:APPLY_TEST_COST_CENTRE}"
+    resource_tags: "{{.var.tags}}"
+vxcs:
+  - name: "VXC-{{.var.region}}"
+    rate_limit: 100
+    term: 12
+    a_end: {product_uid: "{{.port.Port-syd}}"}
+`
+
+func TestParseConfigFile_Variables(t *testing.T) {
+	t.Setenv("APPLY_TEST_COST_CENTRE", "CC-42")
+	f := writeTempFile(t, "region.yaml", regionConfig)
+	varFile := writeTempFile(t, "syd.yaml", "location_id: 3\nspeed: 10000\n")
+
+	vars, err := ConfigVars(varsCmd([]string{varFile}, "speed=100000"))
+	require.NoError(t, err)
+	cfg, err := ParseConfigFile(f, vars, utils.ProjectSettings{})
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Ports, 1)
+	p := cfg.Ports[0]
+	assert.Equal(t, "Port-syd", p.Name, "a reference within a string is substituted")
+	assert.Equal(t, 3, p.LocationID, "a whole-value reference takes the variable's type")
+	assert.Equal(t, 100000, p.Speed, "--var overrides --var-file, which overrides the default")
+	assert.Equal(t, "CC-42", p.CostCentre)
+	assert.Equal(t, map[string]string{"team": "networking"}, p.ResourceTags, "a variable can hold a mapping")
+	require.Len(t, cfg.VXCs, 1)
+	assert.Equal(t, "VXC-syd", cfg.VXCs[0].Name)
+	assert.Equal(t, "{{.port.Port-syd}}", cfg.VXCs[0].AEnd.ProductUID, "resource references are left for apply")
+}
+
+func TestParseConfigFile_VariablesInJSON(t *testing.T) {
+	f := writeTempFile(t, "region.json", `{
+  "variables": {"location_id": 3},
+  "mcrs": [{"name": "MCR", "location_id": "{{.var.location_id}}", "speed": 1000, "term": 12}]
+}`)
+	cfg, err := ParseConfigFile(f, nil, utils.ProjectSettings{})
+	require.NoError(t, err)
+	require.Len(t, cfg.MCRs, 1)
+	assert.Equal(t, 3, cfg.MCRs[0].LocationID)
+}
+
+func TestParseConfigFile_UnresolvedVariablesReported(t *testing.T) {
+	require.NoError(t, os.Unsetenv("APPLY_TEST_UNSET"))
+	f := writeTempFile(t, "region.yaml", `
+variables:
+  location_id:
+ports:
+  - name: "Port-{{.var.region}}"
+    location_id: "{{.var.location_id}}"
+    speed: 1000
+    term: 12
+    cost_centre: "${env:APPLY_TEST_UNSET}"
+  - name: "Other-{{.var.region}}"
+    location_id: 1
+    speed: 1000
+    term: 12
+`)
+	vars := map[string]*yaml.Node{"typo": {Kind: yaml.ScalarNode, Value: "1"}}
+	_, err := ParseConfigFile(f, vars, utils.ProjectSettings{})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `undefined variable "region"`)
+	assert.ErrorContains(t, err, `variable "location_id" has no value; set it with --var or --var-file`)
+	assert.ErrorContains(t, err, `environment variable "APPLY_TEST_UNSET" is not set`)
+	assert.ErrorContains(t, err, `variable "typo" is set but not declared`)
+	assert.Equal(t, 1, strings.Count(err.Error(), `undefined variable "region"`), "a repeated reference is reported once")
+}
+
+func TestParseConfigFile_MappingVariableInString(t *testing.T) {
+	f := writeTempFile(t, "config.yaml", `
+variables:
+  tags: {team: networking}
+ports:
+  - {name: "Port-{{.var.tags}}", location_id: 1, speed: 1000, term: 12}
+`)
+	_, err := ParseConfigFile(f, nil, utils.ProjectSettings{})
+	assert.ErrorContains(t, err, `variable "tags" is a list or mapping and can only be used as a whole value`)
+}
+
+func TestParseConfigFile_Include(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "shared"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared", "ports.yaml"), []byte(`
+variables:
+  speed: 1000
+  location_id: 1
+ports:
+  - {name: Shared, location_id: "{{.var.location_id}}", speed: "{{.var.speed}}", term: 12}
+`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared", "mcr.json"),
+		[]byte(`{"mcrs": [{"name": "MCR", "location_id": 2, "speed": 1000, "term": 12}]}`), 0o600))
+	main := filepath.Join(dir, "main.yaml")
+	require.NoError(t, os.WriteFile(main, []byte(`
+include:
+  - shared/ports.yaml
+  - shared/mcr.json
+variables:
+  location_id: 5
+ports:
+  - {name: Own, location_id: "{{.var.location_id}}", speed: 10000, term: 12}
+`), 0o600))
+
+	cfg, err := ParseConfigFile(main, nil, utils.ProjectSettings{})
+	require.NoError(t, err)
+	require.Len(t, cfg.Ports, 2)
+	assert.Equal(t, "Shared", cfg.Ports[0].Name, "included entries come first")
+	assert.Equal(t, 5, cfg.Ports[0].LocationID, "the including file overrides an included variable")
+	assert.Equal(t, 1000, cfg.Ports[0].Speed)
+	assert.Equal(t, "Own", cfg.Ports[1].Name)
+	require.Len(t, cfg.MCRs, 1)
+	assert.Equal(t, "MCR", cfg.MCRs[0].Name)
+}
+
+func TestParseConfigFile_IncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.yaml")
+	require.NoError(t, os.WriteFile(a, []byte("include: b.yaml\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("include: [a.yaml]\n"), 0o600))
+
+	_, err := ParseConfigFile(a, nil, utils.ProjectSettings{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "include cycle")
+}
+
+func TestParseConfigFile_IncludedUnknownKeyRejected(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ports.yaml"),
+		[]byte("ports:\n  - {name: P, locationId: 1, speed: 1000, term: 12}\n"), 0o600))
+	main := filepath.Join(dir, "main.yaml")
+	require.NoError(t, os.WriteFile(main, []byte("include: ports.yaml\n"), 0o600))
+
+	_, err := ParseConfigFile(main, nil, utils.ProjectSettings{})
+	assert.Error(t, err, "unknown keys in an included file are still rejected")
+}
+
+func TestConfigVars_InvalidVar(t *testing.T) {
+	_, err := ConfigVars(varsCmd(nil, "no-equals-sign"))
+	assert.ErrorContains(t, err, `invalid --var "no-equals-sign": expected name=value`)
+}
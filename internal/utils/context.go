@@ -63,3 +63,32 @@ func TimeoutFromCmd(cmd *cobra.Command, defaultTimeout time.Duration) time.Durat
 	}
 	return defaultTimeout
 }
+
+// ProjectSettings are the settings a project config (.megaport.yaml) gives
+// the commands run in its directory.
+type ProjectSettings struct {
+	// ResourceTags are added to the tags of resources the CLI orders, except
+	// keys the order sets itself.
+	ResourceTags map[string]string
+
+	// Variables are values for the variables an apply config declares,
+	// overridden by --var-file and --var.
+	Variables map[string]interface{}
+}
+
+type projectSettingsKey struct{}
+
+// WithProjectSettings returns a copy of ctx carrying the project settings.
+func WithProjectSettings(ctx context.Context, settings ProjectSettings) context.Context {
+	return context.WithValue(ctx, projectSettingsKey{}, settings)
+}
+
+// ProjectSettingsFromCmd returns the project settings carried by the
+// command's context, or no settings when it carries none.
+func ProjectSettingsFromCmd(cmd *cobra.Command) ProjectSettings {
+	if cmd == nil || cmd.Context() == nil {
+		return ProjectSettings{}
+	}
+	settings, _ := cmd.Context().Value(projectSettingsKey{}).(ProjectSettings)
+	return settings
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
 	"time"
 
 	"github.com/megaport/megaport-cli/internal/base/exitcodes"
 	"github.com/megaport/megaport-cli/internal/base/output"
+	megaport "github.com/megaport/megaportgo"
 	"github.com/spf13/cobra"
 )
 
@@ -158,6 +160,46 @@ func RejectEmptyTagKeys(tags map[string]string) error {
 	return nil
 }
 
+// WithDefaultResourceTags returns tags with defaults added for every key tags
+// does not set. tags is returned unchanged when there are no defaults.
+func WithDefaultResourceTags(defaults, tags map[string]string) map[string]string {
+	if len(defaults) == 0 {
+		return tags
+	}
+	merged := make(map[string]string, len(defaults)+len(tags))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return merged
+}
+
+// WithDefaultResourceTagList is WithDefaultResourceTags for a tag list, as
+// NAT Gateway orders take. Defaults are appended in key order.
+func WithDefaultResourceTagList(defaults map[string]string, tags []megaport.ResourceTag) []megaport.ResourceTag {
+	if len(defaults) == 0 {
+		return tags
+	}
+	set := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		set[tag.Key] = true
+	}
+	keys := make([]string, 0, len(defaults))
+	for k := range defaults {
+		if !set[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	merged := slices.Clip(tags)
+	for _, k := range keys {
+		merged = append(merged, megaport.ResourceTag{Key: k, Value: defaults[k]})
+	}
+	return merged
+}
+
 // TagMapFromObject converts a decoded JSON object into a string tag map,
 // rejecting non-string values (including null) and empty keys. It is the shared
 // validation behind both the --resource-tags flag and the JSON-body
@@ -10,6 +10,7 @@ import (
 
 	"github.com/megaport/megaport-cli/internal/base/exitcodes"
 	"github.com/megaport/megaport-cli/internal/base/output"
+	megaport "github.com/megaport/megaportgo"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -506,3 +507,20 @@ func TestParseResourceTagsFlag(t *testing.T) {
 		assert.Contains(t, err.Error(), "tag key must not be empty")
 	})
 }
+
+func TestWithDefaultResourceTags(t *testing.T) {
+	assert.Nil(t, WithDefaultResourceTags(nil, nil), "no defaults leaves nil tags alone")
+	assert.Equal(t, []megaport.ResourceTag{{Key: "env", Value: "prod"}},
+		WithDefaultResourceTagList(nil, []megaport.ResourceTag{{Key: "env", Value: "prod"}}))
+
+	defaults := map[string]string{"team": "net", "env": "dev"}
+	own := map[string]string{"env": "prod"}
+	assert.Equal(t, map[string]string{"team": "net", "env": "prod"}, WithDefaultResourceTags(defaults, own), "the order's own tags win")
+	assert.Equal(t, map[string]string{"env": "prod"}, own, "the order's tags are not modified")
+	assert.Equal(t, map[string]string{"team": "net", "env": "dev"}, WithDefaultResourceTags(defaults, nil))
+
+	assert.Equal(t, []megaport.ResourceTag{{Key: "env", Value: "prod"}, {Key: "team", Value: "net"}},
+		WithDefaultResourceTagList(defaults, []megaport.ResourceTag{{Key: "env", Value: "prod"}}))
+	assert.Equal(t, []megaport.ResourceTag{{Key: "env", Value: "dev"}, {Key: "team", Value: "net"}},
+		WithDefaultResourceTagList(defaults, nil), "defaults are appended in key order")
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/megaport/megaport-cli/internal/base/exitcodes"
@@ -59,6 +60,10 @@ var (
 	// ProfileOverride is the config profile name selected via --profile.
 	ProfileOverride string
 
+	// ManagedAccount is the managed account (company UID or name) commands act
+	// on behalf of, selected via --managed-account.
+	ManagedAccount string
+
 	// NoRetry disables automatic retry on transient API failures. Set via --no-retry flag.
 	NoRetry bool
 
@@ -79,6 +84,28 @@ var (
 	ValidFormatsWASM = []string{FormatTable, FormatJSON, FormatCSV, FormatXML}
 )
 
+// ExtraFormatsAnnotation is the command annotation listing, comma separated,
+// the output formats a command accepts besides the common ones, e.g. the
+// diagram formats of topology.
+const ExtraFormatsAnnotation = "megaport-cli/extra-output-formats"
+
+// CommandFormats returns the output formats cmd accepts: base followed by the
+// formats of its ExtraFormatsAnnotation.
+func CommandFormats(cmd *cobra.Command, base []string) []string {
+	if cmd == nil || cmd.Annotations[ExtraFormatsAnnotation] == "" {
+		return base
+	}
+	return append(slices.Clip(base), strings.Split(cmd.Annotations[ExtraFormatsAnnotation], ",")...)
+}
+
+// IsActiveStatus reports whether a resource in provisioning status is still
+// in service, rather than cancelled or (being) decommissioned.
+func IsActiveStatus(status string) bool {
+	return status != megaport.STATUS_DECOMMISSIONED &&
+		status != megaport.STATUS_CANCELLED &&
+		status != StatusDecommissioning
+}
+
 func ShouldDisableColors() bool {
 	// Check if NO_COLOR environment variable is set (standard for disabling color)
 	_, noColorEnv := os.LookupEnv("NO_COLOR")
@@ -300,19 +327,13 @@ func WrapOutputFormatRunE(fn func(cmd *cobra.Command, args []string, noColor boo
 		format := strings.ToLower(rawFormat)
 
 		// Validate format
-		validFormat := false
-		for _, f := range ValidFormats {
-			if format == f {
-				validFormat = true
-				break
-			}
-		}
-		if !validFormat {
+		validFormats := CommandFormats(cmd, ValidFormats)
+		if !slices.Contains(validFormats, format) {
 			// An invalid format is itself a usage error. Sync the output package
 			// to table first so finishWithError surfaces it on stderr rather than
 			// suppressing it under a stale json format.
 			syncOutputFormat(FormatTable)
-			return finishWithError(cmd, args, exitcodes.NewUsageError(fmt.Errorf("invalid output format: %s. Must be one of: %v", format, ValidFormats)), FormatTable, noColor, tokenPresent)
+			return finishWithError(cmd, args, exitcodes.NewUsageError(fmt.Errorf("invalid output format: %s. Must be one of: %v", format, validFormats)), FormatTable, noColor, tokenPresent)
 		}
 
 		syncOutputFormat(format)
@@ -13,6 +13,7 @@ import (
 	"github.com/megaport/megaport-cli/internal/base/exitcodes"
 	"github.com/megaport/megaport-cli/internal/base/output"
 	"github.com/megaport/megaport-cli/internal/validation"
+	megaport "github.com/megaport/megaportgo"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -114,6 +115,14 @@ func TestGetCurrentEnv(t *testing.T) {
 	})
 }
 
+func TestIsActiveStatus(t *testing.T) {
+	assert.True(t, IsActiveStatus("LIVE"))
+	assert.True(t, IsActiveStatus("CONFIGURED"))
+	assert.False(t, IsActiveStatus(megaport.STATUS_DECOMMISSIONED))
+	assert.False(t, IsActiveStatus(megaport.STATUS_CANCELLED))
+	assert.False(t, IsActiveStatus(StatusDecommissioning))
+}
+
 func TestWrapRunE(t *testing.T) {
 	t.Run("success returns nil", func(t *testing.T) {
 		wrapped := WrapRunE(func(cmd *cobra.Command, args []string) error {
@@ -391,6 +400,31 @@ func TestWrapOutputFormatRunE(t *testing.T) {
 		assert.Equal(t, exitcodes.Usage, cliErr.Code)
 	})
 
+	t.Run("accepts a command's extra output formats", func(t *testing.T) {
+		var capturedFormat string
+		wrapped := WrapOutputFormatRunE(func(cmd *cobra.Command, args []string, noColor bool, format string) error {
+			capturedFormat = format
+			return nil
+		})
+
+		root := &cobra.Command{Use: "root"}
+		root.PersistentFlags().Bool("no-color", false, "")
+		child := &cobra.Command{Use: "topology", Annotations: map[string]string{ExtraFormatsAnnotation: "dot,mermaid"}}
+		child.Flags().String("output", "", "")
+		other := &cobra.Command{Use: "list"}
+		other.Flags().String("output", "", "")
+		root.AddCommand(child, other)
+		require.NoError(t, child.Flags().Set("output", "Mermaid"))
+		require.NoError(t, other.Flags().Set("output", "mermaid"))
+
+		require.NoError(t, wrapped(child, []string{}))
+		assert.Equal(t, "mermaid", capturedFormat)
+
+		err := wrapped(other, []string{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid output format: mermaid")
+	})
+
 	t.Run("query flag with non-json format returns usage error", func(t *testing.T) {
 		wrapped := WrapOutputFormatRunE(func(cmd *cobra.Command, args []string, noColor bool, format string) error {
 			return nil
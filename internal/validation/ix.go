@@ -0,0 +1,28 @@
+package validation
+
+import (
+	megaport "github.com/megaport/megaportgo"
+)
+
+// ValidateIXRequest validates a request to buy an Internet Exchange connection.
+func ValidateIXRequest(req *megaport.BuyIXRequest) error {
+	if req.Name == "" {
+		return NewValidationError("name", req.Name, "cannot be empty")
+	}
+	if req.ProductUID == "" {
+		return NewValidationError("product UID", req.ProductUID, "cannot be empty")
+	}
+	if req.NetworkServiceType == "" {
+		return NewValidationError("network service type", req.NetworkServiceType, "cannot be empty")
+	}
+	if err := ValidateASN(req.ASN); err != nil {
+		return err
+	}
+	if err := ValidateMACAddress(req.MACAddress); err != nil {
+		return err
+	}
+	if err := ValidateRateLimit(req.RateLimit); err != nil {
+		return err
+	}
+	return ValidateVLAN(req.VLAN)
+}
@@ -0,0 +1,50 @@
+package validation
+
+import (
+	"testing"
+
+	megaport "github.com/megaport/megaportgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateIXRequest(t *testing.T) {
+	valid := func() *megaport.BuyIXRequest {
+		return &megaport.BuyIXRequest{
+			Name:               "Sydney IX",
+			ProductUID:         "port-uid",
+			NetworkServiceType: "Sydney IX",
+			ASN:                65000,
+			MACAddress:         "00:11:22:33:44:55",
+			RateLimit:          1000,
+			VLAN:               100,
+		}
+	}
+	tests := []struct {
+		name   string
+		modify func(*megaport.BuyIXRequest)
+		errMsg string
+	}{
+		{name: "valid request", modify: func(*megaport.BuyIXRequest) {}},
+		{name: "auto-assigned VLAN", modify: func(r *megaport.BuyIXRequest) { r.VLAN = 0 }},
+		{name: "missing name", modify: func(r *megaport.BuyIXRequest) { r.Name = "" }, errMsg: "name"},
+		{name: "missing product UID", modify: func(r *megaport.BuyIXRequest) { r.ProductUID = "" }, errMsg: "product UID"},
+		{name: "missing network service type", modify: func(r *megaport.BuyIXRequest) { r.NetworkServiceType = "" }, errMsg: "network service type"},
+		{name: "invalid ASN", modify: func(r *megaport.BuyIXRequest) { r.ASN = 0 }, errMsg: "ASN"},
+		{name: "invalid MAC address", modify: func(r *megaport.BuyIXRequest) { r.MACAddress = "not-a-mac" }, errMsg: "MAC address"},
+		{name: "invalid rate limit", modify: func(r *megaport.BuyIXRequest) { r.RateLimit = 0 }, errMsg: "rate limit"},
+		{name: "invalid VLAN", modify: func(r *megaport.BuyIXRequest) { r.VLAN = 5000 }, errMsg: "VLAN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(req)
+			err := ValidateIXRequest(req)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
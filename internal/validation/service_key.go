@@ -0,0 +1,28 @@
+package validation
+
+import (
+	megaport "github.com/megaport/megaportgo"
+)
+
+// ValidateCreateServiceKeyRequest validates a request to create a service key.
+// A single-use key is bound to one VXC, so it must name the VLAN that VXC uses.
+func ValidateCreateServiceKeyRequest(req *megaport.CreateServiceKeyRequest) error {
+	if req.ProductUID == "" && req.ProductID == 0 {
+		return NewValidationError("product UID", req.ProductUID, "cannot be empty")
+	}
+	if req.ProductUID != "" && req.ProductID != 0 {
+		return NewValidationError("product ID", req.ProductID, "cannot be set together with a product UID")
+	}
+	if req.MaxSpeed <= 0 {
+		return NewValidationError("max speed", req.MaxSpeed, "must be a positive integer")
+	}
+	if req.SingleUse && req.VLAN == 0 {
+		return NewValidationError("VLAN ID", req.VLAN, "is required for a single-use service key")
+	}
+	if req.VLAN != 0 {
+		if err := ValidateVLAN(req.VLAN); err != nil {
+			return err
+		}
+	}
+	return nil
+}
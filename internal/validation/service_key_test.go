@@ -0,0 +1,61 @@
+package validation
+
+import (
+	"testing"
+
+	megaport "github.com/megaport/megaportgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCreateServiceKeyRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    *megaport.CreateServiceKeyRequest
+		errMsg string
+	}{
+		{
+			name: "multi-use key",
+			req:  &megaport.CreateServiceKeyRequest{ProductUID: "port-uid", MaxSpeed: 500},
+		},
+		{
+			name: "single-use key with VLAN",
+			req:  &megaport.CreateServiceKeyRequest{ProductUID: "port-uid", MaxSpeed: 500, SingleUse: true, VLAN: 100},
+		},
+		{
+			name:   "missing product",
+			req:    &megaport.CreateServiceKeyRequest{MaxSpeed: 500},
+			errMsg: "product UID",
+		},
+		{
+			name:   "product UID and ID",
+			req:    &megaport.CreateServiceKeyRequest{ProductUID: "port-uid", ProductID: 1, MaxSpeed: 500},
+			errMsg: "product ID",
+		},
+		{
+			name:   "missing max speed",
+			req:    &megaport.CreateServiceKeyRequest{ProductUID: "port-uid"},
+			errMsg: "max speed",
+		},
+		{
+			name:   "single-use key without VLAN",
+			req:    &megaport.CreateServiceKeyRequest{ProductUID: "port-uid", MaxSpeed: 500, SingleUse: true},
+			errMsg: "single-use",
+		},
+		{
+			name:   "invalid VLAN",
+			req:    &megaport.CreateServiceKeyRequest{ProductUID: "port-uid", MaxSpeed: 500, VLAN: 5000},
+			errMsg: "VLAN",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCreateServiceKeyRequest(tt.req)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
@@ -59,6 +59,10 @@ const (
 	AWSConnectTypePublic = "public"
 )
 
+// ValidPartnerConnectTypes lists the connectType values of a VXC partner
+// configuration. They are matched without regard to case.
+var ValidPartnerConnectTypes = []string{"AWS", "AWSHC", "AZURE", "GOOGLE", "ORACLE", "IBM", "TRANSIT", "VROUTER"}
+
 // ValidateVXCEndVLAN validates the VLAN ID for a VXC (Virtual Cross Connect) endpoint.
 // This ensures the VLAN ID meets the Megaport requirements for VXC configurations.
 //